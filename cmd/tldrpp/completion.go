@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/makalin/tldrpp/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// newCompletionCmd returns the completion command, which replaces cobra's
+// default one to document how to install the scripts
+func newCompletionCmd(rootCmd *cobra.Command) *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate shell completion scripts",
+		Long: `Generate a completion script for your shell. Page names, snippet names and
+platforms are completed from the local cache.
+
+  bash:       source <(tldrpp completion bash)
+  zsh:        tldrpp completion zsh > "${fpath[1]}/_tldrpp"
+  fish:       tldrpp completion fish > ~/.config/fish/completions/tldrpp.fish
+  powershell: tldrpp completion powershell | Out-String | Invoke-Expression`,
+		DisableFlagsInUseLine: true,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			switch args[0] {
+			case "bash":
+				err = rootCmd.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				err = rootCmd.GenZshCompletion(os.Stdout)
+			case "fish":
+				err = rootCmd.GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				err = rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating completion: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+// completePage completes the page name argument at position n
+func completePage(n int) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != n {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return app.CompletePages(toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeCommand completes a page name and then its subcommands, as in
+// render git commit
+func completeCommand(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return app.CompletePages(toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+	return app.CompleteSubcommands(args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePages completes any number of page name arguments
+func completePages(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return app.CompletePages(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSnippet completes a snippet name argument
+func completeSnippet(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return app.CompleteSnippets(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorkflow completes a workflow name argument
+func completeWorkflow(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return app.CompleteWorkflows(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePlatform completes the --platform flag
+func completePlatform(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return app.CompletePlatforms(), cobra.ShellCompDirectiveNoFileComp
+}
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/makalin/tldrpp/internal/app"
 	"github.com/spf13/cobra"
@@ -27,7 +32,10 @@ then paste or execute the final command.`,
 		Use:   "init",
 		Short: "Initialize tldr++ by downloading page index",
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := app.Initialize(); err != nil {
+			ctx, stop := app.Interruptible()
+			defer stop()
+			if err := app.Initialize(ctx); err != nil {
+				exitIfInterrupted(err)
 				fmt.Fprintf(os.Stderr, "Error initializing tldr++: %v\n", err)
 				os.Exit(1)
 			}
@@ -38,42 +46,315 @@ then paste or execute the final command.`,
 	var updateCmd = &cobra.Command{
 		Use:   "update",
 		Short: "Update tldr pages cache",
+		Long: `Download the pages and rebuild the cache. With pages_version set in the
+config, the cache is built at that tldr-pages release or commit; --pin builds
+it at another version, or "latest", for this update only.`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := app.UpdateCache(); err != nil {
+			ifStale, _ := cmd.Flags().GetBool("if-stale")
+			pin, _ := cmd.Flags().GetString("pin")
+			ctx, stop := app.Interruptible()
+			defer stop()
+			updated, err := app.UpdateCache(ctx, ifStale, pin)
+			if err != nil {
+				exitIfInterrupted(err)
 				fmt.Fprintf(os.Stderr, "Error updating cache: %v\n", err)
 				os.Exit(1)
 			}
+			if !updated {
+				fmt.Println("Cache is up to date")
+				return
+			}
 			fmt.Println("Cache updated successfully!")
+			if summary := app.ChangeSummary(); summary != "" {
+				fmt.Println(summary)
+			}
+		},
+	}
+	updateCmd.Flags().Bool("if-stale", false, "Only update when the cache is older than cache_ttl_hours")
+	updateCmd.Flags().String("pin", "", `Build the cache at this pages version, or "latest", instead of pages_version`)
+
+	var cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and maintain the pages cache",
+	}
+
+	var verifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Check the cache for missing, corrupt and orphaned pages",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.VerifyCache(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error verifying cache: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var repairCmd = &cobra.Command{
+		Use:   "repair",
+		Short: "Re-download broken pages and remove orphans",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.RepairCache(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error repairing cache: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var infoCmd = &cobra.Command{
+		Use:   "info",
+		Short: "Show cache statistics",
+		Run: func(cmd *cobra.Command, args []string) {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			if err := app.CacheInfo(asJSON); err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading cache info: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	infoCmd.Flags().Bool("json", false, "Print cache info as JSON")
+
+	cacheCmd.AddCommand(verifyCmd, repairCmd, infoCmd)
+
+	var configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Read, change and check the configuration",
+	}
+
+	var configGetCmd = &cobra.Command{
+		Use:   "get [key]",
+		Short: "Print a config value, or all of them",
+		Args:  cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return app.ConfigKeys(), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var key string
+			if len(args) > 0 {
+				key = args[0]
+			}
+			if err := app.ConfigGet(key); err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var configSetCmd = &cobra.Command{
+		Use:     "set <key> <value>",
+		Short:   "Change a config value",
+		Example: "  tldrpp config set theme solarized\n  tldrpp config set platforms linux,common",
+		Args:    cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return app.ConfigKeys(), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.ConfigSet(args[0], args[1]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error changing config: %v\n", err)
+				os.Exit(1)
+			}
 		},
 	}
 
+	var configEditCmd = &cobra.Command{
+		Use:   "edit",
+		Short: "Open the config file in $EDITOR and check it afterwards",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.ConfigEdit(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error editing config: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var configValidateCmd = &cobra.Command{
+		Use:   "validate",
+		Short: "Check the config file for unknown keys and invalid values",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.ConfigValidate(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	configCmd.AddCommand(configGetCmd, configSetCmd, configEditCmd, configValidateCmd)
+
 	var renderCmd = &cobra.Command{
-		Use:   "render [command]",
+		Use:   "render [command...] [-- values...]",
 		Short: "Render command with placeholders filled",
-		Args:  cobra.ExactArgs(1),
+		Args:  commandWithPositional,
 		Run: func(cmd *cobra.Command, args []string) {
-			vars, _ := cmd.Flags().GetStringToString("vars")
-			if err := app.RenderCommand(args[0], vars); err != nil {
+			if list, _ := cmd.Flags().GetBool("list-examples"); list {
+				asJSON, _ := cmd.Flags().GetBool("json")
+				vars, _ := cmd.Flags().GetStringToString("vars")
+				command, _ := commandArg(cmd, args)
+				if err := app.ListExamples(command, asJSON, vars); err != nil {
+					fmt.Fprintf(os.Stderr, "Error listing examples: %v\n", err)
+					os.Exit(1)
+				}
+				return
+			}
+
+			command, positional := commandArg(cmd, args)
+			render := app.RenderCommand
+			if qr, _ := cmd.Flags().GetBool("qr"); qr {
+				render = app.RenderQR
+			}
+			if err := render(command, renderOptions(cmd, positional)); err != nil {
 				fmt.Fprintf(os.Stderr, "Error rendering command: %v\n", err)
 				os.Exit(1)
 			}
 		},
 	}
-	renderCmd.Flags().StringToString("vars", nil, "Variables to substitute in placeholders")
+	renderCmd.ValidArgsFunction = completeCommand
+	addRenderFlags(renderCmd)
+	renderCmd.Flags().Bool("strict", false, "Fail if any placeholder is left unresolved")
+	renderCmd.Flags().Bool("qr", false, "Also print the command as a QR code, to photograph it")
 
 	var execCmd = &cobra.Command{
-		Use:   "exec [command]",
+		Use:   "exec [command...] [-- values...]",
 		Short: "Execute command with placeholders filled",
-		Args:  cobra.ExactArgs(1),
+		Args:  commandWithPositional,
 		Run: func(cmd *cobra.Command, args []string) {
-			vars, _ := cmd.Flags().GetStringToString("vars")
-			if err := app.ExecuteCommand(args[0], vars); err != nil {
+			if list, _ := cmd.Flags().GetBool("list-examples"); list {
+				asJSON, _ := cmd.Flags().GetBool("json")
+				vars, _ := cmd.Flags().GetStringToString("vars")
+				command, _ := commandArg(cmd, args)
+				if err := app.ListExamples(command, asJSON, vars); err != nil {
+					fmt.Fprintf(os.Stderr, "Error listing examples: %v\n", err)
+					os.Exit(1)
+				}
+				return
+			}
+
+			command, positional := commandArg(cmd, args)
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			record, _ := cmd.Flags().GetBool("record")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			dir, _ := cmd.Flags().GetString("cwd")
+			asRoot, _ := cmd.Flags().GetBool("sudo")
+			ctx, stop := app.Interruptible()
+			defer stop()
+			if err := app.ExecuteCommand(ctx, command, renderOptions(cmd, positional), app.ExecOptions{Timeout: timeout, Record: record, DryRun: dryRun, Dir: dir, Sudo: asRoot}); err != nil {
+				exitIfInterrupted(err)
 				fmt.Fprintf(os.Stderr, "Error executing command: %v\n", err)
 				os.Exit(1)
 			}
 		},
 	}
-	execCmd.Flags().StringToString("vars", nil, "Variables to substitute in placeholders")
+	execCmd.ValidArgsFunction = completeCommand
+	addRenderFlags(execCmd)
+	execCmd.Flags().Bool("strict", true, "Fail if any placeholder is left unresolved")
+	execCmd.Flags().Duration("timeout", 0, "Stop the command after this long, e.g. 30s; overrides exec.timeout")
+	execCmd.Flags().Bool("record", false, "Record the command's output to replay it with 'tldrpp replay'; see exec.record")
+	execCmd.Flags().Bool("dry-run", false, "Print the command and the environment it would run with instead of running it")
+	execCmd.Flags().String("cwd", "", "Run the command in this directory instead of the current one")
+	execCmd.MarkFlagDirname("cwd")
+	execCmd.Flags().Bool("sudo", false, "Run the command as root with sudo, which asks for a password in the terminal")
+
+	var replayCmd = &cobra.Command{
+		Use:   "replay [N]",
+		Short: "Play back the output of a recorded command",
+		Long: `Play back the output of a command recorded with exec.record in the config or
+'tldrpp exec --record', at the pace it was printed. N picks the Nth most
+recent recording, 1 (the default) for the latest; --list numbers them. Casts
+are asciinema v2 files kept in the data directory, so 'asciinema play' and
+other players read them too; --file plays any such file.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if list, _ := cmd.Flags().GetBool("list"); list {
+				if err := app.ListRecordings(); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+				return
+			}
+			n := 1
+			if len(args) == 1 {
+				var err error
+				if n, err = strconv.Atoi(args[0]); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: expected the number of a recording, got %q\n", args[0])
+					os.Exit(1)
+				}
+			}
+			file, _ := cmd.Flags().GetString("file")
+			maxIdle, _ := cmd.Flags().GetDuration("max-idle")
+			ctx, stop := app.Interruptible()
+			defer stop()
+			if err := app.Replay(ctx, n, file, maxIdle); err != nil {
+				exitIfInterrupted(err)
+				fmt.Fprintf(os.Stderr, "Error replaying: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	replayCmd.Flags().Bool("list", false, "List the recorded commands, newest first")
+	replayCmd.Flags().String("file", "", "Play this cast file instead of a recording")
+	replayCmd.Flags().Duration("max-idle", 2*time.Second, "Shorten longer pauses to this; 0 prints everything at once")
+	replayCmd.MarkFlagsMutuallyExclusive("list", "file")
+
+	var pickCmd = &cobra.Command{
+		Use:   "pick [command...] [-- values...]",
+		Short: "Select, fill and act on an example without the TUI",
+		Long: `Do what picking an example in the TUI does, from flags alone, for scripts and
+tests that have no terminal: select an example with --example or --match,
+fill its placeholders with --fill name=value, --fill-file (name=value lines,
+- for stdin) or positional values after "--", then --print the command (the
+default), --exec it or --copy it to the clipboard. Nothing is prompted for,
+and unresolved placeholders are an error unless --strict=false.
+
+  tldrpp pick tar --example 2 --fill file=x.tgz --exec`,
+		Args: commandWithPositional,
+		Run: func(cmd *cobra.Command, args []string) {
+			command, positional := commandArg(cmd, args)
+			opts := renderOptions(cmd, positional)
+			opts.Vars, _ = cmd.Flags().GetStringToString("fill")
+			opts.VarsFlag = "fill"
+			if path, _ := cmd.Flags().GetString("fill-file"); path != "" {
+				fills, err := app.ReadFills(path)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error reading values: %v\n", err)
+					os.Exit(1)
+				}
+				// Values given with --fill win over those from the file
+				for name, value := range opts.Vars {
+					fills[name] = value
+				}
+				opts.Vars = fills
+			}
+
+			action := app.PickPrint
+			if ok, _ := cmd.Flags().GetBool("exec"); ok {
+				action = app.PickExec
+			} else if ok, _ := cmd.Flags().GetBool("copy"); ok {
+				action = app.PickCopy
+			}
+			if err := app.Pick(command, opts, action); err != nil {
+				fmt.Fprintf(os.Stderr, "Error picking command: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	pickCmd.ValidArgsFunction = completeCommand
+	pickCmd.Flags().Int("example", 0, "Select example by index (see render --list-examples)")
+	pickCmd.Flags().String("match", "", "Select the first example whose description or command matches")
+	pickCmd.Flags().StringToString("fill", nil, "Placeholder values; N.name sets one for example N only")
+	pickCmd.Flags().String("fill-file", "", "Read name=value lines from a file, or - for stdin")
+	pickCmd.Flags().StringSlice("raw", nil, "Placeholders to substitute verbatim instead of shell-quoted")
+	pickCmd.Flags().Bool("strict", true, "Fail if any placeholder is left unresolved")
+	pickCmd.Flags().Bool("print", false, "Print the command (the default)")
+	pickCmd.Flags().Bool("exec", false, "Run the command")
+	pickCmd.Flags().Bool("copy", false, "Copy the command to the clipboard")
+	pickCmd.MarkFlagsMutuallyExclusive("example", "match")
+	pickCmd.MarkFlagsMutuallyExclusive("print", "exec", "copy")
 
 	var pluginCmd = &cobra.Command{
 		Use:   "plugin",
@@ -95,23 +376,653 @@ then paste or execute the final command.`,
 
 	// Global flags
 	rootCmd.PersistentFlags().StringP("platform", "p", "", "Platform filter (common, linux, osx, sunos, windows, android)")
-	rootCmd.PersistentFlags().StringP("theme", "t", "dark", "Theme (light, dark, solarized)")
+	rootCmd.PersistentFlags().StringP("theme", "t", "", "Theme (light, dark, solarized) (default from the config)")
 	rootCmd.PersistentFlags().BoolP("dev", "d", false, "Development mode")
+	rootCmd.PersistentFlags().StringP("language", "L", "", "Page language, e.g. de or pt_BR (default from the locale)")
+	rootCmd.PersistentFlags().Bool("verbose", false, "Log what tldr++ does to stderr (to the data directory's tldrpp.log in the TUI)")
+	rootCmd.PersistentFlags().String("log-file", "", "Append the log to this file")
+	rootCmd.PersistentFlags().Bool("no-color", false, "Disable colors (also set by NO_COLOR)")
+	rootCmd.PersistentFlags().Bool("ascii", false, "Draw borders and symbols in ASCII only")
+	rootCmd.PersistentFlags().String("home", "", "Keep config, cache and history under this directory (also set by TLDRPP_HOME)")
+	rootCmd.PersistentFlags().MarkHidden("home")
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		home, _ := cmd.Flags().GetString("home")
+		if err := app.SetHome(home); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		app.Migrate()
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		dev, _ := cmd.Flags().GetBool("dev")
+		logFile, _ := cmd.Flags().GetString("log-file")
+		if err := app.SetupLogging(verbose, dev, logFile, cmd == cmd.Root()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		language, _ := cmd.Flags().GetString("language")
+		app.SetLanguage(language)
+		noColor, _ := cmd.Flags().GetBool("no-color")
+		ascii, _ := cmd.Flags().GetBool("ascii")
+		app.SetupOutput(noColor, ascii)
+	}
+	rootCmd.RegisterFlagCompletionFunc("platform", completePlatform)
+	rootCmd.RegisterFlagCompletionFunc("language", cobra.NoFileCompletions)
+	rootCmd.RegisterFlagCompletionFunc("theme", cobra.FixedCompletions(
+		[]string{"light", "dark", "solarized"}, cobra.ShellCompDirectiveNoFileComp))
+
+	var snippetCmd = &cobra.Command{
+		Use:   "snippet",
+		Short: "Manage saved snippets",
+	}
+
+	var snippetAddCmd = &cobra.Command{
+		Use:   "add [name] [command] [-- values...]",
+		Short: "Save an example with its placeholder values as a snippet",
+		Args:  snippetWithPositional,
+		Run: func(cmd *cobra.Command, args []string) {
+			force, _ := cmd.Flags().GetBool("force")
+			env, _ := cmd.Flags().GetStringArray("env")
+			opts := renderOptions(cmd, args[2:])
+			if err := app.AddSnippet(args[0], args[1], opts, env, force); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving snippet: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	snippetAddCmd.Flags().StringToString("vars", nil, "Variables to substitute in placeholders")
+	snippetAddCmd.Flags().Int("example", 0, "Select example by index (see render --list-examples)")
+	snippetAddCmd.Flags().String("match", "", "Select the first example whose description or command matches")
+	snippetAddCmd.Flags().Bool("no-prompt", false, "Never prompt for missing placeholder values")
+	snippetAddCmd.Flags().StringSlice("raw", nil, "Placeholders to substitute verbatim instead of shell-quoted")
+	snippetAddCmd.Flags().Bool("force", false, "Replace an existing snippet of the same name")
+	snippetAddCmd.Flags().StringArray("env", nil, "Environment variable to run the snippet with, as NAME=value; repeatable")
+	snippetAddCmd.MarkFlagsMutuallyExclusive("example", "match")
+	snippetAddCmd.ValidArgsFunction = completePage(1)
+
+	var snippetListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List saved snippets",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.ListSnippets(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing snippets: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var snippetShowCmd = &cobra.Command{
+		Use:   "show [name]",
+		Short: "Show a snippet's command and origin",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.ShowSnippet(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error showing snippet: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var snippetRunCmd = &cobra.Command{
+		Use:   "run [name]",
+		Short: "Execute a saved snippet",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			dir, _ := cmd.Flags().GetString("cwd")
+			asRoot, _ := cmd.Flags().GetBool("sudo")
+			if err := app.RunSnippet(args[0], app.ExecOptions{DryRun: dryRun, Dir: dir, Sudo: asRoot}); err != nil {
+				fmt.Fprintf(os.Stderr, "Error running snippet: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	snippetRunCmd.Flags().Bool("dry-run", false, "Print the command and the environment it would run with instead of running it")
+	snippetRunCmd.Flags().String("cwd", "", "Run the snippet in this directory instead of the current one")
+	snippetRunCmd.MarkFlagDirname("cwd")
+	snippetRunCmd.Flags().Bool("sudo", false, "Run the snippet as root with sudo")
+
+	var snippetRemoveCmd = &cobra.Command{
+		Use:     "rm [name]",
+		Aliases: []string{"remove"},
+		Short:   "Delete a saved snippet",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.RemoveSnippet(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing snippet: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	for _, cmd := range []*cobra.Command{snippetShowCmd, snippetRunCmd, snippetRemoveCmd} {
+		cmd.ValidArgsFunction = completeSnippet
+	}
+	snippetCmd.AddCommand(snippetAddCmd, snippetListCmd, snippetShowCmd, snippetRunCmd, snippetRemoveCmd)
+
+	var workflowCmd = &cobra.Command{
+		Use:   "workflow",
+		Short: "Run multi-step workflows written as YAML",
+	}
+
+	var workflowListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List workflows",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.ListWorkflows(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing workflows: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var workflowShowCmd = &cobra.Command{
+		Use:   "show [name]",
+		Short: "Show a workflow's steps and placeholders",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.ShowWorkflow(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error showing workflow: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var workflowRunCmd = &cobra.Command{
+		Use:   "run [name]",
+		Short: "Run a workflow's steps in order, confirming each one",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			vars, _ := cmd.Flags().GetStringToString("vars")
+			noPrompt, _ := cmd.Flags().GetBool("no-prompt")
+			yes, _ := cmd.Flags().GetBool("yes")
+			useTUI, _ := cmd.Flags().GetBool("tui")
+			opts := app.WorkflowOptions{Vars: vars, NoPrompt: noPrompt, Yes: yes, TUI: useTUI}
+			if err := app.RunWorkflow(args[0], opts); err != nil {
+				fmt.Fprintf(os.Stderr, "Error running workflow: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	workflowRunCmd.Flags().StringToString("vars", nil, "Variables to substitute in placeholders")
+	workflowRunCmd.Flags().Bool("no-prompt", false, "Never prompt for missing placeholder values")
+	workflowRunCmd.Flags().BoolP("yes", "y", false, "Run every step without asking")
+	workflowRunCmd.Flags().Bool("tui", false, "Step through the workflow in the terminal UI")
+	workflowRunCmd.MarkFlagsMutuallyExclusive("yes", "tui")
+
+	for _, cmd := range []*cobra.Command{workflowShowCmd, workflowRunCmd} {
+		cmd.ValidArgsFunction = completeWorkflow
+	}
+	workflowCmd.AddCommand(workflowListCmd, workflowShowCmd, workflowRunCmd)
+
+	var explainCmd = &cobra.Command{
+		Use:   "explain [command line...]",
+		Short: "Annotate a command line with its tldr explanation",
+		Long: `Break a command line down against its tldr page: each flag is matched to
+the example that explains it and each argument to the placeholder it fills.
+The command line is read from stdin when none is given:
+
+  echo "tar -xzvf foo.tgz" | tldrpp explain
+  tldrpp explain -- tar -xzvf foo.tgz`,
+		Run: func(cmd *cobra.Command, args []string) {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			if err := app.Explain(args, asJSON); err != nil {
+				fmt.Fprintf(os.Stderr, "Error explaining command: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	explainCmd.ValidArgsFunction = completePage(0)
+	explainCmd.Flags().Bool("json", false, "Print the breakdown as JSON")
+
+	var shellInitCmd = &cobra.Command{
+		Use:   "shell-init [bash|zsh|fish]",
+		Short: "Print the shell widget that looks up the command being typed",
+		Long: `Print shell code for a widget that opens tldr++ on the command line being
+typed and replaces it with the example you pick. Add it to your shell's
+startup file:
+
+  bash: eval "$(tldrpp shell-init bash)"
+  zsh:  eval "$(tldrpp shell-init zsh)"
+  fish: tldrpp shell-init fish | source`,
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		Run: func(cmd *cobra.Command, args []string) {
+			key, _ := cmd.Flags().GetString("key")
+			if err := app.ShellInit(args[0], key); err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating shell widget: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	shellInitCmd.Flags().String("key", "ctrl-g", "Key to bind the widget to, as ctrl-<letter>")
+
+	var auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Query the log of executed commands",
+		Long: `Every command run through tldr++ is recorded in the audit log with the
+user, working directory, page, exit code and duration. --since takes a
+duration such as 24h or 7d, or a date such as 2006-01-02.`,
+	}
+
+	var auditListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List executed commands, oldest first",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.AuditList(auditOptions(cmd)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading audit log: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	auditListCmd.Flags().Int("limit", 0, "Show only the most recent executions")
+
+	var auditSearchCmd = &cobra.Command{
+		Use:   "search <text>",
+		Short: "Find executed commands containing text",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.AuditSearch(args[0], auditOptions(cmd)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error searching audit log: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	auditSearchCmd.Flags().Int("limit", 0, "Show only the most recent executions")
+
+	var auditExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export executed commands as JSON Lines, JSON or CSV",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			format, _ := cmd.Flags().GetString("format")
+			output, _ := cmd.Flags().GetString("output")
+			if err := app.AuditExport(format, output, auditOptions(cmd)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting audit log: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	auditExportCmd.Flags().String("format", "jsonl", "Export format (jsonl, json, csv)")
+	auditExportCmd.Flags().StringP("output", "o", "", "File to write instead of stdout")
+	auditExportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(app.AuditFormats(), cobra.ShellCompDirectiveNoFileComp))
+
+	for _, cmd := range []*cobra.Command{auditListCmd, auditSearchCmd, auditExportCmd} {
+		addAuditFlags(cmd)
+	}
+	auditCmd.AddCommand(auditListCmd, auditSearchCmd, auditExportCmd)
+
+	var statsCmd = &cobra.Command{
+		Use:   "stats",
+		Short: "Show which pages and examples you use most",
+		Long: `Show the most used pages and examples, usage over the last days and how
+often placeholders are filled in. Stats are only recorded once enabled with
+"tldrpp config set stats true", and never leave this machine.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			days, _ := cmd.Flags().GetInt("days")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			if err := app.Stats(days, asJSON); err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading usage stats: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	statsCmd.Flags().Int("days", 14, "Number of days to chart")
+	statsCmd.Flags().Bool("json", false, "Print the stats as JSON")
+
+	var trustCmd = &cobra.Command{
+		Use:   "trust",
+		Short: "Manage which pages may run commands",
+		Long: `Pages from the official tldr archive, or from a source with "trusted: true"
+in the config, run commands freely. Pages from any other source, such as a
+mirror, a fork or a local archive, must each be trusted once before exec
+runs their commands. Trust is remembered per page and source. Custom pages
+and snippets pulled with 'tldrpp sync' must be trusted once too, and again
+whenever a pull changes them.`,
+	}
+
+	var trustListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "Show the pages source and the pages trusted",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.TrustList(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing trusted pages: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var trustAddCmd = &cobra.Command{
+		Use:               "add <command>",
+		Short:             "Trust a page's commands",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePage(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			trustSnippet, _ := cmd.Flags().GetBool("snippet")
+			add := app.TrustAdd
+			if trustSnippet {
+				add = app.TrustAddSnippet
+			}
+			if err := add(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error trusting page: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
 
-	rootCmd.AddCommand(initCmd, updateCmd, renderCmd, execCmd, pluginCmd)
+	var trustRemoveCmd = &cobra.Command{
+		Use:               "remove <page>",
+		Short:             "Stop trusting a page's commands",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePage(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			platform, _ := cmd.Flags().GetString("platform")
+			if err := app.TrustRemove(args[0], platform); err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing trust: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	trustAddCmd.Flags().Bool("snippet", false, "Trust the snippet of that name, pulled with sync")
+	trustCmd.AddCommand(trustListCmd, trustAddCmd, trustRemoveCmd)
+
+	var exportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export pages to other formats",
+	}
+
+	var exportHTMLCmd = &cobra.Command{
+		Use:   "html",
+		Short: "Render pages into a static, searchable HTML site",
+		Long: `Render the cached and custom pages into a static HTML site with client-side
+fuzzy search, e.g. to host a team's cheat sheets. Use --platform to export only
+some platforms (comma-separated).`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			out, _ := cmd.Flags().GetString("out")
+			title, _ := cmd.Flags().GetString("title")
+			if err := app.ExportHTML(out, title, platformsFlag(cmd)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting pages: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	exportHTMLCmd.Flags().StringP("out", "o", "site", "Directory to write the site to")
+	exportHTMLCmd.Flags().String("title", "tldr++ pages", "Title of the site")
+
+	var exportPDFCmd = &cobra.Command{
+		Use:   "pdf [page...]",
+		Short: "Render pages into a printable PDF cheat sheet",
+		Long: `Render the given pages, or all pages, into a PDF cheat sheet on A4 sheets,
+for printing or attaching to a wiki. --layout 2col sets two columns per sheet.
+Without pages, use --platform to export only some platforms (comma-separated).`,
+		Run: func(cmd *cobra.Command, args []string) {
+			out, _ := cmd.Flags().GetString("out")
+			layout, _ := cmd.Flags().GetString("layout")
+			if err := app.ExportPDF(args, out, layout, platformsFlag(cmd)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting pages: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	exportPDFCmd.Flags().StringP("out", "o", "cheatsheet.pdf", "File to write the PDF to")
+	exportPDFCmd.Flags().String("layout", "1col", "Sheet layout ("+strings.Join(app.PDFLayouts(), ", ")+")")
+	exportPDFCmd.RegisterFlagCompletionFunc("layout", cobra.FixedCompletions(app.PDFLayouts(), cobra.ShellCompDirectiveNoFileComp))
+	exportPDFCmd.ValidArgsFunction = completePages
+
+	var exportManCmd = &cobra.Command{
+		Use:   "man <page>...",
+		Short: "Render pages as roff man pages",
+		Long: `Render a page as a man page on stdout, to view with 'man -l -', or write
+pages as <name>.1 files into the --out directory, e.g. ~/.local/share/man/man1
+to install them next to the system man pages.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			out, _ := cmd.Flags().GetString("out")
+			if err := app.ExportMan(args, out); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting pages: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	exportManCmd.Flags().StringP("out", "o", "", "Directory to write <name>.1 files to instead of stdout")
+	exportManCmd.ValidArgsFunction = completePages
+	exportCmd.AddCommand(exportHTMLCmd, exportPDFCmd, exportManCmd)
+
+	var randomCmd = &cobra.Command{
+		Use:   "random",
+		Short: "Show a random page to discover new commands",
+		Long: `Show a page picked at random, to discover tools you didn't know about. Use
+--platform to pick from some platforms only (comma-separated).`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.RandomPage(platformsFlag(cmd)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error picking a page: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var lastCmd = &cobra.Command{
+		Use:   "last",
+		Short: "Print or run the last command run again",
+		Long: `Recall the most recent command from the history. The values of placeholders
+listed in ask_every_time in the config, and of secrets, which the history
+doesn't keep, are asked for again; the others are reused. The command is
+printed (the default) or, with --exec, run.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			run, _ := cmd.Flags().GetBool("exec")
+			if err := app.Last(run); err != nil {
+				fmt.Fprintf(os.Stderr, "Error recalling the last command: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	lastCmd.Flags().Bool("print", false, "Print the command (the default)")
+	lastCmd.Flags().Bool("exec", false, "Run the command")
+	lastCmd.MarkFlagsMutuallyExclusive("print", "exec")
+
+	var doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose problems with the cache, config and environment",
+		Long: `Check the pages cache, the config file, the clipboard tool, the shell widget,
+the tools the submit plugin needs, the terminal's color support and whether
+every page source can be reached, printing how to fix each problem found.
+Exits with an error when a check fails; warnings only point out features
+that won't work.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.Doctor(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var benchCmd = &cobra.Command{
+		Use:   "bench",
+		Short: "Measure search and rendering speed on this machine",
+		Long: `Time loading the index, searching and rendering examples on this machine's
+cache, against the budgets tldr++ is held to. Include the output when
+reporting that tldr++ is slow.`,
+		Args:   cobra.NoArgs,
+		Hidden: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.Bench(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var whatsNewCmd = &cobra.Command{
+		Use:   "whatsnew",
+		Short: "List the pages the last update added or changed",
+		Long: `List the pages the last 'tldrpp update' added, changed and removed, to see
+what the tldr community has been writing. Use --platform to list some
+platforms only (comma-separated). With --mine only the pages you use are
+listed, those in your command history, usage stats and snippets, along
+with how each changed.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			mine, _ := cmd.Flags().GetBool("mine")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			if err := app.WhatsNew(platformsFlag(cmd), mine, asJSON); err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing changes: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	whatsNewCmd.Flags().Bool("mine", false, "Only list the pages you use, with their changes")
+	whatsNewCmd.Flags().Bool("json", false, "Print the changes as JSON")
+
+	var newCmd = &cobra.Command{
+		Use:   "new <name>",
+		Short: "Scaffold a custom page and open it in $EDITOR",
+		Long: `Create a page of your own from a template, in the tldr format. Custom pages
+live in ~/.config/tldrpp/pages/<platform>/<name>.md, show up alongside the
+downloaded pages and replace a downloaded page of the same name and platform.
+Press 'e' on a custom page in the TUI to edit it again.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			platform, _ := cmd.Flags().GetString("platform")
+			if err := app.NewPage(args[0], platform); err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating page: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var scanCmd = &cobra.Command{
+		Use:   "scan [tool...]",
+		Short: "List the tools on PATH that have no page",
+		Long: `List the programs on PATH that have no page, downloaded or custom, or only
+check the tools named. With --stub, draft a custom page for each named tool
+without one from its --help output, to review before sharing, and with
+--submit also start a submission of each draft to tldr-pages. Only named
+tools are run, as running every program on PATH with --help isn't safe.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			stub, _ := cmd.Flags().GetBool("stub")
+			submit, _ := cmd.Flags().GetBool("submit")
+			if err := app.Scan(app.ScanOptions{Tools: args, Stub: stub || submit, Submit: submit}); err != nil {
+				fmt.Fprintf(os.Stderr, "Error scanning tools: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	scanCmd.Flags().Bool("stub", false, "Draft a page for each named tool without one from its --help")
+	scanCmd.Flags().Bool("submit", false, "Draft pages and start submitting them to tldr-pages")
+
+	var packCmd = &cobra.Command{
+		Use:   "pack",
+		Short: "Manage page packs",
+		Long: `Page packs are collections of pages on a theme, such as kubernetes-ops or
+data-science, published by a team or community as a tarball with a pack.yml
+manifest and pages/<platform>/<name>.md. Their pages show up in search next
+to the downloaded ones, replacing those of the same name; your custom pages
+replace theirs. Commands from pack pages must be trusted once before exec
+runs them.`,
+	}
+
+	var packInstallCmd = &cobra.Command{
+		Use:   "install <url|path|name>",
+		Short: "Install or update a pack from a URL, a file, or by name from the pack index",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.PackInstall(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error installing pack: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var packListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the installed packs",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.PackList(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing packs: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	var packRemoveCmd = &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Uninstall a pack",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.PackRemove(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing pack: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	packCmd.AddCommand(packInstallCmd, packListCmd, packRemoveCmd)
+
+	// runSync returns the Run of a sync command going the given ways
+	runSync := func(push, pull bool) func(cmd *cobra.Command, args []string) {
+		return func(cmd *cobra.Command, args []string) {
+			force, _ := cmd.Flags().GetBool("force")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			if err := app.Sync(push, pull, force, dryRun); err != nil {
+				fmt.Fprintf(os.Stderr, "Error syncing: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	var syncCmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Share custom pages, snippets and placeholder memory between machines",
+		Long: `Push the custom pages, snippets and placeholder memory changed here since the
+last sync to the backend in the sync section of the config, a git remote, a
+WebDAV directory or an S3 bucket, and pull those changed on other machines.
+Files changed on both sides are left alone and reported; settle them with
+'tldrpp sync push --force' or 'tldrpp sync pull --force'.`,
+		Args: cobra.NoArgs,
+		Run:  runSync(true, true),
+	}
+	var syncPushCmd = &cobra.Command{
+		Use:   "push",
+		Short: "Only send the files changed here",
+		Args:  cobra.NoArgs,
+		Run:   runSync(true, false),
+	}
+	syncPushCmd.Flags().Bool("force", false, "Overwrite files changed on both sides with this machine's")
+	var syncPullCmd = &cobra.Command{
+		Use:   "pull",
+		Short: "Only fetch the files changed elsewhere",
+		Args:  cobra.NoArgs,
+		Run:   runSync(false, true),
+	}
+	syncPullCmd.Flags().Bool("force", false, "Overwrite files changed on both sides with the backend's")
+	syncCmd.PersistentFlags().Bool("dry-run", false, "List what would be sent without sending it")
+	syncCmd.AddCommand(syncPushCmd, syncPullCmd)
+
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.AddCommand(initCmd, updateCmd, cacheCmd, configCmd, renderCmd, execCmd, exportCmd, newCmd, scanCmd, packCmd, syncCmd, pickCmd, lastCmd, replayCmd, randomCmd, whatsNewCmd, doctorCmd, benchCmd, snippetCmd, workflowCmd, explainCmd, auditCmd, statsCmd, trustCmd, pluginCmd, shellInitCmd, newCompletionCmd(rootCmd))
 
 	// Default action: run the TUI
+	rootCmd.Flags().Bool("print", false, "Print the picked command instead of running it (used by shell-init)")
+	rootCmd.Args = cobra.ArbitraryArgs
+	rootCmd.ValidArgsFunction = completeCommand
 	rootCmd.Run = func(cmd *cobra.Command, args []string) {
 		platform, _ := cmd.Flags().GetString("platform")
 		theme, _ := cmd.Flags().GetString("theme")
 		dev, _ := cmd.Flags().GetBool("dev")
+		pick, _ := cmd.Flags().GetBool("print")
 
-		var searchQuery string
-		if len(args) > 0 {
-			searchQuery = args[0]
-		}
+		// Several words search for a command and its subcommand, e.g.
+		// tldrpp git commit
+		searchQuery := strings.Join(args, " ")
 
-		if err := app.RunTUI(searchQuery, platform, theme, dev); err != nil {
+		if err := app.RunTUI(searchQuery, platform, theme, dev, pick); err != nil {
 			fmt.Fprintf(os.Stderr, "Error running tldr++: %v\n", err)
 			os.Exit(1)
 		}
@@ -121,4 +1032,120 @@ then paste or execute the final command.`,
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
+
+// commandWithPositional accepts a command, possibly of several words such as
+// "git commit", optionally followed by "--" and positional placeholder values
+func commandWithPositional(cmd *cobra.Command, args []string) error {
+	if dash := cmd.ArgsLenAtDash(); dash == 0 || len(args) == 0 {
+		return fmt.Errorf("expected a command")
+	}
+	return nil
+}
+
+// commandArg returns the command given to render or exec, joining the words
+// before "--", and the positional values after it
+func commandArg(cmd *cobra.Command, args []string) (string, []string) {
+	dash := cmd.ArgsLenAtDash()
+	if dash == -1 {
+		dash = len(args)
+	}
+	return strings.Join(args[:dash], " "), args[dash:]
+}
+
+// snippetWithPositional accepts a snippet name and a command, optionally
+// followed by "--" and positional placeholder values
+func snippetWithPositional(cmd *cobra.Command, args []string) error {
+	dash := cmd.ArgsLenAtDash()
+	if dash == -1 {
+		return cobra.ExactArgs(2)(cmd, args)
+	}
+	if dash != 2 {
+		return fmt.Errorf("expected a name and a command before \"--\", got %d arguments", dash)
+	}
+	return nil
+}
+
+// exitIfInterrupted exits with the status a shell gives a command stopped
+// by Ctrl+C if err is due to one, or with the status of the command tldrpp
+// ran if a signal or its timeout stopped it
+func exitIfInterrupted(err error) {
+	var interrupted *app.InterruptedError
+	if errors.As(err, &interrupted) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(interrupted.ExitCode())
+	}
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "Interrupted")
+		os.Exit(130)
+	}
+}
+
+// addRenderFlags registers the flags shared by render and exec
+func addRenderFlags(cmd *cobra.Command) {
+	cmd.Flags().StringToString("vars", nil, "Variables to substitute in placeholders; N.name sets one for example N only")
+	cmd.Flags().Int("example", 0, "Select example by index (see --list-examples)")
+	cmd.Flags().String("match", "", "Select the first example whose description or command matches")
+	cmd.Flags().Bool("list-examples", false, "List the page's examples with their indices")
+	cmd.Flags().Bool("json", false, "With --list-examples, print the parsed page as JSON")
+	cmd.Flags().Bool("no-prompt", false, "Never prompt for missing placeholder values")
+	cmd.Flags().StringSlice("raw", nil, "Placeholders to substitute verbatim instead of shell-quoted")
+	cmd.MarkFlagsMutuallyExclusive("example", "match")
+}
+
+// renderOptions builds app.RenderOptions from the render/exec flags and the
+// positional values
+func renderOptions(cmd *cobra.Command, positional []string) app.RenderOptions {
+	vars, _ := cmd.Flags().GetStringToString("vars")
+	example, _ := cmd.Flags().GetInt("example")
+	match, _ := cmd.Flags().GetString("match")
+	noPrompt, _ := cmd.Flags().GetBool("no-prompt")
+	strict, _ := cmd.Flags().GetBool("strict")
+	raw, _ := cmd.Flags().GetStringSlice("raw")
+	return app.RenderOptions{
+		Vars:       vars,
+		Positional: positional,
+		Example:    example,
+		Match:      match,
+		NoPrompt:   noPrompt,
+		Strict:     strict,
+		Raw:        raw,
+	}
+}
+
+// addAuditFlags registers the filters shared by the audit subcommands
+func addAuditFlags(cmd *cobra.Command) {
+	cmd.Flags().String("since", "", "Only executions since a duration ago (24h, 7d) or a date")
+	cmd.Flags().String("page", "", "Only executions of commands from this page")
+	cmd.Flags().Bool("failed", false, "Only executions that exited with an error")
+	cmd.RegisterFlagCompletionFunc("page", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return app.CompletePages(toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// auditOptions builds app.AuditOptions from the audit flags
+func auditOptions(cmd *cobra.Command) app.AuditOptions {
+	since, _ := cmd.Flags().GetString("since")
+	page, _ := cmd.Flags().GetString("page")
+	failed, _ := cmd.Flags().GetBool("failed")
+	limit, _ := cmd.Flags().GetInt("limit")
+	return app.AuditOptions{
+		Since:  since,
+		Page:   page,
+		Failed: failed,
+		Limit:  limit,
+	}
+}
+
+// platformsFlag returns the platforms given with --platform, which may list
+// several separated by commas
+func platformsFlag(cmd *cobra.Command) []string {
+	value, _ := cmd.Flags().GetString("platform")
+	var platforms []string
+	for _, platform := range strings.Split(value, ",") {
+		if platform = strings.TrimSpace(platform); platform != "" {
+			platforms = append(platforms, platform)
+		}
+	}
+	return platforms
+}
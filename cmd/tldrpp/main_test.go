@@ -0,0 +1,150 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// runMainEnv makes the test binary run tldrpp instead of the tests, so the
+// tests can run the CLI as users do
+const runMainEnv = "TLDRPP_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+var e2ePages = map[string]string{
+	"pages/common/tar.md":   "# tar\n\n> Archiving utility.\n\n- Create an archive from files:\n\n`tar cf {{target.tar}} {{file1 file2 ...}}`\n",
+	"pages/common/touch.md": "# touch\n\n> Create files.\n\n- Create a file:\n\n`touch {{path/to/file}}`\n",
+}
+
+// e2e runs tldrpp hermetically: in a home of its own, from pages in a local
+// archive, with a HOME that must stay empty
+type e2e struct {
+	t    *testing.T
+	home string
+	// userHome is HOME, which nothing may be written to
+	userHome string
+}
+
+func newE2E(t *testing.T) *e2e {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the pages are run with sh")
+	}
+	root := t.TempDir()
+	e := &e2e{t: t, home: filepath.Join(root, "tldrpp"), userHome: filepath.Join(root, "user")}
+	if err := os.MkdirAll(e.userHome, 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+
+	archive := filepath.Join(root, "pages.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	w := zip.NewWriter(f)
+	for name, content := range e2ePages {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("zip Create failed: %v", err)
+		}
+		entry.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("zip Close failed: %v", err)
+	}
+	f.Close()
+
+	config := "sources:\n  - name: e2e\n    url: file://" + filepath.ToSlash(archive) + "\n    trusted: true\n"
+	if err := os.MkdirAll(filepath.Join(e.home, "config"), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(e.home, "config", "config.yml"), []byte(config), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return e
+}
+
+// run runs tldrpp with args and the environment variables in env, and
+// returns what it printed to stdout
+func (e *e2e) run(env []string, args ...string) string {
+	e.t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(),
+		runMainEnv+"=1",
+		"HOME="+e.userHome,
+		"XDG_CONFIG_HOME=",
+		"XDG_CACHE_HOME=",
+		"XDG_DATA_HOME=",
+		"TLDRPP_HOME=",
+	)
+	cmd.Env = append(cmd.Env, env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		e.t.Fatalf("tldrpp %s failed: %v\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+	return stdout.String()
+}
+
+// checkUserHome fails the test if anything was written to HOME
+func (e *e2e) checkUserHome() {
+	e.t.Helper()
+	entries, err := os.ReadDir(e.userHome)
+	if err != nil {
+		e.t.Fatalf("ReadDir failed: %v", err)
+	}
+	for _, entry := range entries {
+		e.t.Errorf("Expected HOME to stay empty, found %s", entry.Name())
+	}
+}
+
+func TestHomeEnv(t *testing.T) {
+	e := newE2E(t)
+	env := []string{"TLDRPP_HOME=" + e.home}
+
+	out := e.run(env, "render", "tar", "--no-prompt", "--vars", "target.tar=out.tar,file=a.txt")
+	if strings.TrimSpace(out) != "tar cf out.tar a.txt" {
+		t.Errorf("Expected the rendered command, got %q", out)
+	}
+
+	created := filepath.Join(t.TempDir(), "created")
+	e.run(env, "exec", "touch", "--no-prompt", "--", created)
+	if _, err := os.Stat(created); err != nil {
+		t.Errorf("Expected exec to run the command: %v", err)
+	}
+
+	for _, path := range []string{
+		filepath.Join(e.home, "cache", "index.json"),
+		filepath.Join(e.home, "data", "executions.json"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s in the home: %v", path, err)
+		}
+	}
+	e.checkUserHome()
+}
+
+func TestHomeFlag(t *testing.T) {
+	e := newE2E(t)
+
+	out := e.run(nil, "--home", e.home, "render", "touch", "--no-prompt", "--", "new.txt")
+	if strings.TrimSpace(out) != "touch new.txt" {
+		t.Errorf("Expected the rendered command, got %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(e.home, "cache", "index.json")); err != nil {
+		t.Errorf("Expected the cache in the home: %v", err)
+	}
+	e.checkUserHome()
+}
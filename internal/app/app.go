@@ -1,42 +1,63 @@
 package app
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/makalin/tldrpp/internal/cache"
 	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/log"
 	"github.com/makalin/tldrpp/internal/tui"
-	"github.com/spf13/viper"
+	"github.com/makalin/tldrpp/internal/types"
 )
 
-// Initialize downloads the tldr pages index and sets up the cache
-func Initialize() error {
+// Initialize downloads the tldr pages index and sets up the cache, giving up
+// when ctx is done
+func Initialize(ctx context.Context) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	cacheManager := cache.New(cfg.CacheDir)
-	return cacheManager.Initialize()
+	cacheManager := newCacheManager(cfg)
+	return initializeCache(ctx, cacheManager)
 }
 
-// UpdateCache refreshes the tldr pages cache
-func UpdateCache() error {
+// UpdateCache refreshes the tldr pages cache. With ifStale set, a cache
+// younger than the configured TTL is left alone. A version, or "latest",
+// overrides the pages_version pin for this update. It reports whether the
+// cache was updated. The download stops when ctx is done, leaving the
+// cache as it was.
+func UpdateCache(ctx context.Context, ifStale bool, version string) (bool, error) {
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return false, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cacheManager := newCacheManager(cfg)
+	if version != "" {
+		cacheManager.SetPin(version)
+	}
+	if ifStale && !cacheManager.IsStale(cfg.CacheTTL()) {
+		return false, nil
 	}
 
-	cacheManager := cache.New(cfg.CacheDir)
-	return cacheManager.Update()
+	err = withProgress(func(progress cache.ProgressFunc) error {
+		return cacheManager.UpdateContext(ctx, progress)
+	})
+	return err == nil, err
 }
 
-// RunTUI starts the terminal user interface
-func RunTUI(searchQuery, platform, theme string, dev bool) error {
+// RunTUI starts the terminal user interface. With pick set, searchQuery is
+// a half-typed command line, and the command picked in the TUI is printed
+// instead of run, for the shell widget.
+func RunTUI(searchQuery, platform, theme string, dev, pick bool) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -53,102 +74,325 @@ func RunTUI(searchQuery, platform, theme string, dev bool) error {
 		cfg.DevMode = true
 	}
 
-	cacheManager := cache.New(cfg.CacheDir)
-	
-	// Ensure cache is initialized
-	if !cacheManager.IsInitialized() {
-		if err := cacheManager.Initialize(); err != nil {
-			return fmt.Errorf("failed to initialize cache: %w", err)
+	// The TUI initializes an empty cache itself, showing progress
+	cacheManager := newCacheManager(cfg)
+
+	executions, err := history.LoadLog(executionLogPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	app := tui.New(cfg, cacheManager, executions)
+	app.SetClock(appClock)
+	app.SetChecker(func(execution history.Execution) ([]tui.Confirmation, error) {
+		return commandConfirmations(cfg, execution)
+	})
+	app.SetRunner(func(execution history.Execution, output io.Writer) (bool, error) {
+		return true, runConfirmed(context.Background(), cfg, executions, execution, output)
+	})
+	restoring := cfg.RestoreSession && !pick
+	if restoring {
+		restoreSession(app, cfg, cacheManager, platform != "")
+	}
+	if pick {
+		app.EnablePick()
+		searchQuery = commandLineQuery(cacheManager, searchQuery)
+	} else if strings.Contains(searchQuery, " ") && cacheManager.IsInitialized() {
+		// "tldrpp git commit" searches for the git-commit page
+		if name := cacheManager.PageName(searchQuery); name != "" {
+			searchQuery = name
 		}
 	}
+	if err := app.Run(searchQuery); err != nil {
+		return err
+	}
+	if restoring {
+		saveSession(app)
+	}
+
+	if pick {
+		if picked := app.Picked(); picked != "" {
+			fmt.Println(picked)
+		}
+		return nil
+	}
+
+	// A command picked from the start screen runs once the TUI has exited
+	if execution := app.Rerun(); execution != nil {
+		recalled, err := recall(cfg, *execution)
+		if err != nil {
+			return err
+		}
+		return runCommand(context.Background(), cfg, executions, recalled)
+	}
+	if s := app.RerunSnippet(); s != nil {
+		execution, err := snippetExecution(cfg, s)
+		if err != nil {
+			return err
+		}
+		return runCommand(context.Background(), cfg, executions, execution)
+	}
+	return nil
+}
 
-	app := tui.New(cfg, cacheManager)
-	return app.Run(searchQuery)
+// RenderOptions controls which example is rendered and how its placeholders
+// are filled
+type RenderOptions struct {
+	// Vars holds named placeholder values
+	Vars map[string]string
+	// Positional values are bound to the placeholders not named in Vars, in order
+	Positional []string
+	// Example selects an example by 1-based index; 0 means no preference
+	Example int
+	// Match selects the first example whose description or command contains it
+	Match string
+	// NoPrompt disables asking for missing placeholder values on a terminal
+	NoPrompt bool
+	// Strict fails instead of leaving placeholders unresolved
+	Strict bool
+	// Raw names placeholders to substitute verbatim instead of shell-quoted
+	Raw []string
+	// VarsFlag names the flag Vars come from in error messages; --vars if
+	// empty
+	VarsFlag string
 }
 
 // RenderCommand renders a command with placeholders filled
-func RenderCommand(command string, vars map[string]string) error {
-	cfg, err := config.Load()
+func RenderCommand(command string, opts RenderOptions) error {
+	_, execution, err := renderCommandLine(context.Background(), command, opts)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
+	fmt.Println(execution.Command)
+	return nil
+}
 
-	cacheManager := cache.New(cfg.CacheDir)
-	if !cacheManager.IsInitialized() {
-		if err := cacheManager.Initialize(); err != nil {
-			return fmt.Errorf("failed to initialize cache: %w", err)
-		}
+// ListExamples prints the examples of a command's page with their indices,
+// or the whole parsed page as JSON. Examples are shown filled with the
+// values in vars that apply to them.
+func ListExamples(command string, asJSON bool, vars map[string]string) error {
+	cfg, cacheManager, err := loadConfigAndCache()
+	if err != nil {
+		return err
 	}
 
 	page, err := cacheManager.FindPage(command)
 	if err != nil {
 		return fmt.Errorf("command not found: %w", err)
 	}
+	recordView(cfg, page)
 
-	// Find the best matching example
-	example := page.FindBestExample(command)
-	if example == nil {
-		return fmt.Errorf("no suitable example found for command: %s", command)
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(page)
 	}
 
-	// Render the command with variables
-	rendered := example.Render(vars)
-	fmt.Println(rendered)
+	printExamples(page, vars)
+	if subcommands := cacheManager.Subcommands(page.Name); len(subcommands) > 0 {
+		fmt.Printf("\nSubcommands: %s\n", strings.Join(subcommands, ", "))
+	}
 	return nil
 }
 
-// ExecuteCommand executes a command with placeholders filled
-func ExecuteCommand(command string, vars map[string]string) error {
-	cfg, err := config.Load()
+// printExamples prints a page's examples with their indices, filled with
+// the values in vars that apply to them
+func printExamples(page *types.Page, vars map[string]string) {
+	for i, example := range page.Examples {
+		fmt.Printf("%3d  %s\n     %s\n", i+1, example.Description, example.Render(types.ScopeVars(vars, i+1)))
+	}
+}
+
+// RandomPage shows a page picked at random from the given platforms, or the
+// configured ones, to discover commands
+func RandomPage(platforms []string) error {
+	cfg, cacheManager, err := loadConfigAndCache()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
+	}
+	if len(platforms) == 0 {
+		platforms = cfg.Platforms
 	}
 
-	cacheManager := cache.New(cfg.CacheDir)
-	if !cacheManager.IsInitialized() {
-		if err := cacheManager.Initialize(); err != nil {
-			return fmt.Errorf("failed to initialize cache: %w", err)
+	entry, err := cacheManager.RandomPage(platforms)
+	if err != nil {
+		return err
+	}
+	page, err := cacheManager.LoadPage(entry)
+	if err != nil {
+		return err
+	}
+	recordView(cfg, page)
+
+	fmt.Printf("%s%s (%s)\n", platformBadge(page.Platform), page.Name, page.Platform)
+	if page.Description != "" {
+		fmt.Printf("%s\n", page.Description)
+	}
+	if page.MoreInfoURL != "" {
+		fmt.Printf("More information: %s\n", hyperlink(page.MoreInfoURL, page.MoreInfoURL))
+	}
+	fmt.Println()
+	printExamples(page, nil)
+	return nil
+}
+
+// ExecOptions override the exec settings of the config for one command
+type ExecOptions struct {
+	// Timeout overrides exec.timeout when not 0
+	Timeout time.Duration
+	// Record saves a cast of the command's output even without exec.record
+	Record bool
+	// DryRun prints the command and the environment it would run with
+	// instead of running it
+	DryRun bool
+	// Dir runs the command in this directory instead of the current one
+	Dir string
+	// Sudo runs the command as root with sudo
+	Sudo bool
+}
+
+// apply overrides the exec settings of cfg, and the directory execution
+// runs in, with those set in o
+func (o ExecOptions) apply(cfg *config.Config, execution *history.Execution) error {
+	if o.Timeout > 0 {
+		cfg.Exec.Timeout = o.Timeout.String()
+	}
+	if o.Record {
+		cfg.Exec.Record = true
+	}
+	if o.Sudo {
+		execution.Sudo = true
+	}
+	if o.Dir != "" {
+		dir, err := commandDir(o.Dir)
+		if err != nil {
+			return err
 		}
+		execution.Dir = dir
 	}
+	return nil
+}
 
-	page, err := cacheManager.FindPage(command)
+// ExecuteCommand executes a command with placeholders filled. When ctx is
+// done, a download of the pages stops and a running command is
+// interrupted.
+func ExecuteCommand(ctx context.Context, command string, opts RenderOptions, exec ExecOptions) error {
+	cfg, execution, err := renderCommandLine(ctx, command, opts)
 	if err != nil {
-		return fmt.Errorf("command not found: %w", err)
+		return err
+	}
+	if err := exec.apply(cfg, &execution); err != nil {
+		return err
+	}
+	if exec.DryRun {
+		return printDryRun(os.Stdout, cfg, execution)
 	}
 
-	// Find the best matching example
-	example := page.FindBestExample(command)
-	if example == nil {
-		return fmt.Errorf("no suitable example found for command: %s", command)
+	executions, err := history.LoadLog(executionLogPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 	}
 
-	// Render the command with variables
-	rendered := example.Render(vars)
-	
-	// Check if command is destructive
-	if isDestructiveCommand(rendered) && cfg.ConfirmDestructive {
-		fmt.Printf("This command appears destructive: %s\n", rendered)
-		fmt.Print("Are you sure you want to execute it? (y/N): ")
-		var response string
-		fmt.Scanln(&response)
-		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
-			fmt.Println("Command cancelled.")
-			return nil
-		}
+	err = runCommand(ctx, cfg, executions, execution)
+	suggestSudo(execution, err)
+	return err
+}
+
+// runCommand runs a rendered command after confirming commands from
+// untrusted pages and destructive ones, and records it in the execution
+// history. The command is interrupted when ctx is done.
+func runCommand(ctx context.Context, cfg *config.Config, executions *history.Log, execution history.Execution) error {
+	_, err := runCommandTo(ctx, cfg, executions, execution, nil)
+	return err
+}
+
+// runCommandTo is runCommand also copying the command's output to output,
+// if not nil. It reports whether the command ran or was cancelled.
+func runCommandTo(ctx context.Context, cfg *config.Config, executions *history.Log, execution history.Execution, output io.Writer) (bool, error) {
+	allowed, err := confirmTrust(cfg, execution)
+	if err != nil {
+		return false, err
+	}
+	if !allowed {
+		fmt.Println("Command cancelled.")
+		return false, nil
 	}
 
-	// Execute the command
-	cmd := exec.Command("sh", "-c", rendered)
+	if confirmation := destructiveConfirmation(cfg, execution.Command); confirmation != nil && !askConfirmation(*confirmation) {
+		fmt.Println("Command cancelled.")
+		return false, nil
+	}
+	return true, runConfirmed(ctx, cfg, executions, execution, output)
+}
+
+// runConfirmed runs the command of execution, already confirmed, copying
+// its output to output if not nil, and records it
+func runConfirmed(ctx context.Context, cfg *config.Config, executions *history.Log, execution history.Execution, output io.Writer) error {
+	env, err := commandEnv(cfg, execution)
+	if err != nil {
+		return err
+	}
+	// Execute the command, as root if asked to
+	command, env, err := escalated(execution, env)
+	if err != nil {
+		return err
+	}
+	cmd, err := shellCommand(cfg.Exec, command)
+	if err != nil {
+		return err
+	}
+	if len(env) > 0 {
+		// Later entries win over the inherited ones of the same name
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if execution.Dir != "" {
+		if cmd.Dir, err = commandDir(execution.Dir); err != nil {
+			return err
+		}
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
+	if output != nil {
+		cmd.Stdout = io.MultiWriter(os.Stdout, output)
+		cmd.Stderr = io.MultiWriter(os.Stderr, output)
+	}
 
-	// Log the execution
-	if err := logExecution(rendered); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to log execution: %v\n", err)
+	// History and the audit log only get the command with secrets redacted
+	execution.Time = appClock.Now()
+	logged := execution
+	logged.Command, logged.Vars, logged.Redacted = types.Redact(execution.Command, execution.Template, execution.Vars)
+
+	var rec *recording
+	if cfg.Exec.Record {
+		// Recording pipes the output, like capturing it for the TUI does
+		if rec, err = startRecording(logged); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: not recording: %v\n", err)
+		} else {
+			logged.Cast = rec.path
+			cmd.Stdout = io.MultiWriter(cmd.Stdout, rec.recorder)
+			cmd.Stderr = io.MultiWriter(cmd.Stderr, rec.recorder)
+		}
+	}
+
+	executions.Add(logged)
+	if err := executions.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save execution history: %v\n", err)
+	}
+
+	err = runProcess(ctx, cmd, cfg.ExecTimeout())
+	if rec != nil {
+		if recErr := rec.finish(); recErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record command: %v\n", recErr)
+		}
 	}
+	log.Info("ran command", "page", logged.Page, "command", logged.Command, "err", err, "duration", appClock.Now().Sub(execution.Time))
 
-	return cmd.Run()
+	// Log the execution
+	if auditErr := recordExecution(logged, err, appClock.Now().Sub(execution.Time)); auditErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to log execution: %v\n", auditErr)
+	}
+	return err
 }
 
 // SubmitToTldr opens the plugin for submitting examples to tldr-pages
@@ -158,9 +402,9 @@ func SubmitToTldr() error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	cacheManager := cache.New(cfg.CacheDir)
+	cacheManager := newCacheManager(cfg)
 	if !cacheManager.IsInitialized() {
-		if err := cacheManager.Initialize(); err != nil {
+		if err := initializeCache(context.Background(), cacheManager); err != nil {
 			return fmt.Errorf("failed to initialize cache: %w", err)
 		}
 	}
@@ -171,6 +415,163 @@ func SubmitToTldr() error {
 	return nil
 }
 
+// loadCache loads the config and returns an initialized cache manager
+func loadCache() (*cache.Manager, error) {
+	_, cacheManager, err := loadConfigAndCache()
+	return cacheManager, err
+}
+
+// loadConfigAndCache loads the config and an initialized cache manager
+func loadConfigAndCache() (*config.Config, *cache.Manager, error) {
+	return loadConfigAndCacheContext(context.Background())
+}
+
+// loadConfigAndCacheContext is like loadConfigAndCache but an initial
+// download of the pages stops when ctx is done
+func loadConfigAndCacheContext(ctx context.Context) (*config.Config, *cache.Manager, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cacheManager := newCacheManager(cfg)
+	if !cacheManager.IsInitialized() {
+		if err := initializeCache(ctx, cacheManager); err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize cache: %w", err)
+		}
+	}
+
+	return cfg, cacheManager, nil
+}
+
+// loadConfigAndStore is like loadConfigAndCacheContext but opens the cache
+// with openStore
+func loadConfigAndStore(ctx context.Context) (*config.Config, CacheStore, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store := openStore(cfg)
+	if !store.IsInitialized() {
+		if err := initializeCache(ctx, store); err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize cache: %w", err)
+		}
+	}
+
+	return cfg, store, nil
+}
+
+// resolveExample finds the page for command and picks the example selected
+// by opts, falling back to the best match for the command
+func resolveExample(ctx context.Context, command string, opts RenderOptions) (*config.Config, *types.Page, *types.Example, error) {
+	if opts.Example < 0 {
+		return nil, nil, nil, fmt.Errorf("--example must be 1 or more, got %d", opts.Example)
+	}
+	cfg, cacheManager, err := loadConfigAndStore(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	page, rest, err := cacheManager.ResolvePage(command)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("command not found: %w", err)
+	}
+
+	var example *types.Example
+	switch {
+	case opts.Example > 0:
+		example, err = page.ExampleAt(opts.Example)
+	case opts.Match != "":
+		example, err = page.MatchExample(opts.Match)
+	default:
+		// Find the best matching example for the words after the page's
+		// name, e.g. "extract" in "tar extract"
+		query := command
+		if rest != "" {
+			query = rest
+		}
+		example = page.FindBestExample(query)
+		if example == nil {
+			err = fmt.Errorf("no suitable example found for command: %s", command)
+		}
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return cfg, page, example, nil
+}
+
+// fillVars binds positional values and, on a terminal, prompts for whatever
+// placeholders are still missing. The final values are remembered.
+func fillVars(cfg *config.Config, example *types.Example, opts RenderOptions) (map[string]string, error) {
+	vars, err := example.BindPositional(opts.Vars, opts.Positional)
+	if err != nil {
+		return nil, err
+	}
+	vars, err = resolveSecrets(cfg, example, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	memory, err := history.Load(placeholderMemoryPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if !opts.NoPrompt && isInteractive() {
+		vars, err = promptMissing(os.Stdin, os.Stderr, cfg, example, vars, memory)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rememberValues(cfg, memory, example, vars)
+	return vars, nil
+}
+
+// renderExample renders example with vars. In strict mode unresolved
+// placeholders are an error; otherwise they are kept visible and reported.
+func renderExample(example *types.Example, vars map[string]string, opts RenderOptions) (string, error) {
+	if len(opts.Raw) > 0 {
+		example = withRawPlaceholders(example, opts.Raw)
+	}
+
+	if opts.Strict {
+		rendered, err := example.RenderStrict(vars)
+		if err != nil {
+			flag := opts.VarsFlag
+			if flag == "" {
+				flag = "vars"
+			}
+			return "", fmt.Errorf("%w (fill them with --%s or positional values)", err, flag)
+		}
+		return rendered, nil
+	}
+
+	if _, err := example.RenderStrict(vars); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return example.Render(vars), nil
+}
+
+// withRawPlaceholders returns a copy of example with the named placeholders
+// marked raw
+func withRawPlaceholders(example *types.Example, names []string) *types.Example {
+	copied := *example
+	copied.Placeholders = make([]types.Placeholder, len(example.Placeholders))
+	for i, placeholder := range example.Placeholders {
+		for _, name := range names {
+			if placeholder.Name == name {
+				placeholder.Raw = true
+			}
+		}
+		copied.Placeholders[i] = placeholder
+	}
+	return &copied
+}
+
 // isDestructiveCommand checks if a command is potentially destructive
 func isDestructiveCommand(command string) bool {
 	destructiveVerbs := []string{
@@ -193,27 +594,4 @@ func isDestructiveCommand(command string) bool {
 		}
 	}
 	return false
-}
-
-// logExecution logs command execution to audit log
-func logExecution(command string) error {
-	cfg, err := config.Load()
-	if err != nil {
-		return err
-	}
-
-	logDir := filepath.Join(cfg.CacheDir, "..")
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return err
-	}
-
-	logFile := filepath.Join(logDir, "exec.log")
-	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	_, err = fmt.Fprintf(f, "%s: %s\n", viper.GetString("timestamp"), command)
-	return err
 }
\ No newline at end of file
@@ -0,0 +1,376 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/audit"
+	"github.com/makalin/tldrpp/internal/cache/cachetest"
+	"github.com/makalin/tldrpp/internal/clock"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/snippet"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// testNow is the time the fake clock of the tests tells
+var testNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+var testPages = map[string]string{
+	"tar": "# tar\n\n> Archiving utility.\n\n" +
+		"- Create an archive from files:\n\n`tar cf {{target.tar}} {{file1 file2 ...}}`\n\n" +
+		"- Extract an archive:\n\n`tar xf {{source.tar}}`\n",
+	"touch":      "# touch\n\n> Create files.\n\n- Create a file:\n\n`touch {{path/to/file}}`\n",
+	"git-commit": "# git commit\n\n> Commit files.\n\n- Commit staged files with a message:\n\n`git commit --message \"{{message}}\"`\n",
+}
+
+// useStore has the tests run over an in-memory cache of testPages and a
+// fake clock, in an empty home directory
+func useStore(t *testing.T) (*cachetest.Store, *clock.Fake) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	var pages []*types.Page
+	for name, content := range testPages {
+		page, err := types.ParsePage(content, types.IndexEntry{Name: name, Platform: "common"})
+		if err != nil {
+			t.Fatalf("ParsePage failed: %v", err)
+		}
+		pages = append(pages, page)
+	}
+	now := clock.NewFake(testNow)
+	store := cachetest.New(now, pages...)
+
+	oldStore, oldClock := openStore, appClock
+	openStore = func(*config.Config) CacheStore { return store }
+	appClock = now
+	t.Cleanup(func() {
+		openStore, appClock = oldStore, oldClock
+	})
+	return store, now
+}
+
+func TestRenderCommandLine(t *testing.T) {
+	useStore(t)
+
+	tests := []struct {
+		command string
+		opts    RenderOptions
+		want    string
+	}{
+		{"tar", RenderOptions{Vars: map[string]string{"target.tar": "out.tar", "file": "a.txt"}}, "tar cf out.tar a.txt"},
+		{"tar extract", RenderOptions{Vars: map[string]string{"source.tar": "in.tar"}}, "tar xf in.tar"},
+		{"tar", RenderOptions{Example: 2, Positional: []string{"in.tar"}}, "tar xf in.tar"},
+		{"git commit", RenderOptions{Vars: map[string]string{"message": "Fix"}}, `git commit --message "Fix"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			tt.opts.NoPrompt = true
+			_, execution, err := renderCommandLine(context.Background(), tt.command, tt.opts)
+			if err != nil {
+				t.Fatalf("renderCommandLine failed: %v", err)
+			}
+			if execution.Command != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, execution.Command)
+			}
+		})
+	}
+}
+
+func TestRenderCommandLineStrict(t *testing.T) {
+	useStore(t)
+
+	_, _, err := renderCommandLine(context.Background(), "touch", RenderOptions{NoPrompt: true, Strict: true})
+	if err == nil || !strings.Contains(err.Error(), "--vars") {
+		t.Errorf("Expected an error about the missing value, got %v", err)
+	}
+	_, _, err = renderCommandLine(context.Background(), "nosuchpage", RenderOptions{NoPrompt: true})
+	if err == nil || !strings.Contains(err.Error(), "command not found") {
+		t.Errorf("Expected a missing page to be an error, got %v", err)
+	}
+	_, _, err = renderCommandLine(context.Background(), "tar", RenderOptions{NoPrompt: true, Example: -1})
+	if err == nil || !strings.Contains(err.Error(), "--example") {
+		t.Errorf("Expected a negative example to be an error, got %v", err)
+	}
+}
+
+func TestExecuteCommand(t *testing.T) {
+	useStore(t)
+	file := filepath.Join(t.TempDir(), "created")
+
+	opts := RenderOptions{Positional: []string{file}, NoPrompt: true}
+	if err := ExecuteCommand(context.Background(), "touch", opts, ExecOptions{}); err != nil {
+		t.Fatalf("ExecuteCommand failed: %v", err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("Expected the command to create %s: %v", file, err)
+	}
+
+	executions, err := history.LoadLog(executionLogPath())
+	if err != nil {
+		t.Fatalf("LoadLog failed: %v", err)
+	}
+	recent := executions.Recent(1)
+	if len(recent) != 1 || recent[0].Page != "touch" || !recent[0].Time.Equal(testNow) {
+		t.Errorf("Expected the run to be recorded at %v, got %+v", testNow, recent)
+	}
+}
+
+func TestExecuteCommandDir(t *testing.T) {
+	useStore(t)
+	dir := t.TempDir()
+
+	opts := RenderOptions{Positional: []string{"created"}, NoPrompt: true}
+	if err := ExecuteCommand(context.Background(), "touch", opts, ExecOptions{Dir: dir}); err != nil {
+		t.Fatalf("ExecuteCommand failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "created")); err != nil {
+		t.Errorf("Expected the command to run in %s: %v", dir, err)
+	}
+	executions, err := history.LoadLog(executionLogPath())
+	if err != nil {
+		t.Fatalf("LoadLog failed: %v", err)
+	}
+	if dirs := executions.RecentDirs(1); len(dirs) != 1 || dirs[0] != dir {
+		t.Errorf("Expected %s among the recent directories, got %v", dir, dirs)
+	}
+
+	err = ExecuteCommand(context.Background(), "touch", opts, ExecOptions{Dir: filepath.Join(dir, "created")})
+	if err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("Expected an error running in a file, got %v", err)
+	}
+}
+
+func TestExecuteCommandUntrusted(t *testing.T) {
+	store, _ := useStore(t)
+	store.Source, store.Trusted = "mirror", false
+	file := filepath.Join(t.TempDir(), "created")
+
+	opts := RenderOptions{Positional: []string{file}, NoPrompt: true}
+	err := ExecuteCommand(context.Background(), "touch", opts, ExecOptions{})
+	if err == nil || !strings.Contains(err.Error(), "tldrpp trust add touch") {
+		t.Errorf("Expected a page from an untrusted source not to run, got %v", err)
+	}
+	if _, err := os.Stat(file); err == nil {
+		t.Error("Expected the command not to run")
+	}
+}
+
+func TestRunPulledSnippet(t *testing.T) {
+	useStore(t)
+	file := filepath.Join(t.TempDir(), "created")
+	s := &snippet.Snippet{Name: "mk", Page: "touch", Platform: "common", Command: "touch " + types.ShellQuote(file)}
+	if err := snippet.NewStore(config.SnippetsDir()).Save(s, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	custom := filepath.Join(config.CustomPagesDir(), "common", "touch.md")
+	if err := os.MkdirAll(filepath.Dir(custom), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(custom, []byte(testPages["touch"]), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := markPulled([]string{"snippets/mk.yml", "pages/common/touch.md", "placeholders.json"}); err != nil {
+		t.Fatalf("markPulled failed: %v", err)
+	}
+
+	err := RunSnippet("mk", ExecOptions{})
+	if err == nil || !strings.Contains(err.Error(), "tldrpp trust add --snippet mk") {
+		t.Errorf("Expected a pulled snippet not to run, got %v", err)
+	}
+	if err := TrustAddSnippet("mk"); err != nil {
+		t.Fatalf("TrustAddSnippet failed: %v", err)
+	}
+	// Its page was pulled too
+	err = RunSnippet("mk", ExecOptions{})
+	if err == nil || !strings.Contains(err.Error(), "tldrpp trust add touch") {
+		t.Errorf("Expected the command of a pulled page not to run, got %v", err)
+	}
+	if _, err := os.Stat(file); err == nil {
+		t.Fatal("Expected the command not to run")
+	}
+
+	confirmation, _, err := trustConfirmation(&config.Config{}, history.Execution{Page: "touch", Platform: "common"})
+	if err != nil || confirmation == nil {
+		t.Fatalf("Expected a confirmation for the pulled page, got %v, %v", confirmation, err)
+	}
+	if err := confirmation.Accept(); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	if err := RunSnippet("mk", ExecOptions{}); err != nil {
+		t.Fatalf("Expected the trusted snippet to run, got %v", err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("Expected the command to run: %v", err)
+	}
+}
+
+func TestStubNeedsTrust(t *testing.T) {
+	useStore(t)
+	if _, err := writeStub("true"); err != nil {
+		t.Fatalf("writeStub failed: %v", err)
+	}
+	confirmation, hint, err := trustConfirmation(&config.Config{}, history.Execution{Page: "true", Platform: "common", Command: "true"})
+	if err != nil || confirmation == nil {
+		t.Fatalf("Expected a confirmation for a drafted page, got %v, %v", confirmation, err)
+	}
+	if !strings.Contains(hint, "--help output") {
+		t.Errorf("Expected the hint to tell the page was drafted, got %q", hint)
+	}
+}
+
+func TestStaleStoreInitialized(t *testing.T) {
+	store, now := useStore(t)
+	if store.IsStale(time.Hour) {
+		t.Fatal("Expected a fresh store not to be stale")
+	}
+	now.Advance(2 * time.Hour)
+	if !store.IsStale(time.Hour) {
+		t.Fatal("Expected a store older than the TTL to be stale")
+	}
+
+	empty := cachetest.NewEmpty(now, store.Upstream...)
+	openStore = func(*config.Config) CacheStore { return empty }
+	if _, _, err := loadConfigAndStore(context.Background()); err != nil {
+		t.Fatalf("loadConfigAndStore failed: %v", err)
+	}
+	if empty.Updates() != 1 || empty.IsStale(time.Hour) {
+		t.Errorf("Expected an empty cache to be filled once, updated %d times", empty.Updates())
+	}
+}
+
+func TestShellCommand(t *testing.T) {
+	cmd, err := shellCommand(config.Exec{Shell: "bash -o pipefail -c"}, "ls | wc -l")
+	if err != nil {
+		t.Fatalf("shellCommand failed: %v", err)
+	}
+	want := []string{"bash", "-o", "pipefail", "-c", "ls | wc -l"}
+	if strings.Join(cmd.Args, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got %q", want, cmd.Args)
+	}
+
+	if _, err := shellCommand(config.Exec{Shell: `bash "-c`}, "ls"); err == nil {
+		t.Error("Expected an error for an invalid exec.shell")
+	}
+}
+
+func TestExecuteCommandRecord(t *testing.T) {
+	useStore(t)
+	file := filepath.Join(t.TempDir(), "created")
+
+	opts := RenderOptions{Positional: []string{file}, NoPrompt: true}
+	if err := ExecuteCommand(context.Background(), "touch", opts, ExecOptions{Record: true}); err != nil {
+		t.Fatalf("ExecuteCommand failed: %v", err)
+	}
+	executions, err := recorded()
+	if err != nil {
+		t.Fatalf("recorded failed: %v", err)
+	}
+	if len(executions) != 1 || !strings.HasPrefix(executions[0].Cast, castDir()) {
+		t.Fatalf("Expected the run to be recorded in %s, got %+v", castDir(), executions)
+	}
+	if err := Replay(context.Background(), 1, "", 0); err != nil {
+		t.Errorf("Replay failed: %v", err)
+	}
+	if err := Replay(context.Background(), 2, "", 0); err == nil {
+		t.Error("Expected an error replaying a recording that doesn't exist")
+	}
+}
+
+func TestCommandEnv(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("REGION", "eu")
+	cfg := &config.Config{PageEnv: map[string][]string{
+		"kubectl": {"KUBECONFIG=~/.kube/staging", "CLUSTER=$REGION-1"},
+	}}
+	execution := history.Execution{Page: "kubectl", Env: []string{"CLUSTER=$CLUSTER-b", "DEBUG=1"}}
+
+	env, err := commandEnv(cfg, execution)
+	if err != nil {
+		t.Fatalf("commandEnv failed: %v", err)
+	}
+	want := []string{"KUBECONFIG=" + filepath.Join(home, ".kube/staging"), "CLUSTER=eu-1-b", "DEBUG=1"}
+	if strings.Join(env, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got %q", want, env)
+	}
+
+	execution.Env = []string{"not a variable"}
+	if _, err := commandEnv(cfg, execution); err == nil {
+		t.Error("Expected an error for an invalid variable")
+	}
+}
+
+func TestRunWithEnv(t *testing.T) {
+	useStore(t)
+	file := filepath.Join(t.TempDir(), "greeting")
+	cfg := &config.Config{PageEnv: map[string][]string{"printf": {"GREETING=hello"}}}
+	executions, err := history.LoadLog(executionLogPath())
+	if err != nil {
+		t.Fatalf("LoadLog failed: %v", err)
+	}
+	execution := history.Execution{
+		Page:    "printf",
+		Command: `printf %s "$GREETING $NAME" > ` + types.ShellQuote(file),
+		Env:     []string{"NAME=world"},
+	}
+
+	var preview strings.Builder
+	if err := printDryRun(&preview, cfg, execution); err != nil {
+		t.Fatalf("printDryRun failed: %v", err)
+	}
+	if !strings.Contains(preview.String(), "  GREETING=hello\n  NAME=world\n") {
+		t.Errorf("Expected the dry run to show the environment, got:\n%s", preview.String())
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("Expected the dry run not to run the command, got %v", err)
+	}
+
+	if err := runConfirmed(context.Background(), cfg, executions, execution, nil); err != nil {
+		t.Fatalf("runConfirmed failed: %v", err)
+	}
+	if got, _ := os.ReadFile(file); string(got) != "hello world" {
+		t.Errorf("Expected the command to see its environment, got %q", got)
+	}
+}
+
+func TestRecordExecutionCwd(t *testing.T) {
+	useStore(t)
+	dir := filepath.Join(os.Getenv("HOME"), "work")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+
+	execution := history.Execution{Command: "true", Dir: "~/work", Time: testNow}
+	if err := recordExecution(execution, nil, time.Second); err != nil {
+		t.Fatalf("recordExecution failed: %v", err)
+	}
+	records, err := auditLog().Read(audit.Filter{})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Cwd != dir {
+		t.Errorf("Expected the directory the command ran in, %s, got %+v", dir, records)
+	}
+}
+
+func TestPruneCasts(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20240101-000000.000-a.cast", "20240102-000000.000-b.cast", "20240103-000000.000-c.cast"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	pruneCasts(dir, 2)
+	casts, _ := filepath.Glob(filepath.Join(dir, "*.cast"))
+	if len(casts) != 2 || filepath.Base(casts[0]) != "20240102-000000.000-b.cast" {
+		t.Errorf("Expected the oldest cast removed, got %v", casts)
+	}
+}
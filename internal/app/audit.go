@@ -0,0 +1,170 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/audit"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+)
+
+// AuditOptions selects audit log records
+type AuditOptions struct {
+	// Since is a duration back from now, a date or an RFC 3339 time
+	Since  string
+	Page   string
+	Failed bool
+	// Limit keeps only the most recent records; 0 means all
+	Limit int
+}
+
+// auditLog returns the audit log in the data directory
+func auditLog() *audit.Log {
+	return audit.New(filepath.Join(config.DataDir(), "audit.jsonl"))
+}
+
+// recordExecution appends an executed command and its outcome to the
+// audit log
+func recordExecution(execution history.Execution, runErr error, duration time.Duration) error {
+	record := audit.Record{
+		Time:     execution.Time,
+		User:     currentUser(),
+		Page:     execution.Page,
+		Platform: execution.Platform,
+		Command:  execution.Command,
+		Duration: duration.Milliseconds(),
+		Sudo:     execution.Sudo,
+	}
+	// The directory the command ran in, as resolved from --cwd
+	record.Cwd = execution.Dir
+	if record.Cwd == "" {
+		record.Cwd, _ = os.Getwd()
+	} else if dir, err := commandDir(record.Cwd); err == nil {
+		record.Cwd = dir
+	}
+
+	var interrupted *InterruptedError
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(runErr, &interrupted):
+		record.ExitCode = interrupted.ExitCode()
+		record.Signal = signalName(interrupted.Signal)
+		record.TimedOut = interrupted.Timeout > 0
+	case errors.As(runErr, &exitErr):
+		record.ExitCode = exitErr.ExitCode()
+	case runErr != nil:
+		// The command could not be started at all
+		record.ExitCode = -1
+	}
+
+	return auditLog().Append(record)
+}
+
+// currentUser returns the name of the user running tldrpp
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// AuditList prints the audit log records selected by opts
+func AuditList(opts AuditOptions) error {
+	records, err := readAudit(opts, "")
+	if err != nil {
+		return err
+	}
+	return printAudit(records)
+}
+
+// AuditSearch prints the audit log records whose command, page or working
+// directory contains text
+func AuditSearch(text string, opts AuditOptions) error {
+	records, err := readAudit(opts, text)
+	if err != nil {
+		return err
+	}
+	return printAudit(records)
+}
+
+// AuditExport writes the selected audit log records in format to output,
+// or to stdout if output is empty
+func AuditExport(format, output string, opts AuditOptions) error {
+	records, err := readAudit(opts, "")
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		return audit.Export(os.Stdout, records, format)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	if err := audit.Export(f, records, format); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to export audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// AuditFormats returns the formats audit export accepts
+func AuditFormats() []string {
+	return audit.Formats
+}
+
+// readAudit reads the audit log records selected by opts and text
+func readAudit(opts AuditOptions, text string) ([]audit.Record, error) {
+	since, err := audit.ParseSince(opts.Since, appClock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := auditLog().Read(audit.Filter{
+		Since:  since,
+		Page:   opts.Page,
+		Failed: opts.Failed,
+		Text:   text,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Limit > 0 && len(records) > opts.Limit {
+		records = records[len(records)-opts.Limit:]
+	}
+	return records, nil
+}
+
+// printAudit prints records as a table, oldest first
+func printAudit(records []audit.Record) error {
+	if len(records) == 0 {
+		fmt.Println("No matching executions.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tEXIT\tDURATION\tPAGE\tCOMMAND")
+	for _, r := range records {
+		duration := time.Duration(r.Duration) * time.Millisecond
+		command := r.Command
+		if r.Sudo {
+			command = "[sudo] " + command
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n",
+			r.Time.Local().Format("2006-01-02 15:04:05"), r.ExitCode, duration, r.Page, command)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// benchDuration is how long each measurement repeats for
+const benchDuration = 500 * time.Millisecond
+
+// benchQueries are typed into search, from a few matches to most pages
+var benchQueries = []string{"tar", "git", "compress", "x"}
+
+// benchmark is a measurement 'tldrpp bench' takes and the time it should
+// stay under on a typical machine, as documented in the README. The go
+// test benchmarks of the cache and types packages measure the same.
+type benchmark struct {
+	name   string
+	budget time.Duration
+	run    func(i int) error
+}
+
+// Bench measures index loading, search and rendering on this machine's
+// cache and prints each against its budget, to attach to performance
+// reports
+func Bench() error {
+	_, cacheManager, err := loadConfigAndCache()
+	if err != nil {
+		return err
+	}
+	index, err := cacheManager.ListPages("", nil)
+	if err != nil {
+		return err
+	}
+
+	// Render the examples of the first pages, with every placeholder set
+	var examples []types.Example
+	for i := 0; i < len(index) && i < 50; i++ {
+		page, err := cacheManager.LoadPage(index[i])
+		if err != nil {
+			continue
+		}
+		examples = append(examples, page.Examples...)
+	}
+	vars := make(map[string]string)
+	for _, example := range examples {
+		for _, placeholder := range example.Placeholders {
+			vars[placeholder.Name] = "some value's/path"
+		}
+	}
+
+	benchmarks := []benchmark{
+		{"index load", 5 * time.Millisecond, func(int) error {
+			_, err := cacheManager.ListPages("", nil)
+			return err
+		}},
+		{"live search", 5 * time.Millisecond, func(i int) error {
+			_, err := cacheManager.ListPages(benchQueries[i%len(benchQueries)], nil)
+			return err
+		}},
+		{"search", 100 * time.Millisecond, func(i int) error {
+			_, err := cacheManager.SearchPages(benchQueries[i%len(benchQueries)], nil)
+			return err
+		}},
+	}
+	if len(examples) > 0 {
+		benchmarks = append(benchmarks, benchmark{"render", 20 * time.Microsecond, func(i int) error {
+			examples[i%len(examples)].Render(vars)
+			return nil
+		}})
+	}
+
+	fmt.Printf("tldr++ on %s/%s, %d CPUs, %d pages\n\n", runtime.GOOS, runtime.GOARCH, runtime.NumCPU(), len(index))
+	over := 0
+	for _, b := range benchmarks {
+		took, err := measure(b.run)
+		if err != nil {
+			return fmt.Errorf("%s failed: %w", b.name, err)
+		}
+		status := "ok"
+		if took > b.budget {
+			status = "over budget"
+			over++
+		}
+		fmt.Printf("%-12s %10s  budget %-8s %s\n", b.name, took.Round(time.Microsecond/10), b.budget, status)
+	}
+	if over > 0 {
+		fmt.Printf("\n%d measurement(s) over budget; please include this output in a performance report\n", over)
+	}
+	return nil
+}
+
+// measure runs f repeatedly for benchDuration, after a first run warming up
+// caches, and returns the average time a run took
+func measure(f func(i int) error) (time.Duration, error) {
+	if err := f(0); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	runs := 0
+	for time.Since(start) < benchDuration || runs < 3 {
+		if err := f(runs); err != nil {
+			return 0, err
+		}
+		runs++
+	}
+	return time.Since(start) / time.Duration(runs), nil
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/snippet"
+	"github.com/makalin/tldrpp/internal/workflow"
+)
+
+// CompletePages returns the cached page names starting with prefix for shell
+// completion, as "name\tdescription". An empty cache yields nothing rather
+// than starting a download in the middle of a completion.
+func CompletePages(prefix string) []string {
+	cacheManager, ok := completionCache()
+	if !ok {
+		return nil
+	}
+	entries, err := cacheManager.ListPages("", nil)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, entry := range entries {
+		if seen[entry.Name] || !strings.HasPrefix(entry.Name, prefix) {
+			continue
+		}
+		seen[entry.Name] = true
+		names = append(names, entry.Name+"\t"+entry.Description)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CompleteSubcommands returns the subcommands starting with prefix of the
+// command in words for shell completion, e.g. commit for git, from the
+// subcommand pages such as git-commit
+func CompleteSubcommands(words []string, prefix string) []string {
+	cacheManager, ok := completionCache()
+	if !ok {
+		return nil
+	}
+	name := cacheManager.PageName(strings.Join(words, " "))
+	if name == "" {
+		return nil
+	}
+
+	var subcommands []string
+	for _, subcommand := range cacheManager.Subcommands(name) {
+		word := strings.TrimPrefix(subcommand, name+"-")
+		if !strings.Contains(word, "-") && strings.HasPrefix(word, prefix) {
+			subcommands = append(subcommands, word)
+		}
+	}
+	return subcommands
+}
+
+// CompletePlatforms returns the platforms found in the cache
+func CompletePlatforms() []string {
+	cacheManager, ok := completionCache()
+	if !ok {
+		return nil
+	}
+	entries, err := cacheManager.ListPages("", nil)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var platforms []string
+	for _, entry := range entries {
+		if !seen[entry.Platform] {
+			seen[entry.Platform] = true
+			platforms = append(platforms, entry.Platform)
+		}
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// CompleteSnippets returns the saved snippet names starting with prefix, as
+// "name\tcommand"
+func CompleteSnippets(prefix string) []string {
+	snippets, err := snippet.NewStore(config.SnippetsDir()).List()
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, s := range snippets {
+		if strings.HasPrefix(s.Name, prefix) {
+			names = append(names, s.Name+"\t"+s.Command)
+		}
+	}
+	return names
+}
+
+// CompleteWorkflows returns the workflow names starting with prefix, as
+// "name\tdescription"
+func CompleteWorkflows(prefix string) []string {
+	workflows, err := workflow.NewStore(config.WorkflowsDir()).List()
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, w := range workflows {
+		if strings.HasPrefix(w.Name, prefix) {
+			names = append(names, w.Name+"\t"+w.Description)
+		}
+	}
+	return names
+}
+
+// completionCache returns the cache manager if the cache is ready
+func completionCache() (*cache.Manager, bool) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, false
+	}
+	cacheManager := newCacheManager(cfg)
+	return cacheManager, cacheManager.IsInitialized()
+}
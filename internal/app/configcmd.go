@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/makalin/tldrpp/internal/config"
+)
+
+// ConfigGet prints the effective value of a config key, including overrides
+// from the environment, or every key and value without a key
+func ConfigGet(key string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if key != "" {
+		value, err := config.Get(cfg, key)
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	}
+
+	for _, key := range config.Keys() {
+		value, _ := config.Get(cfg, key)
+		fmt.Printf("%s = %s\n", key, value)
+	}
+	return nil
+}
+
+// ConfigKeys returns the keys config get accepts
+func ConfigKeys() []string {
+	return append(config.Keys(), "sources", "aliases")
+}
+
+// ConfigSet validates a value and writes it to the config file
+func ConfigSet(key, value string) error {
+	// Load first so a missing config file is created with the defaults
+	if _, err := config.Load(); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	return config.Set(key, value)
+}
+
+// ConfigEdit opens the config file in $VISUAL or $EDITOR and validates it
+// once the editor exits
+func ConfigEdit() error {
+	if _, err := config.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	editor, err := config.EditorCommand(config.File())
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(editor[0], editor[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run editor: %w", err)
+	}
+
+	return ConfigValidate()
+}
+
+// ConfigValidate checks the config file, printing every problem found
+func ConfigValidate() error {
+	problems, err := config.Validate(config.File())
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		fmt.Printf("%s is valid\n", config.File())
+		return nil
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "  %v\n", problem)
+	}
+	return fmt.Errorf("%s has %d problem(s)", config.File(), len(problems))
+}
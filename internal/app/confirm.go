@@ -0,0 +1,63 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/tui"
+)
+
+// commandConfirmations returns the questions to answer yes to before the
+// command of execution runs: trusting the page it comes from, and running
+// it when it looks destructive. The TUI asks them in a dialog, so the
+// terminal doesn't have to leave it for a prompt.
+func commandConfirmations(cfg *config.Config, execution history.Execution) ([]tui.Confirmation, error) {
+	var confirmations []tui.Confirmation
+	trusting, _, err := trustConfirmation(cfg, execution)
+	if err != nil {
+		return nil, err
+	}
+	if trusting != nil {
+		confirmations = append(confirmations, *trusting)
+	}
+	if destructive := destructiveConfirmation(cfg, execution.Command); destructive != nil {
+		confirmations = append(confirmations, *destructive)
+	}
+	return confirmations, nil
+}
+
+// destructiveConfirmation returns the confirmation asked before running a
+// command that looks destructive, or nil if there is none to ask
+func destructiveConfirmation(cfg *config.Config, command string) *tui.Confirmation {
+	if !cfg.ConfirmDestructive || !isDestructiveCommand(command) {
+		return nil
+	}
+	return &tui.Confirmation{
+		Message:  fmt.Sprintf("This command appears destructive: %s", command),
+		Question: "Are you sure you want to execute it?",
+	}
+}
+
+// askConfirmation asks a confirmation in the terminal and reports whether
+// it was answered yes, accepting it then
+func askConfirmation(confirmation tui.Confirmation) bool {
+	if confirmation.Message != "" {
+		fmt.Println(confirmation.Message)
+	}
+	fmt.Printf("%s (y/N): ", confirmation.Question)
+	var response string
+	fmt.Scanln(&response)
+	if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+		return false
+	}
+
+	if confirmation.Accept != nil {
+		if err := confirmation.Accept(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	return true
+}
@@ -0,0 +1,244 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/network"
+	"github.com/makalin/tldrpp/internal/tui"
+	"github.com/muesli/termenv"
+)
+
+// sourceCheckTimeout bounds how long checking that a source is reachable
+// may take
+const sourceCheckTimeout = 10 * time.Second
+
+// checkStatus is the outcome of a doctor check
+type checkStatus string
+
+const (
+	checkOK   checkStatus = "ok"
+	checkWarn checkStatus = "warn"
+	checkFail checkStatus = "fail"
+)
+
+// checkResult is a doctor check's outcome, with how to fix a problem
+type checkResult struct {
+	status checkStatus
+	name   string
+	detail string
+	fix    string
+}
+
+// Doctor checks the environment tldr++ runs in and prints, for every
+// problem found, how to fix it. It fails when any check fails; warnings
+// only point out features that won't work.
+func Doctor() error {
+	failed := 0
+	report := func(results ...checkResult) {
+		for _, result := range results {
+			fmt.Printf("%-5s %-16s %s\n", result.status, result.name, result.detail)
+			if result.fix != "" {
+				fmt.Printf("%-5s %-16s fix: %s\n", "", "", result.fix)
+			}
+			if result.status == checkFail {
+				failed++
+			}
+		}
+	}
+
+	report(checkConfig())
+	cfg, err := config.Load()
+	if err != nil {
+		report(checkResult{checkFail, "config", err.Error(), "fix the file or move it aside to start from the defaults"})
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+
+	cacheManager := newCacheManager(cfg)
+	report(checkCache(cfg, cacheManager)...)
+	report(checkClipboard())
+	report(checkShellIntegration())
+	report(checkSubmitTools()...)
+	report(checkColors())
+	report(checkNetwork(cfg))
+	report(checkSources(cacheManager)...)
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// checkConfig validates the config file
+func checkConfig() checkResult {
+	file := config.File()
+	problems, err := config.Validate(file)
+	switch {
+	case os.IsNotExist(err):
+		return checkResult{checkOK, "config", "no config file, using the defaults", ""}
+	case err != nil:
+		return checkResult{checkFail, "config", err.Error(), "fix the YAML syntax with 'tldrpp config edit'"}
+	case len(problems) > 0:
+		details := make([]string, len(problems))
+		for i, problem := range problems {
+			details[i] = problem.Error()
+		}
+		return checkResult{checkFail, "config", strings.Join(details, "; "), "run 'tldrpp config edit' or 'tldrpp config set <key> <value>'"}
+	}
+	return checkResult{checkOK, "config", file + " is valid", ""}
+}
+
+// checkCache checks that the cache exists, is intact and is fresh
+func checkCache(cfg *config.Config, cacheManager *cache.Manager) []checkResult {
+	if !cacheManager.IsInitialized() {
+		return []checkResult{{checkFail, "cache", "no pages downloaded yet", "run 'tldrpp init'"}}
+	}
+
+	report, err := cacheManager.Verify()
+	if err != nil {
+		return []checkResult{{checkFail, "cache", err.Error(), "run 'tldrpp init' to download the pages again"}}
+	}
+	results := []checkResult{{checkOK, "cache", fmt.Sprintf("%d pages, all intact", report.Checked), ""}}
+	if len(report.Problems) > 0 {
+		results[0] = checkResult{checkFail, "cache", fmt.Sprintf("%d of %d pages have problems", len(report.Problems), report.Checked), "run 'tldrpp cache repair'"}
+	}
+
+	if cacheManager.IsStale(cfg.CacheTTL()) {
+		results = append(results, checkResult{checkWarn, "cache age", fmt.Sprintf("older than cache_ttl_hours (%dh)", cfg.CacheTTLHours), "run 'tldrpp update'"})
+	}
+	return results
+}
+
+// checkClipboard checks which tool copying uses
+func checkClipboard() checkResult {
+	if tool := tui.ClipboardBackend(); tool != "" {
+		return checkResult{checkOK, "clipboard", "copying uses " + tool, ""}
+	}
+	return checkResult{checkWarn, "clipboard", "no clipboard tool found, copying falls back to OSC 52, which not every terminal supports",
+		"install pbcopy (macOS), wl-copy (Wayland) or xclip or xsel (X11)"}
+}
+
+// shellStartupFiles are where each shell's widget is set up
+var shellStartupFiles = map[string]string{
+	"bash": ".bashrc",
+	"zsh":  ".zshrc",
+	"fish": filepath.Join(".config", "fish", "config.fish"),
+}
+
+// checkShellIntegration checks that the startup file of the user's shell
+// sets up the widget
+func checkShellIntegration() checkResult {
+	shell := filepath.Base(os.Getenv("SHELL"))
+	file, ok := shellStartupFiles[shell]
+	if !ok {
+		return checkResult{checkWarn, "shell widget", fmt.Sprintf("can't tell for shell %q", shell), "the widget supports bash, zsh and fish"}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return checkResult{checkWarn, "shell widget", err.Error(), ""}
+	}
+	if shell == "zsh" && os.Getenv("ZDOTDIR") != "" {
+		home = os.Getenv("ZDOTDIR")
+	}
+
+	path := filepath.Join(home, file)
+	data, err := os.ReadFile(path)
+	if err == nil && strings.Contains(string(data), "tldrpp shell-init") {
+		return checkResult{checkOK, "shell widget", "set up in " + path, ""}
+	}
+	fix := fmt.Sprintf(`add 'eval "$(tldrpp shell-init %s)"' to %s`, shell, path)
+	if shell == "fish" {
+		fix = fmt.Sprintf("add 'tldrpp shell-init fish | source' to %s", path)
+	}
+	return checkResult{checkWarn, "shell widget", "not set up in " + path, fix}
+}
+
+// checkSubmitTools checks for the tools the submit plugin needs
+func checkSubmitTools() []checkResult {
+	tools := []struct {
+		name, use, fix string
+	}{
+		{"git", "preparing pages to submit", "install git from https://git-scm.com"},
+		{"gh", "opening pull requests", "install the GitHub CLI from https://cli.github.com"},
+	}
+
+	var results []checkResult
+	for _, tool := range tools {
+		if path, err := exec.LookPath(tool.name); err == nil {
+			results = append(results, checkResult{checkOK, tool.name, path, ""})
+		} else {
+			results = append(results, checkResult{checkWarn, tool.name, "not found, the submit plugin needs it for " + tool.use, tool.fix})
+		}
+	}
+	return results
+}
+
+// checkColors checks how many colors the terminal supports, judging by
+// TERM and COLORTERM
+func checkColors() checkResult {
+	if termenv.EnvNoColor() {
+		return checkResult{checkOK, "colors", "disabled by NO_COLOR", ""}
+	}
+
+	term := os.Getenv("TERM")
+	switch termenv.NewOutput(os.Stdout, termenv.WithTTY(true)).EnvColorProfile() {
+	case termenv.TrueColor:
+		return checkResult{checkOK, "colors", "true color", ""}
+	case termenv.ANSI256:
+		return checkResult{checkOK, "colors", "256 colors", ""}
+	case termenv.ANSI:
+		return checkResult{checkWarn, "colors", fmt.Sprintf("16 colors only (TERM=%s), themes look washed out", term),
+			"set TERM=xterm-256color, or COLORTERM=truecolor if the terminal supports it"}
+	}
+	return checkResult{checkWarn, "colors", fmt.Sprintf("no color support (TERM=%s)", term), "set TERM=xterm-256color"}
+}
+
+// checkNetwork checks that the proxy and TLS settings can be used
+func checkNetwork(cfg *config.Config) checkResult {
+	if _, err := network.Transport(cfg.Network); err != nil {
+		return checkResult{checkFail, "network", err.Error(), "fix the network settings with 'tldrpp config edit'"}
+	}
+	detail := "proxy from the environment"
+	if cfg.Network.Proxy != "" {
+		detail = "proxy " + os.ExpandEnv(cfg.Network.Proxy)
+	}
+	if cfg.Network.CAFile != "" {
+		detail += ", extra CA bundle " + os.ExpandEnv(cfg.Network.CAFile)
+	}
+	if cfg.Network.ClientCert != "" {
+		detail += ", client certificate " + os.ExpandEnv(cfg.Network.ClientCert)
+	}
+	return checkResult{checkOK, "network", detail, ""}
+}
+
+// checkSources checks that every page source can be reached
+func checkSources(cacheManager *cache.Manager) []checkResult {
+	var results []checkResult
+	for _, src := range cacheManager.Sources() {
+		name := "source " + src.Name
+		if src.Name == "" {
+			name = "source"
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), sourceCheckTimeout)
+		err := cacheManager.CheckSource(ctx, src)
+		cancel()
+		if err != nil {
+			fix := network.Hint(err)
+			if fix == "" {
+				fix = "check your network or proxy, or fix the source's url in the config"
+			}
+			results = append(results, checkResult{checkFail, name, fmt.Sprintf("%s is unreachable: %v", src.URL, err), fix})
+		} else {
+			results = append(results, checkResult{checkOK, name, src.URL + " is reachable", ""})
+		}
+	}
+	return results
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+)
+
+// commandEnv returns the environment variables execution's command runs
+// with on top of tldrpp's own, as NAME=value: those page_env sets for its
+// page, then its own, a later one replacing an earlier one of the same
+// name. $NAME in a value is expanded, from the variables before it or the
+// environment, and a leading ~/ to the home directory.
+func commandEnv(cfg *config.Config, execution history.Execution) ([]string, error) {
+	var names []string
+	values := make(map[string]string)
+	lookup := func(name string) string {
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return os.Getenv(name)
+	}
+	for _, entry := range append(append([]string(nil), cfg.Env(execution.Page)...), execution.Env...) {
+		name, value, err := config.ParseEnv(entry)
+		if err != nil {
+			return nil, err
+		}
+		value = config.ExpandHome(os.Expand(value, lookup))
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		values[name] = value
+	}
+
+	env := make([]string, len(names))
+	for i, name := range names {
+		env[i] = name + "=" + values[name]
+	}
+	return env, nil
+}
+
+// commandDir returns the absolute path of dir, a command's working
+// directory, with a leading ~ expanded, or an error if it isn't a
+// directory
+func commandDir(dir string) (string, error) {
+	abs, err := filepath.Abs(config.ExpandHome(dir))
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("invalid working directory: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("invalid working directory: %s is not a directory", abs)
+	}
+	return abs, nil
+}
+
+// printDryRun writes what running execution would do, without running it:
+// the directory and environment variables set for it and its command, with
+// the variables in it when sudo runs it
+func printDryRun(w io.Writer, cfg *config.Config, execution history.Execution) error {
+	env, err := commandEnv(cfg, execution)
+	if err != nil {
+		return err
+	}
+	command, env, err := escalated(execution, env)
+	if err != nil {
+		return err
+	}
+	if len(env) == 0 && execution.Dir == "" {
+		fmt.Fprintln(w, command)
+		return nil
+	}
+	if execution.Dir != "" {
+		dir, err := commandDir(execution.Dir)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Directory:\n  %s\n", dir)
+	}
+	if len(env) > 0 {
+		fmt.Fprintln(w, "Environment:")
+		for _, entry := range env {
+			fmt.Fprintf(w, "  %s\n", entry)
+		}
+	}
+	fmt.Fprintf(w, "Command:\n  %s\n", command)
+	return nil
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/explain"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// stageExplanation is the breakdown of one command of a pipeline or list
+type stageExplanation struct {
+	Command  string `json:"command"`
+	Page     string `json:"page,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	*explain.Explanation
+	Error string `json:"error,omitempty"`
+}
+
+// Explain breaks a command line down against the tldr pages of its
+// commands, printing what each flag and argument does. Pipelines and
+// command lists are explained stage by stage. Without args the command line
+// is read from stdin; a single argument is split like a shell would.
+func Explain(args []string, asJSON bool) error {
+	var line string
+	switch len(args) {
+	case 0:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read command line: %w", err)
+		}
+		line = string(data)
+	case 1:
+		line = args[0]
+	default:
+		line = joinArgs(args)
+	}
+
+	commands, err := explain.SplitCommands(line)
+	if err != nil {
+		return fmt.Errorf("failed to parse command line: %w", err)
+	}
+	if len(commands) == 0 {
+		return fmt.Errorf("no command line to explain")
+	}
+
+	cacheManager, err := loadCache()
+	if err != nil {
+		return err
+	}
+
+	stages := make([]stageExplanation, len(commands))
+	found := false
+	for i, command := range commands {
+		stages[i] = explainCommand(cacheManager, command)
+		found = found || stages[i].Explanation != nil
+	}
+	if !found {
+		return fmt.Errorf("command not found: %s", stages[0].Error)
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stages)
+	}
+
+	for i, stage := range stages {
+		if i > 0 {
+			fmt.Printf("\n%s\n\n", stages[i-1].Operator)
+		}
+		if err := printStage(stage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinArgs rebuilds a command line from separate arguments, quoting them so
+// they stay single words. Arguments that are control operators, such as an
+// escaped \|, still separate commands.
+func joinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		switch arg {
+		case "|", "||", "&&", ";", "&":
+			quoted[i] = arg
+		default:
+			quoted[i] = types.ShellQuote(arg)
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// explainCommand explains a single command, recording a missing page as an
+// error of the stage so the rest of a pipeline is still explained
+func explainCommand(cacheManager *cache.Manager, command explain.Command) stageExplanation {
+	stage := stageExplanation{
+		Command:  strings.Join(command.Words, " "),
+		Operator: command.Operator,
+	}
+
+	name := commandLineQuery(cacheManager, stage.Command)
+	page, err := cacheManager.FindPage(name)
+	if err != nil {
+		stage.Error = err.Error()
+		return stage
+	}
+
+	commandWords := 1
+	if name != command.Words[0] {
+		commandWords = 2
+	}
+	stage.Page = page.Name
+	stage.Explanation = explain.Explain(page, command.Words, commandWords)
+	return stage
+}
+
+// printStage prints the breakdown of one command
+func printStage(stage stageExplanation) error {
+	if stage.Explanation == nil {
+		fmt.Printf("%s: no page found\n", stage.Command)
+		return nil
+	}
+
+	fmt.Printf("%s: %s\n\n", stage.Page, stage.Explanation.Page.Description)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, part := range stage.Parts {
+		example := ""
+		if part.Kind == explain.KindFlag && part.Example != "" {
+			example = "(" + part.Example + ")"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", part.Token, part.Kind, part.Meaning, example)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if stage.Closest != nil {
+		fmt.Printf("\nClosest example:\n  %s\n  %s\n", stage.Closest.Description, stage.Closest.Command)
+	}
+	return nil
+}
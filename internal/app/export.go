@@ -0,0 +1,142 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/makalin/tldrpp/internal/export"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// loadAllPages loads every cached and custom page on the given platforms,
+// or on all platforms if there are none. Pages that can't be read are
+// skipped with a warning.
+func loadAllPages(platforms []string) ([]*types.Page, error) {
+	_, cacheManager, err := loadConfigAndCache()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := cacheManager.ListPages("", platforms)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]*types.Page, 0, len(entries))
+	for _, entry := range entries {
+		page, err := cacheManager.LoadPage(entry)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		pages = append(pages, page)
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no pages to export")
+	}
+	return pages, nil
+}
+
+// ExportHTML renders the pages on the given platforms, or all pages, into a
+// static site with client-side search in dir
+func ExportHTML(dir, title string, platforms []string) error {
+	pages, err := loadAllPages(platforms)
+	if err != nil {
+		return err
+	}
+
+	if err := export.HTML(dir, title, pages); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d pages to %s\n", len(pages), dir)
+	return nil
+}
+
+// ExportPDF writes the named pages, or all pages on the given platforms, as
+// a printable cheat sheet to output
+func ExportPDF(names []string, output, layout string, platforms []string) error {
+	var pages []*types.Page
+	if len(names) == 0 {
+		var err error
+		if pages, err = loadAllPages(platforms); err != nil {
+			return err
+		}
+	} else {
+		cacheManager, err := loadCache()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			page, err := cacheManager.FindPage(name)
+			if err != nil {
+				return err
+			}
+			pages = append(pages, page)
+		}
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create PDF file: %w", err)
+	}
+	if err := export.PDF(f, pages, layout); err != nil {
+		f.Close()
+		os.Remove(output)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write PDF file: %w", err)
+	}
+	fmt.Printf("Exported %d pages to %s\n", len(pages), output)
+	return nil
+}
+
+// PDFLayouts returns the layouts export pdf accepts
+func PDFLayouts() []string {
+	return export.Layouts
+}
+
+// ExportMan writes the named pages as man pages: to stdout for a single
+// page, or as <name>.1 files in dir, e.g. ~/.local/share/man/man1
+func ExportMan(names []string, dir string) error {
+	if dir == "" && len(names) > 1 {
+		return fmt.Errorf("use --out to export more than one page")
+	}
+	cacheManager, err := loadCache()
+	if err != nil {
+		return err
+	}
+
+	pages := make([]*types.Page, 0, len(names))
+	for _, name := range names {
+		page, err := cacheManager.FindPage(name)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, page)
+	}
+
+	if dir == "" {
+		return export.Man(os.Stdout, pages[0])
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for _, page := range pages {
+		path := filepath.Join(dir, page.Name+".1")
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create man page: %w", err)
+		}
+		if err := export.Man(f, page); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write man page: %w", err)
+		}
+		fmt.Printf("Wrote %s\n", path)
+	}
+	return nil
+}
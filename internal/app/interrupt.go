@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// interruptWait is how long an interrupted command has to exit before it is
+// killed
+const interruptWait = 5 * time.Second
+
+// SignalError is the cause of a context cancelled by a signal
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("interrupted by %s", signalName(e.Signal))
+}
+
+// Interruptible returns a context cancelled by Ctrl+C or SIGTERM, whose
+// cause is a SignalError naming the signal, so a download or command in
+// progress stops cleanly. Once it is cancelled, another signal has its
+// default effect and exits at once.
+func Interruptible() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			cancel(&SignalError{Signal: sig})
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// timeoutError is the cause of a context cancelled when a command ran for
+// too long
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s", e.timeout)
+}
+
+// timeoutExitCode is the status of a command stopped for running too long,
+// the same as the timeout utility's
+const timeoutExitCode = 124
+
+// InterruptedError reports a command that a signal stopped before it
+// finished: sent to tldrpp, sent because the command ran out of time, or
+// sent by the system when it used up its CPU time
+type InterruptedError struct {
+	Signal os.Signal
+	// Timeout is set when the command ran longer than it may
+	Timeout time.Duration
+	// CPULimit is set when the command used up the CPU time it may
+	CPULimit bool
+	// Killed is set when the command didn't exit within interruptWait of
+	// the signal and was killed
+	Killed bool
+	// Err is the error the command exited with
+	Err error
+}
+
+func (e *InterruptedError) Error() string {
+	var reason string
+	switch {
+	case e.Timeout > 0:
+		reason = fmt.Sprintf("command timed out after %s", e.Timeout)
+	case e.CPULimit:
+		reason = "command used up its CPU time limit"
+	default:
+		reason = fmt.Sprintf("command interrupted by %s", signalName(e.Signal))
+	}
+	if e.Killed {
+		reason += fmt.Sprintf(" and was killed %s later", interruptWait)
+	}
+	return reason
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns 124 for a command that timed out, like the timeout
+// utility, and otherwise the status a shell reports for a command stopped
+// by the signal, 128 plus its number
+func (e *InterruptedError) ExitCode() int {
+	if e.Timeout > 0 {
+		return timeoutExitCode
+	}
+	if sig, ok := e.Signal.(syscall.Signal); ok {
+		return 128 + int(sig)
+	}
+	return 1
+}
+
+// runProcess runs cmd in a process group of its own for up to timeout, or
+// without limit if it is 0. A signal reaching tldrpp, ctx being done or the
+// timeout passing is forwarded to the whole group as SIGTERM, so commands
+// of a pipeline and their children stop too instead of being orphaned; the
+// group is killed if it hasn't exited within interruptWait. A command
+// stopped by a signal returns an InterruptedError.
+func runProcess(ctx context.Context, cmd *exec.Cmd, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, &timeoutError{timeout: timeout})
+		defer cancel()
+	}
+
+	wait, restore, err := startProcess(cmd)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- wait()
+	}()
+
+	select {
+	case err := <-done:
+		if sig := exitSignal(cmd.ProcessState); sig != nil {
+			// The terminal sent Ctrl+C to the command directly, or the
+			// system stopped it at its CPU time limit
+			return &InterruptedError{Signal: sig, CPULimit: cpuLimitSignal(sig), Err: err}
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	interrupted := &InterruptedError{Signal: syscall.SIGTERM}
+	var signalErr *SignalError
+	var timeoutErr *timeoutError
+	switch cause := context.Cause(ctx); {
+	case errors.As(cause, &signalErr):
+		interrupted.Signal = signalErr.Signal
+	case errors.As(cause, &timeoutErr):
+		interrupted.Timeout = timeoutErr.timeout
+	}
+	signalProcess(cmd.Process, interrupted.Signal)
+
+	timer := time.NewTimer(interruptWait)
+	defer timer.Stop()
+	select {
+	case interrupted.Err = <-done:
+	case <-timer.C:
+		signalProcess(cmd.Process, os.Kill)
+		interrupted.Killed = true
+		interrupted.Err = <-done
+	}
+	return interrupted
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/locale"
+)
+
+// language overrides the configured page language for this invocation
+var language string
+
+// SetLanguage shows pages in the given language, e.g. "de", instead of the
+// configured or locale one
+func SetLanguage(lang string) {
+	language = lang
+}
+
+// SetHome keeps the config, cache and data under dir, or $TLDRPP_HOME if
+// dir is empty, instead of the platform's directories
+func SetHome(dir string) error {
+	return config.SetHome(dir)
+}
+
+// Migrate moves files left in the old fixed locations by earlier versions to
+// the platform's directories. Failing to do so only warrants a warning.
+func Migrate() {
+	if err := config.Migrate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// newCacheManager returns the cache manager for cfg, showing pages in the
+// language from --language, the config or the locale, in that order
+func newCacheManager(cfg *config.Config) *cache.Manager {
+	cacheManager := cache.New(cfg.CacheDir, cfg.Sources)
+	cacheManager.SetCustomDir(config.CustomPagesDir())
+	cacheManager.SetPacksDir(config.PacksDir())
+	cacheManager.SetPin(cfg.PagesVersion)
+	cacheManager.SetRequireSigned(cfg.RequireSignedSources)
+	cacheManager.SetAliases(cfg.Aliases)
+	cacheManager.SetClock(appClock)
+	if err := cacheManager.SetNetwork(cfg.Network); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring network settings: %v\n", err)
+	}
+	switch {
+	case language != "":
+		cacheManager.SetLanguages(locale.Parse(language))
+	case cfg.Language != "":
+		cacheManager.SetLanguages(locale.Parse(cfg.Language))
+	}
+	return cacheManager
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// Last recalls the most recently run command, asking again for the values
+// of the placeholders whose memory policy is ask, and prints it or, with
+// run, runs it again
+func Last(run bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	executions, err := history.LoadLog(executionLogPath())
+	if err != nil {
+		return err
+	}
+	if len(executions.Executions) == 0 {
+		return fmt.Errorf("no command has been run yet")
+	}
+
+	execution, err := recall(cfg, executions.Executions[0])
+	if err != nil {
+		return err
+	}
+	if !run {
+		fmt.Println(execution.Command)
+		return nil
+	}
+	return runCommand(context.Background(), cfg, executions, execution)
+}
+
+// recall fills in again the placeholders of a command from the history
+// whose memory policy is ask, and those with a secret value, which the
+// history does not keep
+func recall(cfg *config.Config, execution history.Execution) (history.Execution, error) {
+	if execution.Template == "" {
+		// Recorded before templates were kept: only the command is known
+		if execution.Redacted {
+			return execution, fmt.Errorf("%s was run with secret values, which are not kept; run it from its page again", execution.Page)
+		}
+		return execution, nil
+	}
+
+	example := types.NewExample("", execution.Template)
+	asked := make(map[string]bool)
+	for _, placeholder := range example.Placeholders {
+		value, ok := execution.Vars[placeholder.Name]
+		if ok && (value == types.Redacted || cfg.MemoryPolicy(placeholder.Name, placeholder.Type) == config.MemoryAsk) {
+			asked[placeholder.Name] = true
+		}
+	}
+	if len(asked) == 0 {
+		return execution, nil
+	}
+	vars := make(map[string]string, len(execution.Vars))
+	for name, value := range execution.Vars {
+		if !asked[name] {
+			vars[name] = value
+		}
+	}
+
+	vars, err := resolveSecrets(cfg, example, vars)
+	if err != nil {
+		return execution, err
+	}
+	var ask []types.Placeholder
+	var names []string
+	for _, placeholder := range example.Placeholders {
+		if _, filled := vars[placeholder.Name]; filled || !asked[placeholder.Name] {
+			continue
+		}
+		// Ask once for a placeholder used twice
+		delete(asked, placeholder.Name)
+		ask = append(ask, placeholder)
+		names = append(names, placeholder.Name)
+	}
+
+	if len(ask) > 0 {
+		if !isInteractive() {
+			return execution, fmt.Errorf("%s must be given again, which needs a terminal", strings.Join(names, ", "))
+		}
+		memory, err := history.Load(placeholderMemoryPath())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		vars, err = promptValues(os.Stdin, os.Stderr, cfg, example, ask, vars, memory)
+		if err != nil {
+			return execution, err
+		}
+		rememberValues(cfg, memory, example, vars)
+	}
+
+	execution.Command = example.Render(vars)
+	execution.Vars = vars
+	execution.Redacted = false
+	return execution, nil
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"log/slog"
+	"path/filepath"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/log"
+)
+
+// SetupLogging turns the diagnostic log on when asked for: verbose logs
+// operations such as cache updates, dev mode (the flag or the dev_mode
+// setting) also logs details such as search scores and key presses. The log
+// goes to file if set, or else to stderr, except that the TUI owns the
+// terminal, so when interactive it goes to tldrpp.log in the data directory.
+func SetupLogging(verbose, dev bool, file string, interactive bool) error {
+	if cfg, err := config.Load(); err == nil && cfg.DevMode {
+		dev = true
+	}
+	if !verbose && !dev && file == "" {
+		return nil
+	}
+
+	level := slog.LevelInfo
+	if dev {
+		level = slog.LevelDebug
+	}
+	if file == "" && interactive {
+		file = filepath.Join(config.DataDir(), "tldrpp.log")
+	}
+	return log.Setup(log.Options{Level: level, File: file})
+}
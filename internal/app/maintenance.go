@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/config"
+)
+
+// VerifyCache checks the cache for missing, corrupt and orphaned pages
+func VerifyCache() error {
+	cacheManager, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	report, err := cacheManager.Verify()
+	if err != nil {
+		return fmt.Errorf("failed to verify cache: %w", err)
+	}
+
+	printProblems(report.Problems)
+	fmt.Printf("Checked %d pages, found %d problems\n", report.Checked, len(report.Problems))
+	if len(report.Problems) > 0 {
+		return fmt.Errorf("cache has %d problems, run 'tldrpp cache repair' to fix them", len(report.Problems))
+	}
+	return nil
+}
+
+// RepairCache fetches missing and corrupt pages again and removes orphans,
+// without downloading the whole cache
+func RepairCache() error {
+	cacheManager, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	report, err := cacheManager.Verify()
+	if err != nil {
+		return fmt.Errorf("failed to verify cache: %w", err)
+	}
+	if len(report.Problems) == 0 {
+		fmt.Println("Cache is healthy, nothing to repair")
+		return nil
+	}
+
+	unfixed, err := cacheManager.Repair(context.Background(), report)
+	if err != nil {
+		return fmt.Errorf("failed to repair cache: %w", err)
+	}
+
+	fmt.Printf("Repaired %d of %d problems\n", len(report.Problems)-len(unfixed), len(report.Problems))
+	if len(unfixed) > 0 {
+		printProblems(unfixed)
+		return fmt.Errorf("%d problems could not be repaired, run 'tldrpp update' to rebuild the cache", len(unfixed))
+	}
+	return nil
+}
+
+// openCache returns the cache manager without initializing an empty cache
+func openCache() (*cache.Manager, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cacheManager := newCacheManager(cfg)
+	if !cacheManager.IsInitialized() {
+		return nil, fmt.Errorf("cache is not initialized, run 'tldrpp init' first")
+	}
+	return cacheManager, nil
+}
+
+// printProblems lists cache problems, one per line
+func printProblems(problems []cache.Problem) {
+	for _, problem := range problems {
+		fmt.Printf("  %-8s %s\n", problem.Kind, problem.Path)
+	}
+}
+
+// CacheInfo prints statistics about the cache, or the same data as JSON
+func CacheInfo(asJSON bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cacheManager, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	info, err := cacheManager.Info(cfg.CacheTTL())
+	if err != nil {
+		return fmt.Errorf("failed to read cache info: %w", err)
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal cache info: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	freshness := "fresh"
+	if info.Stale {
+		freshness = "stale"
+	}
+
+	fmt.Printf("Directory:    %s\n", info.Dir)
+	fmt.Printf("Source:       %s\n", sourceLabel(info))
+	fmt.Printf("Version:      %s\n", versionLabel(info, cfg.PagesVersion))
+	fmt.Printf("Last update:  %s (%s, TTL %dh)\n", info.UpdatedAt.Format(time.RFC1123), freshness, cfg.CacheTTLHours)
+	fmt.Printf("Size on disk: %s\n", info.Size())
+	fmt.Printf("Pages:        %d\n", info.Pages)
+	printCounts("Platforms", info.Platforms)
+	printCounts("Languages", info.Languages)
+	return nil
+}
+
+// versionLabel describes the pages version the cache was built at and
+// whether it matches the configured pin
+func versionLabel(info *cache.Info, pin string) string {
+	label := "latest"
+	if info.Pinned != "" {
+		label = "pinned to " + info.Pinned
+	}
+	switch {
+	case pin == info.Pinned:
+		return label
+	case pin == "":
+		return label + " (no longer pinned, 'tldrpp update' fetches the latest)"
+	default:
+		return fmt.Sprintf("%s (pages_version is %s, run 'tldrpp update')", label, pin)
+	}
+}
+
+// sourceLabel describes where the cache was built from
+func sourceLabel(info *cache.Info) string {
+	switch {
+	case info.Source == "":
+		return "unknown"
+	case info.Source == info.SourceURL:
+		return info.SourceURL
+	default:
+		return fmt.Sprintf("%s (%s)", info.Source, info.SourceURL)
+	}
+}
+
+// printCounts prints a count per key, sorted by key
+func printCounts(title string, counts map[string]int) {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Printf("%s:\n", title)
+	for _, key := range keys {
+		fmt.Printf("  %-12s %d\n", key, counts[key])
+	}
+}
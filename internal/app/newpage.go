@@ -0,0 +1,71 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/platform"
+)
+
+// pageTemplate is the skeleton of a new custom page, in the tldr format
+const pageTemplate = `# %[1]s
+
+> Short description of what %[1]s does.
+> More information: <https://example.com>.
+
+- Describe what the first example does:
+
+` + "`%[1]s {{path/to/file}}`" + `
+
+- Describe what the second example does:
+
+` + "`%[1]s --flag {{value}}`" + `
+`
+
+// NewPage scaffolds a custom page for command from a template and, on a
+// terminal, opens it in the user's editor. Custom pages are listed with the
+// cached ones and replace a cached page of the same name and platform.
+func NewPage(name, platformName string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\ `) {
+		return fmt.Errorf("invalid page name %q", name)
+	}
+	if platformName == "" {
+		platformName = platform.Common
+	}
+	if strings.HasPrefix(platformName, ".") || strings.ContainsAny(platformName, `/\ `) ||
+		platformName == filepath.Base(config.WorkflowsDir()) {
+		return fmt.Errorf("invalid platform %q", platformName)
+	}
+
+	path := filepath.Join(config.CustomPagesDir(), platformName, name+".md")
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("page %s already exists: %s", name, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create pages directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(pageTemplate, name)), 0644); err != nil {
+		return fmt.Errorf("failed to write page: %w", err)
+	}
+	fmt.Printf("Created %s\n", path)
+
+	if !isInteractive() {
+		return nil
+	}
+	editor, err := config.EditorCommand(path)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(editor[0], editor[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run editor: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/terminal"
+	"github.com/makalin/tldrpp/internal/tui"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// features are what the terminal shows beyond text, none when output is
+// plain
+var features terminal.Features
+
+// SetupOutput picks how output is drawn. noColor (or NO_COLOR, which the
+// styles honour by themselves) turns colors off, and the selection is then
+// marked with text; ascii draws borders and symbols in ASCII only. A dumb
+// terminal gets both. The accessible setting lays the TUI out for screen
+// readers. Hyperlinks and images are used where the terminal supports them,
+// unless output is plain or piped.
+func SetupOutput(noColor, ascii bool) {
+	if dumbTerminal() {
+		noColor, ascii = true, true
+	}
+	if noColor {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	tui.SetASCII(ascii)
+
+	accessible := false
+	if cfg, err := config.Load(); err == nil && cfg.Accessible {
+		accessible = true
+		tui.SetAccessible(true)
+	}
+	if !noColor && !ascii && term.IsTerminal(int(os.Stdout.Fd())) {
+		features = terminal.Detect()
+		// Images mean nothing to a screen reader
+		features.Images = features.Images && !accessible
+	}
+	tui.SetHyperlinks(features.Hyperlinks)
+}
+
+// dumbTerminal reports whether TERM says the terminal can't move the cursor
+// or show colors
+func dumbTerminal() bool {
+	return os.Getenv("TERM") == "dumb"
+}
+
+// hyperlink returns text that opens url when clicked, where the terminal
+// supports it
+func hyperlink(url, text string) string {
+	if !features.Hyperlinks || url == "" {
+		return text
+	}
+	return terminal.Hyperlink(url, text)
+}
+
+// badgeWidth and badgeHeight are the size of platform badges in pixels,
+// drawn over two cells
+const (
+	badgeWidth  = 24
+	badgeHeight = 12
+)
+
+// badgeColors are the colors of the platform badges, as RGB
+var badgeColors = map[string][3]byte{
+	"common":  {0x4c, 0xaf, 0x50},
+	"linux":   {0xf5, 0xc2, 0x11},
+	"osx":     {0xa0, 0xa0, 0xa0},
+	"windows": {0x00, 0x78, 0xd4},
+	"android": {0x3d, 0xdc, 0x84},
+	"sunos":   {0xe7, 0x6f, 0x00},
+	"freebsd": {0xab, 0x2b, 0x28},
+	"netbsd":  {0xf2, 0x8c, 0x28},
+	"openbsd": {0xf2, 0xca, 0x30},
+}
+
+// platformBadge returns a pill in the platform's color followed by a
+// space, drawn as an image, or "" where the terminal can't show images
+func platformBadge(platform string) string {
+	if !features.Images {
+		return ""
+	}
+	color, ok := badgeColors[platform]
+	if !ok {
+		color = [3]byte{0x80, 0x80, 0x80}
+	}
+
+	pixels := make([]byte, badgeWidth*badgeHeight*4)
+	radius := badgeHeight / 2
+	for y := 0; y < badgeHeight; y++ {
+		for x := 0; x < badgeWidth; x++ {
+			// Round off the ends: distance from the nearest end's center
+			cx := x
+			if cx < radius {
+				cx = radius
+			} else if cx > badgeWidth-radius-1 {
+				cx = badgeWidth - radius - 1
+			}
+			dx, dy := x-cx, 2*y+1-badgeHeight
+			if 4*dx*dx+dy*dy > badgeHeight*badgeHeight {
+				continue
+			}
+			i := (y*badgeWidth + x) * 4
+			copy(pixels[i:], color[:])
+			pixels[i+3] = 0xff
+		}
+	}
+	return terminal.Image(pixels, badgeWidth, badgeHeight, 2) + " "
+}
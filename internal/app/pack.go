@@ -0,0 +1,115 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/network"
+	"github.com/makalin/tldrpp/internal/pack"
+)
+
+// PackInstall installs the page pack at a URL or path, or by name from the
+// configured pack index, replacing an installed version of it
+func PackInstall(arg string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	data, err := packData(cfg, arg)
+	if err != nil {
+		return network.Explain(err)
+	}
+	p, err := pack.Read(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid pack %s: %w", arg, err)
+	}
+	if isPackName(arg) && p.Manifest.Name != arg {
+		return fmt.Errorf("the index lists pack %s, but its tarball holds pack %s", arg, p.Manifest.Name)
+	}
+
+	dir := config.PacksDir()
+	verb := "Installed"
+	if _, err := os.Stat(filepath.Join(dir, p.Manifest.Name)); err == nil {
+		verb = "Updated"
+	}
+	if err := pack.Install(dir, p); err != nil {
+		return err
+	}
+	fmt.Printf("%s %s %s (%d pages)\n", verb, p.Manifest.Name, p.Manifest.Version, len(p.Pages))
+	return nil
+}
+
+// isPackName reports whether arg names a pack to look up in the index
+// rather than giving its URL or path
+func isPackName(arg string) bool {
+	if strings.Contains(arg, "://") {
+		return false
+	}
+	_, err := os.Stat(arg)
+	return err != nil
+}
+
+// packData returns the tarball of the pack arg refers to
+func packData(cfg *config.Config, arg string) ([]byte, error) {
+	transport, err := network.Transport(cfg.Network)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.Contains(arg, "://"):
+		return pack.Download(transport, arg)
+	case !isPackName(arg):
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pack: %w", err)
+		}
+		return data, nil
+	}
+
+	if cfg.PackIndex == "" {
+		return nil, fmt.Errorf("no pack index configured to look up %s in; set pack_index or give the pack's URL", arg)
+	}
+	data, err := pack.Download(transport, cfg.PackIndex)
+	if err != nil {
+		return nil, err
+	}
+	index, err := pack.ParseIndex(data)
+	if err != nil {
+		return nil, err
+	}
+	url, ok := index[arg]
+	if !ok {
+		return nil, fmt.Errorf("pack %s is not in the index at %s", arg, cfg.PackIndex)
+	}
+	return pack.Download(transport, url)
+}
+
+// PackList prints the installed page packs
+func PackList() error {
+	packs, err := pack.List(config.PacksDir())
+	if err != nil {
+		return err
+	}
+	if len(packs) == 0 {
+		fmt.Println("No packs installed; install one with 'tldrpp pack install <url|name>'")
+		return nil
+	}
+	for _, p := range packs {
+		fmt.Printf("%-24s %-10s %4d pages  %s\n", p.Manifest.Name, p.Manifest.Version, p.Pages, p.Manifest.Description)
+	}
+	return nil
+}
+
+// PackRemove uninstalls a page pack
+func PackRemove(name string) error {
+	if err := pack.Remove(config.PacksDir(), name); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s\n", name)
+	return nil
+}
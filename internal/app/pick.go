@@ -0,0 +1,85 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/tui"
+)
+
+// PickAction is what pick does with the command it rendered
+type PickAction int
+
+const (
+	// PickPrint prints the command
+	PickPrint PickAction = iota
+	// PickExec runs the command
+	PickExec
+	// PickCopy copies the command to the clipboard
+	PickCopy
+)
+
+// Pick goes through what the TUI does, selecting an example, filling in its
+// placeholders and acting on the result, from opts alone. It never prompts,
+// so scripts and tests can use it without a terminal.
+func Pick(command string, opts RenderOptions, action PickAction) error {
+	opts.NoPrompt = true
+	cfg, execution, err := renderCommandLine(context.Background(), command, opts)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case PickExec:
+		executions, err := history.LoadLog(executionLogPath())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		return runCommand(context.Background(), cfg, executions, execution)
+	case PickCopy:
+		if err := tui.CopyToClipboard(execution.Command); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Copied: %s\n", execution.Command)
+	default:
+		fmt.Println(execution.Command)
+	}
+	return nil
+}
+
+// ReadFills reads placeholder values as name=value lines from path, or from
+// stdin if path is "-". Blank lines and lines starting with # are skipped.
+func ReadFills(path string) (map[string]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open values: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	fills := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(text, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("line %d: expected name=value, got %q", line, text)
+		}
+		fills[strings.TrimSpace(name)] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read values: %w", err)
+	}
+	return fills, nil
+}
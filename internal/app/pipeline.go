@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/explain"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// renderCommandLine renders command with placeholders filled. A pipeline or
+// command list of queries, such as "tar create | gzip", renders the best
+// example of each command joined by the same operators. The execution it
+// returns is of the page of the first command, with the values of all
+// placeholders.
+func renderCommandLine(ctx context.Context, command string, opts RenderOptions) (*config.Config, history.Execution, error) {
+	commands, err := explain.SplitCommands(command)
+	if err != nil || len(commands) < 2 {
+		// Not a pipeline, or not parseable as one: treat it as one query
+		return renderQuery(ctx, command, opts)
+	}
+
+	if opts.Example > 0 || opts.Match != "" || len(opts.Positional) > 0 {
+		return nil, history.Execution{}, fmt.Errorf("--example, --match and positional values need a single command, not a pipeline")
+	}
+
+	var cfg *config.Config
+	var line history.Execution
+	var rendered, template strings.Builder
+	vars := make(map[string]string, len(opts.Vars))
+	for name, value := range opts.Vars {
+		vars[name] = value
+	}
+
+	for _, stage := range commands {
+		stageOpts := opts
+		stageOpts.Vars = vars
+		stageCfg, execution, err := renderQuery(ctx, strings.Join(stage.Words, " "), stageOpts)
+		if err != nil {
+			return nil, history.Execution{}, fmt.Errorf("%s: %w", stage.Words[0], err)
+		}
+		if cfg == nil {
+			cfg, line.Page, line.Platform = stageCfg, execution.Page, execution.Platform
+		}
+		// Values given for one command fill placeholders of the same name in
+		// the next ones
+		for name, value := range execution.Vars {
+			vars[name] = value
+		}
+
+		rendered.WriteString(execution.Command)
+		template.WriteString(execution.Template)
+		if stage.Operator != "" {
+			rendered.WriteString(" " + stage.Operator + " ")
+			template.WriteString(" " + stage.Operator + " ")
+		}
+	}
+
+	line.Command = strings.TrimSpace(rendered.String())
+	line.Template = strings.TrimSpace(template.String())
+	line.Vars = vars
+	return cfg, line, nil
+}
+
+// renderQuery renders the example selected by opts for a single query
+func renderQuery(ctx context.Context, command string, opts RenderOptions) (*config.Config, history.Execution, error) {
+	cfg, page, example, err := resolveExample(ctx, command, opts)
+	if err != nil {
+		return nil, history.Execution{}, err
+	}
+	opts.Vars = types.ScopeVars(opts.Vars, page.IndexOf(example))
+
+	vars, err := fillVars(cfg, example, opts)
+	if err != nil {
+		return nil, history.Execution{}, err
+	}
+
+	// Render the command with variables
+	rendered, err := renderExample(example, vars, opts)
+	if err != nil {
+		return nil, history.Execution{}, err
+	}
+
+	recordUsage(cfg, page, example, vars)
+	return cfg, history.Execution{
+		Page:     page.Name,
+		Platform: page.Platform,
+		Command:  rendered,
+		Template: example.Command,
+		Vars:     vars,
+	}, nil
+}
@@ -0,0 +1,97 @@
+//go:build !windows
+
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"golang.org/x/sys/unix"
+)
+
+// shellCommand returns the command running script with the configured
+// shell, sh unless set, at the configured niceness and with the configured
+// CPU time limit. The limit is set by the shell itself so every process it
+// starts inherits it: a soft limit sending SIGXCPU, and a hard one a second
+// later for processes that ignore it. The command doesn't run if the limit
+// can't be set.
+func shellCommand(limits config.Exec, script string) (*exec.Cmd, error) {
+	if limits.CPUSeconds > 0 {
+		script = fmt.Sprintf("ulimit -S -t %d && ulimit -H -t %d || exit 126\n%s", limits.CPUSeconds, limits.CPUSeconds+1, script)
+	}
+	args, err := limits.ShellArgs()
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, script)
+	if limits.Nice > 0 {
+		args = append([]string{"nice", "-n", strconv.Itoa(limits.Nice)}, args...)
+	}
+	return exec.Command(args[0], args[1:]...), nil
+}
+
+// startProcess starts cmd as the leader of a new process group. When tldrpp
+// is in the foreground of a terminal the group takes its place, so the
+// command can read the terminal and Ctrl+C reaches it directly; restore
+// gives the terminal back once the command exited. wait waits for the
+// command to exit.
+func startProcess(cmd *exec.Cmd) (wait func() error, restore func(), err error) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	tty := int(os.Stdin.Fd())
+	foreground, err := unix.IoctlGetInt(tty, unix.TIOCGPGRP)
+	if err != nil || foreground != syscall.Getpgrp() {
+		return cmd.Wait, func() {}, cmd.Start()
+	}
+
+	cmd.SysProcAttr.Foreground = true
+	cmd.SysProcAttr.Ctty = tty
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+	return cmd.Wait, func() {
+		// A background process group changing the foreground one gets
+		// SIGTTOU, which would stop tldrpp
+		signal.Ignore(syscall.SIGTTOU)
+		defer signal.Reset(syscall.SIGTTOU)
+		unix.IoctlSetPointerInt(tty, unix.TIOCSPGRP, foreground)
+	}, nil
+}
+
+// signalProcess sends sig to the process group p leads
+func signalProcess(p *os.Process, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return p.Signal(sig)
+	}
+	return syscall.Kill(-p.Pid, s)
+}
+
+// signalName returns the name of a signal such as SIGINT
+func signalName(sig os.Signal) string {
+	if s, ok := sig.(syscall.Signal); ok {
+		if name := unix.SignalName(s); name != "" {
+			return name
+		}
+	}
+	return sig.String()
+}
+
+// cpuLimitSignal reports whether sig is the one the system stops a process
+// with at its CPU time limit
+func cpuLimitSignal(sig os.Signal) bool {
+	return sig == syscall.SIGXCPU
+}
+
+// exitSignal returns the signal that terminated a process, or nil if it
+// exited by itself
+func exitSignal(state *os.ProcessState) os.Signal {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return status.Signal()
+	}
+	return nil
+}
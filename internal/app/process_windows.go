@@ -0,0 +1,122 @@
+//go:build windows
+
+package app
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/conpty"
+	"golang.org/x/sys/windows"
+	"golang.org/x/term"
+)
+
+// shellCommand returns the command running script with the configured
+// shell, sh unless set. Windows only has sh with Git Bash, MSYS2 or Cygwin,
+// and there is no falling back to cmd.exe: placeholder values are quoted
+// for a POSIX shell, which cmd.exe takes literally, leaving & and | in them
+// to run commands of their own. Windows has no niceness or CPU time limits
+// to set, so the other limits are ignored.
+func shellCommand(limits config.Exec, script string) (*exec.Cmd, error) {
+	args, err := limits.ShellArgs()
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(limits.Shell) == "" {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			return nil, errNoShell
+		}
+	}
+	if name := strings.ToLower(args[0]); name != "cmd" && name != "cmd.exe" {
+		return exec.Command(args[0], append(args[1:], script)...), nil
+	}
+
+	// cmd.exe doesn't parse its command line the way Go quotes arguments,
+	// so the script is passed as it is, in the quotes /s strips
+	cmd := exec.Command(args[0])
+	line := append([]string{args[0], "/d", "/s"}, args[1:]...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine: strings.Join(line, " ") + ` "` + script + `"`,
+	}
+	return cmd, nil
+}
+
+// errNoShell is returned running a command on Windows without sh
+var errNoShell = errors.New("no sh to run commands with: install Git for Windows, MSYS2 or Cygwin and put their sh on PATH, or set exec.shell to a shell that understands POSIX quoting")
+
+// startProcess starts cmd. Windows has no process groups to signal, so
+// there is nothing to restore. A command whose output is captured rather
+// than written to the console runs in a pseudo console, so that it still
+// writes to a console, colors included; its output and errors both go to
+// cmd.Stdout then.
+func startProcess(cmd *exec.Cmd) (wait func() error, restore func(), err error) {
+	if _, ok := cmd.Stdout.(*os.File); ok || cmd.Stdout == nil {
+		return cmd.Wait, func() {}, cmd.Start()
+	}
+	wait, err = startConsole(cmd)
+	return wait, func() {}, err
+}
+
+// startConsole starts cmd in a pseudo console the size of the terminal,
+// copying what it writes to cmd.Stdout. Nothing is typed into the console.
+func startConsole(cmd *exec.Cmd) (wait func() error, err error) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	line := windows.ComposeCommandLine(cmd.Args)
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.CmdLine != "" {
+		line = cmd.SysProcAttr.CmdLine
+	}
+	console, err := conpty.Start(cmd.Path, line, cmd.Dir, cmd.Env, width, height)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Process = console.Process
+
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(cmd.Stdout, console.Output)
+		close(copied)
+	}()
+	return func() error {
+		state, err := console.Process.Wait()
+		// The output ends once the console is closed
+		console.Close()
+		<-copied
+		console.Output.Close()
+		if err != nil {
+			return err
+		}
+		cmd.ProcessState = state
+		if !state.Success() {
+			return &exec.ExitError{ProcessState: state}
+		}
+		return nil
+	}, nil
+}
+
+// signalProcess stops p; Windows can't deliver other signals to a process
+func signalProcess(p *os.Process, sig os.Signal) error {
+	return p.Kill()
+}
+
+// signalName returns the name of a signal
+func signalName(sig os.Signal) string {
+	return sig.String()
+}
+
+// cpuLimitSignal reports false: Windows has no CPU time limits
+func cpuLimitSignal(sig os.Signal) bool {
+	return false
+}
+
+// exitSignal returns nil: on Windows processes aren't terminated by signals
+func exitSignal(state *os.ProcessState) os.Signal {
+	return nil
+}
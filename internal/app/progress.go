@@ -0,0 +1,38 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/makalin/tldrpp/internal/cache"
+	"golang.org/x/term"
+)
+
+// initializeCache initializes the cache, showing download and indexing
+// progress on stderr when it is a terminal, until ctx is done
+func initializeCache(ctx context.Context, cacheManager CacheStore) error {
+	return withProgress(func(progress cache.ProgressFunc) error {
+		return cacheManager.InitializeContext(ctx, progress)
+	})
+}
+
+// withProgress runs a cache operation, rewriting a single stderr line with
+// its progress. Dumb terminals can't rewrite lines, so they get none.
+func withProgress(run func(cache.ProgressFunc) error) error {
+	if !term.IsTerminal(int(os.Stderr.Fd())) || dumbTerminal() {
+		return run(nil)
+	}
+
+	var last string
+	err := run(func(p cache.Progress) {
+		if line := p.String(); line != last {
+			last = line
+			fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+		}
+	})
+	if last != "" {
+		fmt.Fprintln(os.Stderr)
+	}
+	return err
+}
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/secrets"
+	"github.com/makalin/tldrpp/internal/suggest"
+	"github.com/makalin/tldrpp/internal/types"
+	"golang.org/x/term"
+)
+
+// isInteractive reports whether both stdin and stdout are attached to a terminal
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// placeholderMemoryPath returns where remembered placeholder values are stored
+func placeholderMemoryPath() string {
+	return filepath.Join(config.DataDir(), "placeholders.json")
+}
+
+// executionLogPath returns where the history of executed commands is stored
+func executionLogPath() string {
+	return filepath.Join(config.DataDir(), "executions.json")
+}
+
+// promptMissing asks for a value for every placeholder that has none yet,
+// offering the most recently used value as the default. Placeholders set
+// to be remembered get their last value without asking.
+func promptMissing(in io.Reader, out io.Writer, cfg *config.Config, example *types.Example, vars map[string]string, memory *history.Memory) (map[string]string, error) {
+	missing := example.Missing(vars)
+	if len(missing) == 0 {
+		return vars, nil
+	}
+
+	filled := make(map[string]string, len(vars)+len(missing))
+	for name, value := range vars {
+		filled[name] = value
+	}
+	var ask []types.Placeholder
+	for _, placeholder := range missing {
+		last := memory.Last(placeholder.Name)
+		if last != "" && !placeholder.Secret() && cfg.MemoryPolicy(placeholder.Name, placeholder.Type) == config.MemoryRemember {
+			filled[placeholder.Name] = last
+			continue
+		}
+		ask = append(ask, placeholder)
+	}
+	return promptValues(in, out, cfg, example, ask, filled, memory)
+}
+
+// promptValues asks for a value for each of placeholders, offering the
+// most recently used value as the default unless the placeholder's values
+// are forgotten, and returns vars with them set. Values the suggestion
+// providers know of, such as SSH hosts, are listed above the prompt.
+func promptValues(in io.Reader, out io.Writer, cfg *config.Config, example *types.Example, placeholders []types.Placeholder, vars map[string]string, memory *history.Memory) (map[string]string, error) {
+	if len(placeholders) == 0 {
+		return vars, nil
+	}
+
+	filled := make(map[string]string, len(vars)+len(placeholders))
+	for name, value := range vars {
+		filled[name] = value
+	}
+
+	fmt.Fprintf(out, "%s\n", example.Command)
+	reader := bufio.NewReader(in)
+	suggestions := suggest.Default(cfg)
+	for _, placeholder := range placeholders {
+		if placeholder.Secret() {
+			value, err := readSecret(in, out, reader, placeholder)
+			if err != nil {
+				return nil, err
+			}
+			filled[placeholder.Name] = value
+			continue
+		}
+
+		last := memory.Last(placeholder.Name)
+		if cfg.MemoryPolicy(placeholder.Name, placeholder.Type) == config.MemoryForget {
+			last = ""
+		}
+		if values := suggestions.Suggest(placeholder); len(values) > 0 {
+			fmt.Fprintf(out, "  %s suggestions: %s\n", placeholder.Name, strings.Join(values, ", "))
+		}
+		if last != "" {
+			fmt.Fprintf(out, "  %s (%s) [%s]: ", placeholder.Name, placeholder.Type, last)
+		} else {
+			fmt.Fprintf(out, "  %s (%s): ", placeholder.Name, placeholder.Type)
+		}
+
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, fmt.Errorf("failed to read value for %s: %w", placeholder.Name, err)
+		}
+
+		value := strings.TrimSpace(line)
+		if value == "" {
+			value = last
+		}
+		filled[placeholder.Name] = value
+	}
+
+	return filled, nil
+}
+
+// readSecret asks for the value of a secret placeholder, without echoing it
+// when reading from a terminal
+func readSecret(in io.Reader, out io.Writer, reader *bufio.Reader, placeholder types.Placeholder) (string, error) {
+	fmt.Fprintf(out, "  %s (%s, hidden): ", placeholder.Name, placeholder.Type)
+
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		value, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(out)
+		if err != nil {
+			return "", fmt.Errorf("failed to read value for %s: %w", placeholder.Name, err)
+		}
+		return string(value), nil
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read value for %s: %w", placeholder.Name, err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// resolveSecrets expands env: and secret: references in the values of
+// secret placeholders, and looks the missing ones up by name in the
+// configured secrets backend
+func resolveSecrets(cfg *config.Config, example *types.Example, vars map[string]string) (map[string]string, error) {
+	backend, err := secrets.New(cfg.SecretsBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, placeholder := range example.Placeholders {
+		if !placeholder.Secret() {
+			continue
+		}
+		if value := vars[placeholder.Name]; value != "" {
+			resolved, err := secrets.Resolve(value, backend)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", placeholder.Name, err)
+			}
+			vars[placeholder.Name] = resolved
+			continue
+		}
+		if backend == nil {
+			continue
+		}
+		value, err := backend.Lookup(placeholder.Name)
+		if err != nil {
+			if !errors.Is(err, secrets.ErrNotFound) {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			continue
+		}
+		vars[placeholder.Name] = value
+	}
+	return vars, nil
+}
+
+// rememberValues stores the values used for a rendered command so they can be
+// offered as defaults next time. Secrets, and placeholders whose values are
+// set to be forgotten, are never stored.
+func rememberValues(cfg *config.Config, memory *history.Memory, example *types.Example, vars map[string]string) {
+	public := make(map[string]string, len(vars))
+	for _, placeholder := range example.Placeholders {
+		value, ok := vars[placeholder.Name]
+		if ok && !placeholder.Secret() && !types.IsSecretName(placeholder.Name) && cfg.MemoryPolicy(placeholder.Name, placeholder.Type) != config.MemoryForget {
+			public[placeholder.Name] = value
+		}
+	}
+	memory.Remember(public)
+	if err := memory.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save placeholder memory: %v\n", err)
+	}
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/qrcode"
+)
+
+// RenderQR renders a command like RenderCommand and prints it as a QR code
+// above it, to be photographed with a phone or another machine's camera
+func RenderQR(command string, opts RenderOptions) error {
+	cfg, execution, err := renderCommandLine(context.Background(), command, opts)
+	if err != nil {
+		return err
+	}
+	drawing, err := qrDrawing(cfg, execution.Command)
+	if err != nil {
+		return err
+	}
+	fmt.Print(drawing)
+	fmt.Println(execution.Command)
+	return nil
+}
+
+// qrDrawing returns command drawn as a QR code for the configured theme:
+// light modules are drawn as blocks unless the theme is light, as the
+// terminal then draws dark text on a light background
+func qrDrawing(cfg *config.Config, command string) (string, error) {
+	code, err := qrcode.Encode(command)
+	if err != nil {
+		return "", err
+	}
+	return code.Terminal(cfg.Theme != "light"), nil
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/cast"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+	"golang.org/x/term"
+)
+
+// maxCasts is how many recorded casts are kept; older ones are removed
+const maxCasts = 50
+
+// unsafeName matches what a page name can't keep in a cast's file name
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// castDir returns where casts of commands are recorded
+func castDir() string {
+	return filepath.Join(config.DataDir(), "casts")
+}
+
+// recording is a cast being recorded of a command's output
+type recording struct {
+	path     string
+	file     *os.File
+	recorder *cast.Recorder
+}
+
+// startRecording creates the cast of execution, whose command is the one
+// with secrets redacted. The output still shows what the command printed,
+// secrets included.
+func startRecording(execution history.Execution) (*recording, error) {
+	if err := os.MkdirAll(castDir(), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cast directory: %w", err)
+	}
+	name := execution.Time.Format("20060102-150405.000") + "-" + unsafeName.ReplaceAllString(execution.Page, "_") + ".cast"
+	path := filepath.Join(castDir(), name)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cast: %w", err)
+	}
+
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	header := cast.Header{
+		Width:     width,
+		Height:    height,
+		Timestamp: execution.Time.Unix(),
+		Command:   execution.Command,
+		Title:     execution.Page,
+		Env:       map[string]string{"SHELL": os.Getenv("SHELL"), "TERM": os.Getenv("TERM")},
+	}
+	recorder, err := cast.NewRecorder(file, header, appClock)
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	return &recording{path: path, file: file, recorder: recorder}, nil
+}
+
+// finish closes the cast and removes the oldest ones past maxCasts
+func (r *recording) finish() error {
+	err := r.recorder.Close()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	pruneCasts(castDir(), maxCasts)
+	return err
+}
+
+// pruneCasts removes all but the keep newest casts in dir. Their names
+// start with the time they were recorded, so they sort oldest first.
+func pruneCasts(dir string, keep int) {
+	casts, _ := filepath.Glob(filepath.Join(dir, "*.cast"))
+	if len(casts) <= keep {
+		return
+	}
+	sort.Strings(casts)
+	for _, path := range casts[:len(casts)-keep] {
+		os.Remove(path)
+	}
+}
+
+// recorded returns the executions in the history whose cast is still
+// there, newest first
+func recorded() ([]history.Execution, error) {
+	executions, err := history.LoadLog(executionLogPath())
+	if err != nil {
+		return nil, err
+	}
+	var result []history.Execution
+	for _, execution := range executions.Executions {
+		if execution.Cast == "" {
+			continue
+		}
+		if _, err := os.Stat(execution.Cast); err == nil {
+			result = append(result, execution)
+		}
+	}
+	return result, nil
+}
+
+// ListRecordings prints the recorded commands, newest first, numbered for
+// Replay
+func ListRecordings() error {
+	executions, err := recorded()
+	if err != nil {
+		return err
+	}
+	if len(executions) == 0 {
+		fmt.Println("No recorded commands. Set exec.record in the config or run 'tldrpp exec --record'.")
+		return nil
+	}
+	for i, execution := range executions {
+		fmt.Printf("%3d  %s  %s\n", i+1, execution.Time.Local().Format("2006-01-02 15:04"), execution.Command)
+	}
+	return nil
+}
+
+// Replay plays the output of the nth most recent recorded command, 1 for
+// the latest, or of the cast at path if not empty, at the pace it was
+// printed with pauses cut to maxIdle
+func Replay(ctx context.Context, n int, path string, maxIdle time.Duration) error {
+	if path == "" {
+		executions, err := recorded()
+		if err != nil {
+			return err
+		}
+		if len(executions) == 0 {
+			return fmt.Errorf("no recorded commands: set exec.record in the config or run 'tldrpp exec --record'")
+		}
+		if n < 1 || n > len(executions) {
+			return fmt.Errorf("no recorded command %d; 'tldrpp replay --list' shows the %d there are", n, len(executions))
+		}
+		path = executions[n-1].Cast
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open cast: %w", err)
+	}
+	defer file.Close()
+	header, err := cast.Play(ctx, file, os.Stdout, maxIdle)
+	if err != nil {
+		return err
+	}
+	if header.Command != "" {
+		fmt.Fprintf(os.Stderr, "\nReplayed: %s\n", header.Command)
+	}
+	return nil
+}
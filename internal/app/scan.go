@@ -0,0 +1,153 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/platform"
+	"github.com/makalin/tldrpp/internal/plugin"
+	"github.com/makalin/tldrpp/internal/scan"
+	"github.com/makalin/tldrpp/internal/trust"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// helpTimeout bounds how long a tool may take to print its --help
+const helpTimeout = 3 * time.Second
+
+// ScanOptions selects the tools Scan looks at and what it does with those
+// that have no page
+type ScanOptions struct {
+	// Tools limits the scan to these tools; Stub needs them named
+	Tools []string
+	// Stub drafts a custom page for each tool without one from its --help
+	Stub bool
+	// Submit starts a submission to tldr-pages for each drafted page
+	Submit bool
+}
+
+// Scan lists the tools on PATH that have no page, cached or custom. With
+// opts.Stub it drafts a custom page for each of the named tools without
+// one, and with opts.Submit starts a submission of each draft. Only named
+// tools are run with --help, as running every program on PATH is unsafe.
+func Scan(opts ScanOptions) error {
+	if opts.Stub && len(opts.Tools) == 0 {
+		return fmt.Errorf("name the tools to draft pages for, e.g. 'tldrpp scan --stub mytool'")
+	}
+
+	cacheManager, err := openCache()
+	if err != nil {
+		return err
+	}
+	entries, err := cacheManager.ListPages("", nil)
+	if err != nil {
+		return fmt.Errorf("failed to list pages: %w", err)
+	}
+	pages := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		pages[strings.ToLower(entry.Name)] = true
+	}
+
+	tools := scan.Executables(os.Getenv("PATH"))
+	if len(opts.Tools) > 0 {
+		installed := make(map[string]bool, len(tools))
+		for _, tool := range tools {
+			installed[tool] = true
+		}
+		tools = nil
+		for _, tool := range opts.Tools {
+			if !installed[tool] {
+				fmt.Printf("%s is not on PATH\n", tool)
+				continue
+			}
+			tools = append(tools, tool)
+		}
+	}
+
+	missing := scan.Missing(tools, pages)
+	if len(missing) == 0 {
+		fmt.Printf("All %d tools scanned have a page\n", len(tools))
+		return nil
+	}
+	fmt.Printf("%d of %d tools on PATH have no page:\n", len(missing), len(tools))
+	for _, tool := range missing {
+		fmt.Printf("  %s\n", tool)
+	}
+
+	if !opts.Stub {
+		fmt.Println("\nDraft pages for some of them from their --help with 'tldrpp scan --stub <tool>...'")
+		return nil
+	}
+	fmt.Println()
+	for _, tool := range missing {
+		page, err := writeStub(tool)
+		if err != nil {
+			return err
+		}
+		if page == nil || !opts.Submit || len(page.Examples) == 0 {
+			continue
+		}
+		if err := plugin.NewSubmitPlugin(page, &page.Examples[0]).Execute([]string{"init"}); err != nil {
+			return fmt.Errorf("failed to start the submission of %s: %w", tool, err)
+		}
+	}
+	return nil
+}
+
+// writeStub drafts a custom page for tool from its --help and returns it,
+// or nil if the tool has a custom page already. The draft's commands come
+// from the tool rather than the user, so they must be trusted before they
+// run, like those of any page from elsewhere.
+func writeStub(tool string) (*types.Page, error) {
+	path := filepath.Join(config.CustomPagesDir(), platform.Common, tool+".md")
+	if _, err := os.Stat(path); err == nil {
+		fmt.Printf("Skipped %s: %s exists\n", tool, path)
+		return nil, nil
+	}
+
+	content := scan.StubPage(tool, helpOutput(tool))
+	page, err := types.ParsePage(content, types.IndexEntry{Name: tool, Platform: platform.Common, Custom: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the page drafted for %s: %w", tool, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create pages directory: %w", err)
+	}
+	// Marked first, so the page is never there trusted
+	store, err := trust.Load(trustPath())
+	if err != nil {
+		return nil, err
+	}
+	store.MarkForeign(trust.KindPage, platform.Common, tool, foreignScan, appClock.Now())
+	if err := store.Save(); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write page: %w", err)
+	}
+	fmt.Printf("Drafted %s\n", path)
+	return page, nil
+}
+
+// helpOutput returns what tool --help prints, to stdout or stderr, or ""
+// if it fails or takes longer than helpTimeout. Many tools exit with an
+// error after printing their help, so the output counts regardless.
+func helpOutput(tool string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), helpTimeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, tool, "--help")
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil && ctx.Err() != nil {
+		return ""
+	}
+	return out.String()
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/locale"
+	"github.com/makalin/tldrpp/internal/session"
+	"github.com/makalin/tldrpp/internal/tui"
+)
+
+// restoreSession reopens the TUI where the last launch left off. Flags
+// given this time win: --platform over the saved platforms and --language
+// over the saved language; the search query given wins in Run.
+func restoreSession(app *tui.App, cfg *config.Config, cacheManager *cache.Manager, platformFlag bool) {
+	state, err := session.Load(config.SessionFile())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if language == "" && state.Language != "" {
+		language = state.Language
+		cacheManager.SetLanguages(locale.Parse(language))
+	}
+	if platformFlag {
+		state.Platforms = cfg.Platforms
+	}
+	app.Restore(state)
+}
+
+// saveSession keeps where the TUI was left for the next launch
+func saveSession(app *tui.App) {
+	state := app.Session()
+	state.Language = language
+	if err := session.Save(config.SessionFile(), state); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
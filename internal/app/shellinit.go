@@ -0,0 +1,93 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/cache"
+)
+
+// widgetKeyPattern matches the keys the shell widget can be bound to
+var widgetKeyPattern = regexp.MustCompile(`^ctrl-([a-z])$`)
+
+// shellWidgets hold the widget for each shell; %s is the key binding
+var shellWidgets = map[string]string{
+	"bash": `# tldr++ widget: look up the command being typed
+_tldrpp_widget() {
+  local selected
+  selected="$(tldrpp --print -- "$READLINE_LINE")" || return
+  if [ -n "$selected" ]; then
+    READLINE_LINE="$selected"
+    READLINE_POINT=${#READLINE_LINE}
+  fi
+}
+bind -x '"%s": _tldrpp_widget'
+`,
+	"zsh": `# tldr++ widget: look up the command being typed
+_tldrpp_widget() {
+  local selected
+  selected="$(tldrpp --print -- "$BUFFER" </dev/tty)"
+  if [[ -n "$selected" ]]; then
+    BUFFER="$selected"
+    CURSOR=${#BUFFER}
+  fi
+  zle reset-prompt
+}
+zle -N _tldrpp_widget
+bindkey '%s' _tldrpp_widget
+`,
+	"fish": `# tldr++ widget: look up the command being typed
+function _tldrpp_widget
+  set -l selected (tldrpp --print -- (commandline) </dev/tty)
+  if test -n "$selected"
+    commandline -r -- $selected
+  end
+  commandline -f repaint
+end
+bind %s _tldrpp_widget
+`,
+}
+
+// ShellInit prints the shell code that binds the tldr++ widget to key, given
+// as ctrl-<letter>
+func ShellInit(shell, key string) error {
+	widget, ok := shellWidgets[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q: use bash, zsh or fish", shell)
+	}
+
+	match := widgetKeyPattern.FindStringSubmatch(strings.ToLower(key))
+	if match == nil {
+		return fmt.Errorf("unsupported key %q: use ctrl-<letter>, e.g. ctrl-g", key)
+	}
+
+	var binding string
+	switch shell {
+	case "bash":
+		binding = `\C-` + match[1]
+	case "zsh":
+		binding = "^" + match[1]
+	case "fish":
+		binding = `\c` + match[1]
+	}
+
+	fmt.Printf(widget, binding)
+	return nil
+}
+
+// commandLineQuery turns a half-typed command line into a page search: the
+// page for the command and its subcommands, if there is one (git commit ->
+// git-commit), or else the command
+func commandLineQuery(cacheManager *cache.Manager, line string) string {
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return ""
+	}
+	if cacheManager.IsInitialized() {
+		if name := cacheManager.PageName(line); name != "" {
+			return name
+		}
+	}
+	return words[0]
+}
@@ -0,0 +1,199 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/snippet"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// AddSnippet renders an example of command like RenderCommand and saves it,
+// with the values it was filled with, as a named snippet. env sets
+// environment variables for its command, as NAME=value.
+func AddSnippet(name, command string, opts RenderOptions, env []string, overwrite bool) error {
+	if !snippet.ValidName(name) {
+		return fmt.Errorf("invalid snippet name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	for _, entry := range env {
+		if _, _, err := config.ParseEnv(entry); err != nil {
+			return err
+		}
+	}
+
+	cfg, page, example, err := resolveExample(context.Background(), command, opts)
+	if err != nil {
+		return err
+	}
+	opts.Vars = types.ScopeVars(opts.Vars, page.IndexOf(example))
+
+	vars, err := fillVars(cfg, example, opts)
+	if err != nil {
+		return err
+	}
+
+	rendered, err := renderExample(example, vars, opts)
+	if err != nil {
+		return err
+	}
+
+	s := &snippet.Snippet{
+		Name:        name,
+		Page:        page.Name,
+		Platform:    page.Platform,
+		Description: example.Description,
+		Template:    example.Command,
+		Vars:        vars,
+		Command:     rendered,
+		Env:         env,
+	}
+	if err := snippet.NewStore(config.SnippetsDir()).Save(s, overwrite); err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved snippet %s: %s\n", name, rendered)
+	return nil
+}
+
+// ListSnippets prints the saved snippets
+func ListSnippets() error {
+	snippets, err := snippet.NewStore(config.SnippetsDir()).List()
+	if err != nil {
+		return err
+	}
+	if len(snippets) == 0 {
+		fmt.Println("No snippets saved yet. Add one with 'tldrpp snippet add <name> <command>' or 's' in the TUI.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, s := range snippets {
+		fmt.Fprintf(w, "%s\t%s\t(%s)\n", s.Name, s.Command, s.Page)
+	}
+	return w.Flush()
+}
+
+// ShowSnippet prints a snippet's command and where it came from
+func ShowSnippet(name string) error {
+	s, err := snippet.NewStore(config.SnippetsDir()).Load(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n\n", s.Command)
+	fmt.Printf("Page:     %s (%s)\n", s.Page, s.Platform)
+	fmt.Printf("Example:  %s\n", s.Description)
+	fmt.Printf("Template: %s\n", s.Template)
+	printVars(s.Vars)
+	if len(s.Env) > 0 {
+		fmt.Printf("Env:      %s\n", strings.Join(s.Env, " "))
+	}
+	for i, step := range s.Steps {
+		fmt.Printf("Step %d:   %s\n", i+1, step.Template)
+		printVars(step.Vars)
+	}
+	return nil
+}
+
+// printVars prints placeholder values sorted by name
+func printVars(vars map[string]string) {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %s = %s\n", name, vars[name])
+	}
+}
+
+// RunSnippet executes a saved snippet, with exec overriding the exec
+// settings of the config
+func RunSnippet(name string, exec ExecOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	s, err := snippet.NewStore(config.SnippetsDir()).Load(name)
+	if err != nil {
+		return err
+	}
+
+	executions, err := history.LoadLog(executionLogPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	execution, err := snippetExecution(cfg, s)
+	if err != nil {
+		return err
+	}
+	if err := exec.apply(cfg, &execution); err != nil {
+		return err
+	}
+	if exec.DryRun {
+		return printDryRun(os.Stdout, cfg, execution)
+	}
+	err = runCommand(context.Background(), cfg, executions, execution)
+	suggestSudo(execution, err)
+	return err
+}
+
+// snippetExecution returns the command a snippet runs. Secret values aren't
+// saved with a snippet, so they are asked for again, for each step of a
+// snippet saved from several examples.
+func snippetExecution(cfg *config.Config, s *snippet.Snippet) (history.Execution, error) {
+	execution := history.Execution{
+		Page:     s.Page,
+		Platform: s.Platform,
+		Command:  s.Command,
+		Snippet:  s.Name,
+		Template: s.Template,
+		Vars:     s.Vars,
+		Env:      s.Env,
+	}
+	if len(s.Steps) == 0 {
+		return recall(cfg, execution)
+	}
+
+	// The steps run as one command line. It has no single template to
+	// render again, so it is redacted as a whole in history if any step
+	// took a secret. Its values are recorded per step as "N.name".
+	commands := make([]string, len(s.Steps))
+	execution.Template = ""
+	execution.Vars = make(map[string]string)
+	for i, step := range s.Steps {
+		recalled, err := recall(cfg, history.Execution{
+			Page:     s.Page,
+			Platform: s.Platform,
+			Command:  types.NewExample("", step.Template).Render(step.Vars),
+			Template: step.Template,
+			Vars:     step.Vars,
+		})
+		if err != nil {
+			return execution, err
+		}
+		commands[i] = recalled.Command
+		for name, value := range recalled.Vars {
+			execution.Vars[fmt.Sprintf("%d.%s", i+1, name)] = value
+		}
+	}
+	execution.Command = strings.Join(commands, snippet.StepSeparator)
+	return execution, nil
+}
+
+// RemoveSnippet deletes a saved snippet
+func RemoveSnippet(name string) error {
+	if err := snippet.NewStore(config.SnippetsDir()).Remove(name); err != nil {
+		return err
+	}
+	fmt.Printf("Removed snippet %s\n", name)
+	return nil
+}
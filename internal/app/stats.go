@@ -0,0 +1,149 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/stats"
+	"github.com/makalin/tldrpp/internal/tui"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+const (
+	// statsTop is how many pages and examples stats lists
+	statsTop = 10
+	// statsBarWidth is the width of the longest bar in the daily chart
+	statsBarWidth = 40
+)
+
+// StatsReport is the usage summary printed by the stats command
+type StatsReport struct {
+	Pages    []stats.PageUsage    `json:"pages"`
+	Examples []stats.ExampleCount `json:"examples"`
+	Daily    []stats.DayCount     `json:"daily"`
+	// FillRate is the fraction of placeholders given a value, if any
+	// example with placeholders was used
+	FillRate *float64 `json:"fill_rate,omitempty"`
+}
+
+// recordUsage counts the use of an example in the usage stats, if they are
+// enabled
+func recordUsage(cfg *config.Config, page *types.Page, example *types.Example, vars map[string]string) {
+	placeholders := len(example.Placeholders)
+	filled := placeholders - len(example.Missing(vars))
+	updateStats(cfg, func(usage *stats.Stats) {
+		usage.RecordExample(page.Name, page.Platform, example.Command, placeholders, filled, appClock.Now())
+	})
+}
+
+// recordView counts a page being looked at in the usage stats, if they are
+// enabled
+func recordView(cfg *config.Config, page *types.Page) {
+	updateStats(cfg, func(usage *stats.Stats) {
+		usage.RecordView(page.Name, page.Platform, appClock.Now())
+	})
+}
+
+// updateStats applies record to the saved usage stats if they are enabled.
+// Failures are only warned about, as stats never stop a command.
+func updateStats(cfg *config.Config, record func(*stats.Stats)) {
+	if !cfg.Stats {
+		return
+	}
+
+	usage, err := stats.Load(config.StatsFile())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+
+	record(usage)
+	if err := usage.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// Stats prints the top pages and examples, usage over the last days and
+// the placeholder fill rate, or the same as JSON
+func Stats(days int, asJSON bool) error {
+	if days < 1 {
+		return fmt.Errorf("--days must be at least 1, got %d", days)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	usage, err := stats.Load(config.StatsFile())
+	if err != nil {
+		return err
+	}
+
+	if !cfg.Stats && len(usage.Pages) == 0 {
+		fmt.Println("Usage stats are disabled. Enable them with: tldrpp config set stats true")
+		return nil
+	}
+
+	report := StatsReport{
+		Pages:    usage.TopPages(statsTop),
+		Examples: usage.TopExamples(statsTop),
+		Daily:    usage.Daily(days, appClock.Now()),
+	}
+	if rate, ok := usage.FillRate(); ok {
+		report.FillRate = &rate
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	printStats(report)
+	if !cfg.Stats {
+		fmt.Println("\nUsage stats are disabled; these are from before they were turned off.")
+	}
+	return nil
+}
+
+// printStats prints a usage report as text
+func printStats(report StatsReport) {
+	fmt.Println("Top pages")
+	if len(report.Pages) == 0 {
+		fmt.Println("  none yet")
+	}
+	for _, page := range report.Pages {
+		fmt.Printf("  %4d  %s (%s)\n", page.Uses(), page.Page, page.Platform)
+	}
+
+	fmt.Println("\nTop examples")
+	if len(report.Examples) == 0 {
+		fmt.Println("  none yet")
+	}
+	for _, example := range report.Examples {
+		fmt.Printf("  %4d  %s\n", example.Count, example.Example)
+	}
+
+	fmt.Printf("\nLast %d days\n", len(report.Daily))
+	max := 0
+	for _, day := range report.Daily {
+		if day.Count > max {
+			max = day.Count
+		}
+	}
+	for _, day := range report.Daily {
+		bar := 0
+		if max > 0 {
+			bar = day.Count * statsBarWidth / max
+		}
+		fmt.Printf("  %s  %4d  %s\n", day.Day, day.Count, strings.Repeat(tui.Glyph("█", "#"), bar))
+	}
+
+	if report.FillRate != nil {
+		fmt.Printf("\nPlaceholder fill rate: %.0f%%\n", *report.FillRate*100)
+	}
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+
+	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/clock"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/tui"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// CacheStore is the pages cache as rendering and running examples use it:
+// what the TUI uses, and also resolving command lines to pages, telling
+// where the pages come from and filling an empty cache. A *cache.Manager
+// is one.
+type CacheStore interface {
+	tui.CacheStore
+	// ResolvePage finds the page for a command line and returns the words
+	// after the page's name
+	ResolvePage(command string) (*types.Page, string, error)
+	// Origin returns the source the pages come from and whether it is
+	// trusted
+	Origin() (string, bool)
+	InitializeContext(ctx context.Context, progress cache.ProgressFunc) error
+}
+
+// openStore returns the pages cache cfg configures. Tests replace it with
+// an in-memory store, such as the one of package cachetest.
+var openStore = func(cfg *config.Config) CacheStore {
+	return newCacheManager(cfg)
+}
+
+// appClock dates the commands run and measures the age of the cache.
+// Tests replace it with a fake clock.
+var appClock clock.Clock = clock.System{}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/sudo"
+)
+
+// escalated returns the command line execution runs and the environment
+// variables set for it, env being those commandEnv returns: its command,
+// run with sudo when it asks to run as root. sudo resets the environment,
+// so env then goes on the command line instead, and none is left to set.
+// sudo prompts for a password in the terminal itself.
+func escalated(execution history.Execution, env []string) (string, []string, error) {
+	if !execution.Sudo {
+		return execution.Command, env, nil
+	}
+	if err := sudo.Available(); err != nil {
+		return "", nil, err
+	}
+	command := sudo.Wrap(execution.Command, env)
+	if command == strings.TrimSpace(execution.Command) {
+		// Run as root already, or with the command's own sudo
+		return command, env, nil
+	}
+	return command, nil, nil
+}
+
+// suggestSudo points out --sudo when a command that usually needs root
+// failed without it
+func suggestSudo(execution history.Execution, err error) {
+	var exitErr *exec.ExitError
+	if execution.Sudo || !errors.As(err, &exitErr) || !sudo.NeedsRoot(execution.Command) {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Hint: this command usually needs root; run it again with --sudo to use sudo")
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/network"
+	"github.com/makalin/tldrpp/internal/teamsync"
+	"github.com/makalin/tldrpp/internal/trust"
+)
+
+// Sync pushes the custom pages, snippets and placeholder memory changed
+// here to the configured backend and pulls those changed elsewhere, or only
+// one way. It fails if files changed on both sides, leaving them for
+// 'tldrpp sync push --force' or 'tldrpp sync pull --force' to settle. A dry
+// run only lists what would be sent.
+func Sync(push, pull, force, dryRun bool) error {
+	opts := teamsync.Options{Push: push, Pull: pull, Force: force, DryRun: dryRun}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	backend, err := syncBackend(cfg)
+	if err != nil {
+		return err
+	}
+	cipher, err := syncCipher(cfg.Sync)
+	if err != nil {
+		return err
+	}
+	backend = teamsync.Encrypted{Backend: backend, Cipher: cipher}
+
+	stateFile := config.SyncStateFile()
+	state, err := teamsync.LoadState(stateFile)
+	if err != nil {
+		return err
+	}
+	local := teamsync.Local{
+		Pages:    config.CustomPagesDir(),
+		Snippets: config.SnippetsDir(),
+		Memory:   placeholderMemoryPath(),
+	}
+	result, err := teamsync.Sync(local, backend, state, opts)
+	if err != nil {
+		return network.Explain(fmt.Errorf("failed to sync: %w", err))
+	}
+	if !dryRun {
+		if err := markPulled(result.Pulled); err != nil {
+			return err
+		}
+	}
+
+	if dryRun {
+		printFiles("Would push", result.Pushed)
+		printFiles("Would pull", result.Pulled)
+	} else {
+		printFiles("Pushed", result.Pushed)
+		printFiles("Pulled", result.Pulled)
+	}
+	if !pull {
+		printFiles("Changed on the backend, not pulled", result.Waiting)
+	} else if !push {
+		printFiles("Changed here, not pushed", result.Waiting)
+	}
+	printFiles("Changed on both sides", result.Conflicts)
+	if len(result.Pushed)+len(result.Pulled)+len(result.Waiting)+len(result.Conflicts) == 0 {
+		fmt.Println("Everything is in sync")
+	}
+
+	if !dryRun {
+		if err := state.Save(stateFile); err != nil {
+			return err
+		}
+	}
+	if len(result.Conflicts) > 0 {
+		return fmt.Errorf("%d files changed on both sides; keep this machine's with 'tldrpp sync push --force' or the backend's with 'tldrpp sync pull --force'", len(result.Conflicts))
+	}
+	return nil
+}
+
+// markPulled records the pages and snippets pulled as coming from the sync
+// backend, whoever wrote them there, so their commands are confirmed before
+// they first run like those of any page from elsewhere
+func markPulled(pulled []string) error {
+	store, err := trust.Load(trustPath())
+	if err != nil {
+		return err
+	}
+	marked := false
+	for _, file := range pulled {
+		switch parts := strings.Split(file, "/"); {
+		case len(parts) == 3 && parts[0] == "pages" && strings.HasSuffix(parts[2], ".md"):
+			store.MarkForeign(trust.KindPage, parts[1], strings.TrimSuffix(parts[2], ".md"), foreignSync, appClock.Now())
+		case len(parts) == 2 && parts[0] == "snippets" && strings.HasSuffix(parts[1], ".yml"):
+			store.MarkForeign(trust.KindSnippet, "", strings.TrimSuffix(parts[1], ".yml"), foreignSync, appClock.Now())
+		default:
+			continue
+		}
+		marked = true
+	}
+	if !marked {
+		return nil
+	}
+	return store.Save()
+}
+
+// syncBackend returns the backend the config names, reached with its
+// network settings
+func syncBackend(cfg *config.Config) (teamsync.Backend, error) {
+	transport, err := network.Transport(cfg.Network)
+	if err != nil {
+		return nil, err
+	}
+	s := cfg.Sync
+	username, password := os.ExpandEnv(s.Username), os.ExpandEnv(s.Password)
+	switch s.Backend {
+	case "git":
+		if s.URL == "" {
+			return nil, fmt.Errorf("no git remote to sync with, set sync.url")
+		}
+		backend := teamsync.NewGit(s.URL, config.SyncRepoDir())
+		backend.SetEnv(network.GitEnv(cfg.Network))
+		return backend, nil
+	case "webdav":
+		backend, err := teamsync.NewWebDAV(s.URL, username, password)
+		if err != nil {
+			return nil, err
+		}
+		backend.SetTransport(transport)
+		return backend, nil
+	case "s3":
+		creds := teamsync.S3Credentials{AccessKey: username, SecretKey: password}
+		if creds.AccessKey == "" {
+			creds = teamsync.S3Credentials{
+				AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+				SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+				Token:     os.Getenv("AWS_SESSION_TOKEN"),
+			}
+		}
+		region := s.Region
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		backend, err := teamsync.NewS3(s.URL, region, s.Endpoint, creds)
+		if err != nil {
+			return nil, err
+		}
+		backend.SetTransport(transport)
+		return backend, nil
+	default:
+		return nil, fmt.Errorf("no sync backend configured, set sync.backend to git, webdav or s3 and sync.url")
+	}
+}
+
+// syncCipher returns the cipher encrypting sensitive files, or nil if the
+// config sets no passphrase or identity file
+func syncCipher(cfg config.Sync) (*teamsync.Cipher, error) {
+	passphrase := os.ExpandEnv(cfg.Passphrase)
+	switch {
+	case passphrase != "" && cfg.IdentityFile != "":
+		return nil, fmt.Errorf("set sync.passphrase or sync.identity_file, not both")
+	case passphrase != "":
+		return teamsync.NewPassphraseCipher(passphrase)
+	case cfg.IdentityFile != "":
+		return teamsync.NewIdentityCipher(os.ExpandEnv(cfg.IdentityFile))
+	}
+	return nil, nil
+}
+
+// printFiles lists files under a heading, if there are any
+func printFiles(heading string, files []string) {
+	if len(files) == 0 {
+		return
+	}
+	fmt.Printf("%s:\n", heading)
+	for _, file := range files {
+		fmt.Printf("  %s\n", file)
+	}
+}
@@ -0,0 +1,276 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/pack"
+	"github.com/makalin/tldrpp/internal/snippet"
+	"github.com/makalin/tldrpp/internal/trust"
+	"github.com/makalin/tldrpp/internal/tui"
+)
+
+// trustPath returns where the pages the user trusted are stored
+func trustPath() string {
+	return filepath.Join(config.DataDir(), "trust.json")
+}
+
+// confirmTrust reports whether the command of execution may run, asking in
+// the terminal to trust its page or snippet if need be
+func confirmTrust(cfg *config.Config, execution history.Execution) (bool, error) {
+	confirmation, hint, err := trustConfirmation(cfg, execution)
+	if err != nil || confirmation == nil {
+		return err == nil, err
+	}
+	if !isInteractive() {
+		return false, fmt.Errorf("%s", hint)
+	}
+	return askConfirmation(*confirmation), nil
+}
+
+// trustConfirmation returns the confirmation trusting the page or snippet
+// of execution, and how to trust it outside a terminal, or nil if its
+// command may run as it is. The user's own custom pages and snippets, and
+// pages from the official archive or a trusted source, always may; those
+// pulled from the sync backend or drafted by scan --stub, and other pages, including those of page
+// packs, need the user to trust them once, which accepting the
+// confirmation remembers.
+func trustConfirmation(cfg *config.Config, execution history.Execution) (*tui.Confirmation, string, error) {
+	if execution.Page == "" && execution.Snippet == "" {
+		return nil, "", nil
+	}
+	store, err := trust.Load(trustPath())
+	if err != nil {
+		return nil, "", err
+	}
+	if execution.Snippet != "" {
+		if source, ok := store.ForeignSource(trust.KindSnippet, "", execution.Snippet); ok {
+			confirmation, hint := foreignConfirmation(store, trust.KindSnippet, "", execution.Snippet, source, execution)
+			return confirmation, hint, nil
+		}
+	}
+	if execution.Page == "" {
+		return nil, "", nil
+	}
+	// The user's own pages are trusted
+	if isCustomPage(execution.Platform, execution.Page) {
+		if source, ok := store.ForeignSource(trust.KindPage, execution.Platform, execution.Page); ok {
+			confirmation, hint := foreignConfirmation(store, trust.KindPage, execution.Platform, execution.Page, source, execution)
+			return confirmation, hint, nil
+		}
+		return nil, "", nil
+	}
+
+	source, trusted := pageOrigin(openStore(cfg), execution.Platform, execution.Page)
+	if trusted || store.Trusted(source, execution.Platform, execution.Page) {
+		return nil, "", nil
+	}
+
+	origin := "an untrusted source"
+	if source != "" {
+		origin = fmt.Sprintf("the untrusted source %q", source)
+	}
+	hint := fmt.Sprintf("page %s comes from %s; run 'tldrpp trust add %s' to allow its commands", execution.Page, origin, execution.Page)
+	return &tui.Confirmation{
+		Message:  fmt.Sprintf("Page %s comes from %s: %s", execution.Page, origin, execution.Command),
+		Question: "Trust this page and run its commands from now on?",
+		Accept: func() error {
+			store.Trust(source, execution.Platform, execution.Page, appClock.Now())
+			return store.Save()
+		},
+	}, hint, nil
+}
+
+// Sources of custom pages and snippets that aren't the user's own
+const (
+	foreignSync = "sync"
+	foreignScan = "scan"
+)
+
+// foreignOrigins describe where custom pages and snippets that aren't the
+// user's own come from, by their source in the trust store
+var foreignOrigins = map[string]string{
+	foreignSync: "the sync backend",
+	foreignScan: "a draft of the tool's --help output",
+}
+
+// foreignOrigin describes where a custom page or snippet from source comes
+// from
+func foreignOrigin(source string) string {
+	if origin, ok := foreignOrigins[source]; ok {
+		return origin
+	}
+	return source
+}
+
+// foreignConfirmation returns the confirmation trusting a custom page or
+// snippet that came from source, which makes it the user's own, and how to
+// trust it outside a terminal. The environment variables a snippet sets are
+// shown with its command, as they can change what it runs.
+func foreignConfirmation(store *trust.Store, kind, platform, name, source string, execution history.Execution) (*tui.Confirmation, string) {
+	command := execution.Command
+	if len(execution.Env) > 0 {
+		command = strings.Join(execution.Env, " ") + " " + command
+	}
+	origin := foreignOrigin(source)
+	add := "tldrpp trust add " + name
+	if kind == trust.KindSnippet {
+		add = "tldrpp trust add --snippet " + name
+	}
+	return &tui.Confirmation{
+		Message:  fmt.Sprintf("%s %s comes from %s: %s", strings.ToUpper(kind[:1])+kind[1:], name, origin, command),
+		Question: fmt.Sprintf("Trust this %s and run its commands from now on?", kind),
+		Accept: func() error {
+			store.Adopt(kind, platform, name)
+			return store.Save()
+		},
+	}, fmt.Sprintf("%s %s comes from %s; run '%s' to allow its commands", kind, name, origin, add)
+}
+
+// isCustomPage reports whether the user has a custom page of that name for
+// platform, which is found before any other
+func isCustomPage(platform, page string) bool {
+	_, err := os.Stat(filepath.Join(config.CustomPagesDir(), platform, page+".md"))
+	return err == nil
+}
+
+// pageOrigin returns the source a page that isn't custom comes from and
+// whether it is trusted: an installed page pack, which never is, or else
+// the source of the cache
+func pageOrigin(cacheManager CacheStore, platform, page string) (string, bool) {
+	if name := pack.Providing(config.PacksDir(), platform, page); name != "" {
+		return "pack " + name, false
+	}
+	return cacheManager.Origin()
+}
+
+// TrustList prints where the cached pages come from and the pages trusted
+// individually
+func TrustList() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	source, trusted := newCacheManager(cfg).Origin()
+	if source == "" {
+		source = "unknown"
+	}
+	if trusted {
+		fmt.Printf("Pages come from %s, which is trusted.\n", source)
+	} else {
+		fmt.Printf("Pages come from %s, which is not trusted: each page must be trusted before its commands run.\n", source)
+	}
+
+	store, err := trust.Load(trustPath())
+	if err != nil {
+		return err
+	}
+	if grants := store.List(); len(grants) > 0 {
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PAGE\tPLATFORM\tSOURCE\tTRUSTED")
+		for _, g := range grants {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", g.Page, g.Platform, g.Source, g.Time.Local().Format("2006-01-02 15:04"))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if foreign := store.ListForeign(); len(foreign) > 0 {
+		fmt.Println("\nCustom pages and snippets to trust before their commands run:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KIND\tNAME\tPLATFORM\tSOURCE\tWRITTEN")
+		for _, f := range foreign {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.Kind, f.Name, f.Platform, f.Source, f.Time.Local().Format("2006-01-02 15:04"))
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+// TrustAdd trusts the page found for command as published by the current
+// source, or the custom page found for it when that came from elsewhere
+func TrustAdd(command string) error {
+	cacheManager, err := loadCache()
+	if err != nil {
+		return err
+	}
+
+	page, err := cacheManager.FindPage(command)
+	if err != nil {
+		return fmt.Errorf("command not found: %w", err)
+	}
+	store, err := trust.Load(trustPath())
+	if err != nil {
+		return err
+	}
+	if isCustomPage(page.Platform, page.Name) {
+		return adopt(store, trust.KindPage, page.Platform, page.Name)
+	}
+
+	source, trusted := pageOrigin(cacheManager, page.Platform, page.Name)
+	if trusted {
+		fmt.Printf("Page %s comes from %s, which is already trusted.\n", page.Name, source)
+		return nil
+	}
+	store.Trust(source, page.Platform, page.Name, appClock.Now())
+	if err := store.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Trusted %s (%s) from %s.\n", page.Name, page.Platform, source)
+	return nil
+}
+
+// TrustAddSnippet trusts a snippet that came from elsewhere, such as pulled
+// from the sync backend
+func TrustAddSnippet(name string) error {
+	if _, err := snippet.NewStore(config.SnippetsDir()).Load(name); err != nil {
+		return err
+	}
+	store, err := trust.Load(trustPath())
+	if err != nil {
+		return err
+	}
+	return adopt(store, trust.KindSnippet, "", name)
+}
+
+// adopt trusts a custom page or snippet that came from elsewhere, making it
+// the user's own
+func adopt(store *trust.Store, kind, platform, name string) error {
+	source, ok := store.ForeignSource(kind, platform, name)
+	if !ok {
+		fmt.Printf("The %s %s is your own, which is already trusted.\n", kind, name)
+		return nil
+	}
+	store.Adopt(kind, platform, name)
+	if err := store.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Trusted the %s %s from %s.\n", kind, name, foreignOrigin(source))
+	return nil
+}
+
+// TrustRemove revokes the trust given to a page, on one platform or all
+func TrustRemove(name, platform string) error {
+	store, err := trust.Load(trustPath())
+	if err != nil {
+		return err
+	}
+
+	removed := store.Revoke(platform, name)
+	if removed == 0 {
+		return fmt.Errorf("page %s is not trusted", name)
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Revoked trust in %s.\n", name)
+	return nil
+}
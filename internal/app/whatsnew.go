@@ -0,0 +1,174 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/diff"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/snippet"
+	"github.com/makalin/tldrpp/internal/stats"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// WhatsNew lists the pages the last cache update added, changed and removed
+// on the given platforms, or the configured ones, or prints them as JSON.
+// With mine set it only lists the pages the user uses, showing how each
+// changed.
+func WhatsNew(platforms []string, mine, asJSON bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if len(platforms) == 0 {
+		platforms = cfg.Platforms
+	}
+
+	cacheManager, err := openCache()
+	if err != nil {
+		return err
+	}
+	changes, err := cacheManager.Changes()
+	if err != nil {
+		return err
+	}
+	if changes == nil {
+		changes = &cache.Changes{}
+	}
+	changes = changes.Filter(platforms)
+	if mine {
+		changes = changes.Only(usedPages())
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal changes: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if changes.UpdatedAt.IsZero() {
+		fmt.Println("No changes recorded yet, they are listed after the next 'tldrpp update'")
+		return nil
+	}
+	fmt.Printf("Changes in the update of %s\n", changes.UpdatedAt.Format(time.RFC1123))
+	if changes.Empty() {
+		fmt.Println("No pages were added, changed or removed")
+		return nil
+	}
+	printChanged("New", changes.Added)
+	printChanged("Updated", changes.Updated)
+	printChanged("Removed", changes.Removed)
+	if mine {
+		for _, entry := range changes.Updated {
+			printPageDiff(cacheManager, entry)
+		}
+	}
+	return nil
+}
+
+// ChangeSummary describes what the last cache update changed, pointing out
+// the pages the user uses, or returns "" if it recorded nothing
+func ChangeSummary() string {
+	cacheManager, err := openCache()
+	if err != nil {
+		return ""
+	}
+	changes, err := cacheManager.Changes()
+	if err != nil || changes == nil {
+		return ""
+	}
+	if changes.Empty() {
+		return "No pages changed"
+	}
+	summary := fmt.Sprintf("%d new, %d updated and %d removed pages, see 'tldrpp whatsnew'",
+		len(changes.Added), len(changes.Updated), len(changes.Removed))
+	if names := changedNames(changes.Only(usedPages())); len(names) > 0 {
+		summary += fmt.Sprintf("\nPages you use changed: %s, see 'tldrpp whatsnew --mine'", strings.Join(names, ", "))
+	}
+	return summary
+}
+
+// changedNames returns the names of the pages that were changed or removed
+func changedNames(changes *cache.Changes) []string {
+	var names []string
+	for _, entry := range changes.Updated {
+		names = append(names, entry.Name)
+	}
+	for _, entry := range changes.Removed {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+// usedPages returns the names of the pages the user has run commands from,
+// viewed or saved snippets of. Sources that can't be read are skipped.
+func usedPages() map[string]bool {
+	used := make(map[string]bool)
+	if executions, err := history.LoadLog(executionLogPath()); err == nil {
+		for _, e := range executions.Executions {
+			used[e.Page] = true
+		}
+	}
+	if usage, err := stats.Load(config.StatsFile()); err == nil {
+		for _, page := range usage.Pages {
+			used[page.Page] = true
+		}
+	}
+	if snippets, err := snippet.NewStore(config.SnippetsDir()).List(); err == nil {
+		for _, s := range snippets {
+			used[s.Page] = true
+		}
+	}
+	delete(used, "")
+	return used
+}
+
+// printPageDiff shows how a page changed in the last update, line by line
+func printPageDiff(cacheManager *cache.Manager, entry types.IndexEntry) {
+	fmt.Printf("\n--- %s (%s)\n", entry.Name, entry.Platform)
+	previous, err := cacheManager.PreviousVersion(entry)
+	if err != nil {
+		fmt.Println("  previous version not available")
+		return
+	}
+	page, err := cacheManager.LoadPage(entry)
+	if err != nil {
+		fmt.Printf("  %v\n", err)
+		return
+	}
+
+	for _, op := range diff.Lines(previous, page.RawContent) {
+		prefix := "  "
+		switch op.Kind {
+		case diff.Delete:
+			prefix = "- "
+		case diff.Insert:
+			prefix = "+ "
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(op.Text, "\n"), "\n") {
+			fmt.Println(strings.TrimRight(prefix+line, " "))
+		}
+	}
+}
+
+// printChanged lists changed pages under a title, skipping empty lists
+func printChanged(title string, entries []types.IndexEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Printf("\n%s (%d):\n", title, len(entries))
+	for _, entry := range entries {
+		if entry.Description != "" {
+			fmt.Printf("  %-24s %s\n", fmt.Sprintf("%s (%s)", entry.Name, entry.Platform), entry.Description)
+		} else {
+			fmt.Printf("  %s (%s)\n", entry.Name, entry.Platform)
+		}
+	}
+}
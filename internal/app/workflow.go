@@ -0,0 +1,174 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/tui"
+	"github.com/makalin/tldrpp/internal/workflow"
+)
+
+// WorkflowOptions controls how a workflow is filled in and stepped through
+type WorkflowOptions struct {
+	// Vars holds named placeholder values, overriding the workflow's defaults
+	Vars map[string]string
+	// NoPrompt disables asking for missing placeholder values on a terminal
+	NoPrompt bool
+	// Yes runs every step without asking first
+	Yes bool
+	// TUI steps through the workflow in the terminal UI
+	TUI bool
+}
+
+// ListWorkflows prints the available workflows
+func ListWorkflows() error {
+	workflows, err := workflow.NewStore(config.WorkflowsDir()).List()
+	if err != nil {
+		return err
+	}
+	if len(workflows) == 0 {
+		fmt.Printf("No workflows yet. Write one as YAML in %s.\n", config.WorkflowsDir())
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, wf := range workflows {
+		fmt.Fprintf(w, "%s\t%d steps\t%s\n", wf.Name, len(wf.Steps), wf.Description)
+	}
+	return w.Flush()
+}
+
+// ShowWorkflow prints a workflow's steps and placeholders
+func ShowWorkflow(name string) error {
+	wf, err := workflow.NewStore(config.WorkflowsDir()).Load(name)
+	if err != nil {
+		return err
+	}
+
+	if wf.Description != "" {
+		fmt.Printf("%s\n\n", wf.Description)
+	}
+	for i, step := range wf.Steps {
+		fmt.Printf("%d. %s\n   %s\n", i+1, step.Description, step.Command)
+	}
+	if placeholders := wf.Example().Placeholders; len(placeholders) > 0 {
+		fmt.Println("\nPlaceholders:")
+		for _, placeholder := range placeholders {
+			if value := wf.Vars[placeholder.Name]; value != "" {
+				fmt.Printf("  %s = %s\n", placeholder.Name, value)
+			} else {
+				fmt.Printf("  %s (%s)\n", placeholder.Name, placeholder.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// RunWorkflow fills in a workflow's placeholders once and runs its steps in
+// order, asking before each one. It stops at the first failing step.
+func RunWorkflow(name string, opts WorkflowOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	wf, err := workflow.NewStore(config.WorkflowsDir()).Load(name)
+	if err != nil {
+		return err
+	}
+
+	vars := make(map[string]string, len(wf.Vars)+len(opts.Vars))
+	for name, value := range wf.Vars {
+		vars[name] = value
+	}
+	for name, value := range opts.Vars {
+		vars[name] = value
+	}
+	vars, err = fillVars(cfg, wf.Example(), RenderOptions{Vars: vars, NoPrompt: opts.NoPrompt})
+	if err != nil {
+		return err
+	}
+
+	executions, err := history.LoadLog(executionLogPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	examples := wf.Examples()
+	execution := func(step int, command string) history.Execution {
+		return history.Execution{
+			Page:     wf.Steps[step].Page,
+			Platform: wf.Steps[step].Platform,
+			Command:  command,
+			Template: examples[step].Command,
+			Vars:     vars,
+		}
+	}
+	run := func(step int, command string) error {
+		return runCommand(context.Background(), cfg, executions, execution(step, command))
+	}
+
+	if opts.TUI {
+		if !isInteractive() {
+			return fmt.Errorf("--tui needs a terminal")
+		}
+		// The TUI confirms each step's command in a dialog before running it
+		return tui.RunWorkflow(cfg, wf, vars, func(step int, command string) ([]tui.Confirmation, error) {
+			return commandConfirmations(cfg, execution(step, command))
+		}, func(step int, command string) error {
+			return runConfirmed(context.Background(), cfg, executions, execution(step, command), nil)
+		})
+	}
+	if !opts.Yes && !isInteractive() {
+		return fmt.Errorf("workflow steps need confirming on a terminal; pass --yes to run them all")
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for i, example := range examples {
+		command := example.Render(vars)
+		shown, _, _ := example.Redact(vars)
+		fmt.Printf("[%d/%d] %s\n  %s\n", i+1, len(examples), example.Description, shown)
+
+		if !opts.Yes {
+			answer := askStep(reader)
+			if answer == "s" {
+				fmt.Println("Skipped.")
+				continue
+			}
+			if answer == "a" {
+				return workflow.ErrAborted
+			}
+		}
+
+		if err := run(i, command); err != nil {
+			return fmt.Errorf("step %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// askStep asks whether to run, skip or abort a step and returns "y", "s"
+// or "a". The end of input aborts.
+func askStep(reader *bufio.Reader) string {
+	for {
+		fmt.Print("Run this step? [Y]es, [s]kip, [a]bort: ")
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "a"
+		}
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "", "y", "yes":
+			return "y"
+		case "s", "skip":
+			return "s"
+		case "a", "abort", "q":
+			return "a"
+		}
+	}
+}
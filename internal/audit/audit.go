@@ -0,0 +1,301 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxSize is the size at which the audit log is rotated
+	DefaultMaxSize = 5 << 20
+	// DefaultMaxAge is how long rotated audit logs are kept
+	DefaultMaxAge = 90 * 24 * time.Hour
+)
+
+// Record is one executed command in the audit log
+type Record struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Cwd      string    `json:"cwd"`
+	Page     string    `json:"page"`
+	Platform string    `json:"platform"`
+	Command  string    `json:"command"`
+	ExitCode int       `json:"exit_code"`
+	// Signal names the signal that interrupted the command, if any
+	Signal string `json:"signal,omitempty"`
+	// TimedOut is set when the command was stopped for running too long
+	TimedOut bool `json:"timed_out,omitempty"`
+	// Sudo is set when the command was run as root with sudo
+	Sudo bool `json:"sudo,omitempty"`
+	// Duration is in milliseconds
+	Duration int64 `json:"duration_ms"`
+}
+
+// Failed reports whether the command exited unsuccessfully
+func (r Record) Failed() bool {
+	return r.ExitCode != 0
+}
+
+// Filter selects audit records; zero fields match everything
+type Filter struct {
+	Since  time.Time
+	Page   string
+	Failed bool
+	// Text matches records whose command, page or working directory
+	// contains it, ignoring case
+	Text string
+}
+
+// Match reports whether r is selected by the filter
+func (f Filter) Match(r Record) bool {
+	if !f.Since.IsZero() && r.Time.Before(f.Since) {
+		return false
+	}
+	if f.Page != "" && r.Page != f.Page {
+		return false
+	}
+	if f.Failed && !r.Failed() {
+		return false
+	}
+	if f.Text != "" {
+		text := strings.ToLower(f.Text)
+		if !strings.Contains(strings.ToLower(r.Command), text) &&
+			!strings.Contains(strings.ToLower(r.Page), text) &&
+			!strings.Contains(strings.ToLower(r.Cwd), text) {
+			return false
+		}
+	}
+	return true
+}
+
+// Log is an append-only JSONL audit log. Once the file grows past MaxSize it
+// is moved aside with a timestamp suffix, and rotated files older than
+// MaxAge are removed.
+type Log struct {
+	path    string
+	MaxSize int64
+	MaxAge  time.Duration
+	now     func() time.Time
+}
+
+// New returns the audit log stored at path
+func New(path string) *Log {
+	return &Log{
+		path:    path,
+		MaxSize: DefaultMaxSize,
+		MaxAge:  DefaultMaxAge,
+		now:     time.Now,
+	}
+}
+
+// Path returns the file the log appends to
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes a record to the log, rotating it first if needed
+func (l *Log) Append(r Record) error {
+	if r.Time.IsZero() {
+		r.Time = l.now()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	if err := l.rotate(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// rotate moves the log aside when it is too large and prunes old rotations
+func (l *Log) rotate() error {
+	info, err := os.Stat(l.path)
+	if err == nil && l.MaxSize > 0 && info.Size() >= l.MaxSize {
+		rotated := l.rotatedPath(l.now())
+		if err := os.Rename(l.path, rotated); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	}
+
+	if l.MaxAge <= 0 {
+		return nil
+	}
+	files, err := l.rotated()
+	if err != nil {
+		return err
+	}
+	cutoff := l.now().Add(-l.MaxAge)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			return fmt.Errorf("failed to remove old audit log: %w", err)
+		}
+	}
+	return nil
+}
+
+// rotatedPath names the file the log is moved to when rotated at t
+func (l *Log) rotatedPath(t time.Time) string {
+	ext := filepath.Ext(l.path)
+	base := strings.TrimSuffix(l.path, ext)
+	return fmt.Sprintf("%s-%s%s", base, t.UTC().Format("20060102T150405.000"), ext)
+}
+
+// rotated lists the rotated log files, oldest first
+func (l *Log) rotated() ([]string, error) {
+	ext := filepath.Ext(l.path)
+	pattern := strings.TrimSuffix(l.path, ext) + "-*" + ext
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	// The timestamp suffix sorts chronologically
+	sort.Strings(files)
+	return files, nil
+}
+
+// Read returns the records selected by filter from the log and its
+// rotations, oldest first. Lines that cannot be parsed are skipped.
+func (l *Log) Read(filter Filter) ([]Record, error) {
+	files, err := l.rotated()
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, l.path)
+
+	var records []Record
+	for _, file := range files {
+		read, err := readFile(file, filter)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, read...)
+	}
+	return records, nil
+}
+
+// readFile reads the records selected by filter from one log file
+func readFile(path string, filter Filter) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		if filter.Match(r) {
+			records = append(records, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return records, nil
+}
+
+// Formats lists the export formats
+var Formats = []string{"jsonl", "json", "csv"}
+
+// Export writes records to w as jsonl, json or csv
+func Export(w io.Writer, records []Record, format string) error {
+	switch format {
+	case "jsonl", "":
+		encoder := json.NewEncoder(w)
+		for _, r := range records {
+			if err := encoder.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		if records == nil {
+			records = []Record{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"time", "user", "cwd", "page", "platform", "command", "exit_code", "duration_ms", "signal", "timed_out", "sudo"})
+		for _, r := range records {
+			writer.Write([]string{
+				r.Time.Format(time.RFC3339),
+				r.User,
+				r.Cwd,
+				r.Page,
+				r.Platform,
+				r.Command,
+				strconv.Itoa(r.ExitCode),
+				strconv.FormatInt(r.Duration, 10),
+				r.Signal,
+				strconv.FormatBool(r.TimedOut),
+				strconv.FormatBool(r.Sudo),
+			})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unknown export format %q (use %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// ParseSince parses a --since value: a duration back from now such as
+// "36h" or "7d", a date, or an RFC 3339 time
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q (use a duration like 24h or 7d, or a date like 2006-01-02)", value)
+}
@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	l := New(filepath.Join(t.TempDir(), "audit.jsonl"))
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	records := []Record{
+		{Time: base, Page: "tar", Command: "tar -xf a.tar", Cwd: "/src"},
+		{Time: base.Add(time.Hour), Page: "ls", Command: "ls -la", ExitCode: 2},
+		{Time: base.Add(2 * time.Hour), Page: "tar", Command: "tar -cf b.tar dir", ExitCode: 1},
+	}
+	for _, r := range records {
+		if err := l.Append(r); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{"all", Filter{}, []string{"tar -xf a.tar", "ls -la", "tar -cf b.tar dir"}},
+		{"page", Filter{Page: "tar"}, []string{"tar -xf a.tar", "tar -cf b.tar dir"}},
+		{"failed", Filter{Failed: true}, []string{"ls -la", "tar -cf b.tar dir"}},
+		{"since", Filter{Since: base.Add(90 * time.Minute)}, []string{"tar -cf b.tar dir"}},
+		{"text", Filter{Text: "B.TAR"}, []string{"tar -cf b.tar dir"}},
+		{"text matches cwd", Filter{Text: "/src"}, []string{"tar -xf a.tar"}},
+		{"combined", Filter{Page: "tar", Failed: true}, []string{"tar -cf b.tar dir"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.Read(tt.filter)
+			if err != nil {
+				t.Fatalf("Read failed: %v", err)
+			}
+			var commands []string
+			for _, r := range got {
+				commands = append(commands, r.Command)
+			}
+			if strings.Join(commands, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Expected %v, got %v", tt.expected, commands)
+			}
+		})
+	}
+}
+
+func TestReadMissingLog(t *testing.T) {
+	l := New(filepath.Join(t.TempDir(), "audit.jsonl"))
+
+	records, err := l.Read(Filter{})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("Expected no records, got %v", records)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	l := New(filepath.Join(dir, "audit.jsonl"))
+	l.MaxSize = 1
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	for _, command := range []string{"first", "second", "third"} {
+		if err := l.Append(Record{Command: command}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		now = now.Add(time.Second)
+	}
+
+	rotated, err := l.rotated()
+	if err != nil {
+		t.Fatalf("rotated failed: %v", err)
+	}
+	if len(rotated) != 2 {
+		t.Fatalf("Expected 2 rotated files, got %v", rotated)
+	}
+
+	// Rotated files are still read, oldest first
+	records, err := l.Read(Filter{})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(records) != 3 || records[0].Command != "first" || records[2].Command != "third" {
+		t.Errorf("Expected first, second, third, got %v", records)
+	}
+
+	// Rotated files older than MaxAge are removed on the next append
+	old := now.Add(-48 * time.Hour)
+	if err := os.Chtimes(rotated[0], old, old); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	l.MaxSize = 0
+	l.MaxAge = 24 * time.Hour
+	if err := l.Append(Record{Command: "fourth"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if _, err := os.Stat(rotated[0]); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed", rotated[0])
+	}
+	if _, err := os.Stat(rotated[1]); err != nil {
+		t.Errorf("Expected %s to be kept: %v", rotated[1], err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	records := []Record{
+		{Time: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), User: "ada", Page: "tar", Command: "tar -xf \"a b.tar\"", ExitCode: 1, Duration: 42, Sudo: true},
+	}
+
+	tests := []struct {
+		format   string
+		contains string
+	}{
+		{"jsonl", `"exit_code":1`},
+		{"jsonl", `"sudo":true`},
+		{"json", `"duration_ms": 42`},
+		{"csv", `2026-03-01T12:00:00Z,ada,,tar,,"tar -xf ""a b.tar""",1,42,,false,true`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Export(&buf, records, tt.format); err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.contains) {
+				t.Errorf("Expected output to contain %q, got %q", tt.contains, buf.String())
+			}
+		})
+	}
+
+	if err := Export(&bytes.Buffer{}, records, "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{"", time.Time{}, false},
+		{"36h", now.Add(-36 * time.Hour), false},
+		{"7d", now.AddDate(0, 0, -7), false},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"2026-03-01T08:00:00Z", time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSince(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
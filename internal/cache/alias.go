@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/platform"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// DefaultAliases maps common shell aliases and abbreviations to the page of
+// the command they stand for
+var DefaultAliases = map[string]string{
+	"g":   "git",
+	"ga":  "git-add",
+	"gb":  "git-branch",
+	"gc":  "git-commit",
+	"gco": "git-checkout",
+	"gd":  "git-diff",
+	"gl":  "git-pull",
+	"gp":  "git-push",
+	"gst": "git-status",
+	"k":   "kubectl",
+	"d":   "docker",
+	"dc":  "docker-compose",
+	"tf":  "terraform",
+	"py":  "python",
+	"l":   "ls",
+	"la":  "ls",
+	"ll":  "ls",
+	"h":   "history",
+}
+
+// SetAliases sets the user's aliases, which apply on top of DefaultAliases.
+// A user alias is followed even where a page has the alias's name; a
+// built-in one only where no page has.
+func (m *Manager) SetAliases(aliases map[string]string) {
+	m.aliases = make(map[string]string, len(aliases))
+	for alias, name := range aliases {
+		m.aliases[strings.ToLower(alias)] = name
+	}
+}
+
+// resolveAlias returns the page name command is an alias for, or "" if it
+// is none
+func (m *Manager) resolveAlias(index []types.IndexEntry, command string) string {
+	command = strings.ToLower(command)
+	if name, ok := m.aliases[command]; ok {
+		return name
+	}
+	if name, ok := DefaultAliases[command]; ok && m.exactMatch(index, command) < 0 {
+		return name
+	}
+	return ""
+}
+
+// exactMatch returns the position in index of the page named name on the
+// most preferred platform, or -1 if there is none
+func (m *Manager) exactMatch(index []types.IndexEntry, name string) int {
+	found := -1
+	for i, entry := range index {
+		if entry.Name == name && (found < 0 ||
+			platform.Rank(m.platformOrder, entry.Platform) < platform.Rank(m.platformOrder, index[found].Platform)) {
+			found = i
+		}
+	}
+	return found
+}
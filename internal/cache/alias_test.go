@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestAliases(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	m.SetAliases(map[string]string{"TS": "tar-split", "tar": "ls"})
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		// Built-in alias
+		{"ll", "ls"},
+		// User aliases, case-insensitively
+		{"ts", "tar-split"},
+		{"TS", "tar-split"},
+		// A user alias shadows a page
+		{"tar", "ls"},
+		// Not an alias
+		{"tar-split", "tar-split"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			page, err := m.FindPage(tt.command)
+			if err != nil {
+				t.Fatalf("FindPage failed: %v", err)
+			}
+			if page.Name != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, page.Name)
+			}
+
+			entries, err := m.ListPages(tt.command, nil)
+			if err != nil {
+				t.Fatalf("ListPages failed: %v", err)
+			}
+			if len(entries) == 0 || entries[0].Name != tt.expected {
+				t.Errorf("Expected %s to be listed first, got %v", tt.expected, entries)
+			}
+
+			pages, err := m.SearchPages(tt.command, nil)
+			if err != nil {
+				t.Fatalf("SearchPages failed: %v", err)
+			}
+			if len(pages) == 0 || pages[0].Name != tt.expected {
+				t.Errorf("Expected %s to be found first", tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultAliasYieldsToPage(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	// A built-in alias doesn't hide a page of the same name
+	DefaultAliases["tar"] = "ls"
+	defer delete(DefaultAliases, "tar")
+
+	page, err := m.FindPage("tar")
+	if err != nil {
+		t.Fatalf("FindPage failed: %v", err)
+	}
+	if page.Name != "tar" {
+		t.Errorf("Expected tar, got %s", page.Name)
+	}
+}
@@ -0,0 +1,460 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/clock"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/locale"
+	"github.com/makalin/tldrpp/internal/log"
+	"github.com/makalin/tldrpp/internal/platform"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// Manager manages the local cache of tldr pages
+type Manager struct {
+	dir        string
+	sources    []config.Source
+	workers    int
+	retryDelay time.Duration
+	client     *http.Client
+	pages      *pageCache
+	// platformOrder ranks pages with the same relevance by platform
+	platformOrder []string
+	// languages are the page languages to show, most preferred first
+	languages []string
+	// customDir holds the user's own pages
+	customDir string
+	// packsDir holds the installed page packs
+	packsDir string
+	// pin is the pages version updates fetch, "" for the newest
+	pin string
+	// requireSigned refuses pages that aren't signed
+	requireSigned bool
+	// gitEnv gives git the network settings downloads use
+	gitEnv []string
+	// aliases are the user's aliases for page names
+	aliases map[string]string
+	// clock tells the time the cache's age is measured against
+	clock clock.Clock
+
+	// mu guards indexTime, the modification time of the index last read
+	mu        sync.Mutex
+	indexTime time.Time
+}
+
+// New creates a cache manager rooted at dir that downloads pages from the
+// given sources, or from the official archive if there are none
+func New(dir string, sources []config.Source) *Manager {
+	if len(sources) == 0 {
+		sources = config.DefaultSources()
+	}
+	sources = append([]config.Source(nil), sources...)
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Priority < sources[j].Priority
+	})
+
+	// Local archives can be used through file:// URLs
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+
+	restoreBackup(dir)
+
+	return &Manager{
+		dir:        dir,
+		sources:    sources,
+		workers:    defaultWorkers,
+		retryDelay: 500 * time.Millisecond,
+		client:     &http.Client{Timeout: 5 * time.Minute, Transport: transport},
+		pages:      newPageCache(pageCacheSize),
+
+		platformOrder: platform.Order(),
+		languages:     locale.Languages(),
+		clock:         clock.System{},
+	}
+}
+
+// SetClock sets the clock the cache's age, and so whether it outlived its
+// TTL, is measured with; by default the system's
+func (m *Manager) SetClock(c clock.Clock) {
+	m.clock = c
+}
+
+// SetLanguages sets the languages pages are shown in, most preferred first;
+// by default they follow the locale. English is used when a page has no
+// translation into any of them.
+func (m *Manager) SetLanguages(languages []string) {
+	m.languages = languages
+	m.pages.clear()
+}
+
+// Language returns the most preferred page language
+func (m *Manager) Language() string {
+	if len(m.languages) == 0 {
+		return locale.English
+	}
+	return m.languages[0]
+}
+
+// SetPlatformOrder sets the platforms whose pages come first when listing or
+// finding pages; by default the running system's, then common
+func (m *Manager) SetPlatformOrder(order []string) {
+	m.platformOrder = order
+}
+
+// Initialize downloads the pages if the cache is empty
+func (m *Manager) Initialize() error {
+	return m.InitializeContext(context.Background(), nil)
+}
+
+// IsInitialized reports whether the cache has an index
+func (m *Manager) IsInitialized() bool {
+	_, err := os.Stat(filepath.Join(m.dir, indexFile))
+	return err == nil
+}
+
+// Changed reports whether the index was rewritten, e.g. by an update in
+// another process, since this manager last read it
+func (m *Manager) Changed() bool {
+	info, err := os.Stat(filepath.Join(m.dir, indexFile))
+	if err != nil {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.indexTime.IsZero() && !info.ModTime().Equal(m.indexTime)
+}
+
+// Age returns how long ago the cache was last updated
+func (m *Manager) Age() (time.Duration, error) {
+	info, err := os.Stat(filepath.Join(m.dir, indexFile))
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat index: %w", err)
+	}
+	return m.clock.Now().Sub(info.ModTime()), nil
+}
+
+// IsStale reports whether the cache is missing, built at another version
+// than the pinned one, or older than ttl. A ttl of zero or less never
+// expires, and neither does a pinned version.
+func (m *Manager) IsStale(ttl time.Duration) bool {
+	age, err := m.Age()
+	if err != nil || m.Pinned() != m.pin {
+		return true
+	}
+	return m.pin == "" && ttl > 0 && age > ttl
+}
+
+// FindPage finds a page by command name or alias, or by a command with its
+// subcommand such as "git commit", falling back to the closest partial match
+func (m *Manager) FindPage(command string) (*types.Page, error) {
+	return m.FindPageContext(context.Background(), command)
+}
+
+// FindPageContext is like FindPage but gives up when ctx is done
+func (m *Manager) FindPageContext(ctx context.Context, command string) (*types.Page, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	index, err := m.index()
+	if err != nil {
+		return nil, err
+	}
+
+	// Search for exact match first, from the preferred platform
+	if found, _ := m.resolveWords(index, command); found >= 0 {
+		return m.loadPage(index[found])
+	}
+
+	// Search for partial matches
+	query := strings.ToLower(command)
+	var matches []types.IndexEntry
+	for i, entry := range index {
+		if i%searchCheckInterval == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if strings.Contains(strings.ToLower(entry.Name), query) {
+			matches = append(matches, entry)
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no page for %s", command)
+	}
+
+	// Prefix matches first, then alphabetical, then by platform
+	sort.SliceStable(matches, func(i, j int) bool {
+		pi := strings.HasPrefix(strings.ToLower(matches[i].Name), query)
+		pj := strings.HasPrefix(strings.ToLower(matches[j].Name), query)
+		if pi != pj {
+			return pi
+		}
+		ni, nj := strings.ToLower(matches[i].Name), strings.ToLower(matches[j].Name)
+		if ni != nj {
+			return ni < nj
+		}
+		return platform.Rank(m.platformOrder, matches[i].Platform) < platform.Rank(m.platformOrder, matches[j].Platform)
+	})
+
+	return m.loadPage(matches[0])
+}
+
+// ListPages returns the index entries on the given platforms whose name or
+// description contains query, most relevant first and, among equally
+// relevant ones, from the preferred platforms first. Only the index is read,
+// so this stays fast however many pages match; open a page with LoadPage.
+func (m *Manager) ListPages(query string, platforms []string) ([]types.IndexEntry, error) {
+	return m.ListPagesContext(context.Background(), query, platforms)
+}
+
+// searchCheckInterval is how many index entries are matched between checks
+// that a search wasn't cancelled
+const searchCheckInterval = 512
+
+// ListPagesContext is like ListPages but stops early when ctx is done, as
+// it is when the search was typed further
+func (m *Manager) ListPagesContext(ctx context.Context, query string, platforms []string) ([]types.IndexEntry, error) {
+	index, err := m.index()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(query)
+	alias := m.resolveAlias(index, query)
+	results := make([]types.IndexEntry, 0, len(index))
+	scores := make([]int, 0, len(index))
+	ranks := make([]int, 0, len(index))
+	for i, entry := range index {
+		if i%searchCheckInterval == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// Filter by platform if specified
+		if len(platforms) > 0 && !contains(platforms, entry.Platform) {
+			continue
+		}
+
+		// The page an alias stands for comes first
+		if alias != "" && entry.Name == alias {
+			results = append(results, entry)
+			scores = append(scores, aliasRelevance)
+			ranks = append(ranks, platform.Rank(m.platformOrder, entry.Platform))
+			continue
+		}
+
+		if query != "" && !strings.Contains(strings.ToLower(entry.Name), query) &&
+			!strings.Contains(strings.ToLower(entry.Description), query) {
+			continue
+		}
+		results = append(results, entry)
+		scores = append(scores, nameRelevance(entry.Name, entry.Description, query))
+		ranks = append(ranks, platform.Rank(m.platformOrder, entry.Platform))
+	}
+
+	sort.Stable(byScore{results, scores, ranks})
+	if log.Enabled(slog.LevelDebug) {
+		for i := 0; i < len(results) && i < 10; i++ {
+			log.Debug("search result", "query", query, "page", results[i].Name, "platform", results[i].Platform, "score", scores[i], "rank", ranks[i])
+		}
+	}
+
+	return results, nil
+}
+
+// SearchPages returns the pages on the given platforms whose name or
+// description contains query, most relevant first
+func (m *Manager) SearchPages(query string, platforms []string) ([]*types.Page, error) {
+	return m.SearchPagesContext(context.Background(), query, platforms)
+}
+
+// SearchPagesContext is like SearchPages but stops early when ctx is done,
+// as opening every match of a short query takes a while
+func (m *Manager) SearchPagesContext(ctx context.Context, query string, platforms []string) ([]*types.Page, error) {
+	entries, err := m.ListPagesContext(ctx, query, platforms)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := m.index()
+	if err != nil {
+		return nil, err
+	}
+	alias := m.resolveAlias(index, query)
+
+	query = strings.ToLower(query)
+	var results []*types.Page
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := m.loadPage(entry)
+		if err != nil {
+			// Skip pages that can't be loaded
+			continue
+		}
+		results = append(results, page)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return relevance(results[i], query, alias) > relevance(results[j], query, alias)
+	})
+
+	return results, nil
+}
+
+// LoadPage reads and parses the page for an index entry
+func (m *Manager) LoadPage(entry types.IndexEntry) (*types.Page, error) {
+	return m.loadPage(entry)
+}
+
+// loadPage reads and parses a cached page in the most preferred language it
+// is translated into, keeping recently used pages in memory
+func (m *Manager) loadPage(entry types.IndexEntry) (*types.Page, error) {
+	if entry.Custom || entry.Pack != "" {
+		return m.loadCustomPage(entry)
+	}
+
+	key := entry.Platform + "/" + entry.Name
+	if page, ok := m.pages.get(key); ok {
+		return page, nil
+	}
+	log.Debug("loading page", "page", entry.Name, "platform", entry.Platform)
+
+	language := m.translation(entry)
+	if language == locale.English {
+		// Pages parsed before are read as they are, skipping the markdown
+		if page := readParsedPage(m.dir, entry); page != nil {
+			page.Language = language
+			m.pages.add(key, page)
+			return page, nil
+		}
+	}
+
+	var data []byte
+	var err error
+	if language == locale.English {
+		data, err = readPage(m.dir, entry)
+	} else {
+		data, err = readTranslatedPage(m.dir, entry, language)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page %s: %w", entry.Name, err)
+	}
+	page, err := types.ParsePage(string(data), entry)
+	if err != nil {
+		return nil, err
+	}
+	page.Language = language
+	if language == locale.English && entry.Checksum != "" && intact(entry, data) {
+		// Keep the page parsed for next time; the cache may be read-only
+		if err := writeParsedPage(m.dir, entry, entry.Checksum, page); err != nil {
+			log.Debug("failed to keep parsed page", "page", entry.Name, "err", err)
+		}
+	}
+
+	m.pages.add(key, page)
+	return page, nil
+}
+
+// translation returns the most preferred language entry is cached in
+func (m *Manager) translation(entry types.IndexEntry) string {
+	for _, language := range m.languages {
+		if language == locale.English {
+			break
+		}
+		if contains(entry.Languages, language) {
+			return language
+		}
+	}
+	return locale.English
+}
+
+// aliasRelevance is the score of the page a query is an alias for, above
+// any other match
+const aliasRelevance = 1000
+
+// relevance scores how well a page matches a lowercased query, which may be
+// an alias for a page name
+func relevance(page *types.Page, query, alias string) int {
+	if alias != "" && page.Name == alias {
+		return aliasRelevance
+	}
+	score := nameRelevance(page.Name, page.Description, query)
+
+	// Example matches get medium score
+	for _, example := range page.Examples {
+		if strings.Contains(strings.ToLower(example.Description), query) {
+			score += 15
+		}
+	}
+
+	return score
+}
+
+// nameRelevance scores how well a page's name and description match a
+// lowercased query
+func nameRelevance(name, description, query string) int {
+	score := 0
+	if query == "" {
+		return score
+	}
+	name = strings.ToLower(name)
+
+	// Exact name match gets highest score
+	switch {
+	case name == query:
+		score += 100
+	case strings.HasPrefix(name, query):
+		score += 50
+	case strings.Contains(name, query):
+		score += 25
+	}
+
+	// Description match gets lower score
+	if strings.Contains(strings.ToLower(description), query) {
+		score += 10
+	}
+
+	return score
+}
+
+// byScore sorts index entries by descending score, then ascending platform
+// rank
+type byScore struct {
+	entries []types.IndexEntry
+	scores  []int
+	ranks   []int
+}
+
+func (s byScore) Len() int { return len(s.entries) }
+func (s byScore) Less(i, j int) bool {
+	if s.scores[i] != s.scores[j] {
+		return s.scores[i] > s.scores[j]
+	}
+	return s.ranks[i] < s.ranks[j]
+}
+func (s byScore) Swap(i, j int) {
+	s.entries[i], s.entries[j] = s.entries[j], s.entries[i]
+	s.scores[i], s.scores[j] = s.scores[j], s.scores[i]
+	s.ranks[i], s.ranks[j] = s.ranks[j], s.ranks[i]
+}
+
+// contains reports whether list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,588 @@
+package cache
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/clock"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// archive builds a zip with the given files
+func archive(t testing.TB, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// newTestManager returns a manager whose archive is served by a test server
+func newTestManager(t testing.TB, files map[string]string) *Manager {
+	t.Helper()
+
+	data := archive(t, files)
+	server := httptest.NewServer(archiveHandler(data, nil))
+	t.Cleanup(server.Close)
+
+	return newManager(t, server.URL)
+}
+
+// newManager returns a manager for the archive served at baseURL
+func newManager(t testing.TB, baseURL string) *Manager {
+	m := New(filepath.Join(t.TempDir(), "pages"), []config.Source{
+		{Name: "test", URL: baseURL + "/tldr.zip", Checksum: baseURL + "/tldr.sha256sums"},
+	})
+	m.retryDelay = time.Millisecond
+	return m
+}
+
+// archiveHandler serves data as tldr.zip with range support, along with its
+// checksum. Each request is passed to hook first if it is set.
+func archiveHandler(data []byte, hook func(w http.ResponseWriter, r *http.Request) bool) http.Handler {
+	sum := sha256.Sum256(data)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tldr.zip", func(w http.ResponseWriter, r *http.Request) {
+		if hook != nil && !hook(w, r) {
+			return
+		}
+		http.ServeContent(w, r, "tldr.zip", time.Time{}, bytes.NewReader(data))
+	})
+	mux.HandleFunc("/tldr.sha256sums", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  tldr.zip\n", hex.EncodeToString(sum[:]))
+	})
+	return mux
+}
+
+var testPages = map[string]string{
+	"pages/common/tar.md":       "# tar\n\n> Archiving utility.\n\n- Extract an archive:\n\n`tar -xf {{source.tar}}`\n",
+	"pages/common/tar-split.md": "# tar-split\n\n> Split tar archives.\n\n- Split:\n\n`tar-split {{file}}`\n",
+	"pages/linux/ls.md":         "# ls\n\n> List directory contents.\n\n- List files:\n\n`ls`\n",
+	"pages.de/common/tar.md":    "# tar\n\n> Archivierungsprogramm.\n",
+	"LICENSE.md":                "license",
+}
+
+func TestUpdate(t *testing.T) {
+	m := newTestManager(t, testPages)
+
+	if m.IsInitialized() {
+		t.Fatal("Expected empty cache to be uninitialized")
+	}
+
+	var last Progress
+	if err := m.UpdateContext(context.Background(), func(p Progress) { last = p }); err != nil {
+		t.Fatalf("UpdateContext failed: %v", err)
+	}
+
+	if !m.IsInitialized() {
+		t.Error("Expected cache to be initialized after update")
+	}
+	// The German translation is stored too, but not indexed on its own
+	if last.Stage != StageIndex || last.PagesDone != 4 || last.PagesTotal != 4 {
+		t.Errorf("Expected 4/4 pages stored, got %+v", last)
+	}
+
+	index, err := m.loadIndex()
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	if len(index) != 3 {
+		t.Errorf("Expected 3 index entries, got %d", len(index))
+	}
+
+	// A second update replaces the cache in place
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+}
+
+func TestUpdateCancelled(t *testing.T) {
+	m := newTestManager(t, testPages)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := m.UpdateContext(ctx, nil); err == nil {
+		t.Error("Expected an error for a cancelled update")
+	}
+	if m.IsInitialized() {
+		t.Error("Expected cancelled update to leave the cache uninitialized")
+	}
+}
+
+func TestUpdateSkipsEscapingEntries(t *testing.T) {
+	files := map[string]string{
+		"pages/common/ls.md":       testPages["pages/linux/ls.md"],
+		"pages/../evil.md":         "# evil\n\n> Escapes.\n",
+		"pages/./evil.md":          "# evil\n\n> Hidden.\n",
+		"pages../common/evil.md":   "# evil\n\n> Bad language.\n",
+		"tldr/pages/../../evil.md": "# evil\n\n> Escapes further.\n",
+	}
+	m := newTestManager(t, files)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	index, err := m.loadIndex()
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	if len(index) != 1 || index[0].Name != "ls" {
+		t.Errorf("Expected only ls indexed, got %+v", index)
+	}
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(m.dir), "evil*"))
+	if len(matches) > 0 {
+		t.Errorf("Expected nothing written outside the cache, got %q", matches)
+	}
+	for _, name := range []string{"pages/../evil.md", "pages/common/..md", "pages.../common/x.md", "pages/co\\mmon/x.md"} {
+		if _, _, ok := archiveEntry(name); ok {
+			t.Errorf("Expected %q to be skipped", name)
+		}
+	}
+}
+
+func TestSwapRollsBack(t *testing.T) {
+	m := newTestManager(t, testPages)
+	marker := filepath.Join(m.dir, "marker")
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(marker, []byte("old"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	// Installing a staging directory that does not exist fails after the old
+	// cache was moved aside
+	if err := m.swap(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("Expected swap of a missing staging directory to fail")
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("Expected the old cache to be restored after a failed swap: %v", err)
+	}
+	if _, err := os.Stat(backupDir(m.dir)); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup left behind, got %v", err)
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	// Simulate an update interrupted after moving the cache aside
+	if err := os.Rename(m.dir, backupDir(m.dir)); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+
+	restored := New(m.dir, m.sources)
+	if !restored.IsInitialized() {
+		t.Error("Expected the cache to be restored from its backup")
+	}
+}
+
+func TestFindPage(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	tests := []struct {
+		command  string
+		expected string
+		wantErr  bool
+	}{
+		{"tar", "tar", false},
+		{"tar-", "tar-split", false},
+		{"s", "ls", false},
+		{"zip", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.command, func(t *testing.T) {
+			page, err := m.FindPage(test.command)
+			if test.wantErr {
+				if err == nil {
+					t.Error("Expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindPage failed: %v", err)
+			}
+			if page.Name != test.expected {
+				t.Errorf("Expected page '%s', got '%s'", test.expected, page.Name)
+			}
+		})
+	}
+}
+
+func TestSearchPages(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	pages, err := m.SearchPages("tar", []string{"common"})
+	if err != nil {
+		t.Fatalf("SearchPages failed: %v", err)
+	}
+	if len(pages) != 2 || pages[0].Name != "tar" {
+		t.Errorf("Expected tar ranked first of 2 results, got %d results", len(pages))
+	}
+	if pages[0].Description != "Archiving utility" {
+		t.Errorf("Expected description from index, got '%s'", pages[0].Description)
+	}
+
+	pages, err = m.SearchPages("directory", []string{"common"})
+	if err != nil {
+		t.Fatalf("SearchPages failed: %v", err)
+	}
+	if len(pages) != 0 {
+		t.Errorf("Expected platform filter to exclude linux pages, got %d results", len(pages))
+	}
+}
+
+func TestListPagesContextCancelled(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.ListPagesContext(ctx, "tar", nil); err != context.Canceled {
+		t.Errorf("Expected a cancelled search to stop, got %v", err)
+	}
+	if pages, err := m.ListPagesContext(context.Background(), "tar", nil); err != nil || len(pages) == 0 {
+		t.Errorf("Expected tar to be found, got %v, %v", pages, err)
+	}
+}
+
+func TestCancelledLookups(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.SearchPagesContext(ctx, "tar", nil); err != context.Canceled {
+		t.Errorf("Expected a cancelled search to stop, got %v", err)
+	}
+	if _, err := m.FindPageContext(ctx, "tar"); err != context.Canceled {
+		t.Errorf("Expected a cancelled lookup to stop, got %v", err)
+	}
+	if err := m.InitializeContext(ctx, nil); err != nil {
+		t.Errorf("Expected an initialized cache to be left alone, got %v", err)
+	}
+}
+
+func TestBuildReadError(t *testing.T) {
+	m := New(filepath.Join(t.TempDir(), "pages"), nil)
+	var files []pageFile
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("command-%d", i)
+		read := func() ([]byte, error) { return []byte("# " + name + "\n\n> Does things.\n"), nil }
+		if i == 50 {
+			read = func() ([]byte, error) { return nil, fmt.Errorf("disk on fire") }
+		}
+		files = append(files, pageFile{entry: types.IndexEntry{Name: name, Platform: "common"}, name: name + ".md", read: read})
+	}
+
+	err := m.build(context.Background(), config.Source{}, files, filepath.Join(t.TempDir(), "staging"), func(Progress) {})
+	if err == nil || !strings.Contains(err.Error(), "disk on fire") {
+		t.Errorf("Expected the read error, got %v", err)
+	}
+}
+
+// BenchmarkBuild measures compressing and indexing 5000 real pages, the
+// last step of an update
+func BenchmarkBuild(b *testing.B) {
+	pages := corpusPages(b, 5000)
+	var files []pageFile
+	for name, content := range pages {
+		entry, language, _ := archiveEntry(name)
+		content := content
+		files = append(files, pageFile{entry: entry, language: language, name: name,
+			read: func() ([]byte, error) { return []byte(content), nil }})
+	}
+	m := New(filepath.Join(b.TempDir(), "pages"), nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dir := filepath.Join(b.TempDir(), "staging")
+		if err := m.build(context.Background(), config.Source{}, files, dir, func(Progress) {}); err != nil {
+			b.Fatalf("build failed: %v", err)
+		}
+	}
+	b.ReportMetric(float64(len(files)*b.N)/b.Elapsed().Seconds(), "pages/s")
+}
+
+// corpusPages returns the real pages of the parser's test corpus, copied
+// under new names until there are n of them, the size of the full
+// tldr-pages set
+func corpusPages(b *testing.B, n int) map[string]string {
+	b.Helper()
+
+	root := filepath.Join("..", "types", "testdata", "pages")
+	paths, err := filepath.Glob(filepath.Join(root, "*", "*.md"))
+	if err != nil || len(paths) == 0 {
+		b.Fatalf("Failed to list the page corpus: %v", err)
+	}
+	files := make(map[string]string, n)
+	for copies := 0; len(files) < n; copies++ {
+		for _, path := range paths {
+			if len(files) == n {
+				break
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				b.Fatalf("ReadFile failed: %v", err)
+			}
+			platform, name := filepath.Base(filepath.Dir(path)), strings.TrimSuffix(filepath.Base(path), ".md")
+			if copies > 0 {
+				name = fmt.Sprintf("%s-%d", name, copies)
+			}
+			files["pages/"+platform+"/"+name+".md"] = string(data)
+		}
+	}
+	return files
+}
+
+// BenchmarkSearchPages measures search over 5000 real pages, through the
+// index alone as the TUI searches on every keystroke, and loading every
+// match as headless search does
+func BenchmarkSearchPages(b *testing.B) {
+	m := newTestManager(b, corpusPages(b, 5000))
+	if err := m.Update(); err != nil {
+		b.Fatalf("Update failed: %v", err)
+	}
+
+	for _, query := range []string{"git", "tar", "compress", "x"} {
+		b.Run("index/"+query, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := m.ListPages(query, nil); err != nil {
+					b.Fatalf("ListPages failed: %v", err)
+				}
+			}
+		})
+		b.Run("pages/"+query, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := m.SearchPages(query, nil); err != nil {
+					b.Fatalf("SearchPages failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestPlatformOrder(t *testing.T) {
+	m := newTestManager(t, map[string]string{
+		"pages/common/sed.md": "# sed\n\n> Stream editor.\n",
+		"pages/linux/sed.md":  "# sed\n\n> GNU stream editor.\n",
+		"pages/osx/sed.md":    "# sed\n\n> BSD stream editor.\n",
+	})
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	tests := []struct {
+		order    []string
+		expected []string
+	}{
+		{[]string{"osx", "common"}, []string{"osx", "common", "linux"}},
+		{[]string{"linux", "common"}, []string{"linux", "common", "osx"}},
+		{[]string{"linux", "windows", "common"}, []string{"linux", "common", "osx"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.order[0], func(t *testing.T) {
+			m.SetPlatformOrder(test.order)
+
+			entries, err := m.ListPages("sed", nil)
+			if err != nil {
+				t.Fatalf("ListPages failed: %v", err)
+			}
+			var platforms []string
+			for _, entry := range entries {
+				platforms = append(platforms, entry.Platform)
+			}
+			if fmt.Sprint(platforms) != fmt.Sprint(test.expected) {
+				t.Errorf("Expected platforms %v, got %v", test.expected, platforms)
+			}
+
+			page, err := m.FindPage("sed")
+			if err != nil {
+				t.Fatalf("FindPage failed: %v", err)
+			}
+			if page.Platform != test.expected[0] {
+				t.Errorf("Expected the %s page, got %s", test.expected[0], page.Platform)
+			}
+		})
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	tests := []struct {
+		languages   []string
+		command     string
+		language    string
+		description string
+	}{
+		{[]string{"de", "en"}, "tar", "de", "Archivierungsprogramm"},
+		{[]string{"de", "en"}, "ls", "en", "List directory contents"},
+		{[]string{"fr", "de", "en"}, "tar", "de", "Archivierungsprogramm"},
+		{[]string{"en"}, "tar", "en", "Archiving utility"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.languages, test.command), func(t *testing.T) {
+			m.SetLanguages(test.languages)
+			page, err := m.FindPage(test.command)
+			if err != nil {
+				t.Fatalf("FindPage failed: %v", err)
+			}
+			if page.Language != test.language || page.Description != test.description {
+				t.Errorf("Expected %s page %q, got %s page %q", test.language, test.description, page.Language, page.Description)
+			}
+		})
+	}
+
+	info, err := m.Info(time.Hour)
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if info.Languages["en"] != 3 || info.Languages["de"] != 1 {
+		t.Errorf("Expected 3 English and 1 German page, got %v", info.Languages)
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	m := newTestManager(t, testPages)
+
+	if !m.IsStale(time.Hour) {
+		t.Error("Expected a missing cache to be stale")
+	}
+
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if m.IsStale(time.Hour) {
+		t.Error("Expected a fresh cache not to be stale")
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(m.dir, indexFile), old, old); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	if !m.IsStale(time.Hour) {
+		t.Error("Expected a cache older than the TTL to be stale")
+	}
+	if m.IsStale(0) {
+		t.Error("Expected a zero TTL never to expire")
+	}
+}
+
+func TestIsStaleClock(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(m.dir, indexFile))
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	now := clock.NewFake(info.ModTime())
+	m.SetClock(now)
+
+	now.Advance(59 * time.Minute)
+	if m.IsStale(time.Hour) {
+		t.Error("Expected a cache younger than the TTL not to be stale")
+	}
+	now.Advance(2 * time.Minute)
+	if !m.IsStale(time.Hour) {
+		t.Error("Expected a cache older than the TTL to be stale")
+	}
+	if age, err := m.Age(); err != nil || age != 61*time.Minute {
+		t.Errorf("Expected an age of 61m, got %v (err %v)", age, err)
+	}
+}
+
+func TestInfo(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	info, err := m.Info(time.Hour)
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+
+	if info.Pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", info.Pages)
+	}
+	if info.Platforms["common"] != 2 || info.Platforms["linux"] != 1 {
+		t.Errorf("Expected 2 common and 1 linux page, got %v", info.Platforms)
+	}
+	if info.SizeBytes == 0 {
+		t.Error("Expected a non-zero cache size")
+	}
+	if info.Stale {
+		t.Error("Expected a fresh cache")
+	}
+	if info.Source != "test" {
+		t.Errorf("Expected source 'test', got '%s'", info.Source)
+	}
+}
+
+func TestOrigin(t *testing.T) {
+	m := newTestManager(t, testPages)
+
+	if source, trusted := m.Origin(); source != "" || trusted {
+		t.Errorf("Expected an empty cache to have no trusted origin, got %q (%v)", source, trusted)
+	}
+
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if source, trusted := m.Origin(); source != "test" || trusted {
+		t.Errorf("Expected untrusted source 'test', got %q (%v)", source, trusted)
+	}
+
+	// Marking the source trusted applies to a cache built before
+	m.sources[0].Trusted = true
+	if _, trusted := m.Origin(); !trusted {
+		t.Error("Expected the source marked trusted to be trusted")
+	}
+}
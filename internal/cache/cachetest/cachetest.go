@@ -0,0 +1,339 @@
+// Package cachetest provides an in-memory pages cache, so that code using
+// the cache can be tested without downloading pages or touching the home
+// directory.
+package cachetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/clock"
+	"github.com/makalin/tldrpp/internal/locale"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// ErrEmpty is returned for lookups in a store that was never filled
+var ErrEmpty = errors.New("cache not initialized")
+
+// Store is a pages cache held in memory. It serves the pages of its last
+// update, which fetches Upstream, and ages by its clock.
+type Store struct {
+	// Upstream are the pages an update fetches
+	Upstream []*types.Page
+	// Source is where the pages come from, and Trusted whether the user
+	// trusts it
+	Source  string
+	Trusted bool
+	// UpdateErr, if set, fails updates
+	UpdateErr error
+
+	clock clock.Clock
+
+	mu        sync.Mutex
+	pages     []*types.Page
+	updatedAt time.Time
+	updates   int
+}
+
+// New returns a store filled with pages, updated at the time c tells
+func New(c clock.Clock, pages ...*types.Page) *Store {
+	s := NewEmpty(c, pages...)
+	s.pages = pages
+	s.updatedAt = c.Now()
+	return s
+}
+
+// NewEmpty returns a store that holds no pages until it is updated,
+// fetching upstream
+func NewEmpty(c clock.Clock, upstream ...*types.Page) *Store {
+	return &Store{Upstream: upstream, Source: "cachetest", Trusted: true, clock: c}
+}
+
+// Updates returns how many times the store was updated
+func (s *Store) Updates() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updates
+}
+
+// IsInitialized reports whether the store was filled
+func (s *Store) IsInitialized() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.updatedAt.IsZero()
+}
+
+// Age returns how long ago the store was last updated
+func (s *Store) Age() (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.updatedAt.IsZero() {
+		return 0, ErrEmpty
+	}
+	return s.clock.Now().Sub(s.updatedAt), nil
+}
+
+// IsStale reports whether the store is empty or older than ttl, which
+// never expires when zero or less
+func (s *Store) IsStale(ttl time.Duration) bool {
+	age, err := s.Age()
+	return err != nil || ttl > 0 && age > ttl
+}
+
+// Changed reports false, as no other process updates the store
+func (s *Store) Changed() bool {
+	return false
+}
+
+// InitializeContext updates the store if it is empty
+func (s *Store) InitializeContext(ctx context.Context, progress cache.ProgressFunc) error {
+	if s.IsInitialized() {
+		return nil
+	}
+	return s.UpdateContext(ctx, progress)
+}
+
+// UpdateContext fetches Upstream, unless ctx is done or UpdateErr is set
+func (s *Store) UpdateContext(ctx context.Context, progress cache.ProgressFunc) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if s.UpdateErr != nil {
+		return s.UpdateErr
+	}
+	if progress != nil {
+		progress(cache.Progress{Stage: cache.StageIndex, Source: s.Source, PagesDone: len(s.Upstream), PagesTotal: len(s.Upstream)})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages = append([]*types.Page(nil), s.Upstream...)
+	s.updatedAt = s.clock.Now()
+	s.updates++
+	return nil
+}
+
+// Info describes the store
+func (s *Store) Info(ttl time.Duration) (*cache.Info, error) {
+	entries, err := s.ListPages("", nil)
+	if err != nil {
+		return nil, err
+	}
+	info := &cache.Info{
+		Pages:     len(entries),
+		Platforms: make(map[string]int),
+		Languages: map[string]int{locale.English: len(entries)},
+		Stale:     s.IsStale(ttl),
+		Source:    s.Source,
+	}
+	for _, entry := range entries {
+		info.Platforms[entry.Platform]++
+	}
+	s.mu.Lock()
+	info.UpdatedAt = s.updatedAt
+	s.mu.Unlock()
+	return info, nil
+}
+
+// Changes returns nil, as updates record no changes
+func (s *Store) Changes() (*cache.Changes, error) {
+	return nil, nil
+}
+
+// Language returns English, the language of every page
+func (s *Store) Language() string {
+	return locale.English
+}
+
+// Origin returns Source and whether it is trusted
+func (s *Store) Origin() (string, bool) {
+	return s.Source, s.Trusted
+}
+
+// snapshot returns the pages served, or ErrEmpty
+func (s *Store) snapshot() ([]*types.Page, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.updatedAt.IsZero() {
+		return nil, ErrEmpty
+	}
+	return s.pages, nil
+}
+
+// entry returns the index entry of page
+func entry(page *types.Page) types.IndexEntry {
+	return types.IndexEntry{Name: page.Name, Description: page.Description, Platform: page.Platform}
+}
+
+// ListPages returns the pages on the given platforms, or on any platform,
+// whose name or description contains query, those named query first
+func (s *Store) ListPages(query string, platforms []string) ([]types.IndexEntry, error) {
+	return s.ListPagesContext(context.Background(), query, platforms)
+}
+
+// ListPagesContext is like ListPages but fails when ctx is done
+func (s *Store) ListPagesContext(ctx context.Context, query string, platforms []string) ([]types.IndexEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	pages, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(query)
+	var entries []types.IndexEntry
+	for _, page := range pages {
+		if len(platforms) > 0 && !contains(platforms, page.Platform) {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(page.Name), query) &&
+			!strings.Contains(strings.ToLower(page.Description), query) {
+			continue
+		}
+		entries = append(entries, entry(page))
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Name) == query && strings.ToLower(entries[j].Name) != query
+	})
+	return entries, nil
+}
+
+// LoadPage returns the page of an index entry
+func (s *Store) LoadPage(e types.IndexEntry) (*types.Page, error) {
+	pages, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	for _, page := range pages {
+		if page.Name == e.Name && page.Platform == e.Platform {
+			return page, nil
+		}
+	}
+	return nil, fmt.Errorf("no page %s for %s", e.Name, e.Platform)
+}
+
+// FindPage finds the page for a command line, or else the first page whose
+// name contains command
+func (s *Store) FindPage(command string) (*types.Page, error) {
+	page, _, err := s.ResolvePage(command)
+	return page, err
+}
+
+// ResolvePage finds the page for a command line, e.g. the git-commit page
+// for "git commit -m msg", and returns the words after the page's name
+func (s *Store) ResolvePage(command string) (*types.Page, string, error) {
+	pages, err := s.snapshot()
+	if err != nil {
+		return nil, "", err
+	}
+	if page, rest := resolve(pages, command); page != nil {
+		return page, strings.Join(rest, " "), nil
+	}
+	query := strings.ToLower(command)
+	for _, page := range pages {
+		if strings.Contains(strings.ToLower(page.Name), query) {
+			return page, "", nil
+		}
+	}
+	return nil, "", fmt.Errorf("no page for %s", command)
+}
+
+// resolve finds the page named by the longest run of leading words of a
+// command line joined with dashes, and returns the words left over
+func resolve(pages []*types.Page, command string) (*types.Page, []string) {
+	words := strings.Fields(command)
+	n := 0
+	for n < len(words) && !strings.HasPrefix(words[n], "-") && !strings.ContainsAny(words[n], `/\.=`) {
+		n++
+	}
+	for ; n > 0; n-- {
+		name := strings.ToLower(strings.Join(words[:n], "-"))
+		for _, page := range pages {
+			if page.Name == name {
+				return page, words[n:]
+			}
+		}
+	}
+	return nil, words
+}
+
+// PageName returns the name of the page for a command line, or "" if no
+// page has the name
+func (s *Store) PageName(command string) string {
+	pages, err := s.snapshot()
+	if err != nil {
+		return ""
+	}
+	if page, _ := resolve(pages, command); page != nil {
+		return page.Name
+	}
+	return ""
+}
+
+// Subcommands returns the names of the subcommand pages of the page named
+// name in alphabetical order
+func (s *Store) Subcommands(name string) []string {
+	pages, _ := s.snapshot()
+	seen := make(map[string]bool)
+	var names []string
+	for _, page := range pages {
+		if strings.HasPrefix(page.Name, name+"-") && !seen[page.Name] {
+			seen[page.Name] = true
+			names = append(names, page.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RandomPage returns the first page on the given platforms, so that tests
+// picking one know which
+func (s *Store) RandomPage(platforms []string) (types.IndexEntry, error) {
+	entries, err := s.ListPages("", platforms)
+	if err != nil {
+		return types.IndexEntry{}, err
+	}
+	if len(entries) == 0 {
+		return types.IndexEntry{}, fmt.Errorf("no pages to pick from")
+	}
+	return entries[0], nil
+}
+
+// TipOfTheDay picks a page on the given platforms for day, going through
+// them in order a day at a time
+func (s *Store) TipOfTheDay(platforms []string, day time.Time) (types.IndexEntry, error) {
+	entries, err := s.ListPages("", platforms)
+	if err != nil {
+		return types.IndexEntry{}, err
+	}
+	if len(entries) == 0 {
+		return types.IndexEntry{}, fmt.Errorf("no pages to pick from")
+	}
+	return entries[(day.YearDay()-1)%len(entries)], nil
+}
+
+// CustomPath returns "", as the store holds no custom pages
+func (s *Store) CustomPath(types.IndexEntry) string {
+	return ""
+}
+
+// PageURL returns "", as the pages come from no source online
+func (s *Store) PageURL(types.IndexEntry) string {
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package cachetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/clock"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+var testPages = []*types.Page{
+	{Name: "git", Platform: "common", Description: "Distributed version control"},
+	{Name: "git-commit", Platform: "common", Description: "Commit files to the repository"},
+	{Name: "ls", Platform: "linux", Description: "List directory contents"},
+}
+
+func TestResolvePage(t *testing.T) {
+	s := New(clock.NewFake(time.Now()), testPages...)
+
+	page, rest, err := s.ResolvePage("git commit -m msg")
+	if err != nil || page.Name != "git-commit" || rest != "-m msg" {
+		t.Errorf("Expected git-commit with -m msg left, got %v, %q, %v", page, rest, err)
+	}
+	if name := s.PageName("git status"); name != "git" {
+		t.Errorf("Expected git, got %q", name)
+	}
+	if subcommands := s.Subcommands("git"); len(subcommands) != 1 || subcommands[0] != "git-commit" {
+		t.Errorf("Expected [git-commit], got %v", subcommands)
+	}
+	if _, _, err := s.ResolvePage("nosuchpage"); err == nil {
+		t.Error("Expected an unknown command to be an error")
+	}
+}
+
+func TestListPages(t *testing.T) {
+	s := New(clock.NewFake(time.Now()), testPages...)
+
+	entries, err := s.ListPages("git", nil)
+	if err != nil || len(entries) != 2 || entries[0].Name != "git" {
+		t.Errorf("Expected git first of 2 pages, got %v, %v", entries, err)
+	}
+	entries, _ = s.ListPages("", []string{"linux"})
+	if len(entries) != 1 || entries[0].Name != "ls" {
+		t.Errorf("Expected only ls on linux, got %v", entries)
+	}
+	if _, err := s.LoadPage(entries[0]); err != nil {
+		t.Errorf("LoadPage failed: %v", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	now := clock.NewFake(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	s := NewEmpty(now, testPages...)
+	if s.IsInitialized() || !s.IsStale(0) {
+		t.Fatal("Expected an empty store to be stale")
+	}
+	if _, err := s.ListPages("", nil); err != ErrEmpty {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+
+	if err := s.InitializeContext(context.Background(), nil); err != nil {
+		t.Fatalf("InitializeContext failed: %v", err)
+	}
+	now.Advance(2 * time.Hour)
+	if !s.IsStale(time.Hour) || s.IsStale(3*time.Hour) || s.IsStale(0) {
+		t.Error("Expected the store to age by its clock")
+	}
+	info, err := s.Info(time.Hour)
+	if err != nil || info.Pages != 3 || !info.Stale {
+		t.Errorf("Expected a stale store of 3 pages, got %+v, %v", info, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.UpdateContext(ctx, nil); err == nil || s.Updates() != 1 {
+		t.Errorf("Expected a cancelled update to fail, got %v after %d updates", err, s.Updates())
+	}
+}
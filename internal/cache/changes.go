@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/log"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+const (
+	// changesFile records what the last update added, changed and removed
+	changesFile = "changes.json"
+	// previousDir keeps the version of each page the last update changed
+	// from before the update, to show what changed
+	previousDir = "previous"
+)
+
+// Changes lists the pages an update added, changed and removed
+type Changes struct {
+	UpdatedAt time.Time          `json:"updated_at"`
+	Added     []types.IndexEntry `json:"added,omitempty"`
+	Updated   []types.IndexEntry `json:"updated,omitempty"`
+	Removed   []types.IndexEntry `json:"removed,omitempty"`
+}
+
+// Empty reports whether the update changed no page
+func (c *Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Updated) == 0 && len(c.Removed) == 0
+}
+
+// Filter returns the changes to pages on the given platforms; all of them
+// when platforms is empty
+func (c *Changes) Filter(platforms []string) *Changes {
+	if len(platforms) == 0 {
+		return c
+	}
+	return c.keep(func(entry types.IndexEntry) bool {
+		for _, p := range platforms {
+			if entry.Platform == p {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Only returns the changes to the pages with the given names, such as the
+// pages someone uses
+func (c *Changes) Only(names map[string]bool) *Changes {
+	return c.keep(func(entry types.IndexEntry) bool {
+		return names[entry.Name]
+	})
+}
+
+// keep returns the changes to the pages for which keep returns true
+func (c *Changes) keep(keep func(types.IndexEntry) bool) *Changes {
+	kept := func(entries []types.IndexEntry) []types.IndexEntry {
+		var result []types.IndexEntry
+		for _, entry := range entries {
+			if keep(entry) {
+				result = append(result, entry)
+			}
+		}
+		return result
+	}
+	return &Changes{
+		UpdatedAt: c.UpdatedAt,
+		Added:     kept(c.Added),
+		Updated:   kept(c.Updated),
+		Removed:   kept(c.Removed),
+	}
+}
+
+// Changes returns what the last update changed, or nil if it recorded
+// nothing, as after the first download
+func (m *Manager) Changes() (*Changes, error) {
+	data, err := os.ReadFile(filepath.Join(m.dir, changesFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changes: %w", err)
+	}
+
+	var changes Changes
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("failed to parse changes: %w", err)
+	}
+	return &changes, nil
+}
+
+// PreviousVersion returns the content a page had before the last update
+// changed it
+func (m *Manager) PreviousVersion(entry types.IndexEntry) (string, error) {
+	content, err := readPage(filepath.Join(m.dir, previousDir), entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to read previous version of %s: %w", entry.Name, err)
+	}
+	return string(content), nil
+}
+
+// recordChanges compares the index of the cache in dir with that of the
+// freshly built one in staging and records the difference in staging, along
+// with the previous version of each changed page. There
+// is nothing to compare against on the first download or when the old index
+// is damaged.
+func recordChanges(dir, staging string) error {
+	old, err := readJSONIndex(dir)
+	if err != nil {
+		return nil
+	}
+	index, err := readJSONIndex(staging)
+	if err != nil {
+		return err
+	}
+
+	changes := diffIndex(old, index)
+	changes.UpdatedAt = time.Now()
+	log.Info("pages changed", "added", len(changes.Added), "updated", len(changes.Updated), "removed", len(changes.Removed))
+	for _, entry := range changes.Updated {
+		content, err := readPage(dir, entry)
+		if err != nil {
+			continue
+		}
+		if err := writePage(pagePath(filepath.Join(staging, previousDir), entry), content); err != nil {
+			return fmt.Errorf("failed to keep previous version of %s: %w", entry.Name, err)
+		}
+	}
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal changes: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(staging, changesFile), data); err != nil {
+		return fmt.Errorf("failed to write changes: %w", err)
+	}
+	return nil
+}
+
+// readJSONIndex reads index.json in dir
+func readJSONIndex(dir string) ([]types.IndexEntry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		return nil, err
+	}
+	var index []types.IndexEntry
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse index: %w", err)
+	}
+	return index, nil
+}
+
+// diffIndex works out which pages are new in index, which differ from old
+// and which are gone, each sorted by name. Pages are told apart by platform
+// and name and compared by checksum.
+func diffIndex(old, index []types.IndexEntry) *Changes {
+	key := func(entry types.IndexEntry) string { return entry.Platform + "/" + entry.Name }
+	before := make(map[string]types.IndexEntry, len(old))
+	for _, entry := range old {
+		before[key(entry)] = entry
+	}
+
+	changes := &Changes{}
+	for _, entry := range index {
+		previous, ok := before[key(entry)]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, entry)
+		case previous.Checksum != entry.Checksum:
+			changes.Updated = append(changes.Updated, entry)
+		}
+		delete(before, key(entry))
+	}
+	for _, entry := range before {
+		changes.Removed = append(changes.Removed, entry)
+	}
+
+	for _, entries := range [][]types.IndexEntry{changes.Added, changes.Updated, changes.Removed} {
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].Name != entries[j].Name {
+				return entries[i].Name < entries[j].Name
+			}
+			return entries[i].Platform < entries[j].Platform
+		})
+	}
+	return changes
+}
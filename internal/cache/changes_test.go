@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+func TestChanges(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	changes, err := m.Changes()
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+	if changes != nil {
+		t.Errorf("Expected no changes after the first download, got %+v", changes)
+	}
+
+	updated := map[string]string{
+		"pages/common/tar.md":  testPages["pages/common/tar.md"],
+		"pages/linux/ls.md":    "# ls\n\n> List directory contents.\n\n- List all files:\n\n`ls -a`\n",
+		"pages/common/grep.md": "# grep\n\n> Find patterns in files.\n\n- Search a file:\n\n`grep {{pattern}} {{file}}`\n",
+	}
+	server := httptest.NewServer(archiveHandler(archive(t, updated), nil))
+	defer server.Close()
+	m.sources = []config.Source{
+		{Name: "test", URL: server.URL + "/tldr.zip", Checksum: server.URL + "/tldr.sha256sums"},
+	}
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	changes, err = m.Changes()
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+	if changes == nil {
+		t.Fatal("Expected changes to be recorded")
+	}
+
+	names := func(entries []types.IndexEntry) []string {
+		var result []string
+		for _, entry := range entries {
+			result = append(result, entry.Name)
+		}
+		return result
+	}
+	tests := []struct {
+		name     string
+		entries  []types.IndexEntry
+		expected []string
+	}{
+		{"added", changes.Added, []string{"grep"}},
+		{"updated", changes.Updated, []string{"ls"}},
+		{"removed", changes.Removed, []string{"tar-split"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(tt.entries)
+			if len(got) != len(tt.expected) || (len(got) > 0 && got[0] != tt.expected[0]) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+	if changes.UpdatedAt.IsZero() {
+		t.Error("Expected the update time to be recorded")
+	}
+
+	if filtered := changes.Filter([]string{"common"}); len(filtered.Updated) != 0 || len(filtered.Added) != 1 {
+		t.Errorf("Expected only common pages, got %+v", filtered)
+	}
+	if mine := changes.Only(map[string]bool{"ls": true}); len(mine.Updated) != 1 || len(mine.Added)+len(mine.Removed) != 0 {
+		t.Errorf("Expected only ls, got %+v", mine)
+	}
+
+	if len(changes.Updated) == 0 {
+		t.Fatal("Expected an updated page")
+	}
+	previous, err := m.PreviousVersion(changes.Updated[0])
+	if err != nil {
+		t.Fatalf("PreviousVersion failed: %v", err)
+	}
+	if previous != testPages["pages/linux/ls.md"] {
+		t.Errorf("Expected the version from before the update, got %q", previous)
+	}
+	report, err := m.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(report.Problems) != 0 {
+		t.Errorf("Expected previous versions not to be reported, got %v", report.Problems)
+	}
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/pack"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// SetCustomDir sets the directory of the user's own pages, kept as plain
+// markdown in <platform>/<name>.md. They are listed along with the cached
+// pages and replace cached pages of the same name and platform.
+func (m *Manager) SetCustomDir(dir string) {
+	m.customDir = dir
+}
+
+// CustomPath returns the file of a custom page, or "" if entry is a cached
+// page
+func (m *Manager) CustomPath(entry types.IndexEntry) string {
+	if !entry.Custom || m.customDir == "" {
+		return ""
+	}
+	return customPagePath(m.customDir, entry)
+}
+
+// customPagePath returns where a custom page is kept below dir
+func customPagePath(dir string, entry types.IndexEntry) string {
+	return filepath.Join(dir, entry.Platform, entry.Name+".md")
+}
+
+// customEntries lists the custom pages
+func (m *Manager) customEntries() []types.IndexEntry {
+	if m.customDir == "" {
+		return nil
+	}
+	return pageEntries(m.customDir, "")
+}
+
+// pageEntries lists the pages kept as <platform>/<name>.md below dir, the
+// user's own or those installed by pack. Pages that can't be read are
+// skipped.
+func pageEntries(dir, pack string) []types.IndexEntry {
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*.md"))
+	if err != nil {
+		return nil
+	}
+
+	var entries []types.IndexEntry
+	for _, file := range files {
+		entry := types.IndexEntry{
+			Name:     strings.TrimSuffix(filepath.Base(file), ".md"),
+			Platform: filepath.Base(filepath.Dir(file)),
+			Custom:   pack == "",
+			Pack:     pack,
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		page, err := types.ParsePage(string(data), entry)
+		if err != nil {
+			continue
+		}
+		entry.Description = page.Description
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// index returns the cached index with the pages of packs merged in, and
+// the custom pages over those. A later page replaces an earlier one of the
+// same name and platform.
+func (m *Manager) index() ([]types.IndexEntry, error) {
+	index, err := m.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	overlay := append(m.packEntries(), m.customEntries()...)
+	if len(overlay) == 0 {
+		return index, nil
+	}
+	position := make(map[string]int, len(overlay))
+	var replacing []types.IndexEntry
+	for _, entry := range overlay {
+		key := entry.Platform + "/" + entry.Name
+		if i, ok := position[key]; ok {
+			replacing[i] = entry
+			continue
+		}
+		position[key] = len(replacing)
+		replacing = append(replacing, entry)
+	}
+
+	merged := make([]types.IndexEntry, 0, len(index)+len(replacing))
+	for _, entry := range index {
+		if _, ok := position[entry.Platform+"/"+entry.Name]; !ok {
+			merged = append(merged, entry)
+		}
+	}
+	return append(merged, replacing...), nil
+}
+
+// loadCustomPage reads and parses a custom page or a pack's. They are not
+// kept in memory, so edits show up right away.
+func (m *Manager) loadCustomPage(entry types.IndexEntry) (*types.Page, error) {
+	file := customPagePath(m.customDir, entry)
+	if entry.Pack != "" {
+		file = pack.PagePath(m.packsDir, entry.Pack, entry.Platform, entry.Name)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page %s: %w", entry.Name, err)
+	}
+	return types.ParsePage(string(data), entry)
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCustomPages(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	m.SetCustomDir(dir)
+	pages := map[string]string{
+		"common/tar.md":    "# tar\n\n> My own notes on tar.\n\n- Create an archive:\n\n`tar -cf {{target.tar}} {{file}}`\n",
+		"linux/deploy.md":  "# deploy\n\n> Ship the app.\n\n- Deploy:\n\n`./deploy.sh {{env}}`\n",
+		"common/notes.txt": "not a page",
+	}
+	for name, content := range pages {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write page: %v", err)
+		}
+	}
+
+	entries, err := m.ListPages("", nil)
+	if err != nil {
+		t.Fatalf("ListPages failed: %v", err)
+	}
+	tars := 0
+	for _, entry := range entries {
+		if entry.Name == "tar" && entry.Platform == "common" {
+			tars++
+			if !entry.Custom || entry.Description != "My own notes on tar" {
+				t.Errorf("Expected the custom tar page to replace the cached one, got %+v", entry)
+			}
+		}
+	}
+	if tars != 1 {
+		t.Errorf("Expected one common/tar page, got %d", tars)
+	}
+
+	page, err := m.FindPage("deploy")
+	if err != nil {
+		t.Fatalf("FindPage failed: %v", err)
+	}
+	if len(page.Examples) != 1 || page.Examples[0].Command != "./deploy.sh {{env}}" {
+		t.Errorf("Expected the custom deploy page, got %+v", page)
+	}
+
+	// Edits show up without reloading the manager
+	path := filepath.Join(dir, "linux", "deploy.md")
+	os.WriteFile(path, []byte("# deploy\n\n> Ship the app.\n\n- Deploy:\n\n`./deploy.sh --fast {{env}}`\n"), 0644)
+	page, err = m.FindPage("deploy")
+	if err != nil {
+		t.Fatalf("FindPage failed: %v", err)
+	}
+	if page.Examples[0].Command != "./deploy.sh --fast {{env}}" {
+		t.Errorf("Expected the edited command, got '%s'", page.Examples[0].Command)
+	}
+
+	entry := entries[0]
+	for _, e := range entries {
+		if e.Name == "deploy" {
+			entry = e
+		}
+	}
+	if got := m.CustomPath(entry); got != path {
+		t.Errorf("Expected custom path '%s', got '%s'", path, got)
+	}
+}
+
+func TestPackPages(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	packs, custom := t.TempDir(), t.TempDir()
+	m.SetPacksDir(packs)
+	m.SetCustomDir(custom)
+	pages := map[string]string{
+		filepath.Join(packs, "ops", "pack.yml"):                  "name: ops\n",
+		filepath.Join(packs, "ops", "pages", "common", "tar.md"): "# tar\n\n> Tar the ops way.\n\n- Create an archive:\n\n`tar -czf {{target.tar.gz}} {{file}}`\n",
+		filepath.Join(packs, "ops", "pages", "linux", "kctx.md"): "# kctx\n\n> Switch contexts.\n\n- Switch:\n\n`kctx {{name}}`\n",
+		filepath.Join(custom, "linux", "kctx.md"):                "# kctx\n\n> My kctx.\n\n- Switch:\n\n`kctx --mine {{name}}`\n",
+	}
+	for path, content := range pages {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write page: %v", err)
+		}
+	}
+
+	entries, err := m.ListPages("", nil)
+	if err != nil {
+		t.Fatalf("ListPages failed: %v", err)
+	}
+	seen := make(map[string]int)
+	for _, entry := range entries {
+		seen[entry.Platform+"/"+entry.Name]++
+		switch entry.Platform + "/" + entry.Name {
+		case "common/tar":
+			if entry.Pack != "ops" || entry.Custom {
+				t.Errorf("Expected the pack's tar page to replace the cached one, got %+v", entry)
+			}
+		case "linux/kctx":
+			if entry.Pack != "" || !entry.Custom {
+				t.Errorf("Expected the custom kctx page to replace the pack's, got %+v", entry)
+			}
+		}
+	}
+	if seen["common/tar"] != 1 || seen["linux/kctx"] != 1 {
+		t.Errorf("Expected one page each, got %v", seen)
+	}
+
+	page, err := m.FindPage("tar")
+	if err != nil {
+		t.Fatalf("FindPage failed: %v", err)
+	}
+	if page.Examples[0].Command != "tar -czf {{target.tar.gz}} {{file}}" {
+		t.Errorf("Expected the pack's tar page, got %+v", page)
+	}
+	for _, entry := range entries {
+		if entry.Pack != "" && m.CustomPath(entry) != "" {
+			t.Errorf("Expected pack pages not to be editable, got %s", m.CustomPath(entry))
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// RandomPage picks a page on the given platforms, or on any platform, at
+// random
+func (m *Manager) RandomPage(platforms []string) (types.IndexEntry, error) {
+	entries, err := m.ListPages("", platforms)
+	if err != nil {
+		return types.IndexEntry{}, err
+	}
+	if len(entries) == 0 {
+		return types.IndexEntry{}, fmt.Errorf("no pages to pick from")
+	}
+	return entries[rand.Intn(len(entries))], nil
+}
+
+// TipOfTheDay picks a page on the given platforms for day. The pick stays
+// the same all day and changes the next.
+func (m *Manager) TipOfTheDay(platforms []string, day time.Time) (types.IndexEntry, error) {
+	entries, err := m.ListPages("", platforms)
+	if err != nil {
+		return types.IndexEntry{}, err
+	}
+	if len(entries) == 0 {
+		return types.IndexEntry{}, fmt.Errorf("no pages to pick from")
+	}
+
+	// Spread consecutive days over the list rather than walking it in
+	// alphabetical order
+	year, month, date := day.Date()
+	days := time.Date(year, month, date, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60)
+	return entries[int(uint64(days)*2654435761%uint64(len(entries)))], nil
+}
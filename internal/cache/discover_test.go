@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRandomPage(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		entry, err := m.RandomPage([]string{"linux"})
+		if err != nil {
+			t.Fatalf("RandomPage failed: %v", err)
+		}
+		if entry.Name != "ls" {
+			t.Errorf("Expected the only linux page, got %s", entry.Name)
+		}
+	}
+
+	if _, err := m.RandomPage([]string{"osx"}); err == nil {
+		t.Error("Expected an error without pages to pick from")
+	}
+}
+
+func TestTipOfTheDay(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	morning := time.Date(2024, 3, 1, 8, 0, 0, 0, time.Local)
+	first, err := m.TipOfTheDay(nil, morning)
+	if err != nil {
+		t.Fatalf("TipOfTheDay failed: %v", err)
+	}
+	evening, _ := m.TipOfTheDay(nil, morning.Add(12*time.Hour))
+	if evening.Name != first.Name {
+		t.Errorf("Expected the same tip all day, got %s and %s", first.Name, evening.Name)
+	}
+
+	// Every page comes up over a few days
+	seen := make(map[string]bool)
+	for i := 0; i < 30; i++ {
+		entry, _ := m.TipOfTheDay(nil, morning.AddDate(0, 0, i))
+		seen[entry.Name] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("Expected all 3 pages as tips, got %v", seen)
+	}
+}
@@ -0,0 +1,471 @@
+package cache
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/log"
+	"github.com/makalin/tldrpp/internal/network"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// Stage names the step an update is in
+type Stage int
+
+const (
+	StageDownload Stage = iota
+	StageClone
+	StageIndex
+)
+
+// Progress reports how far an update has got
+type Progress struct {
+	Stage      Stage
+	Source     string
+	BytesDone  int64
+	BytesTotal int64 // -1 when the server sends no length
+	PagesDone  int
+	PagesTotal int
+}
+
+// ProgressFunc receives progress updates while the cache is updated
+type ProgressFunc func(Progress)
+
+// Update downloads the pages archive and rebuilds the cache
+func (m *Manager) Update() error {
+	return m.UpdateContext(context.Background(), nil)
+}
+
+// InitializeContext is like Initialize but can be cancelled through ctx and
+// reports progress to the given function, which may be nil
+func (m *Manager) InitializeContext(ctx context.Context, progress ProgressFunc) error {
+	if m.IsInitialized() {
+		return nil
+	}
+	return m.UpdateContext(ctx, progress)
+}
+
+// UpdateContext is like Update but can be cancelled through ctx and reports
+// progress to the given function, which may be nil. Sources are tried in
+// order until one succeeds, and the existing cache is only replaced once the
+// new one is complete.
+func (m *Manager) UpdateContext(ctx context.Context, progress ProgressFunc) error {
+	if progress == nil {
+		progress = func(Progress) {}
+	}
+
+	// Only one update may run at a time, in this process or another
+	release, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	sources, err := m.pinnedSources()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, src := range sources {
+		start := time.Now()
+		log.Info("updating cache", "source", sourceName(src), "url", src.URL)
+		err := m.updateFrom(ctx, src, progress)
+		if err == nil {
+			log.Info("cache updated", "source", sourceName(src), "duration", time.Since(start))
+			return nil
+		}
+		if ctx.Err() != nil {
+			log.Info("cache update cancelled", "source", sourceName(src))
+			return ctx.Err()
+		}
+		log.Warn("source failed", "source", sourceName(src), "err", err)
+		errs = append(errs, fmt.Errorf("source %s: %w", sourceName(src), err))
+	}
+
+	return network.Explain(errors.Join(errs...))
+}
+
+// updateFrom rebuilds the cache from a single source
+func (m *Manager) updateFrom(ctx context.Context, src config.Source, progress ProgressFunc) error {
+	if isGitSource(src) {
+		return m.updateFromGit(ctx, src, progress)
+	}
+
+	archive, err := m.download(ctx, src, progress)
+	if err != nil {
+		return err
+	}
+	// Parts are kept for resuming only until the archive is complete
+	defer os.RemoveAll(m.downloadDir())
+
+	staging := m.dir + ".new"
+	if err := os.RemoveAll(staging); err != nil {
+		return fmt.Errorf("failed to clean staging directory: %w", err)
+	}
+	if err := m.extract(ctx, src, archive, staging, progress); err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+
+	return m.swap(staging)
+}
+
+// pageFile is a page found in a source, read on demand
+type pageFile struct {
+	entry types.IndexEntry
+	// language is empty for English pages
+	language string
+	name     string
+	read     func() ([]byte, error)
+}
+
+// extract unpacks the pages and their translations from archive into dir and
+// writes the index
+func (m *Manager) extract(ctx context.Context, src config.Source, archive, dir string, progress ProgressFunc) error {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer reader.Close()
+
+	var files []pageFile
+	for _, file := range reader.File {
+		if entry, language, ok := archiveEntry(file.Name); ok {
+			file := file
+			files = append(files, pageFile{
+				entry:    entry,
+				language: language,
+				name:     file.Name,
+				read:     func() ([]byte, error) { return readZipFile(file) },
+			})
+		}
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("archive contains no pages")
+	}
+
+	return m.build(ctx, src, files, dir, progress)
+}
+
+// build writes the pages into dir and indexes them. Translations are stored
+// alongside and recorded on the entry of their English page; a translation
+// without an English page is skipped. Pages are compressed and parsed by a
+// worker per CPU, their index data gathered as each is done.
+func (m *Manager) build(ctx context.Context, src config.Source, files []pageFile, dir string, progress ProgressFunc) error {
+	var entries []types.IndexEntry
+	for _, file := range files {
+		if file.language == "" {
+			entries = append(entries, file.entry)
+		}
+	}
+	positions := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		positions[entry.Platform+"/"+entry.Name] = i
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobs := make(chan pageFile)
+	results := make(chan builtPage)
+	workers := runtime.GOMAXPROCS(0)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				select {
+				case results <- buildPage(dir, file, positions):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, file := range files {
+			select {
+			case jobs <- file:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	start := time.Now()
+	state := Progress{Stage: StageIndex, Source: sourceName(src), PagesTotal: len(files)}
+	var buildErr error
+	for result := range results {
+		switch {
+		case buildErr != nil:
+			// Let the workers finish
+			continue
+		case result.err != nil:
+			buildErr = result.err
+			cancel()
+			continue
+		case result.position < 0:
+			// A translation of a page the source doesn't have
+		case result.language == "":
+			entries[result.position].Description = result.description
+			entries[result.position].Checksum = result.checksum
+		default:
+			entries[result.position].Languages = append(entries[result.position].Languages, result.language)
+		}
+
+		state.PagesDone++
+		progress(state)
+	}
+	if buildErr == nil {
+		buildErr = ctx.Err()
+	}
+	if buildErr != nil {
+		return buildErr
+	}
+	took := time.Since(start)
+	log.Info("pages indexed", "pages", len(files), "workers", workers, "duration", took,
+		"pages_per_sec", int(float64(len(files))/took.Seconds()))
+
+	for i := range entries {
+		sort.Strings(entries[i].Languages)
+	}
+
+	if err := writeMeta(dir, src, m.pin); err != nil {
+		return err
+	}
+	return writeIndex(dir, entries)
+}
+
+// builtPage is what building a page file left for the index
+type builtPage struct {
+	// position is the page's index entry, or -1 for a translation without
+	// an English page
+	position int
+	language string
+	// description and checksum are those of an English page
+	description string
+	checksum    string
+	err         error
+}
+
+// buildPage stores a page file below dir and returns its index data
+func buildPage(dir string, file pageFile, positions map[string]int) builtPage {
+	i, ok := positions[file.entry.Platform+"/"+file.entry.Name]
+	if !ok {
+		return builtPage{position: -1}
+	}
+	content, err := file.read()
+	if err != nil {
+		return builtPage{err: fmt.Errorf("failed to read %s: %w", file.name, err)}
+	}
+
+	if file.language != "" {
+		if err := writePage(translatedPagePath(dir, file.entry, file.language), content); err != nil {
+			return builtPage{err: fmt.Errorf("failed to write page %s: %w", file.name, err)}
+		}
+		return builtPage{position: i, language: file.language}
+	}
+	if err := writePage(pagePath(dir, file.entry), content); err != nil {
+		return builtPage{err: fmt.Errorf("failed to write page %s: %w", file.entry.Name, err)}
+	}
+	entry := file.entry
+	if page := describe(&entry, content); page != nil {
+		if err := writeParsedPage(dir, entry, entry.Checksum, page); err != nil {
+			return builtPage{err: err}
+		}
+	}
+	return builtPage{position: i, description: entry.Description, checksum: entry.Checksum}
+}
+
+// describe fills in the index data derived from a page's content: its
+// description for searching and its checksum for verification. It returns
+// the parsed page, or nil if the page doesn't parse.
+func describe(entry *types.IndexEntry, content []byte) *types.Page {
+	page, err := types.ParsePage(string(content), *entry)
+	if err == nil {
+		entry.Description = page.Description
+	}
+	sum := sha256.Sum256(content)
+	entry.Checksum = hex.EncodeToString(sum[:])
+	return page
+}
+
+// swap replaces the cache directory with the freshly built staging
+// directory, recording which pages changed
+func (m *Manager) swap(staging string) error {
+	if err := recordChanges(m.dir, staging); err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+
+	old := backupDir(m.dir)
+	if err := os.RemoveAll(old); err != nil {
+		return fmt.Errorf("failed to clean old cache: %w", err)
+	}
+
+	movedAside := false
+	if _, err := os.Stat(m.dir); err == nil {
+		if err := os.Rename(m.dir, old); err != nil {
+			return fmt.Errorf("failed to move old cache aside: %w", err)
+		}
+		movedAside = true
+	}
+	if err := os.Rename(staging, m.dir); err != nil {
+		// Put the old cache back so a failed update leaves it usable
+		if movedAside {
+			if rerr := os.Rename(old, m.dir); rerr != nil {
+				log.Warn("failed to restore old cache", "dir", m.dir, "err", rerr)
+			}
+		}
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to install new cache: %w", err)
+	}
+	m.pages.clear()
+
+	return os.RemoveAll(old)
+}
+
+// backupDir is where swap moves the cache aside while installing a new one
+func backupDir(dir string) string {
+	return dir + ".old"
+}
+
+// restoreBackup moves the old cache back in place when an update was
+// interrupted between moving it aside and installing the new one
+func restoreBackup(dir string) {
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		return
+	}
+	old := backupDir(dir)
+	if _, err := os.Stat(old); err != nil {
+		return
+	}
+	if err := os.Rename(old, dir); err != nil {
+		log.Warn("failed to restore old cache", "dir", dir, "err", err)
+		return
+	}
+	log.Info("restored cache from interrupted update", "dir", dir)
+}
+
+// archiveEntry maps an archive path such as pages/linux/ls.md to an index
+// entry. Archives of a git branch wrap the pages in a top-level directory,
+// e.g. tldr-main/pages/linux/ls.md. Translated pages live in pages.<lang>
+// directories; their language is returned, or "" for English pages.
+// Entries whose platform or name could lead out of the cache, such as
+// pages/../evil.md, are skipped.
+func archiveEntry(name string) (types.IndexEntry, string, bool) {
+	parts := strings.Split(name, "/")
+	if len(parts) == 4 {
+		parts = parts[1:]
+	}
+	if len(parts) != 3 || !strings.HasSuffix(parts[2], ".md") {
+		return types.IndexEntry{}, "", false
+	}
+	language, ok := pagesLanguage(parts[0])
+	if !ok {
+		return types.IndexEntry{}, "", false
+	}
+	entry := types.IndexEntry{
+		Name:     strings.TrimSuffix(parts[2], ".md"),
+		Platform: parts[1],
+	}
+	if !validPathPart(entry.Name) || !validPathPart(entry.Platform) {
+		return types.IndexEntry{}, "", false
+	}
+	return entry, language, true
+}
+
+// pagesLanguage returns the language of a pages directory: "" for pages,
+// "de" for pages.de
+func pagesLanguage(dir string) (string, bool) {
+	if dir == "pages" {
+		return "", true
+	}
+	language, ok := strings.CutPrefix(dir, "pages.")
+	return language, ok && validPathPart(language)
+}
+
+// validPathPart reports whether part, a platform, page name or language,
+// can be a file name in the cache: not empty, not hidden and not . or ..,
+// and without separators
+func validPathPart(part string) bool {
+	return part != "" && !strings.HasPrefix(part, ".") && !strings.ContainsAny(part, `/\:`)
+}
+
+// readZipFile reads the whole content of an archive member
+func readZipFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// Fraction returns how much of the current stage is done, from 0 to 1
+func (p Progress) Fraction() float64 {
+	switch {
+	case p.Stage == StageDownload && p.BytesTotal > 0:
+		return float64(p.BytesDone) / float64(p.BytesTotal)
+	case p.Stage == StageIndex && p.PagesTotal > 0:
+		return float64(p.PagesDone) / float64(p.PagesTotal)
+	default:
+		return 0
+	}
+}
+
+// String describes the progress for display, e.g. "Downloading 1.2 MB / 8.0 MB"
+func (p Progress) String() string {
+	var s string
+	switch {
+	case p.Stage == StageClone:
+		s = "Fetching repository"
+	case p.Stage == StageIndex:
+		s = fmt.Sprintf("Indexing %d/%d pages", p.PagesDone, p.PagesTotal)
+	case p.BytesTotal > 0:
+		s = fmt.Sprintf("Downloading %s / %s", formatBytes(p.BytesDone), formatBytes(p.BytesTotal))
+	default:
+		s = fmt.Sprintf("Downloading %s", formatBytes(p.BytesDone))
+	}
+	if p.Source != "" {
+		s += " from " + p.Source
+	}
+	return s
+}
+
+// sourceName returns a source's name, or its URL if it has none
+func sourceName(src config.Source) string {
+	if src.Name != "" {
+		return src.Name
+	}
+	return src.URL
+}
+
+// formatBytes formats a byte count with one decimal in KB or MB
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
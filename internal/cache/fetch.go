@@ -0,0 +1,410 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// defaultWorkers is how many ranges of the archive are fetched at once
+	defaultWorkers = 4
+	// maxAttempts is how often a request is tried before giving up
+	maxAttempts = 5
+	// stateFile records which archive the partial download belongs to
+	stateFile = "download.json"
+)
+
+// downloadState identifies the remote archive a partial download belongs to,
+// so parts are only resumed against the same file
+type downloadState struct {
+	URL  string `json:"url"`
+	Size int64  `json:"size"`
+	ETag string `json:"etag"`
+}
+
+// chunk is a byte range of the archive stored in its own part file
+type chunk struct {
+	start, end int64 // end is inclusive, -1 when the size is unknown
+	path       string
+}
+
+// statusError is an unexpected HTTP response status
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return e.status
+}
+
+// downloadDir holds part files of an unfinished download
+func (m *Manager) downloadDir() string {
+	return m.dir + ".download"
+}
+
+// download fetches the pages archive and returns its path. Servers that
+// support ranges are downloaded with parallel workers, and parts left by an
+// interrupted download are resumed.
+func (m *Manager) download(ctx context.Context, src config.Source, progress ProgressFunc) (string, error) {
+	dir := m.downloadDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	state, ranges, err := m.probe(ctx, src)
+	if err != nil {
+		return "", fmt.Errorf("failed to download pages: %w", err)
+	}
+	if err := prepareDownloadDir(dir, state); err != nil {
+		return "", err
+	}
+
+	chunks := splitChunks(dir, state.Size, ranges, m.workers)
+	tracker := &progressTracker{
+		progress: progress,
+		state:    Progress{Stage: StageDownload, Source: sourceName(src), BytesTotal: state.Size},
+	}
+	if state.Size <= 0 {
+		tracker.state.BytesTotal = -1
+	}
+	for _, c := range chunks {
+		if info, err := os.Stat(c.path); err == nil && c.end >= 0 {
+			tracker.add(info.Size())
+		}
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	for _, c := range chunks {
+		c := c
+		group.Go(func() error {
+			return m.retry(groupCtx, func() error {
+				return m.fetchChunk(groupCtx, src, c, tracker)
+			})
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return "", fmt.Errorf("failed to download pages: %w", err)
+	}
+
+	archive := filepath.Join(dir, path.Base(src.URL))
+	if err := joinChunks(archive, chunks); err != nil {
+		return "", err
+	}
+
+	if err := m.verify(ctx, src, archive); err != nil {
+		// A corrupt download can't be resumed, start over next time
+		os.RemoveAll(dir)
+		return "", err
+	}
+	if err := m.verifySignature(ctx, src, archive); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	return archive, nil
+}
+
+// probe asks the server for the archive's size, ETag and range support
+func (m *Manager) probe(ctx context.Context, src config.Source) (downloadState, bool, error) {
+	var resp *http.Response
+	err := m.retry(ctx, func() error {
+		req, err := newRequest(ctx, http.MethodHead, src.URL, src)
+		if err != nil {
+			return err
+		}
+		resp, err = m.client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return checkStatus(resp, http.StatusOK)
+	})
+	var status *statusError
+	if errors.As(err, &status) && status.code != http.StatusNotFound {
+		// Some servers reject HEAD, fall back to a plain download
+		return downloadState{URL: src.URL, Size: -1}, false, nil
+	}
+	if err != nil {
+		return downloadState{}, false, err
+	}
+
+	state := downloadState{
+		URL:  src.URL,
+		Size: resp.ContentLength,
+		ETag: resp.Header.Get("ETag"),
+	}
+	ranges := resp.Header.Get("Accept-Ranges") == "bytes" && resp.ContentLength > 0
+	return state, ranges, nil
+}
+
+// prepareDownloadDir clears part files that belong to a different archive
+// and records the one being downloaded
+func prepareDownloadDir(dir string, state downloadState) error {
+	var previous downloadState
+	if data, err := os.ReadFile(filepath.Join(dir, stateFile)); err == nil {
+		json.Unmarshal(data, &previous)
+	}
+
+	if previous != state {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to clean download directory: %w", err)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create download directory: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal download state: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, stateFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write download state: %w", err)
+	}
+	return nil
+}
+
+// splitChunks divides an archive of the given size between workers. Without
+// range support the whole archive is a single chunk of unknown length.
+func splitChunks(dir string, size int64, ranges bool, workers int) []chunk {
+	if !ranges || workers < 1 {
+		return []chunk{{start: 0, end: -1, path: filepath.Join(dir, "part-0")}}
+	}
+	if int64(workers) > size {
+		workers = int(size)
+	}
+
+	chunks := make([]chunk, workers)
+	step := size / int64(workers)
+	for i := range chunks {
+		chunks[i] = chunk{
+			start: int64(i) * step,
+			end:   int64(i+1)*step - 1,
+			path:  filepath.Join(dir, fmt.Sprintf("part-%d", i)),
+		}
+	}
+	chunks[workers-1].end = size - 1
+	return chunks
+}
+
+// fetchChunk downloads the missing part of a chunk, appending to its part
+// file. Chunks of unknown length can't be resumed and restart from scratch.
+func (m *Manager) fetchChunk(ctx context.Context, src config.Source, c chunk, tracker *progressTracker) error {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if c.end < 0 {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(c.path, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open part file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat part file: %w", err)
+	}
+	have := info.Size()
+	if c.end >= 0 && c.start+have > c.end {
+		return nil // Already complete
+	}
+
+	req, err := newRequest(ctx, http.MethodGet, src.URL, src)
+	if err != nil {
+		return err
+	}
+	want := http.StatusOK
+	if c.end >= 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", c.start+have, c.end))
+		want = http.StatusPartialContent
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp, want); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, io.TeeReader(resp.Body, tracker))
+	return err
+}
+
+// joinChunks concatenates the part files into the archive
+func joinChunks(archive string, chunks []chunk) error {
+	out, err := os.Create(archive)
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer out.Close()
+
+	for _, c := range chunks {
+		part, err := os.Open(c.path)
+		if err != nil {
+			return fmt.Errorf("failed to open part file: %w", err)
+		}
+		_, err = io.Copy(out, part)
+		part.Close()
+		if err != nil {
+			return fmt.Errorf("failed to assemble archive: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// verify checks the archive against the published SHA-256 sum. Sources
+// without a checksum file are accepted as they are, but once one is set the
+// archive must be listed in it.
+func (m *Manager) verify(ctx context.Context, src config.Source, archive string) error {
+	if src.Checksum == "" {
+		return nil
+	}
+
+	data, err := m.fetchFile(ctx, src, src.Checksum)
+	var status *statusError
+	if errors.As(err, &status) && status.code == http.StatusNotFound {
+		return fmt.Errorf("checksum file %s not found", src.Checksum)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch checksums: %w", err)
+	}
+
+	want, ok := findChecksum(string(data), path.Base(src.URL))
+	if !ok {
+		return fmt.Errorf("no checksum for %s in %s", path.Base(src.URL), src.Checksum)
+	}
+
+	file, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("failed to hash archive: %w", err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("archive checksum mismatch: expected %s, got %s", want, got)
+	}
+
+	return nil
+}
+
+// findChecksum looks up a file in sha256sum output
+func findChecksum(sums, name string) (string, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+	return "", false
+}
+
+// retry runs fn until it succeeds, backing off exponentially between
+// attempts. Client errors other than rate limiting are not retried.
+func (m *Manager) retry(ctx context.Context, fn func() error) error {
+	delay := m.retryDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt == maxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		var status *statusError
+		if errors.As(err, &status) && status.code < 500 && status.code != http.StatusTooManyRequests {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// fetchFile fetches a small file from a source into memory
+func (m *Manager) fetchFile(ctx context.Context, src config.Source, url string) ([]byte, error) {
+	var content []byte
+	err := m.retry(ctx, func() error {
+		req, err := newRequest(ctx, http.MethodGet, url, src)
+		if err != nil {
+			return err
+		}
+		resp, err := m.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := checkStatus(resp, http.StatusOK); err != nil {
+			return err
+		}
+		content, err = io.ReadAll(resp.Body)
+		return err
+	})
+	return content, err
+}
+
+// newRequest creates a request carrying the source's headers
+func newRequest(ctx context.Context, method, url string, src config.Source) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range src.Headers {
+		req.Header.Set(name, os.ExpandEnv(value))
+	}
+	return req, nil
+}
+
+// checkStatus turns an unexpected response status into a *statusError
+func checkStatus(resp *http.Response, want int) error {
+	if resp.StatusCode != want {
+		return &statusError{code: resp.StatusCode, status: resp.Status}
+	}
+	return nil
+}
+
+// progressTracker sums the bytes written by all workers and reports them
+type progressTracker struct {
+	mu       sync.Mutex
+	progress ProgressFunc
+	state    Progress
+}
+
+func (t *progressTracker) Write(p []byte) (int, error) {
+	t.add(int64(len(p)))
+	return len(p), nil
+}
+
+// add records n more bytes downloaded
+func (t *progressTracker) add(n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state.BytesDone += n
+	t.progress(t.state)
+}
@@ -0,0 +1,223 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSplitChunks(t *testing.T) {
+	tests := []struct {
+		description string
+		size        int64
+		ranges      bool
+		workers     int
+		expected    [][2]int64
+	}{
+		{"even split", 100, true, 4, [][2]int64{{0, 24}, {25, 49}, {50, 74}, {75, 99}}},
+		{"last chunk takes remainder", 10, true, 3, [][2]int64{{0, 2}, {3, 5}, {6, 9}}},
+		{"more workers than bytes", 2, true, 4, [][2]int64{{0, 0}, {1, 1}}},
+		{"no range support", 100, false, 4, [][2]int64{{0, -1}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			chunks := splitChunks("dir", test.size, test.ranges, test.workers)
+			if len(chunks) != len(test.expected) {
+				t.Fatalf("Expected %d chunks, got %d", len(test.expected), len(chunks))
+			}
+			for i, expected := range test.expected {
+				if chunks[i].start != expected[0] || chunks[i].end != expected[1] {
+					t.Errorf("Expected chunk %d to be %v, got [%d %d]", i, expected, chunks[i].start, chunks[i].end)
+				}
+			}
+		})
+	}
+}
+
+func TestDownloadParallel(t *testing.T) {
+	var mu sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(archiveHandler(archive(t, testPages), func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+		}
+		return true
+	}))
+	defer server.Close()
+
+	m := newManager(t, server.URL)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	if len(ranges) != defaultWorkers {
+		t.Errorf("Expected %d ranged requests, got %v", defaultWorkers, ranges)
+	}
+	for _, r := range ranges {
+		if !strings.HasPrefix(r, "bytes=") {
+			t.Errorf("Expected a Range header, got '%s'", r)
+		}
+	}
+	if _, err := os.Stat(m.downloadDir()); !os.IsNotExist(err) {
+		t.Error("Expected download directory to be removed after update")
+	}
+}
+
+func TestDownloadRetry(t *testing.T) {
+	failures := 2
+	var mu sync.Mutex
+	server := httptest.NewServer(archiveHandler(archive(t, testPages), func(w http.ResponseWriter, r *http.Request) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodGet && failures > 0 {
+			failures--
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return false
+		}
+		return true
+	}))
+	defer server.Close()
+
+	m := newManager(t, server.URL)
+	m.workers = 1
+	if err := m.Update(); err != nil {
+		t.Fatalf("Expected update to succeed after retries, got %v", err)
+	}
+}
+
+func TestDownloadClientErrorNotRetried(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(archiveHandler(nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == http.MethodGet {
+			requests++
+		}
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return false
+	}))
+	defer server.Close()
+
+	m := newManager(t, server.URL)
+	if err := m.Update(); err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	data := archive(t, testPages)
+	var ranges []string
+	server := httptest.NewServer(archiveHandler(data, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == http.MethodGet {
+			ranges = append(ranges, r.Header.Get("Range"))
+		}
+		return true
+	}))
+	defer server.Close()
+
+	m := newManager(t, server.URL)
+	m.workers = 1
+
+	// Leave the first 100 bytes from an interrupted download
+	dir := m.downloadDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	state, _ := json.Marshal(downloadState{URL: m.sources[0].URL, Size: int64(len(data))})
+	os.WriteFile(filepath.Join(dir, stateFile), state, 0644)
+	os.WriteFile(filepath.Join(dir, "part-0"), data[:100], 0644)
+
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	expected := fmt.Sprintf("bytes=100-%d", len(data)-1)
+	if len(ranges) != 1 || ranges[0] != expected {
+		t.Errorf("Expected a single request for '%s', got %v", expected, ranges)
+	}
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	data := archive(t, testPages)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tldr.zip", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "tldr.zip", time.Time{}, bytes.NewReader(data))
+	})
+	mux.HandleFunc("/tldr.sha256sums", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  tldr.zip\n", strings.Repeat("0", 64))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	m := newManager(t, server.URL)
+	err := m.Update()
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Expected checksum mismatch, got %v", err)
+	}
+	if m.IsInitialized() {
+		t.Error("Expected cache to stay uninitialized")
+	}
+	if _, err := os.Stat(m.downloadDir()); !os.IsNotExist(err) {
+		t.Error("Expected corrupt download to be discarded")
+	}
+}
+
+func TestDownloadChecksumMissing(t *testing.T) {
+	data := archive(t, testPages)
+	tests := []struct {
+		name  string
+		sums  http.HandlerFunc
+		error string
+	}{
+		{"not found", http.NotFound, "not found"},
+		{"no entry", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s  tldr-pages.de.zip\n", strings.Repeat("0", 64))
+		}, "no checksum for tldr.zip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/tldr.zip", func(w http.ResponseWriter, r *http.Request) {
+				http.ServeContent(w, r, "tldr.zip", time.Time{}, bytes.NewReader(data))
+			})
+			mux.HandleFunc("/tldr.sha256sums", tt.sums)
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			m := newManager(t, server.URL)
+			err := m.Update()
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Fatalf("Expected %q error, got %v", tt.error, err)
+			}
+			if m.IsInitialized() {
+				t.Error("Expected cache to stay uninitialized")
+			}
+		})
+	}
+}
+
+func TestFindChecksum(t *testing.T) {
+	sums := "abc123  tldr.zip\nDEF456 *tldr-pages.de.zip\n"
+
+	if sum, ok := findChecksum(sums, "tldr.zip"); !ok || sum != "abc123" {
+		t.Errorf("Expected 'abc123', got '%s'", sum)
+	}
+	if sum, ok := findChecksum(sums, "tldr-pages.de.zip"); !ok || sum != "def456" {
+		t.Errorf("Expected 'def456', got '%s'", sum)
+	}
+	if _, ok := findChecksum(sums, "missing.zip"); ok {
+		t.Error("Expected no checksum for missing file")
+	}
+}
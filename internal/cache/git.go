@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// gitPrefix marks a source URL as a git repository, e.g.
+// git+https://github.com/tldr-pages/tldr#main
+const gitPrefix = "git+"
+
+// isGitSource reports whether pages come from a git repository
+func isGitSource(src config.Source) bool {
+	return strings.HasPrefix(src.URL, gitPrefix)
+}
+
+// parseGitURL splits a git source URL into the repository URL and the
+// optional branch or tag given as its fragment
+func parseGitURL(url string) (string, string) {
+	repo := strings.TrimPrefix(url, gitPrefix)
+	if i := strings.LastIndex(repo, "#"); i >= 0 {
+		return repo[:i], repo[i+1:]
+	}
+	return repo, ""
+}
+
+// repoDir holds the shallow clone of a git source
+func (m *Manager) repoDir() string {
+	return m.dir + ".repo"
+}
+
+// updateFromGit rebuilds the cache from a shallow clone of a repository
+func (m *Manager) updateFromGit(ctx context.Context, src config.Source, progress ProgressFunc) error {
+	progress(Progress{Stage: StageClone, Source: sourceName(src)})
+
+	repo, ref := parseGitURL(src.URL)
+	dir := m.repoDir()
+	if err := m.syncRepo(ctx, src, repo, ref, dir); err != nil {
+		return err
+	}
+	if err := m.verifyCommit(ctx, src, dir); err != nil {
+		return err
+	}
+
+	files, err := repoPages(dir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("repository contains no pages")
+	}
+
+	staging := m.dir + ".new"
+	if err := os.RemoveAll(staging); err != nil {
+		return fmt.Errorf("failed to clean staging directory: %w", err)
+	}
+	if err := m.build(ctx, src, files, staging, progress); err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+
+	return m.swap(staging)
+}
+
+// syncRepo pulls the latest commit into an existing clone of repo, or makes
+// a fresh shallow clone
+func (m *Manager) syncRepo(ctx context.Context, src config.Source, repo, ref, dir string) error {
+	if remote, err := m.runGit(ctx, src, dir, "remote", "get-url", "origin"); err == nil && remote == repo {
+		return m.fetchRef(ctx, src, ref, dir)
+	}
+
+	// Missing or pointing at another repository, start over
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to clean repository directory: %w", err)
+	}
+	if isCommit(ref) {
+		// A commit can only be fetched, not cloned
+		if _, err := m.runGit(ctx, src, "", "init", "--quiet", dir); err != nil {
+			return err
+		}
+		if _, err := m.runGit(ctx, src, dir, "remote", "add", "origin", repo); err != nil {
+			return err
+		}
+		return m.fetchRef(ctx, src, ref, dir)
+	}
+	clone := []string{"clone", "--depth", "1"}
+	if ref != "" {
+		clone = append(clone, "--branch", ref)
+	}
+	_, err := m.runGit(ctx, src, "", append(clone, "--", repo, dir)...)
+	return err
+}
+
+// fetchRef checks out the latest commit of ref, or of the default branch if
+// ref is empty, in a clone
+func (m *Manager) fetchRef(ctx context.Context, src config.Source, ref, dir string) error {
+	fetch := []string{"fetch", "--depth", "1", "origin"}
+	if ref != "" {
+		fetch = append(fetch, ref)
+	}
+	if _, err := m.runGit(ctx, src, dir, fetch...); err != nil {
+		return err
+	}
+	_, err := m.runGit(ctx, src, dir, "reset", "--hard", "FETCH_HEAD")
+	return err
+}
+
+// runGit runs a git command in dir, or the current directory if dir is
+// empty, and returns its trimmed output. The source's headers are passed on
+// as http.extraHeader; other authentication is left to git. The proxy and
+// TLS settings are those of downloads.
+func (m *Manager) runGit(ctx context.Context, src config.Source, dir string, args ...string) (string, error) {
+	var full []string
+	if dir != "" {
+		full = append(full, "-C", dir)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", append(full, args...)...)
+	// Never prompt for credentials, the TUI owns the terminal
+	cmd.Env = append(append(os.Environ(), m.gitEnv...), "GIT_TERMINAL_PROMPT=0")
+	cmd.Env = append(cmd.Env, headerConfig(src.Headers)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// headerConfig returns the environment setting headers as git's
+// http.extraHeader. Headers often hold tokens, which other users could read
+// in git's arguments but not in its environment. The settings are added
+// after any GIT_CONFIG_COUNT ones tldrpp was given, and need git 2.31.
+func headerConfig(headers map[string]string) []string {
+	if len(headers) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	count, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	var env []string
+	for _, name := range names {
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=http.extraHeader", count),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s: %s", count, name, os.ExpandEnv(headers[name])))
+		count++
+	}
+	return append(env, fmt.Sprintf("GIT_CONFIG_COUNT=%d", count))
+}
+
+// repoPages lists the pages and their translations in a checkout
+func repoPages(dir string) ([]pageFile, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "pages*", "*", "*.md"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pages: %w", err)
+	}
+
+	files := make([]pageFile, 0, len(paths))
+	for _, path := range paths {
+		path := path
+		language, ok := pagesLanguage(filepath.Base(filepath.Dir(filepath.Dir(path))))
+		if !ok {
+			continue
+		}
+		files = append(files, pageFile{
+			entry: types.IndexEntry{
+				Name:     strings.TrimSuffix(filepath.Base(path), ".md"),
+				Platform: filepath.Base(filepath.Dir(path)),
+			},
+			language: language,
+			name:     path,
+			read:     func() ([]byte, error) { return os.ReadFile(path) },
+		})
+	}
+	return files, nil
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/config"
+)
+
+// gitRepo creates a repository with the given pages committed
+func gitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git(t, dir, "init", "--quiet", "--initial-branch", "main")
+	commitPages(t, dir, files)
+	return dir
+}
+
+// commitPages writes files into the repository and commits them
+func commitPages(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	git(t, dir, "add", "-A")
+	git(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "pages")
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if _, err := (&Manager{}).runGit(context.Background(), config.Source{}, dir, args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunGitHeaders(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("TLDRPP_TEST_TOKEN", "secret")
+	src := config.Source{Headers: map[string]string{"Authorization": "Bearer ${TLDRPP_TEST_TOKEN}", "X-Team": "docs"}}
+
+	got, err := (&Manager{}).runGit(context.Background(), src, "", "config", "--get-all", "http.extraHeader")
+	if err != nil {
+		t.Fatalf("runGit failed: %v", err)
+	}
+	if want := "Authorization: Bearer secret\nX-Team: docs"; got != want {
+		t.Errorf("Expected the headers %q, got %q", want, got)
+	}
+}
+
+func TestParseGitURL(t *testing.T) {
+	tests := []struct {
+		url, repo, ref string
+	}{
+		{"git+https://github.com/tldr-pages/tldr", "https://github.com/tldr-pages/tldr", ""},
+		{"git+https://github.com/tldr-pages/tldr#main", "https://github.com/tldr-pages/tldr", "main"},
+		{"git+ssh://git@example.com/team/tldr.git#v2", "ssh://git@example.com/team/tldr.git", "v2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			repo, ref := parseGitURL(test.url)
+			if repo != test.repo || ref != test.ref {
+				t.Errorf("Expected (%s, %s), got (%s, %s)", test.repo, test.ref, repo, ref)
+			}
+		})
+	}
+}
+
+func TestUpdateFromGit(t *testing.T) {
+	repo := gitRepo(t, testPages)
+
+	m := New(filepath.Join(t.TempDir(), "pages"), []config.Source{{Name: "team", URL: gitPrefix + "file://" + filepath.ToSlash(repo) + "#main"}})
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := m.FindPage("tar"); err != nil {
+		t.Fatalf("Expected tar page, got %v", err)
+	}
+
+	// A second update pulls new commits into the existing clone
+	commitPages(t, repo, map[string]string{
+		"pages/common/deploy.md": "# deploy\n\n> Deploy the app.\n\n- Deploy:\n\n`deploy`\n",
+	})
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	page, err := m.FindPage("deploy")
+	if err != nil || page.Name != "deploy" {
+		t.Errorf("Expected deploy page after pull, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/log"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+const (
+	// swapWait bounds how long a reader waits for an update in another
+	// process to finish swapping the cache directory
+	swapWait = 2 * time.Second
+	// indexFile lists every cached page
+	indexFile = "index.json"
+	// binaryIndexFile holds the same index in a compact form that loads
+	// much faster than JSON; index.json stays the source of truth
+	binaryIndexFile = "index.bin"
+)
+
+// binaryIndexMagic starts every binary index, followed by the entry count and
+// the entries' fields as length-prefixed strings. The last byte is the format
+// version; an index of another version is read from JSON instead.
+var binaryIndexMagic = []byte("TLDRIDX\x02")
+
+// errBadIndex reports a binary index that can't be used
+var errBadIndex = errors.New("malformed binary index")
+
+// loadIndex reads the page index from disk, preferring the binary index
+// when it is at least as new as the JSON one. Cached pages are dropped when
+// the index changed since it was last read.
+func (m *Manager) loadIndex() ([]types.IndexEntry, error) {
+	m.waitForSwap()
+
+	info, err := os.Stat(filepath.Join(m.dir, indexFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	m.mu.Lock()
+	if !info.ModTime().Equal(m.indexTime) {
+		m.indexTime = info.ModTime()
+		m.pages.clear()
+	}
+	m.mu.Unlock()
+
+	binary, err := readBinaryIndex(m.dir)
+	if err == nil {
+		log.Debug("loaded binary index", "pages", len(binary))
+		return binary, nil
+	}
+	log.Debug("binary index unusable, reading JSON", "err", err)
+
+	data, err := os.ReadFile(filepath.Join(m.dir, indexFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	var index []types.IndexEntry
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse index: %w", err)
+	}
+
+	return index, nil
+}
+
+// waitForSwap waits while another process is between moving the old cache
+// directory aside and moving the new one into place
+func (m *Manager) waitForSwap() {
+	deadline := time.Now().Add(swapWait)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(m.dir); !os.IsNotExist(err) {
+			return
+		}
+		if _, err := os.Stat(m.dir + ".old"); err != nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// writeIndex writes the index into dir, in both JSON and binary form
+func writeIndex(dir string, entries []types.IndexEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+	// The JSON index goes first: a binary index older than it is ignored
+	if err := writeFileAtomic(filepath.Join(dir, indexFile), data); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, binaryIndexFile), encodeIndex(entries)); err != nil {
+		return fmt.Errorf("failed to write binary index: %w", err)
+	}
+	return nil
+}
+
+// readBinaryIndex reads the binary index in dir. It fails if the index is
+// missing, damaged or older than index.json, e.g. after a manual edit.
+func readBinaryIndex(dir string) ([]types.IndexEntry, error) {
+	path := filepath.Join(dir, binaryIndexFile)
+	binInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	jsonInfo, err := os.Stat(filepath.Join(dir, indexFile))
+	if err != nil {
+		return nil, err
+	}
+	if binInfo.ModTime().Before(jsonInfo.ModTime()) {
+		return nil, errBadIndex
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeIndex(data)
+}
+
+// encodeIndex serializes entries into the binary index format
+func encodeIndex(entries []types.IndexEntry) []byte {
+	var buf bytes.Buffer
+	buf.Write(binaryIndexMagic)
+	buf.Write(binary.AppendUvarint(nil, uint64(len(entries))))
+	for _, entry := range entries {
+		languages := strings.Join(entry.Languages, ",")
+		for _, field := range []string{entry.Name, entry.Platform, entry.Description, entry.Checksum, languages} {
+			buf.Write(binary.AppendUvarint(nil, uint64(len(field))))
+			buf.WriteString(field)
+		}
+	}
+	return buf.Bytes()
+}
+
+// decodeIndex reads entries in the binary index format. The entries' fields
+// share the memory of a single string, so decoding allocates very little.
+func decodeIndex(data []byte) ([]types.IndexEntry, error) {
+	if !bytes.HasPrefix(data, binaryIndexMagic) {
+		return nil, errBadIndex
+	}
+	text := string(data)
+	pos := len(binaryIndexMagic)
+
+	count, n := binary.Uvarint(data[pos:])
+	if n <= 0 || count > uint64(len(data)) {
+		return nil, errBadIndex
+	}
+	pos += n
+
+	entries := make([]types.IndexEntry, count)
+	for i := range entries {
+		var languages string
+		fields := [...]*string{&entries[i].Name, &entries[i].Platform, &entries[i].Description, &entries[i].Checksum, &languages}
+		for _, field := range fields {
+			size, n := binary.Uvarint(data[pos:])
+			if n <= 0 || size > uint64(len(data)-pos-n) {
+				return nil, errBadIndex
+			}
+			pos += n
+			*field = text[pos : pos+int(size)]
+			pos += int(size)
+		}
+		if languages != "" {
+			entries[i].Languages = strings.Split(languages, ",")
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+func TestBinaryIndexRoundTrip(t *testing.T) {
+	entries := []types.IndexEntry{
+		{Name: "tar", Platform: "common", Description: "Archiving utility", Checksum: "abc", Languages: []string{"de", "pt_BR"}},
+		{Name: "ls", Platform: "linux"},
+	}
+
+	decoded, err := decodeIndex(encodeIndex(entries))
+	if err != nil {
+		t.Fatalf("decodeIndex failed: %v", err)
+	}
+	if len(decoded) != len(entries) {
+		t.Fatalf("Expected %d entries, got %d", len(entries), len(decoded))
+	}
+	for i := range entries {
+		if !reflect.DeepEqual(decoded[i], entries[i]) {
+			t.Errorf("Expected %+v, got %+v", entries[i], decoded[i])
+		}
+	}
+
+	if _, err := decodeIndex([]byte("[]")); err == nil {
+		t.Error("Expected an error for a JSON index")
+	}
+}
+
+func TestLoadIndexFallsBackToJSON(t *testing.T) {
+	tests := []struct {
+		description string
+		damage      func(path string) error
+	}{
+		{"missing", os.Remove},
+		{"corrupt", func(path string) error { return os.WriteFile(path, []byte("TLDRIDX\x01\xff"), 0644) }},
+		{"older than index.json", func(path string) error {
+			old := time.Now().Add(-time.Hour)
+			return os.Chtimes(path, old, old)
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			m := newTestManager(t, testPages)
+			if err := m.Update(); err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+			if err := test.damage(filepath.Join(m.dir, binaryIndexFile)); err != nil {
+				t.Fatalf("Damaging the binary index failed: %v", err)
+			}
+
+			index, err := m.loadIndex()
+			if err != nil {
+				t.Fatalf("loadIndex failed: %v", err)
+			}
+			if len(index) != 3 {
+				t.Errorf("Expected 3 entries from index.json, got %d", len(index))
+			}
+		})
+	}
+}
+
+func TestListPagesReadsOnlyTheIndex(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	// Listing must not touch the page files
+	os.Remove(pagePath(m.dir, types.IndexEntry{Name: "tar", Platform: "common"}))
+
+	entries, err := m.ListPages("tar", []string{"common"})
+	if err != nil {
+		t.Fatalf("ListPages failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name != "tar" {
+		t.Errorf("Expected tar first of 2 entries, got %v", entries)
+	}
+	if entries[0].Description != "Archiving utility" {
+		t.Errorf("Expected description from the index, got '%s'", entries[0].Description)
+	}
+}
+
+// benchmarkIndex returns an index the size of the full tldr-pages set
+func benchmarkIndex(n int) []types.IndexEntry {
+	entries := make([]types.IndexEntry, n)
+	for i := range entries {
+		entries[i] = types.IndexEntry{
+			Name:        fmt.Sprintf("command-%d", i),
+			Platform:    "common",
+			Description: "Does something useful with files",
+			Checksum:    fmt.Sprintf("%064x", i),
+		}
+	}
+	return entries
+}
+
+// BenchmarkLoadIndex compares loading a 6000 page index from JSON and from
+// the binary index
+func BenchmarkLoadIndex(b *testing.B) {
+	for _, binaryIndex := range []bool{false, true} {
+		name := "json"
+		if binaryIndex {
+			name = "binary"
+		}
+		b.Run(name, func(b *testing.B) {
+			m := New(b.TempDir(), nil)
+			if err := os.MkdirAll(m.dir, 0755); err != nil {
+				b.Fatalf("MkdirAll failed: %v", err)
+			}
+			if err := writeIndex(m.dir, benchmarkIndex(6000)); err != nil {
+				b.Fatalf("writeIndex failed: %v", err)
+			}
+			if !binaryIndex {
+				os.Remove(filepath.Join(m.dir, binaryIndexFile))
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := m.ListPages("", nil); err != nil {
+					b.Fatalf("ListPages failed: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/locale"
+)
+
+// metaFile records where the cache was built from
+const metaFile = "meta.json"
+
+// meta is the content of metaFile
+type meta struct {
+	Source string `json:"source"`
+	URL    string `json:"url"`
+	// Trusted is whether the source was trusted when the cache was built
+	Trusted bool `json:"trusted,omitempty"`
+	// Pin is the pages version the cache was built at, if pinned
+	Pin string `json:"pin,omitempty"`
+}
+
+// Info describes the contents and state of the cache
+type Info struct {
+	Dir       string         `json:"dir"`
+	Pages     int            `json:"pages"`
+	Platforms map[string]int `json:"platforms"`
+	Languages map[string]int `json:"languages"`
+	SizeBytes int64          `json:"size_bytes"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Stale     bool           `json:"stale"`
+	Source    string         `json:"source,omitempty"`
+	SourceURL string         `json:"source_url,omitempty"`
+	// Pinned is the pages version the cache was built at, if pinned
+	Pinned string `json:"pinned,omitempty"`
+}
+
+// Size returns the cache size formatted for display
+func (i *Info) Size() string {
+	return formatBytes(i.SizeBytes)
+}
+
+// Info gathers statistics about the cache. The cache is stale when it is
+// older than ttl.
+func (m *Manager) Info(ttl time.Duration) (*Info, error) {
+	index, err := m.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(filepath.Join(m.dir, indexFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat index: %w", err)
+	}
+
+	info := &Info{
+		Dir:       m.dir,
+		Pages:     len(index),
+		Platforms: make(map[string]int),
+		Languages: map[string]int{locale.English: len(index)},
+		UpdatedAt: stat.ModTime(),
+		Stale:     m.IsStale(ttl),
+	}
+	for _, entry := range index {
+		info.Platforms[entry.Platform]++
+		for _, language := range entry.Languages {
+			info.Languages[language]++
+		}
+	}
+
+	err = filepath.WalkDir(m.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		fileInfo, err := d.Info()
+		if err != nil {
+			return err
+		}
+		info.SizeBytes += fileInfo.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure cache size: %w", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(m.dir, metaFile)); err == nil {
+		var source meta
+		if err := json.Unmarshal(data, &source); err == nil {
+			info.Source = source.Source
+			info.SourceURL = source.URL
+			info.Pinned = source.Pin
+		}
+	}
+
+	return info, nil
+}
+
+// Origin returns the name of the source the cache was built from and
+// whether its pages are trusted: pages from the official archive or from a
+// source marked trusted are. A cache without metadata is untrusted.
+func (m *Manager) Origin() (string, bool) {
+	data, err := os.ReadFile(filepath.Join(m.dir, metaFile))
+	if err != nil {
+		return "", false
+	}
+	var source meta
+	if err := json.Unmarshal(data, &source); err != nil {
+		return "", false
+	}
+	if source.Trusted || config.IsOfficial(source.URL) {
+		return source.Source, true
+	}
+	// The source may have been marked trusted since the cache was built
+	for _, src := range m.sources {
+		if src.URL == source.URL && src.IsTrusted() {
+			return source.Source, true
+		}
+	}
+	return source.Source, false
+}
+
+// writeMeta records the source a cache in dir was built from and the
+// version it was pinned to
+func writeMeta(dir string, src config.Source, pin string) error {
+	data, err := json.MarshalIndent(meta{Source: sourceName(src), URL: src.URL, Trusted: src.IsTrusted(), Pin: pin}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache metadata: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, metaFile), data); err != nil {
+		return fmt.Errorf("failed to write cache metadata: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/log"
+)
+
+// lockPollInterval is how often a busy cache lock is tried again
+const lockPollInterval = 100 * time.Millisecond
+
+// lockPath returns the lock file guarding writes to the cache. It lives next
+// to the cache directory since the directory itself is swapped on update.
+func (m *Manager) lockPath() string {
+	return m.dir + ".lock"
+}
+
+// lock takes the exclusive write lock on the cache, waiting while another
+// process or manager holds it. The returned function releases the lock.
+func (m *Manager) lock(ctx context.Context) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(m.dir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	f, err := os.OpenFile(m.lockPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache lock: %w", err)
+	}
+
+	waited := false
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock cache: %w", err)
+		}
+		if locked {
+			return func() {
+				unlock(f)
+				f.Close()
+			}, nil
+		}
+		if !waited {
+			log.Info("waiting for the cache lock", "path", m.lockPath())
+			waited = true
+		}
+
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// writeFileAtomic replaces path with data so that readers see either the
+// old or the new content, never a partial write
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
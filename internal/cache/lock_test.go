@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUpdateWaitsForLock(t *testing.T) {
+	m := newTestManager(t, testPages)
+
+	// Another manager on the same directory, as in a second process
+	other := New(m.dir, m.sources)
+	release, err := other.lock(context.Background())
+	if err != nil {
+		t.Fatalf("lock failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*lockPollInterval)
+	defer cancel()
+	if err := m.UpdateContext(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected update to wait for the lock, got %v", err)
+	}
+
+	release()
+	if err := m.Update(); err != nil {
+		t.Fatalf("Expected update to succeed once the lock is free, got %v", err)
+	}
+}
+
+func TestChangedAfterExternalUpdate(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	reader := New(m.dir, m.sources)
+	if reader.Changed() {
+		t.Error("Expected no change before the index was read")
+	}
+	if _, err := reader.ListPages("", nil); err != nil {
+		t.Fatalf("ListPages failed: %v", err)
+	}
+	if reader.Changed() {
+		t.Error("Expected no change right after reading the index")
+	}
+
+	// Another process rewrites the index
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(m.dir, indexFile), later, later); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	if !reader.Changed() {
+		t.Error("Expected the rewritten index to be detected")
+	}
+
+	if _, err := reader.ListPages("", nil); err != nil {
+		t.Fatalf("ListPages failed: %v", err)
+	}
+	if reader.Changed() {
+		t.Error("Expected no change once the new index was read")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	if err := writeFileAtomic(path, []byte("new")); err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "new" {
+		t.Errorf("Expected 'new', got '%s' (%v)", data, err)
+	}
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*"))
+	if len(files) != 1 {
+		t.Errorf("Expected no temporary files left, got %v", files)
+	}
+}
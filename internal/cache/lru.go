@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// pageCacheSize is how many parsed pages are kept in memory
+const pageCacheSize = 64
+
+// pageCache is a small least-recently-used cache of parsed pages, safe for
+// concurrent use
+type pageCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // Most recently used first
+	items map[string]*list.Element
+}
+
+// pageCacheItem is an element of pageCache.order
+type pageCacheItem struct {
+	key  string
+	page *types.Page
+}
+
+// newPageCache creates a cache holding up to size pages
+func newPageCache(size int) *pageCache {
+	return &pageCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached page for key, marking it as recently used
+func (c *pageCache) get(key string) (*types.Page, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*pageCacheItem).page, true
+}
+
+// add caches a page, evicting the least recently used one when full
+func (c *pageCache) add(key string, page *types.Page) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		element.Value.(*pageCacheItem).page = page
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&pageCacheItem{key: key, page: page})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*pageCacheItem).key)
+	}
+}
+
+// clear drops every cached page
+func (c *pageCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+func TestPageCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newPageCache(2)
+	c.add("common/tar", &types.Page{Name: "tar"})
+	c.add("common/ls", &types.Page{Name: "ls"})
+
+	// Touch tar so ls becomes the oldest
+	if _, ok := c.get("common/tar"); !ok {
+		t.Fatal("Expected tar to be cached")
+	}
+	c.add("common/git", &types.Page{Name: "git"})
+
+	if _, ok := c.get("common/ls"); ok {
+		t.Error("Expected ls to be evicted")
+	}
+	for _, key := range []string{"common/tar", "common/git"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("Expected %s to be cached", key)
+		}
+	}
+
+	c.clear()
+	if _, ok := c.get("common/tar"); ok {
+		t.Error("Expected clear to drop every page")
+	}
+}
@@ -0,0 +1,18 @@
+package cache
+
+import (
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/network"
+)
+
+// SetNetwork makes downloads go through the proxy and TLS settings of cfg,
+// and git sources use them too
+func (m *Manager) SetNetwork(cfg config.Network) error {
+	transport, err := network.Transport(cfg)
+	if err != nil {
+		return err
+	}
+	m.client.Transport = transport
+	m.gitEnv = network.GitEnv(cfg)
+	return nil
+}
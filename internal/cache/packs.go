@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"path/filepath"
+
+	"github.com/makalin/tldrpp/internal/pack"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// SetPacksDir sets the directory page packs are installed in. Their pages
+// are listed along with the cached pages and replace cached pages of the
+// same name and platform; custom pages replace theirs.
+func (m *Manager) SetPacksDir(dir string) {
+	m.packsDir = dir
+}
+
+// packEntries lists the pages of the installed packs, in pack name order
+func (m *Manager) packEntries() []types.IndexEntry {
+	if m.packsDir == "" {
+		return nil
+	}
+	packs, err := pack.List(m.packsDir)
+	if err != nil {
+		return nil
+	}
+
+	var entries []types.IndexEntry
+	for _, p := range packs {
+		name := p.Manifest.Name
+		entries = append(entries, pageEntries(filepath.Join(m.packsDir, name, "pages"), name)...)
+	}
+	return entries
+}
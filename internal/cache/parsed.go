@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/makalin/tldrpp/internal/log"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// parsedExt is the extension of the files holding pages already parsed, next
+// to the markdown they were parsed from
+const parsedExt = ".page.zst"
+
+// parsedPageMagic starts every parsed page, followed by the SHA-256 of the
+// markdown it was parsed from and the page's fields, strings length-prefixed
+// and lists count-prefixed. The last byte is the format version, changed
+// whenever parsing does so that pages parsed by an older version are parsed
+// again.
+var parsedPageMagic = []byte("TLDRPAG\x01")
+
+// errBadParsedPage reports a parsed page that can't be used
+var errBadParsedPage = errors.New("malformed parsed page")
+
+// parsedPagePath returns where a page is stored parsed below dir
+func parsedPagePath(dir string, entry types.IndexEntry) string {
+	return filepath.Join(dir, entry.Platform, entry.Name+parsedExt)
+}
+
+// readParsedPage returns the parsed page stored for entry below dir, or nil
+// when there is none or it was parsed from markdown other than the indexed
+// one, so a page that changed is parsed again
+func readParsedPage(dir string, entry types.IndexEntry) *types.Page {
+	if entry.Checksum == "" {
+		return nil
+	}
+	data, err := os.ReadFile(parsedPagePath(dir, entry))
+	if err != nil {
+		return nil
+	}
+	if data, err = decoder.DecodeAll(data, nil); err == nil {
+		var checksum string
+		var page *types.Page
+		if checksum, page, err = decodePage(data); err == nil && checksum == entry.Checksum {
+			return page
+		}
+	}
+	if err != nil {
+		log.Debug("parsed page unusable", "page", entry.Name, "err", err)
+	}
+	return nil
+}
+
+// writeParsedPage stores a page parsed from the markdown with the given
+// checksum below dir
+func writeParsedPage(dir string, entry types.IndexEntry, checksum string, page *types.Page) error {
+	path := parsedPagePath(dir, entry)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create platform directory: %w", err)
+	}
+	if err := writeFileAtomic(path, encoder.EncodeAll(encodePage(checksum, page), nil)); err != nil {
+		return fmt.Errorf("failed to write parsed page %s: %w", entry.Name, err)
+	}
+	return nil
+}
+
+// pageWriter appends the fields of a parsed page to a buffer
+type pageWriter struct {
+	bytes.Buffer
+}
+
+func (w *pageWriter) count(n int) {
+	w.Write(binary.AppendUvarint(nil, uint64(n)))
+}
+
+func (w *pageWriter) strings(fields ...string) {
+	for _, field := range fields {
+		w.count(len(field))
+		w.WriteString(field)
+	}
+}
+
+func (w *pageWriter) bools(fields ...bool) {
+	for _, field := range fields {
+		if field {
+			w.WriteByte(1)
+		} else {
+			w.WriteByte(0)
+		}
+	}
+}
+
+// encodePage serializes a page parsed from the markdown with the given
+// checksum
+func encodePage(checksum string, page *types.Page) []byte {
+	var w pageWriter
+	w.Write(parsedPageMagic)
+	w.strings(checksum, page.Name, page.Description, page.Platform, page.RawContent, page.MoreInfoURL)
+	w.count(len(page.SeeAlso))
+	w.strings(page.SeeAlso...)
+	w.count(len(page.Examples))
+	for _, example := range page.Examples {
+		w.strings(example.Description, example.Command)
+		w.count(len(example.Placeholders))
+		for _, placeholder := range example.Placeholders {
+			w.strings(placeholder.Name, placeholder.Type, placeholder.Description, placeholder.Default, placeholder.Display)
+			w.bools(placeholder.Raw, placeholder.Variadic)
+			w.count(len(placeholder.Choices))
+			w.strings(placeholder.Choices...)
+		}
+	}
+	return w.Bytes()
+}
+
+// pageReader reads the fields of a parsed page. The strings share the memory
+// of a single string, so decoding allocates very little. The first malformed
+// field sets err, after which every field reads empty.
+type pageReader struct {
+	data []byte
+	text string
+	pos  int
+	err  error
+}
+
+func (r *pageReader) count() int {
+	if r.err != nil {
+		return 0
+	}
+	n, size := binary.Uvarint(r.data[r.pos:])
+	if size <= 0 || n > uint64(len(r.data)-r.pos-size) {
+		r.err = errBadParsedPage
+		return 0
+	}
+	r.pos += size
+	return int(n)
+}
+
+func (r *pageReader) strings(fields ...*string) {
+	for _, field := range fields {
+		n := r.count()
+		if r.err != nil {
+			return
+		}
+		*field = r.text[r.pos : r.pos+n]
+		r.pos += n
+	}
+}
+
+func (r *pageReader) bools(fields ...*bool) {
+	for _, field := range fields {
+		if r.err != nil {
+			return
+		}
+		if r.pos >= len(r.data) || r.data[r.pos] > 1 {
+			r.err = errBadParsedPage
+			return
+		}
+		*field = r.data[r.pos] == 1
+		r.pos++
+	}
+}
+
+// list reads a count-prefixed list of strings
+func (r *pageReader) list() []string {
+	n := r.count()
+	if n == 0 {
+		return nil
+	}
+	list := make([]string, n)
+	for i := range list {
+		r.strings(&list[i])
+	}
+	return list
+}
+
+// decodePage reads a page serialized by encodePage and the checksum of the
+// markdown it was parsed from
+func decodePage(data []byte) (string, *types.Page, error) {
+	if !bytes.HasPrefix(data, parsedPageMagic) {
+		return "", nil, errBadParsedPage
+	}
+	r := &pageReader{data: data, text: string(data), pos: len(parsedPageMagic)}
+
+	var checksum string
+	page := &types.Page{}
+	r.strings(&checksum, &page.Name, &page.Description, &page.Platform, &page.RawContent, &page.MoreInfoURL)
+	page.SeeAlso = r.list()
+	if n := r.count(); n > 0 {
+		page.Examples = make([]types.Example, n)
+	}
+	for i := range page.Examples {
+		example := &page.Examples[i]
+		r.strings(&example.Description, &example.Command)
+		if n := r.count(); n > 0 {
+			example.Placeholders = make([]types.Placeholder, n)
+		}
+		for j := range example.Placeholders {
+			placeholder := &example.Placeholders[j]
+			r.strings(&placeholder.Name, &placeholder.Type, &placeholder.Description, &placeholder.Default, &placeholder.Display)
+			r.bools(&placeholder.Raw, &placeholder.Variadic)
+			placeholder.Choices = r.list()
+		}
+	}
+	if r.err != nil {
+		return "", nil, r.err
+	}
+	if r.pos != len(data) {
+		return "", nil, errBadParsedPage
+	}
+	return checksum, page, nil
+}
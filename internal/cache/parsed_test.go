@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// TestParsedPageRoundTrip checks that every page of the corpus reads back
+// exactly as it was parsed
+func TestParsedPageRoundTrip(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "types", "testdata", "pages", "*", "*.md"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("Failed to list the page corpus: %v", err)
+	}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile failed: %v", err)
+		}
+		entry := types.IndexEntry{Name: strings.TrimSuffix(filepath.Base(path), ".md"), Platform: filepath.Base(filepath.Dir(path))}
+		page, err := types.ParsePage(string(content), entry)
+		if err != nil {
+			continue
+		}
+
+		checksum, decoded, err := decodePage(encodePage("sum", page))
+		if err != nil {
+			t.Fatalf("decodePage failed for %s: %v", path, err)
+		}
+		if checksum != "sum" || !reflect.DeepEqual(decoded, page) {
+			t.Errorf("Expected %s to read back as parsed, got %+v", path, decoded)
+		}
+	}
+}
+
+func TestParsedPages(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	index, err := m.loadIndex()
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	var entry types.IndexEntry
+	for _, e := range index {
+		if e.Name == "ls" {
+			entry = e
+		}
+	}
+
+	// Updating parses every page once
+	page := readParsedPage(m.dir, entry)
+	if page == nil || page.Description != "List directory contents" {
+		t.Fatalf("Expected ls to be stored parsed, got %+v", page)
+	}
+
+	// The parsed page is read instead of the markdown while its checksum
+	// matches the index
+	page.Description = "Parsed before"
+	if err := writeParsedPage(m.dir, entry, entry.Checksum, page); err != nil {
+		t.Fatalf("writeParsedPage failed: %v", err)
+	}
+	m.pages.clear()
+	if loaded, err := m.loadPage(entry); err != nil || loaded.Description != "Parsed before" {
+		t.Errorf("Expected the parsed page to be read, got %+v, %v", loaded, err)
+	}
+
+	// Parsed from other markdown, the page is parsed again and stored anew
+	if err := writeParsedPage(m.dir, entry, "stale", page); err != nil {
+		t.Fatalf("writeParsedPage failed: %v", err)
+	}
+	m.pages.clear()
+	if loaded, err := m.loadPage(entry); err != nil || loaded.Description != "List directory contents" {
+		t.Errorf("Expected a stale parsed page to be parsed again, got %+v, %v", loaded, err)
+	}
+	if page := readParsedPage(m.dir, entry); page == nil || page.Description != "List directory contents" {
+		t.Errorf("Expected the page to be stored parsed again, got %+v", page)
+	}
+
+	// A damaged file is ignored
+	if err := os.WriteFile(parsedPagePath(m.dir, entry), []byte("damaged"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	m.pages.clear()
+	if loaded, err := m.loadPage(entry); err != nil || loaded.Description != "List directory contents" {
+		t.Errorf("Expected a damaged parsed page to be parsed again, got %+v, %v", loaded, err)
+	}
+}
+
+func TestDecodePageTruncated(t *testing.T) {
+	page, err := types.ParsePage(testPages["pages/common/tar.md"], types.IndexEntry{Name: "tar", Platform: "common"})
+	if err != nil {
+		t.Fatalf("ParsePage failed: %v", err)
+	}
+	data := encodePage("sum", page)
+	for i := 0; i < len(data); i++ {
+		if _, _, err := decodePage(data[:i]); err == nil {
+			t.Fatalf("Expected a page truncated to %d bytes to be rejected", i)
+		}
+	}
+}
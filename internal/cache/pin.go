@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/config"
+)
+
+// Latest asks for the newest pages, overriding a pinned version
+const Latest = "latest"
+
+// officialRepo is the tldr-pages repository the official archive is built
+// from
+const officialRepo = "https://github.com/tldr-pages/tldr"
+
+// commitPattern matches a commit hash, full or abbreviated
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// isCommit reports whether a version is a commit hash rather than a tag or
+// branch
+func isCommit(version string) bool {
+	return commitPattern.MatchString(version)
+}
+
+// SetPin pins the pages to a tldr-pages release such as v2.3 or a commit,
+// making updates fetch that version instead of the newest. Empty or Latest
+// fetches the newest.
+func (m *Manager) SetPin(version string) {
+	if version == Latest {
+		version = ""
+	}
+	m.pin = version
+}
+
+// Pinned returns the version the cache was built at, or "" if it was built
+// from the newest pages or isn't built
+func (m *Manager) Pinned() string {
+	data, err := os.ReadFile(filepath.Join(m.dir, metaFile))
+	if err != nil {
+		return ""
+	}
+	var source meta
+	if err := json.Unmarshal(data, &source); err != nil {
+		return ""
+	}
+	return source.Pin
+}
+
+// pinnedSources returns the sources to update from: all of them, or with a
+// pin those that can fetch the pinned version, changed to fetch it
+func (m *Manager) pinnedSources() ([]config.Source, error) {
+	if m.pin == "" {
+		return m.sources, nil
+	}
+	var sources []config.Source
+	for _, src := range m.sources {
+		if pinned, ok := pinSource(src, m.pin); ok {
+			sources = append(sources, pinned)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no source can fetch pages version %s: only the official archive and git sources can be pinned", m.pin)
+	}
+	return sources, nil
+}
+
+// pinSource changes a source to fetch pages at a version: a git source
+// checks it out, and the official archive is replaced by that release's
+// archive, or the repository's archive at a commit. Other archives can't
+// be pinned.
+func pinSource(src config.Source, version string) (config.Source, bool) {
+	switch {
+	case isGitSource(src):
+		repo, _ := parseGitURL(src.URL)
+		src.URL = gitPrefix + repo + "#" + version
+		return src, true
+	case config.IsOfficial(src.URL) && isCommit(version):
+		src.URL = officialRepo + "/archive/" + version + ".zip"
+		src.Checksum = ""
+	case config.IsOfficial(src.URL):
+		src.URL = officialRepo + "/releases/download/" + version + "/tldr.zip"
+		src.Checksum = officialRepo + "/releases/download/" + version + "/tldr.sha256sums"
+	default:
+		return src, false
+	}
+	// Still the official pages, from the same repository
+	src.Trusted = true
+	// Repairs fetch single pages at the same version
+	src.Pages = strings.Replace(src.Pages, "/tldr/main/", "/tldr/"+version+"/", 1)
+	return src, true
+}
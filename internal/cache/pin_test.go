@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/config"
+)
+
+func TestPinSource(t *testing.T) {
+	official := config.DefaultSources()[0]
+	tests := []struct {
+		name    string
+		src     config.Source
+		version string
+		url     string
+		ok      bool
+	}{
+		{"release", official, "v2.3", "https://github.com/tldr-pages/tldr/releases/download/v2.3/tldr.zip", true},
+		{"commit", official, "9f1c2ab", "https://github.com/tldr-pages/tldr/archive/9f1c2ab.zip", true},
+		{"git", config.Source{URL: "git+https://example.com/team/tldr.git#main"}, "v1", "git+https://example.com/team/tldr.git#v1", true},
+		{"mirror", config.Source{URL: "https://mirror.example.com/tldr.zip"}, "v2.3", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pinSource(tt.src, tt.version)
+			if ok != tt.ok || (ok && got.URL != tt.url) {
+				t.Errorf("pinSource = %q, %v, want %q, %v", got.URL, ok, tt.url, tt.ok)
+			}
+		})
+	}
+
+	pinned, _ := pinSource(official, "v2.3")
+	if !pinned.IsTrusted() || !strings.Contains(pinned.Pages, "/tldr/v2.3/pages/") || !strings.HasSuffix(pinned.Checksum, "/v2.3/tldr.sha256sums") {
+		t.Errorf("Expected a trusted release with its checksum and pages, got %+v", pinned)
+	}
+}
+
+func TestPinnedUpdate(t *testing.T) {
+	repo := gitRepo(t, testPages)
+	first, err := (&Manager{}).runGit(context.Background(), config.Source{}, repo, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitPages(t, repo, map[string]string{
+		"pages/common/deploy.md": "# deploy\n\n> Deploy the app.\n\n- Deploy:\n\n`deploy`\n",
+	})
+
+	m := New(filepath.Join(t.TempDir(), "pages"), []config.Source{
+		{Name: "mirror", URL: "https://mirror.invalid/tldr.zip"},
+		{Name: "team", URL: gitPrefix + "file://" + filepath.ToSlash(repo) + "#main"},
+	})
+	m.retryDelay = time.Millisecond
+	m.SetPin(first)
+	if !m.IsStale(0) {
+		t.Error("Expected a missing cache to be stale")
+	}
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := m.FindPage("tar"); err != nil {
+		t.Fatalf("Expected tar page, got %v", err)
+	}
+	if page, err := m.FindPage("deploy"); err == nil && page.Name == "deploy" {
+		t.Error("Expected the page added after the pinned commit to be missing")
+	}
+	if m.Pinned() != first || m.IsStale(time.Nanosecond) {
+		t.Errorf("Expected a fresh cache pinned to %s, got %q", first, m.Pinned())
+	}
+
+	// Changing or dropping the pin makes the cache stale
+	m.SetPin(Latest)
+	if !m.IsStale(0) {
+		t.Error("Expected the cache to be stale once unpinned")
+	}
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := m.FindPage("deploy"); err != nil {
+		t.Errorf("Expected the latest pages, got %v", err)
+	}
+
+	mirror := New(filepath.Join(t.TempDir(), "pages"), []config.Source{{Name: "mirror", URL: "https://mirror.invalid/tldr.zip"}})
+	mirror.SetPin("v2.3")
+	if err := mirror.Update(); err == nil || !strings.Contains(err.Error(), "can be pinned") {
+		t.Errorf("Expected no source to be pinnable, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/makalin/tldrpp/internal/config"
+)
+
+// Sources returns the sources pages are downloaded from, in the order they
+// are tried
+func (m *Manager) Sources() []config.Source {
+	return m.sources
+}
+
+// CheckSource reports whether a source can be downloaded from without
+// downloading it: the archive, local or remote, is asked for with a HEAD
+// request and a git repository must answer ls-remote
+func (m *Manager) CheckSource(ctx context.Context, src config.Source) error {
+	if isGitSource(src) {
+		repo, _ := parseGitURL(src.URL)
+		_, err := m.runGit(ctx, src, "", "ls-remote", "--heads", repo)
+		return err
+	}
+
+	req, err := newRequest(ctx, http.MethodHead, src.URL, src)
+	if err != nil {
+		return err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	// Some servers reject HEAD, which still shows they are reachable
+	if resp.StatusCode == http.StatusMethodNotAllowed {
+		return nil
+	}
+	return checkStatus(resp, http.StatusOK)
+}
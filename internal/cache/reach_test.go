@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/config"
+)
+
+func TestCheckSource(t *testing.T) {
+	server := httptest.NewServer(archiveHandler(archive(t, testPages), nil))
+	defer server.Close()
+	closed := httptest.NewServer(archiveHandler(nil, nil))
+	closed.Close()
+
+	local := filepath.Join(t.TempDir(), "tldr.zip")
+	if err := os.WriteFile(local, archive(t, testPages), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	m := New(t.TempDir(), nil)
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{"reachable", server.URL + "/tldr.zip", false},
+		{"not found", server.URL + "/missing.zip", true},
+		{"unreachable", closed.URL + "/tldr.zip", true},
+		{"local archive", "file://" + local, false},
+		{"missing local archive", "file://" + local + ".gone", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.CheckSource(context.Background(), config.Source{URL: tt.url})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error: %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
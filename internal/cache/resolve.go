@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// resolveWords finds the page for a command line such as "git commit -m
+// msg". Subcommand pages are named with dashes (git-commit), so the longest
+// run of leading words that names a page wins: git-commit, then git. It
+// returns the page's position in index, or -1, and the words left over.
+func (m *Manager) resolveWords(index []types.IndexEntry, command string) (int, []string) {
+	words := strings.Fields(command)
+	if len(words) == 0 {
+		return -1, nil
+	}
+	if name := m.resolveAlias(index, words[0]); name != "" {
+		words = append(strings.Fields(name), words[1:]...)
+	}
+
+	// Options and arguments that look like paths never name a subcommand
+	n := 0
+	for n < len(words) && !strings.HasPrefix(words[n], "-") && !strings.ContainsAny(words[n], `/\.=`) {
+		n++
+	}
+	for ; n > 0; n-- {
+		if found := m.exactMatch(index, strings.Join(words[:n], "-")); found >= 0 {
+			return found, words[n:]
+		}
+	}
+	return -1, words
+}
+
+// ResolvePage finds the page for a command line, e.g. the git-commit page
+// for "git commit -m msg", like FindPage. It also returns the words after
+// the page's name, which tell which of its examples is meant.
+func (m *Manager) ResolvePage(command string) (*types.Page, string, error) {
+	index, err := m.index()
+	if err != nil {
+		return nil, "", err
+	}
+	if found, rest := m.resolveWords(index, command); found >= 0 {
+		page, err := m.loadPage(index[found])
+		return page, strings.Join(rest, " "), err
+	}
+	page, err := m.FindPage(command)
+	return page, "", err
+}
+
+// PageName returns the name of the page for a command line, without
+// loading it, or "" if no page has the name
+func (m *Manager) PageName(command string) string {
+	index, err := m.index()
+	if err != nil {
+		return ""
+	}
+	if found, _ := m.resolveWords(index, command); found >= 0 {
+		return index[found].Name
+	}
+	return ""
+}
+
+// Subcommands returns the names of the subcommand pages of the page named
+// name, such as git-commit and git-push for git, in alphabetical order
+func (m *Manager) Subcommands(name string) []string {
+	index, err := m.index()
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, entry := range index {
+		if strings.HasPrefix(entry.Name, name+"-") && !seen[entry.Name] {
+			seen[entry.Name] = true
+			names = append(names, entry.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolvePage(t *testing.T) {
+	pages := map[string]string{
+		"pages/common/git.md":        "# git\n\n> Version control.\n\n- Show the version:\n\n`git --version`\n",
+		"pages/common/git-commit.md": "# git commit\n\n> Record changes.\n\n- Commit staged files:\n\n`git commit -m {{message}}`\n",
+		"pages/common/git-push.md":   "# git push\n\n> Push commits.\n\n- Push:\n\n`git push`\n",
+		"pages/common/tar.md":        "# tar\n\n> Archiving utility.\n\n- Extract an archive:\n\n`tar -xf {{source.tar}}`\n",
+	}
+	m := newTestManager(t, pages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	tests := []struct {
+		command  string
+		expected string
+		rest     string
+	}{
+		{"git commit", "git-commit", ""},
+		{"git commit -m fix", "git-commit", "-m fix"},
+		{"git status", "git", "status"},
+		{"g push", "git-push", ""},
+		{"tar extract archive", "tar", "extract archive"},
+		{"git ./commit", "git", "./commit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			page, rest, err := m.ResolvePage(tt.command)
+			if err != nil {
+				t.Fatalf("ResolvePage failed: %v", err)
+			}
+			if page.Name != tt.expected || rest != tt.rest {
+				t.Errorf("Expected %s with '%s' left, got %s with '%s'", tt.expected, tt.rest, page.Name, rest)
+			}
+
+			if page, err := m.FindPage(tt.command); err != nil || page.Name != tt.expected {
+				t.Errorf("Expected FindPage to find %s, got %v (%v)", tt.expected, page, err)
+			}
+		})
+	}
+
+	if subcommands := m.Subcommands("git"); !reflect.DeepEqual(subcommands, []string{"git-commit", "git-push"}) {
+		t.Errorf("Expected git's subcommands, got %v", subcommands)
+	}
+	if subcommands := m.Subcommands("tar"); len(subcommands) != 0 {
+		t.Errorf("Expected no subcommands for tar, got %v", subcommands)
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/minisign"
+)
+
+// SetRequireSigned makes updates refuse pages that aren't signed: archives
+// without a valid minisign signature, and git checkouts whose commit
+// signature git can't verify. Single pages can't be signed, so repairs
+// only use git checkouts.
+func (m *Manager) SetRequireSigned(require bool) {
+	m.requireSigned = require
+}
+
+// verifySignature checks the archive against the source's detached minisign
+// signature. A source without one is accepted unless signatures are
+// required; a source with one must provide it.
+func (m *Manager) verifySignature(ctx context.Context, src config.Source, archive string) error {
+	if src.Signature == "" {
+		if m.requireSigned {
+			return fmt.Errorf("source has no signature and require_signed_sources is set")
+		}
+		return nil
+	}
+	if src.PublicKey == "" {
+		return fmt.Errorf("source has a signature but no public_key to check it with")
+	}
+
+	key, err := minisign.ParsePublicKey(src.PublicKey)
+	if err != nil {
+		return err
+	}
+	data, err := m.fetchFile(ctx, src, src.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signature: %w", err)
+	}
+	sig, err := minisign.ParseSignature(string(data))
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+	if err := minisign.Verify(key, sig, file); err != nil {
+		return fmt.Errorf("archive signature is invalid: %w", err)
+	}
+	return nil
+}
+
+// verifyCommit checks the signature of the commit checked out in a git
+// source's clone when signatures are required, with the keys git is set up
+// to trust (gpg, or gpg.ssh.allowedSignersFile for SSH signatures)
+func (m *Manager) verifyCommit(ctx context.Context, src config.Source, dir string) error {
+	if !m.requireSigned {
+		return nil
+	}
+	if _, err := m.runGit(ctx, src, dir, "verify-commit", "HEAD"); err != nil {
+		return fmt.Errorf("commit signature can't be verified and require_signed_sources is set: %w", err)
+	}
+	return nil
+}
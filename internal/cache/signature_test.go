@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/config"
+)
+
+// minisignKey returns a minisign public key and a function signing data
+// with it as minisign -S -l does
+func minisignKey(t *testing.T) (string, func([]byte) string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := []byte("keyid-01")
+	key := base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), id...), pub...))
+	return key, func(data []byte) string {
+		sig := ed25519.Sign(priv, data)
+		comment := "file:tldr.zip"
+		global := ed25519.Sign(priv, append(append([]byte(nil), sig...), comment...))
+		return "untrusted comment: test\n" +
+			base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), id...), sig...)) + "\n" +
+			"trusted comment: " + comment + "\n" +
+			base64.StdEncoding.EncodeToString(global) + "\n"
+	}
+}
+
+func TestSignedSource(t *testing.T) {
+	data := archive(t, testPages)
+	key, sign := minisignKey(t)
+	otherKey, _ := minisignKey(t)
+
+	mux := archiveHandler(data, nil).(*http.ServeMux)
+	mux.HandleFunc("/tldr.zip.minisig", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(sign(data)))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	newSigned := func(publicKey string, require bool) *Manager {
+		src := config.Source{Name: "mirror", URL: server.URL + "/tldr.zip", Signature: server.URL + "/tldr.zip.minisig", PublicKey: publicKey}
+		if publicKey == "" {
+			src.Signature = ""
+		}
+		m := New(filepath.Join(t.TempDir(), "pages"), []config.Source{src})
+		m.retryDelay = time.Millisecond
+		m.SetRequireSigned(require)
+		return m
+	}
+
+	if err := newSigned("untrusted comment: minisign public key\n"+key+"\n", true).Update(); err != nil {
+		t.Errorf("Expected a correctly signed archive to be accepted, got %v", err)
+	}
+	if err := newSigned(otherKey, false).Update(); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("Expected a signature by another key to be refused, got %v", err)
+	}
+	if err := newSigned("", false).Update(); err != nil {
+		t.Errorf("Expected an unsigned archive to be accepted by default, got %v", err)
+	}
+	if err := newSigned("", true).Update(); err == nil || !strings.Contains(err.Error(), "require_signed_sources") {
+		t.Errorf("Expected an unsigned archive to be refused, got %v", err)
+	}
+}
+
+func TestSignedGitSource(t *testing.T) {
+	repo := gitRepo(t, testPages)
+	m := New(filepath.Join(t.TempDir(), "pages"), []config.Source{{Name: "team", URL: gitPrefix + "file://" + filepath.ToSlash(repo) + "#main"}})
+	m.SetRequireSigned(true)
+	if err := m.Update(); err == nil || !strings.Contains(err.Error(), "require_signed_sources") {
+		t.Errorf("Expected an unsigned commit to be refused, got %v", err)
+	}
+	if m.IsInitialized() {
+		t.Error("Expected no cache from an unsigned commit")
+	}
+}
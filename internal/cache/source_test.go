@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/config"
+)
+
+func TestNewOrdersSourcesByPriority(t *testing.T) {
+	m := New(t.TempDir(), []config.Source{
+		{Name: "fallback", Priority: 10},
+		{Name: "mirror", Priority: 1},
+		{Name: "official", Priority: 10},
+	})
+
+	expected := []string{"mirror", "fallback", "official"}
+	for i, name := range expected {
+		if m.sources[i].Name != name {
+			t.Errorf("Expected source %d to be '%s', got '%s'", i, name, m.sources[i].Name)
+		}
+	}
+
+	if m := New(t.TempDir(), nil); len(m.sources) != 1 || m.sources[0].Name != "official" {
+		t.Errorf("Expected the official source by default, got %v", m.sources)
+	}
+}
+
+func TestUpdateFallsBackToNextSource(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(archiveHandler(archive(t, testPages), nil))
+	defer up.Close()
+
+	m := New(filepath.Join(t.TempDir(), "pages"), []config.Source{
+		{Name: "mirror", URL: down.URL + "/tldr.zip", Priority: 1},
+		{Name: "official", URL: up.URL + "/tldr.zip", Priority: 2},
+	})
+	m.retryDelay = 0
+
+	if err := m.Update(); err != nil {
+		t.Fatalf("Expected fallback source to succeed, got %v", err)
+	}
+	if !m.IsInitialized() {
+		t.Error("Expected cache to be initialized")
+	}
+}
+
+func TestUpdateAllSourcesFail(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer down.Close()
+
+	m := New(filepath.Join(t.TempDir(), "pages"), []config.Source{
+		{Name: "a", URL: down.URL + "/a.zip"},
+		{Name: "b", URL: down.URL + "/b.zip"},
+	})
+
+	if err := m.Update(); err == nil {
+		t.Error("Expected an error when every source fails")
+	}
+}
+
+func TestSourceHeaders(t *testing.T) {
+	t.Setenv("TLDRPP_TEST_TOKEN", "secret")
+
+	var auth []string
+	server := httptest.NewServer(archiveHandler(archive(t, testPages), func(w http.ResponseWriter, r *http.Request) bool {
+		auth = append(auth, r.Header.Get("Authorization"))
+		return true
+	}))
+	defer server.Close()
+
+	m := New(filepath.Join(t.TempDir(), "pages"), []config.Source{{
+		URL:     server.URL + "/tldr.zip",
+		Headers: map[string]string{"Authorization": "Bearer ${TLDRPP_TEST_TOKEN}"},
+	}})
+	m.workers = 1
+
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	for _, header := range auth {
+		if header != "Bearer secret" {
+			t.Errorf("Expected expanded Authorization header, got '%s'", header)
+		}
+	}
+}
+
+func TestUpdateFromFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tldr-main.zip")
+
+	// Branch archives wrap the pages in a top-level directory
+	files := map[string]string{}
+	for name, content := range testPages {
+		files["tldr-main/"+name] = content
+	}
+	if err := os.WriteFile(path, archive(t, files), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	m := New(filepath.Join(dir, "pages"), []config.Source{{URL: "file://" + filepath.ToSlash(path)}})
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	if _, err := m.FindPage("ls"); err != nil {
+		t.Errorf("Expected ls page from local archive, got %v", err)
+	}
+}
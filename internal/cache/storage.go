@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// pageExt is the extension of zstd-compressed page files
+const pageExt = ".md.zst"
+
+// The encoder and decoder are safe for concurrent EncodeAll and DecodeAll
+var (
+	encoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	decoder, _ = zstd.NewReader(nil)
+)
+
+// pagePath returns where a page is stored below dir
+func pagePath(dir string, entry types.IndexEntry) string {
+	return filepath.Join(dir, entry.Platform, entry.Name+pageExt)
+}
+
+// translatedPagePath returns where a page's translation into lang is stored
+// below dir, mirroring the pages.<lang> directories of the tldr repository
+func translatedPagePath(dir string, entry types.IndexEntry, lang string) string {
+	return filepath.Join(dir, "pages."+lang, entry.Platform, entry.Name+pageExt)
+}
+
+// legacyPagePath returns where caches written before compression kept a page
+func legacyPagePath(dir string, entry types.IndexEntry) string {
+	return filepath.Join(dir, entry.Platform, entry.Name+".md")
+}
+
+// writePage stores a page compressed
+func writePage(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create platform directory: %w", err)
+	}
+	return writeFileAtomic(path, encoder.EncodeAll(content, nil))
+}
+
+// readPage returns the content of a page below dir, falling back to the
+// uncompressed file of an older cache
+func readPage(dir string, entry types.IndexEntry) ([]byte, error) {
+	data, err := os.ReadFile(pagePath(dir, entry))
+	if os.IsNotExist(err) {
+		return os.ReadFile(legacyPagePath(dir, entry))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decoder.DecodeAll(data, nil)
+}
+
+// readTranslatedPage returns the content of a page's translation below dir
+func readTranslatedPage(dir string, entry types.IndexEntry, lang string) ([]byte, error) {
+	data, err := os.ReadFile(translatedPagePath(dir, entry, lang))
+	if err != nil {
+		return nil, err
+	}
+	return decoder.DecodeAll(data, nil)
+}
@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+func TestPagesStoredCompressed(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	entry := types.IndexEntry{Name: "tar", Platform: "common"}
+	data, err := os.ReadFile(pagePath(m.dir, entry))
+	if err != nil {
+		t.Fatalf("Expected compressed page file: %v", err)
+	}
+	if strings.HasPrefix(string(data), "# tar") {
+		t.Error("Expected page to be stored compressed")
+	}
+
+	content, err := readPage(m.dir, entry)
+	if err != nil {
+		t.Fatalf("readPage failed: %v", err)
+	}
+	if string(content) != testPages["pages/common/tar.md"] {
+		t.Errorf("Expected original content, got '%s'", content)
+	}
+}
+
+func TestReadLegacyPage(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	// Caches written before compression keep plain markdown files
+	entry := types.IndexEntry{Name: "ls", Platform: "linux"}
+	os.Remove(pagePath(m.dir, entry))
+	if err := os.WriteFile(legacyPagePath(m.dir, entry), []byte(testPages["pages/linux/ls.md"]), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	page, err := m.FindPage("ls")
+	if err != nil {
+		t.Fatalf("FindPage failed: %v", err)
+	}
+	if page.Description != "List directory contents" {
+		t.Errorf("Expected legacy page to load, got '%s'", page.Description)
+	}
+}
+
+// benchmarkPages returns a realistically sized set of pages
+func benchmarkPages(n int) map[string]string {
+	files := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("command-%d", i)
+		var page strings.Builder
+		fmt.Fprintf(&page, "# %s\n\n> Does something useful with files.\n> More information: <https://example.com/%s>.\n", name, name)
+		for j := 0; j < 8; j++ {
+			fmt.Fprintf(&page, "\n- Run %s with option %d on a file:\n\n`%s --option-%d {{path/to/file}}`\n", name, j, name, j)
+		}
+		files["pages/common/"+name+".md"] = page.String()
+	}
+	return files
+}
+
+// newBenchmarkManager returns a manager with n pages cached, in legacy
+// uncompressed form if requested
+func newBenchmarkManager(b *testing.B, n int, uncompressed bool) (*Manager, []types.IndexEntry) {
+	b.Helper()
+
+	files := benchmarkPages(n)
+	m := newTestManager(b, files)
+	if err := m.Update(); err != nil {
+		b.Fatalf("Update failed: %v", err)
+	}
+	index, err := m.loadIndex()
+	if err != nil {
+		b.Fatalf("loadIndex failed: %v", err)
+	}
+
+	if uncompressed {
+		for _, entry := range index {
+			os.Remove(pagePath(m.dir, entry))
+			content := files["pages/"+entry.Platform+"/"+entry.Name+".md"]
+			if err := os.WriteFile(legacyPagePath(m.dir, entry), []byte(content), 0644); err != nil {
+				b.Fatalf("WriteFile failed: %v", err)
+			}
+		}
+	}
+	return m, index
+}
+
+// BenchmarkLoadPage compares opening pages from disk, compressed and not,
+// parsing the markdown or reading it parsed, with opening recently used
+// pages from memory
+func BenchmarkLoadPage(b *testing.B) {
+	cases := []struct {
+		name         string
+		uncompressed bool
+		parse        bool
+		warm         bool
+	}{
+		{"uncompressed", true, true, false},
+		{"compressed", false, true, false},
+		{"parsed", false, false, false},
+		{"compressed-warm", false, false, true},
+	}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			m, index := newBenchmarkManager(b, 200, c.uncompressed)
+			if c.parse {
+				// Without a checksum the parsed pages aren't used
+				for i := range index {
+					index[i].Checksum = ""
+				}
+			}
+			if c.warm {
+				index = index[:pageCacheSize/2]
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if !c.warm {
+					m.pages.clear()
+				}
+				if _, err := m.loadPage(index[i%len(index)]); err != nil {
+					b.Fatalf("loadPage failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkFootprint reports how much smaller the compressed pages are than
+// the markdown they hold
+func BenchmarkFootprint(b *testing.B) {
+	m, index := newBenchmarkManager(b, 200, false)
+
+	var raw, stored int64
+	for i := 0; i < b.N; i++ {
+		raw, stored = 0, 0
+		for _, entry := range index {
+			content, err := readPage(m.dir, entry)
+			if err != nil {
+				b.Fatalf("readPage failed: %v", err)
+			}
+			info, err := os.Stat(pagePath(m.dir, entry))
+			if err != nil {
+				b.Fatalf("Stat failed: %v", err)
+			}
+			raw += int64(len(content))
+			stored += info.Size()
+		}
+	}
+
+	b.ReportMetric(float64(stored)/float64(raw), "stored/raw")
+	b.ReportMetric(float64(diskUsage(b, m.dir)), "disk-bytes")
+}
+
+// diskUsage returns the size of the page files below dir
+func diskUsage(b *testing.B, dir string) int64 {
+	var total int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && info.Name() != "index.json" {
+			total += info.Size()
+		}
+		return err
+	})
+	if err != nil {
+		b.Fatalf("Walk failed: %v", err)
+	}
+	return total
+}
@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// ProblemKind classifies an inconsistency found in the cache
+type ProblemKind int
+
+const (
+	// ProblemMissing is an indexed page without a file
+	ProblemMissing ProblemKind = iota
+	// ProblemCorrupt is a page whose content is truncated or damaged
+	ProblemCorrupt
+	// ProblemOrphan is a page file that is not in the index
+	ProblemOrphan
+)
+
+func (k ProblemKind) String() string {
+	switch k {
+	case ProblemMissing:
+		return "missing"
+	case ProblemCorrupt:
+		return "corrupt"
+	case ProblemOrphan:
+		return "orphan"
+	default:
+		return "unknown"
+	}
+}
+
+// Problem is a single inconsistency between the index and the page files
+type Problem struct {
+	Kind  ProblemKind
+	Entry types.IndexEntry
+	Path  string
+}
+
+// VerifyReport lists the problems found by Verify
+type VerifyReport struct {
+	Checked  int
+	Problems []Problem
+}
+
+// Verify checks that every indexed page exists and is intact, and that no
+// page files exist outside the index
+func (m *Manager) Verify() (*VerifyReport, error) {
+	index, err := m.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{Checked: len(index)}
+	indexed := make(map[string]bool, len(index))
+	for _, entry := range index {
+		path := pagePath(m.dir, entry)
+		indexed[path] = true
+		indexed[legacyPagePath(m.dir, entry)] = true
+
+		content, err := readPage(m.dir, entry)
+		switch {
+		case os.IsNotExist(err):
+			report.Problems = append(report.Problems, Problem{Kind: ProblemMissing, Entry: entry, Path: path})
+		case err != nil || !intact(entry, content):
+			report.Problems = append(report.Problems, Problem{Kind: ProblemCorrupt, Entry: entry, Path: path})
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(m.dir, "*", "*"+pageExt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pages: %w", err)
+	}
+	legacy, err := filepath.Glob(filepath.Join(m.dir, "*", "*.md"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pages: %w", err)
+	}
+	for _, path := range append(files, legacy...) {
+		if !indexed[path] {
+			report.Problems = append(report.Problems, Problem{Kind: ProblemOrphan, Path: path})
+		}
+	}
+
+	return report, nil
+}
+
+// intact reports whether a page's content looks undamaged. Pages indexed
+// with a checksum must match it; older caches fall back to checking that the
+// page is valid text with a title.
+func intact(entry types.IndexEntry, content []byte) bool {
+	if entry.Checksum != "" {
+		sum := sha256.Sum256(content)
+		return hex.EncodeToString(sum[:]) == entry.Checksum
+	}
+	return len(content) > 0 && utf8.Valid(content) && strings.HasPrefix(string(content), "# ")
+}
+
+// Repair fixes the problems in a report: orphans are removed, and missing or
+// corrupt pages are fetched again one by one from the first source that has
+// them. It returns the problems it could not fix.
+func (m *Manager) Repair(ctx context.Context, report *VerifyReport) ([]Problem, error) {
+	release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	index, err := m.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[string]int, len(index))
+	for i, entry := range index {
+		positions[pagePath(m.dir, entry)] = i
+	}
+
+	var unfixed []Problem
+	for _, problem := range report.Problems {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if problem.Kind == ProblemOrphan {
+			if err := os.Remove(problem.Path); err != nil && !os.IsNotExist(err) {
+				unfixed = append(unfixed, problem)
+			}
+			continue
+		}
+
+		content, err := m.fetchPage(ctx, problem.Entry)
+		if err != nil {
+			unfixed = append(unfixed, problem)
+			continue
+		}
+		if err := writePage(problem.Path, content); err != nil {
+			return nil, fmt.Errorf("failed to write page %s: %w", problem.Entry.Name, err)
+		}
+		if i, ok := positions[problem.Path]; ok {
+			describe(&index[i], content)
+		}
+	}
+
+	if err := writeIndex(m.dir, index); err != nil {
+		return nil, err
+	}
+	m.pages.clear()
+	return unfixed, nil
+}
+
+// fetchPage gets a single page from the first source that can provide it:
+// the checkout of a git source, or a source with a page URL template
+func (m *Manager) fetchPage(ctx context.Context, entry types.IndexEntry) ([]byte, error) {
+	sources, err := m.pinnedSources()
+	if err != nil {
+		return nil, err
+	}
+	for _, src := range sources {
+		var content []byte
+		var err error
+		switch {
+		case isGitSource(src):
+			if err := m.verifyCommit(ctx, src, m.repoDir()); err != nil {
+				continue
+			}
+			content, err = os.ReadFile(legacyPagePath(filepath.Join(m.repoDir(), "pages"), entry))
+		case src.Pages != "" && !m.requireSigned:
+			content, err = m.fetchFile(ctx, src, pageURL(src, entry))
+		default:
+			continue
+		}
+		if err == nil {
+			return content, nil
+		}
+	}
+	return nil, fmt.Errorf("no source provides page %s/%s", entry.Platform, entry.Name)
+}
+
+// PageURL returns where a page can be read upstream: its URL at the first
+// source with a page URL template, or "" if no source has one
+func (m *Manager) PageURL(entry types.IndexEntry) string {
+	sources, _ := m.pinnedSources()
+	for _, src := range sources {
+		if !isGitSource(src) && src.Pages != "" {
+			return pageURL(src, entry)
+		}
+	}
+	return ""
+}
+
+// pageURL fills in a source's page URL template
+func pageURL(src config.Source, entry types.IndexEntry) string {
+	return strings.NewReplacer("{platform}", entry.Platform, "{name}", entry.Name).Replace(src.Pages)
+}
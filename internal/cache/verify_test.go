@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+func TestVerifyAndRepair(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/", archiveHandler(archive(t, testPages), nil))
+	mux.HandleFunc("/pages/", func(w http.ResponseWriter, r *http.Request) {
+		content, ok := testPages[r.URL.Path[1:]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	m := New(filepath.Join(t.TempDir(), "pages"), []config.Source{{
+		URL:   server.URL + "/tldr.zip",
+		Pages: server.URL + "/pages/{platform}/{name}.md",
+	}})
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	report, err := m.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if report.Checked != 3 || len(report.Problems) != 0 {
+		t.Fatalf("Expected 3 intact pages, got %+v", report)
+	}
+
+	// Truncate one page, delete another and leave a stray file
+	tar := filepath.Join(m.dir, "common", "tar"+pageExt)
+	if err := writePage(tar, []byte("# tar\n\n> Arch")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := os.Remove(filepath.Join(m.dir, "linux", "ls"+pageExt)); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	orphan := filepath.Join(m.dir, "linux", "stray.md")
+	if err := os.WriteFile(orphan, []byte("# stray\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	report, err = m.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	kinds := map[ProblemKind]int{}
+	for _, problem := range report.Problems {
+		kinds[problem.Kind]++
+	}
+	if kinds[ProblemCorrupt] != 1 || kinds[ProblemMissing] != 1 || kinds[ProblemOrphan] != 1 {
+		t.Fatalf("Expected one problem of each kind, got %v", report.Problems)
+	}
+
+	unfixed, err := m.Repair(context.Background(), report)
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if len(unfixed) != 0 {
+		t.Errorf("Expected every problem to be fixed, got %v", unfixed)
+	}
+
+	report, err = m.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(report.Problems) != 0 {
+		t.Errorf("Expected a clean cache after repair, got %v", report.Problems)
+	}
+}
+
+func TestRepairWithoutPageSource(t *testing.T) {
+	m := newTestManager(t, testPages)
+	if err := m.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if err := os.Remove(filepath.Join(m.dir, "linux", "ls"+pageExt)); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	report, err := m.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	unfixed, err := m.Repair(context.Background(), report)
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if len(unfixed) != 1 || unfixed[0].Kind != ProblemMissing {
+		t.Errorf("Expected the missing page to stay unfixed, got %v", unfixed)
+	}
+}
+
+func TestIntactWithoutChecksum(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected bool
+	}{
+		{"# tar\n\n> Archiving utility.\n", true},
+		{"", false},
+		{"\xff\xfe", false},
+		{"garbage", false},
+	}
+
+	for _, test := range tests {
+		if got := intact(types.IndexEntry{Name: "tar"}, []byte(test.content)); got != test.expected {
+			t.Errorf("Expected intact(%q) to be %v, got %v", test.content, test.expected, got)
+		}
+	}
+}
+
+func TestPageURL(t *testing.T) {
+	entry := types.IndexEntry{Name: "tar", Platform: "common"}
+	tests := []struct {
+		name     string
+		sources  []config.Source
+		expected string
+	}{
+		{"no template", []config.Source{{URL: "https://example.com/tldr.zip"}}, ""},
+		{"first template", []config.Source{
+			{URL: "git+https://example.com/tldr.git", Pages: "https://example.com/git/{name}"},
+			{URL: "https://example.com/tldr.zip", Pages: "https://example.com/{platform}/{name}.md"},
+			{URL: "https://mirror.example.com/tldr.zip", Pages: "https://mirror.example.com/{platform}/{name}.md"},
+		}, "https://example.com/common/tar.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(t.TempDir(), tt.sources)
+			if got := m.PageURL(entry); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Package cast records the output of commands as asciinema casts (asciicast
+// v2: a JSON header line followed by a JSON line per chunk of output) and
+// plays them back, so that how a command behaved can be watched again.
+package cast
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/makalin/tldrpp/internal/clock"
+)
+
+// Header is the first line of a cast
+type Header struct {
+	Version   int    `json:"version"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+	Command   string `json:"command,omitempty"`
+	Title     string `json:"title,omitempty"`
+	// Env holds SHELL and TERM, which players use to pick fonts and colors
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// Recorder writes the output written to it as the events of a cast, timed
+// from when it was created. Writes may come from several goroutines, such
+// as a command's stdout and stderr copied separately.
+type Recorder struct {
+	mu    sync.Mutex
+	w     io.Writer
+	clock clock.Clock
+	start time.Time
+	// partial holds the start of a UTF-8 sequence split across writes,
+	// as events are JSON strings
+	partial []byte
+	err     error
+}
+
+// NewRecorder writes header to w and returns a recorder writing the events
+// after it. The header's version and, unless set, its timestamp are filled
+// in.
+func NewRecorder(w io.Writer, header Header, clk clock.Clock) (*Recorder, error) {
+	start := clk.Now()
+	header.Version = 2
+	if header.Timestamp == 0 {
+		header.Timestamp = start.Unix()
+	}
+	line, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write cast: %w", err)
+	}
+	return &Recorder{w: w, clock: clk, start: start}, nil
+}
+
+// Write records p as output printed now. It never fails, so that a command
+// whose output is copied to a recorder isn't stopped by a full disk; Err
+// reports the first write that did.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data := append(r.partial, p...)
+	r.partial = nil
+	// Keep an incomplete rune at the end for the next write
+	for i := 1; i <= utf8.UTFMax-1 && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				r.partial = append([]byte(nil), data[len(data)-i:]...)
+				data = data[:len(data)-i]
+			}
+			break
+		}
+	}
+	r.event(data)
+	return len(p), nil
+}
+
+// Close records what is left of an incomplete rune and reports the first
+// write that failed
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.event(r.partial)
+	r.partial = nil
+	return r.err
+}
+
+// event writes an output event of data, if any
+func (r *Recorder) event(data []byte) {
+	if len(data) == 0 || r.err != nil {
+		return
+	}
+	elapsed := r.clock.Now().Sub(r.start).Seconds()
+	line, err := json.Marshal([]interface{}{elapsed, "o", string(data)})
+	if err == nil {
+		_, err = r.w.Write(append(line, '\n'))
+	}
+	if err != nil {
+		r.err = fmt.Errorf("failed to write cast: %w", err)
+	}
+}
+
+// Play reads a cast from r and writes its output to w at the pace it was
+// recorded, returning its header. Pauses longer than maxIdle are shortened
+// to it, and 0 writes everything at once. Play stops early when ctx is done.
+func Play(ctx context.Context, r io.Reader, w io.Writer, maxIdle time.Duration) (Header, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	var header Header
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return header, err
+		}
+		return header, fmt.Errorf("empty cast")
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return header, fmt.Errorf("invalid cast header: %w", err)
+	}
+	if header.Version != 2 {
+		return header, fmt.Errorf("unsupported cast version %d, expected 2", header.Version)
+	}
+
+	last := 0.0
+	for line := 2; scanner.Scan(); line++ {
+		var event []interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return header, fmt.Errorf("line %d: invalid event: %w", line, err)
+		}
+		if len(event) != 3 {
+			return header, fmt.Errorf("line %d: expected [time, type, data], got %d fields", line, len(event))
+		}
+		at, ok := event[0].(float64)
+		kind, _ := event[1].(string)
+		data, _ := event[2].(string)
+		if !ok {
+			return header, fmt.Errorf("line %d: invalid event time", line)
+		}
+		if kind != "o" {
+			// Input and markers aren't shown
+			continue
+		}
+
+		if wait := time.Duration((at - last) * float64(time.Second)); maxIdle > 0 && wait > 0 {
+			if wait > maxIdle {
+				wait = maxIdle
+			}
+			select {
+			case <-ctx.Done():
+				return header, ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+		last = at
+		if _, err := io.WriteString(w, data); err != nil {
+			return header, err
+		}
+	}
+	return header, scanner.Err()
+}
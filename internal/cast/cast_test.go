@@ -0,0 +1,100 @@
+package cast
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/clock"
+)
+
+func TestRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	now := clock.NewFake(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	r, err := NewRecorder(&buf, Header{Width: 100, Height: 30, Command: "ls -l"}, now)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	r.Write([]byte("total 0\n"))
+	now.Advance(1500 * time.Millisecond)
+	// A rune split across writes is kept whole
+	euro := []byte("€\n")
+	r.Write(euro[:2])
+	r.Write(euro[2:])
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 events, got:\n%s", buf.String())
+	}
+	var header Header
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatalf("Invalid header: %v", err)
+	}
+	if header.Version != 2 || header.Width != 100 || header.Command != "ls -l" || header.Timestamp != now.Now().Add(-1500*time.Millisecond).Unix() {
+		t.Errorf("Unexpected header %+v", header)
+	}
+	if lines[1] != `[0,"o","total 0\n"]` || lines[2] != `[1.5,"o","€\n"]` {
+		t.Errorf("Unexpected events:\n%s\n%s", lines[1], lines[2])
+	}
+}
+
+func TestRecorderConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := NewRecorder(&buf, Header{}, clock.System{})
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Write([]byte("line\n"))
+			}
+		}()
+	}
+	wg.Wait()
+	r.Close()
+
+	var out bytes.Buffer
+	if _, err := Play(context.Background(), &buf, &out, 0); err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+	if out.String() != strings.Repeat("line\n", 400) {
+		t.Errorf("Expected every write played back, got %d bytes", out.Len())
+	}
+}
+
+func TestPlay(t *testing.T) {
+	cast := `{"version":2,"width":80,"height":24,"command":"echo hi"}
+[0.1,"o","hi"]
+[0.2,"i","typed"]
+[0.3,"o","\r\n"]
+`
+	var out bytes.Buffer
+	start := time.Now()
+	header, err := Play(context.Background(), strings.NewReader(cast), &out, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+	if header.Command != "echo hi" || out.String() != "hi\r\n" {
+		t.Errorf("Expected the output of echo hi, got %+v %q", header, out.String())
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Expected pauses cut to 10ms, took %s", elapsed)
+	}
+
+	for _, invalid := range []string{"", `{"version":1}`, "{\"version\":2}\n[\"x\"]\n", "{\"version\":2}\nnot json\n"} {
+		if _, err := Play(context.Background(), strings.NewReader(invalid), &out, 0); err == nil {
+			t.Errorf("Expected an error playing %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package clock tells the time through an interface, so that what depends
+// on it, such as whether the cache outlived its TTL, can be tested at any
+// time without waiting for it.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the clock of the system's time
+type System struct{}
+
+// Now returns the current time
+func (System) Now() time.Time {
+	return time.Now()
+}
+
+// Fake is a clock standing still at a time set by the test using it, until
+// moved on with Advance or Set
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake returns a fake clock telling now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the time the clock was set to
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Set sets the clock to now
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Advance moves the clock on by d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
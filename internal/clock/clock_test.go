@@ -0,0 +1,35 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+	if got := f.Now(); !got.Equal(start) {
+		t.Fatalf("Expected %v, got %v", start, got)
+	}
+	if got := f.Now(); !got.Equal(start) {
+		t.Errorf("Expected a fake clock to stand still, got %v", got)
+	}
+
+	f.Advance(90 * time.Minute)
+	if want := start.Add(90 * time.Minute); !f.Now().Equal(want) {
+		t.Errorf("Expected %v after Advance, got %v", want, f.Now())
+	}
+
+	f.Set(start)
+	if !f.Now().Equal(start) {
+		t.Errorf("Expected %v after Set, got %v", start, f.Now())
+	}
+}
+
+func TestSystem(t *testing.T) {
+	before := time.Now()
+	now := System{}.Now()
+	if now.Before(before) || now.After(time.Now()) {
+		t.Errorf("Expected the system time, got %v", now)
+	}
+}
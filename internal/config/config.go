@@ -4,21 +4,195 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
 
+	"github.com/makalin/tldrpp/internal/shellwords"
 	"github.com/spf13/viper"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Theme              string   `yaml:"theme"`
-	Platforms          []string `yaml:"platforms"`
-	ConfirmDestructive bool     `yaml:"confirm_destructive"`
-	Clipboard          bool     `yaml:"clipboard"`
-	Pager              string   `yaml:"pager"`
-	Keymap             Keymap   `yaml:"keymap"`
-	CacheTTLHours      int      `yaml:"cache_ttl_hours"`
-	CacheDir           string   `yaml:"cache_dir"`
-	DevMode            bool     `yaml:"dev_mode"`
+	Theme string `yaml:"theme"`
+	// Platforms restricts pages to these platforms. Empty shows them all,
+	// the running system's first.
+	Platforms []string `yaml:"platforms"`
+	// Language of the pages to show, e.g. "de" or "pt_BR". Empty follows
+	// the locale (LANGUAGE, LC_ALL, LC_MESSAGES and LANG).
+	Language           string `yaml:"language"`
+	ConfirmDestructive bool   `yaml:"confirm_destructive" mapstructure:"confirm_destructive"`
+	Clipboard          bool   `yaml:"clipboard"`
+	Pager              string `yaml:"pager"`
+	Keymap             Keymap `yaml:"keymap"`
+	CacheTTLHours      int    `yaml:"cache_ttl_hours" mapstructure:"cache_ttl_hours"`
+	CacheDir           string `yaml:"cache_dir" mapstructure:"cache_dir"`
+	// PagesVersion pins the pages to a tldr-pages release, e.g. v2.3, or a
+	// commit. Empty follows the newest pages.
+	PagesVersion string `yaml:"pages_version" mapstructure:"pages_version"`
+	DevMode      bool   `yaml:"dev_mode" mapstructure:"dev_mode"`
+	// Stats records which pages and examples are used, locally only
+	Stats bool `yaml:"stats"`
+	// Accessible lays the TUI out for screen readers: one column, no boxes
+	// and the selection spelled out
+	Accessible bool `yaml:"accessible"`
+	// RestoreSession reopens the TUI where it was left: the search, page,
+	// filters and selection
+	RestoreSession bool `yaml:"restore_session" mapstructure:"restore_session"`
+	// OutputHistory is how many outputs of commands run from the TUI are
+	// kept to browse, in memory only; 0 keeps none
+	OutputHistory int `yaml:"output_history" mapstructure:"output_history"`
+	// SearchDebounceMs is how long the TUI waits after a keystroke before
+	// searching, so fast typing searches once; 0 searches on every key
+	SearchDebounceMs int `yaml:"search_debounce_ms" mapstructure:"search_debounce_ms"`
+	// PresentTypingMs is how long presentation mode takes to type each
+	// character of a command; 0 shows commands whole
+	PresentTypingMs int `yaml:"present_typing_ms" mapstructure:"present_typing_ms"`
+	// KubernetesSuggestions lets placeholders suggest the namespaces, pods
+	// and deployments of the current kubectl context, which means using its
+	// credentials to ask the cluster
+	KubernetesSuggestions bool `yaml:"kubernetes_suggestions" mapstructure:"kubernetes_suggestions"`
+	// PlaceholderMemory sets how the values of placeholders are remembered,
+	// by placeholder name or type: remember, ask or forget. A name wins over
+	// a type.
+	PlaceholderMemory map[string]string `yaml:"placeholder_memory" mapstructure:"placeholder_memory"`
+	// SecretsBackend supplies password and token placeholders: none, env,
+	// pass or secret-tool
+	SecretsBackend string   `yaml:"secrets_backend" mapstructure:"secrets_backend"`
+	Sources        []Source `yaml:"sources"`
+	// RequireSignedSources refuses updates from archives without a valid
+	// signature and git sources whose commits aren't signed
+	RequireSignedSources bool `yaml:"require_signed_sources" mapstructure:"require_signed_sources"`
+	// Aliases map command abbreviations to page names, e.g. k: kubectl, on
+	// top of the built-in ones
+	Aliases map[string]string `yaml:"aliases"`
+	// PageEnv sets environment variables for the commands of a page, by
+	// page name, as NAME=value, e.g. kubectl: [KUBECONFIG=~/.kube/staging]
+	PageEnv map[string][]string `yaml:"page_env" mapstructure:"page_env"`
+	// PackIndex is the URL of the index 'tldrpp pack install <name>' looks
+	// page packs up in, mapping names to tarball URLs
+	PackIndex string `yaml:"pack_index" mapstructure:"pack_index"`
+	// Sync is where 'tldrpp sync' keeps custom pages, snippets and
+	// placeholder memory shared between machines
+	Sync Sync `yaml:"sync"`
+	// Network configures how downloads reach the internet, e.g. through a
+	// corporate proxy
+	Network Network `yaml:"network"`
+	// Exec limits the commands tldrpp runs
+	Exec Exec `yaml:"exec"`
+}
+
+// Network configures the proxy and TLS settings of every download: pages,
+// packs and the sync. git sources and the git sync backend are given the
+// same settings.
+type Network struct {
+	// Proxy is the URL of the proxy to use for every download. Empty uses
+	// HTTPS_PROXY, HTTP_PROXY and NO_PROXY from the environment.
+	Proxy string `yaml:"proxy"`
+	// CAFile is a PEM bundle of certificate authorities trusted on top of
+	// the system's, e.g. that of a proxy intercepting TLS
+	CAFile string `yaml:"ca_file" mapstructure:"ca_file"`
+	// ClientCert and ClientKey are a PEM certificate and key presented to
+	// servers asking for one. The key may be in the certificate's file.
+	ClientCert string `yaml:"client_cert" mapstructure:"client_cert"`
+	ClientKey  string `yaml:"client_key" mapstructure:"client_key"`
+}
+
+// Exec limits how long and how hard the commands tldrpp runs may work, so a
+// runaway example doesn't go on forever
+type Exec struct {
+	// Timeout is how long a command may run before it is stopped, e.g. 10m;
+	// empty or 0 lets it run
+	Timeout string `yaml:"timeout"`
+	// Nice lowers the scheduling priority of commands, from 0 to 19
+	Nice int `yaml:"nice"`
+	// CPUSeconds caps the processor time a command may use; 0 doesn't
+	CPUSeconds int `yaml:"cpu_seconds" mapstructure:"cpu_seconds"`
+	// Shell is the POSIX shell commands run in, with the arguments that
+	// come before the command, e.g. "bash -o pipefail -c"; empty for sh -c
+	Shell string `yaml:"shell"`
+	// Record saves a cast of each command's output, to replay it with
+	// 'tldrpp replay'
+	Record bool `yaml:"record"`
+}
+
+// defaultShell runs commands unless exec.shell names another shell
+var defaultShell = []string{"sh", "-c"}
+
+// ShellArgs returns the arguments running a command in the configured
+// shell, the command itself to be added as the last one
+func (e Exec) ShellArgs() ([]string, error) {
+	if strings.TrimSpace(e.Shell) == "" {
+		return append([]string(nil), defaultShell...), nil
+	}
+	args, err := shellwords.Split(e.Shell)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exec.shell: %w", err)
+	}
+	return args, nil
+}
+
+// Sync configures the backend 'tldrpp sync' pushes to and pulls from
+type Sync struct {
+	// Backend is none, git, webdav or s3
+	Backend string `yaml:"backend"`
+	// URL of the git remote, of the WebDAV directory, or s3://bucket/prefix
+	URL string `yaml:"url"`
+	// Username and Password log in to WebDAV, or are the access and secret
+	// keys for S3. ${VAR} references are expanded from the environment so
+	// secrets need not be stored in the config.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Region and Endpoint locate the bucket; the endpoint is only needed
+	// for S3-compatible services other than AWS
+	Region   string `yaml:"region"`
+	Endpoint string `yaml:"endpoint"`
+	// Passphrase or IdentityFile, a file of random bytes, encrypt snippets
+	// and placeholder memory before they leave this machine. ${VAR} in
+	// either is expanded from the environment.
+	Passphrase   string `yaml:"passphrase"`
+	IdentityFile string `yaml:"identity_file" mapstructure:"identity_file"`
+}
+
+// Source is a location the pages archive can be downloaded from. Sources
+// are tried in order of priority, lowest first, until one succeeds.
+type Source struct {
+	Name string `yaml:"name"`
+	// URL of a zip archive with a pages directory, e.g. the official
+	// archive, a mirror of it, a GitHub branch archive or a file:// path.
+	// A git+ prefix clones a repository instead, with an optional #branch.
+	URL string `yaml:"url"`
+	// Checksum is the URL of a sha256sum file for the archive, if any
+	Checksum string `yaml:"checksum"`
+	Priority int    `yaml:"priority"`
+	// Pages is a URL template for single pages with {platform} and {name}
+	// placeholders, used to repair individual pages without a full download
+	Pages string `yaml:"pages"`
+	// Headers are sent with every request; ${VAR} references are expanded
+	// from the environment so tokens need not be stored in the config
+	Headers map[string]string `yaml:"headers"`
+	// Trusted lets commands from this source's pages run without asking
+	// first. The official archive is always trusted.
+	Trusted bool `yaml:"trusted"`
+	// Signature is the URL of a detached minisign signature of the
+	// archive, checked against PublicKey, the minisign public key
+	Signature string `yaml:"signature"`
+	PublicKey string `yaml:"public_key" mapstructure:"public_key"`
+}
+
+// officialURL is the official tldr pages archive
+const officialURL = "https://tldr.sh/assets/tldr.zip"
+
+// IsOfficial reports whether url is the official tldr pages archive
+func IsOfficial(url string) bool {
+	return url == officialURL
+}
+
+// IsTrusted reports whether commands from the source's pages may run
+// without a per-page confirmation
+func (s Source) IsTrusted() bool {
+	return s.Trusted || IsOfficial(s.URL)
 }
 
 // Keymap represents keyboard shortcuts configuration
@@ -32,7 +206,6 @@ type Keymap struct {
 func DefaultConfig() *Config {
 	return &Config{
 		Theme:              "dark",
-		Platforms:          []string{"common", "linux"},
 		ConfirmDestructive: true,
 		Clipboard:          true,
 		Pager:              "less -R",
@@ -41,37 +214,175 @@ func DefaultConfig() *Config {
 			Copy:  "y",
 			Paste: "p",
 		},
-		CacheTTLHours: 72,
-		CacheDir:      getDefaultCacheDir(),
-		DevMode:       false,
+		CacheTTLHours:    72,
+		OutputHistory:    10,
+		SearchDebounceMs: 80,
+		PresentTypingMs:  40,
+		CacheDir:         getDefaultCacheDir(),
+		DevMode:          false,
+		SecretsBackend:   "none",
+		Sources:          DefaultSources(),
+		Sync:             Sync{Backend: "none"},
+	}
+}
+
+// CacheTTL returns how long the pages cache stays fresh; zero or less means
+// it never expires
+func (c *Config) CacheTTL() time.Duration {
+	return time.Duration(c.CacheTTLHours) * time.Hour
+}
+
+// SearchDebounce returns how long the TUI waits for typing to pause before
+// searching
+func (c *Config) SearchDebounce() time.Duration {
+	return time.Duration(c.SearchDebounceMs) * time.Millisecond
+}
+
+// envName matches the names environment variables can have
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseEnv splits an environment variable set as NAME=value
+func ParseEnv(entry string) (name, value string, err error) {
+	name, value, ok := strings.Cut(entry, "=")
+	if !ok || !envName.MatchString(name) {
+		return "", "", fmt.Errorf("invalid environment variable %q, expected NAME=value", entry)
+	}
+	return name, value, nil
+}
+
+// Env returns the environment variables set for the commands of page, as
+// NAME=value
+func (c *Config) Env(page string) []string {
+	return c.PageEnv[strings.ToLower(page)]
+}
+
+// PresentTyping returns how long presentation mode takes to type each
+// character of a command, 0 to show it whole
+func (c *Config) PresentTyping() time.Duration {
+	return time.Duration(c.PresentTypingMs) * time.Millisecond
+}
+
+// ExecTimeout returns how long a command may run, 0 for no limit
+func (c *Config) ExecTimeout() time.Duration {
+	timeout, err := time.ParseDuration(c.Exec.Timeout)
+	if err != nil || timeout < 0 {
+		return 0
+	}
+	return timeout
+}
+
+// Placeholder memory policies
+const (
+	// MemoryRemember fills in the last value used without asking for it
+	MemoryRemember = "remember"
+	// MemoryAsk asks for the value every time, even when a command is
+	// recalled, offering the last value used
+	MemoryAsk = "ask"
+	// MemoryForget never stores the value
+	MemoryForget = "forget"
+)
+
+// MemoryPolicies are the placeholder memory policies, in the order the TUI
+// cycles through them
+var MemoryPolicies = []string{MemoryRemember, MemoryAsk, MemoryForget}
+
+// MemoryPolicy returns how the values of a placeholder with name and typ
+// are remembered, or "" if the config doesn't say: stored and offered as
+// the default when asked for
+func (c *Config) MemoryPolicy(name, typ string) string {
+	// Viper lowercases map keys
+	if policy, ok := c.PlaceholderMemory[strings.ToLower(name)]; ok {
+		return policy
+	}
+	return c.PlaceholderMemory[strings.ToLower(typ)]
+}
+
+// DefaultSources returns the official tldr pages archive
+func DefaultSources() []Source {
+	return []Source{
+		{
+			Name:     "official",
+			URL:      officialURL,
+			Checksum: "https://tldr.sh/assets/tldr.sha256sums",
+			Pages:    "https://raw.githubusercontent.com/tldr-pages/tldr/main/pages/{platform}/{name}.md",
+		},
 	}
 }
 
-// Load loads the configuration from file or returns default
+// envPrefix starts the environment variables that override config fields,
+// e.g. TLDRPP_THEME or TLDRPP_KEYMAP_COPY for keymap.copy
+const envPrefix = "TLDRPP"
+
+// Load loads the configuration from file or returns default. Values are
+// taken, in order of precedence, from TLDRPP_* environment variables, the
+// config file and the defaults; command line flags are applied on top by
+// the caller.
 func Load() (*Config, error) {
 	configDir := getConfigDir()
 	configFile := filepath.Join(configDir, "config.yml")
 
-	// Set up viper
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(configDir)
+	// Set up a fresh viper instance so repeated loads don't share search
+	// paths or values
+	v := viper.New()
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(configDir)
+
+	// Every key can be set in the environment. Only keys with a default are
+	// looked up when unmarshalling, so each field needs one below.
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
 
 	// Set defaults
 	cfg := DefaultConfig()
-	viper.SetDefault("theme", cfg.Theme)
-	viper.SetDefault("platforms", cfg.Platforms)
-	viper.SetDefault("confirm_destructive", cfg.ConfirmDestructive)
-	viper.SetDefault("clipboard", cfg.Clipboard)
-	viper.SetDefault("pager", cfg.Pager)
-	viper.SetDefault("keymap.run", cfg.Keymap.Run)
-	viper.SetDefault("keymap.copy", cfg.Keymap.Copy)
-	viper.SetDefault("keymap.paste", cfg.Keymap.Paste)
-	viper.SetDefault("cache_ttl_hours", cfg.CacheTTLHours)
-	viper.SetDefault("cache_dir", cfg.CacheDir)
+	v.SetDefault("theme", cfg.Theme)
+	v.SetDefault("platforms", cfg.Platforms)
+	v.SetDefault("language", cfg.Language)
+	v.SetDefault("confirm_destructive", cfg.ConfirmDestructive)
+	v.SetDefault("clipboard", cfg.Clipboard)
+	v.SetDefault("pager", cfg.Pager)
+	v.SetDefault("keymap.run", cfg.Keymap.Run)
+	v.SetDefault("keymap.copy", cfg.Keymap.Copy)
+	v.SetDefault("keymap.paste", cfg.Keymap.Paste)
+	v.SetDefault("cache_ttl_hours", cfg.CacheTTLHours)
+	v.SetDefault("cache_dir", cfg.CacheDir)
+	v.SetDefault("pages_version", cfg.PagesVersion)
+	v.SetDefault("dev_mode", cfg.DevMode)
+	v.SetDefault("stats", cfg.Stats)
+	v.SetDefault("accessible", cfg.Accessible)
+	v.SetDefault("restore_session", cfg.RestoreSession)
+	v.SetDefault("output_history", cfg.OutputHistory)
+	v.SetDefault("search_debounce_ms", cfg.SearchDebounceMs)
+	v.SetDefault("present_typing_ms", cfg.PresentTypingMs)
+	v.SetDefault("kubernetes_suggestions", cfg.KubernetesSuggestions)
+	v.SetDefault("placeholder_memory", cfg.PlaceholderMemory)
+	v.SetDefault("secrets_backend", cfg.SecretsBackend)
+	v.SetDefault("sources", cfg.Sources)
+	v.SetDefault("require_signed_sources", cfg.RequireSignedSources)
+	v.SetDefault("aliases", cfg.Aliases)
+	v.SetDefault("page_env", cfg.PageEnv)
+	v.SetDefault("pack_index", cfg.PackIndex)
+	v.SetDefault("sync.backend", cfg.Sync.Backend)
+	v.SetDefault("sync.url", cfg.Sync.URL)
+	v.SetDefault("sync.username", cfg.Sync.Username)
+	v.SetDefault("sync.password", cfg.Sync.Password)
+	v.SetDefault("sync.region", cfg.Sync.Region)
+	v.SetDefault("sync.endpoint", cfg.Sync.Endpoint)
+	v.SetDefault("sync.passphrase", cfg.Sync.Passphrase)
+	v.SetDefault("sync.identity_file", cfg.Sync.IdentityFile)
+	v.SetDefault("network.proxy", cfg.Network.Proxy)
+	v.SetDefault("network.ca_file", cfg.Network.CAFile)
+	v.SetDefault("network.client_cert", cfg.Network.ClientCert)
+	v.SetDefault("network.client_key", cfg.Network.ClientKey)
+	v.SetDefault("exec.timeout", cfg.Exec.Timeout)
+	v.SetDefault("exec.nice", cfg.Exec.Nice)
+	v.SetDefault("exec.cpu_seconds", cfg.Exec.CPUSeconds)
+	v.SetDefault("exec.shell", cfg.Exec.Shell)
+	v.SetDefault("exec.record", cfg.Exec.Record)
 
 	// Try to read config file
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			// Config file not found, create default
 			if err := createDefaultConfig(configFile); err != nil {
@@ -82,10 +393,14 @@ func Load() (*Config, error) {
 		}
 	}
 
-	// Unmarshal into struct
-	if err := viper.Unmarshal(cfg); err != nil {
+	// Unmarshal into an empty struct: decoding into the defaults would merge
+	// configured lists such as platforms and sources into the default ones
+	// instead of replacing them. Unset keys still get viper's defaults.
+	loaded := &Config{}
+	if err := v.Unmarshal(loaded); err != nil {
 		return cfg, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	cfg = loaded
 
 	// Ensure cache directory exists
 	if err := os.MkdirAll(cfg.CacheDir, 0755); err != nil {
@@ -97,41 +412,263 @@ func Load() (*Config, error) {
 
 // Save saves the configuration to file
 func (c *Config) Save() error {
-	configDir := getConfigDir()
-	configFile := filepath.Join(configDir, "config.yml")
+	return c.saveTo(filepath.Join(getConfigDir(), "config.yml"))
+}
 
+// saveTo writes the configuration to configFile
+func (c *Config) saveTo(configFile string) error {
 	// Ensure config directory exists
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	// Set viper values
-	viper.Set("theme", c.Theme)
-	viper.Set("platforms", c.Platforms)
-	viper.Set("confirm_destructive", c.ConfirmDestructive)
-	viper.Set("clipboard", c.Clipboard)
-	viper.Set("pager", c.Pager)
-	viper.Set("keymap.run", c.Keymap.Run)
-	viper.Set("keymap.copy", c.Keymap.Copy)
-	viper.Set("keymap.paste", c.Keymap.Paste)
-	viper.Set("cache_ttl_hours", c.CacheTTLHours)
-	viper.Set("cache_dir", c.CacheDir)
+	// Set values on a separate viper instance: Set on the global one would
+	// override the config file in every later Load
+	v := viper.New()
+	v.Set("theme", c.Theme)
+	v.Set("platforms", c.Platforms)
+	v.Set("language", c.Language)
+	v.Set("confirm_destructive", c.ConfirmDestructive)
+	v.Set("clipboard", c.Clipboard)
+	v.Set("pager", c.Pager)
+	v.Set("keymap.run", c.Keymap.Run)
+	v.Set("keymap.copy", c.Keymap.Copy)
+	v.Set("keymap.paste", c.Keymap.Paste)
+	v.Set("cache_ttl_hours", c.CacheTTLHours)
+	v.Set("cache_dir", c.CacheDir)
+	v.Set("pages_version", c.PagesVersion)
+	v.Set("stats", c.Stats)
+	v.Set("accessible", c.Accessible)
+	v.Set("restore_session", c.RestoreSession)
+	v.Set("output_history", c.OutputHistory)
+	v.Set("search_debounce_ms", c.SearchDebounceMs)
+	v.Set("present_typing_ms", c.PresentTypingMs)
+	v.Set("kubernetes_suggestions", c.KubernetesSuggestions)
+	v.Set("placeholder_memory", c.PlaceholderMemory)
+	v.Set("secrets_backend", c.SecretsBackend)
+	v.Set("sources", c.Sources)
+	v.Set("require_signed_sources", c.RequireSignedSources)
+	v.Set("aliases", c.Aliases)
+	v.Set("page_env", c.PageEnv)
+	v.Set("pack_index", c.PackIndex)
+	v.Set("sync.backend", c.Sync.Backend)
+	v.Set("sync.url", c.Sync.URL)
+	v.Set("sync.username", c.Sync.Username)
+	v.Set("sync.password", c.Sync.Password)
+	v.Set("sync.region", c.Sync.Region)
+	v.Set("sync.endpoint", c.Sync.Endpoint)
+	v.Set("sync.passphrase", c.Sync.Passphrase)
+	v.Set("sync.identity_file", c.Sync.IdentityFile)
+	v.Set("network.proxy", c.Network.Proxy)
+	v.Set("network.ca_file", c.Network.CAFile)
+	v.Set("network.client_cert", c.Network.ClientCert)
+	v.Set("network.client_key", c.Network.ClientKey)
+	v.Set("exec.timeout", c.Exec.Timeout)
+	v.Set("exec.nice", c.Exec.Nice)
+	v.Set("exec.cpu_seconds", c.Exec.CPUSeconds)
+	v.Set("exec.shell", c.Exec.Shell)
+	v.Set("exec.record", c.Exec.Record)
+
+	return v.WriteConfigAs(configFile)
+}
+
+// homeEnv names the environment variable that moves everything tldr++
+// keeps under one directory, like --home
+const homeEnv = envPrefix + "_HOME"
+
+// home is the directory set with SetHome
+var home string
+
+// SetHome keeps the config, the pages cache and the data of tldr++ in the
+// config, cache and data directories of dir instead of the platform's
+// directories, e.g. to try something out or to run end-to-end tests
+// without touching the user's own. An empty dir leaves it to $TLDRPP_HOME.
+func SetHome(dir string) error {
+	if dir == "" {
+		home = ""
+		return nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid home directory: %w", err)
+	}
+	home = abs
+	return nil
+}
+
+// Home returns the directory set with SetHome or $TLDRPP_HOME, or "" if
+// tldr++ uses the platform's directories
+func Home() string {
+	if home != "" {
+		return home
+	}
+	if dir := os.Getenv(homeEnv); dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			return abs
+		}
+	}
+	return ""
+}
 
-	return viper.WriteConfigAs(configFile)
+// ExpandHome replaces a leading ~ or ~/ in path with the user's home
+// directory, as a shell would, and ~\ on Windows too
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
+	}
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(dir, strings.TrimPrefix(path, "~"))
 }
 
-// getConfigDir returns the configuration directory
-func getConfigDir() string {
-	if homeDir, err := os.UserHomeDir(); err == nil {
-		return filepath.Join(homeDir, ".config", "tldrpp")
+// AbbreviateHome replaces the user's home directory at the start of path
+// with ~, for showing paths shortly
+func AbbreviateHome(path string) string {
+	dir, err := os.UserHomeDir()
+	if err != nil || dir == "" {
+		return path
+	}
+	if path == dir {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator)); ok {
+		return "~" + string(filepath.Separator) + rest
+	}
+	return path
+}
+
+// getConfigDir returns the configuration directory: $XDG_CONFIG_HOME/tldrpp
+// or ~/.config/tldrpp on Unix, the platform's equivalent elsewhere, or the
+// config directory of Home. It is a variable so tests can point it at a
+// temporary directory.
+var getConfigDir = func() string {
+	if home := Home(); home != "" {
+		return filepath.Join(home, "config")
+	}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(configDir, "tldrpp")
 	}
 	return filepath.Join(".", ".config", "tldrpp")
 }
 
-// getDefaultCacheDir returns the default cache directory
+// SnippetsDir returns the directory saved snippets are kept in
+func SnippetsDir() string {
+	return filepath.Join(getConfigDir(), "snippets")
+}
+
+// CustomPagesDir returns the directory of the user's own pages, kept as
+// <platform>/<name>.md
+func CustomPagesDir() string {
+	return filepath.Join(getConfigDir(), "pages")
+}
+
+// WorkflowsDir returns the directory of user-written workflows, which sit
+// with the user's own pages
+func WorkflowsDir() string {
+	return filepath.Join(CustomPagesDir(), "workflows")
+}
+
+// ProvidersDir returns the directory of the user's suggestion provider
+// programs, which offer values for placeholders
+func ProvidersDir() string {
+	return filepath.Join(getConfigDir(), "providers")
+}
+
+// Editor returns the command line of the user's editor from $VISUAL or
+// $EDITOR, falling back to a common one
+func Editor() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(name)); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// EditorCommand returns the arguments running the user's editor, see
+// Editor, on files
+func EditorCommand(files ...string) ([]string, error) {
+	editor := Editor()
+	args, err := shellwords.Split(editor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid editor: %w", err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("invalid editor %q", editor)
+	}
+	return append(args, files...), nil
+}
+
+// StatsFile returns the path of the local usage stats
+func StatsFile() string {
+	return filepath.Join(DataDir(), "stats.json")
+}
+
+// SessionFile returns the path of the TUI state kept between launches
+func SessionFile() string {
+	return filepath.Join(DataDir(), "session.json")
+}
+
+// PacksDir returns the directory page packs are installed in
+func PacksDir() string {
+	return filepath.Join(DataDir(), "packs")
+}
+
+// SyncStateFile returns the path of what the last sync left on both sides
+func SyncStateFile() string {
+	return filepath.Join(DataDir(), "sync.json")
+}
+
+// SyncRepoDir returns the clone the git sync backend works in
+func SyncRepoDir() string {
+	return filepath.Join(DataDir(), "sync-repo")
+}
+
+// DataDir returns the directory history and logs are kept in
+func DataDir() string {
+	if home := Home(); home != "" {
+		return filepath.Join(home, "data")
+	}
+	if dataDir, err := dataHome(); err == nil {
+		return filepath.Join(dataDir, "tldrpp")
+	}
+	return filepath.Join(".", ".local", "share", "tldrpp")
+}
+
+// dataHome returns the base directory for user data: $XDG_DATA_HOME or
+// ~/.local/share on Unix. Go has no os.UserDataDir, so macOS and Windows use
+// the same directories as their config and local app data.
+func dataHome() (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		return os.UserCacheDir() // %LocalAppData%
+	case "darwin", "ios":
+		return os.UserConfigDir() // ~/Library/Application Support
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".local", "share"), nil
+}
+
+// getDefaultCacheDir returns the default cache directory:
+// $XDG_CACHE_HOME/tldrpp/pages or ~/.cache/tldrpp/pages on Unix, the
+// platform's equivalent elsewhere, or the cache directory of Home
 func getDefaultCacheDir() string {
-	if homeDir, err := os.UserHomeDir(); err == nil {
-		return filepath.Join(homeDir, ".cache", "tldrpp", "pages")
+	if home := Home(); home != "" {
+		return filepath.Join(home, "cache")
+	}
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(cacheDir, "tldrpp", "pages")
 	}
 	return filepath.Join(".", ".cache", "tldrpp", "pages")
 }
@@ -144,5 +681,5 @@ func createDefaultConfig(configFile string) error {
 	}
 
 	cfg := DefaultConfig()
-	return cfg.Save()
-}
\ No newline at end of file
+	return cfg.saveTo(configFile)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -13,8 +14,8 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected theme 'dark', got '%s'", cfg.Theme)
 	}
 
-	if len(cfg.Platforms) != 2 || cfg.Platforms[0] != "common" || cfg.Platforms[1] != "linux" {
-		t.Errorf("Expected platforms ['common', 'linux'], got %v", cfg.Platforms)
+	if len(cfg.Platforms) != 0 {
+		t.Errorf("Expected no platform filter by default, got %v", cfg.Platforms)
 	}
 
 	if !cfg.ConfirmDestructive {
@@ -48,10 +49,27 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.DevMode {
 		t.Error("Expected DevMode to be false")
 	}
+
+	if cfg.OutputHistory != 10 {
+		t.Errorf("Expected 10 outputs kept, got %d", cfg.OutputHistory)
+	}
+
+	if len(cfg.Sources) != 1 || cfg.Sources[0].Name != "official" {
+		t.Errorf("Expected the official source, got %v", cfg.Sources)
+	}
 }
 
 func TestLoadConfig(t *testing.T) {
 	// Test loading config when file doesn't exist
+	configDir := filepath.Join(t.TempDir(), ".config", "tldrpp")
+	originalGetConfigDir := getConfigDir
+	getConfigDir = func() string {
+		return configDir
+	}
+	defer func() {
+		getConfigDir = originalGetConfigDir
+	}()
+
 	cfg, err := Load()
 	if err != nil {
 		t.Fatalf("Load failed: %v", err)
@@ -62,8 +80,8 @@ func TestLoadConfig(t *testing.T) {
 		t.Errorf("Expected theme 'dark', got '%s'", cfg.Theme)
 	}
 
-	if len(cfg.Platforms) != 2 {
-		t.Errorf("Expected 2 platforms, got %d", len(cfg.Platforms))
+	if len(cfg.Platforms) != 0 {
+		t.Errorf("Expected no platforms, got %d", len(cfg.Platforms))
 	}
 }
 
@@ -86,6 +104,11 @@ func TestSaveAndLoadConfig(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.Theme = "light"
 	cfg.Platforms = []string{"linux", "osx"}
+	cfg.CacheTTLHours = 12
+	cfg.PlaceholderMemory = map[string]string{"host": MemoryAsk, "port": MemoryRemember}
+	cfg.Sources = []Source{
+		{Name: "mirror", URL: "https://mirror.example.com/tldr.zip", Priority: 1, Headers: map[string]string{"Authorization": "Bearer ${TOKEN}"}},
+	}
 
 	err := cfg.Save()
 	if err != nil {
@@ -111,6 +134,124 @@ func TestSaveAndLoadConfig(t *testing.T) {
 	if len(loadedCfg.Platforms) != 2 || loadedCfg.Platforms[0] != "linux" || loadedCfg.Platforms[1] != "osx" {
 		t.Errorf("Expected platforms ['linux', 'osx'], got %v", loadedCfg.Platforms)
 	}
+
+	if loadedCfg.CacheTTLHours != 12 {
+		t.Errorf("Expected cache TTL 12 hours, got %d", loadedCfg.CacheTTLHours)
+	}
+
+	if len(loadedCfg.PlaceholderMemory) != 2 || loadedCfg.PlaceholderMemory["host"] != MemoryAsk {
+		t.Errorf("Expected placeholder memory to round-trip, got %v", loadedCfg.PlaceholderMemory)
+	}
+
+	// viper lowercases map keys, which is harmless for HTTP header names
+	if len(loadedCfg.Sources) != 1 || loadedCfg.Sources[0].URL != "https://mirror.example.com/tldr.zip" ||
+		loadedCfg.Sources[0].Headers["authorization"] != "Bearer ${TOKEN}" {
+		t.Errorf("Expected mirror source to round-trip, got %v", loadedCfg.Sources)
+	}
+}
+
+func TestLoadReplacesDefaultLists(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".config", "tldrpp")
+	originalGetConfigDir := getConfigDir
+	getConfigDir = func() string {
+		return configDir
+	}
+	defer func() {
+		getConfigDir = originalGetConfigDir
+	}()
+
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	content := "platforms: [osx]\nsources:\n  - name: local\n    url: file:///tmp/tldr.zip\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(cfg.Platforms) != 1 || cfg.Platforms[0] != "osx" {
+		t.Errorf("Expected platforms ['osx'], got %v", cfg.Platforms)
+	}
+	if len(cfg.Sources) != 1 || cfg.Sources[0].Checksum != "" || cfg.Sources[0].Pages != "" {
+		t.Errorf("Expected only the local source without defaults merged in, got %+v", cfg.Sources)
+	}
+	if cfg.Theme != "dark" {
+		t.Errorf("Expected default theme 'dark', got '%s'", cfg.Theme)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".config", "tldrpp")
+	originalGetConfigDir := getConfigDir
+	getConfigDir = func() string {
+		return configDir
+	}
+	defer func() {
+		getConfigDir = originalGetConfigDir
+	}()
+
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	content := "theme: solarized\npager: more\ncache_ttl_hours: 24\nkeymap:\n  copy: c\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	cacheDir := filepath.Join(t.TempDir(), "pages")
+	t.Setenv("TLDRPP_THEME", "light")
+	t.Setenv("TLDRPP_PLATFORMS", "osx,common")
+	t.Setenv("TLDRPP_CACHE_DIR", cacheDir)
+	t.Setenv("TLDRPP_CACHE_TTL_HOURS", "6")
+	t.Setenv("TLDRPP_CONFIRM_DESTRUCTIVE", "false")
+	t.Setenv("TLDRPP_KEYMAP_PASTE", "P")
+	t.Setenv("TLDRPP_DEV_MODE", "true")
+	t.Setenv("TLDRPP_ACCESSIBLE", "true")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		field    string
+		got      interface{}
+		expected interface{}
+	}{
+		// The environment wins over the file and the defaults
+		{"theme", cfg.Theme, "light"},
+		{"platforms", strings.Join(cfg.Platforms, ","), "osx,common"},
+		{"cache_dir", cfg.CacheDir, cacheDir},
+		{"cache_ttl_hours", cfg.CacheTTLHours, 6},
+		{"confirm_destructive", cfg.ConfirmDestructive, false},
+		{"keymap.paste", cfg.Keymap.Paste, "P"},
+		{"dev_mode", cfg.DevMode, true},
+		{"accessible", cfg.Accessible, true},
+		// The file wins over the defaults
+		{"pager", cfg.Pager, "more"},
+		{"keymap.copy", cfg.Keymap.Copy, "c"},
+		// Defaults fill in the rest
+		{"keymap.run", cfg.Keymap.Run, "ctrl+enter"},
+		{"clipboard", cfg.Clipboard, true},
+		{"output_history", cfg.OutputHistory, 10},
+		{"search_debounce_ms", cfg.SearchDebounceMs, 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, tt.got)
+			}
+		})
+	}
+
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Errorf("Expected the cache directory from the environment to be created: %v", err)
+	}
 }
 
 func TestGetConfigDir(t *testing.T) {
@@ -151,4 +292,86 @@ func TestCreateDefaultConfig(t *testing.T) {
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
 		t.Fatal("Default config file was not created")
 	}
-}
\ No newline at end of file
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", `code --wait --profile "My Profile"`)
+	args, err := EditorCommand("page.md")
+	if err != nil {
+		t.Fatalf("EditorCommand failed: %v", err)
+	}
+	want := []string{"code", "--wait", "--profile", "My Profile", "page.md"}
+	if strings.Join(args, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got %q", want, args)
+	}
+
+	t.Setenv("VISUAL", `vim "unterminated`)
+	if _, err := EditorCommand("page.md"); err == nil {
+		t.Error("Expected an error for an editor with an unterminated quote")
+	}
+}
+
+func TestShellArgs(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{"", []string{"sh", "-c"}},
+		{"bash -o pipefail -c", []string{"bash", "-o", "pipefail", "-c"}},
+		{`'/opt/my shell/bin/sh' -c`, []string{"/opt/my shell/bin/sh", "-c"}},
+	}
+	for _, tt := range tests {
+		args, err := Exec{Shell: tt.shell}.ShellArgs()
+		if err != nil {
+			t.Fatalf("ShellArgs(%q) failed: %v", tt.shell, err)
+		}
+		if strings.Join(args, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("ShellArgs(%q): expected %q, got %q", tt.shell, tt.want, args)
+		}
+	}
+
+	if _, err := (Exec{Shell: "bash -c; rm"}).ShellArgs(); err == nil {
+		t.Error("Expected an error for a shell with an operator")
+	}
+}
+
+func TestParseEnv(t *testing.T) {
+	name, value, err := ParseEnv("OPTS=--a=1 --b")
+	if err != nil || name != "OPTS" || value != "--a=1 --b" {
+		t.Errorf("Expected OPTS and %q, got %q, %q, %v", "--a=1 --b", name, value, err)
+	}
+	for _, entry := range []string{"NOVALUE", "=x", "1ST=x", "A-B=x"} {
+		if _, _, err := ParseEnv(entry); err == nil {
+			t.Errorf("Expected an error for %q", entry)
+		}
+	}
+
+	cfg := &Config{PageEnv: map[string][]string{"kubectl": {"KUBECONFIG=staging"}}}
+	if env := cfg.Env("Kubectl"); len(env) != 1 || env[0] != "KUBECONFIG=staging" {
+		t.Errorf("Expected the kubectl environment, got %q", env)
+	}
+}
+
+func TestHomePaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	project := filepath.Join(home, "src", "project")
+	short := "~" + string(filepath.Separator) + filepath.Join("src", "project")
+	if got := AbbreviateHome(project); got != short {
+		t.Errorf("Expected %q, got %q", short, got)
+	}
+	if got := ExpandHome(short); got != project {
+		t.Errorf("Expected %q, got %q", project, got)
+	}
+	if got := ExpandHome("~/src/project"); got != project {
+		t.Errorf("Expected %q, got %q", project, got)
+	}
+	if got := AbbreviateHome(home + "-other"); got != home+"-other" {
+		t.Errorf("Expected a sibling of home unchanged, got %q", got)
+	}
+	if got := ExpandHome("~user/x"); got != "~user/x" {
+		t.Errorf("Expected another user's home unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,526 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/shellwords"
+	"gopkg.in/yaml.v3"
+)
+
+// kind is the type of a setting's value
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindInt
+	kindList
+	kindDuration
+	// kindCommand is a command line, split into arguments by shellwords
+	kindCommand
+)
+
+// setting describes a config key that can be read and set from the command
+// line
+type setting struct {
+	key  string
+	kind kind
+	// allowed lists the valid values, if they are limited
+	allowed []string
+	get     func(c *Config) interface{}
+}
+
+// knownPlatforms are the platforms tldr pages are written for
+var knownPlatforms = []string{"android", "common", "freebsd", "linux", "netbsd", "openbsd", "osx", "sunos", "windows"}
+
+// settings lists every key but sources, aliases and page_env, which are
+// structured and only edited in the file
+var settings = []setting{
+	{key: "theme", kind: kindString, allowed: []string{"dark", "light", "solarized"}, get: func(c *Config) interface{} { return c.Theme }},
+	{key: "platforms", kind: kindList, allowed: knownPlatforms, get: func(c *Config) interface{} { return c.Platforms }},
+	{key: "language", kind: kindString, get: func(c *Config) interface{} { return c.Language }},
+	{key: "confirm_destructive", kind: kindBool, get: func(c *Config) interface{} { return c.ConfirmDestructive }},
+	{key: "clipboard", kind: kindBool, get: func(c *Config) interface{} { return c.Clipboard }},
+	{key: "pager", kind: kindCommand, get: func(c *Config) interface{} { return c.Pager }},
+	{key: "keymap.run", kind: kindString, get: func(c *Config) interface{} { return c.Keymap.Run }},
+	{key: "keymap.copy", kind: kindString, get: func(c *Config) interface{} { return c.Keymap.Copy }},
+	{key: "keymap.paste", kind: kindString, get: func(c *Config) interface{} { return c.Keymap.Paste }},
+	{key: "cache_ttl_hours", kind: kindInt, get: func(c *Config) interface{} { return c.CacheTTLHours }},
+	{key: "cache_dir", kind: kindString, get: func(c *Config) interface{} { return c.CacheDir }},
+	{key: "pages_version", kind: kindString, get: func(c *Config) interface{} { return c.PagesVersion }},
+	{key: "dev_mode", kind: kindBool, get: func(c *Config) interface{} { return c.DevMode }},
+	{key: "stats", kind: kindBool, get: func(c *Config) interface{} { return c.Stats }},
+	{key: "accessible", kind: kindBool, get: func(c *Config) interface{} { return c.Accessible }},
+	{key: "restore_session", kind: kindBool, get: func(c *Config) interface{} { return c.RestoreSession }},
+	{key: "output_history", kind: kindInt, get: func(c *Config) interface{} { return c.OutputHistory }},
+	{key: "search_debounce_ms", kind: kindInt, get: func(c *Config) interface{} { return c.SearchDebounceMs }},
+	{key: "present_typing_ms", kind: kindInt, get: func(c *Config) interface{} { return c.PresentTypingMs }},
+	{key: "kubernetes_suggestions", kind: kindBool, get: func(c *Config) interface{} { return c.KubernetesSuggestions }},
+	{key: "secrets_backend", kind: kindString, allowed: []string{"none", "env", "pass", "secret-tool"}, get: func(c *Config) interface{} { return c.SecretsBackend }},
+	{key: "require_signed_sources", kind: kindBool, get: func(c *Config) interface{} { return c.RequireSignedSources }},
+	{key: "pack_index", kind: kindString, get: func(c *Config) interface{} { return c.PackIndex }},
+	{key: "sync.backend", kind: kindString, allowed: []string{"none", "git", "webdav", "s3"}, get: func(c *Config) interface{} { return c.Sync.Backend }},
+	{key: "sync.url", kind: kindString, get: func(c *Config) interface{} { return c.Sync.URL }},
+	{key: "sync.username", kind: kindString, get: func(c *Config) interface{} { return c.Sync.Username }},
+	{key: "sync.password", kind: kindString, get: func(c *Config) interface{} { return c.Sync.Password }},
+	{key: "sync.region", kind: kindString, get: func(c *Config) interface{} { return c.Sync.Region }},
+	{key: "sync.endpoint", kind: kindString, get: func(c *Config) interface{} { return c.Sync.Endpoint }},
+	{key: "sync.passphrase", kind: kindString, get: func(c *Config) interface{} { return c.Sync.Passphrase }},
+	{key: "sync.identity_file", kind: kindString, get: func(c *Config) interface{} { return c.Sync.IdentityFile }},
+	{key: "network.proxy", kind: kindString, get: func(c *Config) interface{} { return c.Network.Proxy }},
+	{key: "network.ca_file", kind: kindString, get: func(c *Config) interface{} { return c.Network.CAFile }},
+	{key: "network.client_cert", kind: kindString, get: func(c *Config) interface{} { return c.Network.ClientCert }},
+	{key: "network.client_key", kind: kindString, get: func(c *Config) interface{} { return c.Network.ClientKey }},
+	{key: "exec.timeout", kind: kindDuration, get: func(c *Config) interface{} { return c.Exec.Timeout }},
+	{key: "exec.nice", kind: kindInt, get: func(c *Config) interface{} { return c.Exec.Nice }},
+	{key: "exec.cpu_seconds", kind: kindInt, get: func(c *Config) interface{} { return c.Exec.CPUSeconds }},
+	{key: "exec.shell", kind: kindCommand, get: func(c *Config) interface{} { return c.Exec.Shell }},
+	{key: "exec.record", kind: kindBool, get: func(c *Config) interface{} { return c.Exec.Record }},
+}
+
+// Keys returns the keys that Get and Set accept
+func Keys() []string {
+	keys := make([]string, len(settings))
+	for i, s := range settings {
+		keys[i] = s.key
+	}
+	return keys
+}
+
+// File returns the path of the config file
+func File() string {
+	return filepath.Join(getConfigDir(), "config.yml")
+}
+
+// Get returns the value of key in cfg formatted for display; lists are
+// comma-separated
+func Get(cfg *Config, key string) (string, error) {
+	if name, ok := strings.CutPrefix(key, memoryKey+"."); ok {
+		return cfg.PlaceholderMemory[strings.ToLower(name)], nil
+	}
+	if key == "sources" || key == "aliases" || key == envKey || key == memoryKey {
+		var value interface{} = cfg.Sources
+		switch key {
+		case "aliases":
+			value = cfg.Aliases
+		case envKey:
+			value = cfg.PageEnv
+		case memoryKey:
+			value = cfg.PlaceholderMemory
+		}
+		data, err := yaml.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to format %s: %w", key, err)
+		}
+		return strings.TrimSuffix(string(data), "\n"), nil
+	}
+
+	s, err := lookup(key)
+	if err != nil {
+		return "", err
+	}
+	switch value := s.get(cfg).(type) {
+	case []string:
+		return strings.Join(value, ","), nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+// Set validates value for key and writes it to the config file, leaving the
+// other keys in the file as they are. Lists are given comma-separated; an
+// empty value clears a list, or a placeholder's memory policy.
+func Set(key, value string) error {
+	if key == "sources" || key == "aliases" || key == envKey || key == memoryKey {
+		return fmt.Errorf("%s can't be set from the command line, use 'tldrpp config edit'", key)
+	}
+	var parsed interface{}
+	if strings.HasPrefix(key, memoryKey+".") {
+		// Viper lowercases map keys when loading
+		key = strings.ToLower(key)
+		if value != "" {
+			if err := checkMemoryPolicy(key, value); err != nil {
+				return err
+			}
+		}
+		parsed = value
+	} else {
+		s, err := lookup(key)
+		if err != nil {
+			return err
+		}
+		if parsed, err = s.parse(value); err != nil {
+			return err
+		}
+	}
+
+	configFile := File()
+	values, err := readFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	// Nested keys such as keymap.copy live in a map of their own
+	parent, name, nested := strings.Cut(key, ".")
+	if !nested {
+		values[key] = parsed
+	} else {
+		section, _ := values[parent].(map[string]interface{})
+		if section == nil {
+			section = make(map[string]interface{})
+		}
+		if parent == memoryKey && parsed == "" {
+			delete(section, name)
+		} else {
+			section[name] = parsed
+		}
+		values[parent] = section
+	}
+
+	return writeFile(configFile, values)
+}
+
+// Validate checks a config file and returns every problem found: keys that
+// don't exist and values of the wrong type or out of range
+func Validate(configFile string) ([]error, error) {
+	values, err := readFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []error
+	for _, key := range sortedKeys(values) {
+		value := values[key]
+		switch key {
+		case "keymap", "sync", "network", "exec":
+			section, ok := value.(map[string]interface{})
+			if !ok {
+				problems = append(problems, fmt.Errorf("%s: expected a map of settings", key))
+				continue
+			}
+			for _, name := range sortedKeys(section) {
+				problems = append(problems, validateValue(key+"."+name, section[name])...)
+			}
+		case "sources":
+			problems = append(problems, validateSources(value)...)
+		case "aliases":
+			problems = append(problems, validateAliases(value)...)
+		case envKey:
+			problems = append(problems, validatePageEnv(value)...)
+		case memoryKey:
+			problems = append(problems, validateMemory(value)...)
+		default:
+			problems = append(problems, validateValue(key, value)...)
+		}
+	}
+	return problems, nil
+}
+
+// validateValue checks a single key and its value from the file
+func validateValue(key string, value interface{}) []error {
+	s, err := lookup(key)
+	if err != nil {
+		return []error{err}
+	}
+
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		if s.kind != kindList {
+			return []error{fmt.Errorf("%s: expected a single value, got a list", key)}
+		}
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		text = strings.Join(items, ",")
+	case map[string]interface{}:
+		return []error{fmt.Errorf("%s: expected a value, got a map", key)}
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	if _, err := s.parse(text); err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+// validateAliases checks that aliases map names to page names
+func validateAliases(value interface{}) []error {
+	if value == nil {
+		return nil
+	}
+	aliases, ok := value.(map[string]interface{})
+	if !ok {
+		return []error{fmt.Errorf("aliases: expected a map of aliases to page names")}
+	}
+	var problems []error
+	for _, name := range sortedKeys(aliases) {
+		if page, ok := aliases[name].(string); !ok || page == "" {
+			problems = append(problems, fmt.Errorf("aliases.%s: expected a page name", name))
+		}
+	}
+	return problems
+}
+
+// envKey is the map of page names to the environment of their commands
+const envKey = "page_env"
+
+// validatePageEnv checks that page_env maps page names to lists of
+// NAME=value
+func validatePageEnv(value interface{}) []error {
+	if value == nil {
+		return nil
+	}
+	pages, ok := value.(map[string]interface{})
+	if !ok {
+		return []error{fmt.Errorf("%s: expected a map of page names to NAME=value lists", envKey)}
+	}
+	var problems []error
+	for _, page := range sortedKeys(pages) {
+		entries, ok := pages[page].([]interface{})
+		if !ok {
+			problems = append(problems, fmt.Errorf("%s.%s: expected a list of NAME=value", envKey, page))
+			continue
+		}
+		for _, entry := range entries {
+			if _, _, err := ParseEnv(fmt.Sprint(entry)); err != nil {
+				problems = append(problems, fmt.Errorf("%s.%s: %v", envKey, page, err))
+			}
+		}
+	}
+	return problems
+}
+
+// memoryKey is the map of placeholder names and types to memory policies
+const memoryKey = "placeholder_memory"
+
+// checkMemoryPolicy reports a placeholder memory policy that doesn't exist
+func checkMemoryPolicy(key, policy string) error {
+	for _, known := range MemoryPolicies {
+		if policy == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: invalid value %q, expected one of %s", key, policy, strings.Join(MemoryPolicies, ", "))
+}
+
+// validateMemory checks that placeholder_memory maps names and types to
+// policies
+func validateMemory(value interface{}) []error {
+	if value == nil {
+		return nil
+	}
+	policies, ok := value.(map[string]interface{})
+	if !ok {
+		return []error{fmt.Errorf("%s: expected a map of placeholder names and types to %s", memoryKey, strings.Join(MemoryPolicies, ", "))}
+	}
+	var problems []error
+	for _, name := range sortedKeys(policies) {
+		policy, _ := policies[name].(string)
+		if err := checkMemoryPolicy(memoryKey+"."+name, policy); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	return problems
+}
+
+// validateSources checks the sources list
+func validateSources(value interface{}) []error {
+	list, ok := value.([]interface{})
+	if !ok {
+		return []error{fmt.Errorf("sources: expected a list")}
+	}
+
+	var problems []error
+	for i, item := range list {
+		source, ok := item.(map[string]interface{})
+		if !ok {
+			problems = append(problems, fmt.Errorf("sources[%d]: expected a map with name, url and priority", i))
+			continue
+		}
+		for _, field := range sortedKeys(source) {
+			switch field {
+			case "name", "url", "checksum", "priority", "pages", "headers", "trusted", "signature", "public_key":
+			default:
+				problems = append(problems, fmt.Errorf("sources[%d]: unknown field %q%s", i, field,
+					suggest(field, []string{"name", "url", "checksum", "priority", "pages", "headers", "trusted", "signature", "public_key"})))
+			}
+		}
+
+		rawURL, _ := source["url"].(string)
+		if rawURL == "" {
+			problems = append(problems, fmt.Errorf("sources[%d]: url is required", i))
+		} else if u, err := url.Parse(strings.TrimPrefix(rawURL, "git+")); err != nil || u.Scheme == "" {
+			problems = append(problems, fmt.Errorf("sources[%d]: url %q is not a valid URL", i, rawURL))
+		}
+		if priority, ok := source["priority"]; ok {
+			if _, isInt := priority.(int); !isInt {
+				problems = append(problems, fmt.Errorf("sources[%d]: priority must be a number, got %v", i, priority))
+			}
+		}
+		if trusted, ok := source["trusted"]; ok {
+			if _, isBool := trusted.(bool); !isBool {
+				problems = append(problems, fmt.Errorf("sources[%d]: trusted must be true or false, got %v", i, trusted))
+			}
+		}
+	}
+	return problems
+}
+
+// parse converts a value given as text to the setting's type, checking it
+// is allowed
+func (s setting) parse(value string) (interface{}, error) {
+	switch s.kind {
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: expected true or false, got %q", s.key, value)
+		}
+		return b, nil
+	case kindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s: expected a whole number of 0 or more, got %q", s.key, value)
+		}
+		return n, nil
+	case kindDuration:
+		if value == "" || value == "0" {
+			return value, nil
+		}
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return nil, fmt.Errorf("%s: expected a duration such as 30s or 10m, got %q", s.key, value)
+		}
+		return value, nil
+	case kindCommand:
+		if _, err := shellwords.Split(value); err != nil {
+			return nil, fmt.Errorf("%s: %v", s.key, err)
+		}
+		return value, nil
+	case kindList:
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			if err := s.check(item); err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		if err := s.check(value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+// check reports a value that isn't one of the allowed ones
+func (s setting) check(value string) error {
+	if len(s.allowed) == 0 {
+		return nil
+	}
+	for _, allowed := range s.allowed {
+		if value == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: invalid value %q, expected one of %s", s.key, value, strings.Join(s.allowed, ", "))
+}
+
+// lookup finds the setting for key, suggesting the closest key if there is
+// none
+func lookup(key string) (setting, error) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, nil
+		}
+	}
+	return setting{}, fmt.Errorf("unknown key %q%s", key, suggest(key, append(Keys(), "sources", "aliases", envKey, memoryKey)))
+}
+
+// suggest returns a "did you mean" hint for the candidate closest to key, if
+// any is close enough to be a likely typo
+func suggest(key string, candidates []string) string {
+	best, bestDistance := "", len(key)/2+2
+	for _, candidate := range candidates {
+		if d := distance(key, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// distance returns the Levenshtein distance between a and b
+func distance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// readFile parses a config file into a map, keeping keys viper doesn't know
+func readFile(configFile string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", configFile, err)
+	}
+	return values, nil
+}
+
+// writeFile writes values to a config file as YAML
+func writeFile(configFile string, values map[string]interface{}) error {
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to format config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(configFile, data, 0644)
+}
+
+// sortedKeys returns the keys of a map in order, for stable output
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
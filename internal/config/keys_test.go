@@ -0,0 +1,224 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tempConfigDir points the config directory at a temporary directory and
+// returns the config file path in it
+func tempConfigDir(t *testing.T) string {
+	t.Helper()
+	configDir := filepath.Join(t.TempDir(), ".config", "tldrpp")
+	originalGetConfigDir := getConfigDir
+	getConfigDir = func() string {
+		return configDir
+	}
+	t.Cleanup(func() {
+		getConfigDir = originalGetConfigDir
+	})
+	return filepath.Join(configDir, "config.yml")
+}
+
+func TestGet(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Platforms = []string{"linux", "common"}
+	cfg.PlaceholderMemory = map[string]string{"host": MemoryAsk}
+
+	tests := []struct {
+		key      string
+		expected string
+		wantErr  bool
+	}{
+		{"theme", "dark", false},
+		{"platforms", "linux,common", false},
+		{"keymap.copy", "y", false},
+		{"cache_ttl_hours", "72", false},
+		{"confirm_destructive", "true", false},
+		{"placeholder_memory.host", "ask", false},
+		{"placeholder_memory.port", "", false},
+		{"colour", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, err := Get(cfg, tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, value)
+			}
+		})
+	}
+
+	if value, err := Get(cfg, "sources"); err != nil || !strings.Contains(value, "https://tldr.sh/assets/tldr.zip") {
+		t.Errorf("Expected sources as YAML, got %q (%v)", value, err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	configFile := tempConfigDir(t)
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(configFile, []byte("pager: more\nkeymap:\n  run: enter\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	for key, value := range map[string]string{
+		"theme":                       "light",
+		"platforms":                   "linux, common",
+		"keymap.copy":                 "c",
+		"cache_ttl_hours":             "12",
+		"clipboard":                   "false",
+		"placeholder_memory.Host":     "ask",
+		"placeholder_memory.password": "forget",
+		"sync.backend":                "webdav",
+		"exec.timeout":                "90s",
+		"exec.shell":                  `bash -o pipefail -c`,
+	} {
+		if err := Set(key, value); err != nil {
+			t.Fatalf("Set %s failed: %v", key, err)
+		}
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Theme != "light" || strings.Join(cfg.Platforms, ",") != "linux,common" || cfg.CacheTTLHours != 12 || cfg.Clipboard {
+		t.Errorf("Expected the set values to load, got %+v", cfg)
+	}
+	if cfg.Keymap.Copy != "c" || cfg.Keymap.Run != "enter" || cfg.Pager != "more" {
+		t.Errorf("Expected other keys in the file to be kept, got %+v", cfg.Keymap)
+	}
+	if cfg.Sync.Backend != "webdav" {
+		t.Errorf("Expected sync.backend webdav, got %q", cfg.Sync.Backend)
+	}
+	if cfg.ExecTimeout() != 90*time.Second {
+		t.Errorf("Expected exec.timeout 90s, got %s", cfg.ExecTimeout())
+	}
+	if cfg.Exec.Shell != "bash -o pipefail -c" {
+		t.Errorf("Expected exec.shell bash -o pipefail -c, got %q", cfg.Exec.Shell)
+	}
+	if cfg.MemoryPolicy("host", "text") != MemoryAsk || cfg.MemoryPolicy("pass", "password") != MemoryForget {
+		t.Errorf("Expected the set memory policies to load, got %v", cfg.PlaceholderMemory)
+	}
+
+	// An empty policy goes back to the default
+	if err := Set("placeholder_memory.host", ""); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if policy := cfg.MemoryPolicy("host", "text"); policy != "" {
+		t.Errorf("Expected no policy for host, got %q", policy)
+	}
+
+	invalid := []struct {
+		key   string
+		value string
+	}{
+		{"theme", "neon"},
+		{"platforms", "linux,beos"},
+		{"cache_ttl_hours", "-1"},
+		{"clipboard", "maybe"},
+		{"exec.timeout", "90"},
+		{"exec.timeout", "-1m"},
+		{"exec.shell", "bash -c 'x"},
+		{"pager", "less -R | cat"},
+		{"sources", "x"},
+		{"placeholder_memory.host", "always"},
+		{"them", "dark"},
+	}
+	for _, tt := range invalid {
+		if err := Set(tt.key, tt.value); err == nil {
+			t.Errorf("Expected an error setting %s to %q", tt.key, tt.value)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	configFile := tempConfigDir(t)
+
+	// The default config is valid
+	if err := createDefaultConfig(configFile); err != nil {
+		t.Fatalf("createDefaultConfig failed: %v", err)
+	}
+	problems, err := Validate(configFile)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected the default config to be valid, got %v", problems)
+	}
+
+	content := `them: dark
+platforms: [linux, beos]
+cache_ttl_hours: soon
+keymap:
+  cpy: c
+sources:
+  - name: mirror
+    ulr: https://example.com/tldr.zip
+    priority: first
+aliases:
+  k: kubectl
+  g: [git]
+page_env:
+  kubectl: [KUBECONFIG=~/.kube/staging, 1DEBUG=true]
+placeholder_memory:
+  port: remember
+  host: sometimes
+sync:
+  backend: ftp
+exec:
+  timeout: forever
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	problems, err = Validate(configFile)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	expected := []string{
+		`unknown key "them" (did you mean "theme"?)`,
+		`platforms: invalid value "beos"`,
+		`cache_ttl_hours: expected a whole number`,
+		`unknown key "keymap.cpy" (did you mean "keymap.copy"?)`,
+		`sources[0]: unknown field "ulr" (did you mean "url"?)`,
+		`sources[0]: url is required`,
+		`sources[0]: priority must be a number`,
+		`aliases.g: expected a page name`,
+		`page_env.kubectl: invalid environment variable "1DEBUG=true"`,
+		`placeholder_memory.host: invalid value "sometimes"`,
+		`sync.backend: invalid value "ftp"`,
+		`exec.timeout: expected a duration`,
+	}
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.Error())
+	}
+	all := strings.Join(messages, "\n")
+	for _, message := range expected {
+		if !strings.Contains(all, message) {
+			t.Errorf("Expected a problem %q, got:\n%s", message, all)
+		}
+	}
+	if len(problems) != len(expected) {
+		t.Errorf("Expected %d problems, got %d:\n%s", len(expected), len(problems), all)
+	}
+}
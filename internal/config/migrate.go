@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// dataFiles are kept in DataDir; older versions kept them next to the pages
+// cache
+var dataFiles = []string{"executions.json", "placeholders.json", "exec.log"}
+
+// rename is os.Rename, replaced in tests to simulate moves across devices
+var rename = os.Rename
+
+// Migrate moves the files of older versions, which always used
+// ~/.config/tldrpp and ~/.cache/tldrpp, to the platform's config, cache and
+// data directories, and points a config naming the old cache directory at
+// the new one. Nothing is overwritten, so it is safe to call on every start.
+// Nothing is moved into a Home either, which is meant to start out apart
+// from the user's files.
+func Migrate() error {
+	if Home() != "" {
+		return nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return migrate(filepath.Join(homeDir, ".config", "tldrpp"), filepath.Join(homeDir, ".cache", "tldrpp"))
+}
+
+// migrate moves legacyConfig and legacyCache to their current locations
+func migrate(legacyConfig, legacyCache string) error {
+	configDir := getConfigDir()
+	if err := moveIfAbsent(legacyConfig, configDir); err != nil {
+		return fmt.Errorf("failed to move config directory: %w", err)
+	}
+	configFile := filepath.Join(configDir, "config.yml")
+
+	// History was kept next to the configured cache directory
+	legacyPages := filepath.Join(legacyCache, "pages")
+	historyDir := legacyCache
+	if cacheDir, err := configuredCacheDir(configFile); err == nil && cacheDir != "" {
+		historyDir = filepath.Dir(cacheDir)
+	}
+	dataDir := DataDir()
+	for _, name := range dataFiles {
+		if err := moveIfAbsent(filepath.Join(historyDir, name), filepath.Join(dataDir, name)); err != nil {
+			return fmt.Errorf("failed to move %s: %w", name, err)
+		}
+	}
+
+	cacheDir := getDefaultCacheDir()
+	if err := moveIfAbsent(legacyCache, filepath.Dir(cacheDir)); err != nil {
+		return fmt.Errorf("failed to move cache directory: %w", err)
+	}
+	if err := replaceCacheDir(configFile, legacyPages, cacheDir); err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+	return nil
+}
+
+// moveIfAbsent renames from to to, unless from doesn't exist or to does
+func moveIfAbsent(from, to string) error {
+	if from == to {
+		return nil
+	}
+	if _, err := os.Stat(from); err != nil {
+		return nil
+	}
+	if _, err := os.Stat(to); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+
+	// The new location may be on another filesystem, e.g. when XDG_DATA_HOME
+	// is a separate mount, where rename doesn't work
+	err := rename(from, to)
+	var linkErr *os.LinkError
+	if errors.As(err, &linkErr) && errors.Is(linkErr.Err, syscall.EXDEV) {
+		if err := copyTree(from, to); err != nil {
+			os.RemoveAll(to)
+			return err
+		}
+		return os.RemoveAll(from)
+	}
+	return err
+}
+
+// copyTree copies the file or directory from to to, keeping permissions
+// and symlinks
+func copyTree(from, to string) error {
+	return filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(to, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(path, target, info.Mode().Perm())
+		}
+	})
+}
+
+// copyFile copies the content of a regular file to a new file with perm
+func copyFile(from, to string, perm fs.FileMode) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// configuredCacheDir returns the cache_dir set in a config file, if any
+func configuredCacheDir(configFile string) (string, error) {
+	values, err := readFile(configFile)
+	if err != nil {
+		return "", err
+	}
+	cacheDir, _ := values["cache_dir"].(string)
+	return cacheDir, nil
+}
+
+// replaceCacheDir rewrites the cache_dir of a config file from from to to.
+// Only that key changes; the file is edited as YAML rather than through
+// viper so values from the environment don't end up in it.
+func replaceCacheDir(configFile, from, to string) error {
+	if from == to {
+		return nil
+	}
+	values, err := readFile(configFile)
+	if err != nil || values["cache_dir"] != from {
+		return nil
+	}
+
+	values["cache_dir"] = to
+	return writeFile(configFile, values)
+}
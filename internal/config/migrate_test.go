@@ -0,0 +1,228 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// xdgHome points HOME and the XDG directories at a temporary directory and
+// returns it
+func xdgHome(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG directories are only used on Linux")
+	}
+
+	root := t.TempDir()
+	t.Setenv("HOME", filepath.Join(root, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(root, "cache"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(root, "data"))
+	t.Setenv(homeEnv, "")
+	return root
+}
+
+// writeTestFile creates a file with its parent directories
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+}
+
+func TestXDGDirectories(t *testing.T) {
+	root := xdgHome(t)
+
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{"config", getConfigDir(), filepath.Join(root, "config", "tldrpp")},
+		{"cache", getDefaultCacheDir(), filepath.Join(root, "cache", "tldrpp", "pages")},
+		{"data", DataDir(), filepath.Join(root, "data", "tldrpp")},
+		{"snippets", SnippetsDir(), filepath.Join(root, "config", "tldrpp", "snippets")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, tt.got)
+			}
+		})
+	}
+
+	// Without XDG variables the usual dot directories are used
+	t.Setenv("XDG_DATA_HOME", "")
+	if dir := DataDir(); dir != filepath.Join(root, "home", ".local", "share", "tldrpp") {
+		t.Errorf("Expected ~/.local/share/tldrpp, got %s", dir)
+	}
+}
+
+func TestHome(t *testing.T) {
+	root := xdgHome(t)
+	home := filepath.Join(root, "isolated")
+
+	for _, set := range []struct {
+		name string
+		set  func(t *testing.T)
+	}{
+		{"env", func(t *testing.T) { t.Setenv(homeEnv, home) }},
+		{"flag", func(t *testing.T) { SetHome(home) }},
+	} {
+		t.Run(set.name, func(t *testing.T) {
+			set.set(t)
+			defer SetHome("")
+
+			if Home() != home {
+				t.Fatalf("Expected home %s, got %s", home, Home())
+			}
+			for _, dir := range []struct{ got, expected string }{
+				{getConfigDir(), filepath.Join(home, "config")},
+				{getDefaultCacheDir(), filepath.Join(home, "cache")},
+				{DataDir(), filepath.Join(home, "data")},
+				{SnippetsDir(), filepath.Join(home, "config", "snippets")},
+			} {
+				if dir.got != dir.expected {
+					t.Errorf("Expected %s, got %s", dir.expected, dir.got)
+				}
+			}
+		})
+	}
+
+	// A relative home is taken from the working directory
+	SetHome("relative")
+	defer SetHome("")
+	if wd, _ := os.Getwd(); Home() != filepath.Join(wd, "relative") {
+		t.Errorf("Expected an absolute home, got %s", Home())
+	}
+}
+
+func TestMigrateIntoHome(t *testing.T) {
+	root := xdgHome(t)
+	legacyConfig := filepath.Join(root, "home", ".config", "tldrpp", "config.yml")
+	writeTestFile(t, legacyConfig, "theme: light\n")
+	t.Setenv(homeEnv, filepath.Join(root, "isolated"))
+
+	if err := Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if _, err := os.Stat(legacyConfig); err != nil {
+		t.Errorf("Expected the user's files to stay out of an isolated home: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "isolated")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be moved into the home, got %v", err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	root := xdgHome(t)
+	home := filepath.Join(root, "home")
+	legacyConfig := filepath.Join(home, ".config", "tldrpp")
+	legacyCache := filepath.Join(home, ".cache", "tldrpp")
+
+	writeTestFile(t, filepath.Join(legacyConfig, "config.yml"), "theme: light\ncache_dir: "+filepath.Join(legacyCache, "pages")+"\n")
+	writeTestFile(t, filepath.Join(legacyConfig, "snippets", "backup.yml"), "name: backup\n")
+	writeTestFile(t, filepath.Join(legacyCache, "pages", "index.json"), "[]")
+	writeTestFile(t, filepath.Join(legacyCache, "executions.json"), "[]")
+	writeTestFile(t, filepath.Join(legacyCache, "exec.log"), "ls\n")
+
+	if err := Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	for _, path := range []string{
+		filepath.Join(root, "config", "tldrpp", "config.yml"),
+		filepath.Join(root, "config", "tldrpp", "snippets", "backup.yml"),
+		filepath.Join(root, "cache", "tldrpp", "pages", "index.json"),
+		filepath.Join(root, "data", "tldrpp", "executions.json"),
+		filepath.Join(root, "data", "tldrpp", "exec.log"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to exist after migrating: %v", path, err)
+		}
+	}
+	for _, path := range []string{legacyConfig, legacyCache} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be moved away", path)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "config", "tldrpp", "config.yml"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if !strings.Contains(string(data), filepath.Join(root, "cache", "tldrpp", "pages")) || !strings.Contains(string(data), "theme: light") {
+		t.Errorf("Expected the config to point at the new cache and keep its settings, got:\n%s", data)
+	}
+
+	// Running again changes nothing
+	if err := Migrate(); err != nil {
+		t.Fatalf("Second Migrate failed: %v", err)
+	}
+}
+
+func TestMigrateKeepsExistingFiles(t *testing.T) {
+	root := xdgHome(t)
+	legacyConfig := filepath.Join(root, "home", ".config", "tldrpp")
+
+	writeTestFile(t, filepath.Join(legacyConfig, "config.yml"), "theme: light\n")
+	writeTestFile(t, filepath.Join(root, "config", "tldrpp", "config.yml"), "theme: solarized\n")
+
+	if err := Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "config", "tldrpp", "config.yml"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if string(data) != "theme: solarized\n" {
+		t.Errorf("Expected the existing config to be kept, got %q", data)
+	}
+	if _, err := os.Stat(legacyConfig); err != nil {
+		t.Errorf("Expected the old config to be left alone: %v", err)
+	}
+}
+
+func TestMoveIfAbsentAcrossDevices(t *testing.T) {
+	rename = func(from, to string) error {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.EXDEV}
+	}
+	t.Cleanup(func() { rename = os.Rename })
+
+	root := t.TempDir()
+	from := filepath.Join(root, "old")
+	to := filepath.Join(root, "new", "tldrpp")
+	writeTestFile(t, filepath.Join(from, "config.yml"), "theme: light\n")
+	writeTestFile(t, filepath.Join(from, "snippets", "backup.yml"), "name: backup\n")
+	if err := os.Chmod(filepath.Join(from, "config.yml"), 0600); err != nil {
+		t.Fatalf("Chmod failed: %v", err)
+	}
+
+	if err := moveIfAbsent(from, to); err != nil {
+		t.Fatalf("moveIfAbsent failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(to, "snippets", "backup.yml"))
+	if err != nil || string(data) != "name: backup\n" {
+		t.Errorf("Expected the snippet to be copied, got %q (%v)", data, err)
+	}
+	info, err := os.Stat(filepath.Join(to, "config.yml"))
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("Expected permissions 0600 to be kept, got %v", info.Mode().Perm())
+	}
+	if _, err := os.Stat(from); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed after copying", from)
+	}
+}
@@ -0,0 +1,133 @@
+//go:build windows
+
+package conpty
+
+import (
+	"fmt"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Console is a command running in a pseudo console
+type Console struct {
+	// Process is the command's process
+	Process *os.Process
+	// Output reads what the command writes to the console, escape
+	// sequences included. It ends once the console is closed.
+	Output *os.File
+	// input writes keys to the console; nothing is typed
+	input   *os.File
+	console windows.Handle
+}
+
+// Start runs the program at path with commandLine, which the program parses
+// itself and which starts with its name, in a new pseudo console width by
+// height characters large. The command starts in
+// dir, or the current directory if empty, with env as its environment, or
+// tldrpp's own if nil.
+func Start(path, commandLine, dir string, env []string, width, height int) (*Console, error) {
+	var inRead, inWrite, outRead, outWrite windows.Handle
+	if err := windows.CreatePipe(&inRead, &inWrite, nil, 0); err != nil {
+		return nil, fmt.Errorf("failed to create console input: %w", err)
+	}
+	if err := windows.CreatePipe(&outRead, &outWrite, nil, 0); err != nil {
+		windows.CloseHandle(inRead)
+		windows.CloseHandle(inWrite)
+		return nil, fmt.Errorf("failed to create console output: %w", err)
+	}
+	// The console keeps its own ends of the pipes
+	defer windows.CloseHandle(inRead)
+	defer windows.CloseHandle(outWrite)
+
+	c := &Console{
+		input:  os.NewFile(uintptr(inWrite), "conpty-input"),
+		Output: os.NewFile(uintptr(outRead), "conpty-output"),
+	}
+	size := windows.Coord{X: int16(width), Y: int16(height)}
+	if err := windows.CreatePseudoConsole(size, inRead, outWrite, 0, &c.console); err != nil {
+		c.input.Close()
+		c.Output.Close()
+		return nil, fmt.Errorf("failed to create pseudo console: %w", err)
+	}
+	if err := c.start(path, commandLine, dir, env); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// start creates the command's process attached to the console
+func (c *Console) start(path, commandLine, dir string, env []string) error {
+	attrs, err := windows.NewProcThreadAttributeList(1)
+	if err != nil {
+		return err
+	}
+	defer attrs.Delete()
+	// The attribute's value is the console handle itself, not a pointer to
+	// it
+	if err := attrs.Update(windows.PROC_THREAD_ATTRIBUTE_PSEUDOCONSOLE, *(*unsafe.Pointer)(unsafe.Pointer(&c.console)), unsafe.Sizeof(c.console)); err != nil {
+		return fmt.Errorf("failed to attach pseudo console: %w", err)
+	}
+
+	program, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	line, err := windows.UTF16PtrFromString(commandLine)
+	if err != nil {
+		return err
+	}
+	var cwd *uint16
+	if dir != "" {
+		if cwd, err = windows.UTF16PtrFromString(dir); err != nil {
+			return err
+		}
+	}
+	if env == nil {
+		env = os.Environ()
+	}
+	block, err := envBlock(env)
+	if err != nil {
+		return err
+	}
+
+	info := &windows.StartupInfoEx{ProcThreadAttributeList: attrs.List()}
+	info.Cb = uint32(unsafe.Sizeof(*info))
+	var process windows.ProcessInformation
+	flags := uint32(windows.EXTENDED_STARTUPINFO_PRESENT | windows.CREATE_UNICODE_ENVIRONMENT)
+	if err := windows.CreateProcess(program, line, nil, nil, false, flags, &block[0], cwd, &info.StartupInfo, &process); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+	defer windows.CloseHandle(process.Thread)
+	defer windows.CloseHandle(process.Process)
+
+	c.Process, err = os.FindProcess(int(process.ProcessId))
+	return err
+}
+
+// Close closes the console, which ends Output once what is left of it was
+// read, and stops the command if it is still running
+func (c *Console) Close() error {
+	if c.console != 0 {
+		windows.ClosePseudoConsole(c.console)
+		c.console = 0
+	}
+	c.input.Close()
+	return nil
+}
+
+// envBlock returns env as the environment block CreateProcess takes: each
+// NAME=value ended with a NUL, and the block with another one
+func envBlock(env []string) ([]uint16, error) {
+	var block []uint16
+	for _, entry := range env {
+		utf16, err := windows.UTF16FromString(entry)
+		if err != nil {
+			return nil, err
+		}
+		block = append(block, utf16...)
+	}
+	return append(block, 0), nil
+}
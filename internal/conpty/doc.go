@@ -0,0 +1,6 @@
+// Package conpty runs commands in a Windows pseudo console (ConPTY), so
+// that their output can be captured while they still write to a console:
+// programs given a pipe instead often drop colors, buffer their output or
+// refuse to run. Elsewhere commands are run with pipes, or get the
+// terminal tldrpp runs in, and the package is empty.
+package conpty
@@ -0,0 +1,138 @@
+package diff
+
+import "strings"
+
+// Kind says whether a piece of text is in both versions or only one
+type Kind int
+
+const (
+	Equal Kind = iota
+	Delete
+	Insert
+)
+
+// Op is a piece of text that is kept, deleted from the old version or
+// inserted in the new one
+type Op struct {
+	Kind Kind
+	Text string
+}
+
+// Words diffs two command lines word by word, keeping whitespace attached
+// to the words around it so that joining the Equal and Delete ops gives
+// back old and joining the Equal and Insert ops gives back new
+func Words(old, new string) []Op {
+	return tokens(split(old), split(new))
+}
+
+// Lines diffs two texts line by line, each line keeping its newline, e.g.
+// two versions of a page
+func Lines(old, new string) []Op {
+	return tokens(splitLines(old), splitLines(new))
+}
+
+// tokens diffs two sequences of tokens, merging adjacent tokens of the same
+// kind into one op
+func tokens(a, b []string) []Op {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []Op
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = appendOp(ops, Equal, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = appendOp(ops, Delete, a[i])
+			i++
+		default:
+			ops = appendOp(ops, Insert, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = appendOp(ops, Delete, a[i])
+	}
+	for ; j < len(b); j++ {
+		ops = appendOp(ops, Insert, b[j])
+	}
+	return ops
+}
+
+// Changed reports whether ops contain any deletion or insertion
+func Changed(ops []Op) bool {
+	for _, op := range ops {
+		if op.Kind != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// appendOp adds text to ops, merging it into the last op of the same kind
+func appendOp(ops []Op, kind Kind, text string) []Op {
+	if n := len(ops); n > 0 && ops[n-1].Kind == kind {
+		ops[n-1].Text += text
+		return ops
+	}
+	return append(ops, Op{Kind: kind, Text: text})
+}
+
+// split cuts s into words and the runs of whitespace between them
+func split(s string) []string {
+	var tokens []string
+	start := 0
+	for i, r := range s {
+		if i == start {
+			continue
+		}
+		prev := s[i-1] == ' ' || s[i-1] == '\t'
+		cur := r == ' ' || r == '\t'
+		if prev != cur {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+// splitLines splits s after each newline
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Join concatenates the text of the ops of the given kinds
+func Join(ops []Op, kinds ...Kind) string {
+	var text strings.Builder
+	for _, op := range ops {
+		for _, kind := range kinds {
+			if op.Kind == kind {
+				text.WriteString(op.Text)
+			}
+		}
+	}
+	return text.String()
+}
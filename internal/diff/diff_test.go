@@ -0,0 +1,130 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		expected []Op
+	}{
+		{
+			name: "placeholder filled",
+			old:  "tar -xf {{file}}",
+			new:  "tar -xf 'a b.tar'",
+			expected: []Op{
+				{Equal, "tar -xf "},
+				{Delete, "{{file}}"},
+				{Insert, "'a b.tar'"},
+			},
+		},
+		{
+			name: "several placeholders",
+			old:  "scp {{file}} {{user}}@{{host}}:{{path}}",
+			new:  "scp notes.txt root@db:/tmp",
+			expected: []Op{
+				{Equal, "scp "},
+				{Delete, "{{file}}"},
+				{Insert, "notes.txt"},
+				{Equal, " "},
+				{Delete, "{{user}}@{{host}}:{{path}}"},
+				{Insert, "root@db:/tmp"},
+			},
+		},
+		{
+			name:     "unchanged",
+			old:      "ls -la",
+			new:      "ls -la",
+			expected: []Op{{Equal, "ls -la"}},
+		},
+		{
+			name: "word added",
+			old:  "git log",
+			new:  "git log --oneline",
+			expected: []Op{
+				{Equal, "git log"},
+				{Insert, " --oneline"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := Words(tt.old, tt.new)
+			if !reflect.DeepEqual(ops, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, ops)
+			}
+			if got := Join(ops, Equal, Delete); got != tt.old {
+				t.Errorf("Expected the old side to be %q, got %q", tt.old, got)
+			}
+			if got := Join(ops, Equal, Insert); got != tt.new {
+				t.Errorf("Expected the new side to be %q, got %q", tt.new, got)
+			}
+		})
+	}
+}
+
+func TestChanged(t *testing.T) {
+	if Changed(Words("ls", "ls")) {
+		t.Error("Expected identical commands to be unchanged")
+	}
+	if !Changed(Words("ls {{dir}}", "ls src")) {
+		t.Error("Expected a filled placeholder to be a change")
+	}
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		expected []Op
+	}{
+		{
+			name: "example changed",
+			old:  "# ls\n\n- List files:\n\n`ls`\n",
+			new:  "# ls\n\n- List all files:\n\n`ls -a`\n",
+			expected: []Op{
+				{Equal, "# ls\n\n"},
+				{Delete, "- List files:\n"},
+				{Insert, "- List all files:\n"},
+				{Equal, "\n"},
+				{Delete, "`ls`\n"},
+				{Insert, "`ls -a`\n"},
+			},
+		},
+		{
+			name: "line added at the end",
+			old:  "a\nb",
+			new:  "a\nb\nc\n",
+			expected: []Op{
+				{Equal, "a\n"},
+				{Delete, "b"},
+				{Insert, "b\nc\n"},
+			},
+		},
+		{
+			name:     "empty",
+			old:      "",
+			new:      "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := Lines(tt.old, tt.new)
+			if !reflect.DeepEqual(ops, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, ops)
+			}
+			if got := Join(ops, Equal, Delete); got != tt.old {
+				t.Errorf("Expected the old side to be %q, got %q", tt.old, got)
+			}
+			if got := Join(ops, Equal, Insert); got != tt.new {
+				t.Errorf("Expected the new side to be %q, got %q", tt.new, got)
+			}
+		})
+	}
+}
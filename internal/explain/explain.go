@@ -0,0 +1,460 @@
+package explain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// PartKind tells what a word of the command line is
+type PartKind int
+
+const (
+	// KindCommand is the command itself, or a subcommand with its own page
+	KindCommand PartKind = iota
+	// KindFlag is an option such as -x or --extract
+	KindFlag
+	// KindArgument is anything else
+	KindArgument
+	// KindRedirect is a redirection such as 2>&1 or >out.log
+	KindRedirect
+)
+
+func (k PartKind) String() string {
+	switch k {
+	case KindCommand:
+		return "command"
+	case KindFlag:
+		return "flag"
+	case KindRedirect:
+		return "redirect"
+	default:
+		return "argument"
+	}
+}
+
+// MarshalText encodes the kind by name
+func (k PartKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Part explains one word of the command line
+type Part struct {
+	Token string   `json:"token"`
+	Kind  PartKind `json:"kind"`
+	// Meaning is a short gloss such as "extract" for -x or the placeholder
+	// an argument fills
+	Meaning string `json:"meaning,omitempty"`
+	// Example is the description of the example the part was matched to
+	Example string `json:"example,omitempty"`
+}
+
+// Explanation is a command line broken down against a page
+type Explanation struct {
+	Page  *types.Page `json:"-"`
+	Parts []Part      `json:"parts"`
+	// Closest is the example sharing the most flags with the command line
+	Closest *types.Example `json:"closest,omitempty"`
+}
+
+// mnemonicPattern matches the [x]tract style letter markers in descriptions
+var mnemonicPattern = regexp.MustCompile(`\S*\[[A-Za-z0-9]\]\S*`)
+
+// placeholderPattern matches {{placeholder}} in an example command
+var placeholderPattern = regexp.MustCompile(`\{\{[^}]+\}\}`)
+
+// redirectPattern matches the operator at the start of a redirection
+var redirectPattern = regexp.MustCompile(`^(\d*>>?|\d*<|&>>?)(&\d+|&-)?`)
+
+// flagGroup is one option of an example, with all its spellings, e.g.
+// -x and --extract from {{[-x|--extract]}}
+type flagGroup []string
+
+// Explain matches the words of a command line to the examples of page.
+// words[:commandWords] are the command, e.g. 2 for "git commit".
+func Explain(page *types.Page, words []string, commandWords int) *Explanation {
+	explanation := &Explanation{Page: page}
+	for _, word := range words[:commandWords] {
+		explanation.Parts = append(explanation.Parts, Part{Token: word, Kind: KindCommand, Meaning: page.Description})
+	}
+
+	groups := make([][]flagGroup, len(page.Examples))
+	for i := range page.Examples {
+		groups[i] = exampleFlags(&page.Examples[i])
+	}
+
+	var args []string
+	var flags []string
+	rest := words[commandWords:]
+	for i := 0; i < len(rest); i++ {
+		word := rest[i]
+		if operator := redirectPattern.FindString(word); operator != "" {
+			// A bare operator such as > takes the next word as its target
+			if operator == word && !strings.Contains(word, "&") && i+1 < len(rest) {
+				i++
+				word += " " + rest[i]
+			}
+			explanation.Parts = append(explanation.Parts, Part{Token: word, Kind: KindRedirect, Meaning: redirectMeaning(operator)})
+			continue
+		}
+		if !isFlag(word) {
+			args = append(args, word)
+			explanation.Parts = append(explanation.Parts, Part{Token: word, Kind: KindArgument})
+			continue
+		}
+		for _, flag := range splitFlag(word) {
+			flags = append(flags, flag)
+			explanation.Parts = append(explanation.Parts, explainFlag(page, groups, flag))
+		}
+	}
+
+	explanation.Closest = closest(page, groups, flags)
+	if explanation.Closest != nil {
+		explainArguments(explanation, args)
+	}
+	return explanation
+}
+
+// redirectMeaning describes a redirection operator
+func redirectMeaning(operator string) string {
+	switch {
+	case strings.HasSuffix(operator, "&-"):
+		return "close the descriptor"
+	case strings.Contains(operator, ">&"):
+		return "send the output to another descriptor"
+	case strings.HasPrefix(operator, "&>"):
+		return "send output and errors to a file"
+	case strings.HasPrefix(operator, "2>"):
+		return "send errors to a file"
+	case strings.Contains(operator, ">>"):
+		return "append the output to a file"
+	case strings.Contains(operator, ">"):
+		return "write the output to a file"
+	default:
+		return "read the input from a file"
+	}
+}
+
+// explainFlag finds the example that best explains a single flag
+func explainFlag(page *types.Page, groups [][]flagGroup, flag string) Part {
+	part := Part{Token: flag, Kind: KindFlag}
+	name, _, _ := strings.Cut(flag, "=")
+
+	best, bestScore := -1, 0
+	for i, example := range page.Examples {
+		score := 0
+		if findGroup(groups[i], name) != nil {
+			score += 2
+		}
+		if hasMnemonic(example.Description, name) {
+			score++
+		}
+		// More specific examples, with fewer options, win ties
+		if score > bestScore || (score == bestScore && score > 0 && len(groups[i]) < len(groups[best])) {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		part.Meaning = "not covered by this page"
+		return part
+	}
+
+	example := page.Examples[best]
+	part.Example = example.Description
+	part.Meaning = flagMeaning(findGroup(groups[best], name), name, example.Description)
+	return part
+}
+
+// flagMeaning glosses a flag by its long spelling, or by the word marked
+// with its letter in the description
+func flagMeaning(group flagGroup, name, description string) string {
+	for _, spelling := range group {
+		if strings.HasPrefix(spelling, "--") {
+			return strings.TrimPrefix(spelling, "--")
+		}
+	}
+	if hasMnemonic(description, name) {
+		for _, word := range mnemonicPattern.FindAllString(description, -1) {
+			if strings.Contains(word, "["+name[1:]+"]") {
+				word = strings.NewReplacer("[", "", "]", "").Replace(word)
+				return strings.ToLower(strings.Trim(word, ".,:;()"))
+			}
+		}
+	}
+	return ""
+}
+
+// closest returns the example explaining the most flags of the command
+// line, or the first example if none match. Flags written out in the example
+// win ties over ones only marked in its description.
+func closest(page *types.Page, groups [][]flagGroup, flags []string) *types.Example {
+	if len(page.Examples) == 0 {
+		return nil
+	}
+
+	best, bestScore, bestWritten := 0, -1, -1
+	for i, example := range page.Examples {
+		score, written := 0, 0
+		for _, flag := range flags {
+			name, _, _ := strings.Cut(flag, "=")
+			switch {
+			case findGroup(groups[i], name) != nil:
+				score++
+				written++
+			case hasMnemonic(example.Description, name):
+				score++
+			}
+		}
+		if score > bestScore || (score == bestScore && written > bestWritten) {
+			best, bestScore, bestWritten = i, score, written
+		}
+	}
+	return &page.Examples[best]
+}
+
+// hasMnemonic reports whether a description marks a short flag's letter, as
+// in "e[x]tract"
+func hasMnemonic(description, name string) bool {
+	return len(name) == 2 && strings.Contains(description, "["+name[1:]+"]")
+}
+
+// explainArguments maps the arguments, in order, to the placeholders of the
+// closest example that aren't options. A variadic placeholder takes all the
+// remaining arguments.
+func explainArguments(explanation *Explanation, args []string) {
+	var placeholders []types.Placeholder
+	for _, placeholder := range explanation.Closest.Placeholders {
+		if !isOptionGroup(placeholder) {
+			placeholders = append(placeholders, placeholder)
+		}
+	}
+
+	next := 0
+	for i := range explanation.Parts {
+		part := &explanation.Parts[i]
+		if part.Kind != KindArgument {
+			continue
+		}
+		part.Example = explanation.Closest.Description
+		if next >= len(placeholders) {
+			part.Meaning = "argument"
+			continue
+		}
+		placeholder := placeholders[next]
+		part.Meaning = placeholder.Token()
+		if !placeholder.Variadic {
+			next++
+		}
+	}
+}
+
+// exampleFlags lists the options an example uses, both written out and in
+// option placeholders such as {{[-v|--verbose]}}
+func exampleFlags(example *types.Example) []flagGroup {
+	var groups []flagGroup
+	for _, placeholder := range example.Placeholders {
+		if isOptionGroup(placeholder) {
+			groups = append(groups, flagGroup(placeholder.Choices))
+		}
+	}
+
+	plain := placeholderPattern.ReplaceAllString(example.Command, " ")
+	for _, word := range strings.Fields(plain) {
+		if !isFlag(word) {
+			continue
+		}
+		for _, flag := range splitFlag(word) {
+			name, _, _ := strings.Cut(flag, "=")
+			groups = append(groups, flagGroup{name})
+		}
+	}
+	return groups
+}
+
+// isOptionGroup reports whether a placeholder offers spellings of an option
+func isOptionGroup(placeholder types.Placeholder) bool {
+	if len(placeholder.Choices) == 0 {
+		return false
+	}
+	for _, choice := range placeholder.Choices {
+		if !strings.HasPrefix(choice, "-") {
+			return false
+		}
+	}
+	return true
+}
+
+// findGroup returns the group with the given spelling, or nil
+func findGroup(groups []flagGroup, name string) flagGroup {
+	for _, group := range groups {
+		for _, spelling := range group {
+			if spelling == name {
+				return group
+			}
+		}
+	}
+	return nil
+}
+
+// isFlag reports whether a word is an option
+func isFlag(word string) bool {
+	return len(word) > 1 && word[0] == '-' && word != "--"
+}
+
+// splitFlag expands bundled short options, -xzvf -> -x -z -v -f. Long
+// options are returned as they are.
+func splitFlag(word string) []string {
+	if strings.HasPrefix(word, "--") || len(word) <= 2 {
+		return []string{word}
+	}
+	flags := make([]string, 0, len(word)-1)
+	for _, letter := range word[1:] {
+		flags = append(flags, "-"+string(letter))
+	}
+	return flags
+}
+
+// Command is one stage of a pipeline or command list
+type Command struct {
+	Words []string `json:"words"`
+	// Operator joins the command to the next one: |, &&, ||, ; or &. It is
+	// empty for the last command.
+	Operator string `json:"operator,omitempty"`
+}
+
+// operators are the unquoted control operators that separate commands,
+// longest first
+var operators = []string{"&&", "||", "|", ";", "&"}
+
+// SplitCommands breaks a command line into the commands of its pipelines and
+// command lists, e.g. "ps aux | grep ssh && echo ok" into three commands.
+// Words are split like Split does.
+func SplitCommands(line string) ([]Command, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return nil, err
+	}
+
+	var commands []Command
+	var current Command
+	for _, token := range tokens {
+		if !token.operator {
+			current.Words = append(current.Words, token.text)
+			continue
+		}
+		if len(current.Words) == 0 {
+			return nil, fmt.Errorf("missing command before %s", token.text)
+		}
+		current.Operator = token.text
+		commands = append(commands, current)
+		current = Command{}
+	}
+
+	if len(current.Words) > 0 {
+		commands = append(commands, current)
+	} else if len(commands) > 0 && commands[len(commands)-1].Operator != ";" && commands[len(commands)-1].Operator != "&" {
+		return nil, fmt.Errorf("missing command after %s", commands[len(commands)-1].Operator)
+	}
+	return commands, nil
+}
+
+// Split breaks a command line into words like a POSIX shell would, honouring
+// single and double quotes and backslash escapes. Control operators such as
+// | are kept as words.
+//
+// Deprecated: use shellwords.Split for command lines run as argv, which
+// rejects operators rather than passing them on as arguments.
+func Split(line string) ([]string, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return nil, err
+	}
+	words := make([]string, len(tokens))
+	for i, token := range tokens {
+		words[i] = token.text
+	}
+	return words, nil
+}
+
+// token is a word or an unquoted control operator of a command line
+type token struct {
+	text     string
+	operator bool
+}
+
+// tokenize splits a command line into words and control operators
+func tokenize(line string) ([]token, error) {
+	var tokens []token
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	endWord := func() {
+		if inWord {
+			tokens = append(tokens, token{text: word.String()})
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			endWord()
+		case r == '&' && isRedirect(word.String(), runes[i+1:]):
+			// 2>&1 and &> redirect rather than run in the background
+			word.WriteRune(r)
+			inWord = true
+		case r == '|' || r == '&' || r == ';':
+			endWord()
+			operator := string(r)
+			for _, candidate := range operators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					operator = candidate
+					break
+				}
+			}
+			tokens = append(tokens, token{text: operator, operator: true})
+			i += len(operator) - 1
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	endWord()
+	return tokens, nil
+}
+
+// isRedirect reports whether an & between before and after belongs to a
+// redirection such as 2>&1 or &>file
+func isRedirect(before string, after []rune) bool {
+	return strings.HasSuffix(before, ">") || strings.HasSuffix(before, "<") ||
+		(len(after) > 0 && after[0] == '>')
+}
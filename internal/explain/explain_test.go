@@ -0,0 +1,219 @@
+package explain
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+const tarPage = `# tar
+
+> Archiving utility.
+
+- [c]reate an archive and write it to a [f]ile:
+
+` + "`tar cf {{path/to/target.tar}} {{path/to/file1 path/to/file2 ...}}`" + `
+
+- E[x]tract a (compressed) archive [f]ile into the current directory [v]erbosely:
+
+` + "`tar xvf {{path/to/source.tar[.gz|.bz2|.xz]}}`" + `
+
+- Extract a gzipped archive:
+
+` + "`tar {{[-x|--extract]}} {{[-z|--gzip]}} {{[-f|--file]}} {{path/to/source.tar.gz}}`" + `
+
+- [c]reate a g[z]ipped archive and write it to a [f]ile:
+
+` + "`tar czf {{path/to/target.tar.gz}} {{path/to/file1 path/to/file2 ...}}`" + `
+`
+
+func parseTar(t *testing.T) *types.Page {
+	t.Helper()
+	page, err := types.ParsePage(tarPage, types.IndexEntry{Name: "tar", Platform: "common"})
+	if err != nil {
+		t.Fatalf("Failed to parse page: %v", err)
+	}
+	return page
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		words []string
+	}{
+		{"plain", "tar -xzvf foo.tgz", []string{"tar", "-xzvf", "foo.tgz"}},
+		{"extra spaces", "  ls   -la\n", []string{"ls", "-la"}},
+		{"double quotes", `grep "a b" file`, []string{"grep", "a b", "file"}},
+		{"single quotes", `echo '$HOME \n'`, []string{"echo", `$HOME \n`}},
+		{"escape", `cat my\ file`, []string{"cat", "my file"}},
+		{"empty quotes", `printf ''`, []string{"printf", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := Split(tt.line)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(words, tt.words) {
+				t.Errorf("Expected %q, got %q", tt.words, words)
+			}
+		})
+	}
+
+	for _, line := range []string{`echo "open`, `echo 'open`, `echo \`} {
+		if _, err := Split(line); err == nil {
+			t.Errorf("Expected an error for %q", line)
+		}
+	}
+}
+
+func TestSplitCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		commands []Command
+	}{
+		{"single", "tar -xf a.tar", []Command{{Words: []string{"tar", "-xf", "a.tar"}}}},
+		{"pipeline", "ps aux | grep ssh", []Command{
+			{Words: []string{"ps", "aux"}, Operator: "|"},
+			{Words: []string{"grep", "ssh"}},
+		}},
+		{"list", "make&&make install || echo failed; ls", []Command{
+			{Words: []string{"make"}, Operator: "&&"},
+			{Words: []string{"make", "install"}, Operator: "||"},
+			{Words: []string{"echo", "failed"}, Operator: ";"},
+			{Words: []string{"ls"}},
+		}},
+		{"quoted operators", `grep "a|b" 'c;d'`, []Command{{Words: []string{"grep", "a|b", "c;d"}}}},
+		{"redirections", "make 2>&1 &>log | tee out", []Command{
+			{Words: []string{"make", "2>&1", "&>log"}, Operator: "|"},
+			{Words: []string{"tee", "out"}},
+		}},
+		{"trailing separator", "sleep 10 &", []Command{{Words: []string{"sleep", "10"}, Operator: "&"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands, err := SplitCommands(tt.line)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(commands, tt.commands) {
+				t.Errorf("Expected %+v, got %+v", tt.commands, commands)
+			}
+		})
+	}
+
+	for _, line := range []string{"| grep x", "ls |", "ls && && pwd"} {
+		if _, err := SplitCommands(line); err == nil {
+			t.Errorf("Expected an error for %q", line)
+		}
+	}
+}
+
+func TestSplitFlag(t *testing.T) {
+	tests := []struct {
+		word  string
+		flags []string
+	}{
+		{"-x", []string{"-x"}},
+		{"-xzvf", []string{"-x", "-z", "-v", "-f"}},
+		{"--extract", []string{"--extract"}},
+		{"--file=a.tar", []string{"--file=a.tar"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if flags := splitFlag(tt.word); !reflect.DeepEqual(flags, tt.flags) {
+				t.Errorf("Expected %q, got %q", tt.flags, flags)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	page := parseTar(t)
+	explanation := Explain(page, []string{"tar", "-xzvf", "foo.tgz"}, 1)
+
+	expected := []Part{
+		{Token: "tar", Kind: KindCommand, Meaning: "Archiving utility"},
+		{Token: "-x", Kind: KindFlag, Meaning: "extract", Example: "Extract a gzipped archive"},
+		{Token: "-z", Kind: KindFlag, Meaning: "gzip", Example: "Extract a gzipped archive"},
+		{Token: "-v", Kind: KindFlag, Meaning: "verbosely", Example: "E[x]tract a (compressed) archive [f]ile into the current directory [v]erbosely"},
+		{Token: "-f", Kind: KindFlag, Meaning: "file", Example: "Extract a gzipped archive"},
+		{Token: "foo.tgz", Kind: KindArgument, Meaning: "path/to/source.tar.gz", Example: "Extract a gzipped archive"},
+	}
+	if !reflect.DeepEqual(explanation.Parts, expected) {
+		t.Errorf("Expected parts\n%+v\ngot\n%+v", expected, explanation.Parts)
+	}
+	if explanation.Closest == nil || explanation.Closest.Description != "Extract a gzipped archive" {
+		t.Errorf("Expected the gzip example to be closest, got %+v", explanation.Closest)
+	}
+}
+
+func TestExplainRedirects(t *testing.T) {
+	page := parseTar(t)
+	explanation := Explain(page, []string{"tar", "-tf", "a.tar", "2>&1", ">", "list.txt", "2>>err", "<in"}, 1)
+
+	expected := map[string]string{
+		"2>&1":       "send the output to another descriptor",
+		"> list.txt": "write the output to a file",
+		"2>>err":     "send errors to a file",
+		"<in":        "read the input from a file",
+	}
+	redirects := 0
+	for _, part := range explanation.Parts {
+		if part.Kind != KindRedirect {
+			continue
+		}
+		redirects++
+		if part.Meaning != expected[part.Token] {
+			t.Errorf("Expected %q to mean %q, got %q", part.Token, expected[part.Token], part.Meaning)
+		}
+	}
+	if redirects != len(expected) {
+		t.Errorf("Expected %d redirects, got %d", len(expected), redirects)
+	}
+}
+
+func TestExplainLongFlags(t *testing.T) {
+	page := parseTar(t)
+	explanation := Explain(page, []string{"tar", "--extract", "--file=a.tar", "--frobnicate"}, 1)
+
+	meanings := map[string]string{}
+	for _, part := range explanation.Parts {
+		meanings[part.Token] = part.Meaning
+	}
+	if meanings["--extract"] != "extract" {
+		t.Errorf("Expected --extract to mean extract, got %q", meanings["--extract"])
+	}
+	if meanings["--file=a.tar"] != "file" {
+		t.Errorf("Expected --file=a.tar to mean file, got %q", meanings["--file=a.tar"])
+	}
+	if meanings["--frobnicate"] != "not covered by this page" {
+		t.Errorf("Expected --frobnicate to be unknown, got %q", meanings["--frobnicate"])
+	}
+}
+
+func TestExplainVariadicArguments(t *testing.T) {
+	page := parseTar(t)
+	explanation := Explain(page, []string{"tar", "-czf", "out.tar.gz", "a", "b"}, 1)
+
+	if explanation.Closest == nil || explanation.Closest.Command != "tar czf {{path/to/target.tar.gz}} {{path/to/file1 path/to/file2 ...}}" {
+		t.Fatalf("Expected the create example to be closest, got %+v", explanation.Closest)
+	}
+
+	var meanings []string
+	for _, part := range explanation.Parts {
+		if part.Kind == KindArgument {
+			meanings = append(meanings, part.Meaning)
+		}
+	}
+	expected := []string{"path/to/target.tar.gz", "path/to/file1 path/to/file2 ...", "path/to/file1 path/to/file2 ..."}
+	if !reflect.DeepEqual(meanings, expected) {
+		t.Errorf("Expected %q, got %q", expected, meanings)
+	}
+}
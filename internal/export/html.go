@@ -0,0 +1,222 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// Segment is a piece of a command, either literal text or a placeholder
+type Segment struct {
+	Text        string
+	Placeholder bool
+}
+
+// Segments splits a command into literal text and {{placeholders}}
+func Segments(command string) []Segment {
+	var segments []Segment
+	rest := command
+	for {
+		start := strings.Index(rest, "{{")
+		end := strings.Index(rest, "}}")
+		if start < 0 || end < start {
+			break
+		}
+		if start > 0 {
+			segments = append(segments, Segment{Text: rest[:start]})
+		}
+		segments = append(segments, Segment{Text: rest[start : end+2], Placeholder: true})
+		rest = rest[end+2:]
+	}
+	if rest != "" {
+		segments = append(segments, Segment{Text: rest})
+	}
+	return segments
+}
+
+// searchEntry is what the client-side search knows about a page
+type searchEntry struct {
+	Name        string `json:"n"`
+	Platform    string `json:"p"`
+	Description string `json:"d"`
+	URL         string `json:"u"`
+}
+
+// pageURL returns the path of a page's HTML file relative to the site root
+func pageURL(page *types.Page) string {
+	return page.Platform + "/" + page.Name + ".html"
+}
+
+// HTML writes pages as a static site to dir: an index with client-side
+// fuzzy search and one HTML file per page, below a directory per platform.
+// The site works from the file system as well as from a web server.
+func HTML(dir, title string, pages []*types.Page) error {
+	pages = append([]*types.Page(nil), pages...)
+	sort.Slice(pages, func(i, j int) bool {
+		if pages[i].Name != pages[j].Name {
+			return pages[i].Name < pages[j].Name
+		}
+		return pages[i].Platform < pages[j].Platform
+	})
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	entries := make([]searchEntry, len(pages))
+	for i, page := range pages {
+		entries[i] = searchEntry{Name: page.Name, Platform: page.Platform, Description: page.Description, URL: pageURL(page)}
+
+		path := filepath.Join(dir, filepath.FromSlash(pageURL(page)))
+		if err := writeTemplate(path, pageTemplate, map[string]interface{}{"Title": title, "Page": page}); err != nil {
+			return err
+		}
+	}
+
+	index, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+	// A script rather than JSON, so that the index also loads from file://
+	script := "window.PAGES = " + string(index) + ";\n" + searchScript
+	if err := os.WriteFile(filepath.Join(dir, "search.js"), []byte(script), 0644); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "style.css"), []byte(styleSheet), 0644); err != nil {
+		return fmt.Errorf("failed to write style sheet: %w", err)
+	}
+
+	return writeTemplate(filepath.Join(dir, "index.html"), indexTemplate, map[string]interface{}{"Title": title, "Pages": pages})
+}
+
+// writeTemplate renders a template to path
+func writeTemplate(path string, tmpl *template.Template, data interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := tmpl.Execute(f, data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to render %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+var funcs = template.FuncMap{"segments": Segments}
+
+var pageTemplate = template.Must(template.New("page").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html lang="{{with .Page.Language}}{{.}}{{else}}en{{end}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Page.Name}} - {{.Title}}</title>
+<link rel="stylesheet" href="../style.css">
+</head>
+<body>
+<nav><a href="../index.html">{{.Title}}</a></nav>
+<main>
+<h1>{{.Page.Name}} <span class="platform">{{.Page.Platform}}</span></h1>
+{{with .Page.Description}}<p class="description">{{.}}</p>{{end}}
+{{with .Page.MoreInfoURL}}<p class="more">More information: <a href="{{.}}">{{.}}</a></p>{{end}}
+{{range .Page.Examples}}
+<section class="example">
+<p>{{.Description}}</p>
+<pre><code>{{range segments .Command}}{{if .Placeholder}}<var>{{.Text}}</var>{{else}}{{.Text}}{{end}}{{end}}</code></pre>
+</section>
+{{end}}
+{{with .Page.SeeAlso}}<p class="see-also">See also: {{range $i, $name := .}}{{if $i}}, {{end}}<code>{{$name}}</code>{{end}}</p>{{end}}
+</main>
+</body>
+</html>
+`))
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="style.css">
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+<input id="search" type="search" placeholder="Search {{len .Pages}} pages" autofocus>
+<ul id="results">
+{{range .Pages}}<li><a href="{{.Platform}}/{{.Name}}.html">{{.Name}}</a> <span class="platform">{{.Platform}}</span> <span class="description">{{.Description}}</span></li>
+{{end}}</ul>
+</main>
+<script src="search.js"></script>
+</body>
+</html>
+`))
+
+// searchScript ranks pages by a fuzzy match of the query against their
+// name, then their description, and redraws the result list
+const searchScript = `(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+
+  function fuzzy(query, text) {
+    text = text.toLowerCase();
+    var score = 0, last = -1;
+    for (var i = 0; i < query.length; i++) {
+      var found = text.indexOf(query[i], last + 1);
+      if (found < 0) return -1;
+      score += found === last + 1 ? 2 : 1;
+      last = found;
+    }
+    return score;
+  }
+
+  function escape(s) {
+    return s.replace(/[&<>"]/g, function (c) {
+      return {"&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;"}[c];
+    });
+  }
+
+  input.addEventListener("input", function () {
+    var query = input.value.trim().toLowerCase();
+    var matches = [];
+    PAGES.forEach(function (page) {
+      var score = query ? fuzzy(query, page.n) : 0;
+      if (score >= 0) {
+        score += page.n === query ? 100 : 0;
+      } else if (page.d.toLowerCase().indexOf(query) >= 0) {
+        score = 0;
+      } else {
+        return;
+      }
+      matches.push({page: page, score: score});
+    });
+    matches.sort(function (a, b) { return b.score - a.score || a.page.n.localeCompare(b.page.n); });
+    results.innerHTML = matches.slice(0, 200).map(function (m) {
+      return '<li><a href="' + escape(m.page.u) + '">' + escape(m.page.n) + '</a> <span class="platform">' +
+        escape(m.page.p) + '</span> <span class="description">' + escape(m.page.d) + '</span></li>';
+    }).join("");
+  });
+})();
+`
+
+const styleSheet = `body { font-family: system-ui, sans-serif; margin: 0; color: #222; background: #fafafa; }
+main { max-width: 50rem; margin: 0 auto; padding: 1rem; }
+nav { padding: .5rem 1rem; background: #222; }
+nav a { color: #fff; text-decoration: none; }
+h1 .platform, li .platform { font-size: .8rem; color: #777; }
+.description { color: #444; }
+.example p { margin-bottom: .25rem; color: #2a7a2a; }
+pre { background: #fff; border: 1px solid #ddd; border-radius: 4px; padding: .5rem .75rem; overflow-x: auto; }
+var { font-style: normal; color: #b35c00; }
+#search { width: 100%; font-size: 1.1rem; padding: .5rem; box-sizing: border-box; }
+#results { list-style: none; padding: 0; }
+#results li { padding: .25rem 0; }
+`
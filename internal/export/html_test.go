@@ -0,0 +1,73 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+func TestSegments(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected []Segment
+	}{
+		{"ls", []Segment{{Text: "ls"}}},
+		{"tar -xf {{file}}", []Segment{{Text: "tar -xf "}, {Text: "{{file}}", Placeholder: true}}},
+		{"{{cmd}} | wc -l", []Segment{{Text: "{{cmd}}", Placeholder: true}, {Text: " | wc -l"}}},
+		{"echo }} {{", []Segment{{Text: "echo }} {{"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if segments := Segments(tt.command); !reflect.DeepEqual(segments, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, segments)
+			}
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	dir := t.TempDir()
+	pages := []*types.Page{
+		{
+			Name:        "tar",
+			Platform:    "common",
+			Description: "Archiving utility",
+			Examples: []types.Example{
+				{Description: "Extract an archive", Command: "tar -xf {{source.tar}} <script>"},
+			},
+		},
+		{Name: "ls", Platform: "linux", Description: "List directory contents"},
+	}
+
+	if err := HTML(dir, "Team cheats", pages); err != nil {
+		t.Fatalf("HTML failed: %v", err)
+	}
+
+	for _, name := range []string{"index.html", "search.js", "style.css", "common/tar.html", "linux/ls.html"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+		}
+	}
+
+	page, _ := os.ReadFile(filepath.Join(dir, "common", "tar.html"))
+	if !strings.Contains(string(page), "<var>{{source.tar}}</var>") {
+		t.Error("Expected the placeholder to be marked up")
+	}
+	if strings.Contains(string(page), "<script>") {
+		t.Error("Expected the command to be escaped")
+	}
+
+	index, _ := os.ReadFile(filepath.Join(dir, "index.html"))
+	if strings.Index(string(index), "ls.html") > strings.Index(string(index), "tar.html") {
+		t.Error("Expected pages to be listed by name")
+	}
+	search, _ := os.ReadFile(filepath.Join(dir, "search.js"))
+	if !strings.Contains(string(search), `"u":"common/tar.html"`) {
+		t.Errorf("Expected the search index to link the page, got %s", search)
+	}
+}
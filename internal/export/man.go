@@ -0,0 +1,89 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// Man writes a page as a roff man page in section 1, for man -l or for
+// installing next to the system man pages. Commands are set in bold with
+// the placeholders as underlined arguments.
+func Man(w io.Writer, page *types.Page) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH %s 1 \"\" \"tldr++\" \"tldr pages (%s)\"\n", roffQuoted(strings.ToUpper(page.Name)), roffQuoted(page.Platform))
+	b.WriteString(".SH NAME\n")
+	if page.Description != "" {
+		fmt.Fprintf(&b, "%s \\- %s\n", roff(page.Name), roff(firstSentence(page.Description)))
+	} else {
+		b.WriteString(roff(page.Name) + "\n")
+	}
+
+	if page.Description != "" || page.MoreInfoURL != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		if page.Description != "" {
+			b.WriteString(roff(page.Description) + "\n")
+		}
+		if page.MoreInfoURL != "" {
+			b.WriteString(".PP\nMore information: " + roff(page.MoreInfoURL) + "\n")
+		}
+	}
+
+	if len(page.Examples) > 0 {
+		b.WriteString(".SH EXAMPLES\n")
+		for _, example := range page.Examples {
+			b.WriteString(".TP\n")
+			b.WriteString(roff(example.Description+":") + "\n")
+			b.WriteString(manCommand(example.Command) + "\n")
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write man page: %w", err)
+	}
+	return nil
+}
+
+// manCommand sets a command in bold, with the placeholders underlined and
+// their braces dropped
+func manCommand(command string) string {
+	var b strings.Builder
+	b.WriteString("\\fB")
+	for _, s := range Segments(command) {
+		if s.Placeholder {
+			b.WriteString("\\fI" + roffEscaper.Replace(strings.TrimSuffix(strings.TrimPrefix(s.Text, "{{"), "}}")) + "\\fB")
+		} else {
+			b.WriteString(roffEscaper.Replace(s.Text))
+		}
+	}
+	b.WriteString("\\fR")
+	return b.String()
+}
+
+// roffEscaper escapes the characters roff treats specially within a line
+var roffEscaper = strings.NewReplacer(`\`, `\e`, "-", `\-`)
+
+// roff escapes text for a roff text line
+func roff(text string) string {
+	text = roffEscaper.Replace(text)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
+
+// roffQuoted escapes text for a quoted argument of a roff request
+func roffQuoted(text string) string {
+	return strings.ReplaceAll(roff(text), `"`, `\(dq`)
+}
+
+// firstSentence returns the first sentence of a description, for the NAME
+// section
+func firstSentence(text string) string {
+	if i := strings.Index(text, ". "); i >= 0 {
+		return text[:i]
+	}
+	return strings.TrimSuffix(text, ".")
+}
@@ -0,0 +1,65 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+func TestMan(t *testing.T) {
+	page := &types.Page{
+		Name:        "tar",
+		Platform:    "common",
+		Description: "Archiving utility. Often combined with a compression method",
+		MoreInfoURL: "https://www.gnu.org/software/tar",
+		Examples: []types.Example{
+			{Description: "Extract an archive to a directory", Command: `tar -xf {{source.tar}} -C {{path/to/dir}}`},
+			{Description: "Print a backslash", Command: `.\tar`},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := Man(&out, page); err != nil {
+		t.Fatalf("Man failed: %v", err)
+	}
+
+	expected := `.TH TAR 1 "" "tldr++" "tldr pages (common)"
+.SH NAME
+tar \- Archiving utility
+.SH DESCRIPTION
+Archiving utility. Often combined with a compression method
+.PP
+More information: https://www.gnu.org/software/tar
+.SH EXAMPLES
+.TP
+Extract an archive to a directory:
+\fBtar \-xf \fIsource.tar\fB \-C \fIpath/to/dir\fB\fR
+.TP
+Print a backslash:
+\fB.\etar\fR
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestRoff(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"ls -la", `ls \-la`},
+		{`a\b`, `a\eb`},
+		{".hidden", `\&.hidden`},
+		{"'quoted'", `\&'quoted'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := roff(tt.text); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// Layouts lists the PDF layouts, by number of columns per sheet
+var Layouts = []string{"1col", "2col"}
+
+// A4 sheet geometry, in points
+const (
+	sheetWidth  = 595.0
+	sheetHeight = 842.0
+	sheetMargin = 40.0
+	columnGap   = 20.0
+	lineSpacing = 1.3
+)
+
+// The standard PDF fonts used, which every reader has built in
+const (
+	fontText        = "F1"
+	fontBold        = "F2"
+	fontCode        = "F3"
+	fontPlaceholder = "F4"
+)
+
+var fontNames = []struct{ id, name string }{
+	{fontText, "Helvetica"},
+	{fontBold, "Helvetica-Bold"},
+	{fontCode, "Courier"},
+	{fontPlaceholder, "Courier-Oblique"},
+}
+
+// helveticaWidths are the widths of the printable ASCII characters in
+// Helvetica, in thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// courierWidth is the width of every Courier character
+const courierWidth = 0.6
+
+// run is text set in a single font
+type run struct {
+	font string
+	text string
+}
+
+// textLine is a line of a cheat sheet; space is the extra room above it
+type textLine struct {
+	runs  []run
+	size  float64
+	gray  float64
+	space float64
+}
+
+func (l textLine) height() float64 {
+	return l.space + l.size*lineSpacing
+}
+
+// block is a group of lines kept together in one column
+type block []textLine
+
+func (b block) height() float64 {
+	var h float64
+	for _, line := range b {
+		h += line.height()
+	}
+	return h
+}
+
+// PDF writes pages as a printable cheat sheet on A4 sheets, laid out in one
+// or two columns. Pages follow each other in the columns, and no example is
+// split across columns.
+func PDF(w io.Writer, pages []*types.Page, layout string) error {
+	columns := 0
+	for i, name := range Layouts {
+		if layout == name {
+			columns = i + 1
+		}
+	}
+	if columns == 0 {
+		return fmt.Errorf("unknown layout %q (use %s)", layout, strings.Join(Layouts, ", "))
+	}
+
+	sheets := &sheetWriter{
+		columns: columns,
+		width:   (sheetWidth - 2*sheetMargin - float64(columns-1)*columnGap) / float64(columns),
+	}
+	for _, page := range pages {
+		for _, b := range pageBlocks(page, sheets.width) {
+			sheets.place(b)
+		}
+	}
+	return sheets.write(w)
+}
+
+// pageBlocks lays out a page in blocks of the given width, keeping the
+// title and description with the first example
+func pageBlocks(page *types.Page, width float64) []block {
+	// Measure the bold title as Helvetica a tenth wider
+	var header block
+	for i, line := range wrapText(page.Name, 14*1.1, width) {
+		header = append(header, textLine{runs: []run{{fontBold, line}}, size: 14})
+		if i == 0 {
+			header[0].space = 12
+		}
+	}
+	for _, line := range wrapText(page.Description, 9, width) {
+		header = append(header, textLine{runs: []run{{fontText, line}}, size: 9, gray: 0.3})
+	}
+
+	blocks := []block{header}
+	for i, example := range page.Examples {
+		var b block
+		for j, line := range wrapText(example.Description+":", 9, width) {
+			b = append(b, textLine{runs: []run{{fontText, line}}, size: 9})
+			if j == 0 {
+				b[0].space = 6
+			}
+		}
+		for _, runs := range wrapCommand(example.Command, 8, width-8) {
+			b = append(b, textLine{runs: runs, size: 8, space: 1})
+		}
+		if i == 0 {
+			blocks[0] = append(blocks[0], b...)
+		} else {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+// textWidth measures text set in Helvetica at size
+func textWidth(text string, size float64) float64 {
+	var w int
+	for _, r := range text {
+		if r >= ' ' && r <= '~' {
+			w += helveticaWidths[r-' ']
+		} else {
+			w += 556
+		}
+	}
+	return float64(w) * size / 1000
+}
+
+// wrapText breaks text set in Helvetica at size into lines that fit width
+func wrapText(text string, size, width float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line == "" {
+			line = word
+		} else if textWidth(line+" "+word, size) <= width {
+			line += " " + word
+		} else {
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// wrapCommand breaks a command set in Courier at size into lines that fit
+// width, with the placeholders in the oblique face. Lines are broken after a
+// space where possible.
+func wrapCommand(command string, size, width float64) [][]run {
+	perLine := int(width / (courierWidth * size))
+	if perLine < 1 {
+		perLine = 1
+	}
+
+	// Lay out characters, remembering each one's font
+	type char struct {
+		r    rune
+		font string
+	}
+	var chars []char
+	for _, s := range Segments(command) {
+		font := fontCode
+		if s.Placeholder {
+			font = fontPlaceholder
+		}
+		for _, r := range s.Text {
+			chars = append(chars, char{r, font})
+		}
+	}
+
+	var lines [][]run
+	for len(chars) > 0 {
+		n := len(chars)
+		if n > perLine {
+			n = perLine
+			for i := perLine; i > perLine/2; i-- {
+				if chars[i-1].r == ' ' {
+					n = i
+					break
+				}
+			}
+		}
+
+		var runs []run
+		for _, c := range chars[:n] {
+			if len(runs) > 0 && runs[len(runs)-1].font == c.font {
+				runs[len(runs)-1].text += string(c.r)
+			} else {
+				runs = append(runs, run{c.font, string(c.r)})
+			}
+		}
+		lines = append(lines, runs)
+		chars = chars[n:]
+	}
+	return lines
+}
+
+// sheetWriter places blocks in the columns of successive sheets and writes
+// the PDF
+type sheetWriter struct {
+	columns int
+	width   float64
+	sheets  []*bytes.Buffer
+	column  int
+	y       float64
+}
+
+// top is where the first line of a column goes
+const top = sheetHeight - sheetMargin
+
+// place adds a block, moving to the next column if it doesn't fit in this
+// one. Blocks taller than a column are split.
+func (s *sheetWriter) place(b block) {
+	if len(s.sheets) == 0 {
+		s.newSheet()
+	}
+	if s.y < top && s.y-b.height() < sheetMargin {
+		s.nextColumn()
+	}
+
+	x := sheetMargin + float64(s.column)*(s.width+columnGap)
+	for _, line := range b {
+		if s.y < top && s.y-line.height() < sheetMargin {
+			s.nextColumn()
+			x = sheetMargin + float64(s.column)*(s.width+columnGap)
+		}
+		if s.y < top {
+			s.y -= line.space
+		}
+		s.y -= line.size * lineSpacing
+
+		indent := 0.0
+		if line.runs[0].font == fontCode || line.runs[0].font == fontPlaceholder {
+			indent = 8
+		}
+		content := s.sheets[len(s.sheets)-1]
+		fmt.Fprintf(content, "BT %.2f g %.2f %.2f Td", line.gray, x+indent, s.y)
+		for _, r := range line.runs {
+			fmt.Fprintf(content, " /%s %.1f Tf (%s) Tj", r.font, line.size, pdfString(r.text))
+		}
+		content.WriteString(" ET\n")
+	}
+}
+
+func (s *sheetWriter) newSheet() {
+	s.sheets = append(s.sheets, &bytes.Buffer{})
+	s.column = 0
+	s.y = top
+}
+
+func (s *sheetWriter) nextColumn() {
+	if s.column+1 < s.columns {
+		s.column++
+		s.y = top
+		return
+	}
+	s.newSheet()
+}
+
+// write writes the sheets as a PDF document
+func (s *sheetWriter) write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(format string, args ...interface{}) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&out, format, args...)
+		out.WriteString("\nendobj\n")
+	}
+
+	// Objects: catalog, page tree, fonts, then a page and its content
+	// stream per sheet
+	firstSheet := 3 + len(fontNames)
+	var kids, fonts []string
+	for i := range s.sheets {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstSheet+2*i))
+	}
+	for i, font := range fontNames {
+		fonts = append(fonts, fmt.Sprintf("/%s %d 0 R", font.id, 3+i))
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(s.sheets))
+	for _, font := range fontNames {
+		object("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font.name)
+	}
+	for _, sheet := range s.sheets {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			sheetWidth, sheetHeight, strings.Join(fonts, " "), len(offsets)+2)
+		object("<< /Length %d >>\nstream\n%sendstream", sheet.Len(), sheet.Bytes())
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	if _, err := w.Write(out.Bytes()); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+// pdfString escapes text for a PDF string literal in WinAnsiEncoding.
+// Characters outside Latin-1 are replaced with '?'.
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r < ' ' || r > 0xff || (r >= 0x7f && r < 0xa0):
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+func TestPDF(t *testing.T) {
+	var examples []types.Example
+	for i := 0; i < 60; i++ {
+		examples = append(examples, types.Example{
+			Description: fmt.Sprintf("Example %d", i),
+			Command:     "tar -xf {{source.tar}} (verbose)",
+		})
+	}
+	pages := []*types.Page{{Name: "tar", Platform: "common", Description: "Archiving utility", Examples: examples}}
+
+	tests := []struct {
+		layout string
+		sheets int
+	}{
+		{"1col", 3},
+		{"2col", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := PDF(&out, pages, tt.layout); err != nil {
+				t.Fatalf("PDF failed: %v", err)
+			}
+
+			pdf := out.String()
+			if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+				t.Error("Expected a PDF header and trailer")
+			}
+			if !strings.Contains(pdf, "(tar) Tj") {
+				t.Error("Expected the title in the content")
+			}
+			if !strings.Contains(pdf, `/F4 8.0 Tf ({{source.tar}}) Tj`) {
+				t.Error("Expected the placeholder in the oblique face")
+			}
+			if !strings.Contains(pdf, `\(verbose\)`) {
+				t.Error("Expected parentheses to be escaped")
+			}
+			if count := fmt.Sprintf("/Count %d ", tt.sheets); !strings.Contains(pdf, count) {
+				t.Errorf("Expected %d sheets", tt.sheets)
+			}
+		})
+	}
+
+	if err := PDF(&bytes.Buffer{}, pages, "3col"); err == nil {
+		t.Error("Expected an error for an unknown layout")
+	}
+}
+
+func TestWrapCommand(t *testing.T) {
+	// 10 characters fit on a line
+	lines := wrapCommand("tar -xf {{source}} -C {{directory}}", 10, 60)
+	expected := [][]run{
+		{{fontCode, "tar -xf "}},
+		{{fontPlaceholder, "{{source}}"}},
+		{{fontCode, " -C "}, {fontPlaceholder, "{{dire"}},
+		{{fontPlaceholder, "ctory}}"}},
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected %v, got %v", expected, lines)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("Extract an archive to a directory", 10, 100)
+	if len(lines) < 2 {
+		t.Fatalf("Expected the text to wrap, got %v", lines)
+	}
+	for _, line := range lines {
+		if w := textWidth(line, 10); w > 100 {
+			t.Errorf("Expected '%s' to fit, got width %.1f", line, w)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// maxExecutions caps how many executions are kept in the log
+const maxExecutions = 200
+
+// Execution is a command rendered from a page and run
+type Execution struct {
+	Page     string `json:"page"`
+	Platform string `json:"platform"`
+	Command  string `json:"command"`
+	// Snippet is the snippet the command was run from, if any
+	Snippet string `json:"snippet,omitempty"`
+	// Template is the command as written on the page, with its
+	// placeholders, for filling it in again
+	Template string            `json:"template,omitempty"`
+	Vars     map[string]string `json:"vars,omitempty"`
+	Time     time.Time         `json:"time"`
+	// Redacted is set when secret values were masked in Command and Vars
+	Redacted bool `json:"redacted,omitempty"`
+	// Cast is the file the command's output was recorded to, if it was
+	Cast string `json:"cast,omitempty"`
+	// Dir is the directory the command was chosen to run in, empty for the
+	// one tldrpp was started in
+	Dir string `json:"dir,omitempty"`
+	// Sudo runs the command as root with sudo
+	Sudo bool `json:"sudo,omitempty"`
+	// Env sets environment variables for the command, as NAME=value, on
+	// top of those page_env sets for its page. It isn't kept in history.
+	Env []string `json:"-"`
+}
+
+// PageCount is how often commands from a page were run
+type PageCount struct {
+	Page     string
+	Platform string
+	Count    int
+}
+
+// Log is the history of executed commands, newest first
+type Log struct {
+	path       string
+	Executions []Execution `json:"executions"`
+}
+
+// LoadLog reads the execution log from path, returning an empty log if the
+// file does not exist yet
+func LoadLog(path string) (*Log, error) {
+	l := &Log{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return l, fmt.Errorf("failed to read execution history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, l); err != nil {
+		return l, fmt.Errorf("failed to parse execution history: %w", err)
+	}
+
+	return l, nil
+}
+
+// Add records an execution as the most recent one
+func (l *Log) Add(e Execution) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	l.Executions = append([]Execution{e}, l.Executions...)
+	if len(l.Executions) > maxExecutions {
+		l.Executions = l.Executions[:maxExecutions]
+	}
+}
+
+// Recent returns up to n of the most recent executions, newest first, with
+// repeated commands only listed once
+func (l *Log) Recent(n int) []Execution {
+	var recent []Execution
+	seen := make(map[string]bool)
+	for _, e := range l.Executions {
+		if len(recent) == n {
+			break
+		}
+		if seen[e.Command] {
+			continue
+		}
+		seen[e.Command] = true
+		recent = append(recent, e)
+	}
+	return recent
+}
+
+// RecentDirs returns up to n of the directories commands were chosen to
+// run in, most recently used first
+func (l *Log) RecentDirs(n int) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, e := range l.Executions {
+		if len(dirs) == n {
+			break
+		}
+		if e.Dir == "" || seen[e.Dir] {
+			continue
+		}
+		seen[e.Dir] = true
+		dirs = append(dirs, e.Dir)
+	}
+	return dirs
+}
+
+// Frequent returns up to n pages whose commands were run most often,
+// breaking ties by how recently they were used
+func (l *Log) Frequent(n int) []PageCount {
+	var counts []PageCount
+	positions := make(map[string]int)
+	for _, e := range l.Executions {
+		key := e.Platform + "/" + e.Page
+		if i, ok := positions[key]; ok {
+			counts[i].Count++
+			continue
+		}
+		positions[key] = len(counts)
+		counts = append(counts, PageCount{Page: e.Page, Platform: e.Platform, Count: 1})
+	}
+
+	// Stable sort keeps the most recently used first among equal counts
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].Count > counts[j].Count
+	})
+	if len(counts) > n {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+// CoOccurring counts, for every other page, how many of its commands were
+// run within window of a command from page, as a measure of which tools
+// are used together
+func (l *Log) CoOccurring(page string, window time.Duration) map[string]int {
+	counts := make(map[string]int)
+	for _, e := range l.Executions {
+		if e.Page != page {
+			continue
+		}
+		for _, other := range l.Executions {
+			if other.Page == page {
+				continue
+			}
+			if d := other.Time.Sub(e.Time); d <= window && d >= -window {
+				counts[other.Page]++
+			}
+		}
+	}
+	return counts
+}
+
+// Save writes the execution log back to disk
+func (l *Log) Save() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode execution history: %w", err)
+	}
+
+	return os.WriteFile(l.path, data, 0644)
+}
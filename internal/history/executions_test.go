@@ -0,0 +1,113 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecentSkipsRepeatedCommands(t *testing.T) {
+	l, err := LoadLog(filepath.Join(t.TempDir(), "executions.json"))
+	if err != nil {
+		t.Fatalf("LoadLog failed: %v", err)
+	}
+
+	l.Add(Execution{Page: "tar", Platform: "common", Command: "tar -xf a.tar"})
+	l.Add(Execution{Page: "ls", Platform: "common", Command: "ls -la"})
+	l.Add(Execution{Page: "tar", Platform: "common", Command: "tar -xf a.tar"})
+
+	recent := l.Recent(5)
+	if len(recent) != 2 {
+		t.Fatalf("Expected 2 recent commands, got %v", recent)
+	}
+	if recent[0].Command != "tar -xf a.tar" || recent[1].Command != "ls -la" {
+		t.Errorf("Expected [tar -xf a.tar, ls -la], got %v", recent)
+	}
+
+	if got := len(l.Recent(1)); got != 1 {
+		t.Errorf("Expected 1 command, got %d", got)
+	}
+}
+
+func TestRecentDirs(t *testing.T) {
+	l, _ := LoadLog(filepath.Join(t.TempDir(), "executions.json"))
+	l.Add(Execution{Page: "make", Command: "make", Dir: "/src/a"})
+	l.Add(Execution{Page: "ls", Command: "ls"})
+	l.Add(Execution{Page: "make", Command: "make test", Dir: "/src/b"})
+	l.Add(Execution{Page: "git", Command: "git status", Dir: "/src/a"})
+
+	if dirs := l.RecentDirs(5); !reflect.DeepEqual(dirs, []string{"/src/a", "/src/b"}) {
+		t.Errorf("Expected [/src/a /src/b], got %v", dirs)
+	}
+	if dirs := l.RecentDirs(1); len(dirs) != 1 {
+		t.Errorf("Expected 1 directory, got %v", dirs)
+	}
+}
+
+func TestFrequent(t *testing.T) {
+	l, _ := LoadLog(filepath.Join(t.TempDir(), "executions.json"))
+
+	l.Add(Execution{Page: "git", Platform: "common", Command: "git status"})
+	l.Add(Execution{Page: "tar", Platform: "common", Command: "tar -xf a.tar"})
+	l.Add(Execution{Page: "git", Platform: "common", Command: "git log"})
+	l.Add(Execution{Page: "ls", Platform: "common", Command: "ls"})
+
+	frequent := l.Frequent(2)
+	if len(frequent) != 2 {
+		t.Fatalf("Expected 2 pages, got %v", frequent)
+	}
+	if frequent[0].Page != "git" || frequent[0].Count != 2 {
+		t.Errorf("Expected git used twice first, got %+v", frequent[0])
+	}
+	if frequent[1].Page != "ls" {
+		t.Errorf("Expected the most recent of the rest, got %+v", frequent[1])
+	}
+}
+
+func TestCoOccurring(t *testing.T) {
+	l, _ := LoadLog(filepath.Join(t.TempDir(), "executions.json"))
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.Add(Execution{Page: "tar", Command: "tar -cf a.tar src", Time: start})
+	l.Add(Execution{Page: "gzip", Command: "gzip a.tar", Time: start.Add(time.Minute)})
+	l.Add(Execution{Page: "zstd", Command: "zstd a.tar", Time: start.Add(-2 * time.Minute)})
+	l.Add(Execution{Page: "ls", Command: "ls", Time: start.Add(time.Hour)})
+	l.Add(Execution{Page: "tar", Command: "tar -tf a.tar", Time: start.Add(3 * time.Minute)})
+
+	expected := map[string]int{"gzip": 2, "zstd": 1}
+	if counts := l.CoOccurring("tar", 3*time.Minute); !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+}
+
+func TestLogCapsExecutions(t *testing.T) {
+	l, _ := LoadLog(filepath.Join(t.TempDir(), "executions.json"))
+
+	for i := 0; i < maxExecutions+5; i++ {
+		l.Add(Execution{Page: "ls", Command: "ls"})
+	}
+
+	if got := len(l.Executions); got != maxExecutions {
+		t.Errorf("Expected %d executions, got %d", maxExecutions, got)
+	}
+}
+
+func TestLogSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "executions.json")
+
+	l, _ := LoadLog(path)
+	l.Add(Execution{Page: "ssh", Platform: "common", Command: "ssh example.com", Vars: map[string]string{"host": "example.com"}})
+	if err := l.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadLog(path)
+	if err != nil {
+		t.Fatalf("LoadLog failed: %v", err)
+	}
+	recent := loaded.Recent(1)
+	if len(recent) != 1 || recent[0].Vars["host"] != "example.com" {
+		t.Errorf("Expected ssh execution with its placeholders, got %v", recent)
+	}
+}
@@ -0,0 +1,87 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// maxValuesPerPlaceholder caps how many recent values are kept per name
+const maxValuesPerPlaceholder = 10
+
+// Memory remembers the values most recently used for each placeholder name
+type Memory struct {
+	path   string
+	Values map[string][]string `json:"values"`
+}
+
+// Load reads placeholder memory from path, returning an empty memory if the
+// file does not exist yet
+func Load(path string) (*Memory, error) {
+	m := &Memory{
+		path:   path,
+		Values: make(map[string][]string),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return m, fmt.Errorf("failed to read placeholder memory: %w", err)
+	}
+
+	if err := json.Unmarshal(data, m); err != nil {
+		return m, fmt.Errorf("failed to parse placeholder memory: %w", err)
+	}
+	if m.Values == nil {
+		m.Values = make(map[string][]string)
+	}
+
+	return m, nil
+}
+
+// Last returns the most recently used value for a placeholder, or ""
+func (m *Memory) Last(name string) string {
+	if values := m.Values[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Recent returns the recently used values for a placeholder, newest first
+func (m *Memory) Recent(name string) []string {
+	return m.Values[name]
+}
+
+// Remember records vars as the most recent values of their placeholders
+func (m *Memory) Remember(vars map[string]string) {
+	for name, value := range vars {
+		if value == "" {
+			continue
+		}
+
+		values := []string{value}
+		for _, v := range m.Values[name] {
+			if v != value && len(values) < maxValuesPerPlaceholder {
+				values = append(values, v)
+			}
+		}
+		m.Values[name] = values
+	}
+}
+
+// Save writes placeholder memory back to disk
+func (m *Memory) Save() error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode placeholder memory: %w", err)
+	}
+
+	return os.WriteFile(m.path, data, 0644)
+}
@@ -0,0 +1,60 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRemember(t *testing.T) {
+	m, err := Load(filepath.Join(t.TempDir(), "placeholders.json"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	m.Remember(map[string]string{"file": "a.tar"})
+	m.Remember(map[string]string{"file": "b.tar", "dest": ""})
+	m.Remember(map[string]string{"file": "a.tar"})
+
+	if got := m.Last("file"); got != "a.tar" {
+		t.Errorf("Expected last value 'a.tar', got '%s'", got)
+	}
+
+	recent := m.Recent("file")
+	if len(recent) != 2 || recent[1] != "b.tar" {
+		t.Errorf("Expected [a.tar b.tar], got %v", recent)
+	}
+
+	if got := m.Last("dest"); got != "" {
+		t.Errorf("Expected empty values to be skipped, got '%s'", got)
+	}
+}
+
+func TestRememberCapsValues(t *testing.T) {
+	m, _ := Load(filepath.Join(t.TempDir(), "placeholders.json"))
+
+	for i := 0; i < maxValuesPerPlaceholder+5; i++ {
+		m.Remember(map[string]string{"port": string(rune('a' + i))})
+	}
+
+	if got := len(m.Recent("port")); got != maxValuesPerPlaceholder {
+		t.Errorf("Expected %d values, got %d", maxValuesPerPlaceholder, got)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "placeholders.json")
+
+	m, _ := Load(path)
+	m.Remember(map[string]string{"host": "example.com"})
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := loaded.Last("host"); got != "example.com" {
+		t.Errorf("Expected 'example.com', got '%s'", got)
+	}
+}
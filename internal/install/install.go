@@ -0,0 +1,82 @@
+// Package install tells whether the command of a page is installed and,
+// when it isn't, how to install it with the system's package manager.
+package install
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// lookPath finds a program on PATH; tests replace it
+var lookPath = exec.LookPath
+
+// Manager is a package manager and the command line that installs a
+// package with it
+type Manager struct {
+	Name string
+	// Install is the command installing packages, given after it
+	Install string
+}
+
+// managers are the package managers looked for on each system, preferred
+// first
+var managers = map[string][]Manager{
+	"linux": {
+		{"apt", "sudo apt install"},
+		{"dnf", "sudo dnf install"},
+		{"pacman", "sudo pacman -S"},
+	},
+	"darwin":  {{"brew", "brew install"}},
+	"windows": {{"choco", "choco install"}},
+}
+
+// packages maps commands to the packages providing them where the names
+// differ, by package manager; the "" entry holds for the others
+var packages = map[string]map[string]string{
+	"7z":      {"apt": "p7zip-full", "dnf": "p7zip", "pacman": "p7zip", "brew": "p7zip", "": "7zip"},
+	"ag":      {"apt": "silversearcher-ag", "": "the_silver_searcher"},
+	"convert": {"": "imagemagick"},
+	"delta":   {"": "git-delta"},
+	"dig":     {"apt": "dnsutils", "dnf": "bind-utils", "pacman": "bind", "": "bind"},
+	"fd":      {"apt": "fd-find", "": "fd"},
+	"http":    {"": "httpie"},
+	"kubectl": {"apt": "kubernetes-client", "brew": "kubernetes-cli", "choco": "kubernetes-cli", "": "kubectl"},
+	"magick":  {"": "imagemagick"},
+	"nc":      {"apt": "netcat-openbsd", "pacman": "openbsd-netcat", "brew": "netcat", "": "nmap-ncat"},
+	"nvim":    {"": "neovim"},
+	"pip":     {"apt": "python3-pip", "dnf": "python3-pip", "pacman": "python-pip", "": "python"},
+	"python":  {"apt": "python3", "dnf": "python3", "": "python"},
+	"rg":      {"": "ripgrep"},
+}
+
+// Installed reports whether tool is on PATH
+func Installed(tool string) bool {
+	_, err := lookPath(tool)
+	return err == nil
+}
+
+// Detect returns the first package manager found on the running system, or
+// nil if there is none known
+func Detect() *Manager {
+	for _, m := range managers[runtime.GOOS] {
+		if _, err := lookPath(m.Name); err == nil {
+			m := m
+			return &m
+		}
+	}
+	return nil
+}
+
+// Command returns the command line installing tool with m: the package is
+// looked up for the tool, or assumed to be named like it
+func (m *Manager) Command(tool string) string {
+	pkg := tool
+	if names, ok := packages[tool]; ok {
+		if name, ok := names[m.Name]; ok {
+			pkg = name
+		} else if name, ok := names[""]; ok {
+			pkg = name
+		}
+	}
+	return m.Install + " " + pkg
+}
@@ -0,0 +1,69 @@
+package install
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+)
+
+// fakePath makes lookPath find only the given programs
+func fakePath(t *testing.T, programs ...string) {
+	t.Helper()
+	old := lookPath
+	t.Cleanup(func() { lookPath = old })
+	lookPath = func(name string) (string, error) {
+		for _, program := range programs {
+			if name == program {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestInstalled(t *testing.T) {
+	fakePath(t, "git")
+	if !Installed("git") {
+		t.Error("expected git to be installed")
+	}
+	if Installed("rg") {
+		t.Error("expected rg not to be installed")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	known := managers[runtime.GOOS]
+	if len(known) == 0 {
+		t.Skip("no package managers known for " + runtime.GOOS)
+	}
+	fakePath(t)
+	if m := Detect(); m != nil {
+		t.Errorf("expected no package manager, got %s", m.Name)
+	}
+
+	last := known[len(known)-1]
+	fakePath(t, last.Name)
+	if m := Detect(); m == nil || m.Name != last.Name {
+		t.Errorf("Detect = %v, want %s", m, last.Name)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	apt := &Manager{"apt", "sudo apt install"}
+	brew := &Manager{"brew", "brew install"}
+	tests := []struct {
+		m    *Manager
+		tool string
+		want string
+	}{
+		{apt, "fd", "sudo apt install fd-find"},
+		{brew, "fd", "brew install fd"},
+		{brew, "rg", "brew install ripgrep"},
+		{apt, "jq", "sudo apt install jq"},
+	}
+	for _, test := range tests {
+		if got := test.m.Command(test.tool); got != test.want {
+			t.Errorf("%s Command(%s) = %q, want %q", test.m.Name, test.tool, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package locale
+
+import (
+	"os"
+	"strings"
+)
+
+// English is the language every page exists in
+const English = "en"
+
+// Languages returns the page languages the user prefers, most preferred
+// first and always ending with English, from the environment
+func Languages() []string {
+	return languages(os.Getenv)
+}
+
+// Parse returns the page languages for a language tag such as "pt_BR" or
+// "de_DE.UTF-8", followed by English
+func Parse(tag string) []string {
+	return dedupe(append(candidates(tag), English))
+}
+
+// languages follows the tldr client specification: LANGUAGE lists
+// languages in order of preference, then comes the locale itself. A C or
+// POSIX locale means English only. LC_ALL and LC_MESSAGES override LANG as
+// they do for other programs.
+func languages(getenv func(string) string) []string {
+	locale := getenv("LC_ALL")
+	if locale == "" {
+		locale = getenv("LC_MESSAGES")
+	}
+	if locale == "" {
+		locale = getenv("LANG")
+	}
+	if locale == "" || locale == "C" || locale == "POSIX" || strings.HasPrefix(locale, "C.") {
+		return []string{English}
+	}
+
+	var langs []string
+	for _, tag := range strings.Split(getenv("LANGUAGE"), ":") {
+		langs = append(langs, candidates(tag)...)
+	}
+	langs = append(langs, candidates(locale)...)
+	return dedupe(append(langs, English))
+}
+
+// candidates maps a locale such as pt_BR.UTF-8 to the page languages that
+// may match it, the regional one first: pt_BR, pt
+func candidates(tag string) []string {
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	tag = strings.ReplaceAll(tag, "-", "_")
+	if tag == "" || tag == "C" || tag == "POSIX" {
+		return nil
+	}
+
+	lang, region, found := strings.Cut(tag, "_")
+	lang = strings.ToLower(lang)
+	if !found {
+		return []string{lang}
+	}
+	return []string{lang + "_" + strings.ToUpper(region), lang}
+}
+
+// dedupe removes repeated languages, keeping the first of each
+func dedupe(langs []string) []string {
+	seen := make(map[string]bool, len(langs))
+	result := langs[:0]
+	for _, lang := range langs {
+		if !seen[lang] {
+			seen[lang] = true
+			result = append(result, lang)
+		}
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package locale
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLanguages(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected []string
+	}{
+		{"unset", nil, []string{"en"}},
+		{"C locale", map[string]string{"LANG": "C.UTF-8", "LANGUAGE": "de"}, []string{"en"}},
+		{"lang", map[string]string{"LANG": "de_DE.UTF-8"}, []string{"de_DE", "de", "en"}},
+		{"region kept", map[string]string{"LANG": "pt_BR.UTF-8"}, []string{"pt_BR", "pt", "en"}},
+		{"language list", map[string]string{"LANG": "en_US.UTF-8", "LANGUAGE": "fr:es"}, []string{"fr", "es", "en_US", "en"}},
+		{"lc_all wins", map[string]string{"LANG": "de_DE.UTF-8", "LC_ALL": "it_IT.UTF-8"}, []string{"it_IT", "it", "en"}},
+		{"lc_messages", map[string]string{"LANG": "de_DE.UTF-8", "LC_MESSAGES": "ko_KR"}, []string{"ko_KR", "ko", "en"}},
+		{"modifier", map[string]string{"LANG": "ca_ES@valencia"}, []string{"ca_ES", "ca", "en"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if langs := languages(getenv); !reflect.DeepEqual(langs, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, langs)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected []string
+	}{
+		{"de", []string{"de", "en"}},
+		{"zh-tw", []string{"zh_TW", "zh", "en"}},
+		{"en", []string{"en"}},
+		{"", []string{"en"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if langs := Parse(tt.tag); !reflect.DeepEqual(langs, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, langs)
+			}
+		})
+	}
+}
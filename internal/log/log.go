@@ -0,0 +1,89 @@
+// Package log writes a structured diagnostic log of what tldr++ does, such
+// as cache updates, search scoring and key presses, to find out why
+// something behaves oddly. It is silent unless Setup turns it on.
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// levelOff is above every level, so nothing is logged
+const levelOff = slog.Level(100)
+
+var (
+	level  = new(slog.LevelVar)
+	logger = newLogger(io.Discard)
+)
+
+func init() {
+	level.Set(levelOff)
+}
+
+// Options configure the log
+type Options struct {
+	// Level is the least severe level logged, e.g. slog.LevelDebug to
+	// include search scores and key presses
+	Level slog.Level
+	// File is appended to instead of writing to stderr, if set
+	File string
+}
+
+// Setup turns the log on
+func Setup(opts Options) error {
+	var w io.Writer = os.Stderr
+	if opts.File != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.File), 0755); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+		// Logged commands may carry secrets, so only the user can read the log
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		if err := file.Chmod(0600); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to restrict log file: %w", err)
+		}
+		w = file
+	}
+
+	logger = newLogger(w)
+	level.Set(opts.Level)
+	return nil
+}
+
+// newLogger returns a logger writing text records to w at the current level
+func newLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// Enabled reports whether messages at l are logged, to skip working out
+// what to log when they aren't
+func Enabled(l slog.Level) bool {
+	return logger.Enabled(context.Background(), l)
+}
+
+// Debug logs a detail only of interest when debugging, such as a score
+func Debug(msg string, args ...interface{}) {
+	logger.Debug(msg, args...)
+}
+
+// Info logs an operation, such as a cache update
+func Info(msg string, args ...interface{}) {
+	logger.Info(msg, args...)
+}
+
+// Warn logs a problem that was worked around
+func Warn(msg string, args ...interface{}) {
+	logger.Warn(msg, args...)
+}
+
+// Error logs a failure
+func Error(msg string, args ...interface{}) {
+	logger.Error(msg, args...)
+}
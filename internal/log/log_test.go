@@ -0,0 +1,82 @@
+package log
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSilentByDefault(t *testing.T) {
+	if Enabled(slog.LevelError) {
+		t.Error("Expected the log to be off until Setup")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	defer level.Set(levelOff)
+
+	tests := []struct {
+		name     string
+		level    slog.Level
+		expected []string
+		missing  []string
+	}{
+		{"info", slog.LevelInfo, []string{"msg=updating", "msg=failed"}, []string{"msg=scored"}},
+		{"debug", slog.LevelDebug, []string{"msg=scored", "score=42", "msg=updating"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "logs", "tldrpp.log")
+			if err := Setup(Options{Level: tt.level, File: file}); err != nil {
+				t.Fatalf("Setup failed: %v", err)
+			}
+
+			Debug("scored", "page", "tar", "score", 42)
+			Info("updating", "source", "official")
+			Error("failed", "err", "timeout")
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("ReadFile failed: %v", err)
+			}
+			for _, text := range tt.expected {
+				if !strings.Contains(string(data), text) {
+					t.Errorf("Expected %q in the log, got %q", text, data)
+				}
+			}
+			for _, text := range tt.missing {
+				if strings.Contains(string(data), text) {
+					t.Errorf("Expected no %q in the log, got %q", text, data)
+				}
+			}
+		})
+	}
+}
+
+func TestSetupRestrictsLogFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions are not used on Windows")
+	}
+	defer level.Set(levelOff)
+
+	// An existing log readable by others is restricted too
+	file := filepath.Join(t.TempDir(), "tldrpp.log")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := Setup(Options{Level: slog.LevelInfo, File: file}); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected permissions 0600, got %v", info.Mode().Perm())
+	}
+}
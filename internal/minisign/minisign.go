@@ -0,0 +1,133 @@
+// Package minisign verifies detached minisign signatures, as made by
+// minisign -S or rsign, with Ed25519 over the file or its BLAKE2b-512 hash.
+package minisign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Signature algorithms: Ed25519 over the file, or over its BLAKE2b-512
+// hash, which minisign uses by default
+const (
+	algPure      = "Ed"
+	algPrehashed = "ED"
+)
+
+// PublicKey is a minisign public key
+type PublicKey struct {
+	ID  [8]byte
+	Key ed25519.PublicKey
+}
+
+// ParsePublicKey reads a public key as minisign prints it, the base64 line
+// alone or the whole .pub file with its comment
+func ParsePublicKey(text string) (*PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(lastLine(text))
+	if err != nil || len(data) != 2+8+ed25519.PublicKeySize || string(data[:2]) != algPure {
+		return nil, fmt.Errorf("invalid minisign public key")
+	}
+	key := &PublicKey{Key: ed25519.PublicKey(data[10:])}
+	copy(key.ID[:], data[2:10])
+	return key, nil
+}
+
+// String returns the key ID as minisign shows it
+func (k *PublicKey) String() string {
+	id := make([]byte, len(k.ID))
+	for i := range k.ID {
+		id[i] = k.ID[len(k.ID)-1-i]
+	}
+	return strings.ToUpper(hex.EncodeToString(id))
+}
+
+// lastLine returns the last non-empty line of text, trimmed
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// Signature is a parsed .minisig file
+type Signature struct {
+	Algorithm string
+	KeyID     [8]byte
+	Signature []byte
+	// TrustedComment is signed along with the signature
+	TrustedComment  string
+	GlobalSignature []byte
+}
+
+// ParseSignature reads a .minisig file: an untrusted comment, the
+// signature, a trusted comment and the signature over both
+func ParseSignature(text string) (*Signature, error) {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "untrusted comment:") {
+		return nil, fmt.Errorf("invalid minisign signature: expected 4 lines")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid minisign signature")
+	}
+	comment, ok := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !ok {
+		return nil, fmt.Errorf("invalid minisign signature: no trusted comment")
+	}
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid minisign signature: bad global signature")
+	}
+
+	s := &Signature{
+		Algorithm:       string(sig[:2]),
+		Signature:       sig[10:],
+		TrustedComment:  comment,
+		GlobalSignature: global,
+	}
+	copy(s.KeyID[:], sig[2:10])
+	if s.Algorithm != algPure && s.Algorithm != algPrehashed {
+		return nil, fmt.Errorf("unsupported minisign signature algorithm %q", s.Algorithm)
+	}
+	return s, nil
+}
+
+// Verify checks that sig signs the content read from r with key, and that
+// its trusted comment wasn't changed
+func Verify(key *PublicKey, sig *Signature, r io.Reader) error {
+	if sig.KeyID != key.ID {
+		return fmt.Errorf("signed with another key than %s", key)
+	}
+
+	var message []byte
+	if sig.Algorithm == algPrehashed {
+		h := newBlake2b()
+		if _, err := io.Copy(h, r); err != nil {
+			return fmt.Errorf("failed to read signed file: %w", err)
+		}
+		message = h.Sum(nil)
+	} else {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("failed to read signed file: %w", err)
+		}
+		message = data
+	}
+	if !ed25519.Verify(key.Key, message, sig.Signature) {
+		return fmt.Errorf("signature doesn't match key %s", key)
+	}
+
+	global := append(append([]byte(nil), sig.Signature...), sig.TrustedComment...)
+	if !ed25519.Verify(key.Key, global, sig.GlobalSignature) {
+		return fmt.Errorf("trusted comment of the signature was altered")
+	}
+	return nil
+}
+
+// VerifyBytes is Verify for content in memory
+func VerifyBytes(key *PublicKey, sig *Signature, data []byte) error {
+	return Verify(key, sig, bytes.NewReader(data))
+}
@@ -0,0 +1,107 @@
+package minisign
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestBlake2b(t *testing.T) {
+	long := make([]byte, 0, 1024)
+	for i := 0; i < 4; i++ {
+		for b := 0; b < 256; b++ {
+			long = append(long, byte(b))
+		}
+	}
+	tests := []struct {
+		input []byte
+		want  string
+	}{
+		{nil, "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce"},
+		// RFC 7693, appendix A
+		{[]byte("abc"), "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"},
+		// Exactly one block, held back until the end
+		{[]byte(strings.Repeat("a", 128)), "fc6c71f688f43ea7d60817478808f3cac753e61571865c95adbc2d9122c943a76b92c2cb1047ef3fe7bf6e436ec1d0a99a9e5b216780bf7fed9d7ca91d3a8f3b"},
+		{long, "6b490f42e902f61b1ee12d3c85e34152e37c94d07ab9ea577cad6a6eb4690fad38064f53a19c225703a5c52cdc9a85add71b339d327e1630ee3432b920240e8a"},
+	}
+	for _, tt := range tests {
+		h := newBlake2b()
+		// Written in uneven pieces to cross block boundaries
+		for data := tt.input; len(data) > 0; {
+			n := min(len(data), 37)
+			h.Write(data[:n])
+			data = data[n:]
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.want {
+			t.Errorf("blake2b(%d bytes) = %s, want %s", len(tt.input), got, tt.want)
+		}
+	}
+}
+
+// sign makes a .minisig file the way minisign does
+func sign(priv ed25519.PrivateKey, id []byte, alg string, data []byte, comment string) string {
+	message := data
+	if alg == algPrehashed {
+		h := newBlake2b()
+		h.Write(data)
+		message = h.Sum(nil)
+	}
+	sig := ed25519.Sign(priv, message)
+	global := ed25519.Sign(priv, append(append([]byte(nil), sig...), comment...))
+	blob := append(append([]byte(alg), id...), sig...)
+	return "untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(blob) + "\n" +
+		"trusted comment: " + comment + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n"
+}
+
+func TestVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	keyText := "untrusted comment: minisign public key 0807060504030201\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte(algPure), id...), pub...)) + "\n"
+	key, err := ParsePublicKey(keyText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.String() != "0807060504030201" {
+		t.Errorf("key ID = %s", key)
+	}
+
+	data := []byte("pages archive")
+	for _, alg := range []string{algPure, algPrehashed} {
+		sig, err := ParseSignature(sign(priv, id, alg, data, "timestamp:1700000000\tfile:tldr.zip"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyBytes(key, sig, data); err != nil {
+			t.Errorf("%s: %v", alg, err)
+		}
+		if err := VerifyBytes(key, sig, []byte("tampered archive")); err == nil {
+			t.Errorf("%s: expected a changed file to fail", alg)
+		}
+		sig.TrustedComment = "timestamp:1800000000"
+		if err := VerifyBytes(key, sig, data); err == nil {
+			t.Errorf("%s: expected a changed trusted comment to fail", alg)
+		}
+	}
+
+	other, _ := ParseSignature(sign(priv, []byte{9, 9, 9, 9, 9, 9, 9, 9}, algPrehashed, data, "x"))
+	if err := VerifyBytes(key, other, data); err == nil {
+		t.Error("expected a signature by another key ID to fail")
+	}
+
+	for _, text := range []string{"", "untrusted comment: x\nnot base64\ntrusted comment: y\nAAAA\n"} {
+		if _, err := ParseSignature(text); err == nil {
+			t.Errorf("expected %q to be rejected", text)
+		}
+	}
+	if _, err := ParsePublicKey("RWQ-not-a-key"); err == nil {
+		t.Error("expected an invalid public key to be rejected")
+	}
+}
@@ -0,0 +1,159 @@
+// Package network builds the HTTP transport downloads go through, with the
+// proxy and TLS settings of the config, and explains the errors corporate
+// proxies cause.
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/config"
+)
+
+// Transport returns the transport for downloads: through the configured
+// proxy, or the one in HTTPS_PROXY, HTTP_PROXY and NO_PROXY, trusting the
+// configured CA bundle on top of the system's and presenting the client
+// certificate if any. file:// URLs read local files.
+func Transport(cfg config.Network) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+
+	if proxy := os.ExpandEnv(cfg.Proxy); proxy != "" {
+		proxyURL, err := ParseProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := TLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// ParseProxy checks a proxy URL; a bare host:port means an HTTP proxy
+func ParseProxy(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q, expected e.g. http://proxy.example.com:3128", proxy)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy %q: unsupported scheme %s", proxy, proxyURL.Scheme)
+	}
+	return proxyURL, nil
+}
+
+// TLSConfig returns the TLS settings of cfg, or nil when it has none and
+// the defaults apply
+func TLSConfig(cfg config.Network) (*tls.Config, error) {
+	caFile := os.ExpandEnv(cfg.CAFile)
+	certFile := os.ExpandEnv(cfg.ClientCert)
+	keyFile := os.ExpandEnv(cfg.ClientKey)
+	if caFile == "" && certFile == "" {
+		if keyFile != "" {
+			return nil, fmt.Errorf("network.client_key is set without network.client_cert")
+		}
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no PEM certificates in CA bundle %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if certFile != "" {
+		if keyFile == "" {
+			keyFile = certFile
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// GitEnv returns the environment giving git the same proxy and TLS settings
+// as the transport. Without a configured proxy git reads the proxy
+// environment itself.
+func GitEnv(cfg config.Network) []string {
+	var env []string
+	if proxy := os.ExpandEnv(cfg.Proxy); proxy != "" {
+		env = append(env, "http_proxy="+proxy, "https_proxy="+proxy)
+	}
+	if caFile := os.ExpandEnv(cfg.CAFile); caFile != "" {
+		env = append(env, "GIT_SSL_CAINFO="+caFile)
+	}
+	if certFile := os.ExpandEnv(cfg.ClientCert); certFile != "" {
+		keyFile := os.ExpandEnv(cfg.ClientKey)
+		if keyFile == "" {
+			keyFile = certFile
+		}
+		env = append(env, "GIT_SSL_CERT="+certFile, "GIT_SSL_KEY="+keyFile)
+	}
+	return env
+}
+
+// Explain adds what to do to an error a proxy or TLS interception causes,
+// such as a certificate signed by an unknown authority. Other errors are
+// returned as they are.
+func Explain(err error) error {
+	if err == nil {
+		return nil
+	}
+	if hint := Hint(err); hint != "" {
+		return fmt.Errorf("%w\n%s", err, hint)
+	}
+	return err
+}
+
+// Hint returns what to do about err, or "" if it isn't a network problem
+// the config can solve
+func Hint(err error) string {
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return "The server's certificate isn't signed by an authority this machine trusts. " +
+			"Behind a proxy that intercepts TLS, set network.ca_file to the proxy's CA bundle " +
+			"(your IT department has it): tldrpp config set network.ca_file /path/to/ca.pem"
+	case errors.As(err, &invalid):
+		return "The server's certificate is invalid or expired. " +
+			"If a proxy intercepts TLS, check the clock and that network.ca_file holds its current CA."
+	case errors.As(err, &hostname):
+		return "The certificate is for another host, which happens when a proxy intercepts TLS. " +
+			"Set network.ca_file to the proxy's CA bundle, or check network.proxy."
+	case strings.Contains(err.Error(), "tls: certificate required"),
+		strings.Contains(err.Error(), "tls: bad certificate"):
+		return "The server asks for a client certificate; set network.client_cert and network.client_key."
+	case strings.Contains(err.Error(), "proxyconnect"):
+		return "The proxy couldn't be reached; check network.proxy, or HTTPS_PROXY when it isn't set."
+	}
+	return ""
+}
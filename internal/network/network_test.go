@@ -0,0 +1,178 @@
+package network
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/config"
+)
+
+// writePEM writes PEM blocks to a file in dir and returns its path
+func writePEM(t *testing.T, dir, name string, blocks ...*pem.Block) string {
+	t.Helper()
+	var data []byte
+	for _, block := range blocks {
+		data = append(data, pem.EncodeToMemory(block)...)
+	}
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func get(transport http.RoundTripper, url string) error {
+	resp, err := (&http.Client{Transport: transport, Timeout: 10 * time.Second}).Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func TestTransportCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	transport, err := Transport(config.Network{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = get(transport, server.URL)
+	if err == nil {
+		t.Fatal("expected the test server's certificate to be untrusted")
+	}
+	if !strings.Contains(Explain(err).Error(), "network.ca_file") {
+		t.Errorf("expected a hint at network.ca_file, got %v", Explain(err))
+	}
+
+	ca := writePEM(t, t.TempDir(), "ca.pem", &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	transport, err = Transport(config.Network{CAFile: ca})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(transport, server.URL); err != nil {
+		t.Errorf("expected the CA bundle to be trusted, got %v", err)
+	}
+}
+
+func TestTransportErrors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.Network
+		want string
+	}{
+		{"bad proxy scheme", config.Network{Proxy: "ftp://proxy:21"}, "unsupported scheme"},
+		{"missing CA", config.Network{CAFile: filepath.Join(dir, "missing.pem")}, "failed to read CA bundle"},
+		{"no certificates", config.Network{CAFile: empty}, "no PEM certificates"},
+		{"key alone", config.Network{ClientKey: empty}, "without network.client_cert"},
+		{"bad client cert", config.Network{ClientCert: empty}, "failed to load client certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Transport(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error with %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "tldrpp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certBlock := &pem.Block{Type: "CERTIFICATE", Bytes: der}
+	keyBlock := &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}
+
+	dir := t.TempDir()
+	separate := config.Network{
+		ClientCert: writePEM(t, dir, "cert.pem", certBlock),
+		ClientKey:  writePEM(t, dir, "key.pem", keyBlock),
+	}
+	// The key may be in the certificate's file
+	combined := config.Network{ClientCert: writePEM(t, dir, "both.pem", certBlock, keyBlock)}
+	for _, cfg := range []config.Network{separate, combined} {
+		tlsConfig, err := TLSConfig(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tlsConfig.Certificates) != 1 {
+			t.Errorf("expected the client certificate to be loaded, got %d", len(tlsConfig.Certificates))
+		}
+	}
+}
+
+func TestTransportProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	transport, err := Transport(config.Network{Proxy: strings.TrimPrefix(proxy.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(transport, "http://pages.example.com/tldr.zip"); err != nil {
+		t.Fatal(err)
+	}
+	if proxied != "http://pages.example.com/tldr.zip" {
+		t.Errorf("expected the request to go through the proxy, got %q", proxied)
+	}
+}
+
+func TestGitEnv(t *testing.T) {
+	if env := GitEnv(config.Network{}); len(env) != 0 {
+		t.Errorf("expected no environment without settings, got %v", env)
+	}
+	env := strings.Join(GitEnv(config.Network{Proxy: "http://proxy:3128", CAFile: "/ca.pem", ClientCert: "/both.pem"}), " ")
+	for _, want := range []string{"https_proxy=http://proxy:3128", "GIT_SSL_CAINFO=/ca.pem", "GIT_SSL_CERT=/both.pem", "GIT_SSL_KEY=/both.pem"} {
+		if !strings.Contains(env, want) {
+			t.Errorf("expected %s in %s", want, env)
+		}
+	}
+}
+
+func TestHint(t *testing.T) {
+	if Hint(os.ErrNotExist) != "" {
+		t.Error("expected no hint for an unrelated error")
+	}
+	if err := Explain(os.ErrNotExist); err != os.ErrNotExist {
+		t.Errorf("expected an unrelated error unchanged, got %v", err)
+	}
+}
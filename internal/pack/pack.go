@@ -0,0 +1,286 @@
+package pack
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile names the manifest at the root of a pack
+const ManifestFile = "pack.yml"
+
+// maxSize bounds what a pack may unpack to, so a hostile archive can't
+// fill the disk
+const maxSize = 64 << 20
+
+// downloadTimeout bounds how long downloading a pack or an index may take
+const downloadTimeout = 2 * time.Minute
+
+// validName is what pack names look like, e.g. kubernetes-ops
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Manifest describes a pack
+type Manifest struct {
+	Name        string `yaml:"name"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description"`
+	// Homepage is where the pack is maintained, if anywhere
+	Homepage string `yaml:"homepage,omitempty"`
+}
+
+// Pack is a themed collection of pages: a gzipped tarball with a pack.yml
+// manifest and pages laid out as pages/<platform>/<name>.md, both
+// optionally inside a single top-level directory
+type Pack struct {
+	Manifest Manifest
+	// Pages holds the content of each page by <platform>/<name>.md
+	Pages map[string][]byte
+}
+
+// Read unpacks and checks a pack: its manifest must name it and every
+// page must parse and have examples
+func Read(r io.Reader) (*Pack, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a gzipped tarball: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	var total int64
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pack: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		total += header.Size
+		if total > maxSize {
+			return nil, fmt.Errorf("pack is larger than %d MB", maxSize>>20)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		files[path.Clean(strings.TrimPrefix(header.Name, "./"))] = data
+	}
+
+	root, err := findRoot(files)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pack{Pages: make(map[string][]byte)}
+	if err := yaml.Unmarshal(files[path.Join(root, ManifestFile)], &p.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	if !validName.MatchString(p.Manifest.Name) {
+		return nil, fmt.Errorf("invalid pack name %q in %s, expected lowercase letters, digits and dashes", p.Manifest.Name, ManifestFile)
+	}
+
+	pagesDir := path.Join(root, "pages") + "/"
+	for name, data := range files {
+		rel, ok := strings.CutPrefix(name, pagesDir)
+		if !ok || !strings.HasSuffix(rel, ".md") {
+			continue
+		}
+		platform, file, ok := strings.Cut(rel, "/")
+		if !ok || strings.Contains(file, "/") || strings.HasPrefix(platform, ".") {
+			return nil, fmt.Errorf("page %s isn't in pages/<platform>/<name>.md", name)
+		}
+		entry := types.IndexEntry{Name: strings.TrimSuffix(file, ".md"), Platform: platform}
+		page, err := types.ParsePage(string(data), entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page %s: %w", name, err)
+		}
+		if len(page.Examples) == 0 {
+			return nil, fmt.Errorf("page %s has no examples", name)
+		}
+		p.Pages[rel] = data
+	}
+	if len(p.Pages) == 0 {
+		return nil, fmt.Errorf("pack %s has no pages", p.Manifest.Name)
+	}
+	return p, nil
+}
+
+// findRoot returns the directory holding the manifest: the root of the
+// archive or its only top-level directory
+func findRoot(files map[string][]byte) (string, error) {
+	if _, ok := files[ManifestFile]; ok {
+		return ".", nil
+	}
+	var roots []string
+	for name := range files {
+		if dir, file := path.Split(name); file == ManifestFile && !strings.Contains(strings.TrimSuffix(dir, "/"), "/") {
+			roots = append(roots, strings.TrimSuffix(dir, "/"))
+		}
+	}
+	if len(roots) != 1 {
+		return "", fmt.Errorf("no %s at the root of the pack", ManifestFile)
+	}
+	return roots[0], nil
+}
+
+// Install writes a pack below dir, replacing any earlier version of it
+func Install(dir string, p *Pack) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create packs directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(dir, ".install-")
+	if err != nil {
+		return fmt.Errorf("failed to create packs directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	manifest, err := yaml.Marshal(p.Manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, ManifestFile), manifest, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	for rel, data := range p.Pages {
+		file := filepath.Join(tmp, "pages", filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return fmt.Errorf("failed to create pages directory: %w", err)
+		}
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			return fmt.Errorf("failed to write page: %w", err)
+		}
+	}
+
+	target := filepath.Join(dir, p.Manifest.Name)
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("failed to remove the installed pack: %w", err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return fmt.Errorf("failed to install pack: %w", err)
+	}
+	return nil
+}
+
+// Installed is a pack installed below a directory
+type Installed struct {
+	Manifest Manifest
+	Pages    int
+}
+
+// List returns the packs installed below dir in name order
+func List(dir string) ([]Installed, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list packs: %w", err)
+	}
+
+	var packs []Installed
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name(), ManifestFile))
+		if err != nil {
+			continue
+		}
+		var installed Installed
+		if err := yaml.Unmarshal(data, &installed.Manifest); err != nil {
+			continue
+		}
+		pages, _ := filepath.Glob(filepath.Join(dir, entry.Name(), "pages", "*", "*.md"))
+		installed.Pages = len(pages)
+		packs = append(packs, installed)
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Manifest.Name < packs[j].Manifest.Name })
+	return packs, nil
+}
+
+// Remove deletes a pack installed below dir
+func Remove(dir, name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid pack name %q", name)
+	}
+	target := filepath.Join(dir, name)
+	if _, err := os.Stat(filepath.Join(target, ManifestFile)); err != nil {
+		return fmt.Errorf("pack %s is not installed", name)
+	}
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("failed to remove pack %s: %w", name, err)
+	}
+	return nil
+}
+
+// PagePath returns the file of a page installed by a pack below dir
+func PagePath(dir, name, platform, page string) string {
+	return filepath.Join(dir, name, "pages", platform, page+".md")
+}
+
+// Providing returns the installed pack whose page of a name and platform
+// is shown, or "" if no pack has that page. Packs override one another in
+// name order, the last winning.
+func Providing(dir, platform, page string) string {
+	packs, err := List(dir)
+	if err != nil {
+		return ""
+	}
+	for i := len(packs) - 1; i >= 0; i-- {
+		if _, err := os.Stat(PagePath(dir, packs[i].Manifest.Name, platform, page)); err == nil {
+			return packs[i].Manifest.Name
+		}
+	}
+	return ""
+}
+
+// ParseIndex reads a pack index, which maps pack names to the URLs of
+// their tarballs:
+//
+//	kubernetes-ops: https://example.com/packs/kubernetes-ops-1.2.tar.gz
+func ParseIndex(data []byte) (map[string]string, error) {
+	var index map[string]string
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid pack index: %w", err)
+	}
+	return index, nil
+}
+
+// Download returns what is at a URL, a pack or an index, through transport,
+// reading at most the largest pack allowed
+func Download(transport http.RoundTripper, url string) ([]byte, error) {
+	client := &http.Client{Timeout: downloadTimeout, Transport: transport}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	if len(data) > maxSize {
+		return nil, fmt.Errorf("%s is larger than %d MB", url, maxSize>>20)
+	}
+	return data, nil
+}
@@ -0,0 +1,144 @@
+package pack
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/network"
+)
+
+const page = "# kctx\n\n> Switch Kubernetes contexts.\n\n- Switch to a context:\n\n`kctx {{name}}`\n"
+
+// tarball returns a gzipped tarball of files
+func tarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	// Packs made with a top-level directory, as release tarballs are
+	p, err := Read(bytes.NewReader(tarball(t, map[string]string{
+		"kubernetes-ops-1.2/pack.yml":               "name: kubernetes-ops\nversion: \"1.2\"\ndescription: Runbooks\n",
+		"kubernetes-ops-1.2/pages/common/kctx.md":   page,
+		"kubernetes-ops-1.2/pages/linux/README.txt": "not a page",
+		"kubernetes-ops-1.2/README.md":              "# About\n",
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Manifest.Name != "kubernetes-ops" || p.Manifest.Version != "1.2" {
+		t.Errorf("unexpected manifest %+v", p.Manifest)
+	}
+	if len(p.Pages) != 1 || string(p.Pages["common/kctx.md"]) != page {
+		t.Errorf("expected only common/kctx.md, got %v", p.Pages)
+	}
+
+	invalid := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"no manifest", map[string]string{"pages/common/kctx.md": page}},
+		{"bad name", map[string]string{"pack.yml": "name: ../ops\n", "pages/common/kctx.md": page}},
+		{"no pages", map[string]string{"pack.yml": "name: ops\n"}},
+		{"nested page", map[string]string{"pack.yml": "name: ops\n", "pages/common/sub/kctx.md": page}},
+		{"bad page", map[string]string{"pack.yml": "name: ops\n", "pages/common/kctx.md": "not a page"}},
+	}
+	for _, tt := range invalid {
+		if _, err := Read(bytes.NewReader(tarball(t, tt.files))); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+	if _, err := Read(strings.NewReader("plain text")); err == nil {
+		t.Error("expected a file that isn't a tarball to fail")
+	}
+}
+
+func TestInstall(t *testing.T) {
+	dir := t.TempDir()
+	p := &Pack{Manifest: Manifest{Name: "ops", Version: "1"}, Pages: map[string][]byte{"common/kctx.md": []byte(page)}}
+	if err := Install(dir, p); err != nil {
+		t.Fatal(err)
+	}
+	if got := Providing(dir, "common", "kctx"); got != "ops" {
+		t.Errorf("Providing = %q, want ops", got)
+	}
+	if got := Providing(dir, "linux", "kctx"); got != "" {
+		t.Errorf("expected no pack for linux/kctx, got %q", got)
+	}
+
+	// Installing again replaces the pages
+	p.Manifest.Version = "2"
+	p.Pages = map[string][]byte{"linux/kns.md": []byte(page)}
+	if err := Install(dir, p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(PagePath(dir, "ops", "common", "kctx")); !os.IsNotExist(err) {
+		t.Error("expected the old version's pages to be gone")
+	}
+
+	packs, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packs) != 1 || packs[0].Manifest.Version != "2" || packs[0].Pages != 1 {
+		t.Errorf("unexpected packs %+v", packs)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected no leftovers in the packs directory, got %v", entries)
+	}
+
+	if err := Remove(dir, "ops"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Remove(dir, "ops"); err == nil {
+		t.Error("expected removing a pack twice to fail")
+	}
+	if err := Remove(dir, ".."); err == nil {
+		t.Error("expected an invalid name to fail")
+	}
+	if packs, _ := List(dir); len(packs) != 0 {
+		t.Errorf("expected no packs, got %+v", packs)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "index.yml")
+	if err := os.WriteFile(file, []byte("ops: https://example.com/ops.tar.gz\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	transport, err := network.Transport(config.Network{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Download(transport, "file://"+filepath.ToSlash(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := ParseIndex(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index["ops"] != "https://example.com/ops.tar.gz" {
+		t.Errorf("unexpected index %v", index)
+	}
+	if _, err := Download(transport, "file:///nonexistent/index.yml"); err == nil {
+		t.Error("expected a missing file to fail")
+	}
+}
@@ -0,0 +1,67 @@
+package platform
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Common is the platform of pages that work everywhere
+const Common = "common"
+
+// current caches the detected platforms, which can't change while running
+var current = sync.OnceValue(func() []string {
+	procVersion, _ := os.ReadFile("/proc/version")
+	return detect(runtime.GOOS, os.Getenv, string(procVersion))
+})
+
+// Current returns the tldr platforms of the running system, most specific
+// first: termux is android then linux, WSL is linux then windows
+func Current() []string {
+	return append([]string(nil), current()...)
+}
+
+// Order returns the platforms pages are preferred from: the current ones,
+// then common. Pages on other platforms rank after these.
+func Order() []string {
+	return append(Current(), Common)
+}
+
+// Rank returns the position of platform in order, or len(order) if it isn't
+// there, so lower ranks are preferred
+func Rank(order []string, platform string) int {
+	for i, p := range order {
+		if p == platform {
+			return i
+		}
+	}
+	return len(order)
+}
+
+// detect maps an operating system to tldr platforms. getenv and procVersion
+// tell termux and WSL apart from plain linux.
+func detect(goos string, getenv func(string) string, procVersion string) []string {
+	switch goos {
+	case "android":
+		return []string{"android", "linux"}
+	case "linux":
+		if getenv("TERMUX_VERSION") != "" || strings.Contains(getenv("PREFIX"), "com.termux") {
+			return []string{"android", "linux"}
+		}
+		if getenv("WSL_DISTRO_NAME") != "" || strings.Contains(strings.ToLower(procVersion), "microsoft") {
+			return []string{"linux", "windows"}
+		}
+		return []string{"linux"}
+	case "darwin", "ios":
+		return []string{"osx"}
+	case "windows":
+		return []string{"windows"}
+	case "solaris", "illumos":
+		return []string{"sunos"}
+	case "freebsd", "openbsd", "netbsd":
+		return []string{goos}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,65 @@
+package platform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name        string
+		goos        string
+		env         map[string]string
+		procVersion string
+		expected    []string
+	}{
+		{"linux", "linux", nil, "Linux version 6.1.0 (gcc)", []string{"linux"}},
+		{"wsl from proc", "linux", nil, "Linux version 5.15.90.1-microsoft-standard-WSL2", []string{"linux", "windows"}},
+		{"wsl from env", "linux", map[string]string{"WSL_DISTRO_NAME": "Ubuntu"}, "", []string{"linux", "windows"}},
+		{"termux", "linux", map[string]string{"TERMUX_VERSION": "0.118"}, "", []string{"android", "linux"}},
+		{"termux prefix", "linux", map[string]string{"PREFIX": "/data/data/com.termux/files/usr"}, "", []string{"android", "linux"}},
+		{"android", "android", nil, "", []string{"android", "linux"}},
+		{"macos", "darwin", nil, "", []string{"osx"}},
+		{"windows", "windows", nil, "", []string{"windows"}},
+		{"illumos", "illumos", nil, "", []string{"sunos"}},
+		{"freebsd", "freebsd", nil, "", []string{"freebsd"}},
+		{"unknown", "plan9", nil, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if platforms := detect(tt.goos, getenv, tt.procVersion); !reflect.DeepEqual(platforms, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, platforms)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	order := []string{"linux", "windows", Common}
+	tests := []struct {
+		platform string
+		expected int
+	}{
+		{"linux", 0},
+		{"windows", 1},
+		{"common", 2},
+		{"osx", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			if rank := Rank(order, tt.platform); rank != tt.expected {
+				t.Errorf("Expected rank %d, got %d", tt.expected, rank)
+			}
+		})
+	}
+}
+
+func TestOrderEndsWithCommon(t *testing.T) {
+	order := Order()
+	if len(order) == 0 || order[len(order)-1] != Common {
+		t.Errorf("Expected order to end with common, got %v", order)
+	}
+}
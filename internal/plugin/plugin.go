@@ -178,6 +178,7 @@ func (p *SubmitPlugin) createPullRequest() error {
 
 	cmd := exec.Command("gh", "pr", "create", 
 		"--repo", "tldr-pages/tldr",
+		"--head", branchName,
 		"--title", title,
 		"--body", body,
 		"--file", tempFile)
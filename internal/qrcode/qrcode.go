@@ -0,0 +1,517 @@
+// Package qrcode encodes text as a QR code (ISO/IEC 18004) and draws it
+// with block characters, so that a command shown in a terminal can be
+// photographed with a phone.
+//
+// Text is encoded as bytes at the low error correction level: a code drawn
+// on a screen isn't smudged or torn, and the lowest level keeps codes of long
+// commands small enough to fit a terminal.
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxVersion is the largest QR code version, 177 modules wide
+const maxVersion = 40
+
+// quietZone is how many light modules are drawn around a code. The standard
+// asks for 4, but 2 is enough for a code on a screen and saves room.
+const quietZone = 2
+
+// eccPerBlock is how many error correction codewords each block of a version
+// has at the low level, indexed by version
+var eccPerBlock = [maxVersion + 1]int{
+	0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28,
+	28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30,
+}
+
+// eccBlocks is how many blocks the codewords of a version are split into at
+// the low level, indexed by version
+var eccBlocks = [maxVersion + 1]int{
+	0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7,
+	8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25,
+}
+
+// Code is an encoded QR code
+type Code struct {
+	// Size is how many modules wide and high the code is, without the quiet
+	// zone
+	Size    int
+	version int
+	modules [][]bool
+	// function marks the modules of the finder, timing, alignment, format
+	// and version patterns, which masks leave alone
+	function [][]bool
+}
+
+// Encode returns the smallest QR code holding text, or an error if text is
+// too long for any
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+	version := 1
+	for ; version <= maxVersion; version++ {
+		if 4+countBits(version)+8*len(data) <= 8*dataCodewords(version) {
+			break
+		}
+	}
+	if version > maxVersion {
+		return nil, fmt.Errorf("text of %d bytes is too long for a QR code, which holds at most %d", len(data), (8*dataCodewords(maxVersion)-4-countBits(maxVersion))/8)
+	}
+
+	c := &Code{Size: version*4 + 17, version: version}
+	c.modules = grid(c.Size)
+	c.function = grid(c.Size)
+	c.drawFunctionPatterns()
+	c.drawCodewords(c.interleave(c.dataCodewords(data)))
+
+	best, lowest := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormat(mask)
+		if penalty := c.penalty(); lowest < 0 || penalty < lowest {
+			best, lowest = mask, penalty
+		}
+		// Masking twice undoes it
+		c.applyMask(mask)
+	}
+	c.applyMask(best)
+	c.drawFormat(best)
+	return c, nil
+}
+
+// Dark reports whether the module at column x and row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Terminal draws the code with block characters, two rows of modules per
+// line of text, inside its quiet zone. Dark modules are drawn as blocks,
+// which suits terminals drawing dark text on a light background; inverted
+// draws the light ones instead, for dark backgrounds.
+func (c *Code) Terminal(inverted bool) string {
+	// filled reports whether a module, or the quiet zone around the code,
+	// is drawn as a block
+	filled := func(x, y int) bool {
+		dark := x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y][x]
+		return dark != inverted
+	}
+	var b strings.Builder
+	for y := -quietZone; y < c.Size+quietZone; y += 2 {
+		for x := -quietZone; x < c.Size+quietZone; x++ {
+			top := filled(x, y)
+			// Past the quiet zone, the last line's lower half stays empty
+			bottom := y+1 < c.Size+quietZone && filled(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// grid returns a size by size grid of light modules
+func grid(size int) [][]bool {
+	g := make([][]bool, size)
+	for y := range g {
+		g[y] = make([]bool, size)
+	}
+	return g
+}
+
+// countBits is how many bits the byte count of the data takes in a version
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// rawModules is how many modules of a version hold codewords, data and
+// error correction, once the function patterns are drawn
+func rawModules(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		alignments := version/7 + 2
+		n -= (25*alignments-10)*alignments - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+// dataCodewords is how many codewords of a version hold data
+func dataCodewords(version int) int {
+	return rawModules(version)/8 - eccPerBlock[version]*eccBlocks[version]
+}
+
+// alignmentPositions returns the rows, and columns, of the centers of the
+// alignment patterns of a version
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	n := version/7 + 2
+	step := (version*8 + n*3 + 5) / (n*4 - 4) * 2
+	positions := make([]int, n)
+	positions[0] = 6
+	for i, pos := n-1, version*4+17-7; i >= 1; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+// set draws a module of a function pattern
+func (c *Code) set(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+// drawFunctionPatterns draws the timing, finder, alignment and version
+// patterns, and reserves the modules of the format
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+
+	for _, center := range [][2]int{{3, 3}, {c.Size - 4, 3}, {3, c.Size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := center[0]+dx, center[1]+dy
+				if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+					continue
+				}
+				dist := max(abs(dx), abs(dy))
+				c.set(x, y, dist != 2 && dist != 4)
+			}
+		}
+	}
+
+	positions := alignmentPositions(c.version)
+	last := len(positions) - 1
+	for i, y := range positions {
+		for j, x := range positions {
+			// The corners taken by finder patterns have none
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	c.drawFormat(0)
+	if c.version >= 7 {
+		rem := c.version
+		for i := 0; i < 12; i++ {
+			rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+		}
+		bits := c.version<<12 | rem
+		for i := 0; i < 18; i++ {
+			dark := bits>>i&1 == 1
+			a, b := c.Size-11+i%3, i/3
+			c.set(a, b, dark)
+			c.set(b, a, dark)
+		}
+	}
+}
+
+// drawFormat draws both copies of the format: the error correction level
+// and mask
+func (c *Code) drawFormat(mask int) {
+	// The low level is 01
+	data := 1<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.set(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.Size-15+i, bit(i))
+	}
+	c.set(8, c.Size-8, true)
+}
+
+// dataCodewords returns data in byte mode, padded to the data codewords of
+// the code's version
+func (c *Code) dataCodewords(data []byte) []byte {
+	var bits bitBuffer
+	bits.append(0x4, 4)
+	bits.append(len(data), countBits(c.version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := 8 * dataCodewords(c.version)
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			codewords[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return codewords
+}
+
+// interleave splits data into blocks, adds their error correction
+// codewords and interleaves them in the order they are drawn
+func (c *Code) interleave(data []byte) []byte {
+	blocks := eccBlocks[c.version]
+	eccLen := eccPerBlock[c.version]
+	raw := rawModules(c.version) / 8
+	short := blocks - raw%blocks
+	shortLen := raw / blocks
+	divisor := rsDivisor(eccLen)
+
+	all := make([][]byte, blocks)
+	for i, k := 0, 0; i < blocks; i++ {
+		n := shortLen - eccLen
+		if i >= short {
+			n++
+		}
+		block := append([]byte(nil), data[k:k+n]...)
+		k += n
+		ecc := rsRemainder(block, divisor)
+		if i < short {
+			// Short blocks line up with the long ones, skipped below
+			block = append(block, 0)
+		}
+		all[i] = append(block, ecc...)
+	}
+
+	var result []byte
+	for i := range all[0] {
+		for j, block := range all {
+			if i != shortLen-eccLen || j >= short {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// drawCodewords draws codewords in the zigzag of two-module columns from
+// the bottom right corner, around the function patterns
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		// The vertical timing pattern is skipped
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if upward {
+					y = c.Size - 1 - vert
+				}
+				if !c.function[y][x] && i < len(codewords)*8 {
+					c.modules[y][x] = codewords[i/8]>>(7-i%8)&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the modules outside the function patterns that mask
+// selects
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !c.function[y][x] {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// finderLike are runs of modules looking like part of a finder pattern,
+// which a mask should avoid
+var finderLike = [][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty scores how hard the code is to read, the lower the better: long
+// runs and blocks of one color, patterns looking like finders, and an
+// imbalance of dark and light modules
+func (c *Code) penalty() int {
+	score := 0
+	dark := 0
+	for i := 0; i < c.Size; i++ {
+		row := make([]bool, c.Size)
+		column := make([]bool, c.Size)
+		for j := 0; j < c.Size; j++ {
+			row[j], column[j] = c.modules[i][j], c.modules[j][i]
+			if row[j] {
+				dark++
+			}
+		}
+		for _, line := range [][]bool{row, column} {
+			score += runPenalty(line) + finderPenalty(line)
+		}
+	}
+
+	for y := 0; y < c.Size-1; y++ {
+		for x := 0; x < c.Size-1; x++ {
+			m := c.modules[y][x]
+			if m == c.modules[y][x+1] && m == c.modules[y+1][x] && m == c.modules[y+1][x+1] {
+				score += 3
+			}
+		}
+	}
+
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return score + k*10
+}
+
+// runPenalty scores the runs of five or more modules of one color in line
+func runPenalty(line []bool) int {
+	score := 0
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			score += run - 2
+		}
+		run = 1
+	}
+	return score
+}
+
+// finderPenalty scores the runs of modules in line looking like finders
+func finderPenalty(line []bool) int {
+	score := 0
+	for i := 0; i+len(finderLike[0]) <= len(line); i++ {
+		for _, pattern := range finderLike {
+			match := true
+			for j, dark := range pattern {
+				if line[i+j] != dark {
+					match = false
+					break
+				}
+			}
+			if match {
+				score += 40
+			}
+		}
+	}
+	return score
+}
+
+// rsDivisor returns the Reed-Solomon generator polynomial of degree n,
+// highest coefficient first and its leading 1 left out
+func rsDivisor(n int) []byte {
+	divisor := make([]byte, n)
+	divisor[n-1] = 1
+	root := byte(1)
+	for i := 0; i < n; i++ {
+		for j := range divisor {
+			divisor[j] = gfMultiply(divisor[j], root)
+			if j+1 < n {
+				divisor[j] ^= divisor[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return divisor
+}
+
+// rsRemainder returns the error correction codewords of data
+func rsRemainder(data, divisor []byte) []byte {
+	remainder := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ remainder[0]
+		copy(remainder, remainder[1:])
+		remainder[len(remainder)-1] = 0
+		for i, d := range divisor {
+			remainder[i] ^= gfMultiply(d, factor)
+		}
+	}
+	return remainder
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// bitBuffer is a sequence of bits, most significant first
+type bitBuffer []bool
+
+// append adds the n low bits of v
+func (b *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>i&1 == 1)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
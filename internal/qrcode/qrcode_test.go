@@ -0,0 +1,218 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodeSize(t *testing.T) {
+	tests := []struct {
+		text string
+		size int
+	}{
+		{"", 21},
+		{strings.Repeat("a", 17), 21},
+		{strings.Repeat("a", 18), 25},
+		{strings.Repeat("a", 271), 57},
+		{strings.Repeat("a", 2953), 177},
+	}
+	for _, tt := range tests {
+		c, err := Encode(tt.text)
+		if err != nil {
+			t.Fatalf("Encode of %d bytes failed: %v", len(tt.text), err)
+		}
+		if c.Size != tt.size {
+			t.Errorf("Expected %d bytes to take %d modules, got %d", len(tt.text), tt.size, c.Size)
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("a", 2954)); err == nil {
+		t.Error("Expected an error for text too long for a QR code")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	// The format strings of the low level, from the standard
+	want := []string{
+		"111011111000100", "111001011110011", "111110110101010", "111100010011101",
+		"110011000101111", "110001100011000", "110110001000001", "110100101110110",
+	}
+	c, err := Encode("format")
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	for mask, bits := range want {
+		c.drawFormat(mask)
+		if got := readFormat(c); got != bits {
+			t.Errorf("Mask %d: expected format %s, got %s", mask, bits, got)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	texts := []string{
+		"",
+		"tar cf backup.tar file1 file2",
+		"ssh -i ~/.ssh/id_ed25519 -L 8080:localhost:80 user@example.com 'echo ünïcode'",
+		strings.Repeat("rsync -avz --delete src/ host:/srv/dst/ && ", 20),
+		strings.Repeat("x", 2953),
+	}
+	for _, text := range texts {
+		c, err := Encode(text)
+		if err != nil {
+			t.Fatalf("Encode of %d bytes failed: %v", len(text), err)
+		}
+		if got := decode(t, c); got != text {
+			t.Errorf("Expected %q back, got %q", text, got)
+		}
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	c, err := Encode("ls")
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(c.Terminal(false), "\n"), "\n")
+	if len(lines) != (21+2*quietZone+1)/2 {
+		t.Errorf("Expected %d lines, got %d", (21+2*quietZone+1)/2, len(lines))
+	}
+	// The quiet zone is blank above the finder patterns, whose top edge is
+	// drawn with the light row below it
+	if lines[0] != strings.Repeat(" ", 21+2*quietZone) {
+		t.Errorf("Expected a blank first line, got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "  █▀▀▀▀▀█") {
+		t.Errorf("Expected the finder pattern at the start of the second line, got %q", lines[1])
+	}
+
+	inverted := strings.Split(c.Terminal(true), "\n")
+	if inverted[0] != strings.Repeat("█", 21+2*quietZone) {
+		t.Errorf("Expected an inverted quiet zone drawn as blocks, got %q", inverted[0])
+	}
+}
+
+// readFormat returns the first copy of the format bits of c, most
+// significant first
+func readFormat(c *Code) string {
+	var bits [15]bool
+	for i := 0; i <= 5; i++ {
+		bits[i] = c.modules[i][8]
+	}
+	bits[6], bits[7], bits[8] = c.modules[7][8], c.modules[8][8], c.modules[8][7]
+	for i := 9; i < 15; i++ {
+		bits[i] = c.modules[8][14-i]
+	}
+	var s strings.Builder
+	for i := 14; i >= 0; i-- {
+		if bits[i] {
+			s.WriteByte('1')
+		} else {
+			s.WriteByte('0')
+		}
+	}
+	return s.String()
+}
+
+// decode reads the text back from c, checking the error correction of
+// every block
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+	mask := -1
+	format := readFormat(c)
+	probe := &Code{Size: c.Size, modules: grid(c.Size), function: grid(c.Size)}
+	for m := 0; m < 8; m++ {
+		probe.drawFormat(m)
+		if readFormat(probe) == format {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("Unknown format %s", format)
+	}
+
+	plain := &Code{Size: c.Size, version: c.version, modules: grid(c.Size), function: c.function}
+	for y := range c.modules {
+		copy(plain.modules[y], c.modules[y])
+	}
+	plain.applyMask(mask)
+
+	var codewords []byte
+	var bits bitBuffer
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if upward {
+					y = c.Size - 1 - vert
+				}
+				if !c.function[y][x] {
+					bits = append(bits, plain.modules[y][x])
+				}
+			}
+		}
+	}
+	for i := 0; i+8 <= len(bits); i += 8 {
+		var b byte
+		for _, bit := range bits[i : i+8] {
+			b <<= 1
+			if bit {
+				b |= 1
+			}
+		}
+		codewords = append(codewords, b)
+	}
+
+	blocks := eccBlocks[c.version]
+	eccLen := eccPerBlock[c.version]
+	raw := rawModules(c.version) / 8
+	short := blocks - raw%blocks
+	shortLen := raw / blocks
+	all := make([][]byte, blocks)
+	k := 0
+	for i := 0; i < shortLen+1; i++ {
+		for j := range all {
+			if i != shortLen-eccLen || j >= short {
+				all[j] = append(all[j], codewords[k])
+				k++
+			}
+		}
+	}
+
+	var data []byte
+	for j, block := range all {
+		// Every block is divisible by the generator: it vanishes at its
+		// roots
+		for i, root := 0, byte(1); i < eccLen; i, root = i+1, gfMultiply(root, 0x02) {
+			var value byte
+			for _, b := range block {
+				value = gfMultiply(value, root) ^ b
+			}
+			if value != 0 {
+				t.Fatalf("Block %d doesn't check out at root %d", j, i)
+			}
+		}
+		data = append(data, block[:len(block)-eccLen]...)
+	}
+
+	read := func(i, n int) int {
+		v := 0
+		for ; n > 0; i, n = i+1, n-1 {
+			v = v<<1 | int(data[i/8]>>(7-i%8)&1)
+		}
+		return v
+	}
+	if mode := read(0, 4); mode != 0x4 {
+		t.Fatalf("Expected byte mode, got %x", mode)
+	}
+	n := read(4, countBits(c.version))
+	text := make([]byte, n)
+	for i := range text {
+		text[i] = byte(read(4+countBits(c.version)+8*i, 8))
+	}
+	return string(text)
+}
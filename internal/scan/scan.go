@@ -0,0 +1,77 @@
+// Package scan finds the tools installed on the machine and drafts tldr
+// pages for those without one from their --help output.
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Executables returns the names of the executables in the directories of
+// path, a PATH-style list, sorted and without duplicates. On Windows the
+// extensions of PATHEXT are dropped, so that git.exe is listed as git.
+func Executables(path string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := executableName(dir, entry)
+			if !ok || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// executableName returns the command name of a directory entry, if it is an
+// executable file or a link to one
+func executableName(dir string, entry os.DirEntry) (string, bool) {
+	name := entry.Name()
+	if strings.HasPrefix(name, ".") {
+		return "", false
+	}
+	info, err := os.Stat(filepath.Join(dir, name))
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		for _, known := range filepath.SplitList(strings.ToLower(os.Getenv("PATHEXT"))) {
+			if ext != "" && ext == known {
+				return strings.TrimSuffix(name, filepath.Ext(name)), true
+			}
+		}
+		return "", false
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		return "", false
+	}
+	return name, true
+}
+
+// Missing returns the tools that have no page among pages, keeping their
+// order
+func Missing(tools []string, pages map[string]bool) []string {
+	var missing []string
+	for _, tool := range tools {
+		if !pages[strings.ToLower(tool)] {
+			missing = append(missing, tool)
+		}
+	}
+	return missing
+}
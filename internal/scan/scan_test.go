@@ -0,0 +1,78 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExecutables(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executables are found by extension on Windows")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	files := []struct {
+		dir, name string
+		mode      os.FileMode
+	}{
+		{first, "tar", 0755},
+		{first, "notes.txt", 0644},
+		{first, ".hidden", 0755},
+		{second, "tar", 0755},
+		{second, "awk", 0755},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(f.dir, f.name), nil, f.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(second, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path := strings.Join([]string{first, "", filepath.Join(first, "missing"), second}, string(os.PathListSeparator))
+	if got, want := Executables(path), []string{"awk", "tar"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Executables = %v, want %v", got, want)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	got := Missing([]string{"awk", "mytool", "Tar"}, map[string]bool{"awk": true, "tar": true})
+	if want := []string{"mytool"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Missing = %v, want %v", got, want)
+	}
+}
+
+func TestStubPage(t *testing.T) {
+	help := `Usage: mytool [OPTION]... FILE
+Convert files between formats quickly.
+
+Options:
+  -o, --output=FILE    write the result to FILE
+  -v, --verbose        explain what is being done.
+  -q                   be quiet
+      --verbose        repeated
+  -h, --help           display this help and exit
+      --version        output version information and exit
+`
+	want := "# mytool\n\n" +
+		"> Convert files between formats quickly.\n" +
+		"> Drafted from `mytool --help`, review before sharing.\n" +
+		"> More information: <https://example.com>.\n" +
+		"\n- Write the result to FILE:\n\n`mytool --output`\n" +
+		"\n- Explain what is being done:\n\n`mytool --verbose`\n" +
+		"\n- Be quiet:\n\n`mytool -q`\n"
+	if got := StubPage("mytool", help); got != want {
+		t.Errorf("StubPage =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestStubPageWithoutHelp(t *testing.T) {
+	got := StubPage("mytool", "")
+	if !strings.Contains(got, "> Short description of what mytool does.") || !strings.Contains(got, "`mytool {{path/to/file}}`") {
+		t.Errorf("expected the template page, got\n%s", got)
+	}
+}
@@ -0,0 +1,97 @@
+package scan
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxStubExamples caps how many options of the help become examples
+const maxStubExamples = 5
+
+// helpOption matches an option line of --help output, as in
+//
+//	-v, --verbose    explain what is being done
+//
+// capturing the options and the description after two or more spaces
+var helpOption = regexp.MustCompile(`^\s+(-\S.*?)\s{2,}(\S.*)$`)
+
+// longOption and shortOption pick an option's spellings out of the list of
+// them on an option line
+var (
+	longOption  = regexp.MustCompile(`--[A-Za-z0-9][\w-]*`)
+	shortOption = regexp.MustCompile(`(?:^|[\s,])(-[A-Za-z0-9])\b`)
+)
+
+// StubPage drafts a page in the tldr format for tool from the output of
+// tool --help: the first line of prose describes the tool and the first
+// documented options become examples. The result is a starting point to
+// review, not a finished page.
+func StubPage(tool, help string) string {
+	description := fmt.Sprintf("Short description of what %s does.", tool)
+	described := false
+	var examples []string
+	seen := map[string]bool{"--help": true, "--version": true}
+
+	scanner := bufio.NewScanner(strings.NewReader(help))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if m := helpOption.FindStringSubmatch(line); m != nil {
+			option := longOption.FindString(m[1])
+			if option == "" {
+				if short := shortOption.FindStringSubmatch(m[1]); short != nil {
+					option = short[1]
+				}
+			}
+			if option == "" || seen[option] || len(examples) == maxStubExamples {
+				continue
+			}
+			seen[option] = true
+			examples = append(examples, fmt.Sprintf("- %s:\n\n`%s %s`", sentence(m[2]), tool, option))
+			continue
+		}
+		if !described && isDescription(line) {
+			description = sentence(line) + "."
+			described = true
+		}
+	}
+
+	if len(examples) == 0 {
+		examples = append(examples, fmt.Sprintf("- Describe what the first example does:\n\n`%s {{path/to/file}}`", tool))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", tool)
+	fmt.Fprintf(&b, "> %s\n", description)
+	fmt.Fprintf(&b, "> Drafted from `%s --help`, review before sharing.\n", tool)
+	fmt.Fprintf(&b, "> More information: <https://example.com>.\n")
+	for _, example := range examples {
+		b.WriteString("\n" + example + "\n")
+	}
+	return b.String()
+}
+
+// isDescription reports whether a help line can describe the tool: prose
+// rather than a usage line, heading or option
+func isDescription(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "-") || strings.HasSuffix(trimmed, ":") {
+		return false
+	}
+	lower := strings.ToLower(trimmed)
+	if strings.HasPrefix(lower, "usage") || strings.HasPrefix(lower, "try ") {
+		return false
+	}
+	return strings.Contains(trimmed, " ")
+}
+
+// sentence capitalizes s and drops a trailing period, as tldr example
+// descriptions are written
+func sentence(s string) string {
+	s = strings.TrimSuffix(strings.TrimSpace(s), ".")
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
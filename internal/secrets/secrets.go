@@ -0,0 +1,112 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Backends lists the secrets backends that can be configured
+var Backends = []string{"env", "pass", "secret-tool"}
+
+// ErrNotFound is returned when a backend has no secret for a key
+var ErrNotFound = errors.New("secret not found")
+
+// Backend looks up secret values by key
+type Backend interface {
+	Lookup(key string) (string, error)
+}
+
+// New returns the named backend, or nil for "" or "none":
+//
+//   - env reads TLDRPP_SECRET_<KEY>
+//   - pass reads the first line of `pass show tldrpp/<key>`
+//   - secret-tool reads `secret-tool lookup service tldrpp key <key>`
+func New(name string) (Backend, error) {
+	switch name {
+	case "", "none":
+		return nil, nil
+	case "env":
+		return envBackend{getenv: os.Getenv}, nil
+	case "pass":
+		return commandBackend{name: "pass", args: func(key string) []string {
+			return []string{"show", "tldrpp/" + key}
+		}}, nil
+	case "secret-tool":
+		return commandBackend{name: "secret-tool", args: func(key string) []string {
+			return []string{"lookup", "service", "tldrpp", "key", key}
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unknown secrets backend %q (use %s)", name, strings.Join(Backends, ", "))
+	}
+}
+
+// envBackend reads secrets from TLDRPP_SECRET_* environment variables
+type envBackend struct {
+	getenv func(string) string
+}
+
+func (b envBackend) Lookup(key string) (string, error) {
+	name := "TLDRPP_SECRET_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_", "/", "_").Replace(key))
+	if value := b.getenv(name); value != "" {
+		return value, nil
+	}
+	return "", ErrNotFound
+}
+
+// commandBackend reads secrets from the output of a password manager
+type commandBackend struct {
+	name string
+	args func(key string) []string
+}
+
+func (b commandBackend) Lookup(key string) (string, error) {
+	if _, err := exec.LookPath(b.name); err != nil {
+		return "", fmt.Errorf("secrets backend %s is not installed: %w", b.name, err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(b.name, b.args(key)...)
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		// Both tools fail when the entry does not exist
+		return "", ErrNotFound
+	}
+
+	// pass keeps extra lines below the password
+	value, _, _ := strings.Cut(stdout.String(), "\n")
+	if value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Resolve expands a secret reference given as a placeholder value:
+// "env:NAME" reads the NAME environment variable and "secret:KEY" looks KEY
+// up in backend. Other values are returned as they are.
+func Resolve(value string, backend Backend) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "env:"):
+		name := strings.TrimPrefix(value, "env:")
+		resolved, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return resolved, nil
+	case strings.HasPrefix(value, "secret:"):
+		key := strings.TrimPrefix(value, "secret:")
+		if backend == nil {
+			return "", fmt.Errorf("no secrets backend is configured for %s (set secrets_backend)", value)
+		}
+		resolved, err := backend.Lookup(key)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up secret %s: %w", key, err)
+		}
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
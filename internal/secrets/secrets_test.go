@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	for _, name := range append([]string{"", "none"}, Backends...) {
+		if _, err := New(name); err != nil {
+			t.Errorf("Expected backend %q to be known, got %v", name, err)
+		}
+	}
+	if _, err := New("vault"); err == nil {
+		t.Error("Expected an error for an unknown backend")
+	}
+}
+
+func TestEnvBackend(t *testing.T) {
+	env := map[string]string{"TLDRPP_SECRET_GITHUB_TOKEN": "ghp_123"}
+	backend := envBackend{getenv: func(name string) string { return env[name] }}
+
+	value, err := backend.Lookup("github-token")
+	if err != nil || value != "ghp_123" {
+		t.Errorf("Expected ghp_123, got %q (%v)", value, err)
+	}
+
+	if _, err := backend.Lookup("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv("DEPLOY_TOKEN", "s3cret")
+	t.Setenv("TLDRPP_SECRET_DB", "hunter2")
+	backend, _ := New("env")
+
+	tests := []struct {
+		value    string
+		backend  Backend
+		expected string
+		wantErr  bool
+	}{
+		{"plain", backend, "plain", false},
+		{"env:DEPLOY_TOKEN", nil, "s3cret", false},
+		{"env:TLDRPP_TEST_UNSET", nil, "", true},
+		{"secret:db", backend, "hunter2", false},
+		{"secret:db", nil, "", true},
+		{"secret:missing", backend, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Resolve(tt.value, tt.backend)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
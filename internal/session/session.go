@@ -0,0 +1,68 @@
+// Package session keeps what the TUI showed when it exited, so the next
+// launch can pick up where the last one left off
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Screens a session can be restored to
+const (
+	ScreenSearch = "search"
+	ScreenPages  = "pages"
+	ScreenPage   = "page"
+)
+
+// State is what the TUI showed when it exited
+type State struct {
+	SearchQuery string `json:"search_query,omitempty"`
+	// Filter narrows the pages list by name
+	Filter string `json:"filter,omitempty"`
+	// Platforms are the platforms shown; empty shows them all
+	Platforms []string `json:"platforms,omitempty"`
+	// Language is the page language given with --language, if any
+	Language string `json:"language,omitempty"`
+	// Screen is ScreenSearch, ScreenPages or ScreenPage
+	Screen string `json:"screen,omitempty"`
+	// Page is the selected page as platform/name
+	Page string `json:"page,omitempty"`
+	// Example is the index of the selected example on the page
+	Example int `json:"example,omitempty"`
+}
+
+// Load reads the state saved at path, returning an empty state if there is
+// none
+func Load(path string) (*State, error) {
+	state := &State{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, fmt.Errorf("failed to read session: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return &State{}, fmt.Errorf("failed to parse session: %w", err)
+	}
+	return state, nil
+}
+
+// Save writes the state to path
+func Save(path string, state *State) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
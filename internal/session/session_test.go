@@ -0,0 +1,58 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tldrpp", "session.json")
+
+	state, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if state.SearchQuery != "" || state.Screen != "" {
+		t.Errorf("Expected an empty state without a file, got %+v", state)
+	}
+
+	saved := &State{
+		SearchQuery: "tar",
+		Filter:      "ta",
+		Platforms:   []string{"linux", "common"},
+		Language:    "de",
+		Screen:      ScreenPage,
+		Page:        "common/tar",
+		Example:     2,
+	}
+	if err := Save(path, saved); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.SearchQuery != "tar" || loaded.Filter != "ta" || loaded.Page != "common/tar" ||
+		loaded.Example != 2 || loaded.Screen != ScreenPage || loaded.Language != "de" ||
+		strings.Join(loaded.Platforms, ",") != "linux,common" {
+		t.Errorf("Expected %+v, got %+v", saved, loaded)
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	state, err := Load(path)
+	if err == nil {
+		t.Error("Expected an error for a corrupt session")
+	}
+	if state == nil || state.SearchQuery != "" {
+		t.Errorf("Expected an empty state to start from, got %+v", state)
+	}
+}
@@ -0,0 +1,105 @@
+// Package shellwords splits the command lines given in the config and the
+// environment, such as the pager or $EDITOR, into the arguments of the
+// program to run. Words are quoted like in a POSIX shell, but no shell
+// runs: operators, redirections and substitutions are refused rather than
+// passed on as arguments.
+package shellwords
+
+import (
+	"fmt"
+	"strings"
+)
+
+// operators are the characters a shell gives a meaning beyond the word they
+// are in, when not quoted
+const operators = "|&;<>()`$"
+
+// Split breaks line into words. Words are separated by blanks; single
+// quotes keep everything up to the next single quote as it is, double
+// quotes keep blanks and let a backslash escape ", \, $ and `, and a
+// backslash outside quotes escapes the next character. Quotes that enclose
+// nothing make an empty word.
+func Split(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case r == '\\':
+			i++
+			if i == len(runes) {
+				return nil, fmt.Errorf("%q ends with a backslash", line)
+			}
+			// A backslash before a newline continues the line
+			if runes[i] != '\n' {
+				word.WriteRune(runes[i])
+				inWord = true
+			}
+		case r == '\'':
+			end := indexFrom(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote in %q", line)
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			inWord = true
+			i = end
+		case r == '"':
+			end, err := doubleQuoted(runes, i+1, &word)
+			if err != nil {
+				return nil, fmt.Errorf("%w in %q", err, line)
+			}
+			inWord = true
+			i = end
+		case strings.ContainsRune(operators, r):
+			return nil, fmt.Errorf("unsupported shell syntax %q in %q; quote it to pass it on", r, line)
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// doubleQuoted writes the word in double quotes starting at runes[start]
+// to word and returns the position of the closing quote
+func doubleQuoted(runes []rune, start int, word *strings.Builder) (int, error) {
+	for i := start; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '"':
+			return i, nil
+		case r == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]):
+			i++
+			if runes[i] != '\n' {
+				word.WriteRune(runes[i])
+			}
+		case r == '$' || r == '`':
+			return 0, fmt.Errorf("unsupported substitution %q in double quotes; use single quotes to pass it on", r)
+		default:
+			word.WriteRune(r)
+		}
+	}
+	return 0, fmt.Errorf("unterminated double quote")
+}
+
+// indexFrom returns the position of the first r in runes from start on, or
+// -1
+func indexFrom(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
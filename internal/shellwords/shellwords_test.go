@@ -0,0 +1,68 @@
+package shellwords
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"less -R", []string{"less", "-R"}},
+		{"  less\t-R\n", []string{"less", "-R"}},
+		{"code --wait", []string{"code", "--wait"}},
+		{`"/Applications/Sublime Text.app/bin/subl" -w`, []string{"/Applications/Sublime Text.app/bin/subl", "-w"}},
+		{`'/opt/my editor/bin/ed' -n`, []string{"/opt/my editor/bin/ed", "-n"}},
+		{`/opt/my\ editor/ed`, []string{"/opt/my editor/ed"}},
+		{`emacsclient -t -a ''`, []string{"emacsclient", "-t", "-a", ""}},
+		{`less --prompt="page %d"`, []string{"less", "--prompt=page %d"}},
+		{`a"b c"'d e'f`, []string{"ab cd ef"}},
+		{`echo "say \"hi\" \\ \n"`, []string{"echo", `say "hi" \ \n`}},
+		{`echo 'it''s'`, []string{"echo", "its"}},
+		{`echo '$HOME | x'`, []string{"echo", "$HOME | x"}},
+		{`echo \$HOME \|`, []string{"echo", "$HOME", "|"}},
+		{"bash -o pipefail \\\n -c", []string{"bash", "-o", "pipefail", "-c"}},
+		{"vim -c 'set ft=markdown'", []string{"vim", "-c", "set ft=markdown"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := Split(tt.line)
+			if err != nil {
+				t.Fatalf("Split failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{`less "-R`, "unterminated double quote"},
+		{`less '-R`, "unterminated single quote"},
+		{`less -R\`, "ends with a backslash"},
+		{`less -R | tee log`, "unsupported shell syntax '|'"},
+		{`less -R > out`, "unsupported shell syntax '>'"},
+		{`vim; rm -rf x`, "unsupported shell syntax ';'"},
+		{`less $OPTS`, "unsupported shell syntax '$'"},
+		{"vim `which x`", "unsupported shell syntax '`'"},
+		{`vim "$HOME/x"`, "unsupported substitution '$'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			_, err := Split(tt.line)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package snippet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotFound is returned for a snippet that does not exist
+var ErrNotFound = errors.New("snippet not found")
+
+// ErrExists is returned when saving a snippet under a name already in use
+var ErrExists = errors.New("snippet already exists")
+
+// namePattern restricts snippet names to what is safe as a file name
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Snippet is an example saved together with the placeholder values it was
+// filled with
+type Snippet struct {
+	Name     string `yaml:"name"`
+	Page     string `yaml:"page"`
+	Platform string `yaml:"platform"`
+	// Description is the description of the example
+	Description string `yaml:"description"`
+	// Template is the example's command with its placeholders
+	Template string            `yaml:"template"`
+	Vars     map[string]string `yaml:"vars,omitempty"`
+	// Command is the template rendered with Vars
+	Command string `yaml:"command"`
+	// Env sets environment variables for Command, as NAME=value, on top
+	// of those page_env in the config sets for Page
+	Env []string `yaml:"env,omitempty"`
+	// Steps are set for a snippet saved from several examples, each filled
+	// with its own values. Template and Command then join those of the
+	// steps and Vars is unused.
+	Steps   []Step    `yaml:"steps,omitempty"`
+	Created time.Time `yaml:"created"`
+}
+
+// Step is one of the commands of a snippet saved from several examples
+type Step struct {
+	Template string            `yaml:"template"`
+	Vars     map[string]string `yaml:"vars,omitempty"`
+}
+
+// StepSeparator joins the commands of a snippet's steps, so they run in
+// order and stop at the first failure
+const StepSeparator = " && "
+
+// Store keeps snippets as one YAML file each in a directory
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store for the snippets in dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// ValidName reports whether name can be used for a snippet
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Save stores a snippet. An existing snippet of the same name is only
+// replaced if overwrite is set.
+func (s *Store) Save(snippet *Snippet, overwrite bool) error {
+	if !ValidName(snippet.Name) {
+		return fmt.Errorf("invalid snippet name %q: use letters, digits, '.', '_' and '-'", snippet.Name)
+	}
+
+	path := s.path(snippet.Name)
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return fmt.Errorf("%w: %s", ErrExists, snippet.Name)
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create snippets directory: %w", err)
+	}
+
+	if snippet.Created.IsZero() {
+		snippet.Created = time.Now()
+	}
+
+	// Secret values are never written to disk; they are asked for again
+	// when the snippet runs
+	stored := *snippet
+	stored.Command, stored.Vars, _ = types.Redact(snippet.Command, snippet.Template, snippet.Vars)
+	if len(snippet.Steps) > 0 {
+		stored.Steps = make([]Step, len(snippet.Steps))
+		commands := make([]string, len(snippet.Steps))
+		for i, step := range snippet.Steps {
+			example := types.NewExample("", step.Template)
+			commands[i], stored.Steps[i].Vars, _ = example.Redact(step.Vars)
+			stored.Steps[i].Template = step.Template
+		}
+		stored.Command = strings.Join(commands, StepSeparator)
+	}
+	data, err := yaml.Marshal(&stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode snippet: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// Load reads the snippet with the given name
+func (s *Store) Load(name string) (*Snippet, error) {
+	if !ValidName(name) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	data, err := os.ReadFile(s.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to read snippet: %w", err)
+	}
+
+	var snippet Snippet
+	if err := yaml.Unmarshal(data, &snippet); err != nil {
+		return nil, fmt.Errorf("failed to parse snippet %s: %w", name, err)
+	}
+	snippet.Name = name
+
+	return &snippet, nil
+}
+
+// List returns every snippet, sorted by name. Files that can't be read are
+// skipped.
+func (s *Store) List() ([]*Snippet, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.yml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snippets: %w", err)
+	}
+
+	var snippets []*Snippet
+	for _, file := range files {
+		snippet, err := s.Load(strings.TrimSuffix(filepath.Base(file), ".yml"))
+		if err != nil {
+			continue
+		}
+		snippets = append(snippets, snippet)
+	}
+
+	sort.Slice(snippets, func(i, j int) bool {
+		return snippets[i].Name < snippets[j].Name
+	})
+	return snippets, nil
+}
+
+// Remove deletes the snippet with the given name
+func (s *Store) Remove(name string) error {
+	if !ValidName(name) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	if err := os.Remove(s.path(name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return fmt.Errorf("failed to remove snippet: %w", err)
+	}
+	return nil
+}
+
+// path returns the file a snippet is stored in
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+".yml")
+}
@@ -0,0 +1,180 @@
+package snippet
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	saved := &Snippet{
+		Name:     "backup",
+		Page:     "tar",
+		Platform: "common",
+		Template: "tar -czf {{target.tar.gz}} {{path/to/file}}",
+		Vars:     map[string]string{"target": "backup.tar.gz", "file": "src"},
+		Command:  "tar -czf backup.tar.gz src",
+	}
+	if err := store.Save(saved, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load("backup")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Command != saved.Command || loaded.Vars["file"] != "src" {
+		t.Errorf("Expected %+v, got %+v", saved, loaded)
+	}
+	if loaded.Created.IsZero() {
+		t.Error("Expected creation time to be recorded")
+	}
+}
+
+func TestSaveRedactsSecrets(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	saved := &Snippet{
+		Name:     "db",
+		Page:     "mysql",
+		Platform: "common",
+		Template: "mysql -u {{user}} -p{{password}} {{database}}",
+		Vars:     map[string]string{"user": "admin", "password": "hunter2", "database": "app"},
+		Command:  "mysql -u admin -phunter2 app",
+	}
+	if err := store.Save(saved, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "db.yml"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Errorf("Expected the secret not to be written, got:\n%s", data)
+	}
+
+	loaded, err := store.Load("db")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Command != "mysql -u admin -p******** app" || loaded.Vars["password"] != "********" || loaded.Vars["user"] != "admin" {
+		t.Errorf("Expected the secret to be redacted, got %+v", loaded)
+	}
+	// The caller's snippet is left as it was
+	if saved.Vars["password"] != "hunter2" {
+		t.Errorf("Expected Save not to change the snippet, got %+v", saved)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, "db.yml"))
+		if err != nil {
+			t.Fatalf("Stat failed: %v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("Expected permissions 0600, got %v", info.Mode().Perm())
+		}
+	}
+}
+
+func TestSaveSteps(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	// Both steps fill "file", each with its own value
+	saved := &Snippet{
+		Name:     "pack",
+		Page:     "tar",
+		Platform: "common",
+		Template: "tar -cf {{archive}} {{path/to/file}} && gpg -c --passphrase {{passphrase}} {{path/to/file}}",
+		Command:  "tar -cf out.tar src && gpg -c --passphrase s3cret out.tar",
+		Steps: []Step{
+			{Template: "tar -cf {{archive}} {{path/to/file}}", Vars: map[string]string{"archive": "out.tar", "file": "src"}},
+			{Template: "gpg -c --passphrase {{passphrase}} {{path/to/file}}", Vars: map[string]string{"passphrase": "s3cret", "file": "out.tar"}},
+		},
+	}
+	if err := store.Save(saved, false); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load("pack")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Steps) != 2 {
+		t.Fatalf("Expected 2 steps, got %+v", loaded.Steps)
+	}
+	if loaded.Steps[0].Vars["file"] != "src" || loaded.Steps[1].Vars["file"] != "out.tar" {
+		t.Errorf("Expected each step to keep its own value, got %+v", loaded.Steps)
+	}
+	if loaded.Steps[1].Vars["passphrase"] != "********" {
+		t.Errorf("Expected the passphrase to be redacted, got %+v", loaded.Steps[1].Vars)
+	}
+	if loaded.Command != "tar -cf out.tar src && gpg -c --passphrase ******** out.tar" {
+		t.Errorf("Unexpected command: %s", loaded.Command)
+	}
+}
+
+func TestSaveExisting(t *testing.T) {
+	store := NewStore(t.TempDir())
+	store.Save(&Snippet{Name: "ls", Command: "ls -la"}, false)
+
+	err := store.Save(&Snippet{Name: "ls", Command: "ls -lh"}, false)
+	if !errors.Is(err, ErrExists) {
+		t.Errorf("Expected ErrExists, got %v", err)
+	}
+
+	if err := store.Save(&Snippet{Name: "ls", Command: "ls -lh"}, true); err != nil {
+		t.Fatalf("Save with overwrite failed: %v", err)
+	}
+	loaded, _ := store.Load("ls")
+	if loaded.Command != "ls -lh" {
+		t.Errorf("Expected overwritten command 'ls -lh', got '%s'", loaded.Command)
+	}
+}
+
+func TestInvalidNames(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	for _, name := range []string{"", "../escape", "a/b", ".hidden", "with space"} {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Save(&Snippet{Name: name}, false); err == nil {
+				t.Errorf("Expected an error for name %q", name)
+			}
+			if _, err := store.Load(name); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound for name %q, got %v", name, err)
+			}
+		})
+	}
+}
+
+func TestListAndRemove(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	if snippets, err := store.List(); err != nil || len(snippets) != 0 {
+		t.Fatalf("Expected no snippets in a missing directory, got %v (%v)", snippets, err)
+	}
+
+	store.Save(&Snippet{Name: "zip", Command: "zip -r a.zip ."}, false)
+	store.Save(&Snippet{Name: "archive", Command: "tar -cf a.tar ."}, false)
+
+	snippets, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(snippets) != 2 || snippets[0].Name != "archive" || snippets[1].Name != "zip" {
+		t.Errorf("Expected [archive zip], got %v", snippets)
+	}
+
+	if err := store.Remove("zip"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := store.Remove("zip"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound removing twice, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// dayFormat keys the daily usage counts
+const dayFormat = "2006-01-02"
+
+// PageUsage is how a page has been used
+type PageUsage struct {
+	Page     string `json:"page"`
+	Platform string `json:"platform"`
+	// Views counts the times the page was opened
+	Views int `json:"views"`
+	// Examples counts the uses of each example, keyed by its template
+	Examples map[string]int `json:"examples,omitempty"`
+	// Placeholders counts the placeholders of the examples used, and
+	// Filled how many of them were given a value
+	Placeholders int       `json:"placeholders"`
+	Filled       int       `json:"filled"`
+	LastUsed     time.Time `json:"last_used"`
+}
+
+// Uses is how often the page was viewed or one of its examples used
+func (u *PageUsage) Uses() int {
+	uses := u.Views
+	for _, n := range u.Examples {
+		uses += n
+	}
+	return uses
+}
+
+// ExampleCount is how often an example was used
+type ExampleCount struct {
+	Page    string `json:"page"`
+	Example string `json:"example"`
+	Count   int    `json:"count"`
+}
+
+// DayCount is the number of uses on one day
+type DayCount struct {
+	Day   string `json:"day"`
+	Count int    `json:"count"`
+}
+
+// Stats is the local record of page and example usage. Nothing in it
+// leaves the machine.
+type Stats struct {
+	path string
+	// Pages is keyed by platform/name
+	Pages map[string]*PageUsage `json:"pages"`
+	// Days counts uses per day
+	Days map[string]int `json:"days"`
+}
+
+// Load reads the stats from path, returning empty stats if the file does
+// not exist yet
+func Load(path string) (*Stats, error) {
+	s := &Stats{
+		path:  path,
+		Pages: make(map[string]*PageUsage),
+		Days:  make(map[string]int),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, fmt.Errorf("failed to read usage stats: %w", err)
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return s, fmt.Errorf("failed to parse usage stats: %w", err)
+	}
+	if s.Pages == nil {
+		s.Pages = make(map[string]*PageUsage)
+	}
+	if s.Days == nil {
+		s.Days = make(map[string]int)
+	}
+	return s, nil
+}
+
+// Save writes the stats back to disk
+func (s *Stats) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage stats: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write usage stats: %w", err)
+	}
+	return nil
+}
+
+// RecordView counts a page being opened at t
+func (s *Stats) RecordView(page, platform string, t time.Time) {
+	s.usage(page, platform, t).Views++
+}
+
+// RecordExample counts an example of a page being used at t, along with
+// how many of its placeholders were filled
+func (s *Stats) RecordExample(page, platform, example string, placeholders, filled int, t time.Time) {
+	u := s.usage(page, platform, t)
+	if u.Examples == nil {
+		u.Examples = make(map[string]int)
+	}
+	u.Examples[example]++
+	u.Placeholders += placeholders
+	u.Filled += filled
+}
+
+// usage returns the entry for a page, counting a use on t's day
+func (s *Stats) usage(page, platform string, t time.Time) *PageUsage {
+	key := platform + "/" + page
+	u, ok := s.Pages[key]
+	if !ok {
+		u = &PageUsage{Page: page, Platform: platform}
+		s.Pages[key] = u
+	}
+	u.LastUsed = t
+	s.Days[t.Format(dayFormat)]++
+	return u
+}
+
+// TopPages returns up to n of the most used pages, most used first
+func (s *Stats) TopPages(n int) []PageUsage {
+	pages := make([]PageUsage, 0, len(s.Pages))
+	for _, u := range s.Pages {
+		pages = append(pages, *u)
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		if pages[i].Uses() != pages[j].Uses() {
+			return pages[i].Uses() > pages[j].Uses()
+		}
+		return pages[i].LastUsed.After(pages[j].LastUsed)
+	})
+	if len(pages) > n {
+		pages = pages[:n]
+	}
+	return pages
+}
+
+// TopExamples returns up to n of the most used examples, most used first
+func (s *Stats) TopExamples(n int) []ExampleCount {
+	var examples []ExampleCount
+	for _, u := range s.Pages {
+		for example, count := range u.Examples {
+			examples = append(examples, ExampleCount{Page: u.Page, Example: example, Count: count})
+		}
+	}
+	sort.Slice(examples, func(i, j int) bool {
+		if examples[i].Count != examples[j].Count {
+			return examples[i].Count > examples[j].Count
+		}
+		return examples[i].Example < examples[j].Example
+	})
+	if len(examples) > n {
+		examples = examples[:n]
+	}
+	return examples
+}
+
+// Daily returns the uses on each of the last days days up to now, oldest
+// first, including days without any
+func (s *Stats) Daily(days int, now time.Time) []DayCount {
+	counts := make([]DayCount, days)
+	for i := range counts {
+		day := now.AddDate(0, 0, i-days+1).Format(dayFormat)
+		counts[i] = DayCount{Day: day, Count: s.Days[day]}
+	}
+	return counts
+}
+
+// FillRate returns the fraction of placeholders that were given a value
+// across all examples used, and false if no example had placeholders
+func (s *Stats) FillRate() (float64, bool) {
+	var placeholders, filled int
+	for _, u := range s.Pages {
+		placeholders += u.Placeholders
+		filled += u.Filled
+	}
+	if placeholders == 0 {
+		return 0, false
+	}
+	return float64(filled) / float64(placeholders), true
+}
@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	s.RecordView("tar", "common", now)
+	s.RecordExample("tar", "common", "tar -xf {{file}}", 1, 1, now)
+	s.RecordExample("tar", "common", "tar -xf {{file}}", 1, 0, now)
+	s.RecordExample("ls", "common", "ls -la", 0, 0, now.AddDate(0, 0, -1))
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	top := loaded.TopPages(5)
+	if len(top) != 2 || top[0].Page != "tar" || top[0].Uses() != 3 {
+		t.Fatalf("Expected tar used 3 times first, got %+v", top)
+	}
+	if got := loaded.TopPages(1); len(got) != 1 {
+		t.Errorf("Expected 1 page, got %d", len(got))
+	}
+
+	examples := loaded.TopExamples(5)
+	if len(examples) != 2 || examples[0].Example != "tar -xf {{file}}" || examples[0].Count != 2 {
+		t.Errorf("Expected the tar example used twice first, got %+v", examples)
+	}
+
+	rate, ok := loaded.FillRate()
+	if !ok || rate != 0.5 {
+		t.Errorf("Expected a fill rate of 0.5, got %v (%v)", rate, ok)
+	}
+}
+
+func TestDaily(t *testing.T) {
+	s, _ := Load(filepath.Join(t.TempDir(), "stats.json"))
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	s.RecordView("tar", "common", now)
+	s.RecordView("tar", "common", now)
+	s.RecordView("ls", "common", now.AddDate(0, 0, -2))
+	s.RecordView("ls", "common", now.AddDate(0, 0, -10))
+
+	expected := []DayCount{
+		{"2026-03-08", 1},
+		{"2026-03-09", 0},
+		{"2026-03-10", 2},
+	}
+	got := s.Daily(3, now)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected[i], got[i])
+		}
+	}
+}
+
+func TestFillRateWithoutPlaceholders(t *testing.T) {
+	s, _ := Load(filepath.Join(t.TempDir(), "stats.json"))
+	s.RecordExample("ls", "common", "ls", 0, 0, time.Now())
+
+	if _, ok := s.FillRate(); ok {
+		t.Error("Expected no fill rate without placeholders")
+	}
+}
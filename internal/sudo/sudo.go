@@ -0,0 +1,116 @@
+// Package sudo runs commands as root: it tells which commands usually need
+// to, and wraps a command line so that sudo runs all of it, pipelines and
+// redirections included, rather than only its first program.
+package sudo
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/shellwords"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// ErrUnavailable is returned where commands can't be run with sudo
+var ErrUnavailable = errors.New("sudo is not available: install it, or run tldrpp as root")
+
+// rootPrograms are programs that mostly manage the system, and fail or do
+// little when run as another user
+var rootPrograms = map[string]bool{
+	"apt": true, "apt-get": true, "aptitude": true, "dpkg": true,
+	"dnf": true, "yum": true, "rpm": true, "zypper": true, "pacman": true, "apk": true, "emerge": true,
+	"systemctl": true, "service": true,
+	"mount": true, "umount": true, "swapon": true, "swapoff": true,
+	"fdisk": true, "parted": true, "mkswap": true, "blkid": true, "lvcreate": true, "pvcreate": true, "vgcreate": true,
+	"useradd": true, "usermod": true, "userdel": true, "groupadd": true, "groupdel": true, "chpasswd": true,
+	"modprobe": true, "insmod": true, "rmmod": true,
+	"iptables": true, "ip6tables": true, "nft": true, "ufw": true, "firewall-cmd": true,
+	"shutdown": true, "reboot": true, "halt": true, "poweroff": true,
+	"update-grub": true, "grub-install": true, "visudo": true, "chroot": true,
+}
+
+// NeedsRoot reports whether command runs a program that usually needs
+// root, such as a package manager, and isn't run with sudo or as root yet.
+// Only the first program of the command is looked at.
+func NeedsRoot(command string) bool {
+	if isRoot() {
+		return false
+	}
+	program := firstProgram(command)
+	if program == "sudo" || program == "doas" {
+		return false
+	}
+	if rootPrograms[program] {
+		// systemctl --user manages the user's own services
+		return !(program == "systemctl" && strings.Contains(command, "--user"))
+	}
+	return strings.HasPrefix(program, "mkfs")
+}
+
+// firstProgram returns the name of the program command starts with,
+// skipping variable assignments before it
+func firstProgram(command string) string {
+	for _, word := range strings.Fields(command) {
+		if name, _, ok := strings.Cut(word, "="); ok && name != "" && !strings.ContainsAny(name, "/'\"") {
+			continue
+		}
+		word = strings.Trim(word, `'"`)
+		if i := strings.LastIndexByte(word, '/'); i >= 0 {
+			word = word[i+1:]
+		}
+		return word
+	}
+	return ""
+}
+
+// Wrap returns command run with sudo. A simple command gets sudo in front
+// of it; one with pipes, lists, redirections or substitutions is passed to
+// sudo sh -c, so that every part of it runs as root. sudo resets the
+// environment, so the variables in env, as NAME=value, are set by env run
+// as root in front of the command. A command already run with sudo, or any
+// command when tldrpp runs as root, is returned unchanged.
+func Wrap(command string, env []string) string {
+	command = strings.TrimSpace(command)
+	if isRoot() || firstProgram(command) == "sudo" {
+		return command
+	}
+	prefix := "sudo "
+	if len(env) > 0 {
+		quoted := make([]string, len(env))
+		for i, entry := range env {
+			quoted[i] = types.ShellQuote(entry)
+		}
+		prefix += "env " + strings.Join(quoted, " ") + " "
+	}
+	if _, err := shellwords.Split(command); err != nil {
+		return prefix + "sh -c " + types.ShellQuote(command)
+	}
+	return prefix + command
+}
+
+// Available returns ErrUnavailable if commands can't be run with sudo here.
+// Running as root needs no sudo.
+func Available() error {
+	if runtime.GOOS == "windows" {
+		return ErrUnavailable
+	}
+	if isRoot() {
+		return nil
+	}
+	if _, err := exec.LookPath("sudo"); err != nil {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// euid returns the user tldrpp runs as. It is a variable so tests can run
+// as another user than root.
+var euid = os.Geteuid
+
+// isRoot reports whether tldrpp runs as root, and sudo isn't needed
+func isRoot() bool {
+	return euid() == 0
+}
@@ -0,0 +1,71 @@
+package sudo
+
+import "testing"
+
+// asUser has the tests run as a user other than root
+func asUser(t *testing.T, uid int) {
+	t.Helper()
+	old := euid
+	euid = func() int { return uid }
+	t.Cleanup(func() { euid = old })
+}
+
+func TestNeedsRoot(t *testing.T) {
+	asUser(t, 1000)
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"apt install ripgrep", true},
+		{"DEBIAN_FRONTEND=noninteractive apt-get upgrade", true},
+		{"/usr/sbin/modprobe loop", true},
+		{"mkfs.ext4 /dev/sdb1", true},
+		{"systemctl restart nginx", true},
+		{"systemctl --user restart syncthing", false},
+		{"sudo apt update", false},
+		{"ls -la", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := NeedsRoot(tt.command); got != tt.want {
+			t.Errorf("NeedsRoot(%q) = %v, expected %v", tt.command, got, tt.want)
+		}
+	}
+
+	asUser(t, 0)
+	if NeedsRoot("apt update") {
+		t.Error("Expected root to need no sudo")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	asUser(t, 1000)
+	tests := []struct {
+		command, want string
+	}{
+		{"apt install 'ripgrep'", "sudo apt install 'ripgrep'"},
+		{"  systemctl restart nginx ", "sudo systemctl restart nginx"},
+		{"sudo apt update", "sudo apt update"},
+		{"echo 1 > /proc/sys/vm/drop_caches", `sudo sh -c 'echo 1 > /proc/sys/vm/drop_caches'`},
+		{"apt update && apt upgrade", `sudo sh -c 'apt update && apt upgrade'`},
+		{"cat /etc/shadow | grep 'root'", `sudo sh -c 'cat /etc/shadow | grep '\''root'\'''`},
+	}
+	for _, tt := range tests {
+		if got := Wrap(tt.command, nil); got != tt.want {
+			t.Errorf("Wrap(%q) = %q, expected %q", tt.command, got, tt.want)
+		}
+	}
+
+	env := []string{"DEBIAN_FRONTEND=noninteractive", "MSG=a b"}
+	if got, want := Wrap("apt upgrade", env), `sudo env DEBIAN_FRONTEND=noninteractive 'MSG=a b' apt upgrade`; got != want {
+		t.Errorf("Wrap with env = %q, expected %q", got, want)
+	}
+	if got, want := Wrap("apt update && apt upgrade", env[:1]), `sudo env DEBIAN_FRONTEND=noninteractive sh -c 'apt update && apt upgrade'`; got != want {
+		t.Errorf("Wrap with env = %q, expected %q", got, want)
+	}
+
+	asUser(t, 0)
+	if got := Wrap("apt update", env); got != "apt update" {
+		t.Errorf("Expected root to run commands as they are, got %q", got)
+	}
+}
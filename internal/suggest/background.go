@@ -0,0 +1,46 @@
+package suggest
+
+import (
+	"sync"
+	"time"
+)
+
+// listTTL is how long values listed in the background are used before
+// they are listed again
+const listTTL = 30 * time.Second
+
+// backgroundList lists values in the background, so that asking for them
+// never waits on a slow command. There are none until the first listing is
+// done; after that the last values listed are returned while newer ones are
+// listed, once they are older than listTTL.
+type backgroundList struct {
+	list func() []string
+
+	mu      sync.Mutex
+	values  []string
+	listed  time.Time
+	loading bool
+}
+
+// newBackgroundList returns a list filled by list
+func newBackgroundList(list func() []string) *backgroundList {
+	return &backgroundList{list: list}
+}
+
+// get returns the values listed last, starting a listing if there are none
+// yet or they are stale
+func (b *backgroundList) get() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.loading && time.Since(b.listed) > listTTL {
+		b.loading = true
+		go func() {
+			values := b.list()
+			b.mu.Lock()
+			b.values, b.listed, b.loading = values, time.Now(), false
+			b.mu.Unlock()
+		}()
+	}
+	return b.values
+}
@@ -0,0 +1,38 @@
+package suggest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// commandTimeout bounds how long a command run for suggestions may take, so
+// that a slow or hung tool doesn't hold up the prompt
+const commandTimeout = time.Second
+
+// commandLines runs name with args in dir, the working directory if empty,
+// and returns the non-empty lines it prints. It returns nil if the command
+// isn't installed, fails or takes longer than commandTimeout.
+func commandLines(dir, name string, args ...string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
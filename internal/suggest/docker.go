@@ -0,0 +1,87 @@
+package suggest
+
+import (
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// Docker suggests the containers, images and volumes of Docker, or of Podman
+// where Docker isn't installed, for placeholders named after them. They are
+// listed in the background, so suggestions appear once the listing is done.
+type Docker struct {
+	tool string
+
+	once       sync.Once
+	containers *backgroundList
+	images     *backgroundList
+	volumes    *backgroundList
+}
+
+// NewDocker returns a provider running tool, docker or podman, whichever is
+// installed, if tool is empty
+func NewDocker(tool string) *Docker {
+	return &Docker{tool: tool}
+}
+
+// Name returns the provider name
+func (d *Docker) Name() string {
+	return "docker"
+}
+
+// Suggest returns the containers for placeholders such as {{container}},
+// the images for {{image}} and the volumes for {{volume}}. Paths such as
+// {{path/to/image.png}} are left to the files provider.
+func (d *Docker) Suggest(placeholder types.Placeholder) []string {
+	name := strings.ToLower(placeholder.Name)
+	if placeholder.Type == "file" || placeholder.Type == "directory" {
+		return nil
+	}
+	if !strings.Contains(name, "container") && !strings.Contains(name, "image") && !strings.Contains(name, "volume") {
+		return nil
+	}
+	d.once.Do(d.init)
+	if d.tool == "" {
+		return nil
+	}
+
+	switch {
+	case strings.Contains(name, "container"):
+		return d.containers.get()
+	case strings.Contains(name, "image"):
+		return d.images.get()
+	default:
+		return d.volumes.get()
+	}
+}
+
+// init finds the tool and sets up the lists. Docker and Podman take the same
+// arguments.
+func (d *Docker) init() {
+	if d.tool == "" {
+		for _, tool := range []string{"docker", "podman"} {
+			if _, err := exec.LookPath(tool); err == nil {
+				d.tool = tool
+				break
+			}
+		}
+	}
+	d.containers = newBackgroundList(func() []string {
+		return commandLines("", d.tool, "ps", "--all", "--format", "{{.Names}}")
+	})
+	d.images = newBackgroundList(func() []string {
+		var images []string
+		for _, image := range commandLines("", d.tool, "images", "--format", "{{.Repository}}:{{.Tag}}") {
+			// Dangling images have neither
+			if !strings.Contains(image, "<none>") {
+				images = append(images, image)
+			}
+		}
+		return images
+	})
+	d.volumes = newBackgroundList(func() []string {
+		return commandLines("", d.tool, "volume", "ls", "--format", "{{.Name}}")
+	})
+}
@@ -0,0 +1,79 @@
+package suggest
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// External suggests values from the provider programs in a directory. Each
+// program is run with the placeholder's name and type as arguments, in the
+// working directory, and prints one suggested value per line. A program that
+// fails or takes longer than commandTimeout suggests nothing. Programs are
+// asked once per placeholder name and type.
+type External struct {
+	dir string
+
+	once     sync.Once
+	programs []string
+
+	mu    sync.Mutex
+	cache map[string][]string
+}
+
+// NewExternal returns a provider running the executables in dir
+func NewExternal(dir string) *External {
+	return &External{dir: dir, cache: make(map[string][]string)}
+}
+
+// Name returns the provider name
+func (e *External) Name() string {
+	return "external"
+}
+
+// Suggest returns what the provider programs print for placeholder, in the
+// programs' name order
+func (e *External) Suggest(placeholder types.Placeholder) []string {
+	e.once.Do(e.load)
+	if len(e.programs) == 0 {
+		return nil
+	}
+
+	key := placeholder.Name + "\x00" + placeholder.Type
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if values, ok := e.cache[key]; ok {
+		return values
+	}
+	var values []string
+	for _, program := range e.programs {
+		values = append(values, commandLines("", program, placeholder.Name, placeholder.Type)...)
+	}
+	e.cache[key] = values
+	return values
+}
+
+// load finds the executables in the directory
+func (e *External) load() {
+	if e.dir == "" {
+		return
+	}
+	entries, err := os.ReadDir(e.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		// Windows has no executable bit; anything there may be run
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		e.programs = append(e.programs, filepath.Join(e.dir, entry.Name()))
+	}
+}
@@ -0,0 +1,66 @@
+package suggest
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// Files suggests the files and directories in dir for file and directory
+// placeholders, leaving out hidden ones. The directory is read once, on the
+// first suggestion.
+type Files struct {
+	dir   string
+	once  sync.Once
+	files []string
+	dirs  []string
+}
+
+// NewFiles returns a provider listing the entries of dir, the working
+// directory if dir is empty
+func NewFiles(dir string) *Files {
+	if dir == "" {
+		dir = "."
+	}
+	return &Files{dir: dir}
+}
+
+// Name returns the provider name
+func (f *Files) Name() string {
+	return "files"
+}
+
+// Suggest returns the files for file placeholders and the directories for
+// directory placeholders
+func (f *Files) Suggest(placeholder types.Placeholder) []string {
+	f.once.Do(f.load)
+
+	switch placeholder.Type {
+	case "file":
+		return f.files
+	case "directory":
+		return f.dirs
+	default:
+		return nil
+	}
+}
+
+// load lists the directory, in name order
+func (f *Files) load() {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if entry.IsDir() {
+			f.dirs = append(f.dirs, entry.Name())
+		} else {
+			f.files = append(f.files, entry.Name())
+		}
+	}
+}
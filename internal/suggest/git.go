@@ -0,0 +1,96 @@
+package suggest
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// recentCommits is how many commits back files count as recently touched
+const recentCommits = 10
+
+// Git suggests from the repository in dir: its local branches for branch
+// placeholders, most recently committed to first, its tags, newest first,
+// its remotes, and for file placeholders the files recently touched:
+// changed, untracked or in the last commits. They are listed in the
+// background, so suggestions appear once the listing is done.
+type Git struct {
+	dir      string
+	branches *backgroundList
+	tags     *backgroundList
+	remotes  *backgroundList
+	files    *backgroundList
+}
+
+// NewGit returns a provider reading the repository in dir, the working
+// directory if dir is empty
+func NewGit(dir string) *Git {
+	g := &Git{dir: dir}
+	g.branches = newBackgroundList(func() []string {
+		return commandLines(g.dir, "git", "for-each-ref", "--sort=-committerdate", "--format=%(refname:short)", "refs/heads")
+	})
+	g.tags = newBackgroundList(func() []string {
+		return commandLines(g.dir, "git", "tag", "--sort=-creatordate")
+	})
+	g.remotes = newBackgroundList(func() []string {
+		return commandLines(g.dir, "git", "remote")
+	})
+	g.files = newBackgroundList(g.touchedFiles)
+	return g
+}
+
+// Name returns the provider name
+func (g *Git) Name() string {
+	return "git"
+}
+
+// Suggest returns the branches for placeholders such as {{branch}} or
+// {{branch_name}}, the tags for {{tag}}, the remotes for {{remote}} and the
+// recently touched files for file placeholders. Only text placeholders get
+// tags and remotes, so {{remote_host}} still gets hosts.
+func (g *Git) Suggest(placeholder types.Placeholder) []string {
+	name := strings.ToLower(placeholder.Name)
+	switch {
+	case placeholder.Type == "file":
+		return g.files.get()
+	case strings.Contains(name, "branch"):
+		return g.branches.get()
+	case placeholder.Type != "text":
+		return nil
+	case strings.Contains(name, "tag"):
+		return g.tags.get()
+	case strings.Contains(name, "remote"):
+		return g.remotes.get()
+	default:
+		return nil
+	}
+}
+
+// touchedFiles lists the files changed since the last commit, the untracked
+// ones and those changed by the last recentCommits commits, in that order,
+// relative to dir. Files since deleted are left out.
+func (g *Git) touchedFiles() []string {
+	lists := [][]string{
+		commandLines(g.dir, "git", "diff", "--name-only", "--relative", "HEAD"),
+		commandLines(g.dir, "git", "ls-files", "--others", "--exclude-standard"),
+		commandLines(g.dir, "git", "log", "--name-only", "--relative", "--format=", "--max-count="+strconv.Itoa(recentCommits)),
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, file := range list {
+			if seen[file] {
+				continue
+			}
+			seen[file] = true
+			if _, err := os.Stat(filepath.Join(g.dir, file)); err == nil {
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
@@ -0,0 +1,82 @@
+package suggest
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// Kubectl suggests the contexts of the kubeconfig for context placeholders
+// and, when asking the cluster is allowed, the namespaces, pods and
+// deployments of the current context. Contexts are read once, on the first
+// suggestion; the cluster is asked in the background, so its suggestions
+// appear once the listing is done.
+type Kubectl struct {
+	tool    string
+	cluster bool
+
+	once        sync.Once
+	contexts    []string
+	namespaces  *backgroundList
+	pods        *backgroundList
+	deployments *backgroundList
+}
+
+// NewKubectl returns a provider running tool, kubectl if empty. Only with
+// cluster set does it ask the cluster, using the credentials of the
+// current context.
+func NewKubectl(tool string, cluster bool) *Kubectl {
+	if tool == "" {
+		tool = "kubectl"
+	}
+	k := &Kubectl{tool: tool, cluster: cluster}
+	k.namespaces = k.resources("namespaces")
+	k.pods = k.resources("pods")
+	k.deployments = k.resources("deployments")
+	return k
+}
+
+// Name returns the provider name
+func (k *Kubectl) Name() string {
+	return "kubectl"
+}
+
+// Suggest returns the contexts for placeholders such as {{context}}, and
+// with cluster access the namespaces for {{namespace}}, the pods for {{pod}}
+// and the deployments for {{deployment}}
+func (k *Kubectl) Suggest(placeholder types.Placeholder) []string {
+	name := strings.ToLower(placeholder.Name)
+	switch {
+	case strings.Contains(name, "context"):
+		k.once.Do(func() {
+			k.contexts = commandLines("", k.tool, "config", "get-contexts", "--output=name")
+		})
+		return k.contexts
+	case !k.cluster:
+		return nil
+	case strings.Contains(name, "namespace"):
+		return k.namespaces.get()
+	case strings.Contains(name, "pod"):
+		return k.pods.get()
+	case strings.Contains(name, "deployment"):
+		return k.deployments.get()
+	default:
+		return nil
+	}
+}
+
+// resources lists the names of a kind of resource in the current namespace
+func (k *Kubectl) resources(kind string) *backgroundList {
+	return newBackgroundList(func() []string {
+		var names []string
+		// --output=name prints kind/name, e.g. pod/web-1
+		for _, line := range commandLines("", k.tool, "get", kind, "--output=name") {
+			if i := strings.Index(line, "/"); i >= 0 {
+				line = line[i+1:]
+			}
+			names = append(names, line)
+		}
+		return names
+	})
+}
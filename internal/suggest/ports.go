@@ -0,0 +1,24 @@
+package suggest
+
+import (
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// commonPorts are the ports suggested for {{port}}, most used first
+var commonPorts = []string{"22", "80", "443", "8080", "3000", "5432", "3306", "6379", "27017", "8443"}
+
+// Ports suggests commonly used ports for port placeholders
+type Ports struct{}
+
+// Name returns the provider name
+func (Ports) Name() string {
+	return "ports"
+}
+
+// Suggest returns the common ports for port placeholders
+func (Ports) Suggest(placeholder types.Placeholder) []string {
+	if placeholder.Type != "port" {
+		return nil
+	}
+	return commonPorts
+}
@@ -0,0 +1,153 @@
+package suggest
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// SSH suggests hosts, addresses and users from ~/.ssh/config and
+// ~/.ssh/known_hosts. The files are read once, on the first suggestion.
+type SSH struct {
+	dir   string
+	once  sync.Once
+	hosts []string
+	ips   []string
+	users []string
+}
+
+// NewSSH returns a provider reading the SSH files in dir, ~/.ssh if dir is
+// empty
+func NewSSH(dir string) *SSH {
+	if dir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, ".ssh")
+		}
+	}
+	return &SSH{dir: dir}
+}
+
+// Name returns the provider name
+func (s *SSH) Name() string {
+	return "ssh"
+}
+
+// Suggest returns the known hosts for host placeholders, the known IP
+// addresses for ip placeholders and the configured users for username
+// placeholders
+func (s *SSH) Suggest(placeholder types.Placeholder) []string {
+	if s.dir == "" {
+		return nil
+	}
+	s.once.Do(s.load)
+
+	switch placeholder.Type {
+	case "host":
+		return s.hosts
+	case "ip":
+		return s.ips
+	case "username":
+		return s.users
+	default:
+		return nil
+	}
+}
+
+// load reads the SSH config first, so that its aliases come before the
+// hosts merely seen in known_hosts
+func (s *SSH) load() {
+	seen := make(map[*[]string]map[string]bool)
+	add := func(list *[]string, value string) {
+		if value == "" || isPattern(value) || seen[list][value] {
+			return
+		}
+		if seen[list] == nil {
+			seen[list] = make(map[string]bool)
+		}
+		seen[list][value] = true
+		*list = append(*list, value)
+	}
+	addHost := func(host string) {
+		if net.ParseIP(host) != nil {
+			add(&s.ips, host)
+			return
+		}
+		add(&s.hosts, host)
+	}
+
+	readLines(filepath.Join(s.dir, "config"), func(line string) {
+		keyword, args := configFields(line)
+		switch keyword {
+		case "host", "hostname":
+			for _, host := range args {
+				addHost(host)
+			}
+		case "user":
+			for _, user := range args {
+				add(&s.users, user)
+			}
+		}
+	})
+
+	readLines(filepath.Join(s.dir, "known_hosts"), func(line string) {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+			fields = fields[1:] // @cert-authority and @revoked markers
+		}
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "|") {
+			return // Hashed hosts can't be read back
+		}
+		for _, host := range strings.Split(fields[0], ",") {
+			// Hosts on a non-standard port are written as [host]:port
+			if strings.HasPrefix(host, "[") {
+				if end := strings.Index(host, "]"); end > 0 {
+					host = host[1:end]
+				}
+			}
+			addHost(host)
+		}
+	})
+}
+
+// configFields splits an ssh_config line into its lowercased keyword and
+// arguments. Keywords may be separated from their arguments by "=".
+func configFields(line string) (string, []string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil
+	}
+	fields := strings.FieldsFunc(line, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '='
+	})
+	if len(fields) == 0 {
+		return "", nil // A line of only separators, such as "="
+	}
+	return strings.ToLower(fields[0]), fields[1:]
+}
+
+// isPattern reports whether an SSH host is a pattern such as *.example.com
+// or !bastion rather than a host
+func isPattern(host string) bool {
+	return strings.ContainsAny(host, "*?!")
+}
+
+// readLines calls fn with each line of the file at path, doing nothing if
+// it can't be read
+func readLines(path string, fn func(string)) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+}
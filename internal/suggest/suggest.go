@@ -0,0 +1,54 @@
+package suggest
+
+import (
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// maxSuggestions caps how many values are offered for one placeholder
+const maxSuggestions = 10
+
+// Provider suggests values for placeholders, usually from what it knows
+// about the machine, such as the hosts in ~/.ssh/config or the branches of
+// the repository at hand
+type Provider interface {
+	Name() string
+	Suggest(placeholder types.Placeholder) []string
+}
+
+// Providers asks several providers for suggestions in turn
+type Providers []Provider
+
+// Default returns the providers used unless configured otherwise: SSH
+// hosts and users, commonly used ports, git branches, kubectl contexts and,
+// if cfg allows, cluster resources, Docker or Podman containers, images and
+// volumes, the files in the working directory and the user's provider
+// programs
+func Default(cfg *config.Config) Providers {
+	return Providers{NewSSH(""), Ports{}, NewGit(""), NewKubectl("", cfg.KubernetesSuggestions), NewDocker(""), NewFiles(""), NewExternal(config.ProvidersDir())}
+}
+
+// Suggest returns the values suggested for placeholder by all providers, in
+// the providers' order and without duplicates. Secrets and choices get no
+// suggestions.
+func (ps Providers) Suggest(placeholder types.Placeholder) []string {
+	if placeholder.Secret() || len(placeholder.Choices) > 0 {
+		return nil
+	}
+
+	var values []string
+	seen := make(map[string]bool)
+	for _, provider := range ps {
+		for _, value := range provider.Suggest(placeholder) {
+			if value == "" || seen[value] {
+				continue
+			}
+			seen[value] = true
+			values = append(values, value)
+			if len(values) == maxSuggestions {
+				return values
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,354 @@
+package suggest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+func TestSSHSuggest(t *testing.T) {
+	dir := t.TempDir()
+	config := `# Work machines
+Host bastion web-?? *.internal
+    HostName 10.0.0.5
+    User deploy
+
+=
+Host=db
+	HostName db.example.com
+	User = admin
+ = =
+`
+	knownHosts := `db.example.com,192.168.1.10 ssh-ed25519 AAAA
+[git.example.com]:2222 ssh-rsa AAAA
+|1|hashed= ssh-rsa AAAA
+@cert-authority *.example.com ssh-rsa AAAA
+bastion ssh-ed25519 AAAA
+`
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "known_hosts"), []byte(knownHosts), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewSSH(dir)
+	tests := []struct {
+		kind string
+		want []string
+	}{
+		{"host", []string{"bastion", "db", "db.example.com", "git.example.com"}},
+		{"ip", []string{"10.0.0.5", "192.168.1.10"}},
+		{"username", []string{"deploy", "admin"}},
+		{"file", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.kind, func(t *testing.T) {
+			got := provider.Suggest(types.Placeholder{Name: test.kind, Type: test.kind})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Suggest(%s) = %v, want %v", test.kind, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSSHSuggestMissingFiles(t *testing.T) {
+	provider := NewSSH(filepath.Join(t.TempDir(), "missing"))
+	if got := provider.Suggest(types.Placeholder{Name: "host", Type: "host"}); got != nil {
+		t.Errorf("expected no suggestions, got %v", got)
+	}
+}
+
+// staticProvider suggests fixed values for any placeholder
+type staticProvider []string
+
+func (p staticProvider) Name() string { return "static" }
+
+func (p staticProvider) Suggest(types.Placeholder) []string { return p }
+
+func TestProvidersSuggest(t *testing.T) {
+	providers := Providers{staticProvider{"a", "b"}, staticProvider{"b", "", "c"}}
+
+	got := providers.Suggest(types.Placeholder{Name: "thing", Type: "text"})
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest = %v, want %v", got, want)
+	}
+
+	if got := providers.Suggest(types.Placeholder{Name: "password", Type: "password"}); got != nil {
+		t.Errorf("expected no suggestions for secrets, got %v", got)
+	}
+	if got := providers.Suggest(types.Placeholder{Name: "mode", Type: "choice", Choices: []string{"x", "y"}}); got != nil {
+		t.Errorf("expected no suggestions for choices, got %v", got)
+	}
+}
+
+func TestPortsSuggest(t *testing.T) {
+	if got := (Ports{}).Suggest(types.Placeholder{Name: "port", Type: "port"}); len(got) == 0 || got[0] != "22" {
+		t.Errorf("expected common ports, got %v", got)
+	}
+	if got := (Ports{}).Suggest(types.Placeholder{Name: "file", Type: "file"}); got != nil {
+		t.Errorf("expected no ports for a file, got %v", got)
+	}
+}
+
+func TestConfigFields(t *testing.T) {
+	tests := []struct {
+		line    string
+		keyword string
+		args    []string
+	}{
+		{"Host a b", "host", []string{"a", "b"}},
+		{"HostName=example.com", "hostname", []string{"example.com"}},
+		{"\tUser = admin", "user", []string{"admin"}},
+		{"# comment", "", nil},
+		{"", "", nil},
+		{"=", "", nil},
+		{" = \t= ", "", nil},
+	}
+	for _, test := range tests {
+		keyword, args := configFields(test.line)
+		if keyword != test.keyword || !reflect.DeepEqual(args, test.args) {
+			t.Errorf("configFields(%q) = %q, %v, want %q, %v", test.line, keyword, args, test.keyword, test.args)
+		}
+	}
+}
+
+func TestFilesSuggest(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.txt", "a.txt", ".hidden"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewFiles(dir)
+	if got, want := provider.Suggest(types.Placeholder{Name: "file", Type: "file"}), []string{"a.txt", "b.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest(file) = %v, want %v", got, want)
+	}
+	if got, want := provider.Suggest(types.Placeholder{Name: "dir", Type: "directory"}), []string{"src"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest(directory) = %v, want %v", got, want)
+	}
+	if got := provider.Suggest(types.Placeholder{Name: "port", Type: "port"}); got != nil {
+		t.Errorf("expected no files for a port, got %v", got)
+	}
+}
+
+func TestGitSuggest(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "--quiet", "--initial-branch=main")
+	write("committed.txt", "a")
+	write("changed.txt", "a")
+	write("deleted.txt", "a")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "first")
+	git("branch", "feature")
+	git("tag", "v1.0")
+	git("remote", "add", "origin", "https://example.com/repo.git")
+	write("changed.txt", "b")
+	write("untracked.txt", "a")
+	if err := os.Remove(filepath.Join(dir, "deleted.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewGit(dir)
+	tests := []struct {
+		placeholder types.Placeholder
+		want        []string
+	}{
+		{types.Placeholder{Name: "branch_name", Type: "text"}, []string{"feature", "main"}},
+		{types.Placeholder{Name: "tag", Type: "text"}, []string{"v1.0"}},
+		{types.Placeholder{Name: "remote", Type: "text"}, []string{"origin"}},
+		{types.Placeholder{Name: "file", Type: "file"}, []string{"changed.txt", "untracked.txt", "committed.txt"}},
+	}
+	for _, test := range tests {
+		t.Run(test.placeholder.Name, func(t *testing.T) {
+			got := eventually(func() []string { return provider.Suggest(test.placeholder) })
+			if test.placeholder.Name == "branch_name" {
+				sort.Strings(got) // Both branches have the same commit
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Suggest(%s) = %v, want %v", test.placeholder.Name, got, test.want)
+			}
+		})
+	}
+
+	if got := provider.Suggest(types.Placeholder{Name: "remote_host", Type: "host"}); got != nil {
+		t.Errorf("expected no remotes for a host, got %v", got)
+	}
+}
+
+func TestExternalSuggest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("provider scripts are shell scripts")
+	}
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"a-echo":   "#!/bin/sh\necho \"$1\"\necho \"$2\"\n",
+		"b-fail":   "#!/bin/sh\necho ignored\nexit 1\n",
+		"c-plain":  "#!/bin/sh\necho plain\n",
+		"not-exec": "#!/bin/sh\necho hidden\n",
+	}
+	for name, script := range scripts {
+		mode := os.FileMode(0755)
+		if name == "not-exec" {
+			mode = 0644
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	provider := NewExternal(dir)
+	got := provider.Suggest(types.Placeholder{Name: "container", Type: "text"})
+	if want := []string{"container", "text", "plain"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest = %v, want %v", got, want)
+	}
+
+	if got := NewExternal(filepath.Join(dir, "missing")).Suggest(types.Placeholder{Name: "x", Type: "text"}); got != nil {
+		t.Errorf("expected no suggestions without providers, got %v", got)
+	}
+}
+
+// eventually calls suggest until it returns something or a second passes,
+// for providers listing values in the background
+func eventually(suggest func() []string) []string {
+	deadline := time.Now().Add(time.Second)
+	for {
+		if values := suggest(); values != nil || time.Now().After(deadline) {
+			return values
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDockerSuggest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake docker is a shell script")
+	}
+	tool := filepath.Join(t.TempDir(), "docker")
+	script := `#!/bin/sh
+case "$1" in
+ps) echo web; echo db ;;
+images) echo "nginx:latest"; echo "<none>:<none>" ;;
+volume) echo data ;;
+esac
+`
+	if err := os.WriteFile(tool, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewDocker(tool)
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"container", []string{"web", "db"}},
+		{"image_name", []string{"nginx:latest"}},
+		{"volume", []string{"data"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := eventually(func() []string {
+				return provider.Suggest(types.Placeholder{Name: test.name, Type: "text"})
+			})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Suggest(%s) = %v, want %v", test.name, got, test.want)
+			}
+		})
+	}
+
+	if got := provider.Suggest(types.Placeholder{Name: "image.png", Type: "file"}); got != nil {
+		t.Errorf("expected no images for a file, got %v", got)
+	}
+}
+
+func TestBackgroundListDoesNotWait(t *testing.T) {
+	release := make(chan struct{})
+	list := newBackgroundList(func() []string {
+		<-release
+		return []string{"done"}
+	})
+
+	if got := list.get(); got != nil {
+		t.Errorf("expected nothing before the listing is done, got %v", got)
+	}
+	close(release)
+	if got := eventually(list.get); !reflect.DeepEqual(got, []string{"done"}) {
+		t.Errorf("get = %v, want [done]", got)
+	}
+}
+
+func TestKubectlSuggest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake kubectl is a shell script")
+	}
+	tool := filepath.Join(t.TempDir(), "kubectl")
+	script := `#!/bin/sh
+case "$1 $2" in
+"config get-contexts") echo dev; echo prod ;;
+"get namespaces") echo namespace/default; echo namespace/web ;;
+"get pods") echo pod/web-1 ;;
+"get deployments") echo deployment.apps/web ;;
+esac
+`
+	if err := os.WriteFile(tool, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewKubectl(tool, true)
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"context", []string{"dev", "prod"}},
+		{"namespace", []string{"default", "web"}},
+		{"pod_name", []string{"web-1"}},
+		{"deployment", []string{"web"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := eventually(func() []string {
+				return provider.Suggest(types.Placeholder{Name: test.name, Type: "text"})
+			})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Suggest(%s) = %v, want %v", test.name, got, test.want)
+			}
+		})
+	}
+
+	// Without cluster access only the kubeconfig is read
+	local := NewKubectl(tool, false)
+	if got := local.Suggest(types.Placeholder{Name: "context", Type: "text"}); !reflect.DeepEqual(got, []string{"dev", "prod"}) {
+		t.Errorf("expected contexts without cluster access, got %v", got)
+	}
+	if got := eventually(func() []string {
+		return local.Suggest(types.Placeholder{Name: "namespace", Type: "text"})
+	}); got != nil {
+		t.Errorf("expected no namespaces without cluster access, got %v", got)
+	}
+}
@@ -0,0 +1,230 @@
+package teamsync
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// encryptedHeader starts every file encrypted by the sync. What follows is
+// the salt the key was derived with, the nonce and the AES-256-GCM sealed
+// content.
+const encryptedHeader = "tldrpp-encrypted-v1\n"
+
+const (
+	saltSize = 16
+	keySize  = 32
+	// kdfIterations is the PBKDF2-HMAC-SHA256 work factor for passphrases
+	kdfIterations = 600000
+	// minIdentitySize is the least an identity file holds, so a key can't
+	// be guessed from it
+	minIdentitySize = 16
+)
+
+// Cipher encrypts files before they reach the backend and decrypts them
+// after, with a key derived from a passphrase or read from an identity
+// file
+type Cipher struct {
+	// derive returns the key for a salt
+	derive func(salt []byte) []byte
+	// salt is what files stored are encrypted with; one salt per sync
+	// means deriving a key from a passphrase once
+	salt []byte
+
+	mu   sync.Mutex
+	keys map[string][]byte
+}
+
+// NewPassphraseCipher returns a cipher whose keys are derived from a
+// passphrase
+func NewPassphraseCipher(passphrase string) (*Cipher, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("empty sync passphrase")
+	}
+	return newCipher(func(salt []byte) []byte {
+		return pbkdf2([]byte(passphrase), salt, kdfIterations, keySize)
+	})
+}
+
+// NewIdentityCipher returns a cipher whose key is read from an identity
+// file: any file of random bytes kept secret on every machine, e.g. made
+// with head -c 32 /dev/urandom
+func NewIdentityCipher(file string) (*Cipher, error) {
+	secret, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync identity: %w", err)
+	}
+	if len(bytes.TrimSpace(secret)) < minIdentitySize {
+		return nil, fmt.Errorf("sync identity %s is too short, it needs at least %d random bytes", file, minIdentitySize)
+	}
+	return newCipher(func(salt []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(salt)
+		return mac.Sum(nil)
+	})
+}
+
+// newCipher returns a cipher deriving keys with derive and a fresh salt
+func newCipher(derive func(salt []byte) []byte) (*Cipher, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return &Cipher{derive: derive, salt: salt, keys: make(map[string][]byte)}, nil
+}
+
+// key returns the key for a salt, deriving it the first time
+func (c *Cipher) key(salt []byte) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key, ok := c.keys[string(salt)]
+	if !ok {
+		key = c.derive(salt)
+		c.keys[string(salt)] = key
+	}
+	return key
+}
+
+// aead returns AES-256-GCM with the key for a salt
+func (c *Cipher) aead(salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(c.key(salt))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Seal encrypts a file stored at name, which is authenticated with it so
+// an encrypted file can't be passed off as another
+func (c *Cipher) Seal(name string, data []byte) ([]byte, error) {
+	aead, err := c.aead(c.salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := append([]byte(encryptedHeader), c.salt...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, data, []byte(name)), nil
+}
+
+// Open decrypts a file stored at name
+func (c *Cipher) Open(name string, data []byte) ([]byte, error) {
+	rest := data[len(encryptedHeader):]
+	if len(rest) < saltSize {
+		return nil, fmt.Errorf("%s is truncated", name)
+	}
+	salt := rest[:saltSize]
+	aead, err := c.aead(salt)
+	if err != nil {
+		return nil, err
+	}
+	rest = rest[saltSize:]
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("%s is truncated", name)
+	}
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: wrong passphrase or identity, or the file was altered", name)
+	}
+	return plain, nil
+}
+
+// IsEncrypted reports whether a stored file was encrypted by the sync
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedHeader))
+}
+
+// Sensitive reports whether a synced file may hold remembered values, such
+// as hostnames and paths, and is encrypted when a cipher is set: snippets
+// and placeholder memory. Custom pages are meant to be shared and aren't.
+func Sensitive(name string) bool {
+	return name == memoryPath || strings.HasPrefix(name, "snippets/")
+}
+
+// Encrypted wraps a backend so sensitive files only reach it encrypted.
+// Without a cipher it passes files through, refusing encrypted ones.
+type Encrypted struct {
+	Backend Backend
+	Cipher  *Cipher
+}
+
+// Fetch returns the backend's files, decrypting those encrypted. A
+// sensitive file stored in plaintext, pushed before encryption was set up,
+// is left out so that this machine's copy is pushed again encrypted.
+func (e Encrypted) Fetch() (map[string][]byte, error) {
+	files, err := e.Backend.Fetch()
+	if err != nil {
+		return nil, err
+	}
+	for name, data := range files {
+		switch {
+		case IsEncrypted(data) && e.Cipher == nil:
+			return nil, fmt.Errorf("%s is encrypted, set sync.passphrase or sync.identity_file to decrypt it", name)
+		case IsEncrypted(data):
+			plain, err := e.Cipher.Open(name, data)
+			if err != nil {
+				return nil, err
+			}
+			files[name] = plain
+		case e.Cipher != nil && Sensitive(name):
+			delete(files, name)
+		}
+	}
+	return files, nil
+}
+
+// Store encrypts the sensitive files when a cipher is set and stores them
+func (e Encrypted) Store(files map[string][]byte) error {
+	if e.Cipher == nil {
+		return e.Backend.Store(files)
+	}
+	stored := make(map[string][]byte, len(files))
+	for name, data := range files {
+		if !Sensitive(name) {
+			stored[name] = data
+			continue
+		}
+		sealed, err := e.Cipher.Seal(name, data)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", name, err)
+		}
+		stored[name] = sealed
+	}
+	return e.Backend.Store(stored)
+}
+
+// pbkdf2 derives a key from a password with PBKDF2 (RFC 8018) and
+// HMAC-SHA256
+func pbkdf2(password, salt []byte, iterations, size int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < size; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:size]
+}
@@ -0,0 +1,152 @@
+package teamsync
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	// RFC 7914, section 11
+	want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if got := hex.EncodeToString(pbkdf2([]byte("passwd"), []byte("salt"), 1, 64)); got != want {
+		t.Errorf("pbkdf2 = %s, want %s", got, want)
+	}
+}
+
+func TestCipher(t *testing.T) {
+	laptop, err := NewPassphraseCipher("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	desktop, _ := NewPassphraseCipher("correct horse")
+	wrong, _ := NewPassphraseCipher("battery staple")
+
+	plain := []byte(`{"host":["db.internal"]}`)
+	sealed, err := laptop.Seal(memoryPath, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(sealed) || bytes.Contains(sealed, []byte("db.internal")) {
+		t.Fatalf("expected the sealed file to hide its content, got %q", sealed)
+	}
+
+	// Another machine with the passphrase opens it, whatever its own salt
+	opened, err := desktop.Open(memoryPath, sealed)
+	if err != nil || !bytes.Equal(opened, plain) {
+		t.Fatalf("Open = %q, %v", opened, err)
+	}
+	if _, err := wrong.Open(memoryPath, sealed); err == nil {
+		t.Error("expected the wrong passphrase to fail")
+	}
+	if _, err := desktop.Open("snippets/other.json", sealed); err == nil {
+		t.Error("expected a file moved to another path to fail")
+	}
+	if _, err := desktop.Open(memoryPath, sealed[:len(encryptedHeader)+4]); err == nil {
+		t.Error("expected a truncated file to fail")
+	}
+}
+
+func TestIdentityCipher(t *testing.T) {
+	dir := t.TempDir()
+	identity := filepath.Join(dir, "sync.key")
+	writeFile(t, identity, "0123456789abcdef0123456789abcdef")
+	short := filepath.Join(dir, "short.key")
+	writeFile(t, short, "secret\n")
+
+	if _, err := NewIdentityCipher(short); err == nil {
+		t.Error("expected a short identity to fail")
+	}
+	if _, err := NewIdentityCipher(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected a missing identity to fail")
+	}
+
+	c, err := NewIdentityCipher(identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, _ := c.Seal("snippets/backup.json", []byte("{}"))
+	other, _ := NewIdentityCipher(identity)
+	if opened, err := other.Open("snippets/backup.json", sealed); err != nil || string(opened) != "{}" {
+		t.Errorf("Open = %q, %v", opened, err)
+	}
+}
+
+func TestEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	identity := filepath.Join(dir, "sync.key")
+	writeFile(t, identity, strings.Repeat("k", 32))
+	c, err := NewIdentityCipher(identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inner := memBackend{"snippets/old.json": []byte(`{"command":"ssh db.internal"}`)}
+	backend := Encrypted{Backend: inner, Cipher: c}
+	files := map[string][]byte{
+		"pages/common/deploy.md": []byte("# deploy\n"),
+		"snippets/backup.json":   []byte(`{"command":"rsync /srv"}`),
+		memoryPath:               []byte(`{"host":["db.internal"]}`),
+	}
+	if err := backend.Store(files); err != nil {
+		t.Fatal(err)
+	}
+	if IsEncrypted(inner["pages/common/deploy.md"]) {
+		t.Error("expected pages to be stored as they are")
+	}
+	for _, name := range []string{"snippets/backup.json", memoryPath} {
+		if !IsEncrypted(inner[name]) {
+			t.Errorf("expected %s to be stored encrypted, got %q", name, inner[name])
+		}
+	}
+
+	fetched, err := backend.Fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if !bytes.Equal(fetched[name], data) {
+			t.Errorf("expected %s to come back decrypted, got %q", name, fetched[name])
+		}
+	}
+	// Stored before encryption was set up, so pushed again encrypted
+	if _, ok := fetched["snippets/old.json"]; ok {
+		t.Error("expected a sensitive file stored in plaintext to be left out")
+	}
+
+	if _, err := (Encrypted{Backend: inner}).Fetch(); err == nil {
+		t.Error("expected fetching encrypted files without a cipher to fail")
+	}
+}
+
+func TestSyncEncrypted(t *testing.T) {
+	identity := filepath.Join(t.TempDir(), "sync.key")
+	writeFile(t, identity, strings.Repeat("k", 32))
+	c, err := NewIdentityCipher(identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	laptop, desktop := newLocal(t), newLocal(t)
+	writeFile(t, filepath.Join(laptop.Snippets, "backup.json"), `{"command":"rsync /srv"}`)
+	inner := memBackend{}
+	backend := Encrypted{Backend: inner, Cipher: c}
+	both := Options{Push: true, Pull: true}
+	for _, local := range []Local{laptop, desktop, laptop} {
+		if _, err := Sync(local, backend, &State{Files: map[string]string{}}, both); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(desktop.Snippets, "backup.json"))
+	if err != nil || string(data) != `{"command":"rsync /srv"}` {
+		t.Errorf("expected the snippet decrypted on the desktop, got %q, %v", data, err)
+	}
+	if !IsEncrypted(inner["snippets/backup.json"]) {
+		t.Error("expected the snippet encrypted on the backend")
+	}
+}
@@ -0,0 +1,136 @@
+package teamsync
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Git keeps the shared files in a git repository, through a clone of it
+// that only the sync writes to
+type Git struct {
+	url string
+	dir string
+	// env is added to git's environment
+	env []string
+}
+
+// NewGit returns a backend pushing to and pulling from the repository at
+// url through a clone in dir
+func NewGit(url, dir string) *Git {
+	return &Git{url: url, dir: dir}
+}
+
+// SetEnv adds variables to the environment git runs in, e.g. proxy and TLS
+// settings
+func (g *Git) SetEnv(env []string) {
+	g.env = env
+}
+
+// Fetch brings the clone up to date with the repository and returns its
+// files
+func (g *Git) Fetch() (map[string][]byte, error) {
+	if remote, err := g.run("remote", "get-url", "origin"); err != nil || remote != g.url {
+		// Missing or cloned from another repository, start over
+		if err := os.RemoveAll(g.dir); err != nil {
+			return nil, fmt.Errorf("failed to clean sync repository: %w", err)
+		}
+		if _, err := runGit("", g.env, "clone", "--quiet", "--", g.url, g.dir); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := g.run("fetch", "--quiet", "origin"); err != nil {
+			return nil, err
+		}
+		branch, err := g.run("symbolic-ref", "--short", "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		// A repository nobody pushed to yet has no branch to follow
+		if _, err := g.run("rev-parse", "--verify", "--quiet", "origin/"+branch); err == nil {
+			if _, err := g.run("reset", "--quiet", "--hard", "origin/"+branch); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(g.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(g.dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync repository: %w", err)
+	}
+	return files, nil
+}
+
+// Store commits files to the clone and pushes them. A push rejected
+// because someone pushed in between fails; syncing again picks their
+// changes up.
+func (g *Git) Store(files map[string][]byte) error {
+	for name, data := range files {
+		target := filepath.Join(g.dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", name, err)
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	if _, err := g.run("add", "--all"); err != nil {
+		return err
+	}
+	host, _ := os.Hostname()
+	if _, err := g.run("commit", "--quiet", "-m", "Sync from "+host); err != nil {
+		return err
+	}
+	_, err := g.run("push", "--quiet", "origin", "HEAD")
+	return err
+}
+
+// run runs git in the clone
+func (g *Git) run(args ...string) (string, error) {
+	return runGit(g.dir, g.env, args...)
+}
+
+// runGit runs a git command in dir, or the current directory if dir is
+// empty, with env added to its environment and returns its trimmed output
+func runGit(dir string, env []string, args ...string) (string, error) {
+	command := args[0]
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	cmd := exec.Command("git", args...)
+	// Never prompt for credentials, leave those to a credential helper
+	cmd.Env = append(append(os.Environ(), env...), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,254 @@
+package teamsync
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3 keeps the shared files under a prefix of an S3 bucket, or of a bucket
+// of an S3-compatible service such as MinIO. Requests are signed with AWS
+// Signature Version 4 and address the bucket in the path, which every
+// service supports.
+type S3 struct {
+	endpoint  *url.URL
+	bucket    string
+	prefix    string
+	region    string
+	accessKey string
+	secretKey string
+	token     string
+	client    *http.Client
+	// now is the time requests are signed at; tests fix it
+	now func() time.Time
+}
+
+// S3Credentials are the keys S3 requests are signed with, and the session
+// token of temporary ones
+type S3Credentials struct {
+	AccessKey string
+	SecretKey string
+	Token     string
+}
+
+// NewS3 returns a backend for rawURL, s3://bucket/prefix, in region. The
+// endpoint defaults to AWS's for the region.
+func NewS3(rawURL, region, endpoint string, creds S3Credentials) (*S3, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 URL %q, expected s3://bucket/prefix", rawURL)
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	e, err := url.Parse(endpoint)
+	if err != nil || (e.Scheme != "http" && e.Scheme != "https") {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	if creds.AccessKey == "" || creds.SecretKey == "" {
+		return nil, fmt.Errorf("no S3 credentials: set sync.username and sync.password, or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	prefix := strings.Trim(u.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &S3{
+		endpoint:  e,
+		bucket:    u.Host,
+		prefix:    prefix,
+		region:    region,
+		accessKey: creds.AccessKey,
+		secretKey: creds.SecretKey,
+		token:     creds.Token,
+		client:    &http.Client{Timeout: httpTimeout},
+		now:       time.Now,
+	}, nil
+}
+
+// SetTransport sets the transport requests go through, e.g. one with proxy
+// and TLS settings
+func (s *S3) SetTransport(transport http.RoundTripper) {
+	s.client.Transport = transport
+}
+
+// listResult is the part of a ListObjectsV2 response the sync reads
+type listResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// Fetch lists the objects under the prefix and downloads them
+func (s *S3) Fetch() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var list listResult
+		err = xml.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the listing of bucket %s: %w", s.bucket, err)
+		}
+
+		for _, object := range list.Contents {
+			name := strings.TrimPrefix(object.Key, s.prefix)
+			if name == "" || strings.HasSuffix(name, "/") {
+				continue // Folder markers
+			}
+			resp, err := s.do(http.MethodGet, object.Key, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to download %s: %w", object.Key, err)
+			}
+			files[name] = data
+		}
+		if !list.IsTruncated || list.NextContinuationToken == "" {
+			return files, nil
+		}
+		token = list.NextContinuationToken
+	}
+}
+
+// Store uploads files under the prefix
+func (s *S3) Store(files map[string][]byte) error {
+	for _, name := range sortedPaths(files) {
+		resp, err := s.do(http.MethodPut, s.prefix+name, nil, files[name])
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// do sends a signed request for an object key, or the bucket if key is
+// empty, failing on any status but 200
+func (s *S3) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket + "/" + key
+	u.RawPath = s3Escape(u.Path)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, body)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", s.endpoint.Host, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("S3 %s %s failed: %s: %s", method, u.Path, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+// sign adds the AWS Signature Version 4 headers to req
+func (s *S3) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := Hash(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.token != "" {
+		req.Header.Set("X-Amz-Security-Token", s.token)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + Hash([]byte(canonicalRequest))
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range []string{date, s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes a query string as Signature Version 4 wants it:
+// sorted, with spaces as %20 rather than +
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+// s3Escape percent-encodes a path as Signature Version 4 wants it: every
+// byte but letters, digits, "-", ".", "_", "~" and "/". Go leaves more
+// alone, such as "+", which the signature would then not match.
+func s3Escape(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,324 @@
+// Package teamsync shares the user's own pages, snippets and placeholder
+// memory between machines through a git repository, a WebDAV directory or
+// an S3 bucket.
+package teamsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Backend is where the shared files are kept. Paths are slash-separated and
+// relative to the backend's root, e.g. pages/common/deploy.md.
+type Backend interface {
+	// Fetch returns every file stored
+	Fetch() (map[string][]byte, error)
+	// Store writes files, replacing those at the same paths
+	Store(files map[string][]byte) error
+}
+
+// Local is where the shared files live on this machine: the custom pages
+// directory, synced as pages/, the snippets directory, synced as snippets/,
+// and the placeholder memory file, synced as placeholders.json
+type Local struct {
+	Pages    string
+	Snippets string
+	Memory   string
+}
+
+// memoryPath is the path placeholder memory is synced as
+const memoryPath = "placeholders.json"
+
+// Read returns the local files by their synced path. Hidden files are left
+// out.
+func (l Local) Read() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	for prefix, dir := range map[string]string{"pages": l.Pages, "snippets": l.Snippets} {
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if strings.HasPrefix(d.Name(), ".") && p != dir {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			files[path.Join(prefix, filepath.ToSlash(rel))] = data
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+	}
+
+	data, err := os.ReadFile(l.Memory)
+	if err == nil {
+		files[memoryPath] = data
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read placeholder memory: %w", err)
+	}
+	return files, nil
+}
+
+// Write stores a synced file where it lives locally
+func (l Local) Write(name string, data []byte) error {
+	target, err := l.localPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", name, err)
+	}
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// localPath maps a synced path to the local file, refusing paths that
+// would leave the synced directories
+func (l Local) localPath(name string) (string, error) {
+	if name == memoryPath {
+		return l.Memory, nil
+	}
+	clean := path.Clean(name)
+	if clean != name || strings.HasPrefix(clean, "../") || path.IsAbs(clean) {
+		return "", fmt.Errorf("invalid synced path %q", name)
+	}
+	prefix, rest, _ := strings.Cut(clean, "/")
+	switch {
+	case rest == "":
+	case prefix == "pages":
+		return filepath.Join(l.Pages, filepath.FromSlash(rest)), nil
+	case prefix == "snippets":
+		return filepath.Join(l.Snippets, filepath.FromSlash(rest)), nil
+	}
+	return "", fmt.Errorf("invalid synced path %q", name)
+}
+
+// Kind tells how a file differs between this machine and the backend
+type Kind int
+
+const (
+	// Outgoing files changed here since the last sync, or are new here
+	Outgoing Kind = iota
+	// Incoming files changed on the backend, or are new there
+	Incoming
+	// Conflict files changed on both sides, differently
+	Conflict
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Outgoing:
+		return "outgoing"
+	case Incoming:
+		return "incoming"
+	default:
+		return "conflict"
+	}
+}
+
+// Change is a file that differs between this machine and the backend
+type Change struct {
+	Path string
+	Kind Kind
+}
+
+// Diff compares the local and remote files, given the hashes they had when
+// last synced, and returns the files that differ in path order. A file
+// changed on one side only goes to the other; a file changed on both, or
+// present on both without having been synced, is a conflict. Deleting a
+// file doesn't delete it elsewhere: it comes back on the next pull.
+func Diff(local, remote map[string][]byte, base map[string]string) []Change {
+	paths := make(map[string]bool)
+	for p := range local {
+		paths[p] = true
+	}
+	for p := range remote {
+		paths[p] = true
+	}
+
+	var changes []Change
+	for p := range paths {
+		l, inLocal := local[p]
+		r, inRemote := remote[p]
+		switch {
+		case inLocal && inRemote && Hash(l) == Hash(r):
+			continue
+		case !inRemote:
+			changes = append(changes, Change{p, Outgoing})
+		case !inLocal:
+			changes = append(changes, Change{p, Incoming})
+		default:
+			localChanged := Hash(l) != base[p]
+			remoteChanged := Hash(r) != base[p]
+			switch {
+			case localChanged && !remoteChanged:
+				changes = append(changes, Change{p, Outgoing})
+			case remoteChanged && !localChanged:
+				changes = append(changes, Change{p, Incoming})
+			default:
+				changes = append(changes, Change{p, Conflict})
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// Hash returns the hex SHA-256 of a file's content
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// State is what was synced last: the hash of every file both sides agreed
+// on, which tells which side changed a file since
+type State struct {
+	Files map[string]string `json:"files"`
+}
+
+// LoadState reads the sync state, empty if there is none yet
+func LoadState(file string) (*State, error) {
+	state := &State{Files: make(map[string]string)}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse sync state: %w", err)
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]string)
+	}
+	return state, nil
+}
+
+// Save writes the sync state
+func (s *State) Save(file string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	return nil
+}
+
+// Record notes the files both sides now agree on
+func (s *State) Record(local, remote map[string][]byte) {
+	for p, l := range local {
+		if r, ok := remote[p]; ok && Hash(l) == Hash(r) {
+			s.Files[p] = Hash(l)
+		}
+	}
+}
+
+// Options selects which way a sync sends files
+type Options struct {
+	Push bool
+	Pull bool
+	// Force settles conflicts the way a one-way sync goes: a push keeps
+	// this machine's version, a pull the backend's
+	Force bool
+	// DryRun works out what would be sent without sending anything
+	DryRun bool
+}
+
+// Result lists the files a sync sent each way, or would with DryRun, and
+// the conflicts it left alone
+type Result struct {
+	Pushed    []string
+	Pulled    []string
+	Conflicts []string
+	// Waiting are files changed on the side a one-way sync doesn't read
+	Waiting []string
+}
+
+// Sync sends the files changed on one side since the last sync to the
+// other and records the files both now agree on in state, unless
+// opts.DryRun is set. Conflicts are left alone unless opts.Force settles
+// them.
+func Sync(local Local, backend Backend, state *State, opts Options) (*Result, error) {
+	if opts.Force && opts.Push == opts.Pull {
+		return nil, fmt.Errorf("force needs a push or a pull, not both")
+	}
+
+	localFiles, err := local.Read()
+	if err != nil {
+		return nil, err
+	}
+	remoteFiles, err := backend.Fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	upload := make(map[string][]byte)
+	for _, change := range Diff(localFiles, remoteFiles, state.Files) {
+		push := opts.Push && (change.Kind == Outgoing || change.Kind == Conflict && opts.Force)
+		pull := opts.Pull && (change.Kind == Incoming || change.Kind == Conflict && opts.Force)
+		switch {
+		case push:
+			upload[change.Path] = localFiles[change.Path]
+			result.Pushed = append(result.Pushed, change.Path)
+		case pull:
+			result.Pulled = append(result.Pulled, change.Path)
+		case change.Kind == Conflict:
+			result.Conflicts = append(result.Conflicts, change.Path)
+		default:
+			result.Waiting = append(result.Waiting, change.Path)
+		}
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if len(upload) > 0 {
+		if err := backend.Store(upload); err != nil {
+			return nil, err
+		}
+		for p, data := range upload {
+			remoteFiles[p] = data
+		}
+	}
+	for _, p := range result.Pulled {
+		if err := local.Write(p, remoteFiles[p]); err != nil {
+			return nil, err
+		}
+		localFiles[p] = remoteFiles[p]
+	}
+	state.Record(localFiles, remoteFiles)
+	return result, nil
+}
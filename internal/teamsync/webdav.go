@@ -0,0 +1,211 @@
+package teamsync
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// httpTimeout bounds each request to a WebDAV or S3 server
+const httpTimeout = 30 * time.Second
+
+// WebDAV keeps the shared files in a directory of a WebDAV server, such as
+// Nextcloud's or a plain Apache mod_dav
+type WebDAV struct {
+	root     *url.URL
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewWebDAV returns a backend for the directory at rawURL, logging in with
+// username and password if username isn't empty
+func NewWebDAV(rawURL, username, password string) (*WebDAV, error) {
+	root, err := url.Parse(rawURL)
+	if err != nil || (root.Scheme != "http" && root.Scheme != "https") {
+		return nil, fmt.Errorf("invalid WebDAV URL %q", rawURL)
+	}
+	if !strings.HasSuffix(root.Path, "/") {
+		root.Path += "/"
+	}
+	return &WebDAV{root: root, username: username, password: password, client: &http.Client{Timeout: httpTimeout}}, nil
+}
+
+// SetTransport sets the transport requests go through, e.g. one with proxy
+// and TLS settings
+func (w *WebDAV) SetTransport(transport http.RoundTripper) {
+	w.client.Transport = transport
+}
+
+// propfind is the body asking only whether resources are collections
+const propfind = `<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><prop><resourcetype/></prop></propfind>`
+
+// multistatus is the part of a PROPFIND response the sync reads
+type multistatus struct {
+	Responses []struct {
+		Href       string    `xml:"href"`
+		Collection *struct{} `xml:"propstat>prop>resourcetype>collection"`
+	} `xml:"response"`
+}
+
+// Fetch lists the directory, one level at a time as not every server
+// allows listing a whole tree, and downloads every file
+func (w *WebDAV) Fetch() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	dirs := []string{""}
+	for len(dirs) > 0 {
+		dir := dirs[0]
+		dirs = dirs[1:]
+
+		entries, err := w.list(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if strings.HasSuffix(entry, "/") {
+				dirs = append(dirs, entry)
+				continue
+			}
+			data, err := w.get(entry)
+			if err != nil {
+				return nil, err
+			}
+			files[entry] = data
+		}
+	}
+	return files, nil
+}
+
+// list returns the entries of a directory relative to the root, those of
+// subdirectories ending in a slash. A directory that doesn't exist yet is
+// empty.
+func (w *WebDAV) list(dir string) ([]string, error) {
+	resp, err := w.do("PROPFIND", dir, strings.NewReader(propfind), map[string]string{"Depth": "1", "Content-Type": "application/xml"})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("failed to list %s: %s", w.url(dir), resp.Status)
+	}
+
+	var status multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to parse the listing of %s: %w", w.url(dir), err)
+	}
+	var entries []string
+	for _, response := range status.Responses {
+		href, err := url.Parse(response.Href)
+		if err != nil {
+			continue
+		}
+		rel, ok := strings.CutPrefix(path.Clean(href.Path), path.Clean(w.root.Path))
+		rel = strings.TrimPrefix(rel, "/")
+		if !ok || rel == "" || rel+"/" == dir {
+			continue // The directory itself
+		}
+		if response.Collection != nil {
+			rel += "/"
+		}
+		entries = append(entries, rel)
+	}
+	sort.Strings(entries)
+	return entries, nil
+}
+
+// get downloads a file
+func (w *WebDAV) get(name string) ([]byte, error) {
+	resp, err := w.do(http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", w.url(name), resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Store uploads files, creating the root and the directories they go in
+func (w *WebDAV) Store(files map[string][]byte) error {
+	created := make(map[string]bool)
+	for _, name := range sortedPaths(files) {
+		dirs := []string{""}
+		dir := ""
+		for _, part := range strings.Split(path.Dir(name), "/") {
+			if part == "." {
+				break
+			}
+			dir += part + "/"
+			dirs = append(dirs, dir)
+		}
+		for _, dir := range dirs {
+			if created[dir] {
+				continue
+			}
+			// MKCOL fails on directories that exist, which is fine
+			resp, err := w.do("MKCOL", dir, nil, nil)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			created[dir] = true
+		}
+
+		resp, err := w.do(http.MethodPut, name, bytes.NewReader(files[name]), nil)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("failed to upload %s: %s", w.url(name), resp.Status)
+		}
+	}
+	return nil
+}
+
+// do sends a request for a path relative to the root
+func (w *WebDAV) do(method, name string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequest(method, w.url(name), body)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", w.root.Host, err)
+	}
+	return resp, nil
+}
+
+// url returns the URL of a path relative to the root
+func (w *WebDAV) url(name string) string {
+	u := *w.root
+	u.Path += name
+	return u.String()
+}
+
+// sortedPaths returns the paths of files in order
+func sortedPaths(files map[string][]byte) []string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
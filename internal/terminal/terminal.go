@@ -0,0 +1,153 @@
+// Package terminal works out what the terminal can show beyond styled text,
+// such as hyperlinks and images, and writes the escape sequences for them.
+// Terminals don't report these features, so they are judged by the
+// environment, and anything not known to work is left out.
+package terminal
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Features are what a terminal can show beyond styled text
+type Features struct {
+	// Hyperlinks are OSC 8 links, shown as text that opens a URL on click
+	Hyperlinks bool
+	// Images are drawn with the kitty graphics protocol
+	Images bool
+}
+
+// hyperlinkPrograms are the TERM_PROGRAM values of terminals known to
+// support OSC 8 hyperlinks
+var hyperlinkPrograms = map[string]bool{
+	"iTerm.app": true,
+	"WezTerm":   true,
+	"vscode":    true,
+	"ghostty":   true,
+	"Hyper":     true,
+	"Tabby":     true,
+}
+
+// imagePrograms are the TERM_PROGRAM values of terminals known to support
+// the kitty graphics protocol, besides kitty itself
+var imagePrograms = map[string]bool{
+	"WezTerm": true,
+	"ghostty": true,
+}
+
+// Detect returns the features of the terminal tldr++ runs in
+func Detect() Features {
+	return detect(os.Getenv)
+}
+
+// detect works out the terminal's features from its environment.
+// FORCE_HYPERLINK set to 0 or 1 overrides the guess about hyperlinks.
+func detect(getenv func(string) string) Features {
+	term := getenv("TERM")
+	if term == "dumb" {
+		return Features{}
+	}
+	// Multiplexers swallow the sequences unless set up to pass them on
+	multiplexed := getenv("TMUX") != "" || strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux")
+	program := getenv("TERM_PROGRAM")
+	kitty := term == "xterm-kitty" || getenv("KITTY_WINDOW_ID") != ""
+
+	var features Features
+	if !multiplexed {
+		features.Images = kitty || imagePrograms[program] || term == "xterm-ghostty"
+		features.Hyperlinks = kitty || hyperlinkPrograms[program] ||
+			getenv("WT_SESSION") != "" ||
+			getenv("KONSOLE_VERSION") != "" ||
+			term == "alacritty" || strings.HasPrefix(term, "foot") || term == "xterm-ghostty" ||
+			vteVersion(getenv("VTE_VERSION")) >= 5000
+	}
+
+	switch getenv("FORCE_HYPERLINK") {
+	case "":
+	case "0":
+		features.Hyperlinks = false
+	default:
+		features.Hyperlinks = true
+	}
+	return features
+}
+
+// vteVersion parses VTE_VERSION, e.g. 6800 for VTE 0.68, which GNOME
+// Terminal and other VTE-based terminals set
+func vteVersion(s string) int {
+	version, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// Hyperlink returns text that opens url when clicked
+func Hyperlink(url, text string) string {
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// hyperlinkPattern matches the sequences that start and end a hyperlink
+var hyperlinkPattern = regexp.MustCompile("\x1b]8;[^\x1b]*\x1b\\\\")
+
+// StripHyperlinks removes the hyperlinks from s, keeping their text
+func StripHyperlinks(s string) string {
+	return hyperlinkPattern.ReplaceAllString(s, "")
+}
+
+// escapePattern matches escape sequences: control sequences such as colors
+// and cursor moves, operating system commands such as titles and
+// hyperlinks, and the two-character ones. Carriage returns before a newline
+// go too.
+var escapePattern = regexp.MustCompile("\x1b\\[[0-?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(?:\x07|\x1b\\\\)|\x1b[ -/]*[0-~]|\r+\n")
+
+// StripEscapes removes the escape sequences from s, such as those a command
+// writing to a terminal or a Windows pseudo console prints, keeping its text
+// with Unix line endings
+func StripEscapes(s string) string {
+	return escapePattern.ReplaceAllStringFunc(s, func(m string) string {
+		if m[0] == '\r' {
+			return "\n"
+		}
+		return ""
+	})
+}
+
+// imageChunk is the most base64 data the kitty graphics protocol accepts
+// in one sequence
+const imageChunk = 4096
+
+// Image returns the sequences drawing an RGBA image of the given size in
+// pixels, scaled to fill columns cells of one row. The cursor ends up after
+// the image.
+func Image(rgba []byte, width, height, columns int) string {
+	data := base64.StdEncoding.EncodeToString(rgba)
+
+	var b strings.Builder
+	first := true
+	for {
+		chunk := data
+		if len(chunk) > imageChunk {
+			chunk = chunk[:imageChunk]
+		}
+		data = data[len(chunk):]
+		more := 0
+		if data != "" {
+			more = 1
+		}
+
+		if first {
+			fmt.Fprintf(&b, "\x1b_Ga=T,f=32,s=%d,v=%d,c=%d,r=1,q=2,m=%d;%s\x1b\\", width, height, columns, more, chunk)
+			first = false
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+		if more == 0 {
+			return b.String()
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected Features
+	}{
+		{"plain xterm", map[string]string{"TERM": "xterm-256color"}, Features{}},
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, Features{Hyperlinks: true, Images: true}},
+		{"iterm", map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app"}, Features{Hyperlinks: true}},
+		{"wezterm", map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "WezTerm"}, Features{Hyperlinks: true, Images: true}},
+		{"gnome terminal", map[string]string{"TERM": "xterm-256color", "VTE_VERSION": "6800"}, Features{Hyperlinks: true}},
+		{"old vte", map[string]string{"TERM": "xterm-256color", "VTE_VERSION": "4803"}, Features{}},
+		{"windows terminal", map[string]string{"WT_SESSION": "abc"}, Features{Hyperlinks: true}},
+		{"kitty in tmux", map[string]string{"TERM": "tmux-256color", "KITTY_WINDOW_ID": "1", "TMUX": "/tmp/tmux"}, Features{}},
+		{"dumb", map[string]string{"TERM": "dumb", "FORCE_HYPERLINK": "1"}, Features{}},
+		{"forced on", map[string]string{"TERM": "xterm-256color", "FORCE_HYPERLINK": "1"}, Features{Hyperlinks: true}},
+		{"forced off", map[string]string{"TERM": "xterm-kitty", "FORCE_HYPERLINK": "0"}, Features{Images: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detect(func(key string) string { return tt.env[key] })
+			if got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHyperlink(t *testing.T) {
+	link := Hyperlink("https://tldr.sh", "tldr")
+	if !strings.Contains(link, "https://tldr.sh") || !strings.Contains(link, "tldr") {
+		t.Errorf("Expected the URL and text in %q", link)
+	}
+
+	line := "More information: " + link + "."
+	if got := StripHyperlinks(line); got != "More information: tldr." {
+		t.Errorf("Expected the link text only, got %q", got)
+	}
+}
+
+func TestStripEscapes(t *testing.T) {
+	tests := []struct {
+		name, in, expected string
+	}{
+		{"plain", "a\nb", "a\nb"},
+		{"colors", "\x1b[1;31merror\x1b[0m: x", "error: x"},
+		{"title and cursor", "\x1b]0;cmd.exe\x07\x1b[?25l\x1b[2Jok\x1b[?25h", "ok"},
+		{"hyperlink", Hyperlink("https://tldr.sh", "tldr"), "tldr"},
+		{"charset", "\x1b(Bok", "ok"},
+		{"crlf", "one\r\ntwo\r\n", "one\ntwo\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripEscapes(tt.in); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestImage(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		chunks int
+	}{
+		{"small", 4 * 4 * 4, 1},
+		// 4096 base64 characters hold 3072 bytes
+		{"chunked", 3072 * 2, 2},
+		{"partial last chunk", 3072*2 + 1, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image := Image(make([]byte, tt.size), 4, 4, 2)
+			if got := strings.Count(image, "\x1b_G"); got != tt.chunks {
+				t.Errorf("Expected %d chunks, got %d", tt.chunks, got)
+			}
+			if !strings.HasPrefix(image, "\x1b_Ga=T,f=32,s=4,v=4,c=2,") {
+				t.Errorf("Expected the first chunk to place the image, got %q", image[:30])
+			}
+			if !strings.Contains(image, "m=0;") {
+				t.Errorf("Expected the last chunk to end the image")
+			}
+		})
+	}
+}
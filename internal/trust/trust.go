@@ -0,0 +1,180 @@
+package trust
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Grant records that the user trusted the commands of a page from a source
+type Grant struct {
+	Page     string    `json:"page"`
+	Platform string    `json:"platform"`
+	Source   string    `json:"source"`
+	Time     time.Time `json:"time"`
+}
+
+// key identifies a grant. Trust is given to a page as published by one
+// source, so the same page from another source asks again.
+func (g Grant) key() string {
+	return g.Source + ":" + g.Platform + "/" + g.Page
+}
+
+// Kinds of the user's own files that may come from elsewhere
+const (
+	KindPage    = "page"
+	KindSnippet = "snippet"
+)
+
+// Foreign records a custom page or snippet tldrpp wrote from elsewhere,
+// such as pulled from the sync backend. Unlike the user's own, its commands
+// need trusting before they run.
+type Foreign struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Platform is set for pages
+	Platform string    `json:"platform,omitempty"`
+	Source   string    `json:"source"`
+	Time     time.Time `json:"time"`
+}
+
+// foreignKey identifies a foreign page or snippet
+func foreignKey(kind, platform, name string) string {
+	return kind + ":" + platform + "/" + name
+}
+
+// Store holds the pages the user trusted, and the custom pages and
+// snippets that aren't the user's own
+type Store struct {
+	path    string
+	Grants  map[string]Grant   `json:"grants"`
+	Foreign map[string]Foreign `json:"foreign,omitempty"`
+}
+
+// Load reads the trusted pages from path, returning an empty store if the
+// file does not exist yet
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, Grants: make(map[string]Grant), Foreign: make(map[string]Foreign)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, fmt.Errorf("failed to read trusted pages: %w", err)
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return s, fmt.Errorf("failed to parse trusted pages: %w", err)
+	}
+	if s.Grants == nil {
+		s.Grants = make(map[string]Grant)
+	}
+	if s.Foreign == nil {
+		s.Foreign = make(map[string]Foreign)
+	}
+	return s, nil
+}
+
+// Save writes the trusted pages back to disk
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create trust directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trusted pages: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write trusted pages: %w", err)
+	}
+	return nil
+}
+
+// Trusted reports whether the page from source was trusted
+func (s *Store) Trusted(source, platform, page string) bool {
+	_, ok := s.Grants[Grant{Page: page, Platform: platform, Source: source}.key()]
+	return ok
+}
+
+// Trust records the page from source as trusted at t
+func (s *Store) Trust(source, platform, page string, t time.Time) {
+	g := Grant{Page: page, Platform: platform, Source: source, Time: t}
+	s.Grants[g.key()] = g
+}
+
+// Revoke removes the trust given to a page from any source, returning how
+// many grants were removed. An empty platform matches every platform.
+func (s *Store) Revoke(platform, page string) int {
+	removed := 0
+	for key, g := range s.Grants {
+		if g.Page == page && (platform == "" || g.Platform == platform) {
+			delete(s.Grants, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// MarkForeign records that a custom page or snippet was written from source
+// at t, so its commands need trusting, again if they were trusted before
+func (s *Store) MarkForeign(kind, platform, name, source string, t time.Time) {
+	s.Foreign[foreignKey(kind, platform, name)] = Foreign{Kind: kind, Name: name, Platform: platform, Source: source, Time: t}
+}
+
+// ForeignSource returns the source a custom page or snippet was written
+// from, or false if it is the user's own
+func (s *Store) ForeignSource(kind, platform, name string) (string, bool) {
+	f, ok := s.Foreign[foreignKey(kind, platform, name)]
+	return f.Source, ok
+}
+
+// Adopt trusts a custom page or snippet written from elsewhere, which then
+// counts as the user's own
+func (s *Store) Adopt(kind, platform, name string) {
+	delete(s.Foreign, foreignKey(kind, platform, name))
+}
+
+// ListForeign returns the custom pages and snippets not trusted yet,
+// ordered by kind and name
+func (s *Store) ListForeign() []Foreign {
+	foreign := make([]Foreign, 0, len(s.Foreign))
+	for _, f := range s.Foreign {
+		foreign = append(foreign, f)
+	}
+	sort.Slice(foreign, func(i, j int) bool {
+		a, b := foreign[i], foreign[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Platform < b.Platform
+	})
+	return foreign
+}
+
+// List returns the grants ordered by page, platform and source
+func (s *Store) List() []Grant {
+	grants := make([]Grant, 0, len(s.Grants))
+	for _, g := range s.Grants {
+		grants = append(grants, g)
+	}
+	sort.Slice(grants, func(i, j int) bool {
+		a, b := grants[i], grants[j]
+		if a.Page != b.Page {
+			return a.Page < b.Page
+		}
+		if a.Platform != b.Platform {
+			return a.Platform < b.Platform
+		}
+		return a.Source < b.Source
+	})
+	return grants
+}
@@ -0,0 +1,117 @@
+package trust
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrustAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trust.json")
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if s.Trusted("mirror", "common", "tar") {
+		t.Error("Expected nothing to be trusted in an empty store")
+	}
+
+	s.Trust("mirror", "common", "tar", time.Now())
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		source, platform, page string
+		expected               bool
+	}{
+		{"mirror", "common", "tar", true},
+		{"fork", "common", "tar", false},
+		{"mirror", "linux", "tar", false},
+		{"mirror", "common", "ls", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source+":"+tt.platform+"/"+tt.page, func(t *testing.T) {
+			if got := loaded.Trusted(tt.source, tt.platform, tt.page); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	s, _ := Load(filepath.Join(t.TempDir(), "trust.json"))
+	now := time.Now()
+	s.Trust("mirror", "common", "tar", now)
+	s.Trust("fork", "common", "tar", now)
+	s.Trust("mirror", "linux", "tar", now)
+	s.Trust("mirror", "common", "ls", now)
+
+	if removed := s.Revoke("linux", "tar"); removed != 1 {
+		t.Errorf("Expected 1 grant removed, got %d", removed)
+	}
+	if removed := s.Revoke("", "tar"); removed != 2 {
+		t.Errorf("Expected 2 grants removed, got %d", removed)
+	}
+
+	grants := s.List()
+	if len(grants) != 1 || grants[0].Page != "ls" {
+		t.Errorf("Expected only ls to stay trusted, got %+v", grants)
+	}
+}
+
+func TestList(t *testing.T) {
+	s, _ := Load(filepath.Join(t.TempDir(), "trust.json"))
+	now := time.Now()
+	s.Trust("mirror", "linux", "tar", now)
+	s.Trust("mirror", "common", "tar", now)
+	s.Trust("fork", "common", "ls", now)
+
+	grants := s.List()
+	expected := []string{"ls common", "tar common", "tar linux"}
+	if len(grants) != len(expected) {
+		t.Fatalf("Expected %v, got %+v", expected, grants)
+	}
+	for i, g := range grants {
+		if got := g.Page + " " + g.Platform; got != expected[i] {
+			t.Errorf("Expected %s at %d, got %s", expected[i], i, got)
+		}
+	}
+}
+
+func TestForeign(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trust.json")
+	s, _ := Load(path)
+	now := time.Now()
+	s.MarkForeign(KindPage, "common", "deploy", "sync", now)
+	s.MarkForeign(KindSnippet, "", "deploy", "sync", now)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if source, ok := loaded.ForeignSource(KindPage, "common", "deploy"); !ok || source != "sync" {
+		t.Errorf("Expected the page to come from sync, got %q, %v", source, ok)
+	}
+	if _, ok := loaded.ForeignSource(KindPage, "linux", "deploy"); ok {
+		t.Error("Expected the page on another platform to be the user's own")
+	}
+
+	loaded.Adopt(KindSnippet, "", "deploy")
+	if _, ok := loaded.ForeignSource(KindSnippet, "", "deploy"); ok {
+		t.Error("Expected an adopted snippet to be the user's own")
+	}
+	if foreign := loaded.ListForeign(); len(foreign) != 1 || foreign[0].Kind != KindPage {
+		t.Errorf("Expected only the page left, got %+v", foreign)
+	}
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/makalin/tldrpp/internal/cache"
+)
+
+// accessible is set when the TUI is laid out for screen readers
+var accessible bool
+
+// SetAccessible lays the TUI out for screen readers: a single column
+// without boxes or Unicode symbols, the selection spelled out on its own
+// line and no redraws that change nothing a reader needs, such as a
+// blinking cursor or byte counts
+func SetAccessible(on bool) {
+	accessible = on
+	if on {
+		ascii = true
+	}
+}
+
+// renderSelection announces what is selected, e.g. "Selected: page tar
+// (common), 3 of 12", for screen readers to read out
+func (a *App) renderSelection() string {
+	var selected string
+	switch {
+	case a.palette != nil:
+		if len(a.palette.matches) > 0 {
+			selected = fmt.Sprintf("action %s, %d of %d", a.palette.matches[a.palette.list.Index].name, a.palette.list.Index+1, len(a.palette.matches))
+		}
+	case a.relatedFocus:
+		selected = fmt.Sprintf("related page %s, %d of %d", a.related[a.relatedIdx], a.relatedIdx+1, len(a.related))
+	case a.linkFocus:
+		selected = fmt.Sprintf("link %s, %d of %d", a.links[a.linkIdx].text, a.linkIdx+1, len(a.links))
+	case a.state == StatePages && len(a.pages) > 0:
+		page := a.pages[a.selectedIdx]
+		selected = fmt.Sprintf("page %s (%s), %d of %d", page.Name, page.Platform, a.selectedIdx+1, len(a.pages))
+	case a.state == StateExamples:
+		if page := a.selectedPage(); page != nil && len(page.Examples) > 0 {
+			example := a.currentExample()
+			selected = fmt.Sprintf("example %d of %d, %s: %s", a.exampleIdx+1, len(page.Examples), example.Description, example.Command)
+		}
+	case a.state == StateEdit:
+		if example := a.currentExample(); example != nil && len(example.Placeholders) > 0 {
+			placeholder := example.Placeholders[a.fieldIdx]
+			selected = fmt.Sprintf("placeholder %s, %d of %d, value %q", placeholder.Name, a.fieldIdx+1, len(example.Placeholders), a.displayValue(placeholder))
+		}
+	case a.state == StateSnippets && len(a.snippetList) > 0:
+		s := a.snippetList[a.snippetRows.Index]
+		selected = fmt.Sprintf("snippet %s, %d of %d", s.Name, a.snippetRows.Index+1, len(a.snippetList))
+	case a.state == StateOutput && len(a.outputs) > 0:
+		out := a.outputs[a.outputIdx]
+		selected = fmt.Sprintf("output of %s, %d of %d", out.command, a.outputIdx+1, len(a.outputs))
+	}
+	if selected == "" {
+		return ""
+	}
+	return "\n\nSelected: " + selected
+}
+
+// progressText describes a cache refresh in steps of a tenth, so that it
+// only changes, and is read out again, every tenth of the way
+func progressText(p cache.Progress) string {
+	stage := "Downloading pages"
+	switch p.Stage {
+	case cache.StageClone:
+		return "Fetching repository"
+	case cache.StageIndex:
+		stage = "Indexing pages"
+	}
+	return fmt.Sprintf("%s, %d%% done", stage, int(p.Fraction()*10)*10)
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// clipboardTool is a command that copies its stdin to the system clipboard
+type clipboardTool struct {
+	args []string
+	// env must be set for the tool to work, if not empty
+	env string
+}
+
+// clipboardTools are tried in order; the first one installed is used
+var clipboardTools = []clipboardTool{
+	{args: []string{"pbcopy"}},
+	{args: []string{"wl-copy"}, env: "WAYLAND_DISPLAY"},
+	{args: []string{"xclip", "-selection", "clipboard"}, env: "DISPLAY"},
+	{args: []string{"xsel", "--clipboard", "--input"}, env: "DISPLAY"},
+	// Under WSL, where the Windows clipboard isn't reached natively
+	{args: []string{"clip.exe"}},
+}
+
+// CopyToClipboard puts text on the system clipboard, natively on Windows
+// and with the first clipboard tool installed elsewhere, falling back to
+// the OSC 52 escape sequence, which most terminals also honour over SSH
+func CopyToClipboard(text string) error {
+	if nativeClipboard != "" {
+		return copyNative(text)
+	}
+	if tool := findClipboardTool(); tool != nil {
+		cmd := exec.Command(tool.args[0], tool.args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to copy with %s: %w", tool.args[0], err)
+		}
+		return nil
+	}
+
+	_, err := fmt.Fprintf(os.Stderr, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err
+}
+
+// ClipboardBackend names the clipboard or tool copying uses, or returns ""
+// when it falls back to OSC 52
+func ClipboardBackend() string {
+	if nativeClipboard != "" {
+		return nativeClipboard
+	}
+	if tool := findClipboardTool(); tool != nil {
+		return tool.args[0]
+	}
+	return ""
+}
+
+// findClipboardTool returns the first clipboard tool that is installed and
+// usable in this session, or nil
+func findClipboardTool() *clipboardTool {
+	for i, tool := range clipboardTools {
+		if tool.env != "" && os.Getenv(tool.env) == "" {
+			continue
+		}
+		if _, err := exec.LookPath(tool.args[0]); err != nil {
+			continue
+		}
+		return &clipboardTools[i]
+	}
+	return nil
+}
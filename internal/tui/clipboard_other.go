@@ -0,0 +1,14 @@
+//go:build !windows
+
+package tui
+
+import "errors"
+
+// nativeClipboard is empty: elsewhere the clipboard is reached through the
+// tools in clipboardTools
+const nativeClipboard = ""
+
+// copyNative is never called where there is no native clipboard
+func copyNative(text string) error {
+	return errors.ErrUnsupported
+}
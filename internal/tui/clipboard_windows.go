@@ -0,0 +1,91 @@
+//go:build windows
+
+package tui
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// nativeClipboard names the system clipboard copyNative copies to
+const nativeClipboard = "Windows clipboard"
+
+var (
+	user32   = windows.NewLazySystemDLL("user32.dll")
+	kernel32 = windows.NewLazySystemDLL("kernel32.dll")
+
+	procOpenClipboard    = user32.NewProc("OpenClipboard")
+	procCloseClipboard   = user32.NewProc("CloseClipboard")
+	procEmptyClipboard   = user32.NewProc("EmptyClipboard")
+	procSetClipboardData = user32.NewProc("SetClipboardData")
+	procGlobalAlloc      = kernel32.NewProc("GlobalAlloc")
+	procGlobalFree       = kernel32.NewProc("GlobalFree")
+	procGlobalLock       = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock     = kernel32.NewProc("GlobalUnlock")
+)
+
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+)
+
+// copyNative puts text on the clipboard as Unicode text with Windows line
+// endings. clip.exe would read it in the console's code page and mangle
+// anything outside it.
+func copyNative(text string) error {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+	utf16, err := windows.UTF16FromString(text)
+	if err != nil {
+		return fmt.Errorf("failed to copy: %w", err)
+	}
+
+	// The clipboard is opened for the calling thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if err := openClipboard(); err != nil {
+		return fmt.Errorf("failed to open the clipboard: %w", err)
+	}
+	defer procCloseClipboard.Call()
+	if r, _, err := procEmptyClipboard.Call(); r == 0 {
+		return fmt.Errorf("failed to empty the clipboard: %w", err)
+	}
+
+	size := uintptr(len(utf16)) * unsafe.Sizeof(utf16[0])
+	mem, _, err := procGlobalAlloc.Call(gmemMoveable, size)
+	if mem == 0 {
+		return fmt.Errorf("failed to copy: %w", err)
+	}
+	locked, _, err := procGlobalLock.Call(mem)
+	if locked == 0 {
+		procGlobalFree.Call(mem)
+		return fmt.Errorf("failed to copy: %w", err)
+	}
+	copy(unsafe.Slice((*uint16)(*(*unsafe.Pointer)(unsafe.Pointer(&locked))), len(utf16)), utf16)
+	procGlobalUnlock.Call(mem)
+
+	// The clipboard owns the memory once it has it
+	if r, _, err := procSetClipboardData.Call(cfUnicodeText, mem); r == 0 {
+		procGlobalFree.Call(mem)
+		return fmt.Errorf("failed to copy: %w", err)
+	}
+	return nil
+}
+
+// openClipboard opens the clipboard, waiting a little for another program
+// that has it open
+func openClipboard() error {
+	var err error
+	for i := 0; i < 10; i++ {
+		var r uintptr
+		if r, _, err = procOpenClipboard.Call(0); r != 0 {
+			return nil
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return err
+}
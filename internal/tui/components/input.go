@@ -0,0 +1,60 @@
+package components
+
+import (
+	bubbletea "github.com/charmbracelet/bubbletea"
+)
+
+// InputState is how far typing into an input got
+type InputState int
+
+const (
+	InputEditing InputState = iota
+	InputSubmitted
+	InputCancelled
+)
+
+// Input is a line of text typed in after a prompt, submitted with Enter
+// and cancelled with Esc
+type Input struct {
+	Prompt string
+	Value  string
+	// Help follows the value, e.g. "(Enter Save, Esc Cancel)"
+	Help   string
+	State  InputState
+	Styles Styles
+}
+
+// NewInput returns an input asking for prompt, starting out with value
+func NewInput(prompt, value, help string, styles Styles) Input {
+	return Input{Prompt: prompt, Value: value, Help: help, Styles: styles}
+}
+
+// Update edits the value with the key pressed
+func (m Input) Update(msg bubbletea.Msg) (Input, bubbletea.Cmd) {
+	keyMsg, ok := msg.(bubbletea.KeyMsg)
+	if !ok || m.State != InputEditing {
+		return m, nil
+	}
+	switch keyMsg.Type {
+	case bubbletea.KeyEnter:
+		m.State = InputSubmitted
+	case bubbletea.KeyEsc:
+		m.State = InputCancelled
+	case bubbletea.KeyBackspace:
+		if runes := []rune(m.Value); len(runes) > 0 {
+			m.Value = string(runes[:len(runes)-1])
+		}
+	case bubbletea.KeyRunes, bubbletea.KeySpace:
+		m.Value += string(keyMsg.Runes)
+	}
+	return m, nil
+}
+
+// View renders the prompt and the value typed so far
+func (m Input) View() string {
+	view := m.Prompt + m.Value + m.Styles.Cursor
+	if m.Help != "" {
+		view += "  " + m.Help
+	}
+	return m.Styles.Accent.Render(view)
+}
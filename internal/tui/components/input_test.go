@@ -0,0 +1,43 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+)
+
+// typeKeys sends each key to the input
+func typeKeys(m Input, keys ...bubbletea.KeyMsg) Input {
+	for _, k := range keys {
+		m, _ = m.Update(k)
+	}
+	return m
+}
+
+func runes(s string) bubbletea.KeyMsg {
+	return bubbletea.KeyMsg{Type: bubbletea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestInputTyping(t *testing.T) {
+	m := NewInput("Name: ", "ta", "(Enter Save)", DefaultStyles())
+	m = typeKeys(m, runes("r"), bubbletea.KeyMsg{Type: bubbletea.KeySpace, Runes: []rune(" ")}, runes("é"), press(bubbletea.KeyBackspace))
+	if m.Value != "tar " {
+		t.Errorf("Expected %q, got %q", "tar ", m.Value)
+	}
+	if view := m.View(); !strings.Contains(view, "Name: tar █") || !strings.Contains(view, "(Enter Save)") {
+		t.Errorf("Expected the prompt, value and help, got %q", view)
+	}
+
+	m = typeKeys(m, press(bubbletea.KeyEnter), runes("x"))
+	if m.State != InputSubmitted || m.Value != "tar " {
+		t.Errorf("Expected the value submitted and left alone, got %v %q", m.State, m.Value)
+	}
+}
+
+func TestInputCancel(t *testing.T) {
+	m := typeKeys(NewInput("Name: ", "", "", DefaultStyles()), runes("a"), press(bubbletea.KeyEsc))
+	if m.State != InputCancelled {
+		t.Errorf("Expected Esc to cancel, got %v", m.State)
+	}
+}
@@ -0,0 +1,119 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Item is a row of a list: its text, and a hint such as a key shown
+// faintly at the right end
+type Item struct {
+	Text string
+	Hint string
+}
+
+// ListKeys are the keys moving through a list
+type ListKeys struct {
+	Up, Down key.Binding
+}
+
+// List is a list of items with one selected, scrolled to keep the selected
+// one in view
+type List struct {
+	Items []Item
+	// Index is the selected item
+	Index int
+	// Height is how many items are shown at once, or 0 for all of them
+	Height int
+	// Width is how wide rows are drawn, hints aligned at the right end;
+	// 0 draws rows as wide as they are
+	Width int
+	// Empty is shown when there are no items
+	Empty  string
+	Keys   ListKeys
+	Styles Styles
+}
+
+// NewList returns a list of items moved through with keys
+func NewList(items []Item, keys ListKeys, styles Styles) List {
+	return List{Items: items, Keys: keys, Styles: styles}
+}
+
+// SetItems replaces the items, keeping the selection within them
+func (l *List) SetItems(items []Item) {
+	l.Items = items
+	l.Select(l.Index)
+}
+
+// Select selects item i, or the nearest one
+func (l *List) Select(i int) {
+	if i >= len(l.Items) {
+		i = len(l.Items) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	l.Index = i
+}
+
+// Update moves the selection with the up and down keys
+func (l List) Update(msg bubbletea.Msg) (List, bubbletea.Cmd) {
+	if msg, ok := msg.(bubbletea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, l.Keys.Up):
+			l.Select(l.Index - 1)
+		case key.Matches(msg, l.Keys.Down):
+			l.Select(l.Index + 1)
+		}
+	}
+	return l, nil
+}
+
+// View renders the items in view, the selected one highlighted, and how
+// many more there are below
+func (l List) View() string {
+	if len(l.Items) == 0 {
+		if l.Empty == "" {
+			return ""
+		}
+		return l.Styles.Text.Render(l.Empty) + "\n"
+	}
+
+	start, end := 0, len(l.Items)
+	if l.Height > 0 && end > l.Height {
+		if l.Index >= l.Height {
+			start = l.Index - l.Height + 1
+		}
+		end = start + l.Height
+	}
+
+	var content strings.Builder
+	for i := start; i < end; i++ {
+		item := l.Items[i]
+		style := l.Styles.Text
+		if i == l.Index {
+			style = l.Styles.Selected
+		}
+		text := item.Text
+		if l.Width > 0 {
+			text = truncate(text, l.Width-lipgloss.Width(item.Hint)-3, l.Styles.Ellipsis)
+		}
+		row := l.Styles.Mark(i == l.Index) + style.Render(text)
+		if item.Hint != "" {
+			gap := 1
+			if l.Width > 0 {
+				gap = max(l.Width-2-lipgloss.Width(text)-lipgloss.Width(item.Hint), 1)
+			}
+			row += strings.Repeat(" ", gap) + l.Styles.Faint.Render(item.Hint)
+		}
+		content.WriteString(row + "\n")
+	}
+	if more := len(l.Items) - end; more > 0 {
+		content.WriteString(l.Styles.Faint.Render(fmt.Sprintf("%d more", more)) + "\n")
+	}
+	return content.String()
+}
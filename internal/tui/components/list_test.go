@@ -0,0 +1,80 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	bubbletea "github.com/charmbracelet/bubbletea"
+)
+
+var testListKeys = ListKeys{
+	Up:   key.NewBinding(key.WithKeys("up")),
+	Down: key.NewBinding(key.WithKeys("down")),
+}
+
+func press(k bubbletea.KeyType) bubbletea.KeyMsg {
+	return bubbletea.KeyMsg{Type: k}
+}
+
+func TestListMoves(t *testing.T) {
+	l := NewList([]Item{{Text: "a"}, {Text: "b"}, {Text: "c"}}, testListKeys, DefaultStyles())
+
+	l, _ = l.Update(press(bubbletea.KeyUp))
+	if l.Index != 0 {
+		t.Errorf("Expected up on the first item to stay, got %d", l.Index)
+	}
+	for i := 0; i < 5; i++ {
+		l, _ = l.Update(press(bubbletea.KeyDown))
+	}
+	if l.Index != 2 {
+		t.Errorf("Expected down to stop at the last item, got %d", l.Index)
+	}
+
+	// Removing items keeps the selection within them
+	l.SetItems(l.Items[:1])
+	if l.Index != 0 {
+		t.Errorf("Expected the selection to move to the remaining item, got %d", l.Index)
+	}
+	l.SetItems(nil)
+	if l.Index != 0 {
+		t.Errorf("Expected an empty list to select 0, got %d", l.Index)
+	}
+}
+
+func TestListView(t *testing.T) {
+	items := make([]Item, 20)
+	for i := range items {
+		items[i] = Item{Text: string(rune('a' + i)), Hint: "k"}
+	}
+	l := NewList(items, testListKeys, DefaultStyles())
+	l.Height = 5
+	l.Select(7)
+
+	lines := strings.Split(strings.TrimSuffix(l.View(), "\n"), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("Expected 5 rows and the count of the others, got %q", lines)
+	}
+	// Scrolled to keep the selected item, h, on the last row
+	if !strings.Contains(lines[0], "d") || !strings.Contains(lines[4], "h") {
+		t.Errorf("Expected rows d to h, got %q", lines)
+	}
+	if !strings.Contains(lines[5], "12 more") {
+		t.Errorf("Expected the count of the items below, got %q", lines[5])
+	}
+
+	l.Items = nil
+	l.Empty = "Nothing here"
+	if view := l.View(); !strings.Contains(view, "Nothing here") {
+		t.Errorf("Expected the empty text, got %q", view)
+	}
+}
+
+func TestListTruncates(t *testing.T) {
+	l := NewList([]Item{{Text: strings.Repeat("x", 50), Hint: "ctrl+p"}}, testListKeys, DefaultStyles())
+	l.Width = 20
+	view := strings.TrimSuffix(l.View(), "\n")
+	if !strings.Contains(view, "…") || !strings.HasSuffix(view, "ctrl+p") {
+		t.Errorf("Expected a truncated row ending with its hint, got %q", view)
+	}
+}
@@ -0,0 +1,101 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The keys answering a modal
+var (
+	YesKey = key.NewBinding(key.WithKeys("y", "Y", "enter"), key.WithHelp("y", "yes"))
+	NoKey  = key.NewBinding(key.WithKeys("n", "N", "esc"), key.WithHelp("n", "no"))
+)
+
+// modalWidth is how wide a modal is drawn when the screen has room
+const modalWidth = 60
+
+// Modal asks a yes or no question in a box drawn over the screen
+type Modal struct {
+	Title string
+	// Message says why the question is asked, and may be empty
+	Message  string
+	Question string
+	// Answered is set once the question was answered, and Yes then tells
+	// the answer
+	Answered bool
+	Yes      bool
+	// Width is the width of the screen, or 0 if it isn't known
+	Width  int
+	Styles Styles
+}
+
+// NewModal returns a modal asking question
+func NewModal(title, message, question string, styles Styles) Modal {
+	return Modal{Title: title, Message: message, Question: question, Styles: styles}
+}
+
+// Update answers the question with YesKey or NoKey
+func (m Modal) Update(msg bubbletea.Msg) (Modal, bubbletea.Cmd) {
+	switch msg := msg.(type) {
+	case bubbletea.WindowSizeMsg:
+		m.Width = msg.Width
+	case bubbletea.KeyMsg:
+		if m.Answered {
+			break
+		}
+		switch {
+		case key.Matches(msg, YesKey):
+			m.Answered, m.Yes = true, true
+		case key.Matches(msg, NoKey):
+			m.Answered, m.Yes = true, false
+		}
+	}
+	return m, nil
+}
+
+// View renders the box, at most modalWidth wide
+func (m Modal) View() string {
+	width := modalWidth
+	if m.Width > 0 && m.Width-4 < width {
+		width = m.Width - 4
+	}
+	text := m.Styles.Text.Copy().Width(width - 4)
+
+	var content strings.Builder
+	content.WriteString(m.Styles.Warning.Copy().Bold(true).Render(m.Title) + "\n\n")
+	if m.Message != "" {
+		content.WriteString(text.Render(m.Message) + "\n\n")
+	}
+	content.WriteString(text.Copy().Bold(true).Render(m.Question) + "\n\n")
+
+	bar := help.New()
+	bar.ShortSeparator = m.Styles.Separator
+	bar.Styles.ShortKey = m.Styles.Accent
+	bar.Styles.ShortDesc = m.Styles.Text
+	bar.Styles.ShortSeparator = m.Styles.Faint
+	content.WriteString(bar.ShortHelpView([]key.Binding{YesKey, NoKey}))
+	return m.Styles.Frame.Copy().BorderForeground(m.Styles.Warning.GetForeground()).Padding(0, 1).Render(content.String())
+}
+
+// Overlay draws box over the top of view, centered in width, leaving the
+// first line of view, such as a breadcrumb, in view
+func Overlay(view, box string, width int) string {
+	boxLines := strings.Split(box, "\n")
+	lines := strings.Split(view, "\n")
+	for len(lines) < len(boxLines)+1 {
+		lines = append(lines, "")
+	}
+
+	indent := ""
+	if boxWidth := lipgloss.Width(boxLines[0]); width > boxWidth {
+		indent = strings.Repeat(" ", (width-boxWidth)/2)
+	}
+	for i, line := range boxLines {
+		lines[i+1] = indent + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,59 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestModalAnswers(t *testing.T) {
+	tests := []struct {
+		key bubbletea.KeyMsg
+		yes bool
+	}{
+		{runes("y"), true},
+		{runes("Y"), true},
+		{press(bubbletea.KeyEnter), true},
+		{runes("n"), false},
+		{press(bubbletea.KeyEsc), false},
+	}
+	for _, tt := range tests {
+		m := NewModal("Confirm", "", "Remove it?", DefaultStyles())
+		m, _ = m.Update(runes("x"))
+		if m.Answered {
+			t.Fatalf("Expected x not to answer")
+		}
+		m, _ = m.Update(tt.key)
+		if !m.Answered || m.Yes != tt.yes {
+			t.Errorf("Expected %s to answer %v, got %v %v", tt.key, tt.yes, m.Answered, m.Yes)
+		}
+	}
+}
+
+func TestModalView(t *testing.T) {
+	m := NewModal("Confirm", "This command appears destructive: "+strings.Repeat("rm -rf dir ", 10), "Run it?", DefaultStyles())
+	m, _ = m.Update(bubbletea.WindowSizeMsg{Width: 40, Height: 20})
+	view := m.View()
+	for _, want := range []string{"Confirm", "destructive", "Run it?", "y yes"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the modal, got:\n%s", want, view)
+		}
+	}
+	if width := lipgloss.Width(view); width > 36 {
+		t.Errorf("Expected the modal to fit the screen with a margin, got %d wide", width)
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	view := Overlay("crumb\n1\n2\n3\n4", "[box]\n[box]", 15)
+	want := "crumb\n     [box]\n     [box]\n3\n4"
+	if view != want {
+		t.Errorf("Expected %q, got %q", want, view)
+	}
+	// A view shorter than the box grows to hold it
+	if lines := strings.Split(Overlay("crumb", "a\nb\nc", 0), "\n"); len(lines) != 4 {
+		t.Errorf("Expected the view to grow to 4 lines, got %q", lines)
+	}
+}
@@ -0,0 +1,48 @@
+package components
+
+import (
+	"github.com/charmbracelet/bubbles/progress"
+	bubbletea "github.com/charmbracelet/bubbletea"
+)
+
+// ProgressMsg reports how far a task got, as a fraction and in words
+type ProgressMsg struct {
+	Fraction float64
+	Text     string
+}
+
+// Progress shows how far a task got: a bar followed by text
+type Progress struct {
+	Bar      progress.Model
+	Fraction float64
+	Text     string
+	// Plain leaves out the bar, for screen readers
+	Plain  bool
+	Styles Styles
+}
+
+// NewProgress returns a progress drawn with bar
+func NewProgress(bar progress.Model, styles Styles) Progress {
+	return Progress{Bar: bar, Styles: styles}
+}
+
+// Update takes in the progress reported by a ProgressMsg
+func (p Progress) Update(msg bubbletea.Msg) (Progress, bubbletea.Cmd) {
+	switch msg := msg.(type) {
+	case ProgressMsg:
+		p.Fraction, p.Text = msg.Fraction, msg.Text
+	case progress.FrameMsg:
+		model, cmd := p.Bar.Update(msg)
+		p.Bar = model.(progress.Model)
+		return p, cmd
+	}
+	return p, nil
+}
+
+// View renders the bar and the text
+func (p Progress) View() string {
+	if p.Plain {
+		return p.Styles.Text.Render(p.Text)
+	}
+	return p.Bar.ViewAs(p.Fraction) + " " + p.Styles.Text.Render(p.Text)
+}
@@ -0,0 +1,24 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/progress"
+)
+
+func TestProgress(t *testing.T) {
+	p := NewProgress(progress.New(progress.WithWidth(10), progress.WithoutPercentage()), DefaultStyles())
+	p.Bar.Full, p.Bar.Empty = '#', '-'
+	p, _ = p.Update(ProgressMsg{Fraction: 0.5, Text: "Indexing pages"})
+
+	view := p.View()
+	if !strings.Contains(view, "#####-----") || !strings.Contains(view, "Indexing pages") {
+		t.Errorf("Expected a half full bar and the text, got %q", view)
+	}
+
+	p.Plain = true
+	if view := p.View(); strings.Contains(view, "#") || !strings.Contains(view, "Indexing pages") {
+		t.Errorf("Expected the text alone, got %q", view)
+	}
+}
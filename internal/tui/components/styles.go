@@ -0,0 +1,62 @@
+// Package components holds the pieces screens of the TUI are built from: a
+// list to move through, a line of text input, a yes or no dialog, a message
+// that goes away by itself and a progress bar. Each has its own Update and
+// View, so a screen only wires them up instead of drawing them again.
+package components
+
+import "github.com/charmbracelet/lipgloss"
+
+// Styles are what components draw with. The TUI builds them from its theme
+// and from how it draws: in ASCII only, or laid out for screen readers.
+type Styles struct {
+	Title    lipgloss.Style
+	Text     lipgloss.Style
+	Faint    lipgloss.Style
+	Selected lipgloss.Style
+	// Accent draws prompts and the keys in hints
+	Accent  lipgloss.Style
+	Warning lipgloss.Style
+	// Frame draws the border around boxes, or none when a screen reader
+	// would read it out
+	Frame lipgloss.Style
+	// Mark returns the prefix marking whether a row is selected, for when
+	// the Selected style can't be seen
+	Mark func(selected bool) string
+	// Cursor ends text being typed
+	Cursor string
+	// Ellipsis ends truncated text
+	Ellipsis string
+	// Separator goes between the keys of a hint line
+	Separator string
+}
+
+// DefaultStyles returns plain styles with Unicode symbols, the selection
+// shown by its style only
+func DefaultStyles() Styles {
+	text := lipgloss.NewStyle()
+	return Styles{
+		Title:     text.Copy().Bold(true),
+		Text:      text,
+		Faint:     text.Copy().Faint(true),
+		Selected:  text.Copy().Reverse(true),
+		Accent:    text.Copy().Bold(true),
+		Warning:   text.Copy().Bold(true),
+		Frame:     text.Copy().Border(lipgloss.RoundedBorder()),
+		Mark:      func(bool) string { return "" },
+		Cursor:    "█",
+		Ellipsis:  "…",
+		Separator: " • ",
+	}
+}
+
+// truncate shortens s to width cells, ending it with ellipsis when cut
+func truncate(s string, width int, ellipsis string) string {
+	if width <= 0 || lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes)+ellipsis) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + ellipsis
+}
@@ -0,0 +1,66 @@
+package components
+
+import (
+	"sync/atomic"
+	"time"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultToastDuration is how long a toast stays on screen by default
+const DefaultToastDuration = 5 * time.Second
+
+// toastIDs numbers the texts toasts show, so that hiding one doesn't hide
+// a text shown since, by the same toast or another one
+var toastIDs atomic.Int64
+
+// toastExpiredMsg asks to hide the text a toast showed
+type toastExpiredMsg struct {
+	id int64
+}
+
+// Toast is a message that goes away once it has been on screen for a
+// while, such as the outcome of the last action
+type Toast struct {
+	Text string
+	// Duration is how long a text stays on screen, or 0 for until the next
+	// one
+	Duration time.Duration
+	Styles   Styles
+	id       int64
+}
+
+// NewToast returns a toast showing each text for duration
+func NewToast(duration time.Duration, styles Styles) Toast {
+	return Toast{Duration: duration, Styles: styles}
+}
+
+// Show puts text on screen and returns the command hiding it once Duration
+// passed
+func (t *Toast) Show(text string) bubbletea.Cmd {
+	t.Text = text
+	t.id = toastIDs.Add(1)
+	if t.Duration <= 0 {
+		return nil
+	}
+	id := t.id
+	return bubbletea.Tick(t.Duration, func(time.Time) bubbletea.Msg {
+		return toastExpiredMsg{id: id}
+	})
+}
+
+// Update hides the text once its time is up
+func (t Toast) Update(msg bubbletea.Msg) (Toast, bubbletea.Cmd) {
+	if msg, ok := msg.(toastExpiredMsg); ok && msg.id == t.id {
+		t.Text = ""
+	}
+	return t, nil
+}
+
+// View renders the text, if any
+func (t Toast) View() string {
+	if t.Text == "" {
+		return ""
+	}
+	return t.Styles.Text.Render(t.Text)
+}
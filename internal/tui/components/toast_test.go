@@ -0,0 +1,35 @@
+package components
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToastExpires(t *testing.T) {
+	toast := NewToast(time.Millisecond, DefaultStyles())
+	first := toast.Show("Copied")
+	if toast.View() == "" || first == nil {
+		t.Fatal("Expected the text shown and a command hiding it")
+	}
+
+	// Hiding the first text leaves a text shown since
+	second := toast.Show("Saved")
+	toast, _ = toast.Update(first())
+	if toast.Text != "Saved" {
+		t.Errorf("Expected the later text to stay, got %q", toast.Text)
+	}
+	toast, _ = toast.Update(second())
+	if toast.Text != "" || toast.View() != "" {
+		t.Errorf("Expected the text hidden, got %q", toast.Text)
+	}
+}
+
+func TestToastWithoutDuration(t *testing.T) {
+	toast := NewToast(0, DefaultStyles())
+	if cmd := toast.Show("Stays"); cmd != nil {
+		t.Error("Expected no command hiding the text")
+	}
+	if toast.Text != "Stays" {
+		t.Errorf("Expected the text shown, got %q", toast.Text)
+	}
+}
@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"fmt"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/log"
+	"github.com/makalin/tldrpp/internal/tui/components"
+)
+
+// Confirmation is a question to answer yes to before something is done,
+// such as running a command that looks destructive
+type Confirmation struct {
+	// Message says why the question is asked, and may be empty
+	Message  string
+	Question string
+	// Accept, if not nil, is called once the question was answered yes,
+	// for instance to remember the answer
+	Accept func() error
+}
+
+// Checker returns the questions to answer yes to before the command of
+// execution runs from the TUI, or an error if it may not run at all
+type Checker func(execution history.Execution) ([]Confirmation, error)
+
+// SetChecker has commands run from the TUI confirmed in a dialog first.
+// The runner is only called once every confirmation was answered yes, so it
+// must not ask again.
+func (a *App) SetChecker(check Checker) {
+	a.checker = check
+}
+
+// confirmDialog asks a yes or no question in a modal, keeping the TUI on
+// screen where a prompt in the terminal would have to leave it
+type confirmDialog struct {
+	modal components.Modal
+	// yes and no, if not nil, return what to do after the answer
+	yes, no func() bubbletea.Cmd
+}
+
+// newConfirmDialog returns the dialog asking c
+func newConfirmDialog(c Confirmation, styles components.Styles) *confirmDialog {
+	return &confirmDialog{modal: components.NewModal("Confirm", c.Message, c.Question, styles)}
+}
+
+// update passes msg to the modal and, once it is answered, returns what
+// the answer leads to
+func (d *confirmDialog) update(msg bubbletea.Msg) (answered bool, cmd bubbletea.Cmd) {
+	d.modal, _ = d.modal.Update(msg)
+	if !d.modal.Answered {
+		return false, nil
+	}
+	next := d.no
+	if d.modal.Yes {
+		next = d.yes
+	}
+	if next == nil {
+		return true, nil
+	}
+	return true, next()
+}
+
+// view renders the dialog for a screen width wide
+func (d *confirmDialog) view(width int) string {
+	d.modal.Width = width
+	return d.modal.View()
+}
+
+// confirmEach asks confirmations in turn through show, which puts a dialog
+// on screen. Each one answered yes is accepted before the next is asked;
+// then runs once all were, and cancel as soon as one is answered no.
+func confirmEach(confirmations []Confirmation, styles components.Styles, show func(*confirmDialog), then, cancel func() bubbletea.Cmd) bubbletea.Cmd {
+	if len(confirmations) == 0 {
+		return then()
+	}
+	c := confirmations[0]
+	dialog := newConfirmDialog(c, styles)
+	dialog.yes = func() bubbletea.Cmd {
+		if c.Accept != nil {
+			if err := c.Accept(); err != nil {
+				log.Warn("failed to accept confirmation", "question", c.Question, "err", err)
+			}
+		}
+		return confirmEach(confirmations[1:], styles, show, then, cancel)
+	}
+	dialog.no = cancel
+	show(dialog)
+	return nil
+}
+
+// ask shows a dialog asking question, running yes if it is answered yes
+func (a *App) ask(message, question string, yes func() bubbletea.Cmd) {
+	a.confirm = newConfirmDialog(Confirmation{Message: message, Question: question}, a.theme.styles())
+	a.confirm.yes = yes
+}
+
+// handleConfirmKey answers the dialog on screen
+func (a *App) handleConfirmKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	if msg.Type == bubbletea.KeyCtrlC {
+		return a, bubbletea.Quit
+	}
+	dialog := a.confirm
+	// Clear the dialog first: answering may show the next one
+	a.confirm = nil
+	answered, cmd := dialog.update(msg)
+	if !answered {
+		a.confirm = dialog
+	}
+	return a, cmd
+}
+
+// confirmRun asks the checker's confirmations for execution, running it
+// once all of them were answered yes
+func (a *App) confirmRun(execution history.Execution, run func() bubbletea.Cmd) bubbletea.Cmd {
+	var confirmations []Confirmation
+	if a.checker != nil {
+		var err error
+		if confirmations, err = a.checker(execution); err != nil {
+			a.status = fmt.Sprintf("Failed to run command: %v", err)
+			return nil
+		}
+	}
+	return confirmEach(confirmations, a.theme.styles(), func(d *confirmDialog) { a.confirm = d }, run, func() bubbletea.Cmd {
+		a.status = "Command cancelled"
+		return nil
+	})
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// copyFormat is how examples are put on the clipboard besides the plain
+// rendered command
+type copyFormat int
+
+const (
+	// copyTemplate copies the commands as written on the page, with their
+	// placeholders
+	copyTemplate copyFormat = iota
+	// copyMarkdown copies the descriptions and the rendered commands as
+	// fenced markdown blocks, for docs and chat
+	copyMarkdown
+)
+
+// copyAs copies the marked examples, or the current one, in format
+func (a *App) copyAs(format copyFormat) (bubbletea.Model, bubbletea.Cmd) {
+	page := a.selectedPage()
+	if page == nil || a.currentExample() == nil {
+		return a, nil
+	}
+	if !a.config.Clipboard {
+		a.status = "Clipboard is disabled in the config"
+		return a, nil
+	}
+	indices := a.markedExamples()
+	if len(indices) == 0 {
+		// Marked examples are copied in bulk and not counted as used, as
+		// with y
+		a.recordExample()
+		indices = []int{a.exampleIdx}
+	}
+
+	var text, what string
+	switch format {
+	case copyTemplate:
+		text, what = a.renderTemplates(page, indices), "template"
+	case copyMarkdown:
+		text, what = a.renderMarkdown(page, indices), "markdown"
+	}
+	if err := CopyToClipboard(text); err != nil {
+		a.status = err.Error()
+		return a, nil
+	}
+	if len(indices) > 1 {
+		a.status = fmt.Sprintf("Copied %d examples as %s", len(indices), what)
+	} else {
+		a.status = fmt.Sprintf("Copied as %s", what)
+	}
+	return a, nil
+}
+
+// renderTemplates returns the commands at the given indices as written on
+// the page, one per line
+func (a *App) renderTemplates(page *types.Page, indices []int) string {
+	commands := make([]string, len(indices))
+	for n, i := range indices {
+		commands[n] = page.Examples[i].Command
+	}
+	return strings.Join(commands, "\n") + "\n"
+}
+
+// renderMarkdown returns the examples at the given indices as their
+// description followed by the rendered command in a fenced block. Secrets
+// are redacted: the text is meant to be shared.
+func (a *App) renderMarkdown(page *types.Page, indices []int) string {
+	blocks := make([]string, len(indices))
+	for n, i := range indices {
+		example := &page.Examples[i]
+		vars := a.varsFor(i, example)
+		command, _, _ := example.Redact(vars)
+		blocks[n] = fmt.Sprintf("%s:\n\n```sh\n%s\n```\n", example.Description, command)
+	}
+	return strings.Join(blocks, "\n")
+}
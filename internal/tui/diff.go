@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/diff"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// renderDiff renders the example's template above the command rendered
+// with the entered values, highlighting the words that differ. Secret
+// values are masked.
+func (a *App) renderDiff(example *types.Example) string {
+	vars := a.currentVars()
+	rendered, _, _ := example.Redact(vars)
+	ops := diff.Words(example.Command, rendered)
+
+	text := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	removed := lipgloss.NewStyle().Foreground(a.theme.Error).Strikethrough(true)
+	added := lipgloss.NewStyle().Foreground(a.theme.Success).Bold(true).Underline(true)
+
+	var before, after strings.Builder
+	before.WriteString(removed.Render("- "))
+	after.WriteString(added.Render("+ "))
+	for _, op := range ops {
+		switch op.Kind {
+		case diff.Equal:
+			before.WriteString(text.Render(op.Text))
+			after.WriteString(text.Render(op.Text))
+		case diff.Delete:
+			before.WriteString(removed.Render(op.Text))
+		case diff.Insert:
+			after.WriteString(added.Render(op.Text))
+		}
+	}
+
+	title := lipgloss.NewStyle().
+		Foreground(a.theme.Accent).
+		Bold(true).
+		Render("Changes")
+	if !diff.Changed(ops) {
+		return title + "\n" + text.Render("No placeholders filled yet") + "\n"
+	}
+	return title + "\n" + before.String() + "\n" + after.String() + "\n"
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// loadTip picks the tip of the day shown on the start screen
+func (a *App) loadTip() {
+	tip, err := a.cache.TipOfTheDay(a.platforms, a.clock.Now())
+	if err != nil {
+		a.tip = nil
+		return
+	}
+	a.tip = &tip
+}
+
+// surpriseMe opens a page picked at random
+func (a *App) surpriseMe() {
+	entry, err := a.cache.RandomPage(a.platforms)
+	if err != nil {
+		a.status = fmt.Sprintf("Failed to pick a page: %v", err)
+		return
+	}
+	a.jumpTo(entry.Name)
+}
+
+// renderTip renders the tip of the day, or "" if there is none
+func (a *App) renderTip() string {
+	if a.tip == nil || a.searchQuery != "" {
+		return ""
+	}
+	title := lipgloss.NewStyle().
+		Foreground(a.theme.Accent).
+		Bold(true)
+	text := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+
+	tip := a.tip.Name
+	if a.tip.Description != "" {
+		tip += " - " + a.tip.Description
+	}
+	return title.Render("Tip of the day: ") + text.Render(tip+" (T to open)") + "\n"
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/makalin/tldrpp/internal/config"
+)
+
+// pageEditedMsg reports that the editor opened on a custom page exited
+type pageEditedMsg struct {
+	err error
+}
+
+// editPage opens the selected page in the user's editor if it is a custom
+// page. The TUI gives the terminal to the editor until it exits.
+func (a *App) editPage() (bubbletea.Model, bubbletea.Cmd) {
+	if len(a.pages) == 0 || a.selectedIdx >= len(a.pages) {
+		return a, nil
+	}
+	entry := a.pages[a.selectedIdx]
+	path := a.cache.CustomPath(entry)
+	if path == "" {
+		a.status = fmt.Sprintf("%s is a downloaded page; write your own with 'tldrpp new %s'", entry.Name, entry.Name)
+		return a, nil
+	}
+
+	editor, err := config.EditorCommand(path)
+	if err != nil {
+		a.status = err.Error()
+		return a, nil
+	}
+	cmd := exec.Command(editor[0], editor[1:]...)
+	return a, bubbletea.ExecProcess(cmd, func(err error) bubbletea.Msg {
+		return pageEditedMsg{err: err}
+	})
+}
+
+// finishEdit re-reads the pages once the editor exits, so that the edited
+// page is shown as saved
+func (a *App) finishEdit(err error) (bubbletea.Model, bubbletea.Cmd) {
+	if err != nil {
+		a.status = fmt.Sprintf("Failed to run editor: %v", err)
+		return a, nil
+	}
+
+	a.reloadPages()
+	page := a.selectedPage()
+	if page == nil {
+		a.backTo(StatePages)
+		return a, nil
+	}
+	if a.exampleIdx >= len(page.Examples) {
+		a.exampleIdx = 0
+	}
+	a.marked = nil
+	a.status = fmt.Sprintf("Reloaded %s", page.Name)
+	return a, nil
+}
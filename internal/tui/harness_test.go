@@ -0,0 +1,391 @@
+package tui
+
+import (
+	"archive/zip"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the TUI snapshots")
+
+// harnessPages are the pages the harness's cache holds
+var harnessPages = map[string]string{
+	"pages/common/tar.md": "# tar\n\n> Archiving utility.\n> More information: <https://www.gnu.org/software/tar>.\n\n" +
+		"- Create an archive from files:\n\n`tar cf {{target.tar}} {{file1 file2 ...}}`\n\n" +
+		"- Extract an archive in a directory:\n\n`tar xf {{source.tar}} -C {{path/to/directory}}`\n",
+	"pages/common/rm.md": "# rm\n\n> Remove files or directories.\n\n- Remove files:\n\n`rm {{path/to/file1 path/to/file2 ...}}`\n",
+	"pages/linux/ls.md":  "# ls\n\n> List directory contents.\n\n- List files one per line:\n\n`ls -1`\n",
+}
+
+// harnessCmdWait is how long the harness waits for a command a key
+// returned; commands taking longer, such as ticks, are dropped
+const harnessCmdWait = 200 * time.Millisecond
+
+// harness drives the TUI the way a terminal would, sending it keys and
+// running the commands they return, and compares the frames it draws with
+// golden files in testdata/golden. Run the tests with -update to rewrite
+// them after changing what a screen looks like.
+type harness struct {
+	t   *testing.T
+	app *App
+	// dir is where the harness keeps its files, scrubbed from frames
+	dir  string
+	quit bool
+}
+
+// newHarness returns a harness running the TUI in a width by height
+// terminal without colors, or one whose size isn't known if width is 0,
+// over a cache of harnessPages and an empty home directory. Nothing is
+// installed, so every page's command is missing.
+func newHarness(t *testing.T, width, height int) *harness {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", filepath.Join(dir, "home"))
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("PATH", filepath.Join(dir, "bin"))
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	archive := filepath.Join(dir, "tldr.zip")
+	writeArchive(t, archive, harnessPages)
+	cfg := config.DefaultConfig()
+	cfg.CacheDir = filepath.Join(dir, "cache")
+	cfg.SearchDebounceMs = 0
+	cfg.PresentTypingMs = 0
+	cfg.Sources = []config.Source{{Name: "harness", URL: "file://" + filepath.ToSlash(archive), Trusted: true}}
+	cacheManager := cache.New(cfg.CacheDir, cfg.Sources)
+	if err := cacheManager.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	a := New(cfg, cacheManager, nil)
+	// Messages stay in the status bar until replaced, as the tick hiding
+	// them would be dropped anyway
+	a.toast.Duration = 0
+	if err := a.loadPages(); err != nil {
+		t.Fatalf("loadPages failed: %v", err)
+	}
+	h := &harness{t: t, app: a, dir: dir}
+	if width > 0 {
+		h.send(bubbletea.WindowSizeMsg{Width: width, Height: height})
+	}
+	return h
+}
+
+// writeArchive writes a zip archive of files to path, sorted by name as
+// the index lists pages in the order the archive holds them
+func writeArchive(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	w := zip.NewWriter(f)
+	for _, name := range names {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("zip Create failed: %v", err)
+		}
+		entry.Write([]byte(files[name]))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("zip Close failed: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+}
+
+// keyTypes are the keys sent by name rather than as the runes typed
+var keyTypes = map[string]bubbletea.KeyType{
+	"enter":     bubbletea.KeyEnter,
+	"esc":       bubbletea.KeyEsc,
+	"tab":       bubbletea.KeyTab,
+	"shift+tab": bubbletea.KeyShiftTab,
+	"backspace": bubbletea.KeyBackspace,
+	"up":        bubbletea.KeyUp,
+	"down":      bubbletea.KeyDown,
+	"left":      bubbletea.KeyLeft,
+	"right":     bubbletea.KeyRight,
+	"ctrl+o":    bubbletea.KeyCtrlO,
+	"ctrl+p":    bubbletea.KeyCtrlP,
+	"ctrl+s":    bubbletea.KeyCtrlS,
+	"ctrl+j":    bubbletea.KeyCtrlJ,
+	"ctrl+x":    bubbletea.KeyCtrlX,
+}
+
+// keys presses each key in turn: a key name such as "enter", or text typed
+// rune by rune
+func (h *harness) keys(keys ...string) *harness {
+	for _, k := range keys {
+		if keyType, ok := keyTypes[k]; ok {
+			h.send(bubbletea.KeyMsg{Type: keyType})
+			continue
+		}
+		for _, r := range k {
+			if r == ' ' {
+				h.send(bubbletea.KeyMsg{Type: bubbletea.KeySpace, Runes: []rune{r}})
+			} else {
+				h.send(bubbletea.KeyMsg{Type: bubbletea.KeyRunes, Runes: []rune{r}})
+			}
+		}
+	}
+	return h
+}
+
+// send passes msg to the TUI and, in turn, the messages of the commands it
+// returns
+func (h *harness) send(msg bubbletea.Msg) {
+	if h.quit {
+		h.t.Fatalf("Sent %#v after the TUI quit", msg)
+	}
+	_, cmd := h.app.Update(msg)
+	h.run(cmd)
+}
+
+// run runs cmd and sends its message, unless it takes longer than
+// harnessCmdWait
+func (h *harness) run(cmd bubbletea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	result := make(chan bubbletea.Msg, 1)
+	go func() {
+		result <- cmd()
+	}()
+	var msg bubbletea.Msg
+	select {
+	case msg = <-result:
+	case <-time.After(harnessCmdWait):
+		return
+	}
+
+	switch msg := msg.(type) {
+	case nil:
+	case bubbletea.QuitMsg:
+		h.quit = true
+	case bubbletea.BatchMsg:
+		for _, cmd := range msg {
+			h.run(cmd)
+		}
+	default:
+		h.send(msg)
+	}
+}
+
+// timestamps are scrubbed from frames, as they change with every run
+var timestamps = regexp.MustCompile(`\d{4}-\d{2}-\d{2} \d{2}:\d{2}`)
+
+// frame returns what the TUI draws, scrubbed of what changes between runs
+// and of trailing spaces
+func (h *harness) frame() string {
+	frame := h.app.View()
+	frame = strings.ReplaceAll(frame, h.dir, "$TMP")
+	frame = timestamps.ReplaceAllString(frame, "YYYY-MM-DD hh:mm")
+	lines := strings.Split(frame, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// snapshot checks the frame against testdata/golden/<name>.golden, or
+// rewrites the file with -update
+func (h *harness) snapshot(name string) {
+	h.t.Helper()
+	path := filepath.Join("testdata", "golden", name+".golden")
+	frame := h.frame()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			h.t.Fatalf("MkdirAll failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(frame), 0644); err != nil {
+			h.t.Fatalf("WriteFile failed: %v", err)
+		}
+		return
+	}
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		h.t.Fatalf("Failed to read the golden file, run with -update to write it: %v", err)
+	}
+	if frame != string(golden) {
+		h.t.Errorf("Frame differs from %s; run with -update if the change is intended.\nGot:\n%s\nWant:\n%s", path, frame, golden)
+	}
+}
+
+// TestSnapshots drives the TUI to each of its screens and compares what it
+// draws with the golden files
+func TestSnapshots(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+	}{
+		{"search", nil},
+		{"pages", []string{"enter"}},
+		{"pages_filtered", []string{"enter", "/", "ta", "enter"}},
+		{"examples", []string{"enter", "/", "tar", "enter", "enter"}},
+		{"examples_marked", []string{"enter", "/", "tar", "enter", "enter", " ", "down", " "}},
+		{"edit", []string{"enter", "/", "tar", "enter", "enter", "tab"}},
+		{"edit_typing", []string{"enter", "/", "tar", "enter", "enter", "tab", "enter", "backup.tar"}},
+		{"edit_typed", []string{"enter", "/", "tar", "enter", "enter", "tab", "enter", "backup.tar", "enter", "tab"}},
+		{"naming", []string{"enter", "/", "tar", "enter", "enter", "s"}},
+		{"saved", []string{"enter", "/", "tar", "enter", "enter", "s", "enter"}},
+		{"snippets", []string{"enter", "/", "tar", "enter", "enter", "s", "enter", "esc", "esc", "S"}},
+		{"snippet_remove", []string{"enter", "/", "tar", "enter", "enter", "s", "enter", "esc", "esc", "S", "d"}},
+		{"help", []string{"?"}},
+		{"hints", []string{"enter", "H"}},
+		{"palette", []string{"ctrl+p"}},
+		{"palette_query", []string{"ctrl+p", "theme"}},
+		{"qr", []string{"enter", "/", "ls", "enter", "enter", "Q"}},
+		{"qr_closed", []string{"enter", "/", "ls", "enter", "enter", "Q", "x"}},
+		{"present", []string{"enter", "/", "tar", "enter", "enter", "P"}},
+		{"present_next", []string{"enter", "/", "tar", "enter", "enter", "P", " "}},
+		{"present_closed", []string{"enter", "/", "tar", "enter", "enter", "P", "esc"}},
+		{"stats", []string{"enter", "enter", "esc", "U"}},
+		{"workdir", []string{"enter", "/", "ls", "enter", "enter", "W", "/", "enter"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t, 100, 30)
+			h.keys(tt.keys...)
+			h.snapshot(tt.name)
+		})
+	}
+}
+
+// TestSnapshotConfirm checks the dialog confirming a command drawn over the
+// page, and what answering it does
+func TestSnapshotConfirm(t *testing.T) {
+	h := newHarness(t, 100, 30)
+	var ran []string
+	h.app.SetRunner(func(execution history.Execution, output io.Writer) (bool, error) {
+		ran = append(ran, execution.Command)
+		return true, nil
+	})
+	h.app.SetChecker(func(execution history.Execution) ([]Confirmation, error) {
+		return []Confirmation{{
+			Message:  "This command appears destructive: " + execution.Command,
+			Question: "Are you sure you want to execute it?",
+		}}, nil
+	})
+
+	h.keys("enter", "/", "rm", "enter", "enter", "X")
+	h.snapshot("confirm")
+	h.keys("n")
+	h.snapshot("confirm_cancelled")
+	if len(ran) != 0 {
+		t.Errorf("Expected a cancelled command not to run, ran %q", ran)
+	}
+}
+
+// TestRunKeys checks that the default run key, ctrl+enter, also runs on
+// the keys terminals that can't send it do send
+func TestRunKeys(t *testing.T) {
+	h := newHarness(t, 100, 30)
+	var ran []string
+	h.app.SetRunner(func(execution history.Execution, output io.Writer) (bool, error) {
+		return true, nil
+	})
+	// Running a command is checked first; the harness doesn't hand the
+	// terminal over
+	h.app.SetChecker(func(execution history.Execution) ([]Confirmation, error) {
+		ran = append(ran, execution.Command)
+		return nil, nil
+	})
+
+	h.keys("enter", "/", "ls", "enter", "enter", "ctrl+j", "ctrl+x")
+	if len(ran) != 2 {
+		t.Errorf("Expected ctrl+j and ctrl+x to run the command, ran %q", ran)
+	}
+}
+
+// TestPresentTyping checks that presentation mode types the command out a
+// rune per tick, and drops the ticks of a command it moved away from
+func TestPresentTyping(t *testing.T) {
+	h := newHarness(t, 100, 30)
+	h.keys("enter", "/", "ls", "enter", "enter")
+	h.app.config.PresentTypingMs = 40
+	h.app.startPresentation()
+	if !strings.Contains(h.frame(), "│   "+Glyph("█", "_")) {
+		t.Fatalf("Expected nothing typed yet, got:\n%s", h.frame())
+	}
+	seq := h.app.present.seq
+	h.app.Update(presentTickMsg{seq: seq})
+	h.app.Update(presentTickMsg{seq: seq})
+	if !strings.Contains(h.frame(), "ls"+Glyph("█", "_")) {
+		t.Errorf("Expected two runes typed, got:\n%s", h.frame())
+	}
+	h.app.Update(presentTickMsg{seq: seq - 1})
+	if h.app.present.typed != 2 {
+		t.Errorf("Expected a stale tick to be dropped, typed %d", h.app.present.typed)
+	}
+	for i := 0; i < 3; i++ {
+		h.app.Update(presentTickMsg{seq: seq})
+	}
+	if h.app.present.typed != -1 || !strings.Contains(h.frame(), "ls -1") {
+		t.Errorf("Expected the whole command once typed, got:\n%s", h.frame())
+	}
+}
+
+// TestWorkDir checks that the directory commands run in is picked from
+// the recent ones or typed, and rejected when it doesn't exist
+func TestWorkDir(t *testing.T) {
+	h := newHarness(t, 100, 30)
+	recent := filepath.Join(h.dir, "project")
+	if err := os.Mkdir(recent, 0755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	executions, err := history.LoadLog(filepath.Join(h.dir, "executions.json"))
+	if err != nil {
+		t.Fatalf("LoadLog failed: %v", err)
+	}
+	executions.Add(history.Execution{Page: "ls", Command: "ls -1", Dir: recent})
+	h.app.executions = executions
+
+	// The directory tldr++ was started in comes first, then the recent ones
+	h.keys("enter", "/", "ls", "enter", "enter", "W", "down", "down", "enter")
+	if h.app.workDir != recent {
+		t.Fatalf("Expected commands to run in %s, got %q", recent, h.app.workDir)
+	}
+	h.keys("W", "backspace", "/nope", "enter")
+	if h.app.workDir != recent || !strings.Contains(h.frame(), "Invalid working directory") {
+		t.Errorf("Expected a missing directory to be rejected, got %q:\n%s", h.app.workDir, h.frame())
+	}
+
+	h.app.executeCommand(false)
+	if h.app.rerun == nil || h.app.rerun.Dir != recent {
+		t.Errorf("Expected the command to run in %s, got %+v", recent, h.app.rerun)
+	}
+}
+
+// TestSnapshotSizes checks the pages screen in split view, a single column
+// and a terminal whose size isn't known yet
+func TestSnapshotSizes(t *testing.T) {
+	for _, size := range terminalSizes {
+		t.Run(size.name, func(t *testing.T) {
+			h := newHarness(t, size.width, size.height)
+			h.keys("enter")
+			h.snapshot("pages_" + size.name)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/terminal"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// hyperlinks is set when the terminal shows OSC 8 hyperlinks
+var hyperlinks bool
+
+// SetHyperlinks makes URLs and commands with a page clickable, for
+// terminals that support OSC 8 hyperlinks
+func SetHyperlinks(on bool) {
+	hyperlinks = on
+}
+
+// hyperlink returns text that opens url when clicked, or text alone when
+// hyperlinks are off or there is no url. Styles must be applied to text
+// first: styling a link would break up its escape sequences.
+func hyperlink(url, text string) string {
+	if !hyperlinks || url == "" {
+		return text
+	}
+	return terminal.Hyperlink(url, text)
+}
+
+// pageURL returns where the page a link leads to can be read upstream, if
+// it will be shown as a hyperlink
+func (a *App) pageURL(name string) string {
+	if !hyperlinks {
+		return ""
+	}
+	page, err := a.cache.FindPage(name)
+	if err != nil {
+		return ""
+	}
+	return a.cache.PageURL(types.IndexEntry{Name: page.Name, Platform: page.Platform})
+}
+
+// fitHyperlinks drops the hyperlinks from the lines of view that are wider
+// than width once their URLs are counted. The renderer takes the URLs for
+// text and would cut such lines short, possibly in the middle of a link.
+func fitHyperlinks(view string, width int) string {
+	if !hyperlinks || width <= 0 {
+		return view
+	}
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		if lipgloss.Width(line) > width {
+			lines[i] = terminal.StripHyperlinks(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
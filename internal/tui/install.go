@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"strings"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/install"
+)
+
+// loadInstall checks whether the command of the selected page is installed
+// and, if not, works out how to install it. A subcommand page such as
+// git-commit is about its parent command's program.
+func (a *App) loadInstall() {
+	a.missing, a.installCommand = false, ""
+	page := a.selectedPage()
+	if page == nil {
+		return
+	}
+
+	tool := page.Name
+	if parent, _, ok := strings.Cut(page.Name, "-"); ok && !install.Installed(tool) && a.cache.PageName(parent) != "" {
+		tool = parent
+	}
+	if install.Installed(tool) {
+		return
+	}
+	a.missing = true
+	if manager := install.Detect(); manager != nil {
+		a.installCommand = manager.Command(tool)
+	}
+}
+
+// installBadge flags a page whose command isn't installed
+func (a *App) installBadge() string {
+	if !a.missing {
+		return ""
+	}
+	return " " + lipgloss.NewStyle().Foreground(a.theme.Warning).Render("[not installed]")
+}
+
+// renderInstall shows how to install the page's command, if it is missing
+// and a package manager is known
+func (a *App) renderInstall() string {
+	if a.installCommand == "" {
+		return ""
+	}
+	meta := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	keys := a.keys.Install.Help().Key + " runs, " + a.keys.CopyInstall.Help().Key + " copies"
+	return meta.Render("Install: "+a.installCommand+" ("+keys+")") + "\n"
+}
+
+// runInstall runs the command installing the page's command like any other
+// command run from a page, asking first
+func (a *App) runInstall() (bubbletea.Model, bubbletea.Cmd) {
+	page := a.selectedPage()
+	if page == nil || a.installCommand == "" {
+		return a, nil
+	}
+	execution := history.Execution{
+		Page:     page.Name,
+		Platform: page.Platform,
+		Command:  a.installCommand,
+		Template: a.installCommand,
+	}
+	if a.runner == nil {
+		a.rerun = &execution
+		return a, bubbletea.Quit
+	}
+	return a.runInTerminal(execution, false)
+}
+
+// copyInstall copies the command installing the page's command
+func (a *App) copyInstall() {
+	if a.installCommand == "" {
+		return
+	}
+	if !a.config.Clipboard {
+		a.status = "Clipboard is disabled in the config"
+		return
+	}
+	if err := CopyToClipboard(a.installCommand); err != nil {
+		a.status = err.Error()
+		return
+	}
+	a.status = "Copied install command to clipboard"
+}
@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/tui/components"
+)
+
+// keyMap holds the key bindings of the TUI. Keys are matched against these
+// bindings and the hint bar is drawn from them, so the hints always show
+// the keys that work.
+type keyMap struct {
+	Up, Down, Prev, Next key.Binding
+	// ArrowUp and ArrowDown move through lists while typing, where letters
+	// are text
+	ArrowUp, ArrowDown key.Binding
+	Select, Back       key.Binding
+	Field, PrevField   key.Binding
+
+	Run, Copy, Paste key.Binding
+	// RunCapture runs the command keeping its output for the output history
+	RunCapture key.Binding
+	// WorkDir chooses the directory commands run in
+	WorkDir key.Binding
+	// Sudo runs the command as root with sudo
+	Sudo key.Binding
+	// CopyTemplate and CopyMarkdown copy in other formats than Copy
+	CopyTemplate, CopyMarkdown key.Binding
+	// QR shows the command as a QR code, to photograph it
+	QR key.Binding
+	// Present shows the example on the whole screen, and Retype types its
+	// command out again there
+	Present, Retype key.Binding
+
+	Mark, Type, Override, Save   key.Binding
+	Undo, Redo, Reset, Memory    key.Binding
+	Filter, Preview              key.Binding
+	AllPlatforms, Platform       key.Binding
+	Recent, Last, Refresh        key.Binding
+	Cancel                       key.Binding
+	Related, Follow              key.Binding
+	Install, CopyInstall         key.Binding
+	EditPage, EditInTUI          key.Binding
+	Pager, RawPager, Browser     key.Binding
+	Snippets, Delete             key.Binding
+	Stats, Random, Tip, News     key.Binding
+	Write                        key.Binding
+	Outputs, SaveOutput, Compare key.Binding
+
+	Palette, Help, Hints, Quit key.Binding
+}
+
+// newKeyMap returns the key bindings, with running, copying and pasting a
+// command on the keys set in the config's keymap
+func newKeyMap(keymap config.Keymap) keyMap {
+	return keyMap{
+		Up:        key.NewBinding(key.WithKeys("up", "k"), key.WithHelp(Glyph("↑", "up")+"/k", "up")),
+		Down:      key.NewBinding(key.WithKeys("down", "j"), key.WithHelp(Glyph("↓", "down")+"/j", "down")),
+		ArrowUp:   key.NewBinding(key.WithKeys("up"), key.WithHelp(Glyph("↑", "up"), "up")),
+		ArrowDown: key.NewBinding(key.WithKeys("down"), key.WithHelp(Glyph("↓", "down"), "down")),
+		Prev:      key.NewBinding(key.WithKeys("left", "h"), key.WithHelp(Glyph("←", "left")+"/h", "previous")),
+		Next:      key.NewBinding(key.WithKeys("right", "l"), key.WithHelp(Glyph("→", "right")+"/l", "next")),
+		Select:    key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+		Back:      key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+		Field:     key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next field")),
+		PrevField: key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "previous field")),
+
+		Run:   runBinding(keymap.Run),
+		Copy:  key.NewBinding(key.WithKeys(keymap.Copy), key.WithHelp(keymap.Copy, "copy")),
+		Paste: key.NewBinding(key.WithKeys(keymap.Paste), key.WithHelp(keymap.Paste, "paste")),
+
+		RunCapture:   key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "run, keep output")),
+		WorkDir:      key.NewBinding(key.WithKeys("W"), key.WithHelp("W", "run in directory")),
+		Sudo:         key.NewBinding(key.WithKeys("#"), key.WithHelp("#", "run with sudo")),
+		CopyTemplate: key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "copy template")),
+		CopyMarkdown: key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "copy markdown")),
+		QR:           key.NewBinding(key.WithKeys("Q"), key.WithHelp("Q", "QR code")),
+		Present:      key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "present")),
+		Retype:       key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "type again")),
+
+		Mark:         key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "mark")),
+		Type:         key.NewBinding(key.WithKeys("enter", "e"), key.WithHelp("enter/e", "type value")),
+		Override:     key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "own value")),
+		Save:         key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "save snippet")),
+		Undo:         key.NewBinding(key.WithKeys("u", "ctrl+z"), key.WithHelp("u", "undo")),
+		Redo:         key.NewBinding(key.WithKeys("ctrl+r", "ctrl+y"), key.WithHelp("ctrl+r", "redo")),
+		Reset:        key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "reset to defaults")),
+		Memory:       key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "memory")),
+		Filter:       key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+		Preview:      key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "preview")),
+		AllPlatforms: key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "all platforms")),
+		Platform:     key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6"), key.WithHelp("1-6", "platform")),
+		Recent:       key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"), key.WithHelp("1-9", "run again")),
+		Last:         key.NewBinding(key.WithKeys("!"), key.WithHelp("!", "run last")),
+		Refresh:      key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh cache")),
+		Cancel:       key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "cancel refresh")),
+		Related:      key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "related")),
+		Follow:       key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "follow link")),
+		Install:      key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "install")),
+		CopyInstall:  key.NewBinding(key.WithKeys("I"), key.WithHelp("I", "copy install command")),
+		EditPage:     key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit page")),
+		EditInTUI:    key.NewBinding(key.WithKeys("E"), key.WithHelp("E", "edit page here")),
+		Pager:        key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "pager")),
+		RawPager:     key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "raw pager")),
+		Browser:      key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "browser")),
+		Snippets:     key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "snippets")),
+		Delete:       key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+		Stats:        key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "stats")),
+		Random:       key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "random page")),
+		Tip:          key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "tip")),
+		News:         key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "what's new")),
+		Write:        key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "save")),
+		Outputs:      key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "output history")),
+		SaveOutput:   key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "save to file")),
+		Compare:      key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "compare with previous")),
+
+		Palette: key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "commands")),
+		Help:    key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+		Hints:   key.NewBinding(key.WithKeys("H"), key.WithHelp("H", "more keys")),
+		Quit:    key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+	}
+}
+
+// runBinding returns the binding running a command on run. Terminals tell
+// ctrl+enter from enter only with keyboard protocols tldr++ doesn't read,
+// and most send it as ctrl+j or not at all, Windows consoles among them, so
+// ctrl+enter also matches ctrl+j and ctrl+x.
+func runBinding(run string) key.Binding {
+	if run != "ctrl+enter" {
+		return key.NewBinding(key.WithKeys(run), key.WithHelp(run, "run"))
+	}
+	return key.NewBinding(key.WithKeys(run, "ctrl+j", "ctrl+x"), key.WithHelp(run+"/ctrl+x", "run"))
+}
+
+// as returns b described as desc, for keys that do different things on
+// different screens
+func as(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// hints are the key bindings shown in the hint bar: a line of the most used
+// ones, or all of them in columns once expanded
+type hints struct {
+	short []key.Binding
+	full  [][]key.Binding
+}
+
+func (h hints) ShortHelp() []key.Binding  { return h.short }
+func (h hints) FullHelp() [][]key.Binding { return h.full }
+
+// hints returns the keys that work in the current state
+func (a *App) hints() hints {
+	k := a.keys
+	hints := hints{}
+	// Keys every screen has, shown last when expanded
+	general := []key.Binding{k.Back, k.Palette, k.Help, as(k.Hints, "fewer keys"), k.Quit}
+
+	switch {
+	case a.confirm != nil:
+		hints.short = []key.Binding{components.YesKey, components.NoKey}
+	case a.qr != nil:
+		hints.short = []key.Binding{as(k.Back, "close")}
+	case a.palette != nil:
+		hints.short = []key.Binding{k.ArrowUp, k.ArrowDown, as(k.Select, "run"), as(k.Back, "close")}
+	case a.filtering:
+		hints.short = []key.Binding{k.ArrowUp, k.ArrowDown, as(k.Select, "done"), as(k.Back, "clear filter")}
+	case a.naming != nil, a.outputPath != nil:
+		hints.short = []key.Binding{as(k.Select, "save"), as(k.Back, "cancel")}
+	case a.workDirPrompt != nil:
+		hints.short = []key.Binding{as(k.Select, "set"), as(k.Back, "cancel"), as(k.ArrowUp, "previous directory"), as(k.ArrowDown, "next directory")}
+	case a.typing:
+		hints.short = []key.Binding{as(k.Select, "set value"), as(k.Field, "set and next"), as(k.Back, "cancel")}
+		if placeholder, ok := a.focusedPlaceholder(); ok && len(a.suggestions.Suggest(placeholder)) > 0 {
+			hints.short = append(hints.short, as(k.ArrowUp, "previous suggestion"), as(k.ArrowDown, "next suggestion"))
+		}
+	case a.relatedFocus:
+		hints.short = []key.Binding{as(k.Prev, "previous page"), as(k.Next, "next page"), as(k.Select, "open"), k.Back}
+	case a.linkFocus:
+		hints.short = []key.Binding{as(k.Prev, "previous link"), as(k.Next, "next link"), as(k.Select, "follow"), k.Back}
+	case a.state == StateSearch:
+		hints.short = []key.Binding{as(k.Select, "search"), k.Recent, k.Last, k.Random, k.Snippets, k.Stats, k.Palette, k.Help, k.Quit}
+		hints.full = [][]key.Binding{
+			{as(k.Select, "search"), k.Recent, k.Last, k.Refresh, k.Cancel},
+			{k.Random, k.Tip, k.News},
+			{k.Snippets, k.Stats, k.Outputs},
+			general[1:],
+		}
+	case a.state == StatePages:
+		hints.short = []key.Binding{k.Up, k.Down, as(k.Select, "open"), k.Filter, k.Preview, k.Back, k.Help}
+		hints.full = [][]key.Binding{
+			{k.Up, k.Down, as(k.Select, "open")},
+			{k.Filter, k.Preview, k.AllPlatforms, k.Platform},
+			{k.EditPage, k.EditInTUI, k.Random, k.Snippets, k.Stats},
+			general,
+		}
+	case a.state == StateExamples && len(a.marked) > 0:
+		hints.short = []key.Binding{k.Mark, as(k.Copy, "copy as script"), k.CopyTemplate, k.CopyMarkdown, as(k.Save, "save as snippet"), k.Back}
+	case a.state == StateExamples:
+		page := []key.Binding{k.Pager, k.RawPager, k.Browser, k.Last, k.Outputs}
+		if a.selectedIdx < len(a.pages) && a.cache.CustomPath(a.pages[a.selectedIdx]) != "" {
+			page = append(page, k.EditPage, k.EditInTUI)
+		}
+		if len(a.links) > 0 {
+			page = append(page, k.Follow)
+		}
+		if len(a.related) > 0 {
+			page = append(page, k.Related)
+		}
+		if a.installCommand != "" {
+			page = append(page, k.Install, k.CopyInstall)
+		}
+		hints.short = []key.Binding{k.Up, k.Down, k.Mark, as(k.Field, "edit"), k.Run, k.Copy, k.Paste, k.Save, k.Back}
+		hints.full = [][]key.Binding{
+			{k.Up, k.Down, k.Mark, as(k.Field, "edit")},
+			{k.Run, k.RunCapture, k.Sudo, k.WorkDir, k.Copy, k.CopyTemplate, k.CopyMarkdown, k.QR, k.Paste, k.Save, k.Present},
+			page,
+			general,
+		}
+	case a.state == StateEdit:
+		hints.short = []key.Binding{as(k.Field, "field"), k.Type, as(k.Next, "choose"), k.Override, k.Undo, k.Run, k.Copy, k.Paste, k.Back}
+		hints.full = [][]key.Binding{
+			{k.Field, k.PrevField, k.Type},
+			{as(k.Prev, "previous choice"), as(k.Next, "next choice"), k.Override, k.Memory},
+			{k.Undo, k.Redo, k.Reset},
+			{k.Run, k.RunCapture, k.Sudo, k.WorkDir, k.Copy, k.CopyTemplate, k.CopyMarkdown, k.QR, k.Paste, k.Save, k.Present},
+			general,
+		}
+	case a.state == StateHelp:
+		hints.short = []key.Binding{as(k.Help, "close help"), k.Back, k.Quit}
+	case a.state == StateSnippets:
+		hints.short = []key.Binding{k.Up, k.Down, as(k.Select, "run"), k.Delete, k.Back}
+	case a.state == StateOutput:
+		hints.short = []key.Binding{k.Up, k.Down, as(k.Copy, "copy"), k.SaveOutput, k.Compare, k.Back}
+	case a.state == StateEditPage:
+		hints.short = []key.Binding{k.Write, as(k.Back, "discard")}
+	default:
+		hints.short = []key.Binding{k.Back, k.Quit}
+	}
+
+	if hints.full == nil {
+		hints.full = [][]key.Binding{hints.short}
+	} else {
+		hints.short = append(hints.short, k.Hints)
+	}
+	return hints
+}
+
+// renderHints renders the hint bar for the current state: one line, or
+// every key when expanded with H
+func (a *App) renderHints() string {
+	bar := help.New()
+	bar.Width = a.width
+	bar.ShowAll = a.allHints
+	bar.Ellipsis = Glyph("…", "...")
+	bar.ShortSeparator = Glyph(" • ", " | ")
+
+	keys := lipgloss.NewStyle().Foreground(a.theme.Accent)
+	desc := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	faint := desc.Copy().Faint(true)
+	bar.Styles = help.Styles{
+		Ellipsis:       faint,
+		ShortKey:       keys,
+		ShortDesc:      desc,
+		ShortSeparator: faint,
+		FullKey:        keys,
+		FullDesc:       desc,
+		FullSeparator:  faint,
+	}
+	return bar.View(a.hints())
+}
+
+// hintLines is how many lines the hint bar takes
+func (a *App) hintLines() int {
+	return lipgloss.Height(a.renderHints())
+}
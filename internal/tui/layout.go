@@ -0,0 +1,258 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+const (
+	// minSplitWidth is the narrowest terminal that gets the split-pane layout
+	minSplitWidth = 80
+	// pagesChrome is the number of lines around the pages list (breadcrumb,
+	// header, platforms, filter and a one line hint bar)
+	pagesChrome = 8
+	// defaultListRows is how many pages are listed before the terminal's
+	// size is known
+	defaultListRows = 50
+	// maxCachedRows bounds how many rendered rows of the pages list are
+	// kept while scrolling through a long one
+	maxCachedRows = 1000
+)
+
+// splitView reports whether the pages list is shown next to a preview.
+// Screen readers read the screen line by line, so the accessible layout
+// never splits it.
+func (a *App) splitView() bool {
+	return !a.singlePane && !accessible && a.width >= minSplitWidth
+}
+
+// renderSplit renders the pages list and the selected page preview side by side
+func (a *App) renderSplit() string {
+	listWidth := a.width * 2 / 5
+	previewWidth := a.width - listWidth - 1
+
+	list := lipgloss.NewStyle().
+		Width(listWidth).
+		MarginRight(1).
+		Render(a.renderPageList(listWidth))
+
+	var preview string
+	if page := a.selectedPage(); page != nil {
+		preview = a.renderPreview(page, previewWidth)
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, preview)
+}
+
+// renderPageList renders the visible part of the pages list, however long
+// the list is. A width of 0 means the list has the whole screen and shows
+// descriptions too.
+func (a *App) renderPageList(width int) string {
+	if len(a.pages) == 0 {
+		return lipgloss.NewStyle().
+			Foreground(a.theme.Warning).
+			Render("No pages match")
+	}
+
+	start, end := a.listWindow()
+
+	// Rows only change with the list, its width and the theme; moving the
+	// cursor renders the selected row alone
+	if !a.rows.valid(a.pages, width, a.theme) || len(a.rows.rows) > maxCachedRows {
+		a.rows = rowCache{pages: a.pages, width: width, theme: a.theme, rows: make(map[int]string)}
+	}
+
+	var content strings.Builder
+	for i := start; i < end; i++ {
+		if i == a.selectedIdx {
+			content.WriteString(a.renderPageRow(i, width, true) + "\n")
+			continue
+		}
+		row, ok := a.rows.rows[i]
+		if !ok {
+			row = a.renderPageRow(i, width, false)
+			a.rows.rows[i] = row
+		}
+		content.WriteString(row + "\n")
+	}
+
+	return strings.TrimSuffix(content.String(), "\n")
+}
+
+// renderPageRow renders a row of the pages list
+func (a *App) renderPageRow(i, width int, selected bool) string {
+	page := a.pages[i]
+	style := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	if selected {
+		style = style.Background(a.theme.Highlight).Foreground(a.theme.Background)
+	}
+
+	mark := selectMark(selected)
+	badge := a.platformBadge(page.Platform)
+	var pageText string
+	if width > 0 {
+		pageText = truncate(page.Name, width-lipgloss.Width(mark+badge)-1)
+	} else {
+		pageText = fmt.Sprintf("%s - %s", page.Name, page.Description)
+	}
+	return mark + style.Render(pageText) + " " + badge
+}
+
+// rowCache keeps the rendered rows of the pages list that aren't selected,
+// which on slow terminals over SSH saves rendering the whole list on every
+// cursor move
+type rowCache struct {
+	pages []types.IndexEntry
+	width int
+	theme Theme
+	rows  map[int]string
+}
+
+// valid reports whether the cached rows are those of pages at width
+func (c *rowCache) valid(pages []types.IndexEntry, width int, theme Theme) bool {
+	if c.rows == nil || len(c.pages) != len(pages) || c.width != width || c.theme != theme {
+		return false
+	}
+	// A new list is a new slice
+	return len(pages) == 0 || &c.pages[0] == &pages[0]
+}
+
+// listWindow returns the range of pages that fits on screen, scrolled so
+// the selected page stays visible
+func (a *App) listWindow() (int, int) {
+	rows := a.height - pagesChrome - (a.hintLines() - 1)
+	if a.height == 0 {
+		// The terminal's size isn't known yet; a screenful will do
+		rows = defaultListRows
+	}
+	if rows >= len(a.pages) {
+		return 0, len(a.pages)
+	}
+	if rows < 1 {
+		rows = 1
+	}
+
+	start := a.selectedIdx - rows/2
+	if start < 0 {
+		start = 0
+	}
+	if start+rows > len(a.pages) {
+		start = len(a.pages) - rows
+	}
+	return start, start + rows
+}
+
+// renderPreview renders a page summary with its examples in a bordered box
+func (a *App) renderPreview(page *types.Page, width int) string {
+	var content strings.Builder
+
+	title := lipgloss.NewStyle().
+		Foreground(a.theme.Accent).
+		Bold(true).
+		Render(page.Name)
+	content.WriteString(title + a.languageBadge(page) + "\n")
+
+	text := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	if page.Description != "" {
+		content.WriteString(text.Render(page.Description) + "\n")
+	}
+
+	command := lipgloss.NewStyle().Foreground(a.theme.Success)
+	for _, example := range page.Examples {
+		content.WriteString("\n" + text.Render(example.Description) + "\n")
+		content.WriteString(command.Render("  "+example.Command) + "\n")
+	}
+
+	// Border and padding take four columns
+	box := frame(lipgloss.NewStyle()).
+		BorderForeground(a.theme.Border).
+		Padding(0, 1).
+		Width(width - 4)
+
+	if a.height > 0 {
+		// Border takes two rows
+		rows := a.height - pagesChrome - (a.hintLines() - 1) - 2
+		if rows < 1 {
+			rows = 1
+		}
+		box = box.MaxHeight(rows + 2)
+	}
+
+	return box.Render(strings.TrimSuffix(content.String(), "\n"))
+}
+
+// renderFilter renders the page name filter line
+func (a *App) renderFilter() string {
+	style := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	switch {
+	case a.filtering:
+		return style.Render(fmt.Sprintf("Filter: %s_", a.filter))
+	case a.filter != "":
+		return style.Render(fmt.Sprintf("Filter: %s", a.filter))
+	default:
+		return style.Render("Press / to filter")
+	}
+}
+
+// handleFilterKey handles keyboard input while the filter line is focused.
+// The loaded pages are searched as the filter is typed.
+func (a *App) handleFilterKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	switch msg.Type {
+	case bubbletea.KeyCtrlC:
+		return a, bubbletea.Quit
+	case bubbletea.KeyEnter:
+		a.filtering = false
+	case bubbletea.KeyEsc:
+		a.filtering = false
+		a.filter = ""
+		return a, a.searchFilter()
+	case bubbletea.KeyBackspace:
+		if runes := []rune(a.filter); len(runes) > 0 {
+			a.filter = string(runes[:len(runes)-1])
+			return a, a.searchFilter()
+		}
+	case bubbletea.KeyUp:
+		if a.selectedIdx > 0 {
+			a.selectedIdx--
+		}
+	case bubbletea.KeyDown:
+		if a.selectedIdx < len(a.pages)-1 {
+			a.selectedIdx++
+		}
+	case bubbletea.KeyRunes, bubbletea.KeySpace:
+		a.filter += string(msg.Runes)
+		return a, a.searchFilter()
+	}
+	return a, nil
+}
+
+// applyFilter narrows the loaded pages to those matching the filter right
+// away, keeping the current selection if it still matches
+func (a *App) applyFilter() {
+	a.searchSeq++
+	a.cancelSearch()
+	pages, err := filterPages(context.Background(), a.cache, a.filter, a.platforms, a.allPages)
+	if err != nil {
+		a.status = fmt.Sprintf("Search failed: %v", err)
+		return
+	}
+	a.setPages(pages)
+}
+
+// truncate shortens s to at most width cells, marking the cut with "…"
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	ellipsis := Glyph("…", "...")
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes)+ellipsis) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + ellipsis
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// frameBudget is the longest a frame may take to render, 60 frames a second
+const frameBudget = 16 * time.Millisecond
+
+// stressApp returns the TUI listing n synthetic pages in a terminal of the
+// given size, 0 by 0 for one whose size isn't known yet
+func stressApp(tb testing.TB, n, width, height int) *App {
+	tb.Helper()
+	tb.Setenv("HOME", tb.TempDir())
+
+	cfg := config.DefaultConfig()
+	cfg.CacheDir = filepath.Join(tb.TempDir(), "cache")
+	a := New(cfg, cache.New(cfg.CacheDir, nil), nil)
+
+	platforms := []string{"common", "linux", "osx", "windows"}
+	pages := make([]types.IndexEntry, n)
+	for i := range pages {
+		pages[i] = types.IndexEntry{
+			Name:        fmt.Sprintf("command-%d", i),
+			Platform:    platforms[i%len(platforms)],
+			Description: "Does something useful with files and directories",
+		}
+	}
+	a.allPages, a.pages = pages, pages
+	a.state = StatePages
+	a.width, a.height = width, height
+	return a
+}
+
+var terminalSizes = []struct {
+	name          string
+	width, height int
+}{
+	{"split", 160, 50},
+	{"single", 70, 50},
+	{"unsized", 0, 0},
+}
+
+// TestPagesFrameTime checks that 10000 pages render within a frame, as the
+// list is scrolled and the cursor jumps around it
+func TestPagesFrameTime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test")
+	}
+	for _, size := range terminalSizes {
+		t.Run(size.name, func(t *testing.T) {
+			a := stressApp(t, 10000, size.width, size.height)
+			a.View()
+
+			const frames = 200
+			start := time.Now()
+			for i := 0; i < frames; i++ {
+				if i%2 == 0 {
+					a.selectedIdx = (a.selectedIdx + 1) % len(a.pages)
+				} else {
+					a.selectedIdx = (i * 7919) % len(a.pages)
+				}
+				a.View()
+			}
+			if perFrame := time.Since(start) / frames; perFrame > frameBudget {
+				t.Errorf("Expected frames under %s, took %s", frameBudget, perFrame)
+			}
+		})
+	}
+}
+
+// TestPagesListWindow checks that only the visible pages are rendered
+func TestPagesListWindow(t *testing.T) {
+	for _, size := range terminalSizes {
+		t.Run(size.name, func(t *testing.T) {
+			a := stressApp(t, 10000, size.width, size.height)
+			a.selectedIdx = 5000
+			start, end := a.listWindow()
+			if start > a.selectedIdx || a.selectedIdx >= end {
+				t.Errorf("Expected the selected page in the window, got %d-%d", start, end)
+			}
+			if end-start > defaultListRows {
+				t.Errorf("Expected at most a screenful of pages, got %d", end-start)
+			}
+		})
+	}
+}
+
+// BenchmarkPagesFrame measures rendering a frame of 10000 pages as the
+// cursor moves
+func BenchmarkPagesFrame(b *testing.B) {
+	for _, size := range terminalSizes {
+		b.Run(size.name, func(b *testing.B) {
+			a := stressApp(b, 10000, size.width, size.height)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				a.selectedIdx = i % len(a.pages)
+				a.View()
+			}
+		})
+	}
+}
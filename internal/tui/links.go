@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// link is a command in backticks in a description that has a page
+type link struct {
+	text string
+	page string
+	// url is where the page can be read upstream, for a hyperlink
+	url string
+}
+
+// loadLinks finds the commands in backticks in the selected page's
+// descriptions that have a page of their own, in the order they are shown
+func (a *App) loadLinks() {
+	a.links, a.linkIdx, a.linkFocus = nil, 0, false
+	page := a.selectedPage()
+	if page == nil {
+		return
+	}
+
+	texts := []string{page.Description}
+	for _, example := range page.Examples {
+		texts = append(texts, example.Description)
+	}
+	for _, text := range texts {
+		for _, span := range types.Spans(text) {
+			if !span.Code {
+				continue
+			}
+			// Only the command itself, not an alias that happens to match
+			// a word such as `h`
+			words := strings.Fields(span.Text)
+			name := a.cache.PageName(span.Text)
+			if name != "" && name != page.Name && strings.HasPrefix(name, words[0]) {
+				a.links = append(a.links, link{text: span.Text, page: name, url: a.pageURL(name)})
+			}
+		}
+	}
+}
+
+// handleLinkKey moves through the links in the page's descriptions and
+// follows one
+func (a *App) handleLinkKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	switch {
+	case msg.Type == bubbletea.KeyCtrlC:
+		return a, bubbletea.Quit
+	case key.Matches(msg, a.keys.Back, a.keys.Follow):
+		a.linkFocus = false
+	case key.Matches(msg, a.keys.Prev, a.keys.PrevField):
+		if a.linkIdx > 0 {
+			a.linkIdx--
+		}
+	case key.Matches(msg, a.keys.Next, a.keys.Field):
+		if a.linkIdx < len(a.links)-1 {
+			a.linkIdx++
+		}
+	case key.Matches(msg, a.keys.Select):
+		a.jumpTo(a.links[a.linkIdx].page)
+	}
+	return a, nil
+}
+
+// linkify renders a description in style with the commands that have a
+// page underlined, and the selected one highlighted while following links.
+// Descriptions must be rendered in the order loadLinks read them; next
+// counts the links rendered so far.
+func (a *App) linkify(text string, style lipgloss.Style, next *int) string {
+	var b strings.Builder
+	for _, span := range types.Spans(text) {
+		switch {
+		case !span.Code:
+			b.WriteString(style.Render(span.Text))
+		case *next < len(a.links) && a.links[*next].text == span.Text:
+			linkStyle := style.Copy().Underline(true)
+			text := span.Text
+			if a.linkFocus && *next == a.linkIdx {
+				linkStyle = linkStyle.Reverse(true)
+				text = selectInline(text)
+			}
+			b.WriteString(hyperlink(a.links[*next].url, linkStyle.Render(text)))
+			*next++
+		default:
+			b.WriteString(style.Render("`" + span.Text + "`"))
+		}
+	}
+	return b.String()
+}
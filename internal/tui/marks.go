@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/snippet"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// toggleMark marks or unmarks the current example for batch copy and save
+func (a *App) toggleMark() {
+	if a.currentExample() == nil {
+		return
+	}
+	if a.marked == nil {
+		a.marked = make(map[int]bool)
+	}
+	if a.marked[a.exampleIdx] {
+		delete(a.marked, a.exampleIdx)
+	} else {
+		a.marked[a.exampleIdx] = true
+	}
+}
+
+// markedExamples returns the indices of the marked examples of the
+// selected page in page order
+func (a *App) markedExamples() []int {
+	page := a.selectedPage()
+	if page == nil || len(a.marked) == 0 {
+		return nil
+	}
+	var indices []int
+	for i := range page.Examples {
+		if a.marked[i] {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// renderScript renders the examples at the given indices with the entered
+// values as a shell script that stops at the first failing command
+func (a *App) renderScript(page *types.Page, indices []int) string {
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\nset -e\n")
+	for _, i := range indices {
+		example := &page.Examples[i]
+		fmt.Fprintf(&script, "\n# %s: %s\n%s\n", page.Name, example.Description, example.Render(a.varsFor(i, example)))
+	}
+	return script.String()
+}
+
+// copyMarked copies the marked examples as a script block
+func (a *App) copyMarked() {
+	page := a.selectedPage()
+	examples := a.markedExamples()
+	if page == nil || len(examples) == 0 {
+		return
+	}
+	if err := CopyToClipboard(a.renderScript(page, examples)); err != nil {
+		a.status = err.Error()
+		return
+	}
+	a.status = fmt.Sprintf("Copied %d commands as a script", len(examples))
+}
+
+// saveMarked saves the marked examples as one snippet that runs them in
+// order, stopping at the first failure
+func (a *App) saveMarked(name string) {
+	page := a.selectedPage()
+	examples := a.markedExamples()
+	if page == nil || len(examples) == 0 {
+		return
+	}
+
+	// Each step keeps its own values, as examples may fill placeholders of
+	// the same name differently
+	steps := make([]snippet.Step, len(examples))
+	templates := make([]string, len(examples))
+	commands := make([]string, len(examples))
+	for n, i := range examples {
+		example := &page.Examples[i]
+		vars := a.varsFor(i, example)
+		steps[n] = snippet.Step{Template: example.Command, Vars: vars}
+		templates[n] = example.Command
+		commands[n] = example.Render(vars)
+	}
+
+	s := &snippet.Snippet{
+		Name:        name,
+		Page:        page.Name,
+		Platform:    page.Platform,
+		Description: fmt.Sprintf("%d steps from %s", len(examples), page.Name),
+		Template:    strings.Join(templates, snippet.StepSeparator),
+		Command:     strings.Join(commands, snippet.StepSeparator),
+		Steps:       steps,
+	}
+	a.storeSnippet(s, fmt.Sprintf("Saved %d commands as snippet %s", len(examples), name))
+}
@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// memoryDescriptions say what each placeholder memory policy does, "" being
+// the default
+var memoryDescriptions = map[string]string{
+	"":                    "last value offered when asked",
+	config.MemoryRemember: "last value filled in without asking",
+	config.MemoryAsk:      "asked for every time, even by tldrpp last",
+	config.MemoryForget:   "values never stored",
+}
+
+// cycleMemory moves the focused placeholder to the next memory policy and
+// saves it to the config. The policy is set for the placeholder's name, so
+// it applies to every example using the name.
+func (a *App) cycleMemory() {
+	example := a.currentExample()
+	if example == nil || a.fieldIdx >= len(example.Placeholders) {
+		return
+	}
+	placeholder := example.Placeholders[a.fieldIdx]
+	if placeholder.Secret() {
+		a.status = "Secret values are never remembered"
+		return
+	}
+
+	name := strings.ToLower(placeholder.Name)
+	policies := append([]string{""}, config.MemoryPolicies...)
+	next := policies[0]
+	for i, policy := range policies {
+		if policy == a.config.PlaceholderMemory[name] {
+			next = policies[(i+1)%len(policies)]
+			break
+		}
+	}
+
+	if a.config.PlaceholderMemory == nil {
+		a.config.PlaceholderMemory = make(map[string]string)
+	}
+	if next == "" {
+		delete(a.config.PlaceholderMemory, name)
+	} else {
+		a.config.PlaceholderMemory[name] = next
+	}
+	effective := a.config.MemoryPolicy(placeholder.Name, placeholder.Type)
+	if err := config.Set("placeholder_memory."+name, next); err != nil {
+		a.status = fmt.Sprintf("Failed to save memory policy: %v", err)
+		return
+	}
+	a.status = fmt.Sprintf("%s: %s", placeholder.Name, memoryDescriptions[effective])
+}
+
+// memoryLabel names the memory policy of placeholder, if it has one
+func (a *App) memoryLabel(placeholder types.Placeholder) string {
+	if placeholder.Secret() {
+		return ""
+	}
+	if policy := a.config.MemoryPolicy(placeholder.Name, placeholder.Type); policy != "" {
+		return " [" + policy + "]"
+	}
+	return ""
+}
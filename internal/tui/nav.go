@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// screen is a place in the TUI that Esc returns to: the state along with
+// the search and page it showed
+type screen struct {
+	state       AppState
+	searchQuery string
+	filter      string
+	// page is the selected page as platform/name
+	page       string
+	exampleIdx int
+	// label names the screen in the breadcrumb
+	label string
+}
+
+// here returns the current screen
+func (a *App) here() screen {
+	return screen{
+		state:       a.state,
+		searchQuery: a.searchQuery,
+		filter:      a.filter,
+		page:        a.selectedKey(),
+		exampleIdx:  a.exampleIdx,
+		label:       a.screenLabel(),
+	}
+}
+
+// selectedKey returns the selected page as platform/name, or "" if there
+// is none
+func (a *App) selectedKey() string {
+	if a.selectedIdx >= len(a.pages) {
+		return ""
+	}
+	return a.pages[a.selectedIdx].Platform + "/" + a.pages[a.selectedIdx].Name
+}
+
+// navigate shows state, remembering the current screen for Esc to return to
+func (a *App) navigate(state AppState) {
+	a.navigateFrom(a.here(), state)
+}
+
+// navigateFrom shows state, remembering from for Esc to return to. Used
+// when the current screen changed before the new one is shown, e.g. when
+// following a link searches for its page.
+func (a *App) navigateFrom(from screen, state AppState) {
+	a.history = append(a.history, from)
+	a.state = state
+}
+
+// back returns to the previous screen, restoring its search and page, or
+// to the search screen when there is none
+func (a *App) back() {
+	n := len(a.history)
+	if n == 0 {
+		a.state = StateSearch
+		return
+	}
+	prev := a.history[n-1]
+	a.history = a.history[:n-1]
+
+	if prev.searchQuery != a.searchQuery || prev.filter != a.filter {
+		a.searchQuery, a.filter = prev.searchQuery, prev.filter
+		if err := a.loadPages(); err != nil {
+			a.status = fmt.Sprintf("Failed to load pages: %v", err)
+		}
+	}
+	pageChanged := prev.page != a.selectedKey()
+	for i, page := range a.pages {
+		if page.Platform+"/"+page.Name == prev.page {
+			a.selectedIdx = i
+			break
+		}
+	}
+
+	a.state = prev.state
+	a.exampleIdx = prev.exampleIdx
+	if pageChanged && (a.state == StateExamples || a.state == StateEdit) {
+		a.marked = nil
+		a.resetValues()
+		a.loadRelated()
+		a.loadLinks()
+		a.loadInstall()
+	}
+}
+
+// backTo goes back to the last visit of state, or straight to it if it
+// wasn't visited
+func (a *App) backTo(state AppState) {
+	for a.state != state && len(a.history) > 0 {
+		a.back()
+	}
+	a.state = state
+}
+
+// screenLabel names the current screen in the breadcrumb
+func (a *App) screenLabel() string {
+	switch a.state {
+	case StatePages:
+		return "pages"
+	case StateExamples:
+		if a.selectedIdx < len(a.pages) {
+			return a.pages[a.selectedIdx].Name
+		}
+		return "page"
+	case StateEdit:
+		if example := a.currentExample(); example != nil && example.Description != "" {
+			return truncate(example.Description, 30) + " > edit"
+		}
+		return "edit"
+	case StateHelp:
+		return "help"
+	case StateSnippets:
+		return "snippets"
+	case StateStats:
+		return "stats"
+	case StateEditPage:
+		return "edit page"
+	case StateOutput:
+		return "output"
+	default:
+		return "search"
+	}
+}
+
+// renderBreadcrumb renders the way to the current screen, e.g. "search >
+// pages > tar > Extract an archive > edit", or "" on the start screen. The
+// oldest screens give way when it is too wide.
+func (a *App) renderBreadcrumb() string {
+	if a.state == StateSearch && len(a.history) == 0 {
+		return ""
+	}
+	labels := make([]string, 0, len(a.history)+1)
+	for _, s := range a.history {
+		labels = append(labels, s.label)
+	}
+	labels = append(labels, a.screenLabel())
+
+	crumbs := strings.Join(labels, " > ")
+	for a.width > 0 && lipgloss.Width(crumbs) > a.width && len(labels) > 1 {
+		labels = labels[1:]
+		crumbs = Glyph("…", "...") + " > " + strings.Join(labels, " > ")
+	}
+	return lipgloss.NewStyle().Foreground(a.theme.Foreground).Faint(true).Render(crumbs) + "\n"
+}
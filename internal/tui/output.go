@@ -0,0 +1,315 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/diff"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/terminal"
+	"github.com/makalin/tldrpp/internal/tui/components"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// maxOutputBytes is how much of a command's output is kept
+const maxOutputBytes = 256 << 10
+
+// Runner runs a command from the TUI once it has given up the terminal,
+// recording it like any other, and copies its output to output if not nil.
+// Commands are confirmed in the TUI when a checker is set, and otherwise by
+// the runner. It reports whether the command ran or was cancelled.
+type Runner func(execution history.Execution, output io.Writer) (bool, error)
+
+// SetRunner has commands run from a page run while the TUI waits, so that
+// their output can be browsed afterwards. Without a runner the TUI exits
+// and leaves the command to Rerun.
+func (a *App) SetRunner(run Runner) {
+	a.runner = run
+}
+
+// commandOutput is what a command run from the TUI printed
+type commandOutput struct {
+	// command is the command run, with secrets redacted
+	command string
+	output  string
+	// truncated is set when the output was longer than maxOutputBytes
+	truncated bool
+	err       error
+	time      time.Time
+}
+
+// outputBuffer keeps the first maxOutputBytes written to it
+type outputBuffer struct {
+	b         strings.Builder
+	truncated bool
+}
+
+func (o *outputBuffer) Write(p []byte) (int, error) {
+	if room := maxOutputBytes - o.b.Len(); len(p) > room {
+		o.b.Write(p[:room])
+		o.truncated = true
+	} else {
+		o.b.Write(p)
+	}
+	return len(p), nil
+}
+
+// commandRun runs a command in the terminal for the TUI and waits for
+// Enter, so its output can be read before the TUI comes back
+type commandRun struct {
+	run       Runner
+	execution history.Execution
+	output    *outputBuffer
+	ran       bool
+	stdin     io.Reader
+}
+
+func (c *commandRun) Run() error {
+	var output io.Writer
+	if c.output != nil {
+		output = c.output
+	}
+	ran, err := c.run(c.execution, output)
+	c.ran = ran
+	if err != nil {
+		fmt.Printf("\nError: %v\n", err)
+	}
+	waitForEnter(c.stdin)
+	return err
+}
+
+func (c *commandRun) SetStdin(r io.Reader) { c.stdin = r }
+func (c *commandRun) SetStdout(io.Writer)  {}
+func (c *commandRun) SetStderr(io.Writer)  {}
+
+// waitForEnter leaves what a command printed on screen until Enter is
+// pressed
+func waitForEnter(stdin io.Reader) {
+	fmt.Print("\nPress Enter to return to tldr++")
+	bufio.NewReader(stdin).ReadString('\n')
+}
+
+// commandDoneMsg reports that a command run from the TUI finished
+type commandDoneMsg struct {
+	ran bool
+	// captured is set when the output was kept for the output history
+	captured bool
+	output   commandOutput
+}
+
+// runInTerminal runs execution with the runner once the checker's
+// confirmations were answered yes in a dialog, in the directory chosen for
+// commands if any. Its output is captured for
+// the output history only when capture is set and output history is on:
+// capturing pipes the command's output, so it no longer writes to a
+// terminal and may print differently or not at all.
+func (a *App) runInTerminal(execution history.Execution, capture bool) (bubbletea.Model, bubbletea.Cmd) {
+	if a.workDir != "" {
+		execution.Dir = a.workDir
+	}
+	return a, a.confirmRun(execution, func() bubbletea.Cmd {
+		return a.execInTerminal(execution, capture)
+	})
+}
+
+// execInTerminal hands the terminal over to the runner to run execution
+func (a *App) execInTerminal(execution history.Execution, capture bool) bubbletea.Cmd {
+	c := &commandRun{run: a.runner, execution: execution, stdin: os.Stdin}
+	if capture && a.config.OutputHistory > 0 {
+		c.output = &outputBuffer{}
+	}
+	command, _, _ := types.Redact(execution.Command, execution.Template, execution.Vars)
+	return bubbletea.Exec(c, func(err error) bubbletea.Msg {
+		done := commandDoneMsg{ran: c.ran, captured: c.output != nil, output: commandOutput{command: command, err: err, time: a.clock.Now()}}
+		if c.output != nil {
+			// Kept as text: a command may color its output, and on
+			// Windows it writes to a pseudo console
+			done.output.output = terminal.StripEscapes(c.output.b.String())
+			done.output.truncated = c.output.truncated
+		}
+		return done
+	})
+}
+
+// finishCommand keeps the output of a command run from the TUI and shows it
+func (a *App) finishCommand(msg commandDoneMsg) (bubbletea.Model, bubbletea.Cmd) {
+	if !msg.ran {
+		if msg.output.err != nil {
+			a.status = fmt.Sprintf("Failed to run command: %v", msg.output.err)
+		} else {
+			a.status = "Command cancelled"
+		}
+		return a, nil
+	}
+	if msg.output.err != nil {
+		a.status = fmt.Sprintf("Command failed: %v", msg.output.err)
+	} else {
+		a.status = "Command finished"
+	}
+	if !msg.captured {
+		return a, nil
+	}
+
+	a.outputs = append(a.outputs, msg.output)
+	if len(a.outputs) > a.config.OutputHistory {
+		a.outputs = a.outputs[len(a.outputs)-a.config.OutputHistory:]
+	}
+	a.outputIdx = len(a.outputs) - 1
+	a.comparing = false
+	a.navigate(StateOutput)
+	return a, nil
+}
+
+// openOutputs shows the output history
+func (a *App) openOutputs() {
+	if len(a.outputs) == 0 {
+		a.status = "No command output yet: run a command with " + a.keys.RunCapture.Help().Key
+		return
+	}
+	a.outputIdx = len(a.outputs) - 1
+	a.comparing = false
+	a.navigate(StateOutput)
+}
+
+// copyOutput copies the selected output to the clipboard
+func (a *App) copyOutput() {
+	if a.outputIdx >= len(a.outputs) {
+		return
+	}
+	if !a.config.Clipboard {
+		a.status = "Clipboard is disabled in the config"
+		return
+	}
+	if err := CopyToClipboard(a.outputs[a.outputIdx].output); err != nil {
+		a.status = err.Error()
+		return
+	}
+	a.status = "Copied output to clipboard"
+}
+
+// handleOutputPathKey handles keys while a file name to save an output to
+// is being typed
+func (a *App) handleOutputPathKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	if msg.Type == bubbletea.KeyCtrlC {
+		return a, bubbletea.Quit
+	}
+	input, _ := a.outputPath.Update(msg)
+	a.outputPath = &input
+	switch input.State {
+	case components.InputSubmitted:
+		a.outputPath = nil
+		a.saveOutput(input.Value)
+	case components.InputCancelled:
+		a.outputPath = nil
+	}
+	return a, nil
+}
+
+// saveOutput writes the selected output to path, a leading ~ expanded
+func (a *App) saveOutput(path string) {
+	if path == "" || a.outputIdx >= len(a.outputs) {
+		return
+	}
+	path = config.ExpandHome(path)
+	if err := os.WriteFile(path, []byte(a.outputs[a.outputIdx].output), 0644); err != nil {
+		a.status = fmt.Sprintf("Failed to save output: %v", err)
+		return
+	}
+	a.status = fmt.Sprintf("Saved output to %s", config.AbbreviateHome(path))
+}
+
+// renderOutputs renders the output history: the commands run, and the
+// output of the selected one or how it differs from the one before
+func (a *App) renderOutputs() string {
+	var content strings.Builder
+	title := lipgloss.NewStyle().Foreground(a.theme.Accent).Bold(true)
+	text := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	faint := text.Copy().Faint(true)
+
+	content.WriteString(title.Render(fmt.Sprintf("Output history (%d)", len(a.outputs))) + "\n\n")
+	for i, out := range a.outputs {
+		result := "ok"
+		if out.err != nil {
+			result = out.err.Error()
+		}
+		style := text
+		if i == a.outputIdx {
+			style = style.Copy().Background(a.theme.Highlight).Foreground(a.theme.Background)
+		}
+		line := fmt.Sprintf("%s  %s  (%s)", out.time.Format("15:04:05"), out.command, result)
+		content.WriteString(selectMark(i == a.outputIdx) + style.Render(line) + "\n")
+	}
+	content.WriteString("\n")
+
+	out := a.outputs[a.outputIdx]
+	var lines []string
+	if a.comparing && a.outputIdx > 0 {
+		previous := a.outputs[a.outputIdx-1]
+		content.WriteString(title.Render("Changes since "+previous.command) + "\n")
+		lines = a.outputDiff(previous.output, out.output)
+	} else {
+		content.WriteString(title.Render("Output") + "\n")
+		output := strings.TrimSuffix(out.output, "\n")
+		if output == "" {
+			output = faint.Render("(no output)")
+		}
+		for _, line := range strings.Split(output, "\n") {
+			lines = append(lines, text.Render(line))
+		}
+		if out.truncated {
+			lines = append(lines, faint.Render(fmt.Sprintf("(only the first %d KiB are kept)", maxOutputBytes>>10)))
+		}
+	}
+
+	// Show the end of long outputs, where the result usually is
+	if a.height > 0 {
+		rows := a.height - len(a.outputs) - 8 - a.hintLines()
+		if rows < 3 {
+			rows = 3
+		}
+		if len(lines) > rows {
+			hidden := len(lines) - rows + 1
+			lines = append([]string{faint.Render(fmt.Sprintf("%s %d lines above", Glyph("…", "..."), hidden))}, lines[hidden:]...)
+		}
+	}
+	content.WriteString(strings.Join(lines, "\n") + "\n\n")
+
+	if a.outputPath != nil {
+		content.WriteString(a.outputPath.View() + "\n")
+	}
+	content.WriteString(a.renderHints())
+	return content.String()
+}
+
+// outputDiff renders the lines of two outputs, marking those only in one
+func (a *App) outputDiff(old, new string) []string {
+	text := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	removed := lipgloss.NewStyle().Foreground(a.theme.Error)
+	added := lipgloss.NewStyle().Foreground(a.theme.Success)
+
+	ops := diff.Lines(old, new)
+	if !diff.Changed(ops) {
+		return []string{text.Render("Same output")}
+	}
+	var lines []string
+	for _, op := range ops {
+		style, prefix := text, "  "
+		switch op.Kind {
+		case diff.Delete:
+			style, prefix = removed, "- "
+		case diff.Insert:
+			style, prefix = added, "+ "
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(op.Text, "\n"), "\n") {
+			lines = append(lines, style.Render(prefix+line))
+		}
+	}
+	return lines
+}
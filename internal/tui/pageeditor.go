@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textarea"
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// pageEditor edits a custom page inside the TUI, for fixes too small to
+// be worth opening an external editor
+type pageEditor struct {
+	path     string
+	area     textarea.Model
+	problems []types.LintProblem
+	// force is set once saving was refused for lint problems; saving
+	// again writes the page anyway
+	force bool
+}
+
+// openPageEditor opens the selected custom page in the built-in editor
+func (a *App) openPageEditor() (bubbletea.Model, bubbletea.Cmd) {
+	if len(a.pages) == 0 || a.selectedIdx >= len(a.pages) {
+		return a, nil
+	}
+	entry := a.pages[a.selectedIdx]
+	path := a.cache.CustomPath(entry)
+	if path == "" {
+		a.status = fmt.Sprintf("%s is a downloaded page; write your own with 'tldrpp new %s'", entry.Name, entry.Name)
+		return a, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		a.status = fmt.Sprintf("Failed to read page: %v", err)
+		return a, nil
+	}
+
+	area := textarea.New()
+	area.ShowLineNumbers = true
+	area.CharLimit = 0
+	area.SetWidth(max(a.width-4, 40))
+	area.SetHeight(max(a.height-10, 10))
+	area.SetValue(string(content))
+	if accessible {
+		// A blinking cursor redraws the screen twice a second
+		area.Cursor.SetMode(cursor.CursorStatic)
+	}
+	cmd := area.Focus()
+
+	a.editor = &pageEditor{path: path, area: area}
+	a.navigate(StateEditPage)
+	return a, cmd
+}
+
+// handleEditorKey handles keys in the built-in page editor
+func (a *App) handleEditorKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	switch msg.Type {
+	case bubbletea.KeyCtrlC:
+		return a, bubbletea.Quit
+	case bubbletea.KeyEsc:
+		a.back()
+		a.editor = nil
+		a.status = "Discarded changes"
+		return a, nil
+	case bubbletea.KeyCtrlS:
+		a.savePageEditor()
+		return a, nil
+	}
+
+	a.editor.force = false
+	var cmd bubbletea.Cmd
+	a.editor.area, cmd = a.editor.area.Update(msg)
+	return a, cmd
+}
+
+// savePageEditor lints the edited page and writes it if it is clean, or if
+// saving is repeated after problems were shown
+func (a *App) savePageEditor() {
+	content := a.editor.area.Value()
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	a.editor.problems = types.Lint(content)
+	if len(a.editor.problems) > 0 && !a.editor.force {
+		a.editor.force = true
+		a.status = fmt.Sprintf("%d lint problem(s); Ctrl+S again to save anyway", len(a.editor.problems))
+		return
+	}
+
+	if err := os.WriteFile(a.editor.path, []byte(content), 0644); err != nil {
+		a.status = fmt.Sprintf("Failed to save page: %v", err)
+		return
+	}
+	a.back()
+	a.editor = nil
+	a.finishEdit(nil)
+}
+
+// renderPageEditor renders the built-in page editor with the lint problems
+// found on the last save
+func (a *App) renderPageEditor() string {
+	var content strings.Builder
+
+	header := lipgloss.NewStyle().
+		Foreground(a.theme.Accent).
+		Bold(true).
+		Render(fmt.Sprintf("Editing %s", a.editor.path))
+	content.WriteString(header + "\n\n")
+	content.WriteString(a.editor.area.View() + "\n")
+
+	if len(a.editor.problems) > 0 {
+		problem := lipgloss.NewStyle().Foreground(a.theme.Warning)
+		content.WriteString("\n")
+		for _, p := range a.editor.problems {
+			content.WriteString(problem.Render("  "+p.String()) + "\n")
+		}
+	}
+
+	content.WriteString("\n" + a.renderHints())
+
+	return content.String()
+}
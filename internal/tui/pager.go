@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/shellwords"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// defaultPager is used when neither the config nor $PAGER name one
+const defaultPager = "less -R"
+
+// pagerClosedMsg reports that the pager exited
+type pagerClosedMsg struct {
+	err error
+}
+
+// pagerCommand returns the argv of the configured pager, falling back to
+// $PAGER and then less
+func (a *App) pagerCommand() ([]string, error) {
+	pager := strings.TrimSpace(a.config.Pager)
+	if pager == "" {
+		pager = strings.TrimSpace(os.Getenv("PAGER"))
+	}
+	if pager == "" {
+		pager = defaultPager
+	}
+
+	argv, err := shellwords.Split(pager)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pager %q: %w", pager, err)
+	}
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("invalid pager %q", pager)
+	}
+	return argv, nil
+}
+
+// openInPager shows the selected page in the pager, rendered with colours
+// or as the raw markdown. The TUI gives the terminal to the pager until it
+// exits.
+func (a *App) openInPager(raw bool) (bubbletea.Model, bubbletea.Cmd) {
+	page := a.selectedPage()
+	if page == nil {
+		return a, nil
+	}
+	argv, err := a.pagerCommand()
+	if err != nil {
+		a.status = err.Error()
+		return a, nil
+	}
+
+	text := page.RawContent
+	if !raw {
+		text = a.pageText(page)
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return a, bubbletea.ExecProcess(cmd, func(err error) bubbletea.Msg {
+		return pagerClosedMsg{err: err}
+	})
+}
+
+// pageText renders a page for reading, like a tldr client does
+func (a *App) pageText(page *types.Page) string {
+	title := lipgloss.NewStyle().Foreground(a.theme.Accent).Bold(true)
+	description := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	example := lipgloss.NewStyle().Foreground(a.theme.Success)
+	command := lipgloss.NewStyle().Foreground(a.theme.Foreground).Bold(true)
+	placeholder := lipgloss.NewStyle().Foreground(a.theme.Warning)
+
+	var text strings.Builder
+	text.WriteString("\n  " + title.Render(page.Name) + "\n\n")
+	if page.Description != "" {
+		text.WriteString("  " + description.Render(page.Description) + "\n")
+	}
+	if page.MoreInfoURL != "" {
+		text.WriteString("  " + description.Render("More information: "+page.MoreInfoURL) + "\n")
+	}
+
+	for _, e := range page.Examples {
+		text.WriteString("\n  " + example.Render("- "+e.Description+":") + "\n")
+
+		// Style the placeholders apart from the rest of the command
+		text.WriteString("    ")
+		rest := e.Command
+		for {
+			start := strings.Index(rest, "{{")
+			end := strings.Index(rest, "}}")
+			if start < 0 || end < start {
+				break
+			}
+			text.WriteString(command.Render(rest[:start]) + placeholder.Render(rest[start:end+2]))
+			rest = rest[end+2:]
+		}
+		text.WriteString(command.Render(rest) + "\n")
+	}
+	text.WriteString("\n")
+
+	return text.String()
+}
@@ -0,0 +1,447 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/export"
+	"github.com/makalin/tldrpp/internal/plugin"
+	"github.com/makalin/tldrpp/internal/tui/components"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// paletteRows is how many matching actions the palette lists at once
+const paletteRows = 10
+
+// action is an entry of the command palette
+type action struct {
+	name string
+	// key is the binding running the action outside the palette, if any
+	key key.Binding
+	// when reports whether the action applies to the current screen
+	when func(a *App) bool
+	run  func(a *App) (bubbletea.Model, bubbletea.Cmd)
+}
+
+// palette is the open command palette: the typed query and the actions
+// matching it, best match first
+type palette struct {
+	query   components.Input
+	matches []action
+	list    components.List
+}
+
+// paletteKeys move through the palette's actions; letters are typed into
+// the query
+var paletteKeys = components.ListKeys{
+	Up:   key.NewBinding(key.WithKeys("up", "ctrl+k")),
+	Down: key.NewBinding(key.WithKeys("down", "ctrl+j", "ctrl+n")),
+}
+
+// settingsEditedMsg reports that the editor opened on the config file exited
+type settingsEditedMsg struct {
+	err error
+}
+
+// pluginDoneMsg reports that a plugin run from the palette finished
+type pluginDoneMsg struct {
+	name string
+	err  error
+}
+
+// in returns a condition that holds on the given screens
+func in(states ...AppState) func(a *App) bool {
+	return func(a *App) bool {
+		for _, state := range states {
+			if a.state == state {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// always is the condition of actions that apply everywhere
+func always(a *App) bool {
+	return true
+}
+
+// press returns an action body pressing the first key of b, so that the
+// palette does exactly what the key does. handleKeyPress matches keys by
+// name, so the key is sent as its name.
+func press(b key.Binding) func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+	return func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+		return a.handleKeyPress(bubbletea.KeyMsg{Type: bubbletea.KeyRunes, Runes: []rune(b.Keys()[0])})
+	}
+}
+
+// paletteActions lists every action the palette offers, in the order shown
+// for an empty query, with their keys in k
+func paletteActions(k keyMap) []action {
+	onPage := func(a *App) bool {
+		return (a.state == StatePages || a.state == StateExamples || a.state == StateEdit) && a.selectedPage() != nil
+	}
+	onExample := func(a *App) bool {
+		return (a.state == StateExamples || a.state == StateEdit) && a.currentExample() != nil
+	}
+
+	actions := []action{
+		{"Search pages", k.Select, in(StateSearch), press(k.Select)},
+		{"Go back", k.Back, func(a *App) bool { return a.state != StateSearch || len(a.history) > 0 }, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			a.back()
+			return a, nil
+		}},
+		{"Show help", k.Help, in(StateSearch, StatePages, StateExamples, StateEdit, StateSnippets, StateStats), press(k.Help)},
+		{"Refresh cache", key.Binding{}, func(a *App) bool { return !a.refreshing }, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			return a.refreshCache()
+		}},
+		{"Cancel cache refresh", k.Cancel, func(a *App) bool { return a.refreshing }, press(k.Cancel)},
+		{"Browse snippets", k.Snippets, in(StateSearch, StatePages), press(k.Snippets)},
+		{"Show usage stats", k.Stats, in(StateSearch, StatePages), press(k.Stats)},
+		{"Surprise me: open a random page", k.Random, in(StateSearch, StatePages), press(k.Random)},
+		{"Open the tip of the day", k.Tip, func(a *App) bool { return a.state == StateSearch && a.tip != nil }, press(k.Tip)},
+		{"List new and changed pages", k.News, func(a *App) bool { return a.state == StateSearch && a.changes != nil }, press(k.News)},
+		{"Filter pages by name", k.Filter, in(StatePages), press(k.Filter)},
+		{"Toggle page preview pane", k.Preview, in(StatePages), press(k.Preview)},
+		{"Toggle all platforms", k.AllPlatforms, in(StateSearch, StatePages), func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			a.toggleAllPlatforms()
+			return a, nil
+		}},
+	}
+	for i := 1; i <= len(platformKeys); i++ {
+		num := fmt.Sprint(i)
+		binding := key.NewBinding(key.WithKeys(num), key.WithHelp(num, ""))
+		actions = append(actions, action{"Toggle platform: " + platformKeys[num], binding, in(StateSearch, StatePages), func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			a.togglePlatform(num)
+			return a, nil
+		}})
+	}
+	actions = append(actions, []action{
+		{"Edit placeholders", k.Field, onExample, press(k.Field)},
+		{"Run command", k.Run, onExample, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			a.recordExample()
+			return a.executeCommand(false)
+		}},
+		{"Run command and keep its output", k.RunCapture, onExample, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			a.recordExample()
+			return a.executeCommand(true)
+		}},
+		{"Run command as root with sudo", k.Sudo, onExample, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			a.recordExample()
+			return a.executeWithSudo()
+		}},
+		{"Choose the directory commands run in", k.WorkDir, onExample, press(k.WorkDir)},
+		{"Undo placeholder change", k.Undo, in(StateEdit), press(k.Undo)},
+		{"Redo placeholder change", k.Redo, in(StateEdit), func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			a.redoChange()
+			return a, nil
+		}},
+		{"Reset placeholders to defaults", k.Reset, in(StateEdit), press(k.Reset)},
+		{"Change how the placeholder's values are remembered", k.Memory, in(StateEdit), press(k.Memory)},
+		{"Copy command", k.Copy, onExample, press(k.Copy)},
+		{"Copy command template", k.CopyTemplate, onExample, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			return a.copyAs(copyTemplate)
+		}},
+		{"Copy as markdown", k.CopyMarkdown, onExample, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			return a.copyAs(copyMarkdown)
+		}},
+		{"Paste command", k.Paste, onExample, press(k.Paste)},
+		{"Show command as a QR code", k.QR, onExample, press(k.QR)},
+		{"Present the example on the whole screen", k.Present, onExample, press(k.Present)},
+		{"Run the last command again", k.Last, in(StateSearch, StatePages, StateExamples, StateOutput), func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			return a.runLast()
+		}},
+		{"Show output history", k.Outputs, func(a *App) bool { return a.state != StateOutput && len(a.outputs) > 0 }, press(k.Outputs)},
+		{"Copy command output", k.Copy, in(StateOutput), press(k.Copy)},
+		{"Save command output to a file", k.SaveOutput, in(StateOutput), press(k.SaveOutput)},
+		{"Compare output with the previous run", k.Compare, func(a *App) bool { return a.state == StateOutput && a.outputIdx > 0 }, press(k.Compare)},
+		{"Save example as a snippet", k.Save, onExample, press(k.Save)},
+		{"Follow a command mentioned in a description", k.Follow, func(a *App) bool { return a.state == StateExamples && len(a.links) > 0 }, press(k.Follow)},
+		{"Install the page's command", k.Install, func(a *App) bool { return a.state == StateExamples && a.installCommand != "" }, press(k.Install)},
+		{"Copy the command installing the page's command", k.CopyInstall, func(a *App) bool { return a.state == StateExamples && a.installCommand != "" }, press(k.CopyInstall)},
+		{"Jump to a related page", k.Related, func(a *App) bool { return a.state == StateExamples && len(a.related) > 0 }, press(k.Related)},
+		{"Edit page in $EDITOR", k.EditPage, in(StatePages, StateExamples), press(k.EditPage)},
+		{"Edit page in the TUI", k.EditInTUI, in(StatePages, StateExamples), press(k.EditInTUI)},
+		{"Open page in the pager", k.Pager, in(StateExamples), press(k.Pager)},
+		{"Open raw page in the pager", k.RawPager, in(StateExamples), press(k.RawPager)},
+		{"Open more information in browser", k.Browser, in(StateExamples), press(k.Browser)},
+		{"Export page as a man page", key.Binding{}, onPage, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			a.exportPage(".1", export.Man)
+			return a, nil
+		}},
+		{"Export page as a PDF", key.Binding{}, onPage, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			a.exportPage(".pdf", func(w io.Writer, page *types.Page) error {
+				return export.PDF(w, []*types.Page{page}, export.Layouts[0])
+			})
+			return a, nil
+		}},
+	}...)
+	for _, theme := range []string{"dark", "light", "solarized"} {
+		theme := theme
+		actions = append(actions, action{"Switch theme: " + theme, key.Binding{}, func(a *App) bool { return a.config.Theme != theme }, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			a.switchTheme(theme)
+			return a, nil
+		}})
+	}
+	actions = append(actions, []action{
+		{"Open settings", key.Binding{}, always, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			return a.openSettings()
+		}},
+		{"Run plugin: submit (validate example)", key.Binding{}, func(a *App) bool { return onExample(a) && !a.pick }, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			return a.runPlugin("validate")
+		}},
+		{"Run plugin: submit (prepare a submission)", key.Binding{}, func(a *App) bool { return onExample(a) && !a.pick }, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			return a.runPlugin("init")
+		}},
+		{"Quit", k.Quit, always, func(a *App) (bubbletea.Model, bubbletea.Cmd) {
+			return a, bubbletea.Quit
+		}},
+	}...)
+	return actions
+}
+
+// openPalette shows the command palette with every action that applies
+func (a *App) openPalette() {
+	styles := a.theme.styles()
+	list := components.NewList(nil, paletteKeys, styles)
+	list.Height, list.Empty = paletteRows, "No matching action"
+	a.palette = &palette{query: components.NewInput("> ", "", "", styles), list: list}
+	a.filterPalette()
+}
+
+// filterPalette lists the actions that apply and fuzzily match the query,
+// best match first
+func (a *App) filterPalette() {
+	type match struct {
+		action action
+		score  int
+	}
+	var matches []match
+	for _, act := range paletteActions(a.keys) {
+		if !act.when(a) {
+			continue
+		}
+		if score, ok := fuzzyScore(a.palette.query.Value, act.name); ok {
+			matches = append(matches, match{act, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	a.palette.matches = a.palette.matches[:0]
+	items := make([]components.Item, len(matches))
+	for i, m := range matches {
+		a.palette.matches = append(a.palette.matches, m.action)
+		items[i] = components.Item{Text: m.action.name, Hint: m.action.key.Help().Key}
+	}
+	a.palette.list.SetItems(items)
+	a.palette.list.Select(0)
+}
+
+// fuzzyScore reports whether the letters of query appear in text in order,
+// ignoring case and spaces, and scores the match: letters in a row and at
+// the start of words score higher
+func fuzzyScore(query, text string) (int, bool) {
+	q := []rune(strings.ToLower(strings.ReplaceAll(query, " ", "")))
+	t := []rune(strings.ToLower(text))
+	score, qi, last := 0, 0, -2
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if t[ti] != q[qi] {
+			continue
+		}
+		score++
+		if ti == last+1 {
+			score += 2
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) {
+			score += 3
+		}
+		last = ti
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+// handlePaletteKey handles keys while the command palette is open
+func (a *App) handlePaletteKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	p := a.palette
+	switch {
+	case msg.Type == bubbletea.KeyCtrlC:
+		return a, bubbletea.Quit
+	case msg.Type == bubbletea.KeyEsc, msg.Type == bubbletea.KeyCtrlP:
+		a.palette = nil
+	case msg.Type == bubbletea.KeyEnter:
+		a.palette = nil
+		if p.list.Index < len(p.matches) {
+			return p.matches[p.list.Index].run(a)
+		}
+	case key.Matches(msg, paletteKeys.Up, paletteKeys.Down):
+		p.list, _ = p.list.Update(msg)
+	default:
+		query := p.query.Value
+		p.query, _ = p.query.Update(msg)
+		if p.query.Value != query {
+			a.filterPalette()
+		}
+	}
+	return a, nil
+}
+
+// renderPalette renders the command palette box
+func (a *App) renderPalette() string {
+	p := a.palette
+	var content strings.Builder
+
+	title := lipgloss.NewStyle().Foreground(a.theme.Accent).Bold(true).Render("Command palette")
+	content.WriteString(title + "\n")
+	content.WriteString(p.query.View() + "\n\n")
+
+	p.list.Width = 48
+	if a.width > 0 && a.width-4 < p.list.Width {
+		p.list.Width = a.width - 4
+	}
+	content.WriteString(p.list.View())
+
+	text := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	bar := help.New()
+	bar.ShortSeparator = Glyph(" • ", " | ")
+	bar.Styles.ShortKey = lipgloss.NewStyle().Foreground(a.theme.Accent)
+	bar.Styles.ShortDesc = text
+	bar.Styles.ShortSeparator = text.Copy().Faint(true)
+	content.WriteString("\n" + bar.View(a.hints()))
+	return frame(lipgloss.NewStyle().BorderForeground(a.theme.Accent).Padding(0, 1)).Render(content.String())
+}
+
+// overlayPalette draws the command palette over the top of view, centered
+func (a *App) overlayPalette(view string) string {
+	return components.Overlay(view, a.renderPalette(), a.width)
+}
+
+// exportPage writes the selected page to <name><ext> in the working
+// directory with write
+func (a *App) exportPage(ext string, write func(io.Writer, *types.Page) error) {
+	page := a.selectedPage()
+	if page == nil {
+		return
+	}
+	path := page.Name + ext
+	f, err := os.Create(path)
+	if err != nil {
+		a.status = fmt.Sprintf("Failed to export %s: %v", page.Name, err)
+		return
+	}
+	if err := write(f, page); err != nil {
+		f.Close()
+		a.status = fmt.Sprintf("Failed to export %s: %v", page.Name, err)
+		return
+	}
+	if err := f.Close(); err != nil {
+		a.status = fmt.Sprintf("Failed to export %s: %v", page.Name, err)
+		return
+	}
+	a.status = fmt.Sprintf("Exported %s to %s", page.Name, path)
+}
+
+// switchTheme shows the TUI in the named theme and saves it as the theme
+// to use from now on
+func (a *App) switchTheme(name string) {
+	a.theme = getTheme(name)
+	a.toast.Styles = a.theme.styles()
+	a.progress.Styles = a.theme.styles()
+	a.config.Theme = name
+	if err := config.Set("theme", name); err != nil {
+		a.status = fmt.Sprintf("Switched to the %s theme but failed to save it: %v", name, err)
+		return
+	}
+	a.status = fmt.Sprintf("Switched to the %s theme", name)
+}
+
+// openSettings opens the config file in the user's editor, like 'tldrpp
+// config edit'. The TUI gives the terminal to the editor until it exits.
+func (a *App) openSettings() (bubbletea.Model, bubbletea.Cmd) {
+	editor, err := config.EditorCommand(config.File())
+	if err != nil {
+		a.status = err.Error()
+		return a, nil
+	}
+	cmd := exec.Command(editor[0], editor[1:]...)
+	return a, bubbletea.ExecProcess(cmd, func(err error) bubbletea.Msg {
+		return settingsEditedMsg{err: err}
+	})
+}
+
+// finishSettings checks the config file once the editor exits and applies
+// the settings the TUI shows, such as the theme
+func (a *App) finishSettings(err error) (bubbletea.Model, bubbletea.Cmd) {
+	if err != nil {
+		a.status = fmt.Sprintf("Failed to run editor: %v", err)
+		return a, nil
+	}
+
+	problems, err := config.Validate(config.File())
+	if err != nil {
+		a.status = fmt.Sprintf("Failed to read settings: %v", err)
+		return a, nil
+	}
+	if len(problems) > 0 {
+		a.status = fmt.Sprintf("Settings have %d problem(s), first: %v", len(problems), problems[0])
+		return a, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		a.status = fmt.Sprintf("Failed to load settings: %v", err)
+		return a, nil
+	}
+	a.config = cfg
+	a.theme = getTheme(cfg.Theme)
+	a.keys = newKeyMap(cfg.Keymap)
+	a.status = "Settings reloaded"
+	return a, nil
+}
+
+// pluginCommand runs a plugin once the TUI has given up the terminal. The
+// plugin prints to the terminal itself, so the output is left for the user
+// to read until Enter is pressed.
+type pluginCommand struct {
+	plugin plugin.Plugin
+	args   []string
+	stdin  io.Reader
+}
+
+func (c *pluginCommand) Run() error {
+	err := c.plugin.Execute(c.args)
+	if err != nil {
+		fmt.Printf("\nError: %v\n", err)
+	}
+	waitForEnter(c.stdin)
+	return err
+}
+
+func (c *pluginCommand) SetStdin(r io.Reader) { c.stdin = r }
+func (c *pluginCommand) SetStdout(io.Writer)  {}
+func (c *pluginCommand) SetStderr(io.Writer)  {}
+
+// runPlugin runs the submit plugin on the selected example with args
+func (a *App) runPlugin(args ...string) (bubbletea.Model, bubbletea.Cmd) {
+	page, example := a.selectedPage(), a.currentExample()
+	if page == nil || example == nil {
+		return a, nil
+	}
+	p := plugin.NewSubmitPlugin(page, example)
+	cmd := &pluginCommand{plugin: p, args: args, stdin: os.Stdin}
+	return a, bubbletea.Exec(cmd, func(err error) bubbletea.Msg {
+		return pluginDoneMsg{name: p.Name(), err: err}
+	})
+}
@@ -0,0 +1,27 @@
+package tui
+
+import (
+	bubbletea "github.com/charmbracelet/bubbletea"
+)
+
+// EnablePick makes Enter on an example pick its rendered command and quit
+// instead of only selecting it. The UI is drawn on the terminal rather than
+// stdout, so the caller can print the pick for a shell widget to read.
+func (a *App) EnablePick() {
+	a.pick = true
+}
+
+// Picked returns the command picked in pick mode, or ""
+func (a *App) Picked() string {
+	return a.picked
+}
+
+// pickCommand picks the current example with the entered values and quits
+func (a *App) pickCommand() (bubbletea.Model, bubbletea.Cmd) {
+	example := a.currentExample()
+	if example == nil {
+		return a, nil
+	}
+	a.picked = example.Render(a.currentVars())
+	return a, bubbletea.Quit
+}
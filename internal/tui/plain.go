@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/tui/components"
+	"github.com/muesli/termenv"
+)
+
+// ascii is set when borders and symbols are drawn in ASCII only
+var ascii bool
+
+// asciiBorder stands in for the rounded border on terminals without Unicode
+var asciiBorder = lipgloss.Border{
+	Top:         "-",
+	Bottom:      "-",
+	Left:        "|",
+	Right:       "|",
+	TopLeft:     "+",
+	TopRight:    "+",
+	BottomLeft:  "+",
+	BottomRight: "+",
+}
+
+// SetASCII draws borders and symbols in ASCII only, for terminals and fonts
+// that can't show Unicode
+func SetASCII(on bool) {
+	ascii = on
+}
+
+// Glyph returns the Unicode symbol s, or its ASCII stand-in when drawing in
+// ASCII only
+func Glyph(s, asciiOnly string) string {
+	if ascii {
+		return asciiOnly
+	}
+	return s
+}
+
+// frame draws a border around style's boxes, unless the layout is
+// accessible: a screen reader would read out the box drawing
+func frame(style lipgloss.Style) lipgloss.Style {
+	switch {
+	case accessible:
+		return style
+	case ascii:
+		return style.Border(asciiBorder)
+	default:
+		return style.Border(lipgloss.RoundedBorder())
+	}
+}
+
+// colorless reports whether colors are off, through NO_COLOR, --no-color or
+// a terminal without them. Highlighting by color can't be seen then, so the
+// selection is marked with text instead.
+func colorless() bool {
+	return lipgloss.ColorProfile() == termenv.Ascii
+}
+
+// selectMark returns the prefix marking whether a row is selected when
+// colors are off, and nothing when the highlight shows it
+func selectMark(selected bool) string {
+	switch {
+	case !colorless() && !accessible:
+		return ""
+	case selected:
+		return "> "
+	default:
+		return "  "
+	}
+}
+
+// selectInline marks a selected item in a line of them, e.g. a link, when
+// colors are off
+func selectInline(s string) string {
+	if colorless() || accessible {
+		return "[" + s + "]"
+	}
+	return s
+}
+
+// newProgressBar returns the bar showing cache update progress, drawn
+// without colors or Unicode when those are off
+func newProgressBar() progress.Model {
+	opts := []progress.Option{progress.WithDefaultGradient(), progress.WithWidth(30)}
+	if colorless() {
+		opts = append(opts, progress.WithColorProfile(termenv.Ascii))
+	}
+	bar := progress.New(opts...)
+	if ascii {
+		bar.Full, bar.Empty = '#', '-'
+	}
+	return bar
+}
+
+// styles returns what components draw with in theme, without colors,
+// Unicode or boxes when those are off
+func (t Theme) styles() components.Styles {
+	text := lipgloss.NewStyle().Foreground(t.Foreground)
+	return components.Styles{
+		Title:     lipgloss.NewStyle().Foreground(t.Accent).Bold(true),
+		Text:      text,
+		Faint:     text.Copy().Faint(true),
+		Selected:  text.Copy().Background(t.Highlight).Foreground(t.Background),
+		Accent:    lipgloss.NewStyle().Foreground(t.Accent),
+		Warning:   lipgloss.NewStyle().Foreground(t.Warning),
+		Frame:     frame(lipgloss.NewStyle()),
+		Mark:      selectMark,
+		Cursor:    Glyph("█", "_"),
+		Ellipsis:  Glyph("…", "..."),
+		Separator: Glyph(" • ", " | "),
+	}
+}
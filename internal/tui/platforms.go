@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/locale"
+	"github.com/makalin/tldrpp/internal/platform"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// platformSummary describes the platform filter, or the preferred platforms
+// when pages from all of them are shown
+func (a *App) platformSummary() string {
+	if len(a.platforms) > 0 {
+		return strings.Join(a.platforms, ", ")
+	}
+	if current := platform.Current(); len(current) > 0 {
+		return "all (" + strings.Join(current, ", ") + " first)"
+	}
+	return "all"
+}
+
+// languageBadge renders the language a page is shown in, flagging pages
+// shown in English because they aren't translated into the preferred
+// language. Nothing is shown when English is preferred.
+func (a *App) languageBadge(page *types.Page) string {
+	preferred := a.cache.Language()
+	if preferred == locale.English {
+		return ""
+	}
+	if page.Language != locale.English {
+		return " " + lipgloss.NewStyle().Foreground(a.theme.Success).Render("["+page.Language+"]")
+	}
+	label := fmt.Sprintf("[%s, no %s translation]", page.Language, preferred)
+	return " " + lipgloss.NewStyle().Foreground(a.theme.Warning).Render(label)
+}
+
+// platformBadge renders the platform a page comes from: pages for the
+// running system stand out, common ones are plain and others are flagged
+func (a *App) platformBadge(name string) string {
+	style := lipgloss.NewStyle().Foreground(a.theme.Warning)
+	switch {
+	case name == platform.Common:
+		style = lipgloss.NewStyle().Foreground(a.theme.Border)
+	case platform.Rank(platform.Current(), name) < len(platform.Current()):
+		style = lipgloss.NewStyle().Foreground(a.theme.Success).Bold(true)
+	}
+	return style.Render("[" + name + "]")
+}
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// presentation shows one example at a time filling the screen, for
+// screencasts, workshops and pair-teaching, without the breadcrumb, status
+// bar and key hints
+type presentation struct {
+	// typed is how many runes of the command are shown while it is being
+	// typed out
+	typed int
+	// seq numbers the typing of each example, so ticks of an earlier one
+	// are dropped
+	seq int
+}
+
+// presentTickMsg types the next rune of the command typed as seq
+type presentTickMsg struct {
+	seq int
+}
+
+// startPresentation presents the current example
+func (a *App) startPresentation() (bubbletea.Model, bubbletea.Cmd) {
+	if a.currentExample() == nil {
+		return a, nil
+	}
+	a.recordExample()
+	a.present = &presentation{}
+	return a, a.typeCommand()
+}
+
+// typeCommand types the current example's command out from the start, or
+// shows it whole when typing is off
+func (a *App) typeCommand() bubbletea.Cmd {
+	a.present.seq++
+	a.present.typed = 0
+	delay := a.config.PresentTyping()
+	if delay <= 0 {
+		a.present.typed = -1
+		return nil
+	}
+	return a.presentTick(delay)
+}
+
+// presentTick waits delay before typing the next rune
+func (a *App) presentTick(delay time.Duration) bubbletea.Cmd {
+	seq := a.present.seq
+	return bubbletea.Tick(delay, func(time.Time) bubbletea.Msg {
+		return presentTickMsg{seq: seq}
+	})
+}
+
+// typeNext shows one more rune of the command being typed
+func (a *App) typeNext(msg presentTickMsg) (bubbletea.Model, bubbletea.Cmd) {
+	if a.present == nil || msg.seq != a.present.seq || a.present.typed < 0 {
+		return a, nil
+	}
+	a.present.typed++
+	if a.present.typed >= len([]rune(a.presentedCommand())) {
+		a.present.typed = -1
+		return a, nil
+	}
+	return a, a.presentTick(a.config.PresentTyping())
+}
+
+// presentedCommand returns the current example's command with its values
+// filled in
+func (a *App) presentedCommand() string {
+	example := a.currentExample()
+	if example == nil {
+		return ""
+	}
+	return example.Render(a.currentVars())
+}
+
+// handlePresentKey moves between examples, types the command again or
+// leaves the presentation
+func (a *App) handlePresentKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	k := a.keys
+	switch {
+	case msg.Type == bubbletea.KeyCtrlC:
+		return a, bubbletea.Quit
+	case key.Matches(msg, k.Back, k.Present):
+		a.present = nil
+	case key.Matches(msg, k.Up, k.Prev):
+		if a.exampleIdx > 0 {
+			a.exampleIdx--
+			return a, a.typeCommand()
+		}
+	case key.Matches(msg, k.Down, k.Next, k.Mark):
+		if page := a.selectedPage(); page != nil && a.exampleIdx < len(page.Examples)-1 {
+			a.exampleIdx++
+			return a, a.typeCommand()
+		}
+	case key.Matches(msg, k.Retype):
+		return a, a.typeCommand()
+	}
+	return a, nil
+}
+
+// renderPresentation draws the current example centered on the whole
+// screen: its description above the command in a large box, and where it
+// is in the page at the bottom
+func (a *App) renderPresentation() string {
+	page := a.selectedPage()
+	example := a.currentExample()
+	if page == nil || example == nil {
+		return ""
+	}
+	width, height := a.width, a.height
+	if width <= 0 {
+		width, height = 80, 24
+	}
+	// The box fills most of the screen, leaving a margin around it
+	boxWidth := width - 8
+	if boxWidth < 20 {
+		boxWidth = width
+	}
+
+	title := lipgloss.NewStyle().Foreground(a.theme.Accent).Bold(true)
+	text := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	faint := text.Copy().Faint(true)
+
+	command := a.presentedCommand()
+	if a.present.typed >= 0 {
+		command = string([]rune(command)[:a.present.typed]) + Glyph("█", "_")
+	}
+	description := title.Copy().Width(boxWidth).Align(lipgloss.Center).Render(example.Description)
+	box := frame(text.Copy().Bold(true)).
+		BorderForeground(a.theme.Accent).
+		Padding(1, 3).
+		Width(boxWidth).
+		Render(command)
+	footer := faint.Render(fmt.Sprintf("%s  %d/%d", page.Name, a.exampleIdx+1, len(page.Examples)))
+
+	content := lipgloss.JoinVertical(lipgloss.Center, description, "", box, "", footer)
+	if accessible {
+		// Screen readers read the text, not the layout
+		return strings.Join([]string{example.Description, a.presentedCommand(), fmt.Sprintf("%s %d/%d", page.Name, a.exampleIdx+1, len(page.Examples))}, "\n") + "\n"
+	}
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, content)
+}
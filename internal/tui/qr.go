@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/qrcode"
+)
+
+// qrView is a command shown as a QR code over the screen, to be
+// photographed with a phone
+type qrView struct {
+	command string
+	code    *qrcode.Code
+}
+
+// showQR shows the current command as a QR code, if it fits the terminal
+func (a *App) showQR() {
+	example := a.currentExample()
+	if example == nil {
+		return
+	}
+	a.recordExample()
+	command := example.Render(a.currentVars())
+	code, err := qrcode.Encode(command)
+	if err != nil {
+		a.status = err.Error()
+		return
+	}
+	view := &qrView{command: command, code: code}
+	// The code, its quiet zone and the box around it
+	width := code.Size + 8
+	height := (code.Size+5)/2 + 4
+	if a.width > 0 && (width > a.width || height > a.height) {
+		a.status = fmt.Sprintf("The QR code needs a terminal of %dx%d; make it larger or copy the command", width, height)
+		return
+	}
+	a.qr = view
+}
+
+// handleQRKey closes the QR code on any key but Ctrl+C, which quits
+func (a *App) handleQRKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	if msg.Type == bubbletea.KeyCtrlC {
+		return a, bubbletea.Quit
+	}
+	a.qr = nil
+	return a, nil
+}
+
+// renderQR draws the QR code in a box, with the command below it. Light
+// modules are drawn as blocks on themes with a dark background.
+func (a *App) renderQR() string {
+	styles := a.theme.styles()
+	code := strings.TrimSuffix(a.qr.code.Terminal(a.config.Theme != "light"), "\n")
+	width := lipgloss.Width(strings.SplitN(code, "\n", 2)[0])
+	caption := styles.Faint.Copy().Width(width).Render(a.qr.command)
+	content := styles.Text.Render(code) + "\n" + caption
+	return styles.Frame.Copy().Padding(0, 1).Render(content)
+}
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+const (
+	// recentCommands is how many recent commands the start screen lists
+	recentCommands = 9
+	// frequentPages is how many frequently used pages the start screen lists
+	frequentPages = 5
+)
+
+// Rerun returns the command picked from the start screen to run again once
+// the TUI has exited, or nil
+func (a *App) Rerun() *history.Execution {
+	return a.rerun
+}
+
+// renderRecent renders the recently run commands and most used pages shown
+// on the start screen, or "" if there is nothing to show
+func (a *App) renderRecent() string {
+	if a.searchQuery != "" || a.executions == nil {
+		return ""
+	}
+	recent := a.executions.Recent(recentCommands)
+	if len(recent) == 0 {
+		return ""
+	}
+
+	var content strings.Builder
+	title := lipgloss.NewStyle().
+		Foreground(a.theme.Accent).
+		Bold(true)
+	text := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	command := lipgloss.NewStyle().Foreground(a.theme.Success)
+
+	content.WriteString(title.Render("Recent commands") + "\n")
+	for i, execution := range recent {
+		line := fmt.Sprintf("%d  %s  %s", i+1,
+			command.Render(execution.Command),
+			text.Render("("+execution.Page+")"))
+		content.WriteString(line + "\n")
+	}
+
+	var pages []string
+	for _, count := range a.executions.Frequent(frequentPages) {
+		pages = append(pages, fmt.Sprintf("%s (%d)", count.Page, count.Count))
+	}
+	content.WriteString("\n" + title.Render("Frequent pages") + "\n")
+	content.WriteString(text.Render(strings.Join(pages, ", ")) + "\n")
+
+	return content.String()
+}
+
+// rerunRecent picks the numbered recent command to run again and quits, as
+// commands run in the terminal once the TUI is gone
+func (a *App) rerunRecent(key string) (bubbletea.Model, bubbletea.Cmd) {
+	if a.searchQuery != "" || a.executions == nil {
+		return a, nil
+	}
+	n, err := strconv.Atoi(key)
+	if err != nil {
+		return a, nil
+	}
+	recent := a.executions.Recent(recentCommands)
+	if n < 1 || n > len(recent) {
+		return a, nil
+	}
+
+	execution := recent[n-1]
+	a.rerun = &execution
+	return a, bubbletea.Quit
+}
+
+// runLast runs the most recent command again. When values of it are asked
+// for every time, or were secret and not kept, its example is opened for
+// editing instead, with the other values filled in.
+func (a *App) runLast() (bubbletea.Model, bubbletea.Cmd) {
+	if a.executions == nil || len(a.executions.Executions) == 0 {
+		a.status = "No command run yet"
+		return a, nil
+	}
+	execution := a.executions.Executions[0]
+	if execution.Template == "" && execution.Redacted {
+		a.status = fmt.Sprintf("%s was run with secret values, which are not kept; run it from its page again", execution.Page)
+		return a, nil
+	}
+
+	asked := make(map[string]bool)
+	if execution.Template != "" {
+		for _, placeholder := range types.NewExample("", execution.Template).Placeholders {
+			value, ok := execution.Vars[placeholder.Name]
+			if ok && (value == types.Redacted || a.config.MemoryPolicy(placeholder.Name, placeholder.Type) == config.MemoryAsk) {
+				asked[placeholder.Name] = true
+			}
+		}
+	}
+	if len(asked) == 0 {
+		if a.runner == nil {
+			a.rerun = &execution
+			return a, bubbletea.Quit
+		}
+		return a.runInTerminal(execution, false)
+	}
+	if !a.openExample(execution, asked) {
+		// A pipeline, or an example no longer on the page: ask for the
+		// values in the terminal
+		a.rerun = &execution
+		return a, bubbletea.Quit
+	}
+	return a, nil
+}
+
+// openExample opens the example execution was rendered from for editing,
+// with its values except the asked ones, and focuses the first of those.
+// It reports whether the example was found.
+func (a *App) openExample(execution history.Execution, asked map[string]bool) bool {
+	a.jumpTo(execution.Page)
+	page := a.selectedPage()
+	if a.state != StateExamples || page == nil {
+		return false
+	}
+	for i := range page.Examples {
+		example := &page.Examples[i]
+		if example.Command != execution.Template {
+			continue
+		}
+		a.exampleIdx = i
+		for name, value := range execution.Vars {
+			if !asked[name] {
+				a.values[name] = value
+			}
+		}
+		a.navigate(StateEdit)
+		a.fieldIdx = 0
+		var names []string
+		for j, placeholder := range example.Placeholders {
+			if asked[placeholder.Name] {
+				if len(names) == 0 {
+					a.fieldIdx = j
+				}
+				names = append(names, placeholder.Name)
+			}
+		}
+		a.status = fmt.Sprintf("Fill in %s, then %s to run", strings.Join(names, ", "), a.keys.Run.Help().Key)
+		return true
+	}
+	return false
+}
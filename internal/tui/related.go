@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// relatedPages is how many related pages the page view lists
+	relatedPages = 8
+	// usedTogether is how close in time commands must run to count as
+	// used together
+	usedTogether = 15 * time.Minute
+)
+
+// openPage shows the selected page's examples
+func (a *App) openPage() {
+	a.navigate(StateExamples)
+	a.showPage()
+}
+
+// showPage resets the page view for the newly selected page
+func (a *App) showPage() {
+	a.exampleIdx = 0
+	a.marked = nil
+	a.resetValues()
+	a.recordView()
+	a.loadRelated()
+	a.loadLinks()
+	a.loadInstall()
+}
+
+// loadRelated works out the pages related to the selected one: those it
+// refers to with "See also", its parent command and sibling subcommands
+// (git-commit for git-push) and the pages whose commands were run around
+// the same time as its own. Its own subcommands are listed apart.
+func (a *App) loadRelated() {
+	a.related, a.relatedIdx, a.relatedFocus = nil, 0, false
+	page := a.selectedPage()
+	if page == nil {
+		return
+	}
+
+	scores := make(map[string]int)
+	add := func(name string, score int) {
+		if name != "" && name != page.Name {
+			scores[name] += score
+		}
+	}
+	for _, name := range page.SeeAlso {
+		add(a.cache.PageName(name), 3)
+	}
+	if parent, _, ok := strings.Cut(page.Name, "-"); ok {
+		add(a.cache.PageName(parent), 2)
+		for _, sibling := range a.cache.Subcommands(parent) {
+			if !strings.HasPrefix(sibling, page.Name+"-") {
+				add(sibling, 1)
+			}
+		}
+	}
+	if a.executions != nil {
+		for name, count := range a.executions.CoOccurring(page.Name, usedTogether) {
+			add(name, 2*count)
+		}
+	}
+
+	for name := range scores {
+		a.related = append(a.related, name)
+	}
+	sort.Slice(a.related, func(i, j int) bool {
+		si, sj := scores[a.related[i]], scores[a.related[j]]
+		if si != sj {
+			return si > sj
+		}
+		return a.related[i] < a.related[j]
+	})
+	if len(a.related) > relatedPages {
+		a.related = a.related[:relatedPages]
+	}
+}
+
+// handleRelatedKey moves through the related pages and jumps to one
+func (a *App) handleRelatedKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	switch {
+	case msg.Type == bubbletea.KeyCtrlC:
+		return a, bubbletea.Quit
+	case key.Matches(msg, a.keys.Back, a.keys.Related):
+		a.relatedFocus = false
+	case key.Matches(msg, a.keys.Prev, a.keys.PrevField):
+		if a.relatedIdx > 0 {
+			a.relatedIdx--
+		}
+	case key.Matches(msg, a.keys.Next, a.keys.Field):
+		if a.relatedIdx < len(a.related)-1 {
+			a.relatedIdx++
+		}
+	case key.Matches(msg, a.keys.Select):
+		a.jumpTo(a.related[a.relatedIdx])
+	}
+	return a, nil
+}
+
+// jumpTo opens the page called name, searching for it so the page list
+// shows it and its neighbours. Esc returns to where the jump started.
+func (a *App) jumpTo(name string) {
+	from := a.here()
+	a.searchQuery = name
+	a.filter = ""
+	if err := a.loadPages(); err != nil {
+		a.status = fmt.Sprintf("Failed to load pages: %v", err)
+		return
+	}
+
+	for i, entry := range a.pages {
+		if entry.Name == name {
+			a.selectedIdx = i
+			a.navigateFrom(from, StateExamples)
+			a.showPage()
+			return
+		}
+	}
+	a.relatedFocus = false
+	a.linkFocus = false
+	a.navigateFrom(from, StatePages)
+	a.status = fmt.Sprintf("%s is not on the selected platforms", name)
+}
+
+// renderRelated renders the related pages, highlighting the selected one
+// while they have the focus
+func (a *App) renderRelated() string {
+	if len(a.related) == 0 {
+		return ""
+	}
+
+	text := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	selected := lipgloss.NewStyle().
+		Background(a.theme.Highlight).
+		Foreground(a.theme.Background)
+
+	names := make([]string, len(a.related))
+	for i, name := range a.related {
+		if a.relatedFocus && i == a.relatedIdx {
+			names[i] = selected.Render(selectInline(name))
+		} else {
+			names[i] = text.Render(name)
+		}
+	}
+	return text.Render("Related: ") + strings.Join(names, text.Render(", ")) + "\n"
+}
@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"fmt"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+)
+
+// Placeholder values are scoped to the page: a value entered for {{file}}
+// in one example fills {{file}} in every other example of the page. An
+// example can override a value for itself only.
+
+// overridden reports whether the current example has its own value for the
+// named placeholder
+func (a *App) overridden(name string) bool {
+	_, ok := a.overrides[a.exampleIdx][name]
+	return ok
+}
+
+// setValue sets a placeholder value for the whole page, or for the current
+// example only if it overrides that placeholder
+func (a *App) setValue(name, value string) {
+	current := a.values[name]
+	if a.overridden(name) {
+		current = a.overrides[a.exampleIdx][name]
+	}
+	if value == current {
+		return
+	}
+	a.recordChange("{{" + name + "}}")
+
+	if a.overridden(name) {
+		a.overrides[a.exampleIdx][name] = value
+		return
+	}
+	a.values[name] = value
+}
+
+// toggleOverride gives the focused placeholder a value of its own in the
+// current example, starting from the shared one, or drops it again
+func (a *App) toggleOverride() {
+	example := a.currentExample()
+	if example == nil || a.fieldIdx >= len(example.Placeholders) {
+		return
+	}
+	placeholder := example.Placeholders[a.fieldIdx]
+	a.recordChange("{{" + placeholder.Name + "}}")
+
+	if a.overridden(placeholder.Name) {
+		delete(a.overrides[a.exampleIdx], placeholder.Name)
+		a.status = fmt.Sprintf("%s uses the page value again", placeholder.Name)
+		return
+	}
+	if a.overrides == nil {
+		a.overrides = make(map[int]map[string]string)
+	}
+	if a.overrides[a.exampleIdx] == nil {
+		a.overrides[a.exampleIdx] = make(map[string]string)
+	}
+	a.overrides[a.exampleIdx][placeholder.Name] = a.values[placeholder.Name]
+	a.status = fmt.Sprintf("%s now has its own value in this example", placeholder.Name)
+}
+
+// resetValues forgets the values entered for the previous page, and their
+// undo history
+func (a *App) resetValues() {
+	a.values = make(map[string]string)
+	a.overrides = nil
+	a.undo, a.redo = nil, nil
+}
+
+// sharedBy returns how many examples of the selected page use the named
+// placeholder
+func (a *App) sharedBy(name string) int {
+	page := a.selectedPage()
+	if page == nil {
+		return 0
+	}
+	count := 0
+	for _, example := range page.Examples {
+		for _, placeholder := range example.Placeholders {
+			if placeholder.Name == name {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// scopeLabel describes where the value of a placeholder applies
+func (a *App) scopeLabel(name string) string {
+	if a.overridden(name) {
+		return " [this example]"
+	}
+	if n := a.sharedBy(name); n > 1 {
+		return fmt.Sprintf(" [shared by %d examples]", n)
+	}
+	return ""
+}
+
+// startTyping starts entering a value for the focused placeholder
+func (a *App) startTyping() {
+	example := a.currentExample()
+	if example == nil || a.fieldIdx >= len(example.Placeholders) {
+		return
+	}
+	placeholder := example.Placeholders[a.fieldIdx]
+	if len(placeholder.Choices) > 0 {
+		return
+	}
+	a.typing = true
+	a.typed = a.valueFor(placeholder)
+	a.suggestIdx = -1
+}
+
+// handleValueKey handles keys while a placeholder value is being typed
+func (a *App) handleValueKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	switch msg.Type {
+	case bubbletea.KeyCtrlC:
+		return a, bubbletea.Quit
+	case bubbletea.KeyEnter, bubbletea.KeyTab:
+		a.typing = false
+		if example := a.currentExample(); example != nil && a.fieldIdx < len(example.Placeholders) {
+			a.setValue(example.Placeholders[a.fieldIdx].Name, a.typed)
+		}
+		if msg.Type == bubbletea.KeyTab {
+			a.moveField(1)
+		}
+	case bubbletea.KeyEsc:
+		a.typing = false
+	case bubbletea.KeyUp:
+		a.cycleSuggestion(-1)
+	case bubbletea.KeyDown:
+		a.cycleSuggestion(1)
+	case bubbletea.KeyBackspace:
+		if runes := []rune(a.typed); len(runes) > 0 {
+			a.typed = string(runes[:len(runes)-1])
+		}
+	case bubbletea.KeyRunes, bubbletea.KeySpace:
+		a.typed += string(msg.Runes)
+	}
+	return a, nil
+}
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// searchDebounceMsg fires when typing paused after the search numbered seq
+// was typed
+type searchDebounceMsg struct {
+	seq int
+}
+
+// searchDoneMsg carries the results of the search numbered seq
+type searchDoneMsg struct {
+	seq   int
+	pages []types.IndexEntry
+	err   error
+}
+
+// searchFilter searches for the filter as typed so far, once typing pauses
+// for the configured delay. A search still running is cancelled, and the
+// results of searches typed past are dropped.
+func (a *App) searchFilter() bubbletea.Cmd {
+	a.searchSeq++
+	a.cancelSearch()
+	if a.filter == "" {
+		// Every loaded page matches, no need to search
+		a.setPages(a.allPages)
+		return nil
+	}
+
+	seq := a.searchSeq
+	delay := a.config.SearchDebounce()
+	if delay <= 0 {
+		return a.startSearch(seq)
+	}
+	return bubbletea.Tick(delay, func(time.Time) bubbletea.Msg {
+		return searchDebounceMsg{seq: seq}
+	})
+}
+
+// startSearch runs the search numbered seq in the background, unless it was
+// typed past while waiting
+func (a *App) startSearch(seq int) bubbletea.Cmd {
+	if seq != a.searchSeq {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.searchCancel = cancel
+	cacheManager, filter, platforms, loaded := a.cache, a.filter, a.platforms, a.allPages
+	return func() bubbletea.Msg {
+		pages, err := filterPages(ctx, cacheManager, filter, platforms, loaded)
+		return searchDoneMsg{seq: seq, pages: pages, err: err}
+	}
+}
+
+// finishSearch shows the results of a search unless it was typed past
+func (a *App) finishSearch(msg searchDoneMsg) (bubbletea.Model, bubbletea.Cmd) {
+	if msg.seq != a.searchSeq {
+		return a, nil
+	}
+	a.cancelSearch()
+	if msg.err != nil {
+		a.status = fmt.Sprintf("Search failed: %v", msg.err)
+		return a, nil
+	}
+	a.setPages(msg.pages)
+	return a, nil
+}
+
+// cancelSearch stops the search running in the background, if any
+func (a *App) cancelSearch() {
+	if a.searchCancel != nil {
+		a.searchCancel()
+		a.searchCancel = nil
+	}
+}
+
+// filterPages returns the loaded pages whose name or description contains
+// filter, most relevant first
+func filterPages(ctx context.Context, cacheManager CacheStore, filter string, platforms []string, loaded []types.IndexEntry) ([]types.IndexEntry, error) {
+	if filter == "" {
+		return loaded, nil
+	}
+	matches, err := cacheManager.ListPagesContext(ctx, filter, platforms)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]bool, len(loaded))
+	for _, page := range loaded {
+		keep[page.Platform+"/"+page.Name] = true
+	}
+	pages := matches[:0]
+	for _, page := range matches {
+		if keep[page.Platform+"/"+page.Name] {
+			pages = append(pages, page)
+		}
+	}
+	return pages, nil
+}
+
+// setPages lists pages, keeping the selected page selected if it is among
+// them
+func (a *App) setPages(pages []types.IndexEntry) {
+	var selected types.IndexEntry
+	if a.selectedIdx < len(a.pages) {
+		selected = a.pages[a.selectedIdx]
+	}
+
+	a.pages = pages
+	a.selectedIdx = 0
+	for i, page := range pages {
+		if page.Platform == selected.Platform && page.Name == selected.Name {
+			a.selectedIdx = i
+			break
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"github.com/makalin/tldrpp/internal/session"
+)
+
+// Restore reopens the TUI where a previous launch left off, unless Run is
+// given a search query. The platforms are restored as given.
+func (a *App) Restore(state *session.State) {
+	a.restore = state
+	a.platforms = state.Platforms
+}
+
+// Session returns where the TUI is, for the next launch to restore. Help
+// and other screens on top of a page count as that page.
+func (a *App) Session() *session.State {
+	state := &session.State{
+		SearchQuery: a.searchQuery,
+		Filter:      a.filter,
+		Platforms:   a.platforms,
+		Screen:      session.ScreenSearch,
+		Page:        a.selectedKey(),
+		Example:     a.exampleIdx,
+	}
+
+	current := a.state
+	for i := len(a.history) - 1; i >= 0 && !restorable(current); i-- {
+		current = a.history[i].state
+	}
+	switch current {
+	case StatePages:
+		state.Screen = session.ScreenPages
+	case StateExamples, StateEdit:
+		state.Screen = session.ScreenPage
+	}
+	return state
+}
+
+// restorable reports whether a launch can reopen state
+func restorable(state AppState) bool {
+	switch state {
+	case StateSearch, StatePages, StateExamples, StateEdit:
+		return true
+	}
+	return false
+}
+
+// restoreScreen selects the restored page and reopens the screen it was on,
+// falling back to the pages list if the page is gone
+func (a *App) restoreScreen() {
+	for i, page := range a.pages {
+		if page.Platform+"/"+page.Name == a.restore.Page {
+			a.selectedIdx = i
+			break
+		}
+	}
+
+	switch a.restore.Screen {
+	case session.ScreenPages:
+		a.navigate(StatePages)
+	case session.ScreenPage:
+		a.navigate(StatePages)
+		if a.selectedKey() != a.restore.Page {
+			return
+		}
+		a.openPage()
+		if page := a.selectedPage(); page != nil && a.restore.Example < len(page.Examples) {
+			a.exampleIdx = a.restore.Example
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/snippet"
+	"github.com/makalin/tldrpp/internal/tui/components"
+)
+
+// startNaming asks for a name to save the current example as a snippet
+func (a *App) startNaming() {
+	page := a.selectedPage()
+	if page == nil || a.currentExample() == nil {
+		return
+	}
+	input := components.NewInput("Save snippet as: ", page.Name, "(Enter Save, Esc Cancel)", a.theme.styles())
+	a.naming = &input
+}
+
+// handleNameKey handles keyboard input while a snippet name is entered
+func (a *App) handleNameKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	if msg.Type == bubbletea.KeyCtrlC {
+		return a, bubbletea.Quit
+	}
+	input, _ := a.naming.Update(msg)
+	a.naming = &input
+	switch input.State {
+	case components.InputSubmitted:
+		a.naming = nil
+		a.saveSnippet(input.Value)
+	case components.InputCancelled:
+		a.naming = nil
+	}
+	return a, nil
+}
+
+// saveSnippet saves the current example with the entered values
+func (a *App) saveSnippet(name string) {
+	if len(a.marked) > 0 {
+		a.saveMarked(name)
+		return
+	}
+
+	page := a.selectedPage()
+	example := a.currentExample()
+	if page == nil || example == nil {
+		return
+	}
+
+	vars := a.currentVars()
+	s := &snippet.Snippet{
+		Name:        name,
+		Page:        page.Name,
+		Platform:    page.Platform,
+		Description: example.Description,
+		Template:    example.Command,
+		Vars:        vars,
+		Command:     example.Render(vars),
+	}
+	a.storeSnippet(s, fmt.Sprintf("Saved snippet %s", name))
+}
+
+// storeSnippet saves s, reporting done once it is saved. Saving over a
+// snippet of the same name asks first.
+func (a *App) storeSnippet(s *snippet.Snippet, done string) {
+	err := a.snippets.Save(s, false)
+	if errors.Is(err, snippet.ErrExists) {
+		a.ask(fmt.Sprintf("A snippet named %s already exists.", s.Name), "Overwrite it?", func() bubbletea.Cmd {
+			if err := a.snippets.Save(s, true); err != nil {
+				a.status = fmt.Sprintf("Failed to save snippet: %v", err)
+			} else {
+				a.status = done
+			}
+			return nil
+		})
+		return
+	}
+	if err != nil {
+		a.status = fmt.Sprintf("Failed to save snippet: %v", err)
+		return
+	}
+	a.status = done
+}
+
+// openSnippets switches to the list of saved snippets
+func (a *App) openSnippets() {
+	snippets, err := a.snippets.List()
+	if err != nil {
+		a.status = fmt.Sprintf("Failed to load snippets: %v", err)
+		return
+	}
+	a.snippetList = snippets
+	a.snippetRows = components.NewList(snippetItems(snippets), components.ListKeys{Up: a.keys.Up, Down: a.keys.Down}, a.theme.styles())
+	a.snippetRows.Empty = "No snippets yet. Press s on an example to save one."
+	a.navigate(StateSnippets)
+}
+
+// snippetItems returns the rows listing snippets
+func snippetItems(snippets []*snippet.Snippet) []components.Item {
+	items := make([]components.Item, len(snippets))
+	for i, s := range snippets {
+		items[i] = components.Item{Text: fmt.Sprintf("%s  %s  (%s)", s.Name, s.Command, s.Page)}
+	}
+	return items
+}
+
+// runSnippet picks the selected snippet to run and quits, as commands run
+// in the terminal once the TUI is gone
+func (a *App) runSnippet() (bubbletea.Model, bubbletea.Cmd) {
+	if a.snippetRows.Index >= len(a.snippetList) {
+		return a, nil
+	}
+	a.rerunSnippet = a.snippetList[a.snippetRows.Index]
+	return a, bubbletea.Quit
+}
+
+// RerunSnippet returns the snippet picked to run once the TUI has exited,
+// or nil
+func (a *App) RerunSnippet() *snippet.Snippet {
+	return a.rerunSnippet
+}
+
+// removeSnippet deletes the selected snippet once confirmed
+func (a *App) removeSnippet() {
+	if a.snippetRows.Index >= len(a.snippetList) {
+		return
+	}
+	i := a.snippetRows.Index
+	s := a.snippetList[i]
+	a.ask(s.Command, fmt.Sprintf("Remove snippet %s?", s.Name), func() bubbletea.Cmd {
+		if err := a.snippets.Remove(s.Name); err != nil {
+			a.status = fmt.Sprintf("Failed to remove snippet: %v", err)
+			return nil
+		}
+
+		a.snippetList = append(a.snippetList[:i], a.snippetList[i+1:]...)
+		a.snippetRows.SetItems(snippetItems(a.snippetList))
+		a.status = fmt.Sprintf("Removed snippet %s", s.Name)
+		return nil
+	})
+}
+
+// renderSnippets renders the list of saved snippets
+func (a *App) renderSnippets() string {
+	var content strings.Builder
+
+	header := lipgloss.NewStyle().
+		Foreground(a.theme.Accent).
+		Bold(true).
+		Render(fmt.Sprintf("Snippets (%d)", len(a.snippetList)))
+	content.WriteString(header + "\n\n")
+
+	content.WriteString(a.snippetRows.View())
+
+	content.WriteString("\n" + a.renderHints())
+
+	return content.String()
+}
@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/stats"
+)
+
+const (
+	// statsTop is how many pages and examples the stats panel lists
+	statsTop = 5
+	// statsDays is how many days the stats panel charts
+	statsDays = 14
+)
+
+// sparkLevels returns the characters drawing the daily usage chart, from no
+// use to the busiest day
+func sparkLevels() []rune {
+	return []rune(Glyph(" ▁▂▃▄▅▆▇█", " .:-=+*#@"))
+}
+
+// loadStats loads the usage stats when they are enabled in the config
+func (a *App) loadStats() {
+	if !a.config.Stats {
+		return
+	}
+	usage, err := stats.Load(config.StatsFile())
+	if err != nil {
+		a.status = err.Error()
+		return
+	}
+	a.usage = usage
+}
+
+// recordView counts the selected page being opened
+func (a *App) recordView() {
+	page := a.selectedPage()
+	if a.usage == nil || page == nil {
+		return
+	}
+	a.usage.RecordView(page.Name, page.Platform, a.clock.Now())
+	a.saveStats()
+}
+
+// recordExample counts the current example being used with the entered
+// values
+func (a *App) recordExample() {
+	page := a.selectedPage()
+	example := a.currentExample()
+	if a.usage == nil || page == nil || example == nil {
+		return
+	}
+	placeholders := len(example.Placeholders)
+	filled := placeholders - len(example.Missing(a.currentVars()))
+	a.usage.RecordExample(page.Name, page.Platform, example.Command, placeholders, filled, a.clock.Now())
+	a.saveStats()
+}
+
+// saveStats writes the usage stats, reporting failures in the status bar
+func (a *App) saveStats() {
+	if err := a.usage.Save(); err != nil {
+		a.status = err.Error()
+	}
+}
+
+// renderStats renders the usage stats panel
+func (a *App) renderStats() string {
+	var content strings.Builder
+
+	title := lipgloss.NewStyle().
+		Foreground(a.theme.Accent).
+		Bold(true)
+	text := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	command := lipgloss.NewStyle().Foreground(a.theme.Success)
+	footer := a.renderHints()
+
+	content.WriteString(title.Render("Usage stats") + "\n\n")
+	if a.usage == nil {
+		content.WriteString(text.Render("Usage stats are disabled. Enable them with: tldrpp config set stats true") + "\n")
+		return content.String() + "\n" + footer
+	}
+
+	content.WriteString(title.Render("Top pages") + "\n")
+	for _, page := range a.usage.TopPages(statsTop) {
+		content.WriteString(text.Render(fmt.Sprintf("%4d  %s (%s)", page.Uses(), page.Page, page.Platform)) + "\n")
+	}
+
+	content.WriteString("\n" + title.Render("Top examples") + "\n")
+	for _, example := range a.usage.TopExamples(statsTop) {
+		content.WriteString(text.Render(fmt.Sprintf("%4d  ", example.Count)) + command.Render(example.Example) + "\n")
+	}
+
+	daily := a.usage.Daily(statsDays, a.clock.Now())
+	content.WriteString("\n" + title.Render(fmt.Sprintf("Last %d days", statsDays)) + "\n")
+	content.WriteString(command.Render(sparkline(daily)) + "\n")
+
+	if rate, ok := a.usage.FillRate(); ok {
+		content.WriteString("\n" + text.Render(fmt.Sprintf("Placeholder fill rate: %.0f%%", rate*100)) + "\n")
+	}
+
+	return content.String() + "\n" + footer
+}
+
+// sparkline draws one bar per day, scaled to the busiest day
+func sparkline(days []stats.DayCount) string {
+	max := 0
+	for _, day := range days {
+		if day.Count > max {
+			max = day.Count
+		}
+	}
+
+	levels := sparkLevels()
+	var line strings.Builder
+	for _, day := range days {
+		level := 0
+		if max > 0 {
+			level = day.Count * (len(levels) - 1) / max
+		}
+		line.WriteRune(levels[level])
+	}
+	return line.String()
+}
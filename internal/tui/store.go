@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/clock"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// CacheStore is the pages cache as the TUI uses it. A *cache.Manager is
+// one; tests can give the TUI an in-memory store instead, such as the one
+// of package cachetest.
+type CacheStore interface {
+	IsInitialized() bool
+	// IsStale reports whether the cache is missing or older than ttl
+	IsStale(ttl time.Duration) bool
+	// Changed reports whether the pages changed since last read, e.g. by an
+	// update in another process
+	Changed() bool
+	// UpdateContext downloads the pages again, reporting progress to
+	// progress if not nil
+	UpdateContext(ctx context.Context, progress cache.ProgressFunc) error
+	Info(ttl time.Duration) (*cache.Info, error)
+	// Changes returns what the last update changed, or nil if it recorded
+	// nothing
+	Changes() (*cache.Changes, error)
+	// Language returns the most preferred page language
+	Language() string
+
+	ListPages(query string, platforms []string) ([]types.IndexEntry, error)
+	ListPagesContext(ctx context.Context, query string, platforms []string) ([]types.IndexEntry, error)
+	LoadPage(entry types.IndexEntry) (*types.Page, error)
+	FindPage(command string) (*types.Page, error)
+	// PageName returns the name of the page for a command line, such as
+	// "git commit", or "" if no page has the name
+	PageName(command string) string
+	Subcommands(name string) []string
+	RandomPage(platforms []string) (types.IndexEntry, error)
+	TipOfTheDay(platforms []string, day time.Time) (types.IndexEntry, error)
+	// CustomPath returns the file of a custom page, or "" if entry is a
+	// cached page
+	CustomPath(entry types.IndexEntry) string
+	// PageURL returns where a page can be read upstream, or "" if no source
+	// says
+	PageURL(entry types.IndexEntry) string
+}
+
+// SetClock sets the clock dating page views, the tip of the day and
+// command outputs; by default the system's
+func (a *App) SetClock(c clock.Clock) {
+	a.clock = c
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/makalin/tldrpp/internal/cache/cachetest"
+	"github.com/makalin/tldrpp/internal/clock"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+var (
+	_ CacheStore = (*cachetest.Store)(nil)
+
+	storePages = []*types.Page{
+		{Name: "ls", Platform: "common", Description: "List directory contents"},
+		{Name: "tar", Platform: "common", Description: "Archiving utility"},
+	}
+)
+
+// storeApp returns a TUI over an in-memory store of storePages updated at
+// the time of the returned clock
+func storeApp(t *testing.T) (*App, *cachetest.Store, *clock.Fake) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	now := clock.NewFake(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	store := cachetest.New(now, storePages...)
+	a := New(config.DefaultConfig(), store, nil)
+	a.SetClock(now)
+	return a, store, now
+}
+
+func TestInitRefreshesStaleCache(t *testing.T) {
+	a, store, now := storeApp(t)
+	a.Init()
+	if a.refreshing {
+		t.Fatal("Expected a fresh cache not to be refreshed")
+	}
+
+	now.Advance(a.config.CacheTTL() + time.Minute)
+	a.Init()
+	if !a.refreshing {
+		t.Fatal("Expected a cache older than the TTL to be refreshed")
+	}
+	for a.refreshing {
+		a.Update(a.waitForRefresh()())
+	}
+	if store.Updates() != 1 || store.IsStale(a.config.CacheTTL()) {
+		t.Errorf("Expected the cache to be updated once, updated %d times", store.Updates())
+	}
+}
+
+func TestTipFollowsClock(t *testing.T) {
+	a, _, now := storeApp(t)
+
+	a.loadTip()
+	if a.tip == nil {
+		t.Fatal("Expected a tip of the day")
+	}
+	first := a.tip.Name
+	a.loadTip()
+	if a.tip.Name != first {
+		t.Errorf("Expected the same tip all day, got %s then %s", first, a.tip.Name)
+	}
+
+	now.Advance(24 * time.Hour)
+	a.loadTip()
+	if a.tip.Name == first {
+		t.Errorf("Expected another tip the next day, got %s again", first)
+	}
+}
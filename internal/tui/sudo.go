@@ -0,0 +1,29 @@
+package tui
+
+import (
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/sudo"
+)
+
+// executeWithSudo executes the current command as root with sudo. The
+// terminal is handed over to the command, so sudo asks for a password
+// there.
+func (a *App) executeWithSudo() (bubbletea.Model, bubbletea.Cmd) {
+	if err := sudo.Available(); err != nil {
+		a.status = err.Error()
+		return a, nil
+	}
+	return a.executeExample(false, true)
+}
+
+// renderNeedsRoot points out the key running the current command with sudo
+// when it usually needs root
+func (a *App) renderNeedsRoot() string {
+	example := a.currentExample()
+	if example == nil || !sudo.NeedsRoot(example.Render(a.currentVars())) {
+		return ""
+	}
+	warning := lipgloss.NewStyle().Foreground(a.theme.Warning)
+	return warning.Render("Needs root: "+a.keys.Sudo.Help().Key+" runs it with sudo") + "\n"
+}
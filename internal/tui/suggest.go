@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/types"
+)
+
+// focusedPlaceholder returns the placeholder the edit focus is on
+func (a *App) focusedPlaceholder() (types.Placeholder, bool) {
+	example := a.currentExample()
+	if example == nil || a.fieldIdx >= len(example.Placeholders) {
+		return types.Placeholder{}, false
+	}
+	return example.Placeholders[a.fieldIdx], true
+}
+
+// cycleSuggestion fills the value being typed with the previous or next
+// value suggested for the focused placeholder
+func (a *App) cycleSuggestion(delta int) {
+	placeholder, ok := a.focusedPlaceholder()
+	if !ok {
+		return
+	}
+	values := a.suggestions.Suggest(placeholder)
+	n := len(values)
+	if n == 0 {
+		return
+	}
+	if a.suggestIdx < 0 && delta < 0 {
+		a.suggestIdx = 0
+	}
+	a.suggestIdx = (a.suggestIdx + delta + n) % n
+	a.typed = values[a.suggestIdx]
+}
+
+// renderSuggestions shows the values suggested for the placeholder being
+// typed as a dropdown under the field, the one picked highlighted
+func (a *App) renderSuggestions(placeholder types.Placeholder) string {
+	values := a.suggestions.Suggest(placeholder)
+	if len(values) == 0 {
+		return ""
+	}
+
+	style := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	picked := style.Copy().Foreground(a.theme.Accent).Bold(true)
+	keys := a.keys.ArrowUp.Help().Key + "/" + a.keys.ArrowDown.Help().Key
+	var b strings.Builder
+	b.WriteString(style.Render("    Suggestions ("+keys+"):") + "\n")
+	for i, value := range values {
+		if i == a.suggestIdx {
+			b.WriteString(picked.Render("    "+selectMark(true)+value) + "\n")
+		} else {
+			b.WriteString(style.Render("    "+selectMark(false)+value) + "\n")
+		}
+	}
+	return b.String()
+}
@@ -1,26 +1,122 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
 	"strings"
+	"time"
 
-	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/key"
+	bubbletea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/makalin/tldrpp/internal/cache"
+	"github.com/makalin/tldrpp/internal/clock"
 	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/history"
+	"github.com/makalin/tldrpp/internal/log"
+	"github.com/makalin/tldrpp/internal/session"
+	"github.com/makalin/tldrpp/internal/snippet"
+	"github.com/makalin/tldrpp/internal/stats"
+	"github.com/makalin/tldrpp/internal/suggest"
+	"github.com/makalin/tldrpp/internal/tui/components"
 	"github.com/makalin/tldrpp/internal/types"
 )
 
 // App represents the main TUI application
 type App struct {
 	config      *config.Config
-	cache       *cache.Manager
+	cache       CacheStore
 	state       AppState
 	searchQuery string
-	pages       []*types.Page
+	pages       []types.IndexEntry
+	allPages    []types.IndexEntry
+	filter      string
+	filtering   bool
+	// searchSeq numbers the searches typed into the filter, so results of
+	// earlier ones are dropped, and searchCancel stops the one running
+	searchSeq    int
+	searchCancel context.CancelFunc
+	// rows caches the rendered rows of the pages list
+	rows rowCache
 	selectedIdx int
+	exampleIdx  int
+	marked      map[int]bool
 	platforms   []string
 	theme        Theme
+	fieldIdx    int
+	values      map[string]string
+	overrides   map[int]map[string]string
+	undo        []valueState
+	redo        []valueState
+	typing      bool
+	typed       string
+	suggestions suggest.Providers
+	suggestIdx  int
+	width       int
+	height      int
+	singlePane  bool
+	refreshing  bool
+	progress    components.Progress
+	cancel      context.CancelFunc
+	updates     chan bubbletea.Msg
+	// status is the message to show next in the status bar, which the toast
+	// then shows for a while
+	status      string
+	toast       components.Toast
+	cacheInfo   *cache.Info
+	executions  *history.Log
+	rerun       *history.Execution
+	rerunSnippet *snippet.Snippet
+	runner      Runner
+	checker     Checker
+	// confirm is the dialog on screen, if any
+	confirm     *confirmDialog
+	// qr is the command shown as a QR code over the screen, if any
+	qr          *qrView
+	// present is set while an example is presented on the whole screen
+	present     *presentation
+	outputs     []commandOutput
+	outputIdx   int
+	comparing   bool
+	// outputPath is the prompt for a file to save an output to, if open
+	outputPath  *components.Input
+	// workDir is the directory commands run in, empty for the one tldr++
+	// was started in, and workDirPrompt the prompt choosing it, if open
+	workDir       string
+	workDirPrompt *workDirPrompt
+	snippets    *snippet.Store
+	usage       *stats.Stats
+	// clock dates page views, tips and command outputs
+	clock       clock.Clock
+	snippetList []*snippet.Snippet
+	snippetRows components.List
+	// naming is the prompt for the name of a snippet to save, if open
+	naming      *components.Input
+	editor      *pageEditor
+	related     []string
+	relatedIdx  int
+	relatedFocus bool
+	links       []link
+	linkIdx     int
+	linkFocus   bool
+	// missing is set when the selected page's command isn't installed, and
+	// installCommand then holds how to install it, if known
+	missing        bool
+	installCommand string
+	history     []screen
+	palette     *palette
+	keys        keyMap
+	allHints    bool
+	restore     *session.State
+	tip         *types.IndexEntry
+	changes     *cache.Changes
+	pick        bool
+	picked      string
 }
 
 // AppState represents the current state of the application
@@ -32,8 +128,27 @@ const (
 	StateExamples
 	StateEdit
 	StateHelp
+	StateSnippets
+	StateStats
+	StateEditPage
+	StateOutput
 )
 
+// cacheProgressMsg reports progress of a background cache refresh
+type cacheProgressMsg cache.Progress
+
+// cacheDoneMsg reports the end of a background cache refresh
+type cacheDoneMsg struct {
+	err error
+}
+
+// cacheWatchMsg asks to check whether the cache was updated elsewhere
+type cacheWatchMsg struct{}
+
+// cacheWatchInterval is how often the TUI checks for updates made by
+// another tldrpp process
+const cacheWatchInterval = 2 * time.Second
+
 // Theme represents the UI theme
 type Theme struct {
 	Background   lipgloss.Color
@@ -46,15 +161,26 @@ type Theme struct {
 	Highlight    lipgloss.Color
 }
 
-// New creates a new TUI application
-func New(cfg *config.Config, cacheManager *cache.Manager) *App {
+// New creates a new TUI application. The execution history feeds the start
+// screen and may be nil.
+func New(cfg *config.Config, cacheManager CacheStore, executions *history.Log) *App {
 	app := &App{
-		config:    cfg,
-		cache:     cacheManager,
-		state:     StateSearch,
-		platforms: cfg.Platforms,
-		theme:     getTheme(cfg.Theme),
+		config:     cfg,
+		cache:      cacheManager,
+		executions: executions,
+		snippets:   snippet.NewStore(config.SnippetsDir()),
+		state:      StateSearch,
+		platforms:  cfg.Platforms,
+		theme:      getTheme(cfg.Theme),
+		values:     make(map[string]string),
+		suggestions: suggest.Default(cfg),
+		keys:       newKeyMap(cfg.Keymap),
+		clock:      clock.System{},
 	}
+	app.progress = components.NewProgress(newProgressBar(), app.theme.styles())
+	app.progress.Plain = accessible
+	app.toast = components.NewToast(components.DefaultToastDuration, app.theme.styles())
+	app.loadStats()
 	
 	return app
 }
@@ -62,118 +188,455 @@ func New(cfg *config.Config, cacheManager *cache.Manager) *App {
 // Run starts the TUI application
 func (a *App) Run(searchQuery string) error {
 	a.searchQuery = searchQuery
+	restore := a.restore != nil && searchQuery == ""
+	if restore {
+		a.searchQuery, a.filter = a.restore.SearchQuery, a.restore.Filter
+	}
 	
-	// Load initial pages
-	if err := a.loadPages(); err != nil {
-		return fmt.Errorf("failed to load pages: %w", err)
+	// Load initial pages; an empty cache is filled in Init instead
+	if a.cache.IsInitialized() {
+		if err := a.loadPages(); err != nil {
+			return fmt.Errorf("failed to load pages: %w", err)
+		}
+		a.loadTip()
+		a.loadChanges()
+		if restore {
+			a.restoreScreen()
+		}
+	}
+
+	options := []bubbletea.ProgramOption{bubbletea.WithAltScreen()}
+	if a.pick {
+		// Stdout carries the picked command, so draw on the terminal directly
+		options = append(options, bubbletea.WithInputTTY(), bubbletea.WithOutput(os.Stderr))
+		if searchQuery != "" {
+			a.navigate(StatePages)
+		}
 	}
 
 	// Create and run the bubbletea program
-	p := bubbletea.NewProgram(a, bubbletea.WithAltScreen())
+	p := bubbletea.NewProgram(a, options...)
 	_, err := p.Run()
 	return err
 }
 
 // Init initializes the bubbletea model
 func (a *App) Init() bubbletea.Cmd {
-	return nil
+	// Fill an empty cache, or refresh a stale one while the old pages stay usable
+	if a.cache.IsStale(a.config.CacheTTL()) {
+		_, cmd := a.refreshCache()
+		return bubbletea.Batch(cmd, watchCache(), a.showStatus())
+	}
+	return bubbletea.Batch(watchCache(), a.showStatus())
 }
 
-// Update handles bubbletea updates
+// Update handles bubbletea updates, showing the status they leave in the
+// toast
 func (a *App) Update(msg bubbletea.Msg) (bubbletea.Model, bubbletea.Cmd) {
+	a.toast, _ = a.toast.Update(msg)
+	model, cmd := a.update(msg)
+	return model, bubbletea.Batch(cmd, a.showStatus())
+}
+
+// showStatus moves a new status to the toast, returning the command
+// hiding it in time
+func (a *App) showStatus() bubbletea.Cmd {
+	if a.status == "" {
+		return nil
+	}
+	cmd := a.toast.Show(a.status)
+	a.status = ""
+	return cmd
+}
+
+// update handles a bubbletea message
+func (a *App) update(msg bubbletea.Msg) (bubbletea.Model, bubbletea.Cmd) {
 	switch msg := msg.(type) {
 	case bubbletea.KeyMsg:
+		log.Debug("key", "key", msg.String(), "state", a.state)
 		return a.handleKeyPress(msg)
 	case bubbletea.WindowSizeMsg:
 		return a.handleResize(msg)
+	case cacheProgressMsg:
+		a.showProgress(cache.Progress(msg))
+		return a, a.waitForRefresh()
+	case cacheDoneMsg:
+		return a.finishRefresh(msg.err)
+	case searchDebounceMsg:
+		return a, a.startSearch(msg.seq)
+	case searchDoneMsg:
+		return a.finishSearch(msg)
+	case presentTickMsg:
+		return a.typeNext(msg)
+	case pageEditedMsg:
+		return a.finishEdit(msg.err)
+	case settingsEditedMsg:
+		return a.finishSettings(msg.err)
+	case commandDoneMsg:
+		return a.finishCommand(msg)
+	case pluginDoneMsg:
+		if msg.err != nil {
+			a.status = fmt.Sprintf("Plugin %s failed: %v", msg.name, msg.err)
+		} else {
+			a.status = fmt.Sprintf("Plugin %s finished", msg.name)
+		}
+		return a, nil
+	case pagerClosedMsg:
+		if msg.err != nil {
+			a.status = fmt.Sprintf("Failed to run pager: %v", msg.err)
+		}
+		return a, nil
+	case cacheWatchMsg:
+		// Pick up an update made by `tldrpp update` or another TUI
+		if !a.refreshing && a.cache.Changed() {
+			a.status = "Cache updated by another tldrpp process"
+			a.reloadPages()
+		}
+		return a, watchCache()
+	}
+	if a.editor != nil {
+		// Keep the editor's cursor blinking
+		var cmd bubbletea.Cmd
+		a.editor.area, cmd = a.editor.area.Update(msg)
+		return a, cmd
 	}
 	return a, nil
 }
 
 // View renders the TUI
 func (a *App) View() string {
+	if a.present != nil {
+		return a.renderPresentation()
+	}
+	var view string
 	switch a.state {
 	case StateSearch:
-		return a.renderSearch()
+		view = a.renderSearch()
 	case StatePages:
-		return a.renderPages()
+		view = a.renderPages()
 	case StateExamples:
-		return a.renderExamples()
+		view = a.renderExamples()
 	case StateEdit:
-		return a.renderEdit()
+		view = a.renderEdit()
 	case StateHelp:
-		return a.renderHelp()
+		view = a.renderHelp()
+	case StateSnippets:
+		view = a.renderSnippets()
+	case StateStats:
+		view = a.renderStats()
+	case StateEditPage:
+		view = a.renderPageEditor()
+	case StateOutput:
+		view = a.renderOutputs()
 	default:
-		return a.renderSearch()
+		view = a.renderSearch()
+	}
+	if a.palette != nil && accessible {
+		// Screen readers get the palette instead of a box drawn over the screen
+		view = a.renderPalette()
+	}
+	if a.confirm != nil && accessible {
+		view = a.confirm.view(a.width)
+	}
+	if a.qr != nil && accessible {
+		view = a.renderQR()
+	}
+	if accessible {
+		view += a.renderSelection()
+	}
+	view = a.renderBreadcrumb() + view + a.renderStatus()
+	if a.palette != nil && !accessible {
+		view = a.overlayPalette(view)
 	}
+	if a.confirm != nil && !accessible {
+		view = components.Overlay(view, a.confirm.view(a.width), a.width)
+	}
+	if a.qr != nil && !accessible {
+		view = components.Overlay(view, a.renderQR(), a.width)
+	}
+	return fitHyperlinks(view, a.width)
 }
 
 // handleKeyPress handles keyboard input
 func (a *App) handleKeyPress(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q":
+	if a.confirm != nil {
+		return a.handleConfirmKey(msg)
+	}
+	if a.qr != nil {
+		return a.handleQRKey(msg)
+	}
+	if a.present != nil {
+		return a.handlePresentKey(msg)
+	}
+	if a.palette != nil {
+		return a.handlePaletteKey(msg)
+	}
+	if a.filtering {
+		return a.handleFilterKey(msg)
+	}
+	if a.naming != nil {
+		return a.handleNameKey(msg)
+	}
+	if a.outputPath != nil {
+		return a.handleOutputPathKey(msg)
+	}
+	if a.workDirPrompt != nil {
+		return a.handleWorkDirKey(msg)
+	}
+	if a.typing {
+		return a.handleValueKey(msg)
+	}
+	if a.editor != nil {
+		return a.handleEditorKey(msg)
+	}
+	if a.relatedFocus {
+		return a.handleRelatedKey(msg)
+	}
+	if a.linkFocus {
+		return a.handleLinkKey(msg)
+	}
+
+	// The keymap keys come first and only match where they apply, so that
+	// they can be set to any key
+	switch {
+	case key.Matches(msg, a.keys.Run) && (a.state == StateExamples || a.state == StateEdit):
+		a.recordExample()
+		return a.executeCommand(false)
+	case key.Matches(msg, a.keys.RunCapture) && (a.state == StateExamples || a.state == StateEdit):
+		a.recordExample()
+		return a.executeCommand(true)
+	case key.Matches(msg, a.keys.Sudo) && (a.state == StateExamples || a.state == StateEdit):
+		a.recordExample()
+		return a.executeWithSudo()
+	case key.Matches(msg, a.keys.Copy) && (a.state == StateExamples || a.state == StateEdit):
+		if a.state == StateExamples && len(a.marked) > 0 {
+			a.copyMarked()
+		} else {
+			a.recordExample()
+			return a.copyCommand()
+		}
+	case key.Matches(msg, a.keys.Copy) && a.state == StateOutput:
+		a.copyOutput()
+	case key.Matches(msg, a.keys.CopyTemplate) && (a.state == StateExamples || a.state == StateEdit):
+		return a.copyAs(copyTemplate)
+	case key.Matches(msg, a.keys.CopyMarkdown) && (a.state == StateExamples || a.state == StateEdit):
+		return a.copyAs(copyMarkdown)
+	case key.Matches(msg, a.keys.Paste) && (a.state == StateExamples || a.state == StateEdit):
+		a.recordExample()
+		return a.pasteCommand()
+	case key.Matches(msg, a.keys.QR) && (a.state == StateExamples || a.state == StateEdit):
+		a.showQR()
+	case key.Matches(msg, a.keys.WorkDir) && (a.state == StateExamples || a.state == StateEdit):
+		a.openWorkDir()
+	case key.Matches(msg, a.keys.Present) && (a.state == StateExamples || a.state == StateEdit):
+		return a.startPresentation()
+	case key.Matches(msg, a.keys.Quit):
 		return a, bubbletea.Quit
-	case "?":
+	case key.Matches(msg, a.keys.Palette):
+		a.openPalette()
+	case key.Matches(msg, a.keys.Help):
 		if a.state == StateHelp {
-			a.state = StateSearch
+			a.back()
 		} else {
-			a.state = StateHelp
+			a.navigate(StateHelp)
+			a.cacheInfo, _ = a.cache.Info(a.config.CacheTTL())
 		}
-	case "enter":
+	case key.Matches(msg, a.keys.Hints):
+		a.allHints = !a.allHints
+	case key.Matches(msg, a.keys.Select):
 		if a.state == StateSearch {
-			a.state = StatePages
+			a.navigate(StatePages)
 		} else if a.state == StatePages {
-			a.state = StateExamples
+			a.openPage()
+		} else if a.state == StateSnippets {
+			return a.runSnippet()
+		} else if a.pick && (a.state == StateExamples || a.state == StateEdit) {
+			a.recordExample()
+			return a.pickCommand()
+		} else if a.state == StateEdit {
+			a.startTyping()
 		}
-	case "esc":
-		switch a.state {
-		case StatePages:
-			a.state = StateSearch
-		case StateExamples:
-			a.state = StatePages
-		case StateEdit:
-			a.state = StateExamples
-		case StateHelp:
-			a.state = StateSearch
-		}
-	case "tab":
+	case key.Matches(msg, a.keys.Type, a.keys.EditPage):
+		if a.state == StateEdit {
+			a.startTyping()
+		} else if a.state == StatePages || a.state == StateExamples {
+			return a.editPage()
+		}
+	case key.Matches(msg, a.keys.RawPager):
 		if a.state == StateExamples {
-			a.state = StateEdit
+			return a.openInPager(true)
 		}
-	case "ctrl+enter":
-		if a.state == StateExamples || a.state == StateEdit {
-			return a.executeCommand()
+	case key.Matches(msg, a.keys.EditInTUI):
+		if a.state == StatePages || a.state == StateExamples {
+			return a.openPageEditor()
 		}
-	case "y":
-		if a.state == StateExamples || a.state == StateEdit {
-			return a.copyCommand()
+	case key.Matches(msg, a.keys.Back):
+		a.back()
+	case key.Matches(msg, a.keys.Field):
+		if a.state == StateExamples {
+			a.navigate(StateEdit)
+			a.fieldIdx = 0
+		} else if a.state == StateEdit {
+			a.moveField(1)
 		}
-	case "p":
-		if a.state == StateExamples || a.state == StateEdit {
-			return a.pasteCommand()
+	case key.Matches(msg, a.keys.PrevField):
+		if a.state == StateEdit {
+			a.moveField(-1)
+		}
+	case key.Matches(msg, a.keys.Prev):
+		if a.state == StateEdit {
+			a.cycleChoice(-1)
+		}
+	case key.Matches(msg, a.keys.Next):
+		if a.state == StateEdit {
+			a.cycleChoice(1)
+		}
+	case key.Matches(msg, a.keys.Undo):
+		if a.state == StateEdit {
+			a.undoChange()
 		}
-	case "r":
+	case key.Matches(msg, a.keys.Redo):
+		if a.state == StateEdit {
+			a.redoChange()
+		}
+	case key.Matches(msg, a.keys.Reset):
+		if a.state == StateEdit {
+			a.resetDefaults()
+		}
+	case key.Matches(msg, a.keys.Memory):
+		if a.state == StateEdit {
+			a.cycleMemory()
+		}
+	case key.Matches(msg, a.keys.Mark):
+		if a.state == StateExamples {
+			a.toggleMark()
+		}
+	case key.Matches(msg, a.keys.Follow):
+		if a.state == StateExamples && len(a.links) > 0 {
+			a.linkFocus = true
+		}
+	case key.Matches(msg, a.keys.Refresh, a.keys.Related):
 		if a.state == StateSearch {
 			return a.refreshCache()
+		} else if a.state == StateExamples && len(a.related) > 0 {
+			a.relatedFocus = true
+		}
+	case key.Matches(msg, a.keys.Cancel):
+		if a.refreshing {
+			a.cancel()
+		}
+	case key.Matches(msg, a.keys.Install):
+		if a.state == StateExamples && a.installCommand != "" {
+			return a.runInstall()
+		}
+	case key.Matches(msg, a.keys.CopyInstall):
+		if a.state == StateExamples && a.installCommand != "" {
+			a.copyInstall()
+		}
+	case key.Matches(msg, a.keys.Save):
+		if a.state == StateExamples || a.state == StateEdit {
+			a.startNaming()
+		}
+	case key.Matches(msg, a.keys.Snippets):
+		if a.state == StateSearch || a.state == StatePages {
+			a.openSnippets()
+		}
+	case key.Matches(msg, a.keys.Stats):
+		if a.state == StateSearch || a.state == StatePages {
+			a.navigate(StateStats)
+		}
+	case key.Matches(msg, a.keys.Random):
+		if a.state == StateSearch || a.state == StatePages {
+			a.surpriseMe()
+		}
+	case key.Matches(msg, a.keys.Tip):
+		if a.state == StateSearch && a.tip != nil {
+			a.jumpTo(a.tip.Name)
 		}
-	case "o":
+	case key.Matches(msg, a.keys.News):
+		if a.state == StateSearch && a.changes != nil {
+			a.showChanges()
+		}
+	case key.Matches(msg, a.keys.Last):
+		if a.state == StateSearch || a.state == StatePages || a.state == StateExamples || a.state == StateOutput {
+			return a.runLast()
+		}
+	case key.Matches(msg, a.keys.Outputs):
+		if a.state != StateOutput {
+			a.openOutputs()
+		}
+	case key.Matches(msg, a.keys.SaveOutput):
+		if a.state == StateOutput {
+			input := components.NewInput("Save output to: ", "", "(Enter Save, Esc Cancel)", a.theme.styles())
+			a.outputPath = &input
+		}
+	case key.Matches(msg, a.keys.Compare):
+		if a.state == StateOutput {
+			if a.outputIdx == 0 {
+				a.status = "No earlier output to compare with"
+			} else {
+				a.comparing = !a.comparing
+			}
+		}
+	case key.Matches(msg, a.keys.Delete):
+		if a.state == StateSnippets {
+			a.removeSnippet()
+		}
+	case key.Matches(msg, a.keys.Pager, a.keys.Override):
+		if a.state == StateExamples {
+			return a.openInPager(false)
+		} else if a.state == StateEdit {
+			a.toggleOverride()
+		}
+	case key.Matches(msg, a.keys.Browser):
 		if a.state == StateExamples {
-			return a.openInPager()
+			return a.openInBrowser()
 		}
-	case "a":
+	case key.Matches(msg, a.keys.Filter):
 		if a.state == StatePages {
-			a.toggleAllPlatforms()
+			a.filtering = true
+		}
+	case key.Matches(msg, a.keys.Preview):
+		if a.state == StatePages {
+			a.singlePane = !a.singlePane
 		}
-	case "1", "2", "3", "4", "5", "6":
+	case key.Matches(msg, a.keys.AllPlatforms):
 		if a.state == StatePages {
+			a.toggleAllPlatforms()
+		}
+	case key.Matches(msg, a.keys.Recent, a.keys.Platform):
+		switch a.state {
+		case StateSearch:
+			return a.rerunRecent(msg.String())
+		case StatePages:
 			a.togglePlatform(msg.String())
 		}
-	case "up", "k":
-		if a.selectedIdx > 0 {
+	case key.Matches(msg, a.keys.Up):
+		if a.state == StateSnippets {
+			a.snippetRows, _ = a.snippetRows.Update(msg)
+		} else if a.state == StateOutput {
+			if a.outputIdx > 0 {
+				a.outputIdx--
+			}
+		} else if a.state == StateExamples {
+			if a.exampleIdx > 0 {
+				a.exampleIdx--
+			}
+		} else if a.selectedIdx > 0 {
 			a.selectedIdx--
 		}
-	case "down", "j":
-		if a.selectedIdx < len(a.pages)-1 {
+	case key.Matches(msg, a.keys.Down):
+		if a.state == StateSnippets {
+			a.snippetRows, _ = a.snippetRows.Update(msg)
+		} else if a.state == StateOutput {
+			if a.outputIdx < len(a.outputs)-1 {
+				a.outputIdx++
+			}
+		} else if a.state == StateExamples {
+			if page := a.selectedPage(); page != nil && a.exampleIdx < len(page.Examples)-1 {
+				a.exampleIdx++
+			}
+		} else if a.selectedIdx < len(a.pages)-1 {
 			a.selectedIdx++
 		}
 	}
@@ -183,20 +646,44 @@ func (a *App) handleKeyPress(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.C
 
 // handleResize handles window resize events
 func (a *App) handleResize(msg bubbletea.WindowSizeMsg) (bubbletea.Model, bubbletea.Cmd) {
+	a.width = msg.Width
+	a.height = msg.Height
 	return a, nil
 }
 
-// loadPages loads pages based on current search query and platforms
+// loadPages lists the pages matching the current search query and
+// platforms. Only the index is read; a page is parsed once it is shown.
 func (a *App) loadPages() error {
-	pages, err := a.cache.SearchPages(a.searchQuery, a.platforms)
+	pages, err := a.cache.ListPages(a.searchQuery, a.platforms)
 	if err != nil {
 		return err
 	}
-	a.pages = pages
-	a.selectedIdx = 0
+	a.allPages = pages
+	a.applyFilter()
 	return nil
 }
 
+// reloadPages loads the pages again after the cache changed, keeping the
+// selected page selected
+func (a *App) reloadPages() {
+	var selected string
+	if a.selectedIdx < len(a.pages) {
+		selected = a.pages[a.selectedIdx].Platform + "/" + a.pages[a.selectedIdx].Name
+	}
+
+	if err := a.loadPages(); err != nil {
+		a.status = fmt.Sprintf("Failed to load pages: %v", err)
+		return
+	}
+
+	for i, page := range a.pages {
+		if page.Platform+"/"+page.Name == selected {
+			a.selectedIdx = i
+			break
+		}
+	}
+}
+
 // renderSearch renders the search interface
 func (a *App) renderSearch() string {
 	var content strings.Builder
@@ -210,25 +697,32 @@ func (a *App) renderSearch() string {
 	content.WriteString(title + "\n\n")
 	
 	// Search box
-	searchBox := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+	searchBox := frame(lipgloss.NewStyle()).
 		BorderForeground(a.theme.Border).
 		Padding(1, 2).
 		Render(fmt.Sprintf("Search: %s", a.searchQuery))
 	
 	content.WriteString(searchBox + "\n\n")
 	
-	// Instructions
-	instructions := lipgloss.NewStyle().
-		Foreground(a.theme.Foreground).
-		Render("Press Enter to search, ? for help, q to quit")
+	// Recently run commands and a page to discover when started without
+	// a query
+	if recent := a.renderRecent(); recent != "" {
+		content.WriteString(recent + "\n")
+	}
+	if changes := a.renderChanges(); changes != "" {
+		content.WriteString(changes + "\n")
+	}
+	if tip := a.renderTip(); tip != "" {
+		content.WriteString(tip + "\n")
+	}
 	
-	content.WriteString(instructions)
+	content.WriteString(a.renderHints())
 	
 	return content.String()
 }
 
-// renderPages renders the pages list
+// renderPages renders the pages list, with a preview of the selected page
+// alongside it when the terminal is wide enough
 func (a *App) renderPages() string {
 	var content strings.Builder
 	
@@ -243,81 +737,98 @@ func (a *App) renderPages() string {
 	// Platform filters
 	platforms := lipgloss.NewStyle().
 		Foreground(a.theme.Foreground).
-		Render(fmt.Sprintf("Platforms: %s", strings.Join(a.platforms, ", ")))
+		Render("Platforms: " + a.platformSummary())
 	
-	content.WriteString(platforms + "\n\n")
+	content.WriteString(platforms + "\n")
+	content.WriteString(a.renderFilter() + "\n\n")
 	
-	// Pages list
-	for i, page := range a.pages {
-		style := lipgloss.NewStyle().Foreground(a.theme.Foreground)
-		if i == a.selectedIdx {
-			style = style.Background(a.theme.Highlight).Foreground(a.theme.Background)
-		}
-		
-		pageText := fmt.Sprintf("%s - %s (%s)", page.Name, page.Description, page.Platform)
-		content.WriteString(style.Render(pageText) + "\n")
+	// Pages list, side by side with the preview in split view
+	if a.splitView() {
+		content.WriteString(a.renderSplit())
+	} else {
+		content.WriteString(a.renderPageList(0))
 	}
 	
-	// Footer
-	footer := lipgloss.NewStyle().
-		Foreground(a.theme.Foreground).
-		Render("↑↓ Navigate, Enter Select, Esc Back, ? Help")
-	
-	content.WriteString("\n" + footer)
+	content.WriteString("\n" + a.renderHints())
 	
 	return content.String()
 }
 
 // renderExamples renders the examples for the selected page
 func (a *App) renderExamples() string {
-	if len(a.pages) == 0 || a.selectedIdx >= len(a.pages) {
+	page := a.selectedPage()
+	if page == nil {
 		return "No pages available"
 	}
 	
-	page := a.pages[a.selectedIdx]
 	var content strings.Builder
 	
-	// Header
-	header := lipgloss.NewStyle().
+	// Header, with the commands in descriptions that have a page as links
+	links := 0
+	headerStyle := lipgloss.NewStyle().
 		Foreground(a.theme.Accent).
-		Bold(true).
-		Render(fmt.Sprintf("%s - %s", page.Name, page.Description))
+		Bold(true)
+	header := headerStyle.Render(page.Name+" - ") + a.linkify(page.Description, headerStyle, &links)
 	
-	content.WriteString(header + "\n\n")
+	content.WriteString(header + a.languageBadge(page) + a.installBadge() + "\n")
 	
-	// Examples
+	// Related commands and documentation link
+	meta := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	if len(page.SeeAlso) > 0 {
+		content.WriteString(meta.Render("See also: "+strings.Join(page.SeeAlso, ", ")) + "\n")
+	}
+	if page.MoreInfoURL != "" {
+		content.WriteString(meta.Render("More information: ") + hyperlink(page.MoreInfoURL, meta.Render(page.MoreInfoURL)) + "\n")
+	}
+	content.WriteString(a.renderInstall())
+	content.WriteString(a.renderWorkDir())
+	content.WriteString(a.renderNeedsRoot())
+	if subcommands := a.cache.Subcommands(page.Name); len(subcommands) > 0 {
+		more := ""
+		if len(subcommands) > 8 {
+			more = fmt.Sprintf(" and %d more", len(subcommands)-8)
+			subcommands = subcommands[:8]
+		}
+		content.WriteString(meta.Render("Subcommands: "+strings.Join(subcommands, ", ")+more) + "\n")
+	}
+	content.WriteString(a.renderRelated())
+	content.WriteString("\n")
+	
+	// Examples, with the current one highlighted and marked ones ticked
 	for i, example := range page.Examples {
 		style := lipgloss.NewStyle().Foreground(a.theme.Foreground)
-		if i == 0 { // Highlight first example
+		if i == a.exampleIdx {
 			style = style.Background(a.theme.Highlight).Foreground(a.theme.Background)
 		}
 		
-		exampleText := fmt.Sprintf("%s\n  %s", example.Description, example.Command)
-		content.WriteString(style.Render(exampleText) + "\n\n")
+		mark := ""
+		if len(a.marked) > 0 {
+			mark = "[ ] "
+			if a.marked[i] {
+				mark = "[x] "
+			}
+		}
+		content.WriteString(selectMark(i == a.exampleIdx) + style.Render(mark) + a.linkify(example.Description, style, &links) + "\n")
+		content.WriteString(selectMark(false) + style.Render("  "+example.Command) + "\n\n")
 	}
 	
-	// Footer
-	footer := lipgloss.NewStyle().
-		Foreground(a.theme.Foreground).
-		Render("Tab Edit, Ctrl+Enter Run, y Copy, p Paste, Esc Back")
-	
-	content.WriteString(footer)
+	content.WriteString(a.renderHints())
 	
 	return content.String()
 }
 
 // renderEdit renders the placeholder editing interface
 func (a *App) renderEdit() string {
-	if len(a.pages) == 0 || a.selectedIdx >= len(a.pages) {
+	page := a.selectedPage()
+	if page == nil {
 		return "No pages available"
 	}
 	
-	page := a.pages[a.selectedIdx]
 	if len(page.Examples) == 0 {
 		return "No examples available"
 	}
 	
-	example := page.Examples[0] // Use first example for now
+	example := *a.currentExample()
 	var content strings.Builder
 	
 	// Header
@@ -326,12 +837,12 @@ func (a *App) renderEdit() string {
 		Bold(true).
 		Render(fmt.Sprintf("Edit: %s", example.Description))
 	
-	content.WriteString(header + "\n\n")
+	content.WriteString(header + "\n" + a.renderWorkDir() + a.renderNeedsRoot() + "\n")
 	
 	// Command with placeholders
 	command := example.Command
 	for _, placeholder := range example.Placeholders {
-		placeholderText := fmt.Sprintf("{{%s}}", placeholder.Name)
+		placeholderText := fmt.Sprintf("{{%s}}", placeholder.Token())
 		highlighted := lipgloss.NewStyle().
 			Background(a.theme.Warning).
 			Foreground(a.theme.Background).
@@ -339,14 +850,16 @@ func (a *App) renderEdit() string {
 		command = strings.Replace(command, placeholderText, highlighted, 1)
 	}
 	
-	commandBox := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+	commandBox := frame(lipgloss.NewStyle()).
 		BorderForeground(a.theme.Border).
 		Padding(1, 2).
 		Render(command)
 	
 	content.WriteString(commandBox + "\n\n")
 	
+	// What the entered values change in the command
+	content.WriteString(a.renderDiff(&example) + "\n")
+	
 	// Placeholders
 	if len(example.Placeholders) > 0 {
 		placeholders := lipgloss.NewStyle().
@@ -354,19 +867,48 @@ func (a *App) renderEdit() string {
 			Render("Placeholders:")
 		content.WriteString(placeholders + "\n")
 		
-		for _, placeholder := range example.Placeholders {
-			placeholderText := fmt.Sprintf("  %s (%s): %s", 
-				placeholder.Name, placeholder.Type, placeholder.Default)
+		for i, placeholder := range example.Placeholders {
+			marker := "  "
+			if i == a.fieldIdx {
+				marker = "> "
+			}
+
+			if len(placeholder.Choices) > 0 {
+				content.WriteString(fmt.Sprintf("%s%s (one of)%s%s:\n", marker, placeholder.Name, a.scopeLabel(placeholder.Name), a.memoryLabel(placeholder)))
+				selected := a.valueFor(placeholder)
+				for _, choice := range placeholder.Choices {
+					style := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+					bullet := "    ( ) "
+					if choice == selected {
+						style = style.Foreground(a.theme.Accent).Bold(true)
+						bullet = "    " + Glyph("(•)", "(*)") + " "
+					}
+					content.WriteString(style.Render(bullet+choice) + "\n")
+				}
+				continue
+			}
+
+			kind := placeholder.Type
+			if placeholder.Variadic {
+				kind += ", multiple"
+			}
+			value := a.displayValue(placeholder)
+			if a.typing && i == a.fieldIdx {
+				value = a.typed + Glyph("█", "_")
+				if placeholder.Secret() {
+					value = strings.Repeat(Glyph("•", "*"), len([]rune(a.typed))) + Glyph("█", "_")
+				}
+			}
+			placeholderText := fmt.Sprintf("%s%s (%s): %s%s%s", 
+				marker, placeholder.Name, kind, value, a.scopeLabel(placeholder.Name), a.memoryLabel(placeholder))
 			content.WriteString(placeholderText + "\n")
+			if a.typing && i == a.fieldIdx {
+				content.WriteString(a.renderSuggestions(placeholder))
+			}
 		}
 	}
 	
-	// Footer
-	footer := lipgloss.NewStyle().
-		Foreground(a.theme.Foreground).
-		Render("Ctrl+Enter Run, y Copy, p Paste, Esc Back")
-	
-	content.WriteString("\n" + footer)
+	content.WriteString("\n" + a.renderHints())
 	
 	return content.String()
 }
@@ -384,56 +926,174 @@ func (a *App) renderHelp() string {
 	content.WriteString(title + "\n\n")
 	
 	// Keybindings
+	k := a.keys
 	keybindings := []struct {
-		key, description string
+		key         key.Binding
+		description string
 	}{
-		{"Enter", "Accept example / Select page"},
-		{"Tab", "Edit placeholders"},
-		{"Ctrl+Enter", "Run command (safe)"},
-		{"y", "Copy to clipboard"},
-		{"p", "Paste to terminal"},
-		{"1-6", "Toggle platform filters"},
-		{"a", "Toggle all platforms"},
-		{"r", "Refresh cache"},
-		{"o", "Open in pager"},
-		{"?", "Show/hide help"},
-		{"Esc", "Go back"},
-		{"q", "Quit"},
+		{k.Select, "Accept example / Select page"},
+		{k.Field, "Edit placeholders"},
+		{k.Run, "Run command (safe)"},
+		{k.RunCapture, "Run command and keep its output for the output history; its output is piped, not written to the terminal"},
+		{k.WorkDir, "Choose the directory commands run in, from the recent ones or typed"},
+		{k.Sudo, "Run command as root with sudo, which asks for a password in the terminal"},
+		{k.Copy, "Copy to clipboard"},
+		{k.CopyTemplate, "Copy the command as written on the page, with its placeholders"},
+		{k.CopyMarkdown, "Copy the description and command as a markdown block"},
+		{k.Paste, "Paste to terminal"},
+		{k.Outputs, fmt.Sprintf("Browse the output of commands run with %s (%s copies, %s saves, %s compares)", k.RunCapture.Help().Key, k.Copy.Help().Key, k.SaveOutput.Help().Key, k.Compare.Help().Key)},
+		{k.Type, "Type the focused placeholder's value (edit view)"},
+		{k.Override, "Give the focused placeholder its own value in this example"},
+		{k.Undo, "Undo a placeholder change (edit view)"},
+		{k.Redo, "Redo an undone placeholder change (edit view)"},
+		{k.Reset, "Reset the example's placeholders to their defaults (edit view)"},
+		{k.Memory, "Remember, always ask for or forget the focused placeholder's values"},
+		{k.EditPage, "Edit a custom page in $EDITOR"},
+		{k.EditInTUI, fmt.Sprintf("Edit a custom page in the TUI (lint on %s)", k.Write.Help().Key)},
+		{k.Mark, fmt.Sprintf("Mark example (%s copies, %s saves the marked ones)", k.Copy.Help().Key, k.Save.Help().Key)},
+		{k.Platform, "Toggle platform filters"},
+		{k.Recent, "Run a recent command again (start screen)"},
+		{k.Last, "Run the last command again, asking for the values set to be asked"},
+		{k.AllPlatforms, "Toggle all platforms"},
+		{k.Filter, "Filter pages by name"},
+		{k.Preview, "Toggle page preview pane"},
+		{k.Refresh, "Refresh cache (start screen) / Jump to a related page"},
+		{k.Follow, "Follow a command mentioned in a description"},
+		{k.Install, fmt.Sprintf("Install the command of a page marked not installed (%s copies the install command)", k.CopyInstall.Help().Key)},
+		{k.Cancel, "Cancel cache refresh"},
+		{k.Save, "Save example as a snippet"},
+		{k.Snippets, "Browse snippets"},
+		{k.Stats, "Show usage stats"},
+		{k.Random, "Surprise me: open a random page"},
+		{k.Tip, "Open the tip of the day (start screen)"},
+		{k.News, "List the pages the last update added or changed (start screen)"},
+		{key.NewBinding(key.WithHelp(k.Pager.Help().Key+" / "+k.RawPager.Help().Key, "")), "Open the page in the pager (rendered / raw markdown)"},
+		{k.Browser, "Open more information in browser"},
+		{k.Palette, "Command palette: find and run any action"},
+		{k.Hints, "Show more or fewer keys in the hint bar"},
+		{k.Help, "Show/hide help"},
+		{k.Back, "Go back to the previous screen"},
+		{k.Quit, "Quit"},
 	}
 	
 	for _, kb := range keybindings {
 		key := lipgloss.NewStyle().
 			Foreground(a.theme.Accent).
 			Bold(true).
-			Render(kb.key)
+			Render(kb.key.Help().Key)
 		desc := lipgloss.NewStyle().
 			Foreground(a.theme.Foreground).
 			Render(kb.description)
 		content.WriteString(fmt.Sprintf("%-15s %s\n", key, desc))
 	}
 	
-	// Footer
-	footer := lipgloss.NewStyle().
-		Foreground(a.theme.Foreground).
-		Render("Press ? to close help")
+	// Cache panel
+	if a.cacheInfo != nil {
+		content.WriteString("\n" + a.renderCacheInfo())
+	}
 	
-	content.WriteString("\n" + footer)
+	content.WriteString("\n" + a.renderHints())
 	
 	return content.String()
 }
 
-// executeCommand executes the current command
-func (a *App) executeCommand() (bubbletea.Model, bubbletea.Cmd) {
-	// This would execute the command
-	// For now, just show a message
-	return a, bubbletea.Quit
+// renderCacheInfo renders the cache statistics shown on the help screen
+func (a *App) renderCacheInfo() string {
+	info := a.cacheInfo
+	freshness := "fresh"
+	if info.Stale {
+		freshness = "stale, press r to refresh"
+	}
+	source := info.Source
+	if source == "" {
+		source = "unknown"
+	}
+
+	platforms := make([]string, 0, len(info.Platforms))
+	for platform, count := range info.Platforms {
+		platforms = append(platforms, fmt.Sprintf("%s %d", platform, count))
+	}
+	sort.Strings(platforms)
+
+	title := lipgloss.NewStyle().
+		Foreground(a.theme.Accent).
+		Bold(true).
+		Render("Cache")
+	text := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	lines := []string{
+		fmt.Sprintf("%d pages, %s on disk", info.Pages, info.Size()),
+		"Platforms: " + strings.Join(platforms, ", "),
+		fmt.Sprintf("Updated %s (%s)", info.UpdatedAt.Format("2006-01-02 15:04"), freshness),
+		"Source: " + source,
+	}
+
+	return title + "\n" + text.Render(strings.Join(lines, "\n")) + "\n"
+}
+
+// renderStatus renders the status bar with the cache refresh progress or
+// the outcome of the last action, for a while
+func (a *App) renderStatus() string {
+	switch {
+	case a.naming != nil:
+		return "\n\n" + a.naming.View()
+	case a.workDirPrompt != nil:
+		return "\n\n" + a.renderWorkDirPrompt()
+	case a.refreshing:
+		return "\n\n" + a.progress.View()
+	case a.toast.Text != "":
+		return "\n\n" + a.toast.View()
+	default:
+		return ""
+	}
+}
+
+// executeCommand executes the current command, keeping its output for the
+// output history when capture is set
+func (a *App) executeCommand(capture bool) (bubbletea.Model, bubbletea.Cmd) {
+	return a.executeExample(capture, false)
+}
+
+// executeExample executes the current command like executeCommand, as root
+// with sudo when asRoot is set
+func (a *App) executeExample(capture, asRoot bool) (bubbletea.Model, bubbletea.Cmd) {
+	page, example := a.selectedPage(), a.currentExample()
+	if page == nil || example == nil {
+		return a, nil
+	}
+	vars := a.currentVars()
+	execution := history.Execution{
+		Page:     page.Name,
+		Platform: page.Platform,
+		Command:  example.Render(vars),
+		Template: example.Command,
+		Vars:     vars,
+		Dir:      a.workDir,
+		Sudo:     asRoot,
+	}
+	if a.runner == nil {
+		// Run in the terminal once the TUI is gone
+		a.rerun = &execution
+		return a, bubbletea.Quit
+	}
+	return a.runInTerminal(execution, capture)
 }
 
 // copyCommand copies the current command to clipboard
 func (a *App) copyCommand() (bubbletea.Model, bubbletea.Cmd) {
-	// This would copy to clipboard
-	// For now, just show a message
-	return a, bubbletea.Quit
+	example := a.currentExample()
+	if example == nil {
+		return a, nil
+	}
+	if !a.config.Clipboard {
+		a.status = "Clipboard is disabled in the config"
+		return a, nil
+	}
+	if err := CopyToClipboard(example.Render(a.currentVars())); err != nil {
+		a.status = err.Error()
+		return a, nil
+	}
+	a.status = "Copied to clipboard"
+	return a, nil
 }
 
 // pasteCommand pastes the current command to terminal
@@ -443,44 +1103,234 @@ func (a *App) pasteCommand() (bubbletea.Model, bubbletea.Cmd) {
 	return a, bubbletea.Quit
 }
 
-// refreshCache refreshes the pages cache
+// refreshCache downloads the pages in the background, reporting progress
+// through messages so the UI stays responsive
 func (a *App) refreshCache() (bubbletea.Model, bubbletea.Cmd) {
-	// This would refresh the cache
-	// For now, just reload pages
-	a.loadPages()
+	if a.refreshing {
+		return a, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan bubbletea.Msg, 1)
+	a.refreshing = true
+	a.showProgress(cache.Progress{})
+	a.cancel = cancel
+	a.updates = updates
+	a.status = ""
+
+	go func() {
+		err := a.cache.UpdateContext(ctx, func(p cache.Progress) {
+			// Drop updates while the UI is still busy with the last one
+			select {
+			case updates <- cacheProgressMsg(p):
+			default:
+			}
+		})
+		updates <- cacheDoneMsg{err: err}
+	}()
+
+	return a, a.waitForRefresh()
+}
+
+// showProgress shows how far a refresh got in the status bar
+func (a *App) showProgress(p cache.Progress) {
+	text := p.String()
+	if accessible {
+		text = progressText(p)
+	}
+	a.progress, _ = a.progress.Update(components.ProgressMsg{Fraction: p.Fraction(), Text: text + "  (x Cancel)"})
+}
+
+// waitForRefresh waits for the next message from a running refresh
+func (a *App) waitForRefresh() bubbletea.Cmd {
+	updates := a.updates
+	return func() bubbletea.Msg {
+		return <-updates
+	}
+}
+
+// watchCache schedules the next check for external cache updates
+func watchCache() bubbletea.Cmd {
+	return bubbletea.Tick(cacheWatchInterval, func(time.Time) bubbletea.Msg {
+		return cacheWatchMsg{}
+	})
+}
+
+// finishRefresh reloads the pages once a refresh is done
+func (a *App) finishRefresh(err error) (bubbletea.Model, bubbletea.Cmd) {
+	a.refreshing = false
+	a.cancel()
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		a.status = "Cache refresh cancelled"
+	case err != nil:
+		a.status = fmt.Sprintf("Cache refresh failed: %v", err)
+	default:
+		a.status = "Cache updated"
+		a.reloadPages()
+		a.loadTip()
+		a.loadChanges()
+	}
+
 	return a, nil
 }
 
-// openInPager opens the current page in a pager
-func (a *App) openInPager() (bubbletea.Model, bubbletea.Cmd) {
-	// This would open in pager
-	// For now, just show a message
-	return a, bubbletea.Quit
+// selectedPage loads the selected page, or returns nil if there is none or
+// it can't be read
+func (a *App) selectedPage() *types.Page {
+	if len(a.pages) == 0 || a.selectedIdx >= len(a.pages) {
+		return nil
+	}
+	page, err := a.cache.LoadPage(a.pages[a.selectedIdx])
+	if err != nil {
+		return nil
+	}
+	return page
+}
+
+// currentExample returns the example being edited, if any
+func (a *App) currentExample() *types.Example {
+	page := a.selectedPage()
+	if page == nil || len(page.Examples) == 0 {
+		return nil
+	}
+	if a.exampleIdx >= len(page.Examples) {
+		a.exampleIdx = len(page.Examples) - 1
+	}
+	return &page.Examples[a.exampleIdx]
+}
+
+// currentVars returns the values entered for the current example's
+// placeholders
+func (a *App) currentVars() map[string]string {
+	example := a.currentExample()
+	if example == nil {
+		return make(map[string]string)
+	}
+	return a.varsFor(a.exampleIdx, example)
+}
+
+// varsFor returns the values entered for the placeholders of the example
+// at index i of the selected page
+func (a *App) varsFor(i int, example *types.Example) map[string]string {
+	vars := make(map[string]string)
+	for _, placeholder := range example.Placeholders {
+		if value := a.valueAt(i, placeholder); value != "" {
+			vars[placeholder.Name] = value
+		}
+	}
+	return vars
+}
+
+// valueFor returns the value entered for a placeholder of the current
+// example, or its default
+func (a *App) valueFor(placeholder types.Placeholder) string {
+	return a.valueAt(a.exampleIdx, placeholder)
+}
+
+// valueAt returns the value entered for a placeholder of the example at
+// index i: its own value first, then the page's, then the default
+func (a *App) valueAt(i int, placeholder types.Placeholder) string {
+	if value, ok := a.overrides[i][placeholder.Name]; ok {
+		if value != "" {
+			return value
+		}
+		return placeholder.Default
+	}
+	if value := a.values[placeholder.Name]; value != "" {
+		return value
+	}
+	return placeholder.Default
+}
+
+// displayValue returns the value shown for a placeholder in the edit form,
+// masking secrets
+func (a *App) displayValue(placeholder types.Placeholder) string {
+	value := a.valueFor(placeholder)
+	if placeholder.Secret() {
+		return strings.Repeat(Glyph("•", "*"), len([]rune(value)))
+	}
+	return value
 }
 
-// toggleAllPlatforms toggles all platform filters
+// moveField moves the edit focus between placeholders, wrapping around
+func (a *App) moveField(delta int) {
+	example := a.currentExample()
+	if example == nil || len(example.Placeholders) == 0 {
+		return
+	}
+	n := len(example.Placeholders)
+	a.fieldIdx = (a.fieldIdx + delta + n) % n
+}
+
+// cycleChoice selects the previous or next alternative of the focused
+// choice placeholder
+func (a *App) cycleChoice(delta int) {
+	example := a.currentExample()
+	if example == nil || a.fieldIdx >= len(example.Placeholders) {
+		return
+	}
+	placeholder := example.Placeholders[a.fieldIdx]
+	n := len(placeholder.Choices)
+	if n == 0 {
+		return
+	}
+
+	current := 0
+	for i, choice := range placeholder.Choices {
+		if choice == a.valueFor(placeholder) {
+			current = i
+		}
+	}
+	a.setValue(placeholder.Name, placeholder.Choices[(current+delta+n)%n])
+}
+
+// openInBrowser opens the page's more information URL in the default browser
+func (a *App) openInBrowser() (bubbletea.Model, bubbletea.Cmd) {
+	page := a.selectedPage()
+	if page == nil || page.MoreInfoURL == "" {
+		return a, nil
+	}
+	url := page.MoreInfoURL
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	cmd.Start()
+	return a, nil
+}
+
+// toggleAllPlatforms switches between common pages only and pages from all
+// platforms, the running system's first
 func (a *App) toggleAllPlatforms() {
-	allPlatforms := []string{"common", "linux", "osx", "sunos", "windows", "android"}
-	if len(a.platforms) == len(allPlatforms) {
+	if len(a.platforms) == 0 {
 		a.platforms = []string{"common"}
 	} else {
-		a.platforms = allPlatforms
+		a.platforms = nil
 	}
 	a.loadPages()
 }
 
+// platformKeys maps the number keys to the platforms they toggle
+var platformKeys = map[string]string{
+	"1": "common",
+	"2": "linux",
+	"3": "osx",
+	"4": "sunos",
+	"5": "windows",
+	"6": "android",
+}
+
 // togglePlatform toggles a specific platform filter
 func (a *App) togglePlatform(platformNum string) {
-	platformMap := map[string]string{
-		"1": "common",
-		"2": "linux", 
-		"3": "osx",
-		"4": "sunos",
-		"5": "windows",
-		"6": "android",
-	}
-	
-	platform := platformMap[platformNum]
+	platform := platformKeys[platformNum]
 	if platform == "" {
 		return
 	}
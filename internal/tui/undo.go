@@ -0,0 +1,116 @@
+package tui
+
+import "fmt"
+
+// maxUndo is how many placeholder changes can be undone
+const maxUndo = 100
+
+// valueState is the placeholder values of the page before a change, for
+// undo and redo
+type valueState struct {
+	values    map[string]string
+	overrides map[int]map[string]string
+	// exampleIdx and fieldIdx are where the change was made, so that undo
+	// puts the focus back on the field
+	exampleIdx int
+	fieldIdx   int
+	// change describes the change, e.g. "{{file}}"
+	change string
+}
+
+// snapshot copies the current placeholder values
+func (a *App) snapshot(change string) valueState {
+	state := valueState{
+		values:     make(map[string]string, len(a.values)),
+		exampleIdx: a.exampleIdx,
+		fieldIdx:   a.fieldIdx,
+		change:     change,
+	}
+	for name, value := range a.values {
+		state.values[name] = value
+	}
+	if a.overrides != nil {
+		state.overrides = make(map[int]map[string]string, len(a.overrides))
+		for i, values := range a.overrides {
+			state.overrides[i] = make(map[string]string, len(values))
+			for name, value := range values {
+				state.overrides[i][name] = value
+			}
+		}
+	}
+	return state
+}
+
+// recordChange remembers the values before a change for undo, and forgets
+// the changes undone before it
+func (a *App) recordChange(change string) {
+	a.undo = append(a.undo, a.snapshot(change))
+	if len(a.undo) > maxUndo {
+		a.undo = a.undo[len(a.undo)-maxUndo:]
+	}
+	a.redo = nil
+}
+
+// restoreValues puts back the values of state, and its focus if it was
+// made in the current example
+func (a *App) restoreValues(state valueState) {
+	a.values, a.overrides = state.values, state.overrides
+	if state.exampleIdx == a.exampleIdx {
+		a.fieldIdx = state.fieldIdx
+	}
+}
+
+// undoChange takes back the last placeholder change
+func (a *App) undoChange() {
+	n := len(a.undo)
+	if n == 0 {
+		a.status = "Nothing to undo"
+		return
+	}
+	state := a.undo[n-1]
+	a.undo = a.undo[:n-1]
+	a.redo = append(a.redo, a.snapshot(state.change))
+	a.restoreValues(state)
+	a.status = fmt.Sprintf("Undid change to %s", state.change)
+}
+
+// redoChange makes the last undone placeholder change again
+func (a *App) redoChange() {
+	n := len(a.redo)
+	if n == 0 {
+		a.status = "Nothing to redo"
+		return
+	}
+	state := a.redo[n-1]
+	a.redo = a.redo[:n-1]
+	a.undo = append(a.undo, a.snapshot(state.change))
+	a.restoreValues(state)
+	a.status = fmt.Sprintf("Redid change to %s", state.change)
+}
+
+// resetDefaults drops the values entered for the current example's
+// placeholders, shared or its own, so that they show their defaults again
+func (a *App) resetDefaults() {
+	example := a.currentExample()
+	if example == nil {
+		return
+	}
+	changed := false
+	for _, placeholder := range example.Placeholders {
+		_, own := a.overrides[a.exampleIdx][placeholder.Name]
+		if own || a.values[placeholder.Name] != "" {
+			changed = true
+		}
+	}
+	if !changed {
+		a.status = "The placeholders already have their defaults"
+		return
+	}
+
+	a.recordChange("the example's placeholders")
+	for _, placeholder := range example.Placeholders {
+		delete(a.overrides[a.exampleIdx], placeholder.Name)
+		delete(a.values, placeholder.Name)
+	}
+	a.status = "Reset the placeholders to their defaults"
+}
@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// whatsNewAge is how long after an update the TUI mentions the pages
+	// it changed
+	whatsNewAge = 7 * 24 * time.Hour
+	// whatsNewNames is how many of those pages the banner names
+	whatsNewNames = 5
+)
+
+// loadChanges reads what the last cache update changed, for the banner on
+// the start screen, and points out in the status bar the pages the user
+// uses that changed
+func (a *App) loadChanges() {
+	a.changes = nil
+	changes, err := a.cache.Changes()
+	if err != nil || changes == nil || time.Since(changes.UpdatedAt) > whatsNewAge {
+		return
+	}
+	changes = changes.Filter(a.platforms)
+	if len(changes.Added)+len(changes.Updated) > 0 {
+		a.changes = changes
+	}
+
+	mine := changes.Only(a.usedPages())
+	var names []string
+	for _, entry := range mine.Updated {
+		names = append(names, entry.Name)
+	}
+	for _, entry := range mine.Removed {
+		names = append(names, entry.Name)
+	}
+	if len(names) > 0 {
+		a.status = fmt.Sprintf("Pages you use changed upstream: %s (see tldrpp whatsnew --mine)", strings.Join(names, ", "))
+	}
+}
+
+// usedPages returns the names of the pages the user has run commands from,
+// viewed or saved snippets of
+func (a *App) usedPages() map[string]bool {
+	used := make(map[string]bool)
+	if a.executions != nil {
+		for _, e := range a.executions.Executions {
+			used[e.Page] = true
+		}
+	}
+	if a.usage != nil {
+		for _, page := range a.usage.Pages {
+			used[page.Page] = true
+		}
+	}
+	if snippets, err := a.snippets.List(); err == nil {
+		for _, s := range snippets {
+			used[s.Page] = true
+		}
+	}
+	delete(used, "")
+	return used
+}
+
+// showChanges lists the pages the last update added or changed
+func (a *App) showChanges() {
+	from := a.here()
+	a.searchQuery = ""
+	a.filter = ""
+	a.allPages = append(append(a.allPages[:0:0], a.changes.Added...), a.changes.Updated...)
+	a.selectedIdx = 0
+	a.applyFilter()
+	a.navigateFrom(from, StatePages)
+	a.status = fmt.Sprintf("New and updated pages since %s", a.changes.UpdatedAt.Format("Jan 2"))
+}
+
+// renderChanges renders the banner naming the pages the last update added
+// or changed, or "" if there is none
+func (a *App) renderChanges() string {
+	if a.changes == nil || a.searchQuery != "" {
+		return ""
+	}
+	title := lipgloss.NewStyle().
+		Foreground(a.theme.Accent).
+		Bold(true)
+	text := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+
+	var names []string
+	for _, entry := range a.changes.Added {
+		names = append(names, entry.Name)
+	}
+	for _, entry := range a.changes.Updated {
+		names = append(names, entry.Name)
+	}
+	list := strings.Join(names, ", ")
+	if len(names) > whatsNewNames {
+		list = strings.Join(names[:whatsNewNames], ", ") + fmt.Sprintf(" and %d more", len(names)-whatsNewNames)
+	}
+
+	summary := fmt.Sprintf("%d new, %d updated", len(a.changes.Added), len(a.changes.Updated))
+	return title.Render("What's new: ") + text.Render(summary+" - "+list+" (N to list)") + "\n"
+}
@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/tui/components"
+)
+
+// maxDirSuggestions is how many directories the working directory prompt
+// suggests
+const maxDirSuggestions = 8
+
+// workDirPrompt asks for the directory commands run in, suggesting the one
+// tldr++ was started in and those commands were run in before
+type workDirPrompt struct {
+	input components.Input
+	dirs  []string
+	// picked is the suggestion in the input, -1 while typing
+	picked int
+}
+
+// openWorkDir opens the prompt for the directory commands run in
+func (a *App) openWorkDir() {
+	var dirs []string
+	if dir, err := os.Getwd(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	if a.executions != nil {
+		for _, dir := range a.executions.RecentDirs(maxDirSuggestions) {
+			if len(dirs) < maxDirSuggestions && (len(dirs) == 0 || dir != dirs[0]) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	input := components.NewInput("Run commands in: ", a.workDir, "(Enter Set, Esc Cancel)", a.theme.styles())
+	a.workDirPrompt = &workDirPrompt{input: input, dirs: dirs, picked: -1}
+}
+
+// handleWorkDirKey handles keys while the working directory is being typed
+// or picked from the suggestions
+func (a *App) handleWorkDirKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	if msg.Type == bubbletea.KeyCtrlC {
+		return a, bubbletea.Quit
+	}
+	prompt := a.workDirPrompt
+	if n := len(prompt.dirs); n > 0 {
+		switch {
+		case key.Matches(msg, a.keys.ArrowUp):
+			if prompt.picked < 0 {
+				prompt.picked = 0
+			}
+			prompt.picked = (prompt.picked - 1 + n) % n
+			prompt.input.Value = prompt.dirs[prompt.picked]
+			return a, nil
+		case key.Matches(msg, a.keys.ArrowDown):
+			prompt.picked = (prompt.picked + 1) % n
+			prompt.input.Value = prompt.dirs[prompt.picked]
+			return a, nil
+		}
+	}
+
+	prompt.input, _ = prompt.input.Update(msg)
+	switch prompt.input.State {
+	case components.InputSubmitted:
+		a.workDirPrompt = nil
+		a.setWorkDir(prompt.input.Value)
+	case components.InputCancelled:
+		a.workDirPrompt = nil
+	default:
+		prompt.picked = -1
+	}
+	return a, nil
+}
+
+// setWorkDir has commands run in dir, or in the directory tldr++ was
+// started in when it is empty
+func (a *App) setWorkDir(dir string) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		a.workDir = ""
+		a.status = "Commands run in the directory tldr++ was started in"
+		return
+	}
+	abs, err := filepath.Abs(config.ExpandHome(dir))
+	if err == nil {
+		var info os.FileInfo
+		if info, err = os.Stat(abs); err == nil && !info.IsDir() {
+			err = fmt.Errorf("%s is not a directory", abs)
+		}
+	}
+	if err != nil {
+		a.status = fmt.Sprintf("Invalid working directory: %v", err)
+		return
+	}
+	a.workDir = abs
+	a.status = "Commands run in " + config.AbbreviateHome(abs)
+}
+
+// renderWorkDirPrompt renders the prompt with the suggested directories
+// under it, the one picked highlighted
+func (a *App) renderWorkDirPrompt() string {
+	prompt := a.workDirPrompt
+	view := prompt.input.View()
+	if len(prompt.dirs) == 0 {
+		return view
+	}
+	style := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	picked := style.Copy().Foreground(a.theme.Accent).Bold(true)
+	keys := a.keys.ArrowUp.Help().Key + "/" + a.keys.ArrowDown.Help().Key
+	var b strings.Builder
+	b.WriteString(view + "\n" + style.Render("  Recent directories ("+keys+"):"))
+	for i, dir := range prompt.dirs {
+		if i == prompt.picked {
+			b.WriteString("\n" + picked.Render("  "+selectMark(true)+dir))
+		} else {
+			b.WriteString("\n" + style.Render("  "+selectMark(false)+dir))
+		}
+	}
+	return b.String()
+}
+
+// renderWorkDir shows the directory commands run in, when one was chosen,
+// the home directory shortened to ~
+func (a *App) renderWorkDir() string {
+	if a.workDir == "" {
+		return ""
+	}
+	meta := lipgloss.NewStyle().Foreground(a.theme.Foreground)
+	return meta.Render("Runs in: "+config.AbbreviateHome(a.workDir)+" ("+a.keys.WorkDir.Help().Key+" changes)") + "\n"
+}
@@ -0,0 +1,233 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	bubbletea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/makalin/tldrpp/internal/config"
+	"github.com/makalin/tldrpp/internal/tui/components"
+	"github.com/makalin/tldrpp/internal/types"
+	"github.com/makalin/tldrpp/internal/workflow"
+)
+
+// stepState is how far a workflow step got
+type stepState int
+
+const (
+	stepPending stepState = iota
+	stepDone
+	stepSkipped
+	stepFailed
+)
+
+// stepDoneMsg reports that a step's command exited
+type stepDoneMsg struct {
+	err error
+}
+
+// stepCommand runs a step once the TUI has released the terminal. The run
+// function uses the process' standard streams, so the ones bubbletea hands
+// over are ignored.
+type stepCommand struct {
+	run func() error
+}
+
+// Run runs the step and waits for Enter, so that its output can be read
+// before the TUI takes over the screen again
+func (c stepCommand) Run() error {
+	err := c.run()
+	fmt.Fprint(os.Stderr, "\nPress Enter to go back to the workflow")
+	bufio.NewReader(os.Stdin).ReadString('\n')
+	return err
+}
+
+func (c stepCommand) SetStdin(io.Reader)  {}
+func (c stepCommand) SetStdout(io.Writer) {}
+func (c stepCommand) SetStderr(io.Writer) {}
+
+// workflowRunner steps through a workflow, asking before each command
+type workflowRunner struct {
+	workflow *workflow.Workflow
+	examples []*types.Example
+	vars     map[string]string
+	check    func(step int, command string) ([]Confirmation, error)
+	run      func(step int, command string) error
+	theme    Theme
+	states   []stepState
+	current  int
+	err      error
+	aborted  bool
+	// confirm is the dialog on screen, if any
+	confirm *confirmDialog
+	width   int
+}
+
+// RunWorkflow steps through a workflow whose placeholders are filled with
+// vars. check returns the questions to answer yes to in a dialog before the
+// command of a step runs, and run executes it; it is called with the
+// terminal released from the TUI and must not ask again. It returns
+// workflow.ErrAborted if the user stopped before the last step.
+func RunWorkflow(cfg *config.Config, w *workflow.Workflow, vars map[string]string, check func(step int, command string) ([]Confirmation, error), run func(step int, command string) error) error {
+	runner := &workflowRunner{
+		workflow: w,
+		examples: w.Examples(),
+		vars:     vars,
+		check:    check,
+		run:      run,
+		theme:    getTheme(cfg.Theme),
+		states:   make([]stepState, len(w.Steps)),
+	}
+
+	if _, err := bubbletea.NewProgram(runner, bubbletea.WithAltScreen()).Run(); err != nil {
+		return err
+	}
+	if runner.aborted {
+		return workflow.ErrAborted
+	}
+	return nil
+}
+
+// Init initializes the bubbletea model
+func (r *workflowRunner) Init() bubbletea.Cmd {
+	return nil
+}
+
+// Update handles bubbletea updates
+func (r *workflowRunner) Update(msg bubbletea.Msg) (bubbletea.Model, bubbletea.Cmd) {
+	switch msg := msg.(type) {
+	case bubbletea.WindowSizeMsg:
+		r.width = msg.Width
+	case stepDoneMsg:
+		if msg.err != nil {
+			r.states[r.current] = stepFailed
+			r.err = msg.err
+			return r, nil
+		}
+		r.states[r.current] = stepDone
+		return r.next()
+	case bubbletea.KeyMsg:
+		if r.confirm != nil {
+			return r.handleConfirmKey(msg)
+		}
+		switch msg.String() {
+		case "y", "enter", "r":
+			r.err = nil
+			return r, r.runStep()
+		case "s":
+			r.err = nil
+			r.states[r.current] = stepSkipped
+			return r.next()
+		case "a", "q", "esc", "ctrl+c":
+			r.aborted = true
+			return r, bubbletea.Quit
+		}
+	}
+	return r, nil
+}
+
+// runStep runs the current step's command once its confirmations were
+// answered yes
+func (r *workflowRunner) runStep() bubbletea.Cmd {
+	step := r.current
+	command := r.examples[step].Render(r.vars)
+	confirmations, err := r.check(step, command)
+	if err != nil {
+		r.states[step] = stepFailed
+		r.err = err
+		return nil
+	}
+	return confirmEach(confirmations, r.theme.styles(), func(d *confirmDialog) { r.confirm = d }, func() bubbletea.Cmd {
+		return bubbletea.Exec(stepCommand{run: func() error {
+			return r.run(step, command)
+		}}, func(err error) bubbletea.Msg {
+			return stepDoneMsg{err: err}
+		})
+	}, nil)
+}
+
+// handleConfirmKey answers the dialog on screen; answering no leaves the
+// step to run, skip or abort
+func (r *workflowRunner) handleConfirmKey(msg bubbletea.KeyMsg) (bubbletea.Model, bubbletea.Cmd) {
+	if msg.Type == bubbletea.KeyCtrlC {
+		r.aborted = true
+		return r, bubbletea.Quit
+	}
+	dialog := r.confirm
+	r.confirm = nil
+	answered, cmd := dialog.update(msg)
+	if !answered {
+		r.confirm = dialog
+	}
+	return r, cmd
+}
+
+// next moves on to the following step, quitting after the last one
+func (r *workflowRunner) next() (bubbletea.Model, bubbletea.Cmd) {
+	if r.current == len(r.examples)-1 {
+		return r, bubbletea.Quit
+	}
+	r.current++
+	return r, nil
+}
+
+// View renders the steps, with the command of the current one
+func (r *workflowRunner) View() string {
+	var content strings.Builder
+
+	header := lipgloss.NewStyle().
+		Foreground(r.theme.Accent).
+		Bold(true).
+		Render(fmt.Sprintf("Workflow %s (%d steps)", r.workflow.Name, len(r.examples)))
+	content.WriteString(header + "\n")
+	text := lipgloss.NewStyle().Foreground(r.theme.Foreground)
+	if r.workflow.Description != "" {
+		content.WriteString(text.Render(r.workflow.Description) + "\n")
+	}
+	content.WriteString("\n")
+
+	marks := map[stepState]string{
+		stepPending: "  ",
+		stepDone:    lipgloss.NewStyle().Foreground(r.theme.Success).Render(Glyph("✓ ", "+ ")),
+		stepSkipped: lipgloss.NewStyle().Foreground(r.theme.Warning).Render("- "),
+		stepFailed:  lipgloss.NewStyle().Foreground(r.theme.Error).Render(Glyph("✗ ", "x ")),
+	}
+	for i, example := range r.examples {
+		line := fmt.Sprintf("%d. %s", i+1, example.Description)
+		if example.Description == "" {
+			line = fmt.Sprintf("%d. %s", i+1, example.Command)
+		}
+		style := text
+		if i == r.current {
+			style = style.Background(r.theme.Highlight).Foreground(r.theme.Background)
+		}
+		content.WriteString(marks[r.states[i]] + selectMark(i == r.current) + style.Render(line) + "\n")
+	}
+
+	// Secret values are masked on screen
+	command, _, _ := r.examples[r.current].Redact(r.vars)
+	content.WriteString("\n" + frame(lipgloss.NewStyle()).
+		BorderForeground(r.theme.Border).
+		Padding(0, 1).
+		Render(command) + "\n")
+
+	help := "y/Enter Run, s Skip, a Abort"
+	if r.err != nil {
+		content.WriteString(lipgloss.NewStyle().Foreground(r.theme.Error).
+			Render(fmt.Sprintf("Step %d failed: %v", r.current+1, r.err)) + "\n")
+		help = "r Retry, s Skip, a Abort"
+	}
+	content.WriteString("\n" + text.Render(help))
+
+	if r.confirm != nil {
+		if accessible {
+			return r.confirm.view(r.width)
+		}
+		return components.Overlay(content.String(), r.confirm.view(r.width), r.width)
+	}
+	return content.String()
+}
@@ -0,0 +1,110 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// LintProblem is a departure from the tldr page style guide
+type LintProblem struct {
+	// Line is 1-based; 0 means the page as a whole
+	Line    int
+	Message string
+}
+
+func (p LintProblem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// Lint checks a page against the rules of tldr-lint that matter for pages
+// written by hand: the title, description and example layout, and stray
+// whitespace
+func Lint(content string) []LintProblem {
+	var problems []LintProblem
+	add := func(line int, format string, args ...interface{}) {
+		problems = append(problems, LintProblem{Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.Contains(content, "\r\n") {
+		add(0, "use Unix line endings")
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	if !strings.HasSuffix(content, "\n") {
+		add(0, "end the page with a newline")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "# ") {
+		add(1, "start the page with a '# name' title")
+	}
+
+	examples := 0
+	expectCommand := 0
+	for i, line := range lines {
+		n := i + 1
+		if strings.TrimRight(line, " \t") != line {
+			add(n, "remove trailing whitespace")
+		}
+		if strings.Contains(line, "\t") {
+			add(n, "use spaces instead of tabs")
+		}
+		if line == "" && i > 0 && lines[i-1] == "" {
+			add(n, "use a single empty line between sections")
+		}
+
+		switch {
+		case strings.HasPrefix(line, ">"):
+			text := strings.TrimPrefix(line, "> ")
+			if !startsUpper(text) {
+				add(n, "start the description with a capital letter")
+			}
+			if !strings.HasSuffix(strings.TrimSpace(text), ".") {
+				add(n, "end the description with a period")
+			}
+		case strings.HasPrefix(line, "- "):
+			if expectCommand > 0 {
+				add(expectCommand, "follow the example description with a command")
+			}
+			examples++
+			expectCommand = n
+			text := strings.TrimPrefix(line, "- ")
+			if !startsUpper(text) {
+				add(n, "start the example description with a capital letter")
+			}
+			if !strings.HasSuffix(strings.TrimSpace(text), ":") {
+				add(n, "end the example description with a colon")
+			}
+		case strings.HasPrefix(line, "`"):
+			expectCommand = 0
+			if len(line) < 2 || !strings.HasSuffix(line, "`") {
+				add(n, "wrap the command in backticks")
+				continue
+			}
+			command := line[1 : len(line)-1]
+			if strings.TrimSpace(command) != command || command == "" {
+				add(n, "don't start or end the command with whitespace")
+			}
+		}
+	}
+	if expectCommand > 0 {
+		add(expectCommand, "follow the example description with a command")
+	}
+	if examples == 0 {
+		add(0, "add at least one example")
+	}
+
+	return problems
+}
+
+// startsUpper reports whether s starts with an upper-case letter or a
+// non-letter, such as a digit or a command name in backticks
+func startsUpper(s string) bool {
+	for _, r := range s {
+		return !unicode.IsLower(r)
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:    "clean page",
+			content: "# tar\n\n> Archiving utility.\n\n- Extract an archive:\n\n`tar -xf {{source.tar}}`\n",
+		},
+		{
+			name:     "missing title",
+			content:  "> Archiving utility.\n\n- Extract an archive:\n\n`tar -xf {{source.tar}}`\n",
+			expected: []string{"line 1: start the page with a '# name' title"},
+		},
+		{
+			name:    "description style",
+			content: "# tar\n\n> archiving utility\n\n- extract an archive\n\n`tar -xf {{source.tar}}`\n",
+			expected: []string{
+				"line 3: start the description with a capital letter",
+				"line 3: end the description with a period",
+				"line 5: start the example description with a capital letter",
+				"line 5: end the example description with a colon",
+			},
+		},
+		{
+			name:    "whitespace",
+			content: "# tar \n\n\n> Archiving utility.\n\n- Extract an archive:\n\n`\ttar -xf {{source.tar}}`",
+			expected: []string{
+				"end the page with a newline",
+				"line 1: remove trailing whitespace",
+				"line 3: use a single empty line between sections",
+				"line 8: use spaces instead of tabs",
+				"line 8: don't start or end the command with whitespace",
+			},
+		},
+		{
+			name:    "example without command",
+			content: "# tar\n\n> Archiving utility.\n\n- Extract an archive:\n\n- List an archive:\n\n`tar -tf {{source.tar}}`\n",
+			expected: []string{
+				"line 5: follow the example description with a command",
+			},
+		},
+		{
+			name:    "no examples",
+			content: "# tar\n\n> Archiving utility.\n",
+			expected: []string{
+				"add at least one example",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := Lint(tt.content)
+			if len(problems) != len(tt.expected) {
+				t.Fatalf("Expected %d problems, got %v", len(tt.expected), problems)
+			}
+			for i, problem := range problems {
+				if problem.String() != tt.expected[i] {
+					t.Errorf("Expected '%s', got '%s'", tt.expected[i], problem)
+				}
+			}
+		})
+	}
+}
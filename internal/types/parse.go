@@ -0,0 +1,210 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// markdown is the parser used for tldr pages
+var markdown = goldmark.New()
+
+// ParsePage parses a tldr page from markdown content
+func ParsePage(content string, entry IndexEntry) (*Page, error) {
+	page := &Page{
+		Name:        entry.Name,
+		Description: entry.Description,
+		Platform:    entry.Platform,
+		RawContent:  content,
+	}
+
+	source := []byte(strings.ReplaceAll(content, "\r\n", "\n"))
+	doc := markdown.Parser().Parse(text.NewReader(source))
+
+	var current *Example
+	flush := func() {
+		if current != nil {
+			page.Examples = append(page.Examples, *current)
+			current = nil
+		}
+	}
+
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		switch block := node.(type) {
+		case *ast.Heading:
+			// Title
+			if page.Name == "" {
+				page.Name = joinLines(block, source, " ")
+			}
+		case *ast.Blockquote:
+			// Description, related commands and the more information link
+			parseBlockquote(page, block, source)
+		case *ast.List:
+			// Each list item starts a new example; a command may be nested
+			// inside the item when it is indented under the description
+			for item := block.FirstChild(); item != nil; item = item.NextSibling() {
+				flush()
+				current = parseListItem(item, source)
+			}
+		default:
+			// Command following the description of the current example
+			if command, ok := parseCommand(node, source); ok && current != nil && current.Command == "" {
+				current.setCommand(command)
+			}
+		}
+	}
+
+	// Add last example
+	flush()
+
+	return page, nil
+}
+
+// parseListItem builds an example from a list item. The description may be
+// followed by the command, either as its own block or, in tight lists, as a
+// continuation line of the description.
+func parseListItem(item ast.Node, source []byte) *Example {
+	example := &Example{}
+	var description []string
+
+	for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+		if example.Command != "" {
+			break
+		}
+		if command, ok := parseCommand(child, source); ok && len(description) > 0 {
+			example.setCommand(command)
+			continue
+		}
+		for _, line := range rawLines(child, source) {
+			if command, ok := stripCodeSpan(line); ok && len(description) > 0 {
+				example.setCommand(command)
+				break
+			}
+			description = append(description, line)
+		}
+	}
+
+	example.Description = strings.TrimSuffix(strings.Join(description, " "), ":")
+	return example
+}
+
+// NewExample returns an example for a command that did not come from a page,
+// with its placeholders extracted
+func NewExample(description, command string) *Example {
+	example := &Example{Description: description}
+	example.setCommand(command)
+	return example
+}
+
+// setCommand sets the example command and extracts its placeholders
+func (e *Example) setCommand(command string) {
+	e.Command = command
+	e.Placeholders = extractPlaceholders(command)
+}
+
+// parseBlockquote reads the page's blockquote: description lines, plus the
+// "See also:" and "More information:" lines tldr pages end it with
+func parseBlockquote(page *Page, block ast.Node, source []byte) {
+	var lines []string
+	for child := block.FirstChild(); child != nil; child = child.NextSibling() {
+		for _, line := range rawLines(child, source) {
+			switch {
+			case strings.HasPrefix(line, "More information:"):
+				page.MoreInfoURL = parseMoreInfoURL(strings.TrimPrefix(line, "More information:"))
+			case strings.HasPrefix(line, "See also:"):
+				page.SeeAlso = append(page.SeeAlso, parseSeeAlso(strings.TrimPrefix(line, "See also:"))...)
+			default:
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	if len(lines) > 0 {
+		page.Description = strings.TrimSuffix(strings.Join(lines, " "), ".")
+	}
+}
+
+// parseMoreInfoURL extracts the URL from "<https://...>." or a bare link
+func parseMoreInfoURL(s string) string {
+	s = strings.TrimSuffix(strings.TrimSpace(s), ".")
+	return strings.TrimSuffix(strings.TrimPrefix(s, "<"), ">")
+}
+
+// parseSeeAlso extracts command names from "`a`, `b`, or `c`."
+func parseSeeAlso(s string) []string {
+	var commands []string
+	for {
+		start := strings.Index(s, "`")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start+1:], "`")
+		if end < 0 {
+			break
+		}
+		if command := strings.TrimSpace(s[start+1 : start+1+end]); command != "" {
+			commands = append(commands, command)
+		}
+		s = s[start+1+end+1:]
+	}
+	return commands
+}
+
+// parseCommand extracts a command from a block that consists of a single
+// code span, or from a fenced or indented code block
+func parseCommand(node ast.Node, source []byte) (string, bool) {
+	switch block := node.(type) {
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		var lines []string
+		segments := block.Lines()
+		for i := 0; i < segments.Len(); i++ {
+			segment := segments.At(i)
+			lines = append(lines, strings.TrimRight(string(segment.Value(source)), "\n"))
+		}
+		return strings.Join(lines, "\n"), len(lines) > 0
+	case *ast.Paragraph, *ast.TextBlock:
+		return stripCodeSpan(joinLines(block, source, " "))
+	}
+	return "", false
+}
+
+// stripCodeSpan returns the content of s if the whole of s is one code span,
+// such as "`tar -xf {{file}}`", including spans fenced by several backticks
+func stripCodeSpan(s string) (string, bool) {
+	fence := len(s) - len(strings.TrimLeft(s, "`"))
+	if fence == 0 || len(s) < 2*fence+1 {
+		return "", false
+	}
+
+	ticks := strings.Repeat("`", fence)
+	inner := s[fence : len(s)-fence]
+	if !strings.HasSuffix(s, ticks) || strings.Contains(inner, ticks) {
+		return "", false
+	}
+
+	// A single space on both sides is padding, as in CommonMark
+	if len(inner) > 2 && inner[0] == ' ' && inner[len(inner)-1] == ' ' {
+		inner = inner[1 : len(inner)-1]
+	}
+	return inner, true
+}
+
+// rawLines returns the source lines of a block, trimmed of surrounding space
+func rawLines(node ast.Node, source []byte) []string {
+	var lines []string
+	segments := node.Lines()
+	for i := 0; i < segments.Len(); i++ {
+		segment := segments.At(i)
+		if line := strings.TrimSpace(string(segment.Value(source))); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// joinLines joins the source lines of a block with sep
+func joinLines(node ast.Node, source []byte, sep string) string {
+	return strings.Join(rawLines(node, source), sep)
+}
@@ -0,0 +1,272 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePageCorpus(t *testing.T) {
+	tests := []struct {
+		file        string
+		description string
+		examples    int
+		// checks maps an example index to its expected description and command
+		checks map[int][2]string
+	}{
+		{
+			file:        "common/tar.md",
+			description: "Archiving utility. Often combined with a compression method, such as `gzip` or `bzip2`",
+			examples:    5,
+			checks: map[int][2]string{
+				0: {"[c]reate an archive and write it to a [f]ile", "tar cf {{path/to/target.tar}} {{path/to/file1 path/to/file2 ...}}"},
+				3: {"E[x]tract a (compressed) archive [f]ile into the target directory", "tar xf {{path/to/source.tar[.gz|.bz2|.xz]}} {{[-C|--directory]}} {{path/to/directory}}"},
+			},
+		},
+		{
+			file:        "common/git-commit.md",
+			description: "Commit files to the repository",
+			examples:    5,
+			checks: map[int][2]string{
+				0: {"Commit staged files to the repository with a message", `git commit {{[-m|--message]}} "{{message}}"`},
+				3: {"Update the last commit by adding the currently staged changes, changing the commit's hash", "git commit --amend"},
+			},
+		},
+		{
+			file:        "common/grep.md",
+			description: "Find patterns in files using `regex`es",
+			examples:    4,
+			checks: map[int][2]string{
+				1: {"Search for an exact string (disables `regex`es)", `grep {{[-F|--fixed-strings]}} "{{exact_string}}" {{path/to/file}}`},
+				2: {"Use extended `regex`es (supports `?`, `+`, `{}`, `()`, and `|`), in case-insensitive mode", `grep {{[-Ei|--extended-regexp --ignore-case]}} "{{search_pattern}}" {{path/to/file}}`},
+				3: {"Search `stdin` for lines that do not match a pattern", `cat {{path/to/file}} | grep {{[-v|--invert-match]}} "{{search_pattern}}"`},
+			},
+		},
+		{
+			file:        "common/ls.md",
+			description: "List directory contents",
+			examples:    4,
+			checks: map[int][2]string{
+				0: {"List files one per line", "ls -1"},
+				2: {"List files with a trailing symbol to indicate file type (directory/, symbolic_link@, executable*, ...)", "ls {{[-F|--classify]}}"},
+			},
+		},
+		{
+			file:        "common/awk.md",
+			description: "A versatile programming language for working on files",
+			examples:    3,
+			checks: map[int][2]string{
+				0: {"Print the fifth column (a.k.a. field) in a space-separated file", "awk '{print $5}' {{path/to/file}}"},
+				2: {"Print different values based on conditions", `awk '{if ($1 == "foo") print "Exact match foo"; else if ($1 ~ "bar") print "Partial match bar"; else print "Baz"}' {{path/to/file}}`},
+			},
+		},
+		{
+			file:        "common/docker-run.md",
+			description: "Run a command in a new Docker container",
+			examples:    4,
+			checks: map[int][2]string{
+				3: {"Run command in a new container with passed environment variables", "docker run {{[-e|--env]}} '{{variable}}={{value}}' {{[-e|--env]}} {{variable}} {{image:tag}} {{command}}"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		data, err := os.ReadFile(filepath.Join("testdata", "pages", test.file))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", test.file, err)
+		}
+
+		// Every page must parse the same with Unix and Windows line endings
+		variants := map[string]string{
+			"lf":   string(data),
+			"crlf": strings.ReplaceAll(string(data), "\n", "\r\n"),
+		}
+
+		for variant, content := range variants {
+			t.Run(test.file+"/"+variant, func(t *testing.T) {
+				page, err := ParsePage(content, IndexEntry{Platform: "common"})
+				if err != nil {
+					t.Fatalf("ParsePage failed: %v", err)
+				}
+
+				expectedName := strings.TrimSuffix(filepath.Base(test.file), ".md")
+				if strings.ReplaceAll(page.Name, " ", "-") != expectedName {
+					t.Errorf("Expected name '%s', got '%s'", expectedName, page.Name)
+				}
+
+				if page.Description != test.description {
+					t.Errorf("Expected description '%s', got '%s'", test.description, page.Description)
+				}
+
+				if len(page.Examples) != test.examples {
+					t.Fatalf("Expected %d examples, got %d", test.examples, len(page.Examples))
+				}
+
+				for i, example := range page.Examples {
+					if example.Description == "" || example.Command == "" {
+						t.Errorf("Example %d is incomplete: %+v", i, example)
+					}
+				}
+
+				for i, expected := range test.checks {
+					example := page.Examples[i]
+					if example.Description != expected[0] {
+						t.Errorf("Example %d: expected description '%s', got '%s'", i, expected[0], example.Description)
+					}
+					if example.Command != expected[1] {
+						t.Errorf("Example %d: expected command '%s', got '%s'", i, expected[1], example.Command)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestParsePageAllPages(t *testing.T) {
+	root := filepath.Join("testdata", "pages")
+	files, err := filepath.Glob(filepath.Join(root, "*", "*.md"))
+	if err != nil {
+		t.Fatalf("Failed to list pages: %v", err)
+	}
+	if len(files) < 300 {
+		t.Fatalf("Expected at least 300 pages in the corpus, got %d", len(files))
+	}
+
+	for _, file := range files {
+		rel, _ := filepath.Rel(root, file)
+		t.Run(filepath.ToSlash(rel), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", rel, err)
+			}
+
+			page, err := ParsePage(string(data), IndexEntry{Platform: filepath.Base(filepath.Dir(file))})
+			if err != nil {
+				t.Fatalf("ParsePage failed: %v", err)
+			}
+
+			if page.Name == "" || page.Description == "" {
+				t.Errorf("Missing name or description: %q, %q", page.Name, page.Description)
+			}
+
+			// Every "- " line in a page introduces exactly one example
+			expected := 0
+			for _, line := range strings.Split(string(data), "\n") {
+				if strings.HasPrefix(line, "- ") {
+					expected++
+				}
+			}
+			if len(page.Examples) != expected {
+				t.Fatalf("Expected %d examples, got %d", expected, len(page.Examples))
+			}
+
+			for i, example := range page.Examples {
+				if example.Description == "" || example.Command == "" {
+					t.Errorf("Example %d is incomplete: %+v", i, example)
+				}
+				if strings.HasPrefix(example.Command, "`") {
+					t.Errorf("Example %d kept its code span: %s", i, example.Command)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePageFencedCommands(t *testing.T) {
+	content := "# deploy\n\n" +
+		"> Deploy a service.\n\n" +
+		"- Deploy a service, waiting for the rollout to finish:\n\n" +
+		"```\ndeploy {{service}} \\\n  --env {{environment}} \\\n  --wait\n```\n\n" +
+		"- Roll back the last deployment:\n\n" +
+		"`deploy rollback {{service}}`\n"
+
+	page, err := ParsePage(content, IndexEntry{Name: "deploy"})
+	if err != nil {
+		t.Fatalf("ParsePage failed: %v", err)
+	}
+
+	if len(page.Examples) != 2 {
+		t.Fatalf("Expected 2 examples, got %d", len(page.Examples))
+	}
+	if page.Examples[0].Command != "deploy {{service}} \\\n  --env {{environment}} \\\n  --wait" {
+		t.Errorf("Unexpected command: %q", page.Examples[0].Command)
+	}
+	if page.Examples[1].Command != "deploy rollback {{service}}" {
+		t.Errorf("Unexpected command: %q", page.Examples[1].Command)
+	}
+}
+
+func TestParsePageIndentedCommands(t *testing.T) {
+	content := "# ssh\n\n> Secure Shell client.\n\n- Connect to a remote server:\n  `ssh {{user}}@{{host}}`\n- Connect using a specific port:\n  `ssh -p {{port}} {{user}}@{{host}}`\n"
+
+	page, err := ParsePage(content, IndexEntry{Name: "ssh"})
+	if err != nil {
+		t.Fatalf("ParsePage failed: %v", err)
+	}
+
+	if len(page.Examples) != 2 {
+		t.Fatalf("Expected 2 examples, got %d", len(page.Examples))
+	}
+	if page.Examples[1].Command != "ssh -p {{port}} {{user}}@{{host}}" {
+		t.Errorf("Unexpected command: %s", page.Examples[1].Command)
+	}
+	if len(page.Examples[1].Placeholders) != 3 {
+		t.Errorf("Expected 3 placeholders, got %d", len(page.Examples[1].Placeholders))
+	}
+}
+
+func TestStripCodeSpan(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"`ls -la`", "ls -la", true},
+		{"`` echo `date` ``", "echo `date`", true},
+		{"`a` and `b`", "", false},
+		{"plain text", "", false},
+		{"``", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			result, ok := stripCodeSpan(test.input)
+			if ok != test.ok || result != test.expected {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", test.expected, test.ok, result, ok)
+			}
+		})
+	}
+}
+
+func TestParsePageLinks(t *testing.T) {
+	content := "# gzip\n\n" +
+		"> Compress/uncompress files with `gzip` compression (LZ77).\n" +
+		"> See also: `gunzip`, `zcat`, and `bzip2`.\n" +
+		"> More information: <https://www.gnu.org/software/gzip/manual/gzip.html>.\n\n" +
+		"- Compress a file, replacing it with a `gzip` compressed version:\n\n" +
+		"`gzip {{path/to/file}}`\n"
+
+	page, err := ParsePage(content, IndexEntry{Name: "gzip"})
+	if err != nil {
+		t.Fatalf("ParsePage failed: %v", err)
+	}
+
+	if page.Description != "Compress/uncompress files with `gzip` compression (LZ77)" {
+		t.Errorf("Unexpected description: %s", page.Description)
+	}
+
+	if page.MoreInfoURL != "https://www.gnu.org/software/gzip/manual/gzip.html" {
+		t.Errorf("Unexpected more information URL: %s", page.MoreInfoURL)
+	}
+
+	expected := []string{"gunzip", "zcat", "bzip2"}
+	if len(page.SeeAlso) != len(expected) {
+		t.Fatalf("Expected see also %v, got %v", expected, page.SeeAlso)
+	}
+	for i, command := range expected {
+		if page.SeeAlso[i] != command {
+			t.Errorf("Expected see also %v, got %v", expected, page.SeeAlso)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+)
+
+// trailingDigits matches the numeric suffix tldr pages use to tell apart
+// repeated placeholders, as in {{file1 file2 ...}}
+var trailingDigits = regexp.MustCompile(`\d+$`)
+
+// optionalGroup matches bracketed alternatives embedded in a placeholder
+var optionalGroup = regexp.MustCompile(`\[[^\]]*\]`)
+
+// nonIdentifier matches runs of characters not allowed in variable names
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// parsePlaceholder interprets the text between {{ and }}. It understands the
+// tldr conventions for paths ({{path/to/file}}), alternatives ({{a|b}} and
+// option groups like {{[-v|--verbose]}}) and repeated arguments
+// ({{file1 file2 ...}}).
+func parsePlaceholder(token string) Placeholder {
+	placeholder := Placeholder{
+		Name:    token,
+		Display: token,
+	}
+
+	text := strings.TrimSpace(token)
+
+	if strings.HasSuffix(text, "...") {
+		placeholder.Variadic = true
+		text = strings.TrimSpace(strings.TrimSuffix(text, "..."))
+		if fields := strings.Fields(text); len(fields) > 0 {
+			text = trailingDigits.ReplaceAllString(fields[0], "")
+		}
+	}
+
+	// Optional suffix groups such as in {{path/to/file.tar[.gz|.xz]}} do not
+	// make the placeholder a choice
+	if !strings.HasPrefix(text, "[") {
+		text = optionalGroup.ReplaceAllString(text, "")
+	}
+
+	if strings.Contains(text, "|") {
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "["), "]")
+		for _, choice := range strings.Split(text, "|") {
+			if choice = strings.TrimSpace(choice); choice != "" {
+				placeholder.Choices = append(placeholder.Choices, choice)
+			}
+		}
+		placeholder.Name = choiceName(placeholder.Choices)
+		placeholder.Type = "choice"
+		if len(placeholder.Choices) > 0 {
+			placeholder.Default = placeholder.Choices[0]
+		}
+		return placeholder
+	}
+
+	if i := strings.LastIndex(text, "/"); i >= 0 && i < len(text)-1 {
+		text = text[i+1:]
+	}
+	if text != "" {
+		placeholder.Name = text
+	}
+	placeholder.Type = inferPlaceholderType(placeholder.Name)
+	placeholder.Raw = rawPlaceholderNames[strings.ToLower(placeholder.Name)]
+
+	return placeholder
+}
+
+// choiceName derives a variable name for an alternatives placeholder. Option
+// groups are named after their longest spelling ("--verbose" -> "verbose"),
+// plain alternatives after all of them ("start_or_stop").
+func choiceName(choices []string) string {
+	if len(choices) == 0 {
+		return "choice"
+	}
+
+	options := true
+	for _, choice := range choices {
+		if !strings.HasPrefix(choice, "-") {
+			options = false
+			break
+		}
+	}
+
+	if options {
+		longest := choices[0]
+		for _, choice := range choices[1:] {
+			if len(choice) > len(longest) {
+				longest = choice
+			}
+		}
+		return identifier(strings.TrimLeft(longest, "-"))
+	}
+
+	parts := make([]string, len(choices))
+	for i, choice := range choices {
+		parts[i] = identifier(choice)
+	}
+	return strings.Join(parts, "_or_")
+}
+
+// identifier turns arbitrary text into a variable-name friendly form
+func identifier(s string) string {
+	return strings.Trim(nonIdentifier.ReplaceAllString(s, "_"), "_")
+}
+
+// Token returns the text that appears between the braces in the command
+func (p Placeholder) Token() string {
+	if p.Display != "" {
+		return p.Display
+	}
+	return p.Name
+}
+
+// Redacted replaces secret values in stored commands
+const Redacted = "********"
+
+// secretTypes are the placeholder types whose values are never stored
+var secretTypes = map[string]bool{"password": true, "token": true}
+
+// Secret reports whether the placeholder takes a secret such as a password
+// or an API token
+func (p Placeholder) Secret() bool {
+	return secretTypes[p.Type]
+}
+
+// IsSecretName reports whether a placeholder of this name takes a secret.
+// Types are inferred from names, so this holds for any example.
+func IsSecretName(name string) bool {
+	return secretTypes[inferPlaceholderType(name)]
+}
+
+// Redact replaces the values of secret placeholders in a rendered command
+// and its vars with Redacted, for storing them in history and logs. It
+// reports whether anything was redacted. The command is rendered again from
+// its template with the secrets left out, so other values that happen to
+// contain a secret are kept intact. A command without a template, as stored
+// by older versions, can't be rendered again and is redacted as a whole.
+func Redact(command, template string, vars map[string]string) (string, map[string]string, bool) {
+	safe, redacted := redactVars(vars)
+	if !redacted {
+		return command, safe, false
+	}
+	if template == "" {
+		return Redacted, safe, true
+	}
+	return NewExample("", template).Redact(vars)
+}
+
+// Redact renders the example like Render, with the values of secret
+// placeholders replaced by Redacted, and returns the vars likewise
+// redacted. It reports whether anything was redacted.
+func (e *Example) Redact(vars map[string]string) (string, map[string]string, bool) {
+	safe, redacted := redactVars(vars)
+	command := e.render(vars, func(placeholder Placeholder, value string, state quoteState) string {
+		if placeholder.Secret() || IsSecretName(placeholder.Name) {
+			return Redacted
+		}
+		return substitute(placeholder, value, state)
+	})
+	return command, safe, redacted
+}
+
+// redactVars returns a copy of vars with secret values replaced by Redacted
+func redactVars(vars map[string]string) (map[string]string, bool) {
+	redacted := false
+	safe := make(map[string]string, len(vars))
+	for name, value := range vars {
+		if value == "" || !IsSecretName(name) {
+			safe[name] = value
+			continue
+		}
+		safe[name] = Redacted
+		redacted = true
+	}
+	return safe, redacted
+}
@@ -0,0 +1,164 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePlaceholder(t *testing.T) {
+	tests := []struct {
+		token    string
+		expected Placeholder
+	}{
+		{
+			token:    "file",
+			expected: Placeholder{Name: "file", Display: "file", Type: "file"},
+		},
+		{
+			token:    "path/to/file_or_directory",
+			expected: Placeholder{Name: "file_or_directory", Display: "path/to/file_or_directory", Type: "file"},
+		},
+		{
+			token: "start|stop|restart",
+			expected: Placeholder{
+				Name: "start_or_stop_or_restart", Display: "start|stop|restart", Type: "choice",
+				Default: "start", Choices: []string{"start", "stop", "restart"},
+			},
+		},
+		{
+			token: "[-v|--verbose]",
+			expected: Placeholder{
+				Name: "verbose", Display: "[-v|--verbose]", Type: "choice",
+				Default: "-v", Choices: []string{"-v", "--verbose"},
+			},
+		},
+		{
+			token: "path/to/file1 path/to/file2 ...",
+			expected: Placeholder{
+				Name: "file", Display: "path/to/file1 path/to/file2 ...", Type: "file", Variadic: true,
+			},
+		},
+		{
+			token:    "args",
+			expected: Placeholder{Name: "args", Display: "args", Type: "text", Raw: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.token, func(t *testing.T) {
+			result := parsePlaceholder(test.token)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("Expected %+v, got %+v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestRenderAlternativeSyntaxes(t *testing.T) {
+	command := "rm {{[-r|--recursive]}} {{path/to/file1 path/to/file2 ...}} > {{path/to/log}}"
+	example := Example{
+		Command:      command,
+		Placeholders: extractPlaceholders(command),
+	}
+
+	result := example.Render(map[string]string{
+		"recursive": "--recursive",
+		"file":      "a.txt my notes.txt",
+		"log":       "out.log",
+	})
+
+	expected := "rm --recursive a.txt my notes.txt > out.log"
+	if result != expected {
+		t.Errorf("Expected %s, got %s", expected, result)
+	}
+
+	// Choices fall back to their first alternative
+	result = example.Render(map[string]string{"file": "x", "log": "y"})
+	if result != "rm -r x > y" {
+		t.Errorf("Expected choice default, got %s", result)
+	}
+}
+
+func TestParsePlaceholderOptionalSuffix(t *testing.T) {
+	result := parsePlaceholder("path/to/source.tar[.gz|.bz2|.xz]")
+	if result.Name != "source.tar" || len(result.Choices) != 0 {
+		t.Errorf("Expected plain placeholder 'source.tar', got %+v", result)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		vars     map[string]string
+		expected string
+	}{
+		{"plain", "mysql -u {{user}} -p{{password}}", map[string]string{"user": "root", "password": "hunter2"}, "mysql -u root -p********"},
+		{"quoted", "curl -H {{token}} {{url}}", map[string]string{"token": "a b$c", "url": "https://example.com"}, "curl -H ******** https://example.com"},
+		{"double quoted", `curl -H "Authorization: Bearer {{token}}"`, map[string]string{"token": `x"y`}, `curl -H "Authorization: Bearer ********"`},
+		{"no secrets", "ls {{path/to/dir}}", map[string]string{"dir": "src"}, "ls src"},
+		// The secret appears inside the other values, which must survive
+		{"substring", "mysql -u {{user}} -p {{password}} {{database}}", map[string]string{"user": "admin", "password": "a", "database": "app data"}, "mysql -u admin -p ******** 'app data'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			example := &Example{Command: tt.template, Placeholders: extractPlaceholders(tt.template)}
+			rendered := example.Render(tt.vars)
+
+			command, vars, redacted := Redact(rendered, tt.template, tt.vars)
+			if command != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, command)
+			}
+			anySecret := false
+			for name, value := range tt.vars {
+				expected := value
+				if IsSecretName(name) {
+					expected = Redacted
+					anySecret = true
+				}
+				if vars[name] != expected {
+					t.Errorf("Expected %s to be %q, got %q", name, expected, vars[name])
+				}
+			}
+			if redacted != anySecret {
+				t.Errorf("Expected redacted %v, got %v", anySecret, redacted)
+			}
+		})
+	}
+}
+
+func TestRedactWithoutTemplate(t *testing.T) {
+	vars := map[string]string{"user": "admin", "password": "a"}
+
+	// Without a template there is nothing to render the command again from
+	command, safe, redacted := Redact("mysql -u admin -p a", "", vars)
+	if command != Redacted || safe["password"] != Redacted || safe["user"] != "admin" || !redacted {
+		t.Errorf("Expected the whole command to be redacted, got %q %v %v", command, safe, redacted)
+	}
+
+	command, _, redacted = Redact("ls -a", "", map[string]string{"dir": "a"})
+	if command != "ls -a" || redacted {
+		t.Errorf("Expected a command without secrets to be kept, got %q %v", command, redacted)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	tests := []struct {
+		token    string
+		expected bool
+	}{
+		{"password", true},
+		{"api_token", true},
+		{"path/to/file", false},
+		{"--password|--token", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			if got := parsePlaceholder(tt.token).Secret(); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"strings"
+)
+
+// rawPlaceholderNames are placeholders whose values are meant to expand into
+// several shell words, so they are substituted without quoting
+var rawPlaceholderNames = map[string]bool{
+	"args":      true,
+	"arguments": true,
+	"options":   true,
+	"flags":     true,
+	"command":   true,
+}
+
+// ShellQuote quotes s for POSIX shells. Values made only of characters that
+// are never special to the shell are returned unchanged, and a leading "~/"
+// is kept outside the quotes so tilde expansion still works.
+func ShellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.HasPrefix(s, "~/") {
+		return "~/" + ShellQuote(strings.TrimPrefix(s, "~/"))
+	}
+	if isShellSafe(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isShellSafe reports whether s can be used as a shell word without quoting
+func isShellSafe(s string) bool {
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("@%+=:,./_-", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// quoteState tracks which kind of shell quoting is open at a position
+type quoteState int
+
+const (
+	quoteNone quoteState = iota
+	quoteSingle
+	quoteDouble
+)
+
+// quoteStateAt scans command up to offset and returns the quoting in effect
+func quoteStateAt(command string, offset int) quoteState {
+	state := quoteNone
+	for i := 0; i < offset; i++ {
+		c := command[i]
+		switch state {
+		case quoteNone:
+			switch c {
+			case '\\':
+				i++
+			case '\'':
+				state = quoteSingle
+			case '"':
+				state = quoteDouble
+			}
+		case quoteSingle:
+			if c == '\'' {
+				state = quoteNone
+			}
+		case quoteDouble:
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				state = quoteNone
+			}
+		}
+	}
+	return state
+}
+
+// quoteFor escapes value for insertion at a position with the given quoting,
+// so it always ends up as literal text inside the surrounding shell word
+func quoteFor(value string, state quoteState) string {
+	switch state {
+	case quoteSingle:
+		return strings.ReplaceAll(value, "'", `'\''`)
+	case quoteDouble:
+		replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+		return replacer.Replace(value)
+	default:
+		return ShellQuote(value)
+	}
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"archive.tar.gz", "archive.tar.gz"},
+		{"user@host:/srv/data", "user@host:/srv/data"},
+		{"", "''"},
+		{"my file.txt", "'my file.txt'"},
+		{"it's", `'it'\''s'`},
+		{"$(rm -rf /)", "'$(rm -rf /)'"},
+		{"a;b", "'a;b'"},
+		{"*.log", "'*.log'"},
+		{"~/my docs", "~/'my docs'"},
+		{"~/notes", "~/notes"},
+		{"~user", "'~user'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			if result := ShellQuote(test.value); result != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestRenderQuoting(t *testing.T) {
+	tests := []struct {
+		description string
+		command     string
+		value       string
+		expected    string
+	}{
+		{
+			description: "bare word",
+			command:     "cat {{file}}",
+			value:       "my file.txt",
+			expected:    "cat 'my file.txt'",
+		},
+		{
+			description: "inside double quotes",
+			command:     `echo "{{text}}"`,
+			value:       `say "hi" to $USER`,
+			expected:    `echo "say \"hi\" to \$USER"`,
+		},
+		{
+			description: "inside single quotes",
+			command:     "grep '{{pattern}}' log",
+			value:       "it's",
+			expected:    `grep 'it'\''s' log`,
+		},
+		{
+			description: "attached to an option",
+			command:     "tar -xf archive.tar --directory={{dir}}",
+			value:       "out dir",
+			expected:    "tar -xf archive.tar --directory='out dir'",
+		},
+		{
+			description: "after an escaped quote",
+			command:     `echo \"{{text}}`,
+			value:       "a b",
+			expected:    `echo \"'a b'`,
+		},
+		{
+			description: "injection attempt",
+			command:     "ping {{host}}",
+			value:       "example.com; rm -rf ~",
+			expected:    "ping 'example.com; rm -rf ~'",
+		},
+		{
+			description: "backticks inside double quotes",
+			command:     `echo "{{text}}"`,
+			value:       "`id`",
+			expected:    "echo \"\\`id\\`\"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			example := Example{
+				Command:      test.command,
+				Placeholders: extractPlaceholders(test.command),
+			}
+			name := example.Placeholders[0].Name
+			result := example.Render(map[string]string{name: test.value})
+			if result != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestRenderRawPlaceholder(t *testing.T) {
+	example := Example{
+		Command:      "docker run {{image}} {{args}}",
+		Placeholders: extractPlaceholders("docker run {{image}} {{args}}"),
+	}
+
+	if !example.Placeholders[1].Raw {
+		t.Fatal("Expected {{args}} to be raw")
+	}
+
+	result := example.Render(map[string]string{"image": "alpine", "args": "ls -la /"})
+	if result != "docker run alpine ls -la /" {
+		t.Errorf("Expected raw args, got %s", result)
+	}
+}
@@ -0,0 +1,35 @@
+package types
+
+import "strings"
+
+// Span is a piece of a description, either prose or a `code span`, which
+// often names another command
+type Span struct {
+	Text string
+	Code bool
+}
+
+// Spans splits a description into prose and code spans; Text of a code
+// span is without its backticks. An unmatched backtick is kept as prose.
+func Spans(text string) []Span {
+	var spans []Span
+	for {
+		start := strings.Index(text, "`")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(text[start+1:], "`")
+		if end < 0 {
+			break
+		}
+		if start > 0 {
+			spans = append(spans, Span{Text: text[:start]})
+		}
+		spans = append(spans, Span{Text: text[start+1 : start+1+end], Code: true})
+		text = text[start+1+end+1:]
+	}
+	if text != "" {
+		spans = append(spans, Span{Text: text})
+	}
+	return spans
+}
@@ -0,0 +1,26 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpans(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []Span
+	}{
+		{"Extract an archive", []Span{{Text: "Extract an archive"}}},
+		{"Compress with `gzip`", []Span{{Text: "Compress with "}, {Text: "gzip", Code: true}}},
+		{"`xz` or `zstd` files", []Span{{Text: "xz", Code: true}, {Text: " or "}, {Text: "zstd", Code: true}, {Text: " files"}}},
+		{"Unmatched ` backtick", []Span{{Text: "Unmatched ` backtick"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if spans := Spans(tt.text); !reflect.DeepEqual(spans, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, spans)
+			}
+		})
+	}
+}
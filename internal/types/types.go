@@ -1,7 +1,9 @@
 package types
 
 import (
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -10,6 +12,14 @@ type IndexEntry struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Platform    string `json:"platform"`
+	// Checksum is the SHA-256 of the cached page, used to detect corruption
+	Checksum string `json:"checksum,omitempty"`
+	// Languages lists the translations of the page that are cached
+	Languages []string `json:"languages,omitempty"`
+	// Custom pages are written by the user rather than downloaded
+	Custom bool `json:"custom,omitempty"`
+	// Pack names the installed page pack the page comes from, if any
+	Pack string `json:"pack,omitempty"`
 }
 
 // Page represents a tldr page
@@ -19,6 +29,12 @@ type Page struct {
 	Platform    string    `json:"platform"`
 	Examples    []Example `json:"examples"`
 	RawContent  string    `json:"raw_content"`
+	// MoreInfoURL is the link from the page's "More information" line
+	MoreInfoURL string `json:"more_info_url,omitempty"`
+	// SeeAlso lists related commands from the page's "See also" line
+	SeeAlso []string `json:"see_also,omitempty"`
+	// Language is the language of the page, e.g. "en" or "pt_BR"
+	Language string `json:"language,omitempty"`
 }
 
 // Example represents a command example
@@ -34,56 +50,16 @@ type Placeholder struct {
 	Type        string `json:"type"`
 	Description string `json:"description"`
 	Default     string `json:"default"`
-}
-
-// ParsePage parses a tldr page from markdown content
-func ParsePage(content string, entry IndexEntry) (*Page, error) {
-	page := &Page{
-		Name:        entry.Name,
-		Description: entry.Description,
-		Platform:    entry.Platform,
-		RawContent:  content,
-	}
-
-	lines := strings.Split(content, "\n")
-	var currentExample *Example
-	var inExample bool
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		
-		if strings.HasPrefix(line, "# ") {
-			// Skip title
-			continue
-		} else if strings.HasPrefix(line, "> ") {
-			// Description
-			page.Description = strings.TrimPrefix(line, "> ")
-		} else if strings.HasPrefix(line, "- ") {
-			// Start new example
-			if currentExample != nil {
-				page.Examples = append(page.Examples, *currentExample)
-			}
-			currentExample = &Example{
-				Description: strings.TrimPrefix(line, "- "),
-			}
-			inExample = true
-		} else if strings.HasPrefix(line, "`") && strings.HasSuffix(line, "`") && inExample {
-			// Command
-			command := strings.Trim(line, "`")
-			currentExample.Command = command
-			currentExample.Placeholders = extractPlaceholders(command)
-		} else if line == "" {
-			// Empty line ends example
-			inExample = false
-		}
-	}
-
-	// Add last example
-	if currentExample != nil {
-		page.Examples = append(page.Examples, *currentExample)
-	}
-
-	return page, nil
+	// Raw placeholders are substituted verbatim instead of shell-quoted
+	Raw bool `json:"raw"`
+	// Display is the placeholder text as written in the page, e.g.
+	// "path/to/file" for a placeholder named "file"
+	Display string `json:"display,omitempty"`
+	// Choices lists the alternatives of a {{a|b}} placeholder
+	Choices []string `json:"choices,omitempty"`
+	// Variadic placeholders such as {{file1 file2 ...}} take several values
+	// separated by whitespace
+	Variadic bool `json:"variadic,omitempty"`
 }
 
 // FindBestExample finds the best matching example for a command
@@ -112,46 +88,204 @@ func (p *Page) FindBestExample(query string) *Example {
 	return &p.Examples[0]
 }
 
-// Render renders a command with placeholders filled
+// ExampleAt returns the example at the given 1-based index
+func (p *Page) ExampleAt(n int) (*Example, error) {
+	if n < 1 || n > len(p.Examples) {
+		return nil, fmt.Errorf("example %d out of range (page %s has %d examples)", n, p.Name, len(p.Examples))
+	}
+	return &p.Examples[n-1], nil
+}
+
+// MatchExample returns the first example whose description or command
+// contains match, ignoring case
+func (p *Page) MatchExample(match string) (*Example, error) {
+	match = strings.ToLower(match)
+	for i := range p.Examples {
+		example := &p.Examples[i]
+		if strings.Contains(strings.ToLower(example.Description), match) ||
+			strings.Contains(strings.ToLower(example.Command), match) {
+			return example, nil
+		}
+	}
+	return nil, fmt.Errorf("no example of %s matches %q", p.Name, match)
+}
+
+// IndexOf returns the 1-based index of the page's example with the same
+// command and description as example, or 0 if there is none
+func (p *Page) IndexOf(example *Example) int {
+	for i := range p.Examples {
+		if p.Examples[i].Command == example.Command && p.Examples[i].Description == example.Description {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// ScopeVars returns the values that apply to the example with the given
+// 1-based index. Plain names apply to every example of a page; "N.name"
+// sets name for example N only and wins over the plain name.
+func ScopeVars(vars map[string]string, n int) map[string]string {
+	scoped := make(map[string]string, len(vars))
+	for name, value := range vars {
+		if _, _, ok := exampleScoped(name); !ok {
+			scoped[name] = value
+		}
+	}
+	for name, value := range vars {
+		if index, name, ok := exampleScoped(name); ok && index == n {
+			scoped[name] = value
+		}
+	}
+	return scoped
+}
+
+// exampleScoped splits a "N.name" variable into its example index and name
+func exampleScoped(name string) (int, string, bool) {
+	prefix, rest, ok := strings.Cut(name, ".")
+	if !ok || rest == "" {
+		return 0, "", false
+	}
+	index, err := strconv.Atoi(prefix)
+	if err != nil || index < 1 {
+		return 0, "", false
+	}
+	return index, rest, true
+}
+
+// UnresolvedError reports placeholders that had no value when rendering
+type UnresolvedError struct {
+	Names []string
+}
+
+func (e *UnresolvedError) Error() string {
+	return fmt.Sprintf("unresolved placeholders: %s", strings.Join(e.Names, ", "))
+}
+
+// Render renders a command with placeholders filled. Values are shell-quoted
+// according to where the placeholder sits in the command, except for raw
+// placeholders. Placeholders without a value or default are left as {{name}}
+// so they stay visible in the output.
 func (e *Example) Render(vars map[string]string) string {
-	command := e.Command
-	
-	// Replace placeholders with variables
+	return e.render(vars, substitute)
+}
+
+// render fills the placeholders that have a value with what format makes
+// of it
+func (e *Example) render(vars map[string]string, format func(Placeholder, string, quoteState) string) string {
+	byToken := make(map[string]Placeholder, len(e.Placeholders))
 	for _, placeholder := range e.Placeholders {
+		byToken[placeholder.Token()] = placeholder
+	}
+
+	var command strings.Builder
+	last := 0
+	for _, match := range placeholderPattern.FindAllStringSubmatchIndex(e.Command, -1) {
+		placeholder, ok := byToken[e.Command[match[2]:match[3]]]
+		if !ok {
+			continue
+		}
 		value := vars[placeholder.Name]
 		if value == "" {
 			value = placeholder.Default
 		}
 		if value == "" {
-			value = placeholder.Name // Use placeholder name as fallback
+			continue // Leave unresolved placeholders untouched
 		}
-		
-		placeholderPattern := regexp.MustCompile(`\{\{` + regexp.QuoteMeta(placeholder.Name) + `\}\}`)
-		command = placeholderPattern.ReplaceAllString(command, value)
+
+		command.WriteString(e.Command[last:match[0]])
+		command.WriteString(format(placeholder, value, quoteStateAt(e.Command, match[0])))
+		last = match[1]
 	}
-	
-	return command
+	command.WriteString(e.Command[last:])
+
+	return command.String()
+}
+
+// substitute formats a placeholder value for insertion into a command
+func substitute(placeholder Placeholder, value string, state quoteState) string {
+	switch {
+	case placeholder.Raw:
+		return value
+	case placeholder.Variadic && state == quoteNone:
+		words := strings.Fields(value)
+		for i, word := range words {
+			words[i] = ShellQuote(word)
+		}
+		return strings.Join(words, " ")
+	default:
+		return quoteFor(value, state)
+	}
+}
+
+// RenderStrict renders a command like Render but fails with an
+// *UnresolvedError if any placeholder is left without a value
+func (e *Example) RenderStrict(vars map[string]string) (string, error) {
+	if missing := e.Missing(vars); len(missing) > 0 {
+		names := make([]string, len(missing))
+		for i, placeholder := range missing {
+			names[i] = placeholder.Name
+		}
+		return "", &UnresolvedError{Names: names}
+	}
+	return e.Render(vars), nil
 }
 
+// BindPositional maps positional values onto the example's placeholders in
+// order of appearance. Placeholders already set in vars are skipped, so named
+// values always win over positional ones.
+func (e *Example) BindPositional(vars map[string]string, args []string) (map[string]string, error) {
+	bound := make(map[string]string, len(vars)+len(args))
+	for name, value := range vars {
+		bound[name] = value
+	}
+
+	i := 0
+	for _, placeholder := range e.Placeholders {
+		if i >= len(args) {
+			break
+		}
+		if _, ok := bound[placeholder.Name]; ok {
+			continue
+		}
+		bound[placeholder.Name] = args[i]
+		i++
+	}
+
+	if i < len(args) {
+		return nil, fmt.Errorf("too many positional values: %d given, %d placeholders free", len(args), i)
+	}
+
+	return bound, nil
+}
+
+// Missing returns the placeholders that have neither a value in vars nor a
+// default
+func (e *Example) Missing(vars map[string]string) []Placeholder {
+	var missing []Placeholder
+	for _, placeholder := range e.Placeholders {
+		if vars[placeholder.Name] == "" && placeholder.Default == "" {
+			missing = append(missing, placeholder)
+		}
+	}
+	return missing
+}
+
+// placeholderPattern matches {{placeholder}} patterns
+var placeholderPattern = regexp.MustCompile(`\{\{([^}]+)\}\}`)
+
 // extractPlaceholders extracts placeholders from a command string
 func extractPlaceholders(command string) []Placeholder {
 	var placeholders []Placeholder
 	
-	// Regex to find {{placeholder}} patterns
-	re := regexp.MustCompile(`\{\{([^}]+)\}\}`)
-	matches := re.FindAllStringSubmatch(command, -1)
+	matches := placeholderPattern.FindAllStringSubmatch(command, -1)
 	
 	seen := make(map[string]bool)
 	for _, match := range matches {
 		if len(match) > 1 {
-			name := match[1]
-			if !seen[name] {
-				seen[name] = true
-				placeholder := Placeholder{
-					Name: name,
-					Type: inferPlaceholderType(name),
-				}
-				placeholders = append(placeholders, placeholder)
+			token := match[1]
+			if !seen[token] {
+				seen[token] = true
+				placeholders = append(placeholders, parsePlaceholder(token))
 			}
 		}
 	}
@@ -174,12 +308,16 @@ func inferPlaceholderType(name string) string {
 		return "number"
 	case strings.Contains(name, "url") || strings.Contains(name, "link"):
 		return "url"
+	case strings.Contains(name, "host"):
+		return "host"
 	case strings.Contains(name, "ip") || strings.Contains(name, "address"):
 		return "ip"
 	case strings.Contains(name, "user") || strings.Contains(name, "username"):
 		return "username"
 	case strings.Contains(name, "pass") || strings.Contains(name, "password"):
 		return "password"
+	case strings.Contains(name, "token") || strings.Contains(name, "secret") || strings.Contains(name, "apikey") || strings.Contains(name, "api_key"):
+		return "token"
 	case strings.Contains(name, "email"):
 		return "email"
 	default:
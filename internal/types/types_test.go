@@ -1,20 +1,22 @@
 package types
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
 func TestParsePage(t *testing.T) {
-	content := `# tar
-
-> Archive utility.
-
-- Extract archive:
-  \`tar -xf {{file}}\`
-
-- List contents:
-  \`tar -tf {{file}}\`
-`
+	content := "# tar\n" +
+		"\n" +
+		"> Archive utility.\n" +
+		"\n" +
+		"- Extract archive:\n" +
+		"  `tar -xf {{file}}`\n" +
+		"\n" +
+		"- List contents:\n" +
+		"  `tar -tf {{file}}`\n"
 
 	entry := IndexEntry{
 		Name:        "tar",
@@ -193,12 +195,19 @@ func TestInferPlaceholderType(t *testing.T) {
 		{"num", "number"},
 		{"url", "url"},
 		{"link", "url"},
+		{"host", "host"},
+		{"remote_host", "host"},
+		{"hostname", "host"},
 		{"ip", "ip"},
 		{"address", "ip"},
 		{"username", "username"},
 		{"user", "username"},
 		{"password", "password"},
 		{"pass", "password"},
+		{"token", "token"},
+		{"access_token", "token"},
+		{"client_secret", "token"},
+		{"api_key", "token"},
 		{"email", "email"},
 		{"unknown", "text"},
 		{"random", "text"},
@@ -212,4 +221,232 @@ func TestInferPlaceholderType(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestBindPositional(t *testing.T) {
+	example := Example{
+		Command: "tar -xf {{file}} -C {{dest}}",
+		Placeholders: []Placeholder{
+			{Name: "file", Type: "file"},
+			{Name: "dest", Type: "text"},
+		},
+	}
+
+	tests := []struct {
+		description string
+		vars        map[string]string
+		args        []string
+		expected    map[string]string
+		wantErr     bool
+	}{
+		{
+			description: "positional only",
+			args:        []string{"x.tar.gz", "./out"},
+			expected:    map[string]string{"file": "x.tar.gz", "dest": "./out"},
+		},
+		{
+			description: "named values are skipped",
+			vars:        map[string]string{"file": "a.tar"},
+			args:        []string{"./out"},
+			expected:    map[string]string{"file": "a.tar", "dest": "./out"},
+		},
+		{
+			description: "fewer values than placeholders",
+			args:        []string{"x.tar.gz"},
+			expected:    map[string]string{"file": "x.tar.gz"},
+		},
+		{
+			description: "too many values",
+			args:        []string{"a", "b", "c"},
+			wantErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			bound, err := example.BindPositional(test.vars, test.args)
+			if test.wantErr {
+				if err == nil {
+					t.Error("Expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BindPositional failed: %v", err)
+			}
+			if len(bound) != len(test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, bound)
+			}
+			for name, value := range test.expected {
+				if bound[name] != value {
+					t.Errorf("Expected %s=%s, got %s", name, value, bound[name])
+				}
+			}
+		})
+	}
+}
+
+func TestSelectExample(t *testing.T) {
+	page := &Page{
+		Name: "tar",
+		Examples: []Example{
+			{Description: "Create an archive", Command: "tar -cf {{target.tar}} {{file}}"},
+			{Description: "Extract an archive", Command: "tar -xf {{source.tar}}"},
+		},
+	}
+
+	example, err := page.ExampleAt(2)
+	if err != nil {
+		t.Fatalf("ExampleAt failed: %v", err)
+	}
+	if example.Description != "Extract an archive" {
+		t.Errorf("Expected 'Extract an archive', got '%s'", example.Description)
+	}
+
+	for _, n := range []int{0, 3} {
+		if _, err := page.ExampleAt(n); err == nil {
+			t.Errorf("Expected error for index %d", n)
+		}
+	}
+
+	example, err = page.MatchExample("EXTRACT")
+	if err != nil {
+		t.Fatalf("MatchExample failed: %v", err)
+	}
+	if example.Command != "tar -xf {{source.tar}}" {
+		t.Errorf("Expected extract example, got '%s'", example.Command)
+	}
+
+	example, err = page.MatchExample("-cf")
+	if err != nil || example.Description != "Create an archive" {
+		t.Errorf("Expected match on command text, got %v, %v", example, err)
+	}
+
+	if _, err := page.MatchExample("compress"); err == nil {
+		t.Error("Expected error for unmatched query")
+	}
+}
+
+func TestMissing(t *testing.T) {
+	example := Example{
+		Command: "scp -P {{port}} {{file}} {{host}}:{{dest}}",
+		Placeholders: []Placeholder{
+			{Name: "port", Default: "22"},
+			{Name: "file"},
+			{Name: "host"},
+			{Name: "dest"},
+		},
+	}
+
+	missing := example.Missing(map[string]string{"file": "a.txt", "host": ""})
+	if len(missing) != 2 || missing[0].Name != "host" || missing[1].Name != "dest" {
+		t.Errorf("Expected [host dest] missing, got %v", missing)
+	}
+}
+
+func TestExampleRenderUnresolved(t *testing.T) {
+	example := Example{
+		Command: "rm -rf {{dir}}",
+		Placeholders: []Placeholder{
+			{Name: "dir", Type: "directory"},
+		},
+	}
+
+	if result := example.Render(map[string]string{}); result != "rm -rf {{dir}}" {
+		t.Errorf("Expected unresolved placeholder to stay visible, got '%s'", result)
+	}
+
+	_, err := example.RenderStrict(map[string]string{})
+	unresolved, ok := err.(*UnresolvedError)
+	if !ok {
+		t.Fatalf("Expected *UnresolvedError, got %v", err)
+	}
+	if len(unresolved.Names) != 1 || unresolved.Names[0] != "dir" {
+		t.Errorf("Expected [dir] unresolved, got %v", unresolved.Names)
+	}
+
+	result, err := example.RenderStrict(map[string]string{"dir": "build"})
+	if err != nil {
+		t.Fatalf("RenderStrict failed: %v", err)
+	}
+	if result != "rm -rf build" {
+		t.Errorf("Expected 'rm -rf build', got '%s'", result)
+	}
+}
+
+func TestScopeVars(t *testing.T) {
+	vars := map[string]string{
+		"file":   "notes.txt",
+		"host":   "db",
+		"2.file": "other.txt",
+		"3.host": "web",
+		"0.file": "ignored",
+	}
+
+	tests := []struct {
+		name     string
+		n        int
+		expected map[string]string
+	}{
+		{"page values", 1, map[string]string{"file": "notes.txt", "host": "db", "0.file": "ignored"}},
+		{"file overridden", 2, map[string]string{"file": "other.txt", "host": "db", "0.file": "ignored"}},
+		{"host overridden", 3, map[string]string{"file": "notes.txt", "host": "web", "0.file": "ignored"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scoped := ScopeVars(vars, tt.n)
+			if !reflect.DeepEqual(scoped, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, scoped)
+			}
+		})
+	}
+}
+
+func TestIndexOf(t *testing.T) {
+	page := &Page{Examples: []Example{
+		{Description: "List", Command: "ls"},
+		{Description: "List all", Command: "ls -a"},
+	}}
+
+	example := page.Examples[1]
+	if n := page.IndexOf(&example); n != 2 {
+		t.Errorf("Expected index 2, got %d", n)
+	}
+	if n := page.IndexOf(&Example{Command: "pwd"}); n != 0 {
+		t.Errorf("Expected index 0 for a foreign example, got %d", n)
+	}
+}
+
+// BenchmarkRender measures filling an example of the page corpus, with a
+// value for every placeholder, as the TUI does on every edit. Budget: under
+// 20µs per example.
+func BenchmarkRender(b *testing.B) {
+	files, err := filepath.Glob(filepath.Join("testdata", "pages", "*", "*.md"))
+	if err != nil {
+		b.Fatalf("Failed to list pages: %v", err)
+	}
+	var examples []Example
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			b.Fatalf("Failed to read %s: %v", file, err)
+		}
+		page, err := ParsePage(string(data), IndexEntry{Platform: filepath.Base(filepath.Dir(file))})
+		if err != nil {
+			b.Fatalf("ParsePage failed: %v", err)
+		}
+		examples = append(examples, page.Examples...)
+	}
+	vars := make(map[string]string)
+	for _, example := range examples {
+		for _, placeholder := range example.Placeholders {
+			vars[placeholder.Name] = "some value's/path"
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		examples[i%len(examples)].Render(vars)
+	}
+}
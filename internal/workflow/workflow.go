@@ -0,0 +1,153 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/makalin/tldrpp/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotFound is returned for a workflow that does not exist
+var ErrNotFound = errors.New("workflow not found")
+
+// ErrAborted is returned when the user stops a workflow before its last step
+var ErrAborted = errors.New("workflow aborted")
+
+// namePattern restricts workflow names to what is safe as a file name
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Step is one command of a workflow
+type Step struct {
+	Description string `yaml:"description"`
+	// Command may use {{placeholders}}; steps share the values of
+	// placeholders with the same name
+	Command string `yaml:"command"`
+	// Page and Platform optionally name the page the step comes from, so
+	// that running it goes through the same trust checks as the page
+	Page     string `yaml:"page,omitempty"`
+	Platform string `yaml:"platform,omitempty"`
+}
+
+// Workflow is an ordered set of commands run one after the other, like a
+// runbook
+type Workflow struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Vars holds default placeholder values
+	Vars  map[string]string `yaml:"vars,omitempty"`
+	Steps []Step            `yaml:"steps"`
+}
+
+// Examples returns the steps as examples, with their placeholders extracted
+func (w *Workflow) Examples() []*types.Example {
+	examples := make([]*types.Example, len(w.Steps))
+	for i, step := range w.Steps {
+		examples[i] = types.NewExample(step.Description, step.Command)
+	}
+	return examples
+}
+
+// Example returns all steps as one example whose placeholders are those of
+// the steps, each name once, so that shared placeholders are filled once
+func (w *Workflow) Example() *types.Example {
+	commands := make([]string, len(w.Steps))
+	var placeholders []types.Placeholder
+	seen := make(map[string]bool)
+	for i, example := range w.Examples() {
+		commands[i] = example.Command
+		for _, placeholder := range example.Placeholders {
+			if !seen[placeholder.Name] {
+				seen[placeholder.Name] = true
+				placeholders = append(placeholders, placeholder)
+			}
+		}
+	}
+	return &types.Example{
+		Description:  w.Description,
+		Command:      strings.Join(commands, "\n"),
+		Placeholders: placeholders,
+	}
+}
+
+// validate checks that the workflow has something to run
+func (w *Workflow) validate() error {
+	if len(w.Steps) == 0 {
+		return fmt.Errorf("workflow %s has no steps", w.Name)
+	}
+	for i, step := range w.Steps {
+		if strings.TrimSpace(step.Command) == "" {
+			return fmt.Errorf("step %d of workflow %s has no command", i+1, w.Name)
+		}
+	}
+	return nil
+}
+
+// Store reads workflows kept as one YAML file each in a directory
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store for the workflows in dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Load reads the workflow with the given name
+func (s *Store) Load(name string) (*Workflow, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	data, err := os.ReadFile(s.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to read workflow: %w", err)
+	}
+
+	var workflow Workflow
+	if err := yaml.Unmarshal(data, &workflow); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow %s: %w", name, err)
+	}
+	workflow.Name = name
+
+	if err := workflow.validate(); err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+// List returns every valid workflow, sorted by name. Files that can't be
+// read are skipped.
+func (s *Store) List() ([]*Workflow, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.yml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	var workflows []*Workflow
+	for _, file := range files {
+		workflow, err := s.Load(strings.TrimSuffix(filepath.Base(file), ".yml"))
+		if err != nil {
+			continue
+		}
+		workflows = append(workflows, workflow)
+	}
+
+	sort.Slice(workflows, func(i, j int) bool {
+		return workflows[i].Name < workflows[j].Name
+	})
+	return workflows, nil
+}
+
+// path returns the file a workflow is stored in
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+".yml")
+}
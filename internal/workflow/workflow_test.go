@@ -0,0 +1,105 @@
+package workflow
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const deploy = `description: Build and ship a release
+vars:
+  branch: main
+steps:
+  - description: Update the checkout
+    command: git pull origin {{branch}}
+  - description: Build the archive
+    command: tar -czf {{archive}} {{path/to/dir}}
+  - description: Copy it to the server
+    command: scp {{archive}} {{host}}:/srv
+    page: scp
+    platform: common
+`
+
+func writeWorkflow(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name+".yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflow(t, dir, "deploy", deploy)
+
+	workflow, err := NewStore(dir).Load("deploy")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if workflow.Name != "deploy" || len(workflow.Steps) != 3 {
+		t.Fatalf("Expected workflow deploy with 3 steps, got %+v", workflow)
+	}
+	if workflow.Vars["branch"] != "main" {
+		t.Errorf("Expected default branch 'main', got '%s'", workflow.Vars["branch"])
+	}
+	if step := workflow.Steps[2]; step.Page != "scp" || step.Platform != "common" {
+		t.Errorf("Expected the last step to come from common/scp, got %+v", step)
+	}
+}
+
+func TestSharedPlaceholders(t *testing.T) {
+	workflow := &Workflow{Steps: []Step{
+		{Command: "tar -czf {{archive}} {{path/to/dir}}"},
+		{Command: "scp {{archive}} {{host}}:/srv"},
+	}}
+
+	example := workflow.Example()
+	var names []string
+	for _, placeholder := range example.Placeholders {
+		names = append(names, placeholder.Name)
+	}
+	expected := []string{"archive", "dir", "host"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected placeholders %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected placeholders %v, got %v", expected, names)
+		}
+	}
+
+	vars := map[string]string{"archive": "out.tar.gz", "dir": "src", "host": "db"}
+	examples := workflow.Examples()
+	if got := examples[1].Render(vars); got != "scp out.tar.gz db:/srv" {
+		t.Errorf("Expected the shared value in the second step, got '%s'", got)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkflow(t, dir, "empty", "description: nothing\n")
+	writeWorkflow(t, dir, "blank", "steps:\n  - description: no command\n")
+	writeWorkflow(t, dir, "ok", "steps:\n  - command: ls\n")
+	store := NewStore(dir)
+
+	tests := []string{"empty", "blank", "missing", "../ok"}
+	for _, name := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.Load(name); err == nil {
+				t.Errorf("Expected an error loading %q", name)
+			}
+		})
+	}
+
+	if _, err := store.Load("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	workflows, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(workflows) != 1 || workflows[0].Name != "ok" {
+		t.Errorf("Expected only the valid workflow to be listed, got %d", len(workflows))
+	}
+}